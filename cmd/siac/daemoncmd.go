@@ -3,12 +3,18 @@ package main
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"gitlab.com/NebulousLabs/errors"
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/node/api"
+	"go.thebigfile.com/bigd/persist"
 )
 
 var (
@@ -44,6 +50,30 @@ Set them to 0 for no limit.`,
 		Run: wrap(globalratelimitcmd),
 	}
 
+	logLevelsCmd = &cobra.Command{
+		Use:   "loglevels",
+		Short: "List the log levels of the modules",
+		Long: `List the log levels of siad's modules. Modules without a level of their
+own use the default level. Valid levels are debug, info, warn and error.`,
+		Run: wrap(loglevelscmd),
+	}
+
+	logLevelsResetCmd = &cobra.Command{
+		Use:   "reset [module]",
+		Short: "Reset the log level of a module",
+		Long:  "Remove the log level of a module, which then uses the default level again.",
+		Run:   wrap(loglevelsresetcmd),
+	}
+
+	logLevelsSetCmd = &cobra.Command{
+		Use:   "set [module] [level]",
+		Short: "Set the log level of a module",
+		Long: `Set the log level of a module, e.g. renter, host or contractor. The
+module 'default' sets the level of all modules without a level of their own.
+Valid levels are debug, info, warn and error.`,
+		Run: wrap(loglevelssetcmd),
+	}
+
 	profileCmd = &cobra.Command{
 		Use:   "profile",
 		Short: "Start and stop profiles for the daemon",
@@ -82,6 +112,42 @@ directory in the siad data directory.`,
 		Run:   wrap(updatecmd),
 	}
 
+	tokensCmd = &cobra.Command{
+		Use:   "tokens",
+		Short: "List the API tokens",
+		Long: `List the API tokens. API tokens can be used instead of the API password
+and are limited to the routes of their scopes. To use a token with siac, pass
+it with --apipassword.`,
+		Run: wrap(tokenscmd),
+	}
+
+	tokensCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create an API token",
+		Long: `Create an API token with the scopes provided with --scopes and print it.
+The token can't be retrieved again later. Valid scopes are:
+
+  read-only     GET requests which don't require the API password and
+                the metrics
+  renter-files  uploading, downloading and managing the renter's files
+  local-files   renter requests which use paths on the daemon's filesystem
+  wallet-spend  spending from and managing the wallet
+  host-admin    changing the host's settings and storage
+  daemon-admin  all other requests which modify state or require the
+                API password
+
+Every scope includes read-only. --path-prefixes restricts the renter file
+requests of the token to siapaths within the provided folders.`,
+		Run: wrap(tokenscreatecmd),
+	}
+
+	tokensRevokeCmd = &cobra.Command{
+		Use:   "revoke [name]",
+		Short: "Revoke an API token",
+		Long:  "Revoke an API token.",
+		Run:   wrap(tokensrevokecmd),
+	}
+
 	versionCmd = &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
@@ -290,6 +356,45 @@ func globalratelimitcmd(downloadSpeedStr, uploadSpeedStr string) {
 	fmt.Println("Set global maxdownloadspeed to ", downloadSpeedInt, " and maxuploadspeed to ", uploadSpeedInt)
 }
 
+// loglevelscmd lists the log levels of the modules.
+func loglevelscmd() {
+	dsg, err := httpClient.DaemonSettingsGet()
+	if err != nil {
+		die("Could not get log levels:", err)
+	}
+	names := make([]string, 0, len(dsg.LogLevels))
+	for module := range dsg.LogLevels {
+		names = append(names, module)
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Module\tLevel")
+	for _, module := range names {
+		fmt.Fprintf(w, "  %v\t%v\n", module, dsg.LogLevels[module])
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// loglevelsresetcmd removes the log level of a module.
+func loglevelsresetcmd(module string) {
+	err := httpClient.DaemonLogLevelsPost(map[string]persist.LogLevel{module: ""})
+	if err != nil {
+		die("Could not reset log level:", err)
+	}
+	fmt.Printf("Reset log level of %v.\n", module)
+}
+
+// loglevelssetcmd sets the log level of a module.
+func loglevelssetcmd(module, level string) {
+	err := httpClient.DaemonLogLevelsPost(map[string]persist.LogLevel{module: persist.LogLevel(level)})
+	if err != nil {
+		die("Could not set log level:", err)
+	}
+	fmt.Printf("Set log level of %v to %v.\n", module, level)
+}
+
 // printAlerts is a helper function to print details of a slice of alerts
 // with given severity description to command line
 func printAlerts(alerts []modules.Alert, as modules.AlertSeverity) {
@@ -304,3 +409,70 @@ func printAlerts(alerts []modules.Alert, as modules.AlertSeverity) {
 	}
 	fmt.Printf("\n------------------\n\n")
 }
+
+// tokenscmd lists the API tokens.
+func tokenscmd() {
+	dtg, err := httpClient.DaemonTokensGet()
+	if err != nil {
+		die("Could not get API tokens:", err)
+	}
+	if len(dtg.Tokens) == 0 {
+		fmt.Println("No API tokens.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Name\tScopes\tPath Prefixes\tCreated")
+	for _, t := range dtg.Tokens {
+		scopes := make([]string, 0, len(t.Scopes))
+		for _, s := range t.Scopes {
+			scopes = append(scopes, string(s))
+		}
+		prefixes := "-"
+		if len(t.PathPrefixes) > 0 {
+			strs := make([]string, 0, len(t.PathPrefixes))
+			for _, p := range t.PathPrefixes {
+				strs = append(strs, p.String())
+			}
+			prefixes = strings.Join(strs, ",")
+		}
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", t.Name, strings.Join(scopes, ","), prefixes, t.CreationTime.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// tokenscreatecmd creates an API token and prints it.
+func tokenscreatecmd(name string) {
+	var scopes []api.APIScope
+	for _, s := range strings.Split(tokenScopes, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, api.APIScope(s))
+		}
+	}
+	var prefixes []modules.SiaPath
+	for _, p := range strings.Split(tokenPathPrefixes, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		prefix, err := modules.NewSiaPath(p)
+		if err != nil {
+			die("Could not parse path prefix:", err)
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	dtc, err := httpClient.DaemonTokensCreatePost(name, scopes, prefixes)
+	if err != nil {
+		die("Could not create API token:", err)
+	}
+	fmt.Printf("Created API token %v.\n", dtc.Name)
+	fmt.Println("Token:", dtc.Token)
+}
+
+// tokensrevokecmd revokes an API token.
+func tokensrevokecmd(name string) {
+	if err := httpClient.DaemonTokensRevokePost(name); err != nil {
+		die("Could not revoke API token:", err)
+	}
+	fmt.Printf("Revoked API token %v.\n", name)
+}
@@ -1,16 +1,43 @@
 package main
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
+	"io"
+	"math/big"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/types"
 )
 
 var (
+	hostExportCmd = &cobra.Command{
+		Use:   "export [destination]",
+		Short: "Export the host's contracts for revenue reporting",
+		Long: `Export the host's contracts and their revenue in CSV or JSON format to the
+specified file, or to stdout if no file is given.
+
+Every contract is reported at the height at which it was resolved. Unresolved
+contracts are only exported with --unresolved and are reported at their
+negotiation height with their potential revenue. The range of the export can be
+limited by reporting height and by the time of the block at that height.
+
+The values can be converted to fiat with an exchange rate table. The table is
+a CSV file with a day (YYYY-MM-DD) or unix timestamp and the fiat value of one
+siacoin per line. Each rate applies until the next entry, e.g.:
+	2021-01-01,0.005 usd
+	2021-02-01,0.007 usd`,
+		Run: hostexportcmd,
+	}
+
 	renterExportCmd = &cobra.Command{
 		Use:   "export",
 		Short: "export renter data to various formats",
@@ -49,3 +76,173 @@ func renterexportcontracttxnscmd(destination string) {
 	}
 	fmt.Println("Exported contract data to", destination)
 }
+
+// hostExportCSVHeader is the header of a contract export in CSV format.
+var hostExportCSVHeader = []string{
+	"obligation id", "status", "reporting height", "time",
+	"negotiation height", "expiration height", "proof deadline", "proof height",
+	"contract cost (SC)", "storage revenue (SC)", "upload revenue (SC)", "download revenue (SC)",
+	"account funding (SC)", "transaction fees (SC)", "locked collateral (SC)", "risked collateral (SC)",
+	"lost collateral (SC)", "lost revenue (SC)",
+	"origin transaction id", "revision transaction id", "proof transaction id",
+	"exchange rate", "contract cost (fiat)", "storage revenue (fiat)", "upload revenue (fiat)", "download revenue (fiat)",
+	"account funding (fiat)", "transaction fees (fiat)", "locked collateral (fiat)", "risked collateral (fiat)",
+	"lost collateral (fiat)", "lost revenue (fiat)",
+}
+
+// hostexportcmd is the handler for the command `siac host export`.
+// Exports the host's contracts for revenue reporting.
+func hostexportcmd(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	params := modules.HostContractExportParams{
+		StartHeight:       types.BlockHeight(hostExportStartHeight),
+		EndHeight:         types.BlockHeight(hostExportEndHeight),
+		IncludeUnresolved: hostExportUnresolved,
+	}
+	var err error
+	if hostExportStartTime != "" {
+		params.StartTime, err = parseExportTime(hostExportStartTime, false)
+		if err != nil {
+			die("Could not parse start time:", err)
+		}
+	}
+	if hostExportEndTime != "" {
+		params.EndTime, err = parseExportTime(hostExportEndTime, true)
+		if err != nil {
+			die("Could not parse end time:", err)
+		}
+	}
+	if hostExportRates != "" {
+		params.Rates, err = readExchangeRates(abs(hostExportRates))
+		if err != nil {
+			die("Could not read exchange rates:", err)
+		}
+	}
+	if hostExportFormat != "csv" && hostExportFormat != "json" {
+		die("Unknown export format:", hostExportFormat)
+	}
+
+	heg, err := httpClient.HostExportGet(params)
+	if err != nil {
+		die("Could not export contracts:", err)
+	}
+
+	out := io.Writer(os.Stdout)
+	if len(args) == 1 {
+		destination := abs(args[0])
+		file, err := os.Create(destination)
+		if err != nil {
+			die("Could not export to file:", err)
+		}
+		defer func() {
+			if err := file.Close(); err != nil {
+				die("Could not export to file:", err)
+			}
+			fmt.Printf("Exported %v contracts to %v\n", len(heg.Records), destination)
+		}()
+		out = file
+	}
+	if hostExportFormat == "json" {
+		err = json.NewEncoder(out).Encode(heg.Records)
+	} else {
+		err = writeContractRecordsCSV(out, heg.Records)
+	}
+	if err != nil {
+		die("Could not export contracts:", err)
+	}
+}
+
+// parseExportTime parses a day in the format YYYY-MM-DD or a unix timestamp.
+// If end is true, a day is parsed as the last second of that day.
+func parseExportTime(s string, end bool) (types.Timestamp, error) {
+	if timestamp, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return types.Timestamp(timestamp), nil
+	}
+	day, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return 0, errors.New("expected a day (YYYY-MM-DD) or a unix timestamp")
+	}
+	if end {
+		day = day.Add(24*time.Hour - time.Second)
+	}
+	return types.Timestamp(day.Unix()), nil
+}
+
+// readExchangeRates reads an exchange rate table from a CSV file.
+func readExchangeRates(path string) ([]modules.HostExchangeRate, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	r := csv.NewReader(file)
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+	lines, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	rates := make([]modules.HostExchangeRate, 0, len(lines))
+	for _, line := range lines {
+		timestamp, err := parseExportTime(strings.TrimSpace(line[0]), false)
+		if err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid entry %q", strings.Join(line, ",")))
+		}
+		rates = append(rates, modules.HostExchangeRate{
+			Timestamp: timestamp,
+			Rate:      strings.TrimSpace(line[1]),
+		})
+	}
+	return rates, nil
+}
+
+// writeContractRecordsCSV writes the contract records in CSV format.
+func writeContractRecordsCSV(out io.Writer, records []modules.HostContractRecord) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(hostExportCSVHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		row := []string{
+			r.ObligationId.String(), r.ObligationStatus, fmt.Sprint(r.ReportingHeight), "",
+			fmt.Sprint(r.NegotiationHeight), fmt.Sprint(r.ExpirationHeight), fmt.Sprint(r.ProofDeadline), "",
+			currencySC(r.ContractCost), currencySC(r.StorageRevenue), currencySC(r.UploadRevenue), currencySC(r.DownloadRevenue),
+			currencySC(r.AccountFunding), currencySC(r.TransactionFees), currencySC(r.LockedCollateral), currencySC(r.RiskedCollateral),
+			currencySC(r.LostCollateral), currencySC(r.LostRevenue),
+			r.OriginTransactionID.String(), "", "",
+		}
+		if r.Timestamp != 0 {
+			row[3] = time.Unix(int64(r.Timestamp), 0).UTC().Format(time.RFC3339)
+		}
+		if r.ProofHeight != 0 {
+			row[7] = fmt.Sprint(r.ProofHeight)
+		}
+		if r.RevisionTransactionID != (types.TransactionID{}) {
+			row[19] = r.RevisionTransactionID.String()
+		}
+		if r.ProofTransactionID != (types.TransactionID{}) {
+			row[20] = r.ProofTransactionID.String()
+		}
+		if f := r.Fiat; f != nil {
+			row = append(row, f.Rate, f.ContractCost, f.StorageRevenue, f.UploadRevenue, f.DownloadRevenue,
+				f.AccountFunding, f.TransactionFees, f.LockedCollateral, f.RiskedCollateral,
+				f.LostCollateral, f.LostRevenue)
+		} else {
+			row = append(row, make([]string, len(hostExportCSVHeader)-len(row))...)
+		}
+		if err := w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// currencySC formats a currency as an exact amount of siacoins without unit.
+func currencySC(c types.Currency) string {
+	sc := new(big.Rat).SetFrac(c.Big(), types.SiacoinPrecision.Big()).FloatString(24)
+	return strings.TrimSuffix(strings.TrimRight(sc, "0"), ".")
+}
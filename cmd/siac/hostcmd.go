@@ -5,8 +5,10 @@ import (
 	"math/big"
 	"os"
 	"sort"
+	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,6 +21,30 @@ import (
 )
 
 var (
+	hostAccountsCmd = &cobra.Command{
+		Use:   "accounts",
+		Short: "Show host ephemeral accounts",
+		Long:  "Show the ephemeral accounts of the host sorted by balance, and the total balance held in them.",
+		Run:   wrap(hostaccountscmd),
+	}
+
+	hostAccountsExpireCmd = &cobra.Command{
+		Use:   "expire [id]",
+		Short: "Expire an ephemeral account",
+		Long: `Expire an ephemeral account right away. The balance of the account is
+removed and blocked withdrawals are cancelled.`,
+		Run: wrap(hostaccountsexpirecmd),
+	}
+
+	hostAccountsMaxBalanceCmd = &cobra.Command{
+		Use:   "maxbalance [id] [amount]",
+		Short: "Set the max balance of an ephemeral account",
+		Long: `Set the max balance of a single ephemeral account. The account can't be
+funded beyond the lower of this cap and the host's maxephemeralaccountbalance.
+Use 0 to remove the cap.`,
+		Run: wrap(hostaccountsmaxbalancecmd),
+	}
+
 	hostAnnounceCmd = &cobra.Command{
 		Use:   "announce",
 		Short: "Announce yourself as a host",
@@ -70,6 +96,29 @@ Available settings:
      registrysize:       filesize
      customregistrypath: string
 
+     acceptingtemporarysectors: boolean
+     maxtemporarystorage:       filesize
+     mintemporarystorageprice:  currency / TB / Month
+
+     bandwidthlimitdownload:       bandwidth, e.g. 10MB/s
+     bandwidthlimitupload:         bandwidth, e.g. 10MB/s
+     bandwidthlimitregistry:       bandwidth, e.g. 10MB/s
+     bandwidthlimitrenterdownload: bandwidth, e.g. 1MB/s
+     bandwidthlimitrenterupload:   bandwidth, e.g. 1MB/s
+     bandwidthlimitrenterregistry: bandwidth, e.g. 1MB/s
+
+     pricingengine:                             boolean
+     pricingengineexchangerate:                 fiat / SC, e.g. "0.005 usd"
+     pricingenginetargetdownloadbandwidthprice: fiat / TB
+     pricingenginetargetstorageprice:           fiat / TB / Month
+     pricingenginetargetuploadbandwidthprice:   fiat / TB
+     pricingenginetargetbandwidth:              bandwidth, e.g. 10MB/s
+     pricingenginemaxdownloadbandwidthprice:    currency / TB
+     pricingenginemaxstorageprice:              currency / TB / Month
+     pricingenginemaxuploadbandwidthprice:      currency / TB
+     pricingenginemaxpricechange:               fraction, e.g. 0.05
+     pricingengineupdateinterval:               seconds
+
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
 Durations (maxduration and windowsize) must be specified in either blocks (b),
@@ -82,6 +131,10 @@ seconds, and a week is 604800 seconds.
 
 For a description of each parameter, see doc/API.md.
 
+The pricing engine adjusts the storage and bandwidth prices within the min and
+max prices based on the host's utilization and the target prices. The target
+prices are specified in the currency of the exchange rate.
+
 To configure the host to accept new contracts, set acceptingcontracts to true:
 	siac host config acceptingcontracts true
 `,
@@ -109,8 +162,48 @@ Available output types:
 
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, remove, resize, tier, migrate, rebalance or mirror storage folders",
+		Long:  "Add, remove, resize, tier, migrate, rebalance or mirror storage folders.",
+	}
+
+	hostFolderCancelCmd = &cobra.Command{
+		Use:   "cancel",
+		Short: "Cancel a running migration or rebalance",
+		Long: `Cancel the running storage folder migration or rebalance. Sectors that were
+moved already stay in their new storage folder.`,
+		Run: wrap(hostfoldercancelcmd),
+	}
+
+	hostFolderMigrateCmd = &cobra.Command{
+		Use:   "migrate [source] [destination]",
+		Short: "Move all data of a storage folder into another one",
+		Long: `Move all data of the source storage folder into the destination storage
+folder in the background. No new data is stored in the source folder during the
+migration. Use --remove-source to remove the source folder once it is empty,
+for example when replacing a disk. The progress is shown by 'siac host'.`,
+		Run: wrap(hostfoldermigratecmd),
+	}
+
+	hostFolderRebalanceCmd = &cobra.Command{
+		Use:   "rebalance",
+		Short: "Even out the free space of the storage folders",
+		Long: `Move data between the storage folders of each tier in the background until
+all of them have the same relative amount of free space. The progress is shown
+by 'siac host'.`,
+		Run: wrap(hostfolderrebalancecmd),
+	}
+
+	hostFolderRedundancyCmd = &cobra.Command{
+		Use:   "redundancy [none|mirror]",
+		Short: "Set the local redundancy of the stored data",
+		Long: `Set the local redundancy of the data stored by the host. In mirror mode a
+second copy of every sector is stored in a storage folder on a different
+device, so that the data survives the failure of a single disk. Reads fall back
+to the second copy if the first one can't be read. Missing copies are created
+and copies on failed disks are replaced in the background, the progress is
+shown by 'siac host'. Mirroring requires storage folders on at least two
+devices and halves the usable capacity.`,
+		Run: wrap(hostfolderredundancycmd),
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -131,6 +224,53 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostFolderTierCmd = &cobra.Command{
+		Use:   "tier [path] [fast|slow]",
+		Short: "Set the tier of a storage folder",
+		Long: `Set the tier of a storage folder. Folders on fast media such as SSDs should
+use the fast tier, folders on slow media such as HDDs the slow tier. Sectors
+that are read frequently are moved to the fast tier in the background, sectors
+that aren't read anymore are moved back to the slow tier to make room.`,
+		Run: wrap(hostfoldertiercmd),
+	}
+
+	hostRegistryCmd = &cobra.Command{
+		Use:   "registry",
+		Short: "Show the usage of the host's registry",
+		Long:  "Show the usage statistics of the host's registry.",
+		Run:   wrap(hostregistrycmd),
+	}
+
+	hostRegistryEntryCmd = &cobra.Command{
+		Use:   "entry [entryid]",
+		Short: "Show a registry entry",
+		Long:  "Show the entry of the host's registry with the given entry id.",
+		Run:   wrap(hostregistryentrycmd),
+	}
+
+	hostRegistryExportCmd = &cobra.Command{
+		Use:   "export [destination]",
+		Short: "Export the registry entries",
+		Long: `Export the entries of the host's registry to a new file at the destination.
+The file can be imported by another host with 'siac host registry import'.`,
+		Run: wrap(hostregistryexportcmd),
+	}
+
+	hostRegistryImportCmd = &cobra.Command{
+		Use:   "import [source]",
+		Short: "Import registry entries",
+		Long: `Import the entries of a registry export into the host's registry. Expired
+entries and entries for which the host knows a more recent revision are skipped.`,
+		Run: wrap(hostregistryimportcmd),
+	}
+
+	hostRegistryPruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete expired registry entries",
+		Long:  "Delete the expired entries from the host's registry.",
+		Run:   wrap(hostregistryprunecmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
@@ -238,6 +378,17 @@ Host Internal Settings:
 	registrysize:       %v
 	customregistrypath: %v
 
+	acceptingtemporarysectors: %v
+	maxtemporarystorage:       %v
+	mintemporarystorageprice:  %v / TB / Month
+
+	bandwidthlimitdownload:       %v
+	bandwidthlimitupload:         %v
+	bandwidthlimitregistry:       %v
+	bandwidthlimitrenterdownload: %v
+	bandwidthlimitrenterupload:   %v
+	bandwidthlimitrenterregistry: %v
+
 Host Financials:
 	Contract Count:               %v
 	Transaction Fee Compensation: %v
@@ -264,6 +415,12 @@ RPC Stats:
 	Revise Calls:       %v
 	Settings Calls:     %v
 	FormContract Calls: %v
+
+Bandwidth Shaping:
+	Class     Transferred  Throttled    Wait Time  Queued  Renters
+	Download  %-11v  %-11v  %-9v  %-6v  %v
+	Upload    %-11v  %-11v  %-9v  %-6v  %v
+	Registry  %-11v  %-11v  %-9v  %-6v  %v
 `,
 			connectabilityString,
 			es.Version,
@@ -292,6 +449,17 @@ RPC Stats:
 			modules.FilesizeUnits(is.RegistrySize),
 			is.CustomRegistryPath,
 
+			yesNo(is.AcceptingTemporarySectors),
+			modules.FilesizeUnits(is.MaxTemporaryStorage),
+			currencyUnits(is.MinTemporaryStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
+
+			bandwidthLimitUnits(is.BandwidthLimits.Download),
+			bandwidthLimitUnits(is.BandwidthLimits.Upload),
+			bandwidthLimitUnits(is.BandwidthLimits.Registry),
+			bandwidthLimitUnits(is.BandwidthLimits.RenterDownload),
+			bandwidthLimitUnits(is.BandwidthLimits.RenterUpload),
+			bandwidthLimitUnits(is.BandwidthLimits.RenterRegistry),
+
 			fm.ContractCount, currencyUnits(fm.ContractCompensation),
 			currencyUnits(fm.PotentialContractCompensation),
 			currencyUnits(fm.TransactionFeeExpenses),
@@ -310,7 +478,14 @@ RPC Stats:
 
 			nm.ErrorCalls, nm.UnrecognizedCalls, nm.DownloadCalls,
 			nm.RenewCalls, nm.ReviseCalls, nm.SettingsCalls,
-			nm.FormContractCalls)
+			nm.FormContractCalls,
+
+			modules.FilesizeUnits(nm.DownloadBandwidth.Bytes), modules.FilesizeUnits(nm.DownloadBandwidth.ThrottledBytes),
+			nm.DownloadBandwidth.WaitTime.Round(time.Second), nm.DownloadBandwidth.QueuedRequests, nm.DownloadBandwidth.Renters,
+			modules.FilesizeUnits(nm.UploadBandwidth.Bytes), modules.FilesizeUnits(nm.UploadBandwidth.ThrottledBytes),
+			nm.UploadBandwidth.WaitTime.Round(time.Second), nm.UploadBandwidth.QueuedRequests, nm.UploadBandwidth.Renters,
+			modules.FilesizeUnits(nm.RegistryBandwidth.Bytes), modules.FilesizeUnits(nm.RegistryBandwidth.ThrottledBytes),
+			nm.RegistryBandwidth.WaitTime.Round(time.Second), nm.RegistryBandwidth.QueuedRequests, nm.RegistryBandwidth.Renters)
 	} else {
 		fmt.Printf(`Host info:
 	Connectability Status: %v
@@ -335,6 +510,19 @@ RPC Stats:
 			currencyUnits(totalRevenue))
 	}
 
+	// print the prices of the pricing engine
+	if is.PricingEngine.Enabled {
+		fmt.Printf(`
+Pricing Engine:
+	Storage Price:            %v / TB / Month
+	Upload Bandwidth Price:   %v / TB
+	Download Bandwidth Price: %v / TB
+`,
+			currencyUnits(es.StoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
+			currencyUnits(es.UploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+			currencyUnits(es.DownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)))
+	}
+
 	// if wallet is locked print warning
 	walletstatus, walleterr := httpClient.WalletGet()
 	if walleterr != nil {
@@ -355,15 +543,64 @@ RPC Stats:
 		return
 	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
-	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\tPath\n")
+	fmt.Fprintf(w, "\tUsed\tCapacity\t%% Used\tTier\tPath\n")
 	for _, folder := range sg.Folders {
 		curSize := int64(folder.Capacity - folder.CapacityRemaining)
 		pctUsed := 100 * (float64(curSize) / float64(folder.Capacity))
-		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, folder.Path)
+		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, folder.Tier, folder.Path)
 	}
 	if err := w.Flush(); err != nil {
 		die("failed to flush writer")
 	}
+	if sg.Operation != nil {
+		printStorageFolderOperation(*sg.Operation, sg.Folders)
+	}
+	printStorageRedundancy(sg.Redundancy)
+}
+
+// printStorageRedundancy prints the local redundancy status of the host's
+// storage.
+func printStorageRedundancy(rs modules.StorageRedundancyStatus) {
+	fmt.Printf("\nRedundancy:\n")
+	fmt.Printf("  Mode:     %v\n", rs.Mode)
+	fmt.Printf("  Mirrored: %v of %v sectors, %v degraded\n", rs.MirroredSectors, rs.Sectors, rs.DegradedSectors)
+	if rs.Rebuilding {
+		fmt.Printf("  Rebuild:  %v of %v sectors rebuilt, %v failed\n", rs.RebuildSectorsDone, rs.RebuildSectorsTotal, rs.RebuildSectorsFailed)
+	} else if !rs.LastRebuild.IsZero() {
+		fmt.Printf("  Rebuild:  finished %v, %v of %v sectors rebuilt, %v failed\n", rs.LastRebuild.Format(time.RFC822), rs.RebuildSectorsDone, rs.RebuildSectorsTotal, rs.RebuildSectorsFailed)
+	}
+}
+
+// printStorageFolderOperation prints the progress of a storage folder
+// operation.
+func printStorageFolderOperation(op modules.StorageFolderOperation, folders []modules.StorageFolderMetadata) {
+	status := "running"
+	if op.Cancelled {
+		status = "cancelled"
+	} else if op.Error != "" {
+		status = "failed: " + op.Error
+	} else if !op.Running {
+		status = "finished"
+	}
+	folderPath := func(index uint16) string {
+		for _, sf := range folders {
+			if sf.Index == index {
+				return sf.Path
+			}
+		}
+		return fmt.Sprintf("removed folder %v", index)
+	}
+	fmt.Printf("\nStorage Folder Operation:\n")
+	fmt.Printf("  Type:     %v\n", op.Type)
+	if op.Type == modules.StorageFolderOperationMigrate {
+		fmt.Printf("  Source:   %v\n", folderPath(op.Source))
+		fmt.Printf("  Dest:     %v\n", folderPath(op.Destination))
+	}
+	fmt.Printf("  Status:   %v\n", status)
+	fmt.Printf("  Progress: %v of %v sectors moved, %v failed\n", op.SectorsMoved, op.SectorsTotal, op.SectorsFailed)
+	if op.MaxBandwidth > 0 {
+		fmt.Printf("  Limit:    %v/s\n", modules.FilesizeUnits(op.MaxBandwidth))
+	}
 }
 
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
@@ -379,7 +616,7 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// currency/TB (convert to hastings/byte)
-	case "mindownloadbandwidthprice", "minuploadbandwidthprice":
+	case "mindownloadbandwidthprice", "minuploadbandwidthprice", "pricingenginemaxdownloadbandwidthprice", "pricingenginemaxuploadbandwidthprice":
 		hastings, err := types.ParseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -389,7 +626,7 @@ func hostconfigcmd(param, value string) {
 		value = c.String()
 
 	// currency/TB/month (convert to hastings/byte/block)
-	case "collateral", "minstorageprice":
+	case "collateral", "minstorageprice", "mintemporarystorageprice", "pricingenginemaxstorageprice":
 		hastings, err := types.ParseCurrency(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -399,7 +636,7 @@ func hostconfigcmd(param, value string) {
 		value = c.String()
 
 	// bool (allow "yes" and "no")
-	case "acceptingcontracts":
+	case "acceptingcontracts", "acceptingtemporarysectors", "pricingengine":
 		switch strings.ToLower(value) {
 		case "yes":
 			value = "true"
@@ -415,21 +652,31 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// filesize (convert to bytes)
-	case "registrysize":
+	case "registrysize", "maxtemporarystorage":
 		value, err = parseFilesize(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
 		}
 
+	// bandwidth (convert to bytes per second)
+	case "pricingenginetargetbandwidth", "bandwidthlimitdownload", "bandwidthlimitupload", "bandwidthlimitregistry",
+		"bandwidthlimitrenterdownload", "bandwidthlimitrenterupload", "bandwidthlimitrenterregistry":
+		bps, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		value = strconv.FormatInt(bps, 10)
+
 	// timeout (convert to seconds)
-	case "ephemeralaccountexpiry":
+	case "ephemeralaccountexpiry", "pricingengineupdateinterval":
 		value, err = parseTimeout(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
 		}
 
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "customregistrypath":
+	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "customregistrypath", "pricingengineexchangerate",
+		"pricingenginetargetdownloadbandwidthprice", "pricingenginetargetstorageprice", "pricingenginetargetuploadbandwidthprice", "pricingenginemaxpricechange":
 
 	// invalid settings
 	default:
@@ -483,6 +730,158 @@ func hostcontractcmd() {
 	}
 }
 
+// hostaccountscmd is the handler for the command `siac host accounts`. It
+// lists the ephemeral accounts of the host.
+func hostaccountscmd() {
+	hag, err := httpClient.HostAccountsGet()
+	if err != nil {
+		die("Could not fetch host accounts:", err)
+	}
+	sort.Slice(hag.Accounts, func(i, j int) bool { return hag.Accounts[i].Balance.Cmp(hag.Accounts[j].Balance) > 0 })
+
+	s := hag.Summary
+	fmt.Printf(`Ephemeral Accounts:
+  Accounts:            %v
+  Total Balance:       %v
+  Max Balance:         %v
+  Current Risk:        %v
+  Max Risk:            %v
+  Blocked Deposits:    %v
+  Blocked Withdrawals: %v
+`, s.Accounts, currencyUnits(s.TotalBalance), currencyUnits(s.MaxBalance), currencyUnits(s.CurrentRisk), currencyUnits(s.MaxRisk), s.BlockedDeposits, s.BlockedWithdrawals)
+	if s.WithdrawalsInactive {
+		fmt.Println("  Withdrawals are inactive until the host is synced.")
+	}
+	if len(hag.Accounts) == 0 {
+		return
+	}
+
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Account ID\tBalance\tMax Balance\tPending Risk\tBlocked Withdrawals\tLast Activity\tExpiry\n")
+	for _, acc := range hag.Accounts {
+		maxBalance := "-"
+		if !acc.MaxBalance.IsZero() {
+			maxBalance = currencyUnits(acc.MaxBalance)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d (%s)\t%s\t%s\n", acc.ID, currencyUnits(acc.Balance), maxBalance, currencyUnits(acc.PendingRisk),
+			acc.BlockedWithdrawals, currencyUnits(acc.BlockedWithdrawalsValue), acc.LastActivity.Format(time.RFC3339), acc.Expiry.Format(time.RFC3339))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostaccountsexpirecmd is the handler for the command `siac host accounts
+// expire [id]`.
+func hostaccountsexpirecmd(idStr string) {
+	var id modules.AccountID
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse account id:", err)
+	}
+	if err := httpClient.HostAccountsExpirePost(id); err != nil {
+		die("Could not expire account:", err)
+	}
+	fmt.Println("Expired account", idStr)
+}
+
+// hostaccountsmaxbalancecmd is the handler for the command `siac host
+// accounts maxbalance [id] [amount]`.
+func hostaccountsmaxbalancecmd(idStr, amount string) {
+	var id modules.AccountID
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse account id:", err)
+	}
+	hastings, err := types.ParseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var maxBalance types.Currency
+	if _, err := fmt.Sscan(hastings, &maxBalance); err != nil {
+		die("Could not parse amount:", err)
+	}
+	if err := httpClient.HostAccountsMaxBalancePost(id, maxBalance); err != nil {
+		die("Could not set the max balance of the account:", err)
+	}
+	fmt.Printf("Set the max balance of account %v to %v\n", idStr, currencyUnits(maxBalance))
+}
+
+// hostregistrycmd is the handler for the command `siac host registry`. It
+// shows the usage statistics of the host's registry.
+func hostregistrycmd() {
+	hrg, err := httpClient.HostRegistryGet()
+	if err != nil {
+		die("Could not fetch registry stats:", err)
+	}
+	s := hrg.Stats
+	var fill float64
+	if s.Capacity > 0 {
+		fill = 100 * float64(s.UsedSlots) / float64(s.Capacity)
+	}
+	fmt.Printf(`Registry:
+  Path:            %v
+  Entries:         %v
+  Expired Entries: %v
+  Capacity:        %v (%v)
+  Used Slots:      %v (%.2f%%)
+  Full Words:      %v of %v
+`, s.Path, s.Entries, s.ExpiredEntries, s.Capacity, modules.FilesizeUnits(s.Capacity*modules.RegistryEntrySize),
+		s.UsedSlots, fill, s.FullBitfieldWords, s.BitfieldWords)
+}
+
+// hostregistryentrycmd is the handler for the command `siac host registry
+// entry [entryid]`.
+func hostregistryentrycmd(eidStr string) {
+	var eid crypto.Hash
+	if err := eid.LoadString(eidStr); err != nil {
+		die("Could not parse entry id:", err)
+	}
+	hreg, err := httpClient.HostRegistryEntryGet(modules.RegistryEntryID(eid))
+	if err != nil {
+		die("Could not fetch registry entry:", err)
+	}
+	e := hreg.Entry
+	fmt.Printf(`Entry ID:   %v
+Public Key: %v
+Tweak:      %v
+Type:       %v
+Revision:   %v
+Expiry:     %v
+Data:       %v
+Signature:  %v
+`, crypto.Hash(e.EntryID), e.PublicKey, e.Tweak, e.Type, e.Revision, e.Expiry, e.Data, e.Signature)
+}
+
+// hostregistryexportcmd is the handler for the command `siac host registry
+// export [destination]`.
+func hostregistryexportcmd(destination string) {
+	hrep, err := httpClient.HostRegistryExportPost(abs(destination))
+	if err != nil {
+		die("Could not export registry:", err)
+	}
+	fmt.Printf("Exported %v registry entries to %v\n", hrep.Exported, destination)
+}
+
+// hostregistryimportcmd is the handler for the command `siac host registry
+// import [source]`.
+func hostregistryimportcmd(source string) {
+	hrip, err := httpClient.HostRegistryImportPost(abs(source))
+	if err != nil {
+		die("Could not import registry:", err)
+	}
+	fmt.Printf("Imported %v registry entries, skipped %v\n", hrip.Imported, hrip.Skipped)
+}
+
+// hostregistryprunecmd is the handler for the command `siac host registry
+// prune`.
+func hostregistryprunecmd() {
+	hrpp, err := httpClient.HostRegistryPrunePost()
+	if err != nil {
+		die("Could not prune registry:", err)
+	}
+	fmt.Printf("Pruned %v expired registry entries\n", hrpp.Pruned)
+}
+
 // hostannouncecmd is the handler for the command `siac host announce`.
 // Announces yourself as a host to the network. Optionally takes an address to
 // announce as.
@@ -531,6 +930,52 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
+// hostfoldercancelcmd cancels the running storage folder operation.
+func hostfoldercancelcmd() {
+	err := httpClient.HostStorageFoldersCancelPost()
+	if err != nil {
+		die("Could not cancel the storage folder operation:", err)
+	}
+	fmt.Println("Cancelled the storage folder operation")
+}
+
+// hostfoldermigratecmd moves all sectors of a storage folder into another
+// one.
+func hostfoldermigratecmd(source, destination string) {
+	maxBandwidth, err := parseRatelimit(hostFolderMaxBandwidth)
+	if err != nil {
+		die("Could not parse max bandwidth:", err)
+	}
+	err = httpClient.HostStorageFoldersMigratePost(abs(source), abs(destination), hostFolderRemoveSource, uint64(maxBandwidth))
+	if err != nil {
+		die("Could not migrate folder:", err)
+	}
+	fmt.Printf("Started migrating folder %v to %v\n", source, destination)
+}
+
+// hostfolderrebalancecmd evens out the free space of the storage folders.
+func hostfolderrebalancecmd() {
+	maxBandwidth, err := parseRatelimit(hostFolderMaxBandwidth)
+	if err != nil {
+		die("Could not parse max bandwidth:", err)
+	}
+	err = httpClient.HostStorageFoldersRebalancePost(uint64(maxBandwidth))
+	if err != nil {
+		die("Could not rebalance folders:", err)
+	}
+	fmt.Println("Started rebalancing the storage folders")
+}
+
+// hostfolderredundancycmd sets the local redundancy mode of the host's
+// storage.
+func hostfolderredundancycmd(mode string) {
+	err := httpClient.HostStorageRedundancyPost(modules.StorageRedundancyMode(mode))
+	if err != nil {
+		die("Could not set the redundancy:", err)
+	}
+	fmt.Printf("Set the redundancy to %v\n", mode)
+}
+
 // hostfolderremovecmd removes a folder from the host.
 func hostfolderremovecmd(path string) {
 	// Ask for confirm for dangerous --force flag
@@ -550,6 +995,15 @@ func hostfolderremovecmd(path string) {
 	fmt.Println("Removed folder", path)
 }
 
+// hostfoldertiercmd sets the tier of a storage folder.
+func hostfoldertiercmd(path, tier string) {
+	err := httpClient.HostStorageFoldersTierPost(abs(path), modules.StorageFolderTier(tier))
+	if err != nil {
+		die("Could not set the tier of the folder:", err)
+	}
+	fmt.Printf("Set the tier of folder %v to %v\n", path, tier)
+}
+
 // hostfolderresizecmd resizes a folder in the host.
 func hostfolderresizecmd(path, newsize string) {
 	newsize, err := parseFilesize(newsize)
@@ -1,11 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -47,6 +51,22 @@ var (
 		Run: hostdbsetfiltermodecmd,
 	}
 
+	hostdbPolicyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: "View the host selection policy.",
+		Long:  "View the host selection policy which is applied on top of the default host scoring.",
+		Run:   wrap(hostdbpolicycmd),
+	}
+
+	hostdbSetPolicyCmd = &cobra.Command{
+		Use:   "setpolicy [file]",
+		Short: "Set the host selection policy.",
+		Long: `Set the host selection policy from a JSON file. Fields which are omitted
+from the file keep their current value. The current policy can be viewed with
+'siac hostdb policy'.`,
+		Run: wrap(hostdbsetpolicycmd),
+	}
+
 	hostdbViewCmd = &cobra.Command{
 		Use:   "view [pubkey]",
 		Short: "View the full information for a host.",
@@ -65,6 +85,8 @@ func printScoreBreakdown(info *api.HostdbHostsGET) {
 	fmt.Fprintf(w, "\t\tCollateral:\t %.3f\n", info.ScoreBreakdown.CollateralAdjustment/1e96)
 	fmt.Fprintf(w, "\t\tDuration:\t %.3f\n", info.ScoreBreakdown.DurationAdjustment)
 	fmt.Fprintf(w, "\t\tInteraction:\t %.3f\n", info.ScoreBreakdown.InteractionAdjustment)
+	fmt.Fprintf(w, "\t\tPerformance:\t %.3f\n", info.ScoreBreakdown.PerformanceAdjustment)
+	fmt.Fprintf(w, "\t\tPolicy:\t %.3f\n", info.ScoreBreakdown.PolicyAdjustment)
 	fmt.Fprintf(w, "\t\tPrice:\t %.3f\n", info.ScoreBreakdown.PriceAdjustment*1e24)
 	fmt.Fprintf(w, "\t\tStorage:\t %.3f\n", info.ScoreBreakdown.StorageRemainingAdjustment)
 	fmt.Fprintf(w, "\t\tUptime:\t %.3f\n", info.ScoreBreakdown.UptimeAdjustment)
@@ -75,6 +97,26 @@ func printScoreBreakdown(info *api.HostdbHostsGET) {
 	}
 }
 
+// printPerformance prints the measured latencies and throughputs of a host.
+func printPerformance(entry modules.HostDBEntry) {
+	// notMeasured is a helper to print metrics without measurements.
+	notMeasured := func(measured bool, value string) string {
+		if !measured {
+			return "not measured"
+		}
+		return value
+	}
+	fmt.Println("\n  Performance:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\t\tRTT:\t", notMeasured(entry.RTT > 0, entry.RTT.Round(time.Millisecond).String()))
+	fmt.Fprintln(w, "\t\tPrice Table Latency:\t", notMeasured(entry.PriceTableLatency > 0, entry.PriceTableLatency.Round(time.Millisecond).String()))
+	fmt.Fprintln(w, "\t\tUpload Throughput:\t", notMeasured(entry.UploadThroughput > 0, ratelimitUnits(int64(entry.UploadThroughput))))
+	fmt.Fprintln(w, "\t\tDownload Throughput:\t", notMeasured(entry.DownloadThroughput > 0, ratelimitUnits(int64(entry.DownloadThroughput))))
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
 // hostdbcmd is the handler for the command `siac hostdb`.
 // Lists hosts known to the hostdb
 func hostdbcmd() {
@@ -379,6 +421,47 @@ func hostdbsetfiltermodecmd(cmd *cobra.Command, args []string) {
 	fmt.Println("Successfully set the filter mode")
 }
 
+// hostdbpolicycmd is the handler for the command `siac hostdb policy`.
+func hostdbpolicycmd() {
+	hdpg, err := httpClient.HostDbPolicyGet()
+	if err != nil {
+		die("Could not fetch host selection policy:", err)
+	}
+	js, err := json.MarshalIndent(hdpg.Policy, "", "  ")
+	if err != nil {
+		die("Could not marshal host selection policy:", err)
+	}
+	fmt.Println(string(js))
+}
+
+// hostdbsetpolicycmd is the handler for the command `siac hostdb setpolicy`.
+// Sets the host selection policy from a JSON file.
+func hostdbsetpolicycmd(path string) {
+	policyBytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read policy file:", err)
+	}
+	// Decode the file on top of the current policy to only update the
+	// specified fields.
+	hdpg, err := httpClient.HostDbPolicyGet()
+	if err != nil {
+		die("Could not fetch host selection policy:", err)
+	}
+	policy := hdpg.Policy
+	dec := json.NewDecoder(bytes.NewReader(policyBytes))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&policy); err != nil {
+		die("Could not parse policy file:", err)
+	}
+	if err := policy.Validate(); err != nil {
+		die("Invalid host selection policy:", err)
+	}
+	if err := httpClient.HostDbPolicyPost(policy); err != nil {
+		die("Could not set host selection policy:", err)
+	}
+	fmt.Println("Successfully set the host selection policy")
+}
+
 // hostdbviewcmd is the handler for the command `siac hostdb view`.
 // shows detailed information about a host in the hostdb.
 func hostdbviewcmd(pubkey string) {
@@ -426,6 +509,7 @@ func hostdbviewcmd(pubkey string) {
 	}
 
 	printScoreBreakdown(&info)
+	printPerformance(info.Entry.HostDBEntry)
 
 	// Compute the total measured uptime and total measured downtime for this
 	// host.
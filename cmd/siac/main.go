@@ -21,6 +21,12 @@ var (
 	siaDir        string // Path to sia data dir
 	verbose       bool   // Display additional information
 
+	// API TLS Flags
+	apiTLS     bool   // Connect to the API over TLS
+	apiTLSCA   string // CA certificate the API certificate is verified against
+	apiTLSCert string // Client certificate to authenticate with
+	apiTLSKey  string // Key of the client certificate
+
 	// Module Specific Flags
 	//
 	// Daemon Flags
@@ -30,15 +36,29 @@ var (
 	daemonProfileDirectory string // The Directory where the profile logs are saved
 	daemonTraceProfile     bool   // Indicates that the Trace profile should be started
 
+	// API Token Flags
+	tokenPathPrefixes string // Comma separated siapath prefixes a token is restricted to
+	tokenScopes       string // Comma separated scopes of a token
+
 	// Host Flags
 	hostContractOutputType string // output type for host contracts
+	hostExportEndHeight    uint64 // last reporting height of a contract export
+	hostExportEndTime      string // last day or timestamp of a contract export
+	hostExportFormat       string // format of a contract export
+	hostExportRates        string // exchange rate table file of a contract export
+	hostExportStartHeight  uint64 // first reporting height of a contract export
+	hostExportStartTime    string // first day or timestamp of a contract export
+	hostExportUnresolved   bool   // whether to export unresolved contracts
+	hostFolderMaxBandwidth string // max bandwidth of a folder migration or rebalance
 	hostFolderRemoveForce  bool   // force folder remove
+	hostFolderRemoveSource bool   // remove the source folder after a migration
 
 	// Renter Flags
 	dataPieces                string // the number of data pieces a file should be uploaded with
 	parityPieces              string // the number of parity pieces a file should be uploaded with
 	renterAllContracts        bool   // Show all active and expired contracts
 	renterBubbleAll           bool   // Bubble the entire directory tree
+	renterDeleteRecursive     bool   // Match wildcards against files in subfolders.
 	renterDeleteRoot          bool   // Delete path start from root instead of the UserFolder.
 	renterDownloadAsync       bool   // Downloads files asynchronously
 	renterDownloadRecursive   bool   // Downloads folders recursively.
@@ -46,8 +66,26 @@ var (
 	renterFuseMountAllowOther bool   // Mount fuse with 'AllowOther' set to true.
 	renterListRecursive       bool   // List files of folder recursively.
 	renterListRoot            bool   // List path start from root instead of the UserFolder.
+	renterReencodeCipherType  string // Cipher type a file should be re-encoded with.
+	renterReencodeRecursive   bool   // Re-encode all files of a folder recursively.
 	renterRenameRoot          bool   // Rename files relative to root instead of the UserFolder.
 	renterShowHistory         bool   // Show download history in addition to download queue.
+	renterVerifyRecursive     bool   // Verify all files of a folder recursively.
+	renterVerifyRepair        bool   // Remove unreadable pieces to have them repaired.
+	renterVerifySamples       uint64 // Number of pieces sampled per host and file.
+
+	// Renter Contract Event Flags
+	renterContractEventsHost      string // Only show contract events for this host
+	renterContractEventsID        string // Only show contract events for this contract
+	renterContractEventsLimit     int    // Maximum number of contract events to show
+	renterContractEventsMaxHeight uint64 // Only show contract events up to this height
+	renterContractEventsMinHeight uint64 // Only show contract events from this height
+	renterContractEventsType      string // Only show contract events of these types
+
+	// Renter Tenant Flags
+	renterTenantDownloadQuota string // download quota of a tenant per period
+	renterTenantStorageQuota  string // storage quota of a tenant
+	renterTenantUploadQuota   string // upload quota of a tenant per period
 
 	// Renter Allowance Flags
 	allowanceFunds       string // amount of money to be used within a period
@@ -67,6 +105,13 @@ var (
 	allowanceMaxStoragePrice           string // max allowed price to store data on a host
 	allowanceMaxUploadBandwidthPrice   string // max allowed price to upload data to a host
 
+	allowanceMaxDownloadSpending    string // max spending on downloads within a period
+	allowanceMaxFeeSpending         string // max spending on contract fees within a period
+	allowanceMaxFundAccountSpending string // max spending on funding ephemeral accounts within a period
+	allowanceMaxStorageSpending     string // max spending on storage within a period
+	allowanceMaxUploadSpending      string // max spending on uploads within a period
+	allowanceSpendingAlertThreshold string // fraction of a spending cap at which an alert is raised
+
 	// Skykey Flags
 	skykeyID              string // ID used to identify a Skykey.
 	skykeyName            string // Name used to identify a Skykey.
@@ -254,6 +299,9 @@ func main() {
 		// set API password if it was not set
 		setAPIPasswordIfNotSet()
 
+		// set the TLS config of the client
+		setAPITLSConfig()
+
 		// Check if the siaDir is set.
 		if siaDir == "" {
 			// No siaDir passed in, fetch the siaDir
@@ -297,14 +345,26 @@ func initCmds() *cobra.Command {
 	gatewayBlocklistCmd.AddCommand(gatewayBlocklistAppendCmd, gatewayBlocklistClearCmd, gatewayBlocklistRemoveCmd, gatewayBlocklistSetCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostAnnounceCmd, hostConfigCmd, hostContractCmd, hostFolderCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostCmd.AddCommand(hostAccountsCmd, hostAnnounceCmd, hostConfigCmd, hostContractCmd, hostExportCmd, hostFolderCmd, hostRegistryCmd, hostSectorCmd)
+	hostAccountsCmd.AddCommand(hostAccountsExpireCmd, hostAccountsMaxBalanceCmd)
+	hostRegistryCmd.AddCommand(hostRegistryEntryCmd, hostRegistryExportCmd, hostRegistryImportCmd, hostRegistryPruneCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderCancelCmd, hostFolderMigrateCmd, hostFolderRebalanceCmd, hostFolderRedundancyCmd, hostFolderRemoveCmd, hostFolderResizeCmd, hostFolderTierCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostExportCmd.Flags().Uint64Var(&hostExportEndHeight, "end-height", 0, "Last reporting height to export, 0 for no limit")
+	hostExportCmd.Flags().StringVar(&hostExportEndTime, "end-time", "", "Last day (YYYY-MM-DD) or unix timestamp to export")
+	hostExportCmd.Flags().StringVarP(&hostExportFormat, "format", "f", "csv", "Export format, csv or json")
+	hostExportCmd.Flags().StringVar(&hostExportRates, "rates", "", "CSV file with an exchange rate table of day (YYYY-MM-DD) or unix timestamp and rate, e.g. 2021-01-01,0.005 usd")
+	hostExportCmd.Flags().Uint64Var(&hostExportStartHeight, "start-height", 0, "First reporting height to export")
+	hostExportCmd.Flags().StringVar(&hostExportStartTime, "start-time", "", "First day (YYYY-MM-DD) or unix timestamp to export")
+	hostExportCmd.Flags().BoolVar(&hostExportUnresolved, "unresolved", false, "Export unresolved contracts with their potential revenue")
+	hostFolderMigrateCmd.Flags().StringVar(&hostFolderMaxBandwidth, "max-bandwidth", "0", "Max bandwidth of the migration, e.g. 100MB/s, 0 for no limit")
+	hostFolderMigrateCmd.Flags().BoolVar(&hostFolderRemoveSource, "remove-source", false, "Remove the source folder once all of its data was moved")
+	hostFolderRebalanceCmd.Flags().StringVar(&hostFolderMaxBandwidth, "max-bandwidth", "0", "Max bandwidth of the rebalance, e.g. 100MB/s, 0 for no limit")
 	hostFolderRemoveCmd.Flags().BoolVarP(&hostFolderRemoveForce, "force", "f", false, "Force the removal of the folder and its data")
 
 	root.AddCommand(hostdbCmd)
-	hostdbCmd.AddCommand(hostdbFiltermodeCmd, hostdbSetFiltermodeCmd, hostdbViewCmd)
+	hostdbCmd.AddCommand(hostdbFiltermodeCmd, hostdbSetFiltermodeCmd, hostdbPolicyCmd, hostdbSetPolicyCmd, hostdbViewCmd)
 	hostdbCmd.Flags().IntVarP(&hostdbNumHosts, "numhosts", "n", 0, "Number of hosts to display from the hostdb")
 
 	root.AddCommand(minerCmd)
@@ -314,19 +374,32 @@ func initCmds() *cobra.Command {
 	renterCmd.AddCommand(renterAllowanceCmd, renterBubbleCmd, renterBackupCreateCmd, renterBackupListCmd, renterBackupLoadCmd,
 		renterCleanCmd, renterContractsCmd, renterContractsRecoveryScanProgressCmd, renterDownloadCancelCmd,
 		renterDownloadsCmd, renterExportCmd, renterFilesDeleteCmd, renterFilesDownloadCmd,
-		renterFilesListCmd, renterFilesRenameCmd, renterFilesUnstuckCmd, renterFilesUploadCmd,
-		renterFuseCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterSetAllowanceCmd,
-		renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
+		renterFilesListCmd, renterFilesReencodeCmd, renterFilesRenameCmd, renterFilesUnstuckCmd, renterFilesUploadCmd,
+		renterFilesVerifyCmd, renterFuseCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterSetAllowanceCmd,
+		renterSetLocalPathCmd, renterTenantsCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
 		renterHealthSummaryCmd)
 	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersDownloadsCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
 
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
 	renterBubbleCmd.Flags().BoolVarP(&renterBubbleAll, "all", "A", false, "Bubble the entire directory tree")
-	renterContractsCmd.AddCommand(renterContractsViewCmd)
+	renterContractsCmd.AddCommand(renterContractsEventsCmd, renterContractsViewCmd)
 	renterFilesUploadCmd.AddCommand(renterFilesUploadPauseCmd, renterFilesUploadResumeCmd)
+	renterTenantsCmd.AddCommand(renterTenantsCreateCmd, renterTenantsDeleteCmd, renterTenantsQuotaCmd)
+	for _, cmd := range []*cobra.Command{renterTenantsCreateCmd, renterTenantsQuotaCmd} {
+		cmd.Flags().StringVar(&renterTenantDownloadQuota, "download", "", "download quota per period in bytes (B), kilobytes (KB), megabytes (MB) etc., 0B for no quota")
+		cmd.Flags().StringVar(&renterTenantStorageQuota, "storage", "", "storage quota in bytes (B), kilobytes (KB), megabytes (MB) etc., 0B for no quota")
+		cmd.Flags().StringVar(&renterTenantUploadQuota, "upload", "", "upload quota per period in bytes (B), kilobytes (KB), megabytes (MB) etc., 0B for no quota")
+	}
 
 	renterContractsCmd.Flags().BoolVarP(&renterAllContracts, "all", "A", false, "Show all expired contracts in addition to active contracts")
+	renterContractsEventsCmd.Flags().StringVar(&renterContractEventsHost, "host", "", "Only show events for the host with this public key")
+	renterContractsEventsCmd.Flags().StringVar(&renterContractEventsID, "id", "", "Only show events for this contract")
+	renterContractsEventsCmd.Flags().IntVarP(&renterContractEventsLimit, "limit", "n", 0, "Only show the most recent events")
+	renterContractsEventsCmd.Flags().Uint64Var(&renterContractEventsMaxHeight, "max-height", 0, "Only show events up to this block height")
+	renterContractsEventsCmd.Flags().Uint64Var(&renterContractEventsMinHeight, "min-height", 0, "Only show events from this block height")
+	renterContractsEventsCmd.Flags().StringVarP(&renterContractEventsType, "type", "t", "", "Comma separated list of event types to show")
 	renterDownloadsCmd.Flags().BoolVarP(&renterShowHistory, "history", "H", false, "Show download history in addition to the download queue")
+	renterFilesDeleteCmd.Flags().BoolVarP(&renterDeleteRecursive, "recursive", "R", false, "Match wildcards against the names of files in subfolders as well")
 	renterFilesDeleteCmd.Flags().BoolVar(&renterDeleteRoot, "root", false, "Delete files and folders from root instead of from the user home directory")
 	renterFilesDownloadCmd.Flags().BoolVarP(&renterDownloadAsync, "async", "A", false, "Download file asynchronously")
 	renterFilesDownloadCmd.Flags().BoolVarP(&renterDownloadRecursive, "recursive", "R", false, "Download folder recursively")
@@ -336,6 +409,13 @@ func initCmds() *cobra.Command {
 	renterFilesUploadCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces a files should be uploaded with")
 	renterFilesUploadCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces a files should be uploaded with")
 	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
+	renterFilesReencodeCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces the file should be re-encoded with")
+	renterFilesReencodeCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces the file should be re-encoded with")
+	renterFilesReencodeCmd.Flags().StringVar(&renterReencodeCipherType, "cipher-type", "", "the cipher type the file should be re-encoded with")
+	renterFilesReencodeCmd.Flags().BoolVarP(&renterReencodeRecursive, "recursive", "R", false, "Re-encode all files of a folder recursively")
+	renterFilesVerifyCmd.Flags().BoolVarP(&renterVerifyRecursive, "recursive", "R", false, "Verify all files of a folder recursively")
+	renterFilesVerifyCmd.Flags().BoolVar(&renterVerifyRepair, "repair", false, "Remove corrupt pieces from their files to have them repaired")
+	renterFilesVerifyCmd.Flags().Uint64Var(&renterVerifySamples, "samples", modules.DefaultVerificationSamples, "the number of pieces to sample per host and file")
 	renterFilesRenameCmd.Flags().BoolVar(&renterRenameRoot, "root", false, "Rename files relative to root instead of the user homedir")
 
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceFunds, "amount", "", "amount of money in allowance, specified in currency units")
@@ -352,12 +432,19 @@ func initCmds() *cobra.Command {
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxSectorAccessPrice, "max-sector-access-price", "", "the maximum price that the renter will pay to access a sector on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxStoragePrice, "max-storage-price", "", "the maximum price that the renter will pay to store data on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxUploadBandwidthPrice, "max-upload-bandwidth-price", "", "the maximum price that the renter will pay to upload data to a host")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxUploadSpending, "max-upload-spending", "", "the maximum amount of money spent on uploads within a period, 0SC for no cap")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxDownloadSpending, "max-download-spending", "", "the maximum amount of money spent on downloads within a period, 0SC for no cap")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxStorageSpending, "max-storage-spending", "", "the maximum amount of money spent on storage within a period, 0SC for no cap")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxFeeSpending, "max-fee-spending", "", "the maximum amount of money spent on contract fees within a period, 0SC for no cap")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxFundAccountSpending, "max-fund-account-spending", "", "the maximum amount of money spent on funding ephemeral accounts within a period, 0SC for no cap")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceSpendingAlertThreshold, "spending-alert-threshold", "", "the fraction of a spending cap at which an alert is raised, e.g. 0.8")
 
 	renterFuseCmd.AddCommand(renterFuseMountCmd, renterFuseUnmountCmd)
 	renterFuseMountCmd.Flags().BoolVarP(&renterFuseMountAllowOther, "allow-other", "", false, "Allow users other than the user that mounted the fuse directory to access and use the fuse directory")
 
 	// Daemon Commands
-	root.AddCommand(alertsCmd, globalRatelimitCmd, profileCmd, stackCmd, stopCmd, updateCmd, versionCmd)
+	root.AddCommand(alertsCmd, globalRatelimitCmd, logLevelsCmd, profileCmd, stackCmd, stopCmd, tokensCmd, updateCmd, versionCmd)
+	logLevelsCmd.AddCommand(logLevelsResetCmd, logLevelsSetCmd)
 	profileCmd.AddCommand(profileStartCmd, profileStopCmd)
 	profileStartCmd.Flags().BoolVarP(&daemonCPUProfile, "cpu", "c", false, "Start the CPU profile")
 	profileStartCmd.Flags().BoolVarP(&daemonMemoryProfile, "memory", "m", false, "Start the Memory profile")
@@ -365,6 +452,9 @@ func initCmds() *cobra.Command {
 	profileStartCmd.Flags().BoolVarP(&daemonTraceProfile, "trace", "t", false, "Start the Trace profile")
 	stackCmd.Flags().StringVarP(&daemonStackOutputFile, "filename", "f", "stack.txt", "Specify the output file for the stack trace")
 	updateCmd.AddCommand(updateCheckCmd)
+	tokensCmd.AddCommand(tokensCreateCmd, tokensRevokeCmd)
+	tokensCreateCmd.Flags().StringVar(&tokenPathPrefixes, "path-prefixes", "", "Comma separated siapaths the token's renter file requests are restricted to")
+	tokensCreateCmd.Flags().StringVar(&tokenScopes, "scopes", "", "Comma separated scopes of the token: read-only, renter-files, local-files, wallet-spend, host-admin and daemon-admin")
 
 	root.AddCommand(utilsCmd)
 	utilsCmd.AddCommand(bashcomplCmd, mangenCmd, utilsBruteForceSeedCmd, utilsCheckSigCmd,
@@ -408,6 +498,24 @@ func initClient(root *cobra.Command, verbose *bool, client *client.Client, siaDi
 	root.PersistentFlags().StringVarP(siaDir, "sia-directory", "d", "", "location of the sia directory")
 	root.PersistentFlags().StringVarP(&client.UserAgent, "useragent", "", "Sia-Agent", "the useragent used by siac to connect to the daemon's API")
 	root.PersistentFlags().BoolVarP(alertSuppress, "alert-suppress", "s", false, "suppress siac alerts")
+	root.PersistentFlags().BoolVarP(&apiTLS, "api-tls", "", false, "connect to the API over TLS, implied by the other --api-tls flags")
+	root.PersistentFlags().StringVarP(&apiTLSCA, "api-tls-ca", "", "", "verify the API certificate against this PEM encoded CA certificate")
+	root.PersistentFlags().StringVarP(&apiTLSCert, "api-tls-cert", "", "", "authenticate with this PEM encoded client certificate")
+	root.PersistentFlags().StringVarP(&apiTLSKey, "api-tls-key", "", "", "PEM encoded key of the client certificate")
+}
+
+// setAPITLSConfig sets the TLS config of the client if any of the TLS flags
+// were used.
+func setAPITLSConfig() {
+	if !apiTLS && apiTLSCA == "" && apiTLSCert == "" && apiTLSKey == "" {
+		return
+	}
+	tlsConfig, err := client.NewTLSConfig(apiTLSCA, apiTLSCert, apiTLSKey)
+	if err != nil {
+		fmt.Println("Exiting: Error creating API TLS config:", err)
+		os.Exit(exitCodeGeneral)
+	}
+	httpClient.TLSConfig = tlsConfig
 }
 
 // setAPIPasswordIfNotSet sets API password if it was not set
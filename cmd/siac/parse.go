@@ -314,6 +314,15 @@ func ratelimitUnits(ratelimit int64) string {
 	return fmt.Sprintf("%.4g %s", float64(ratelimit)/mag, unit)
 }
 
+// bandwidthLimitUnits converts a bandwidth limit in bytes per second to a
+// human-readable string. A limit of 0 means that the bandwidth is unlimited.
+func bandwidthLimitUnits(bps uint64) string {
+	if bps == 0 {
+		return "unlimited"
+	}
+	return ratelimitUnits(int64(bps))
+}
+
 // yesNo returns "Yes" if b is true, and "No" if b is false.
 func yesNo(b bool) string {
 	if b {
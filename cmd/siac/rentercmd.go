@@ -22,6 +22,7 @@ import (
 
 	"gitlab.com/NebulousLabs/errors"
 	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/filesystem"
 	"go.thebigfile.com/bigd/node/api"
@@ -30,6 +31,10 @@ import (
 )
 
 const (
+	// bulkWildcardChars are the characters which turn a path into a pattern
+	// which is matched against the files of a folder.
+	bulkWildcardChars = "*?["
+
 	fileSizeUnits = "B, KB, MB, GB, TB, PB, EB, ZB, YB"
 
 	// truncateErrLength is the length at which an error string gets truncated
@@ -110,6 +115,18 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Run:   wrap(rentercontractscmd),
 	}
 
+	renterContractsEventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "View the contract event journal",
+		Long: `View the events recorded for the Renter's contracts. Events are recorded
+when contracts are formed, renewed, refreshed, marked as not good for upload
+or renew, churned, or when the watchdog finds a double-spend.
+
+Valid event types are formed, renewed, refreshed, notgoodforupload,
+notgoodforrenew, churned and doublespend.`,
+		Run: wrap(rentercontractseventscmd),
+	}
+
 	renterContractsRecoveryScanProgressCmd = &cobra.Command{
 		Use:   "recoveryscanprogress",
 		Short: "Returns the recovery scan progress.",
@@ -142,8 +159,12 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Use:     "delete [path]",
 		Aliases: []string{"rm"},
 		Short:   "Delete a file or folder",
-		Long:    "Delete a file or folder. Does not delete the file/folder on disk.  Multiple files may be deleted with space separation.",
-		Run:     renterfilesdeletecmd,
+		Long: `Delete a file or folder. Does not delete the file/folder on disk. Multiple
+files may be deleted with space separation. Wildcards in the last element of
+a path delete all matching files of the folder, use --recursive to match the
+names of files in subfolders as well. Quote paths with wildcards to keep the
+shell from expanding them.`,
+		Run: renterfilesdeletecmd,
 	}
 
 	renterFilesDownloadCmd = &cobra.Command{
@@ -164,8 +185,32 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Use:     "rename [path] [newpath]",
 		Aliases: []string{"mv"},
 		Short:   "Rename a file",
-		Long:    "Rename a file.",
-		Run:     wrap(renterfilesrenamecmd),
+		Long: `Rename a file. Wildcards in the last element of the path move all matching
+files of the folder into the folder [newpath].`,
+		Run: wrap(renterfilesrenamecmd),
+	}
+
+	renterFilesReencodeCmd = &cobra.Command{
+		Use:   "reencode [path]",
+		Short: "Migrate a file to new redundancy or encryption settings",
+		Long: `Migrate a file to new redundancy or encryption settings by re-uploading its
+data chunk by chunk. The --data-pieces and --parity-pieces flags set the new
+erasure code and the --cipher-type flag sets the new cipher. Settings which
+aren't provided remain unchanged. Use --recursive to migrate all files within
+a folder. The migration runs in the background.`,
+		Run: wrap(renterfilesreencodecmd),
+	}
+
+	renterFilesVerifyCmd = &cobra.Command{
+		Use:   "verify [path]",
+		Short: "Verify that a file's data can be retrieved from its hosts",
+		Long: `Verify that a file's data can be retrieved from its hosts by downloading a
+random sample of segments from every host and checking them against the file's
+merkle roots. The results update the hosts' trust scores. Use --recursive to
+verify all files within a folder ('.' for all files) and --repair to have
+corrupt pieces repaired. Pieces which couldn't be downloaded are reported as
+unverified and are never removed.`,
+		Run: wrap(renterfilesverifycmd),
 	}
 
 	renterFuseCmd = &cobra.Command{
@@ -201,6 +246,39 @@ local path where the Sia folder is mounted.`,
 		Run:   wrap(rentersetlocalpathcmd),
 	}
 
+	renterTenantsCmd = &cobra.Command{
+		Use:   "tenants",
+		Short: "View the renter's tenants",
+		Long: `View the tenants of the Renter together with their quotas and usage.
+Tenants share the Renter's contracts but have their own root directory, API
+credentials and quotas. Tenants authenticate to the API with their name as
+the username and their token as the password.`,
+		Run: wrap(rentertenantscmd),
+	}
+
+	renterTenantsCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a tenant",
+		Long: `Create a new tenant and print its API token. The token can't be
+retrieved again later. Names may only contain lowercase letters, digits, '-'
+and '_'.`,
+		Run: wrap(rentertenantscreatecmd),
+	}
+
+	renterTenantsDeleteCmd = &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a tenant",
+		Long:  "Delete a tenant. The files within the tenant's root directory are not deleted.",
+		Run:   wrap(rentertenantsdeletecmd),
+	}
+
+	renterTenantsQuotaCmd = &cobra.Command{
+		Use:   "quota [name]",
+		Short: "Update the quota of a tenant",
+		Long:  "Update the quota of a tenant. Quotas which aren't specified are left unchanged.",
+		Run:   wrap(rentertenantsquotacmd),
+	}
+
 	renterFilesUnstuckCmd = &cobra.Command{
 		Use:   "unstuckall",
 		Short: "Set all files to unstuck",
@@ -611,6 +689,14 @@ Price Protections:
   MaxSectorAccessPrice:      %v per million accesses
   MaxStoragePrice:           %v per TB per Month
   MaxUploadBandwidthPrice:   %v per TB
+
+Spending Caps:
+  MaxUploadSpending:         %v
+  MaxDownloadSpending:       %v
+  MaxStorageSpending:        %v
+  MaxFeeSpending:            %v
+  MaxFundAccountSpending:    %v
+  SpendingAlertThreshold:    %v
 `, currencyUnitsWithExchangeRate(allowance.Funds, rate), allowance.Period, allowance.RenewWindow,
 		allowance.Hosts,
 		modules.FilesizeUnits(allowance.ExpectedStorage),
@@ -622,7 +708,13 @@ Price Protections:
 		currencyUnits(allowance.MaxDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 		currencyUnits(allowance.MaxSectorAccessPrice.Mul64(1e6)),
 		currencyUnits(allowance.MaxStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
-		currencyUnits(allowance.MaxUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)))
+		currencyUnits(allowance.MaxUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+		spendingCapStr(allowance.MaxUploadSpending),
+		spendingCapStr(allowance.MaxDownloadSpending),
+		spendingCapStr(allowance.MaxStorageSpending),
+		spendingCapStr(allowance.MaxFeeSpending),
+		spendingCapStr(allowance.MaxFundAccountSpending),
+		spendingAlertThresholdStr(allowance.SpendingAlertThreshold))
 
 	// Show detailed current Period spending metrics
 	renterallowancespending(rg)
@@ -642,6 +734,24 @@ Price Protections:
 	}
 }
 
+// spendingCapStr returns the string representation of an allowance spending
+// cap.
+func spendingCapStr(spendingCap types.Currency) string {
+	if spendingCap.IsZero() {
+		return "none"
+	}
+	return currencyUnits(spendingCap)
+}
+
+// spendingAlertThresholdStr returns the string representation of the
+// allowance's spending alert threshold.
+func spendingAlertThresholdStr(threshold float64) string {
+	if threshold == 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%.0f%%", threshold*100)
+}
+
 // renterallowancecancelcmd is the handler for `siac renter allowance cancel`.
 // cancels the current allowance.
 func renterallowancecancelcmd() {
@@ -862,6 +972,43 @@ func rentersetallowancecmd(_ *cobra.Command, _ []string) {
 		req = req.WithMaxUploadBandwidthPrice(price)
 		changedFields++
 	}
+	// parse the spending caps
+	spendingCaps := []struct {
+		name  string
+		value string
+		set   func(types.Currency) *client.AllowanceRequestPost
+	}{
+		{"upload", allowanceMaxUploadSpending, req.WithMaxUploadSpending},
+		{"download", allowanceMaxDownloadSpending, req.WithMaxDownloadSpending},
+		{"storage", allowanceMaxStorageSpending, req.WithMaxStorageSpending},
+		{"fee", allowanceMaxFeeSpending, req.WithMaxFeeSpending},
+		{"fund account", allowanceMaxFundAccountSpending, req.WithMaxFundAccountSpending},
+	}
+	for _, sc := range spendingCaps {
+		if sc.value == "" {
+			continue
+		}
+		hastings, err := types.ParseCurrency(sc.value)
+		if err != nil {
+			die(fmt.Sprintf("Could not parse max %v spending:", sc.name), err)
+		}
+		var spending types.Currency
+		_, err = fmt.Sscan(hastings, &spending)
+		if err != nil {
+			die(fmt.Sprintf("Could not read max %v spending:", sc.name), err)
+		}
+		req = sc.set(spending)
+		changedFields++
+	}
+	// parse spendingalertthreshold
+	if allowanceSpendingAlertThreshold != "" {
+		threshold, err := strconv.ParseFloat(allowanceSpendingAlertThreshold, 64)
+		if err != nil {
+			die("Could not parse spending alert threshold:", err)
+		}
+		req = req.WithSpendingAlertThreshold(threshold)
+		changedFields++
+	}
 
 	// check if any fields were updated.
 	if changedFields == 0 {
@@ -1522,6 +1669,144 @@ func rentercontractsviewcmd(cid string) {
 	}
 }
 
+// rentercontractseventscmd is the handler for the command `siac renter
+// contracts events`. It lists the events from the contract event journal.
+func rentercontractseventscmd() {
+	filter := modules.ContractEventFilter{
+		MinHeight: types.BlockHeight(renterContractEventsMinHeight),
+		MaxHeight: types.BlockHeight(renterContractEventsMaxHeight),
+		Limit:     renterContractEventsLimit,
+	}
+	if renterContractEventsType != "" {
+		for _, t := range strings.Split(renterContractEventsType, ",") {
+			filter.Types = append(filter.Types, modules.ContractEventType(strings.TrimSpace(t)))
+		}
+	}
+	if renterContractEventsID != "" {
+		if err := filter.ContractID.LoadString(renterContractEventsID); err != nil {
+			die("Could not parse contract id:", err)
+		}
+	}
+	if renterContractEventsHost != "" {
+		if err := filter.HostPublicKey.LoadString(renterContractEventsHost); err != nil {
+			die("Could not parse host public key:", err)
+		}
+	}
+	rce, err := httpClient.RenterContractEventsGet(filter)
+	if err != nil {
+		die("Could not get contract events:", err)
+	}
+	if len(rce.Events) == 0 {
+		fmt.Println("No contract events.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Height\tTime\tEvent\tContract ID\tHost Public Key\tFunds\tFees\tSize\tReason")
+	for _, e := range rce.Events {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", e.BlockHeight, e.Timestamp.Format(time.RFC3339), e.Type,
+			e.ContractID, e.HostPublicKey, currencyUnits(e.Funds), currencyUnits(e.Fees), modules.FilesizeUnits(e.Size), e.Reason)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentertenantscmd is the handler for the command `siac renter tenants`. It
+// lists the renter's tenants.
+func rentertenantscmd() {
+	rt, err := httpClient.RenterTenantsGet()
+	if err != nil {
+		die("Could not get tenants:", err)
+	}
+	if len(rt.Tenants) == 0 {
+		fmt.Println("No tenants.")
+		return
+	}
+	quotaStr := func(quota uint64) string {
+		if quota == 0 {
+			return "unlimited"
+		}
+		return modules.FilesizeUnits(quota)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Name\tRoot\tStorage\tUploaded\tDownloaded\tStorage Quota\tUpload Quota\tDownload Quota\tAttributed Spending")
+	for _, ti := range rt.Tenants {
+		spending := ti.Usage.StorageSpending.Add(ti.Usage.UploadSpending).Add(ti.Usage.DownloadSpending)
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", ti.Name, ti.Root, modules.FilesizeUnits(ti.Usage.Storage),
+			modules.FilesizeUnits(ti.Usage.Uploaded), modules.FilesizeUnits(ti.Usage.Downloaded), quotaStr(ti.Quota.Storage),
+			quotaStr(ti.Quota.Upload), quotaStr(ti.Quota.Download), currencyUnits(spending))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// parseTenantQuotaFlags applies the quota flags of the tenant commands to the
+// provided quota.
+func parseTenantQuotaFlags(quota modules.TenantQuota) modules.TenantQuota {
+	flags := []struct {
+		value string
+		field *uint64
+	}{
+		{renterTenantDownloadQuota, &quota.Download},
+		{renterTenantStorageQuota, &quota.Storage},
+		{renterTenantUploadQuota, &quota.Upload},
+	}
+	for _, f := range flags {
+		if f.value == "" {
+			continue
+		}
+		size, err := parseFilesize(f.value)
+		if err != nil {
+			die("Could not parse quota:", err)
+		}
+		if _, err := fmt.Sscan(size, f.field); err != nil {
+			die("Could not parse quota:", err)
+		}
+	}
+	return quota
+}
+
+// rentertenantscreatecmd is the handler for the command `siac renter tenants
+// create [name]`. It creates a tenant and prints its token.
+func rentertenantscreatecmd(name string) {
+	rtc, err := httpClient.RenterTenantsCreatePost(name, parseTenantQuotaFlags(modules.TenantQuota{}))
+	if err != nil {
+		die("Could not create tenant:", err)
+	}
+	fmt.Printf("Created tenant %v with root directory %v.\n", rtc.Name, rtc.Root)
+	fmt.Println("Token:", rtc.Token)
+}
+
+// rentertenantsdeletecmd is the handler for the command `siac renter tenants
+// delete [name]`. It deletes a tenant.
+func rentertenantsdeletecmd(name string) {
+	if err := httpClient.RenterTenantsDeletePost(name); err != nil {
+		die("Could not delete tenant:", err)
+	}
+	fmt.Printf("Deleted tenant %v.\n", name)
+}
+
+// rentertenantsquotacmd is the handler for the command `siac renter tenants
+// quota [name]`. It updates the quota of a tenant.
+func rentertenantsquotacmd(name string) {
+	rt, err := httpClient.RenterTenantsGet()
+	if err != nil {
+		die("Could not get tenants:", err)
+	}
+	for _, ti := range rt.Tenants {
+		if ti.Name != name {
+			continue
+		}
+		if err := httpClient.RenterTenantsQuotaPost(name, parseTenantQuotaFlags(ti.Quota)); err != nil {
+			die("Could not update quota:", err)
+		}
+		fmt.Printf("Updated quota of tenant %v.\n", name)
+		return
+	}
+	die("Could not update quota:", modules.ErrUnknownTenant)
+}
+
 // renterfilesdownload downloads the dir at the given path from the Sia network
 // to the local specified destination.
 func renterdirdownload(path, destination string) {
@@ -1579,52 +1864,59 @@ func renterdownloadcancelcmd(cancelID modules.DownloadID) {
 	fmt.Println("Download canceled successfully")
 }
 
+// parseBulkPath parses a path which may contain wildcards in its last
+// element. Paths with wildcards are split into the folder the files are
+// selected from and the glob the names of the files are matched against.
+func parseBulkPath(path string) (modules.SiaPath, string, error) {
+	if !strings.ContainsAny(path, bulkWildcardChars) {
+		siaPath, err := modules.NewSiaPath(path)
+		return siaPath, "", err
+	}
+	dir, glob := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		dir, glob = path[:i], path[i+1:]
+	}
+	if strings.ContainsAny(dir, bulkWildcardChars) {
+		return modules.SiaPath{}, "", errors.New("wildcards are only supported in the last element of a path")
+	}
+	if dir == "" {
+		return modules.RootSiaPath(), glob, nil
+	}
+	siaPath, err := modules.NewSiaPath(dir)
+	return siaPath, glob, err
+}
+
 // renterfilesdeletecmd is the handler for the command `siac renter delete [path]`.
 // Removes the specified path from the Sia network.
 func renterfilesdeletecmd(cmd *cobra.Command, paths []string) {
+	// Delete all paths within a single request.
+	ops := make([]modules.BulkOperation, 0, len(paths))
 	for _, path := range paths {
-		// Parse SiaPath.
-		siaPath, err := modules.NewSiaPath(path)
+		siaPath, glob, err := parseBulkPath(path)
 		if err != nil {
 			die("Couldn't parse SiaPath:", err)
 		}
-
-		// Try to delete file.
-		//
-		// In the case where the path points to a dir, this will fail and we
-		// silently move on to deleting it as a dir. This is more efficient than
-		// querying the renter first to see if it is a file or a dir, as that is
-		// guaranteed to always be two renter calls.
-		var errFile error
-		if renterDeleteRoot {
-			errFile = httpClient.RenterFileDeleteRootPost(siaPath)
-		} else {
-			errFile = httpClient.RenterFileDeletePost(siaPath)
-		}
-		if errFile == nil {
-			fmt.Printf("Deleted file '%v'\n", path)
-			continue
-		} else if !(strings.Contains(errFile.Error(), filesystem.ErrNotExist.Error()) || strings.Contains(errFile.Error(), filesystem.ErrDeleteFileIsDir.Error())) {
-			die(fmt.Sprintf("Failed to delete file %v: %v", path, errFile))
-		}
-		// Try to delete dir.
-		var errDir error
-		if renterDeleteRoot {
-			errDir = httpClient.RenterDirDeleteRootPost(siaPath)
-		} else {
-			errDir = httpClient.RenterDirDeletePost(siaPath)
-		}
-		if errDir == nil {
-			fmt.Printf("Deleted directory '%v'\n", path)
+		ops = append(ops, modules.BulkOperation{
+			Type:      modules.BulkOperationDelete,
+			SiaPath:   siaPath,
+			Glob:      glob,
+			Recursive: renterDeleteRecursive,
+		})
+	}
+	rbr, err := httpClient.RenterBulkPost(ops, renterDeleteRoot)
+	if err != nil {
+		die("Failed to delete:", err)
+	}
+	for _, result := range rbr.Results {
+		if result.Error != "" {
+			fmt.Printf("Failed to delete '%v': %v\n", result.SiaPath, result.Error)
 			continue
-		} else if !strings.Contains(errDir.Error(), filesystem.ErrNotExist.Error()) {
-			die(fmt.Sprintf("Failed to delete directory %v: %v", path, errDir))
 		}
-
-		// Unknown file/dir.
-		die(fmt.Sprintf("Unknown path '%v'", path))
+		fmt.Printf("Deleted '%v'\n", result.SiaPath)
+	}
+	if rbr.Failed > 0 {
+		die(fmt.Sprintf("Failed to delete %v of %v paths", rbr.Failed, len(rbr.Results)))
 	}
-	return
 }
 
 // renterfilesdownload is the handler for the command `siac renter download
@@ -1840,16 +2132,133 @@ func renterfileslistcmd(cmd *cobra.Command, args []string) {
 // Renames a file on the Sia network.
 func renterfilesrenamecmd(path, newpath string) {
 	// Parse SiaPath.
-	siaPath, err1 := modules.NewSiaPath(path)
+	siaPath, glob, err1 := parseBulkPath(path)
 	newSiaPath, err2 := modules.NewSiaPath(newpath)
 	if err := errors.Compose(err1, err2); err != nil {
 		die("Couldn't parse SiaPath:", err)
 	}
-	err := httpClient.RenterRenamePost(siaPath, newSiaPath, renterRenameRoot)
+	if glob == "" {
+		err := httpClient.RenterRenamePost(siaPath, newSiaPath, renterRenameRoot)
+		if err != nil {
+			die("Could not rename file:", err)
+		}
+		fmt.Printf("Renamed %s to %s\n", path, newpath)
+		return
+	}
+
+	// Move all matching files into the new folder.
+	rbr, err := httpClient.RenterBulkPost([]modules.BulkOperation{{
+		Type:       modules.BulkOperationRename,
+		SiaPath:    siaPath,
+		Glob:       glob,
+		NewSiaPath: newSiaPath,
+	}}, renterRenameRoot)
+	if err != nil {
+		die("Could not rename files:", err)
+	}
+	for _, result := range rbr.Results {
+		if result.Error != "" {
+			fmt.Printf("Failed to rename %v: %v\n", result.SiaPath, result.Error)
+			continue
+		}
+		fmt.Printf("Renamed %v to %v\n", result.SiaPath, result.NewSiaPath)
+	}
+	if rbr.Failed > 0 {
+		die(fmt.Sprintf("Failed to rename %v of %v files", rbr.Failed, len(rbr.Results)))
+	}
+}
+
+// renterfilesreencodecmd is the handler for the command `siac renter
+// reencode [path]`. Migrates a file or folder to new erasure code settings
+// and/or cipher type.
+func renterfilesreencodecmd(path string) {
+	// Parse SiaPath.
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	// Parse the new settings.
+	numDataPieces, numParityPieces, err := api.ParseDataAndParityPieces(dataPieces, parityPieces)
+	if err != nil {
+		die("Could not parse data and parity pieces:", err)
+	}
+	ct := crypto.TypeInvalid
+	if renterReencodeCipherType != "" {
+		if err := ct.FromString(renterReencodeCipherType); err != nil {
+			die("Could not parse cipher type:", err)
+		}
+	}
+	if numDataPieces == 0 && ct == crypto.TypeInvalid {
+		die("Must provide either --data-pieces and --parity-pieces or --cipher-type")
+	}
+
+	if renterReencodeRecursive {
+		err = httpClient.RenterDirReencodePost(siaPath, uint64(numDataPieces), uint64(numParityPieces), ct)
+	} else {
+		err = httpClient.RenterFileReencodePost(siaPath, uint64(numDataPieces), uint64(numParityPieces), ct)
+	}
+	if err != nil {
+		die("Could not re-encode:", err)
+	}
+	fmt.Printf("Started re-encoding %s\n", path)
+}
+
+// renterfilesverifycmd is the handler for the command `siac renter verify
+// [path]`. Verifies the integrity of a file or folder.
+func renterfilesverifycmd(path string) {
+	// Parse SiaPath.
+	var siaPath modules.SiaPath
+	if path == "." && renterVerifyRecursive {
+		siaPath = modules.RootSiaPath()
+	} else {
+		err := siaPath.LoadString(path)
+		if err != nil {
+			die("Couldn't parse SiaPath:", err)
+		}
+	}
+
+	report, err := httpClient.RenterVerifyPost(siaPath, renterVerifySamples, renterVerifyRecursive, renterVerifyRepair)
 	if err != nil {
-		die("Could not rename file:", err)
+		die("Could not verify:", err)
+	}
+
+	// Print the host results.
+	fmt.Printf("Verified %v file(s)\n\n", report.FilesVerified)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Host PubKey\tSamples\tFailures\tUnverified\tTrust Score")
+	for _, h := range report.Hosts {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%.2f\n", h.HostPublicKey.String(), h.Samples, h.Failures, h.Unverified, h.TrustScore)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+
+	// Print the corrupt and unverified pieces.
+	if len(report.CorruptPieces) == 0 && len(report.UnverifiedPieces) == 0 {
+		fmt.Println("\nAll sampled pieces were verified successfully.")
+		return
+	}
+	printVerificationPieces("Corrupt", report.CorruptPieces)
+	printVerificationPieces("Unverified", report.UnverifiedPieces)
+	if renterVerifyRepair {
+		fmt.Printf("\nRemoved %v corrupt piece(s) to have them repaired.\n", report.PiecesRemoved)
+	}
+}
+
+// printVerificationPieces prints a table of pieces of a verification report.
+func printVerificationPieces(title string, pieces []modules.UnreadablePiece) {
+	if len(pieces) == 0 {
+		return
+	}
+	fmt.Printf("\n%v %v Piece(s):\n", len(pieces), title)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Path\tChunk\tPiece\tHost PubKey\tError")
+	for _, p := range pieces {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\t%v\n", p.SiaPath, p.ChunkIndex, p.PieceIndex, p.HostPublicKey.String(), p.Error)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
 	}
-	fmt.Printf("Renamed %s to %s\n", path, newpath)
 }
 
 // renterfusecmd displays the list of directories that are currently mounted via
@@ -17,6 +17,7 @@ import (
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/node/api/server"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/profile"
 )
 
@@ -31,27 +32,53 @@ func passwordPrompt(prompt string) (string, error) {
 // verifyAPISecurity checks that the security values are consistent with a
 // sane, secure system.
 func verifyAPISecurity(config Config) error {
-	// Make sure that only the loopback address is allowed unless the
-	// --disable-api-security flag has been used.
+	// The API is authenticated with the API password or, over TLS, with client
+	// certificates.
+	tlsEnabled := config.Siad.APITLSCert != "" && config.Siad.APITLSKey != ""
+	authenticated := config.Siad.AuthenticateAPI || (tlsEnabled && config.Siad.APITLSRequireClientCert)
+
+	// Make sure that only the loopback address is allowed unless the API is
+	// served over TLS with authentication or the --disable-api-security flag
+	// has been used.
 	if !config.Siad.AllowAPIBind {
 		addr := modules.NetAddress(config.Siad.APIaddr)
-		if !addr.IsLoopback() {
-			if addr.Host() == "" {
-				return fmt.Errorf("a blank host will listen on all interfaces, did you mean localhost:%v?\nyou must pass --disable-api-security to bind Siad to a non-localhost address", addr.Port())
-			}
-			return errors.New("you must pass --disable-api-security to bind Siad to a non-localhost address")
+		if addr.IsLoopback() || (tlsEnabled && authenticated) {
+			return nil
+		}
+		if addr.Host() == "" {
+			return fmt.Errorf("a blank host will listen on all interfaces, did you mean localhost:%v?\nyou must serve the API over TLS with authentication or pass --disable-api-security to bind Siad to a non-localhost address", addr.Port())
 		}
-		return nil
+		return errors.New("you must serve the API over TLS with authentication or pass --disable-api-security to bind Siad to a non-localhost address")
 	}
 
-	// If the --disable-api-security flag is used, enforce that
-	// --authenticate-api must also be used.
-	if config.Siad.AllowAPIBind && !config.Siad.AuthenticateAPI {
+	// If the --disable-api-security flag is used, enforce that the API is
+	// authenticated.
+	if !authenticated {
 		return errors.New("cannot use --disable-api-security without setting an api password")
 	}
 	return nil
 }
 
+// apiTLSConfig returns the TLS config of the API server or nil if TLS is not
+// enabled.
+func apiTLSConfig(config Config) (*server.TLSConfig, error) {
+	if config.Siad.APITLSCert == "" && config.Siad.APITLSKey == "" {
+		if config.Siad.APITLSClientCA != "" || config.Siad.APITLSRequireClientCert {
+			return nil, errors.New("client certificates require --api-tls-cert and --api-tls-key")
+		}
+		return nil, nil
+	}
+	if config.Siad.APITLSCert == "" || config.Siad.APITLSKey == "" {
+		return nil, errors.New("--api-tls-cert and --api-tls-key must be used together")
+	}
+	return &server.TLSConfig{
+		CertFile:          config.Siad.APITLSCert,
+		KeyFile:           config.Siad.APITLSKey,
+		ClientCAFile:      config.Siad.APITLSClientCA,
+		RequireClientCert: config.Siad.APITLSRequireClientCert,
+	}, nil
+}
+
 // processNetAddr adds a ':' to a bare integer, so that it is a proper port
 // number.
 func processNetAddr(addr string) string {
@@ -211,8 +238,22 @@ func startDaemon(config Config) (err error) {
 	// set the wallet password from the environment variable
 	nodeParams.WalletPassword = build.WalletPassword()
 
+	// Set the options of the modules' loggers.
+	err = persist.SetLogOptions(persist.LogOptions{
+		Format:  persist.LogFormat(config.Siad.LogFormat),
+		MaxAge:  config.Siad.LogMaxAge,
+		MaxSize: config.Siad.LogMaxSize * 1e6,
+	})
+	if err != nil {
+		return err
+	}
+
 	// Start and run the server.
-	srv, err := server.New(config.Siad.APIaddr, config.Siad.RequiredUserAgent, config.APIPassword, nodeParams, loadStart)
+	tlsConfig, err := apiTLSConfig(config)
+	if err != nil {
+		return err
+	}
+	srv, err := server.New(config.Siad.APIaddr, config.Siad.RequiredUserAgent, config.APIPassword, tlsConfig, nodeParams, loadStart)
 	if err != nil {
 		return err
 	}
@@ -159,8 +159,8 @@ func TestLoadAPIPassword(t *testing.T) {
 
 // TestVerifyAPISecurity checks that the verifyAPISecurity function is
 // correctly banning the use of a non-loopback address without the
-// --disable-security flag, and that the --disable-security flag cannot be used
-// without an api password.
+// --disable-security flag or TLS with authentication, and that the
+// --disable-security flag cannot be used without an api password.
 func TestVerifyAPISecurity(t *testing.T) {
 	// Check that the loopback address is accepted when security is enabled.
 	var securityOnLoopback Config
@@ -206,4 +206,82 @@ func TestVerifyAPISecurity(t *testing.T) {
 	if err != nil {
 		t.Error("public + securityOff with authentication was rejected:", err)
 	}
+
+	// Check that a public hostname is rejected over TLS without
+	// authentication.
+	var tlsPublic Config
+	tlsPublic.Siad.APIaddr = "sia.tech:9980"
+	tlsPublic.Siad.APITLSCert = "cert.pem"
+	tlsPublic.Siad.APITLSKey = "key.pem"
+	err = verifyAPISecurity(tlsPublic)
+	if err == nil {
+		t.Error("public + TLS was accepted without authentication")
+	}
+
+	// Check that a public hostname is accepted over TLS with an api password.
+	tlsPublicAuthenticated := tlsPublic
+	tlsPublicAuthenticated.Siad.AuthenticateAPI = true
+	err = verifyAPISecurity(tlsPublicAuthenticated)
+	if err != nil {
+		t.Error("public + TLS with authentication was rejected:", err)
+	}
+
+	// Check that a public hostname is accepted over TLS with required client
+	// certificates.
+	tlsPublicClientCert := tlsPublic
+	tlsPublicClientCert.Siad.APITLSClientCA = "ca.pem"
+	tlsPublicClientCert.Siad.APITLSRequireClientCert = true
+	err = verifyAPISecurity(tlsPublicClientCert)
+	if err != nil {
+		t.Error("public + TLS with client certificates was rejected:", err)
+	}
+
+	// Check that client certificates without TLS don't count as
+	// authentication.
+	var clientCertPublic Config
+	clientCertPublic.Siad.APIaddr = "sia.tech:9980"
+	clientCertPublic.Siad.APITLSRequireClientCert = true
+	err = verifyAPISecurity(clientCertPublic)
+	if err == nil {
+		t.Error("public + client certificates without TLS was accepted")
+	}
+
+	// Check that a blank host is accepted over TLS with an api password.
+	tlsBlankAuthenticated := tlsPublicAuthenticated
+	tlsBlankAuthenticated.Siad.APIaddr = ":9980"
+	err = verifyAPISecurity(tlsBlankAuthenticated)
+	if err != nil {
+		t.Error("blank + TLS with authentication was rejected:", err)
+	}
+}
+
+// TestAPITLSConfig probes the 'apiTLSConfig' function.
+func TestAPITLSConfig(t *testing.T) {
+	// Without TLS flags there is no TLS config.
+	var config Config
+	if tlsConfig, err := apiTLSConfig(config); err != nil || tlsConfig != nil {
+		t.Fatal("unexpected TLS config", tlsConfig, err)
+	}
+
+	// Client certificates require a server certificate.
+	config.Siad.APITLSClientCA = "ca.pem"
+	if _, err := apiTLSConfig(config); err == nil {
+		t.Error("client CA without certificate was accepted")
+	}
+
+	// The certificate requires a key.
+	config.Siad.APITLSCert = "cert.pem"
+	if _, err := apiTLSConfig(config); err == nil {
+		t.Error("certificate without key was accepted")
+	}
+
+	config.Siad.APITLSKey = "key.pem"
+	config.Siad.APITLSRequireClientCert = true
+	tlsConfig, err := apiTLSConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tlsConfig.CertFile != "cert.pem" || tlsConfig.KeyFile != "key.pem" || tlsConfig.ClientCAFile != "ca.pem" || !tlsConfig.RequireClientCert {
+		t.Error("unexpected TLS config", tlsConfig)
+	}
 }
@@ -3,10 +3,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/persist"
 )
 
 var (
@@ -39,6 +41,15 @@ type Config struct {
 		SiaMuxWSAddr  string
 		AllowAPIBind  bool
 
+		APITLSCert              string
+		APITLSKey               string
+		APITLSClientCA          string
+		APITLSRequireClientCert bool
+
+		LogFormat  string
+		LogMaxAge  time.Duration
+		LogMaxSize uint64
+
 		Modules           string
 		NoBootstrap       bool
 		UseUPNP           bool
@@ -204,6 +215,13 @@ func main() {
 	root.Flags().BoolVarP(&globalConfig.Siad.AuthenticateAPI, "authenticate-api", "", true, "enable API password protection")
 	root.Flags().BoolVarP(&globalConfig.Siad.TempPassword, "temp-password", "", false, "enter a temporary API password during startup")
 	root.Flags().BoolVarP(&globalConfig.Siad.AllowAPIBind, "disable-api-security", "", false, "allow siad to listen on a non-localhost address (DANGEROUS)")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSCert, "api-tls-cert", "", "", "serve the API over TLS with this PEM encoded certificate, reloaded on change")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSKey, "api-tls-key", "", "", "PEM encoded key of the API TLS certificate")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSClientCA, "api-tls-client-ca", "", "", "accept API client certificates signed by this PEM encoded CA")
+	root.Flags().BoolVarP(&globalConfig.Siad.APITLSRequireClientCert, "api-tls-require-client-cert", "", false, "reject API connections without a valid client certificate")
+	root.Flags().StringVarP(&globalConfig.Siad.LogFormat, "log-format", "", string(persist.LogFormatText), "format of the log files, 'text' or 'json'")
+	root.Flags().DurationVarP(&globalConfig.Siad.LogMaxAge, "log-max-age", "", 0, "rotate log files after this duration, e.g. 24h, 0 disables rotation by age")
+	root.Flags().Uint64VarP(&globalConfig.Siad.LogMaxSize, "log-max-size", "", 0, "rotate log files once they exceed this size in MB, 0 disables rotation by size")
 
 	// If globalConfig.Siad.SiaDir is not set, use the environment variable provided.
 	if globalConfig.Siad.SiaDir == "" {
@@ -45,6 +45,10 @@ const (
 	// AlertIDRenterContractRenewalError is the id of the alert that is
 	// registered if at least once contract renewal or refresh failed
 	AlertIDRenterContractRenewalError = "contract-renewal-error"
+	// AlertIDRenterSpendingCap is the id of the alert that is registered if
+	// the spending in at least one category is close to or has reached the
+	// category's cap.
+	AlertIDRenterSpendingCap = "spending-cap"
 	// AlertIDGatewayOffline is the id of the alert that is registered upon a
 	// call to 'gateway.Offline' if the value returned is 'false' and
 	// unregistered when it returns 'true'.
@@ -1,8 +1,15 @@
 package modules
 
 import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
+	"gitlab.com/NebulousLabs/errors"
+
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/persist"
@@ -153,6 +160,16 @@ var (
 	// reasonably competitive.
 	DefaultStoragePrice = types.SiacoinPrecision.Mul64(50).Div(BlockBytesPerMonthTerabyte) // 50 SC / TB / Month
 
+	// DefaultMaxTemporaryStorage defines the default maximum number of bytes
+	// the host will store in temporary sectors that don't belong to a
+	// contract.
+	DefaultMaxTemporaryStorage = uint64(1 << 32) // 4 GiB
+
+	// DefaultTemporaryStoragePrice defines the default price of storing data
+	// in temporary sectors. It is twice the price of contract storage since
+	// the host doesn't receive any collateral or contract fees for it.
+	DefaultTemporaryStoragePrice = DefaultStoragePrice.Mul64(2) // 100 SC / TB / Month
+
 	// DefaultUploadBandwidthPrice defines the default price of upload
 	// bandwidth. The default is set to 1 siacoin per GB, because the host is
 	// presumed to have a large amount of downstream bandwidth. Furthermore,
@@ -161,6 +178,19 @@ var (
 	// data.
 	DefaultUploadBandwidthPrice = types.SiacoinPrecision.Mul64(1).Div(BytesPerTerabyte) // 1 SC / TB
 
+	// DefaultPricingEngineMaxPriceChange is the default maximum relative
+	// change of a price per update of the pricing engine.
+	DefaultPricingEngineMaxPriceChange = 0.05
+
+	// DefaultPricingEngineUpdateInterval is the default minimum time between
+	// two price updates of the pricing engine.
+	DefaultPricingEngineUpdateInterval = build.Select(build.Var{
+		Dev:      time.Minute * 5,
+		Standard: time.Hour,
+		Testnet:  time.Hour,
+		Testing:  time.Second,
+	}).(time.Duration)
+
 	// CompatV1412DefaultEphemeralAccountExpiry defines the default account
 	// expiry used up until v1.4.12. This constant is added to ensure changing
 	// the default does not break legacy checks.
@@ -195,6 +225,42 @@ var (
 	MaxSectorAccessPriceVsBandwidth = uint64(400e3)
 )
 
+var (
+	// ErrInvalidMaxPriceChange is returned if the max price change of the
+	// pricing engine is not between 0 and 1.
+	ErrInvalidMaxPriceChange = errors.New("max price change must be between 0 and 1")
+
+	// ErrInvalidPricingInterval is returned if the update interval of the
+	// pricing engine is negative.
+	ErrInvalidPricingInterval = errors.New("pricing engine update interval can't be negative")
+
+	// ErrInvalidPricingTarget is returned if a target price of the pricing
+	// engine is negative or not a number.
+	ErrInvalidPricingTarget = errors.New("target price must be a non-negative number")
+
+	// ErrMaxPriceBelowMinPrice is returned if a max price of the pricing engine
+	// is lower than the corresponding min price.
+	ErrMaxPriceBelowMinPrice = errors.New("max price can't be lower than the min price")
+
+	// ErrPricingTargetWithoutRate is returned if a target price is set without
+	// an exchange rate.
+	ErrPricingTargetWithoutRate = errors.New("target prices require an exchange rate")
+
+	// ErrInvalidExportRange is returned if the end of the range of a contract
+	// export is before its start.
+	ErrInvalidExportRange = errors.New("end of the export range can't be before its start")
+
+	// ErrSectorNotFound is returned when a lookup for a sector fails.
+	ErrSectorNotFound = errors.New("could not find the desired sector")
+)
+
+// IsSectorNotFoundErr returns whether the error, which might have been
+// returned by a host over the network, indicates that the host doesn't store
+// the requested sector.
+func IsSectorNotFoundErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), ErrSectorNotFound.Error())
+}
+
 var (
 	// HostConnectabilityStatusChecking is returned from ConnectabilityStatus()
 	// if the host is still determining if it is connectable.
@@ -290,6 +356,73 @@ type (
 
 		CustomRegistryPath string `json:"customregistrypath"`
 		RegistrySize       uint64 `json:"registrysize"`
+
+		AcceptingTemporarySectors bool           `json:"acceptingtemporarysectors"`
+		MaxTemporaryStorage       uint64         `json:"maxtemporarystorage"`
+		MinTemporaryStoragePrice  types.Currency `json:"mintemporarystorageprice"`
+
+		BandwidthLimits HostBandwidthLimits       `json:"bandwidthlimits"`
+		PricingEngine   HostPricingEngineSettings `json:"pricingengine"`
+	}
+
+	// HostBandwidthLimits configures the bandwidth shaping of the host. The
+	// limits are in bytes per second and a limit of 0 means that the
+	// bandwidth isn't limited. Every class of RPC has its own budget and
+	// queue. Within a class the bandwidth is shared fairly between renters
+	// and every renter is limited to the per-renter budget of the class.
+	// Renters are identified by their contract or otherwise by their IP.
+	HostBandwidthLimits struct {
+		// Download limits the data sent by the host for downloads, Upload
+		// limits the data received by the host for uploads and Registry
+		// limits the data in both directions for registry RPCs.
+		Download uint64 `json:"download"`
+		Upload   uint64 `json:"upload"`
+		Registry uint64 `json:"registry"`
+
+		// The per-renter limits of the classes.
+		RenterDownload uint64 `json:"renterdownload"`
+		RenterUpload   uint64 `json:"renterupload"`
+		RenterRegistry uint64 `json:"renterregistry"`
+	}
+
+	// HostPricingEngineSettings configures the host's pricing engine. If
+	// enabled, the engine periodically adjusts the storage and bandwidth
+	// prices based on the host's utilization and an optional target fiat
+	// price. The MinStoragePrice, MinUploadBandwidthPrice and
+	// MinDownloadBandwidthPrice of the internal settings act as lower bounds
+	// for the adjusted prices.
+	HostPricingEngineSettings struct {
+		Enabled bool `json:"enabled"`
+
+		// ExchangeRate is the fiat value of one siacoin, e.g. "0.005 usd". It
+		// is required for the target prices.
+		ExchangeRate string `json:"exchangerate"`
+
+		// The target prices are specified in the currency of the exchange
+		// rate. The storage price is per TB per month and the bandwidth prices
+		// are per TB. A target price of 0 means that the minimum price is used
+		// as the base price instead.
+		TargetDownloadBandwidthPrice float64 `json:"targetdownloadbandwidthprice"`
+		TargetStoragePrice           float64 `json:"targetstorageprice"`
+		TargetUploadBandwidthPrice   float64 `json:"targetuploadbandwidthprice"`
+
+		// TargetBandwidth is the bandwidth in bytes per second at which the
+		// bandwidth prices equal their base price. 0 means that the bandwidth
+		// prices are not adjusted for the host's bandwidth usage.
+		TargetBandwidth uint64 `json:"targetbandwidth"`
+
+		// The max prices are the upper bounds for the adjusted prices. A max
+		// price of 0 means there is no upper bound.
+		MaxDownloadBandwidthPrice types.Currency `json:"maxdownloadbandwidthprice"`
+		MaxStoragePrice           types.Currency `json:"maxstorageprice"`
+		MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+
+		// MaxPriceChange is the maximum relative change of a price per update,
+		// e.g. 0.1 for 10%. UpdateInterval is the minimum time between two
+		// updates. If 0, DefaultPricingEngineMaxPriceChange and
+		// DefaultPricingEngineUpdateInterval are used.
+		MaxPriceChange float64       `json:"maxpricechange"`
+		UpdateInterval time.Duration `json:"updateinterval"`
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
@@ -302,6 +435,28 @@ type (
 		ReviseCalls       uint64 `json:"revisecalls"`
 		SettingsCalls     uint64 `json:"settingscalls"`
 		UnrecognizedCalls uint64 `json:"unrecognizedcalls"`
+
+		// Bandwidth shaping metrics of the RPC classes.
+		DownloadBandwidth HostBandwidthMetrics `json:"downloadbandwidth"`
+		UploadBandwidth   HostBandwidthMetrics `json:"uploadbandwidth"`
+		RegistryBandwidth HostBandwidthMetrics `json:"registrybandwidth"`
+	}
+
+	// HostBandwidthMetrics reports the bandwidth shaping of a class of RPCs.
+	HostBandwidthMetrics struct {
+		// Bytes is the number of bytes transferred by the class and
+		// ThrottledBytes the number of those bytes that had to wait for
+		// bandwidth.
+		Bytes          uint64 `json:"bytes"`
+		ThrottledBytes uint64 `json:"throttledbytes"`
+
+		// WaitTime is the total time spent waiting for bandwidth.
+		WaitTime time.Duration `json:"waittime"`
+
+		// QueuedRequests is the number of requests currently waiting for
+		// bandwidth and Renters the number of renters with recent traffic.
+		QueuedRequests uint64 `json:"queuedrequests"`
+		Renters        uint64 `json:"renters"`
 	}
 
 	// StorageObligation contains information about a storage obligation that
@@ -338,12 +493,165 @@ type (
 		RevisionConfirmed   bool   `json:"revisionconfirmed"`
 		RevisionConstructed bool   `json:"revisionconstructed"`
 
+		// The height and the transaction of the confirmed storage proof, the
+		// height at which the host resolved the obligation and the
+		// transaction of the most recent revision.
+		ProofHeight           types.BlockHeight   `json:"proofheight"`
+		ProofTransactionID    types.TransactionID `json:"prooftransactionid"`
+		ResolutionHeight      types.BlockHeight   `json:"resolutionheight"`
+		RevisionTransactionID types.TransactionID `json:"revisiontransactionid"`
+
 		// The outputs that will be created after the expiration of the contract
 		// or a proof has been confirmed on the blockchain.
 		ValidProofOutputs  []types.SiacoinOutput `json:"validproofoutputs"`
 		MissedProofOutputs []types.SiacoinOutput `json:"missedproofoutputs"`
 	}
 
+	// HostExchangeRate is an entry of the exchange rate table of a contract
+	// export. The rate, e.g. "0.005 usd", applies to all records from the
+	// timestamp until the timestamp of the next entry.
+	HostExchangeRate struct {
+		Timestamp types.Timestamp `json:"timestamp"`
+		Rate      string          `json:"rate"`
+	}
+
+	// HostContractExportParams select the storage obligations of a contract
+	// export. A record is exported if its reporting height and the timestamp
+	// of that height are within the range. An end height or end time of 0
+	// means that the range is not bounded. The fiat values of the records are
+	// computed from the optional exchange rate table. Unresolved obligations,
+	// whose revenue isn't realized yet, are only exported if
+	// IncludeUnresolved is set.
+	HostContractExportParams struct {
+		StartHeight       types.BlockHeight
+		EndHeight         types.BlockHeight
+		StartTime         types.Timestamp
+		EndTime           types.Timestamp
+		Rates             []HostExchangeRate
+		IncludeUnresolved bool
+	}
+
+	// HostContractRecord contains the information about a storage obligation
+	// that is needed for reporting the host's revenue.
+	HostContractRecord struct {
+		ObligationId     types.FileContractID `json:"obligationid"`
+		ObligationStatus string               `json:"obligationstatus"`
+
+		// The reporting height is the height at which the obligation was
+		// resolved or the negotiation height for unresolved obligations. The
+		// timestamp is the timestamp of the block at the reporting height.
+		ReportingHeight types.BlockHeight `json:"reportingheight"`
+		Timestamp       types.Timestamp   `json:"timestamp"`
+
+		NegotiationHeight types.BlockHeight `json:"negotiationheight"`
+		ExpirationHeight  types.BlockHeight `json:"expirationheight"`
+		ProofDeadline     types.BlockHeight `json:"proofdeadline"`
+		ProofHeight       types.BlockHeight `json:"proofheight"`
+
+		// The revenue of the obligation by category. The revenue is only
+		// realized if the obligation succeeded, for unresolved obligations it
+		// is the potential revenue and rejected obligations don't have any.
+		// The lost revenue and collateral are set if the obligation failed.
+		ContractCost     types.Currency `json:"contractcost"`
+		StorageRevenue   types.Currency `json:"storagerevenue"`
+		UploadRevenue    types.Currency `json:"uploadrevenue"`
+		DownloadRevenue  types.Currency `json:"downloadrevenue"`
+		AccountFunding   types.Currency `json:"accountfunding"`
+		TransactionFees  types.Currency `json:"transactionfees"`
+		LockedCollateral types.Currency `json:"lockedcollateral"`
+		RiskedCollateral types.Currency `json:"riskedcollateral"`
+		LostCollateral   types.Currency `json:"lostcollateral"`
+		LostRevenue      types.Currency `json:"lostrevenue"`
+
+		OriginTransactionID   types.TransactionID `json:"origintransactionid"`
+		RevisionTransactionID types.TransactionID `json:"revisiontransactionid"`
+		ProofTransactionID    types.TransactionID `json:"prooftransactionid"`
+
+		// Fiat contains the values of the record converted with the exchange
+		// rate at the timestamp of the record. It is nil if there is no such
+		// rate.
+		Fiat *HostContractRecordFiat `json:"fiat,omitempty"`
+	}
+
+	// HostContractRecordFiat contains the values of a contract record
+	// converted to fiat.
+	HostContractRecordFiat struct {
+		Rate             string `json:"rate"`
+		ContractCost     string `json:"contractcost"`
+		StorageRevenue   string `json:"storagerevenue"`
+		UploadRevenue    string `json:"uploadrevenue"`
+		DownloadRevenue  string `json:"downloadrevenue"`
+		AccountFunding   string `json:"accountfunding"`
+		TransactionFees  string `json:"transactionfees"`
+		LockedCollateral string `json:"lockedcollateral"`
+		RiskedCollateral string `json:"riskedcollateral"`
+		LostCollateral   string `json:"lostcollateral"`
+		LostRevenue      string `json:"lostrevenue"`
+	}
+
+	// HostEphemeralAccount contains information about an ephemeral account on
+	// the host. A max balance of 0 means that the account is only limited by
+	// the host's MaxEphemeralAccountBalance. The expiry is zero if the host
+	// doesn't expire accounts.
+	HostEphemeralAccount struct {
+		ID                      string         `json:"id"`
+		Balance                 types.Currency `json:"balance"`
+		MaxBalance              types.Currency `json:"maxbalance"`
+		PendingRisk             types.Currency `json:"pendingrisk"`
+		BlockedWithdrawals      uint64         `json:"blockedwithdrawals"`
+		BlockedWithdrawalsValue types.Currency `json:"blockedwithdrawalsvalue"`
+		LastActivity            time.Time      `json:"lastactivity"`
+		Expiry                  time.Time      `json:"expiry"`
+	}
+
+	// HostEphemeralAccountsSummary contains aggregate information about the
+	// ephemeral accounts on the host. The current risk is the amount of money
+	// the host could lose because account balances and deposits aren't
+	// persisted yet, it is limited by the max risk.
+	HostEphemeralAccountsSummary struct {
+		Accounts            uint64         `json:"accounts"`
+		TotalBalance        types.Currency `json:"totalbalance"`
+		MaxBalance          types.Currency `json:"maxbalance"`
+		CurrentRisk         types.Currency `json:"currentrisk"`
+		MaxRisk             types.Currency `json:"maxrisk"`
+		BlockedDeposits     uint64         `json:"blockeddeposits"`
+		BlockedWithdrawals  uint64         `json:"blockedwithdrawals"`
+		WithdrawalsInactive bool           `json:"withdrawalsinactive"`
+	}
+
+	// HostRegistryStats contains usage statistics of the host's registry. The
+	// registry tracks its used slots in a bitfield of 64 slot words, full words
+	// make finding a free slot for a new entry slower.
+	HostRegistryStats struct {
+		Path              string `json:"path"`
+		Entries           uint64 `json:"entries"`
+		Capacity          uint64 `json:"capacity"`
+		ExpiredEntries    uint64 `json:"expiredentries"`
+		UsedSlots         uint64 `json:"usedslots"`
+		BitfieldWords     uint64 `json:"bitfieldwords"`
+		FullBitfieldWords uint64 `json:"fullbitfieldwords"`
+	}
+
+	// HostRegistryEntry is an entry of the host's registry.
+	HostRegistryEntry struct {
+		EntryID   RegistryEntryID    `json:"entryid"`
+		PublicKey types.SiaPublicKey `json:"publickey"`
+		Tweak     crypto.Hash        `json:"tweak"`
+		Data      string             `json:"data"`
+		Revision  uint64             `json:"revision"`
+		Signature string             `json:"signature"`
+		Type      RegistryEntryType  `json:"type"`
+		Expiry    types.BlockHeight  `json:"expiry"`
+	}
+
+	// HostRegistryImport contains the result of importing registry entries
+	// into the host's registry. Entries are skipped if they are expired or if
+	// the host already knows the same or a more recent revision.
+	HostRegistryImport struct {
+		Imported uint64 `json:"imported"`
+		Skipped  uint64 `json:"skipped"`
+	}
+
 	// HostWorkingStatus reports the working state of a host. Can be one of
 	// "checking", "working", or "not working".
 	HostWorkingStatus string
@@ -358,6 +666,10 @@ type (
 	Host interface {
 		Alerter
 
+		// ContractRecords returns the records of the storage obligations of
+		// the host that are selected by the export params.
+		ContractRecords(params HostContractExportParams) ([]HostContractRecord, error)
+
 		// AddSector will add a sector on the host. If the sector already
 		// exists, a virtual sector will be added, meaning that the 'sectorData'
 		// will be ignored and no new disk space will be consumed. The expiry
@@ -391,6 +703,10 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// CancelStorageFolderOperation cancels the running storage folder
+		// operation of the host.
+		CancelStorageFolderOperation() error
+
 		// The host needs to be able to shut down.
 		Close() error
 
@@ -413,6 +729,18 @@ type (
 		// BandwidthCounters returns the Hosts's upload and download bandwidth
 		BandwidthCounters() (uint64, uint64, time.Time, error)
 
+		// EphemeralAccounts returns information about the ephemeral accounts
+		// on the host.
+		EphemeralAccounts() []HostEphemeralAccount
+
+		// EphemeralAccountsSummary returns aggregate information about the
+		// ephemeral accounts on the host.
+		EphemeralAccountsSummary() HostEphemeralAccountsSummary
+
+		// ExpireEphemeralAccount expires the ephemeral account with the given
+		// id immediately, the host keeps its balance.
+		ExpireEphemeralAccount(id AccountID) error
+
 		// FinancialMetrics returns the financial statistics of the host.
 		FinancialMetrics() HostFinancialMetrics
 
@@ -420,6 +748,10 @@ type (
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// MigrateStorageFolder starts moving all sectors of the source storage
+		// folder of the host into the destination storage folder.
+		MigrateStorageFolder(source, destination uint16, removeSource bool, maxBandwidth uint64) error
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
@@ -429,6 +761,10 @@ type (
 		// PriceTable returns the host's current price table.
 		PriceTable() RPCPriceTable
 
+		// PruneRegistry deletes the expired entries from the host's registry
+		// and returns the number of deleted entries.
+		PruneRegistry() (uint64, error)
+
 		// PruneStaleStorageObligations will delete storage obligations from the
 		// host that, for whatever reason, did not make it on the block chain.
 		// As these stale storage obligations have an impact on the host
@@ -447,6 +783,25 @@ type (
 		// 'length' bytes at offset 'offset' that match the input sector root.
 		ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error)
 
+		// RebalanceStorageFolders starts evening out the free space of the
+		// host's storage folders.
+		RebalanceStorageFolders(maxBandwidth uint64) error
+
+		// RegistryEntry returns the entry of the host's registry with the given
+		// id.
+		RegistryEntry(eid RegistryEntryID) (HostRegistryEntry, bool)
+
+		// RegistryExport writes the entries of the host's registry to a file
+		// at the given path and returns the number of exported entries.
+		RegistryExport(path string) (uint64, error)
+
+		// RegistryImport adds the entries of a file created by RegistryExport
+		// to the host's registry.
+		RegistryImport(path string) (HostRegistryImport, error)
+
+		// RegistryStats returns usage statistics of the host's registry.
+		RegistryStats() HostRegistryStats
+
 		// RemoveSector will remove a sector from the host. The height at which
 		// the sector expires should be provided, so that the auto-expiry
 		// information for that sector can be properly updated.
@@ -481,9 +836,24 @@ type (
 		// and the resize operation completed, meaning that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// SetEphemeralAccountMaxBalance caps the balance of the ephemeral
+		// account with the given id. A max balance of 0 removes the cap.
+		SetEphemeralAccountMaxBalance(id AccountID, maxBalance types.Currency) error
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetStorageFolderTier sets the tier of a storage folder on the host.
+		SetStorageFolderTier(index uint16, tier StorageFolderTier) error
+
+		// SetStorageRedundancy sets the local redundancy mode of the host's
+		// storage.
+		SetStorageRedundancy(mode StorageRedundancyMode) error
+
+		// StorageFolderOperation returns the running or most recently
+		// finished storage folder operation of the host.
+		StorageFolderOperation() (StorageFolderOperation, bool)
+
 		// StorageObligation returns the storage obligation matching the id or
 		// an error if it does not exist
 		StorageObligation(obligationID types.FileContractID) (StorageObligation, error)
@@ -496,6 +866,10 @@ type (
 		// host.
 		StorageFolders() []StorageFolderMetadata
 
+		// StorageRedundancy returns the local redundancy status of the host's
+		// storage.
+		StorageRedundancy() StorageRedundancyStatus
+
 		// WorkingStatus returns the working state of the host, determined by if
 		// settings calls are increasing.
 		WorkingStatus() HostWorkingStatus
@@ -514,6 +888,116 @@ func (his HostInternalSettings) MaxSectorAccessPrice() types.Currency {
 	return his.MinDownloadBandwidthPrice.Mul64(MaxSectorAccessPriceVsBandwidth)
 }
 
+// PriceChange returns the max relative price change of the pricing engine.
+func (hpes HostPricingEngineSettings) PriceChange() float64 {
+	if hpes.MaxPriceChange == 0 {
+		return DefaultPricingEngineMaxPriceChange
+	}
+	return hpes.MaxPriceChange
+}
+
+// Interval returns the update interval of the pricing engine.
+func (hpes HostPricingEngineSettings) Interval() time.Duration {
+	if hpes.UpdateInterval == 0 {
+		return DefaultPricingEngineUpdateInterval
+	}
+	return hpes.UpdateInterval
+}
+
+// Validate checks the settings of the pricing engine for errors.
+func (hpes HostPricingEngineSettings) Validate(his HostInternalSettings) error {
+	rate, err := types.ParseExchangeRate(hpes.ExchangeRate)
+	if err != nil {
+		return errors.AddContext(err, "invalid exchange rate")
+	}
+	targets := []float64{hpes.TargetDownloadBandwidthPrice, hpes.TargetStoragePrice, hpes.TargetUploadBandwidthPrice}
+	for _, target := range targets {
+		if math.IsNaN(target) || math.IsInf(target, 0) || target < 0 {
+			return ErrInvalidPricingTarget
+		}
+		if target > 0 && rate == nil {
+			return ErrPricingTargetWithoutRate
+		}
+	}
+	if math.IsNaN(hpes.MaxPriceChange) || hpes.MaxPriceChange < 0 || hpes.MaxPriceChange > 1 {
+		return ErrInvalidMaxPriceChange
+	}
+	if hpes.UpdateInterval < 0 {
+		return ErrInvalidPricingInterval
+	}
+	bounds := []struct {
+		min, max types.Currency
+	}{
+		{his.MinDownloadBandwidthPrice, hpes.MaxDownloadBandwidthPrice},
+		{his.MinStoragePrice, hpes.MaxStoragePrice},
+		{his.MinUploadBandwidthPrice, hpes.MaxUploadBandwidthPrice},
+	}
+	for _, b := range bounds {
+		if !b.max.IsZero() && b.max.Cmp(b.min) < 0 {
+			return ErrMaxPriceBelowMinPrice
+		}
+	}
+	return nil
+}
+
+// Validate checks the params of a contract export for errors.
+func (p HostContractExportParams) Validate() error {
+	if p.EndHeight != 0 && p.EndHeight < p.StartHeight {
+		return ErrInvalidExportRange
+	}
+	if p.EndTime != 0 && p.EndTime < p.StartTime {
+		return ErrInvalidExportRange
+	}
+	for _, r := range p.Rates {
+		rate, err := types.ParseExchangeRate(r.Rate)
+		if err != nil {
+			return errors.AddContext(err, fmt.Sprintf("invalid exchange rate at %v", r.Timestamp))
+		}
+		if rate == nil {
+			return errors.AddContext(types.ErrUnexpectedFormat, fmt.Sprintf("missing exchange rate at %v", r.Timestamp))
+		}
+	}
+	return nil
+}
+
+// ParseHostExchangeRates parses an exchange rate table of the form
+// "<timestamp>=<rate>,<timestamp>=<rate>" and returns the entries sorted by
+// their timestamp.
+func ParseHostExchangeRates(s string) ([]HostExchangeRate, error) {
+	var rates []HostExchangeRate
+	if strings.TrimSpace(s) == "" {
+		return rates, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid exchange rate table entry %q", entry)
+		}
+		timestamp, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 64)
+		if err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid timestamp in exchange rate table entry %q", entry))
+		}
+		rates = append(rates, HostExchangeRate{
+			Timestamp: types.Timestamp(timestamp),
+			Rate:      strings.TrimSpace(parts[1]),
+		})
+	}
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Timestamp < rates[j].Timestamp
+	})
+	return rates, nil
+}
+
+// EncodeHostExchangeRates encodes an exchange rate table in the format
+// expected by ParseHostExchangeRates.
+func EncodeHostExchangeRates(rates []HostExchangeRate) string {
+	entries := make([]string, 0, len(rates))
+	for _, r := range rates {
+		entries = append(entries, fmt.Sprintf("%d=%s", r.Timestamp, r.Rate))
+	}
+	return strings.Join(entries, ",")
+}
+
 // DefaultHostExternalSettings returns HostExternalSettings with certain default
 // fields set. NetAddress, RemainingStorage, TotalStorage, UnlockHash, RevisionNumber and SiaMuxPort are not set.
 func DefaultHostExternalSettings() HostExternalSettings {
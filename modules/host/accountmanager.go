@@ -23,6 +23,14 @@ var (
 	// the account has expired in the meantime.
 	ErrAccountExpired = errors.New("ephemeral account expired")
 
+	// ErrAccountExpiryCancelled occurs when the host was stopped while waiting
+	// for the pending persists of an account that is expired.
+	ErrAccountExpiryCancelled = errors.New("ephemeral account expiry cancelled due to a shutdown")
+
+	// ErrAccountNotFound occurs when an account that doesn't exist is
+	// administrated.
+	ErrAccountNotFound = errors.New("ephemeral account not found")
+
 	// ErrBalanceInsufficient occurs when a withdrawal could not be successfully
 	// completed because the account balance was insufficient.
 	ErrBalanceInsufficient = errors.New("ephemeral account balance was insufficient")
@@ -61,6 +69,16 @@ var (
 		Testing:  3 * time.Second,
 	}).(time.Duration)
 
+	// expireAccountRetryInterval is the interval at which the account manager
+	// checks whether the pending persists of an account that is expired by the
+	// host operator have finished.
+	expireAccountRetryInterval = build.Select(build.Var{
+		Standard: 100 * time.Millisecond,
+		Testnet:  100 * time.Millisecond,
+		Dev:      100 * time.Millisecond,
+		Testing:  10 * time.Millisecond,
+	}).(time.Duration)
+
 	// blockedWithdrawalTimeout is the amount of time after which a blocked
 	// withdrawal times out.
 	// NOTE: The standard case is set to 3 minutes since streams established
@@ -163,6 +181,11 @@ type (
 		// inactive for too long. The host can configure this expiry using the
 		// ephemeralaccountexpiry setting.
 		lastTxnTime int64
+
+		// maxBalance caps the balance of the account below the host's
+		// MaxEphemeralAccountBalance. It is set by the host operator to limit
+		// misbehaving accounts, zero means that the account is not capped.
+		maxBalance types.Currency
 	}
 
 	// accountBitfield is a bitfield to keep track of account indexes. When an
@@ -471,7 +494,7 @@ func (am *accountManager) deposit(id modules.AccountID, amount, maxRisk, maxBala
 	}
 
 	// Verify if the deposit does not exceed the maximum
-	if !refund && acc.depositExceedsMaxBalance(amount, maxBalance) {
+	if !refund && acc.depositExceedsMaxBalance(amount, acc.capMaxBalance(maxBalance)) {
 		pr.externErr = ErrBalanceMaxExceeded
 		close(pr.errAvail)
 		return ErrBalanceMaxExceeded
@@ -950,6 +973,122 @@ func (am *accountManager) managedExpireAccounts(threshold int64) []uint32 {
 	return deleted
 }
 
+// callAccounts returns information about all accounts. The expiry is the
+// host's EphemeralAccountExpiry.
+func (am *accountManager) callAccounts(expiry time.Duration) []modules.HostEphemeralAccount {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	accounts := make([]modules.HostEphemeralAccount, 0, len(am.accounts))
+	for _, acc := range am.accounts {
+		info := modules.HostEphemeralAccount{
+			ID:                      acc.id.String(),
+			Balance:                 acc.balance,
+			MaxBalance:              acc.maxBalance,
+			PendingRisk:             acc.pendingRisk,
+			BlockedWithdrawals:      uint64(acc.blockedWithdrawals.Len()),
+			BlockedWithdrawalsValue: acc.blockedWithdrawals.Value(),
+			LastActivity:            time.Unix(acc.lastTxnTime, 0),
+		}
+		if expiry > 0 {
+			info.Expiry = info.LastActivity.Add(expiry)
+		}
+		accounts = append(accounts, info)
+	}
+	return accounts
+}
+
+// callAccountsSummary returns aggregate information about all accounts.
+func (am *accountManager) callAccountsSummary() modules.HostEphemeralAccountsSummary {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	summary := modules.HostEphemeralAccountsSummary{
+		Accounts:            uint64(len(am.accounts)),
+		CurrentRisk:         am.currentRisk,
+		BlockedDeposits:     uint64(len(am.blockedDeposits)),
+		BlockedWithdrawals:  uint64(len(am.blockedWithdrawals)),
+		WithdrawalsInactive: am.withdrawalsInactive,
+	}
+	for _, acc := range am.accounts {
+		summary.TotalBalance = summary.TotalBalance.Add(acc.balance)
+		summary.BlockedWithdrawals += uint64(acc.blockedWithdrawals.Len())
+	}
+	return summary
+}
+
+// managedExpireAccount expires the account with the given id immediately. The
+// account is only expired once its pending persists finished, that way a
+// persist can't write the account back to disk after it was deleted.
+func (am *accountManager) managedExpireAccount(id modules.AccountID) error {
+	var index uint32
+	for {
+		am.mu.Lock()
+		acc, exists := am.accounts[id]
+		if !exists {
+			am.mu.Unlock()
+			return ErrAccountNotFound
+		}
+		if len(acc.persistResults) == 0 {
+			// Fail the withdrawals that wait for a deposit into the account.
+			for acc.blockedWithdrawals.Len() > 0 {
+				bw := acc.blockedWithdrawals.Pop().(*blockedWithdrawal)
+				select {
+				case bw.commitResult <- ErrAccountExpired:
+				default:
+				}
+			}
+			delete(am.accounts, id)
+			index = acc.index
+			am.mu.Unlock()
+			break
+		}
+		am.mu.Unlock()
+
+		select {
+		case <-am.h.tg.StopChan():
+			return ErrAccountExpiryCancelled
+		case <-time.After(expireAccountRetryInterval):
+		}
+	}
+
+	// Delete the account on disk and recycle its index.
+	deleted, err := am.staticAccountsPersister.callBatchDeleteAccount([]uint32{index})
+	if err != nil {
+		return errors.AddContext(err, "failed to delete expired account")
+	}
+	am.mu.Lock()
+	for _, index := range deleted {
+		am.accountBitfield.releaseIndex(index)
+	}
+	am.mu.Unlock()
+	return nil
+}
+
+// managedSetAccountMaxBalance caps the balance of the account with the given
+// id and persists the cap. A max balance of zero removes the cap.
+func (am *accountManager) managedSetAccountMaxBalance(id modules.AccountID, maxBalance types.Currency) error {
+	am.mu.Lock()
+	acc, exists := am.accounts[id]
+	if !exists {
+		am.mu.Unlock()
+		return ErrAccountNotFound
+	}
+	acc.maxBalance = maxBalance
+	pr := &persistResult{
+		errAvail: make(chan struct{}),
+	}
+	am.schedulePersist(acc, pr)
+	am.mu.Unlock()
+
+	select {
+	case <-pr.errAvail:
+		return pr.externErr
+	case <-am.h.tg.StopChan():
+		return ErrAccountPersist
+	}
+}
+
 // callAccountBalance will return the balance of an account.
 func (am *accountManager) callAccountBalance(id modules.AccountID) types.Currency {
 	am.mu.Lock()
@@ -1080,6 +1219,15 @@ func (a *account) depositExceedsMaxBalance(deposit, maxBalance types.Currency) b
 	return updatedBalance.Cmp(maxBalance) > 0
 }
 
+// capMaxBalance returns the max balance of the account given the host's
+// maximum ephemeral account balance.
+func (a *account) capMaxBalance(maxBalance types.Currency) types.Currency {
+	if !a.maxBalance.IsZero() && a.maxBalance.Cmp(maxBalance) < 0 {
+		return a.maxBalance
+	}
+	return maxBalance
+}
+
 // withdrawalExceedsBalance returns true if withdrawal is larger than the
 // account balance.
 func (a *account) withdrawalExceedsBalance(withdrawal types.Currency) bool {
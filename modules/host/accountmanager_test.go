@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
@@ -1162,6 +1163,101 @@ func TestAccountWithdrawalsInactive(t *testing.T) {
 	}
 }
 
+// TestAccountAdministration verifies the host can list, cap and expire
+// ephemeral accounts.
+func TestAccountAdministration(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := ht.Close()
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+	h := ht.host
+	am := h.staticAccountManager
+
+	// Prepare an account and fund it
+	_, accountID := prepareAccount()
+	if err = callDeposit(am, accountID, types.NewCurrency64(10)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the account is listed
+	accounts := h.EphemeralAccounts()
+	if len(accounts) != 1 || accounts[0].ID != accountID.String() {
+		t.Fatal("unexpected accounts", accounts)
+	}
+	if !accounts[0].Balance.Equals(types.NewCurrency64(10)) || !accounts[0].MaxBalance.IsZero() {
+		t.Fatal("unexpected account info", accounts[0])
+	}
+	summary := h.EphemeralAccountsSummary()
+	if summary.Accounts != 1 || !summary.TotalBalance.Equals(types.NewCurrency64(10)) {
+		t.Fatal("unexpected summary", summary)
+	}
+	if !summary.MaxBalance.Equals(h.InternalSettings().MaxEphemeralAccountBalance) {
+		t.Fatal("unexpected max balance", summary.MaxBalance)
+	}
+
+	// Cap the account and verify a deposit can't exceed the cap
+	if err = h.SetEphemeralAccountMaxBalance(accountID, types.NewCurrency64(15)); err != nil {
+		t.Fatal(err)
+	}
+	err = callDeposit(am, accountID, types.NewCurrency64(6))
+	if !errors.Contains(err, ErrBalanceMaxExceeded) {
+		t.Fatal("expected ErrBalanceMaxExceeded", err)
+	}
+	if err = callDeposit(am, accountID, types.NewCurrency64(5)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the cap survives a restart
+	if err = ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, ht.mux, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h = ht.host
+	am = h.staticAccountManager
+	accounts = h.EphemeralAccounts()
+	if len(accounts) != 1 || !accounts[0].MaxBalance.Equals(types.NewCurrency64(15)) || !accounts[0].Balance.Equals(types.NewCurrency64(15)) {
+		t.Fatal("unexpected accounts after restart", accounts)
+	}
+
+	// Expire the account and verify it's gone, also after a restart
+	if err = h.ExpireEphemeralAccount(accountID); err != nil {
+		t.Fatal(err)
+	}
+	if err = h.ExpireEphemeralAccount(accountID); !errors.Contains(err, ErrAccountNotFound) {
+		t.Fatal("expected ErrAccountNotFound", err)
+	}
+	if err = h.SetEphemeralAccountMaxBalance(accountID, types.ZeroCurrency); !errors.Contains(err, ErrAccountNotFound) {
+		t.Fatal("expected ErrAccountNotFound", err)
+	}
+	if err = ht.host.Close(); err != nil {
+		t.Fatal(err)
+	}
+	ht.host, err = New(ht.cs, ht.gateway, ht.tpool, ht.wallet, ht.mux, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accounts = ht.host.EphemeralAccounts(); len(accounts) != 0 {
+		t.Fatal("expected no accounts after expiry", accounts)
+	}
+	if balance := getAccountBalance(ht.host.staticAccountManager, accountID); !balance.IsZero() {
+		t.Fatal("expected zero balance after expiry", balance)
+	}
+}
+
 // managedCurrentRisk will return the current risk
 func managedCurrentRisk(am *accountManager) types.Currency {
 	am.mu.Lock()
@@ -90,6 +90,7 @@ type (
 		ID          modules.AccountID
 		Balance     types.Currency
 		LastTxnTime int64
+		MaxBalance  types.Currency
 	}
 
 	// indexLock contains a lock plus a count of the number of threads currently
@@ -615,6 +616,7 @@ func (a *account) accountData() *accountData {
 		ID:          a.id,
 		Balance:     a.balance,
 		LastTxnTime: a.lastTxnTime,
+		MaxBalance:  a.maxBalance,
 	}
 }
 
@@ -625,6 +627,7 @@ func (a *accountData) account(index uint32) *account {
 		id:                 a.ID,
 		balance:            a.Balance,
 		lastTxnTime:        a.LastTxnTime,
+		maxBalance:         a.MaxBalance,
 		index:              index,
 		blockedWithdrawals: make(blockedWithdrawalHeap, 0),
 	}
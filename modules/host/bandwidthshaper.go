@@ -0,0 +1,411 @@
+package host
+
+// bandwidthshaper.go shapes the bandwidth of the host's RPCs. Every class of
+// RPC has its own queue with its own budget. Within a queue, pending requests
+// are granted round-robin across renters so that a single renter with many
+// concurrent streams can't starve the others. On top of that every renter is
+// limited to the per-renter budget of the class.
+//
+// Budgets are enforced by pacing. Every granted request pushes the time at
+// which the queue and the renter can be served next into the future by the
+// time it takes to transfer the request at the configured rate.
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// bandwidthChunkSize is the max number of bytes a single request for
+// bandwidth can ask for. Larger reads and writes are split up to keep the
+// scheduling fair.
+const bandwidthChunkSize = 1 << 14 // 16 KiB
+
+// bandwidthClass is a class of RPCs which shares a bandwidth budget.
+type bandwidthClass int
+
+const (
+	bandwidthClassNone bandwidthClass = iota - 1
+	bandwidthClassDownload
+	bandwidthClassUpload
+	bandwidthClassRegistry
+	numBandwidthClasses
+)
+
+// errBandwidthWaitInterrupted is returned if waiting for bandwidth was
+// interrupted.
+var errBandwidthWaitInterrupted = errors.New("waiting for bandwidth was interrupted")
+
+type (
+	// bandwidthShaper contains the queues of all bandwidth classes.
+	bandwidthShaper struct {
+		staticQueues [numBandwidthClasses]*bandwidthQueue
+	}
+
+	// bandwidthQueue shapes the bandwidth of a single class of RPCs.
+	bandwidthQueue struct {
+		bps       uint64
+		renterBPS uint64
+
+		// nextFree is the time at which the class budget allows for the next
+		// request to be granted.
+		nextFree time.Time
+
+		// renters contains the renters with pending requests or with a
+		// nextFree in the future. active contains the renters with pending
+		// requests in round-robin order.
+		renters map[string]*bandwidthRenter
+		active  []*bandwidthRenter
+
+		// timer wakes up the queue at timerAt to grant requests that are
+		// waiting for budget.
+		timer   *time.Timer
+		timerAt time.Time
+
+		// Metrics.
+		bytes          uint64
+		throttledBytes uint64
+		waitTime       time.Duration
+		queued         uint64
+
+		mu sync.Mutex
+	}
+
+	// bandwidthRenter tracks the pending requests and the budget of a single
+	// renter within a queue.
+	bandwidthRenter struct {
+		nextFree time.Time
+		pending  []*bandwidthRequest
+	}
+
+	// bandwidthRequest is a request for n bytes of bandwidth. ready is closed
+	// once the request was granted.
+	bandwidthRequest struct {
+		n         uint64
+		cancelled bool
+		granted   bool
+		ready     chan struct{}
+	}
+)
+
+// newBandwidthShaper creates a new shaper without any limits.
+func newBandwidthShaper() *bandwidthShaper {
+	var bs bandwidthShaper
+	for i := range bs.staticQueues {
+		bs.staticQueues[i] = &bandwidthQueue{
+			renters: make(map[string]*bandwidthRenter),
+		}
+	}
+	return &bs
+}
+
+// managedSetLimits updates the limits of all classes.
+func (bs *bandwidthShaper) managedSetLimits(limits modules.HostBandwidthLimits) {
+	bs.staticQueues[bandwidthClassDownload].managedSetLimits(limits.Download, limits.RenterDownload)
+	bs.staticQueues[bandwidthClassUpload].managedSetLimits(limits.Upload, limits.RenterUpload)
+	bs.staticQueues[bandwidthClassRegistry].managedSetLimits(limits.Registry, limits.RenterRegistry)
+}
+
+// newShapedReadWriter wraps rw in a shapedReadWriter that doesn't shape any
+// bandwidth until its classes are set.
+func (bs *bandwidthShaper) newShapedReadWriter(rw io.ReadWriter, cancel <-chan struct{}) *shapedReadWriter {
+	return &shapedReadWriter{
+		staticRW:     rw,
+		staticCancel: cancel,
+		staticShaper: bs,
+	}
+}
+
+// managedMetrics returns the metrics of a class.
+func (bq *bandwidthQueue) managedMetrics() modules.HostBandwidthMetrics {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	return modules.HostBandwidthMetrics{
+		Bytes:          bq.bytes,
+		ThrottledBytes: bq.throttledBytes,
+		WaitTime:       bq.waitTime,
+		QueuedRequests: bq.queued,
+		Renters:        uint64(len(bq.renters)),
+	}
+}
+
+// managedSetLimits updates the limits of the queue and grants the requests
+// that are no longer limited.
+func (bq *bandwidthQueue) managedSetLimits(bps, renterBPS uint64) {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	bq.bps = bps
+	bq.renterBPS = renterBPS
+	bq.schedule(time.Now())
+}
+
+// managedWait blocks until the renter is granted n bytes of bandwidth.
+func (bq *bandwidthQueue) managedWait(renter string, n uint64, cancel <-chan struct{}) error {
+	bq.mu.Lock()
+	bq.bytes += n
+	if bq.bps == 0 && bq.renterBPS == 0 {
+		bq.mu.Unlock()
+		return nil
+	}
+
+	// Queue the request and try to grant it right away.
+	req := bq.enqueue(renter, n)
+	start := time.Now()
+	bq.schedule(start)
+	if req.granted {
+		bq.mu.Unlock()
+		return nil
+	}
+	bq.throttledBytes += n
+	bq.mu.Unlock()
+
+	// Wait for the request to be granted.
+	select {
+	case <-req.ready:
+	case <-cancel:
+	}
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	bq.waitTime += time.Since(start)
+	if !req.granted {
+		req.cancelled = true
+		return errBandwidthWaitInterrupted
+	}
+	return nil
+}
+
+// enqueue adds a request for n bytes to the renter's pending requests.
+func (bq *bandwidthQueue) enqueue(renter string, n uint64) *bandwidthRequest {
+	r, exists := bq.renters[renter]
+	if !exists {
+		r = &bandwidthRenter{}
+		bq.renters[renter] = r
+	}
+	if len(r.pending) == 0 {
+		bq.active = append(bq.active, r)
+	}
+	req := &bandwidthRequest{
+		n:     n,
+		ready: make(chan struct{}),
+	}
+	r.pending = append(r.pending, req)
+	bq.queued++
+	return req
+}
+
+// schedule grants pending requests round-robin across renters for as long as
+// the budgets allow and sets a timer to grant the remaining ones later.
+func (bq *bandwidthQueue) schedule(now time.Time) {
+	for len(bq.active) > 0 {
+		if bq.bps > 0 && bq.nextFree.After(now) {
+			bq.setTimer(now, bq.nextFree)
+			break
+		}
+
+		// Find the first renter that isn't limited by its own budget.
+		idx := -1
+		var earliest time.Time
+		for i, r := range bq.active {
+			if bq.renterBPS == 0 || !r.nextFree.After(now) {
+				idx = i
+				break
+			}
+			if earliest.IsZero() || r.nextFree.Before(earliest) {
+				earliest = r.nextFree
+			}
+		}
+		if idx == -1 {
+			bq.setTimer(now, earliest)
+			break
+		}
+
+		// Pop the renter's first request and move the renter to the back of
+		// the queue if it has more pending requests.
+		r := bq.active[idx]
+		req := r.pending[0]
+		r.pending = r.pending[1:]
+		bq.active = append(bq.active[:idx], bq.active[idx+1:]...)
+		if len(r.pending) > 0 {
+			bq.active = append(bq.active, r)
+		}
+		bq.queued--
+		if req.cancelled {
+			continue
+		}
+
+		// Grant the request.
+		req.granted = true
+		close(req.ready)
+		bq.nextFree = paceBandwidth(bq.nextFree, now, req.n, bq.bps)
+		r.nextFree = paceBandwidth(r.nextFree, now, req.n, bq.renterBPS)
+	}
+
+	// Forget about the renters without pending requests whose budget is
+	// available again.
+	for key, r := range bq.renters {
+		if len(r.pending) == 0 && !r.nextFree.After(now) {
+			delete(bq.renters, key)
+		}
+	}
+}
+
+// setTimer makes sure that the queue is woken up at the given time.
+func (bq *bandwidthQueue) setTimer(now, at time.Time) {
+	if bq.timer != nil && !bq.timerAt.After(at) {
+		return // timer fires early enough
+	}
+	if bq.timer != nil {
+		bq.timer.Stop()
+	}
+	bq.timerAt = at
+	bq.timer = time.AfterFunc(at.Sub(now), bq.threadedWake)
+}
+
+// threadedWake is called by the queue's timer to grant the requests that
+// were waiting for budget.
+func (bq *bandwidthQueue) threadedWake() {
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	bq.timer = nil
+	bq.schedule(time.Now())
+}
+
+// bandwidthRenterKey returns the key that identifies a renter for bandwidth
+// shaping. Renters are identified by their contract and by their IP if there
+// is no contract. Ephemeral accounts are chosen freely by the renter so they
+// are only used if the IP is unknown.
+func bandwidthRenterKey(fcid types.FileContractID, addr net.Addr, account modules.AccountID) string {
+	if fcid != (types.FileContractID{}) {
+		return "contract:" + fcid.String()
+	}
+	if addr != nil {
+		host, _, err := net.SplitHostPort(addr.String())
+		if err != nil {
+			host = addr.String()
+		}
+		if host != "" {
+			return "ip:" + host
+		}
+	}
+	if !account.IsZeroAccount() {
+		return "account:" + account.String()
+	}
+	return "unknown"
+}
+
+// paceBandwidth returns the time at which the next request can be granted
+// after granting n bytes at the given rate.
+func paceBandwidth(nextFree, now time.Time, n, bps uint64) time.Time {
+	if bps == 0 {
+		return nextFree
+	}
+	if nextFree.Before(now) {
+		nextFree = now
+	}
+	return nextFree.Add(time.Duration(n) * time.Second / time.Duration(bps))
+}
+
+type (
+	// shapedReadWriter shapes the reads and writes of the wrapped
+	// io.ReadWriter according to the bandwidth classes set for the current
+	// RPC.
+	shapedReadWriter struct {
+		renter     string
+		readQueue  *bandwidthQueue
+		writeQueue *bandwidthQueue
+		mu         sync.Mutex
+
+		staticCancel <-chan struct{}
+		staticRW     io.ReadWriter
+		staticShaper *bandwidthShaper
+	}
+
+	// shapedConn is a net.Conn that shapes its reads and writes.
+	shapedConn struct {
+		net.Conn
+		*shapedReadWriter
+	}
+)
+
+// newShapedConn wraps conn in a shapedConn that doesn't shape any bandwidth
+// until its classes are set.
+func (bs *bandwidthShaper) newShapedConn(conn net.Conn, cancel <-chan struct{}) *shapedConn {
+	return &shapedConn{
+		Conn:             conn,
+		shapedReadWriter: bs.newShapedReadWriter(conn, cancel),
+	}
+}
+
+// Read implements the io.Reader interface.
+func (sc *shapedConn) Read(b []byte) (int, error) { return sc.shapedReadWriter.Read(b) }
+
+// Write implements the io.Writer interface.
+func (sc *shapedConn) Write(b []byte) (int, error) { return sc.shapedReadWriter.Write(b) }
+
+// managedSetClasses sets the renter and the classes used for shaping reads
+// and writes. Reads are data received by the host and writes are data sent by
+// the host. bandwidthClassNone disables shaping for that direction.
+func (srw *shapedReadWriter) managedSetClasses(renter string, readClass, writeClass bandwidthClass) {
+	srw.mu.Lock()
+	defer srw.mu.Unlock()
+	srw.renter = renter
+	srw.readQueue, srw.writeQueue = nil, nil
+	if readClass != bandwidthClassNone {
+		srw.readQueue = srw.staticShaper.staticQueues[readClass]
+	}
+	if writeClass != bandwidthClassNone {
+		srw.writeQueue = srw.staticShaper.staticQueues[writeClass]
+	}
+}
+
+// Read implements the io.Reader interface. The bandwidth is requested after
+// reading since the size of the read is only known afterwards.
+func (srw *shapedReadWriter) Read(b []byte) (int, error) {
+	srw.mu.Lock()
+	renter, bq := srw.renter, srw.readQueue
+	srw.mu.Unlock()
+	if bq == nil {
+		return srw.staticRW.Read(b)
+	}
+	if len(b) > bandwidthChunkSize {
+		b = b[:bandwidthChunkSize]
+	}
+	n, err := srw.staticRW.Read(b)
+	if n > 0 {
+		err = errors.Compose(err, bq.managedWait(renter, uint64(n), srw.staticCancel))
+	}
+	return n, err
+}
+
+// Write implements the io.Writer interface. The data is written in chunks
+// and the bandwidth for each chunk is requested before writing it.
+func (srw *shapedReadWriter) Write(b []byte) (int, error) {
+	srw.mu.Lock()
+	renter, bq := srw.renter, srw.writeQueue
+	srw.mu.Unlock()
+	if bq == nil {
+		return srw.staticRW.Write(b)
+	}
+	var written int
+	for len(b) > 0 {
+		chunk := b
+		if len(chunk) > bandwidthChunkSize {
+			chunk = chunk[:bandwidthChunkSize]
+		}
+		if err := bq.managedWait(renter, uint64(len(chunk)), srw.staticCancel); err != nil {
+			return written, err
+		}
+		n, err := srw.staticRW.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[len(chunk):]
+	}
+	return written, nil
+}
@@ -0,0 +1,225 @@
+package host
+
+import (
+	"bytes"
+	"net"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// slowBPS is a bandwidth limit which makes a single chunk take more than an
+// hour. That way the timers of the queue never fire during a test.
+const slowBPS = 4
+
+// TestBandwidthQueueFairness checks that pending requests are granted
+// round-robin across renters.
+func TestBandwidthQueueFairness(t *testing.T) {
+	t.Parallel()
+	bq := newBandwidthShaper().staticQueues[bandwidthClassDownload]
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	bq.bps = slowBPS
+
+	// Renter a queues 3 requests before renter b queues 1.
+	a1 := bq.enqueue("a", bandwidthChunkSize)
+	a2 := bq.enqueue("a", bandwidthChunkSize)
+	a3 := bq.enqueue("a", bandwidthChunkSize)
+	b1 := bq.enqueue("b", bandwidthChunkSize)
+
+	// Every call to schedule after the budget became available again should
+	// grant exactly one request, alternating between the renters.
+	now := time.Now()
+	expected := []*bandwidthRequest{a1, b1, a2, a3}
+	for i, req := range expected {
+		bq.schedule(now.Add(time.Duration(i) * 2 * time.Hour))
+		for j, other := range expected {
+			if other.granted != (j <= i) {
+				t.Fatalf("%v: request %v granted: %v", i, j, other.granted)
+			}
+		}
+		select {
+		case <-req.ready:
+		default:
+			t.Fatal("ready chan wasn't closed", i)
+		}
+	}
+	if bq.queued != 0 || len(bq.active) != 0 {
+		t.Fatal("queue should be empty", bq.queued, len(bq.active))
+	}
+}
+
+// TestBandwidthQueueRenterLimit checks that a renter that is limited by its
+// own budget doesn't block the other renters.
+func TestBandwidthQueueRenterLimit(t *testing.T) {
+	t.Parallel()
+	bq := newBandwidthShaper().staticQueues[bandwidthClassUpload]
+	bq.mu.Lock()
+	defer bq.mu.Unlock()
+	bq.renterBPS = slowBPS
+
+	a1 := bq.enqueue("a", bandwidthChunkSize)
+	a2 := bq.enqueue("a", bandwidthChunkSize)
+	b1 := bq.enqueue("b", bandwidthChunkSize)
+
+	// Both renters get their first request right away.
+	now := time.Now()
+	bq.schedule(now)
+	if !a1.granted || !b1.granted || a2.granted {
+		t.Fatal("unexpected grants", a1.granted, b1.granted, a2.granted)
+	}
+	if bq.timer == nil {
+		t.Fatal("timer wasn't set")
+	}
+
+	// Renter a gets its second request once its budget is available again.
+	bq.schedule(now.Add(2 * time.Hour))
+	if !a2.granted {
+		t.Fatal("second request wasn't granted")
+	}
+
+	// All renters are forgotten once their budget is available again.
+	bq.schedule(now.Add(4 * time.Hour))
+	if len(bq.renters) != 0 {
+		t.Fatal("renters weren't pruned", len(bq.renters))
+	}
+}
+
+// TestBandwidthQueueWait checks the blocking behavior and the metrics of
+// managedWait.
+func TestBandwidthQueueWait(t *testing.T) {
+	t.Parallel()
+	bs := newBandwidthShaper()
+	bq := bs.staticQueues[bandwidthClassRegistry]
+
+	// Without limits requests are granted right away.
+	if err := bq.managedWait("a", 100, nil); err != nil {
+		t.Fatal(err)
+	}
+	if m := bq.managedMetrics(); m.Bytes != 100 || m.ThrottledBytes != 0 || m.Renters != 0 {
+		t.Fatal("unexpected metrics", m)
+	}
+
+	// Limit the class. The first request is granted, the second one has to
+	// wait until it is cancelled.
+	bs.managedSetLimits(modules.HostBandwidthLimits{Registry: slowBPS})
+	if err := bq.managedWait("a", bandwidthChunkSize, nil); err != nil {
+		t.Fatal(err)
+	}
+	cancel := make(chan struct{})
+	close(cancel)
+	if err := bq.managedWait("a", bandwidthChunkSize, cancel); err != errBandwidthWaitInterrupted {
+		t.Fatal("expected errBandwidthWaitInterrupted but got", err)
+	}
+	m := bq.managedMetrics()
+	if m.Bytes != 100+2*bandwidthChunkSize || m.ThrottledBytes != bandwidthChunkSize || m.QueuedRequests != 1 {
+		t.Fatal("unexpected metrics", m)
+	}
+
+	// A waiting request is granted once the limit is removed.
+	done := make(chan error)
+	go func() {
+		done <- bq.managedWait("b", bandwidthChunkSize, nil)
+	}()
+	for bq.managedMetrics().QueuedRequests != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	bs.managedSetLimits(modules.HostBandwidthLimits{})
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("request wasn't granted after removing the limit")
+	}
+	if m := bq.managedMetrics(); m.QueuedRequests != 0 || m.WaitTime == 0 {
+		t.Fatal("unexpected metrics", m)
+	}
+}
+
+// TestShapedReadWriter checks that the shapedReadWriter passes through the
+// data and accounts for it in the right classes.
+func TestShapedReadWriter(t *testing.T) {
+	t.Parallel()
+	bs := newBandwidthShaper()
+	var buf bytes.Buffer
+	srw := bs.newShapedReadWriter(&buf, nil)
+
+	// Without classes nothing is recorded.
+	data := fastrand.Bytes(5*bandwidthChunkSize + 1)
+	if n, err := srw.Write(data); err != nil || n != len(data) {
+		t.Fatal(n, err)
+	}
+	if m := bs.staticQueues[bandwidthClassDownload].managedMetrics(); m.Bytes != 0 {
+		t.Fatal("unexpected metrics", m)
+	}
+	buf.Reset()
+
+	// Writes are recorded as downloads and reads as uploads.
+	srw.managedSetClasses("a", bandwidthClassUpload, bandwidthClassDownload)
+	if n, err := srw.Write(data); err != nil || n != len(data) {
+		t.Fatal(n, err)
+	}
+	read := make([]byte, len(data))
+	var total int
+	for total < len(read) {
+		n, err := srw.Read(read[total:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > bandwidthChunkSize {
+			t.Fatal("read more than a chunk", n)
+		}
+		total += n
+	}
+	if !bytes.Equal(read, data) {
+		t.Fatal("data mismatch")
+	}
+	if m := bs.staticQueues[bandwidthClassDownload].managedMetrics(); m.Bytes != uint64(len(data)) {
+		t.Fatal("unexpected download metrics", m)
+	}
+	if m := bs.staticQueues[bandwidthClassUpload].managedMetrics(); m.Bytes != uint64(len(data)) {
+		t.Fatal("unexpected upload metrics", m)
+	}
+}
+
+// TestBandwidthRenterKey checks the identification of renters.
+func TestBandwidthRenterKey(t *testing.T) {
+	t.Parallel()
+	addr := &net.TCPAddr{IP: net.IPv4(1, 2, 3, 4), Port: 1234}
+	aid, _ := modules.NewAccountID()
+	var fcid types.FileContractID
+	fastrand.Read(fcid[:])
+
+	if key := bandwidthRenterKey(types.FileContractID{}, addr, modules.AccountID{}); key != "ip:1.2.3.4" {
+		t.Fatal("unexpected key", key)
+	}
+	if key := bandwidthRenterKey(fcid, addr, aid); key != "contract:"+fcid.String() {
+		t.Fatal("unexpected key", key)
+	}
+	// The account doesn't take precedence over the IP.
+	if key := bandwidthRenterKey(types.FileContractID{}, addr, aid); key != "ip:1.2.3.4" {
+		t.Fatal("unexpected key", key)
+	}
+	if key := bandwidthRenterKey(types.FileContractID{}, nil, aid); key != "account:"+aid.String() {
+		t.Fatal("unexpected key", key)
+	}
+}
+
+// TestProgramBandwidthClasses checks that registry programs use the registry
+// class.
+func TestProgramBandwidthClasses(t *testing.T) {
+	t.Parallel()
+	read, write := programBandwidthClasses(modules.Program{{Specifier: modules.SpecifierReadSector}})
+	if read != bandwidthClassUpload || write != bandwidthClassDownload {
+		t.Fatal("unexpected classes", read, write)
+	}
+	read, write = programBandwidthClasses(modules.Program{{Specifier: modules.SpecifierHasSector}, {Specifier: modules.SpecifierReadRegistry}})
+	if read != bandwidthClassRegistry || write != bandwidthClassRegistry {
+		t.Fatal("unexpected classes", read, write)
+	}
+}
@@ -77,6 +77,15 @@ var (
 	// prevent the host from having too much money at risk.
 	defaultMaxEphemeralAccountRisk = types.SiacoinPrecision.Mul64(5)
 
+	// pricingEngineCheckFrequency defines how often the host checks whether
+	// the prices of the pricing engine need to be updated.
+	pricingEngineCheckFrequency = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute,
+		Testnet:  time.Minute,
+		Testing:  time.Millisecond * 100,
+	}).(time.Duration)
+
 	// logAllLimit is the number of errors of each type that the host will log
 	// before switching to probabilistic logging. If there are not many errors,
 	// it is reasonable that all errors get logged. If there are lots of
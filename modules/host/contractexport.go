@@ -0,0 +1,158 @@
+package host
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// contractRecord converts a storage obligation into a record for revenue
+// reporting. The timestamp is the timestamp of the block at the reporting
+// height of the obligation. Rejected obligations never made it into the
+// blockchain and therefore don't have any revenue. The revenue of unresolved
+// obligations is the potential revenue.
+func contractRecord(so modules.StorageObligation, timestamp types.Timestamp) modules.HostContractRecord {
+	record := modules.HostContractRecord{
+		ObligationId:     so.ObligationId,
+		ObligationStatus: so.ObligationStatus,
+
+		ReportingHeight: reportingHeight(so),
+		Timestamp:       timestamp,
+
+		NegotiationHeight: so.NegotiationHeight,
+		ExpirationHeight:  so.ExpirationHeight,
+		ProofDeadline:     so.ProofDeadLine,
+		ProofHeight:       so.ProofHeight,
+
+		ContractCost:     so.ContractCost,
+		StorageRevenue:   so.PotentialStorageRevenue,
+		UploadRevenue:    so.PotentialUploadRevenue,
+		DownloadRevenue:  so.PotentialDownloadRevenue,
+		AccountFunding:   so.PotentialAccountFunding,
+		TransactionFees:  so.TransactionFeesAdded,
+		LockedCollateral: so.LockedCollateral,
+		RiskedCollateral: so.RiskedCollateral,
+
+		OriginTransactionID:   so.TransactionID,
+		RevisionTransactionID: so.RevisionTransactionID,
+		ProofTransactionID:    so.ProofTransactionID,
+	}
+	switch so.ObligationStatus {
+	case obligationRejected.String():
+		record.ContractCost = types.ZeroCurrency
+		record.StorageRevenue = types.ZeroCurrency
+		record.UploadRevenue = types.ZeroCurrency
+		record.DownloadRevenue = types.ZeroCurrency
+		record.AccountFunding = types.ZeroCurrency
+	case obligationFailed.String():
+		record.LostCollateral = so.RiskedCollateral
+		record.LostRevenue = so.ContractCost.Add(so.PotentialStorageRevenue).Add(so.PotentialDownloadRevenue).Add(so.PotentialUploadRevenue).Add(so.PotentialAccountFunding)
+	}
+	return record
+}
+
+// reportingHeight returns the height at which the revenue of a storage
+// obligation is reported. That is the height at which the obligation was
+// resolved or the negotiation height if it is still unresolved. Obligations
+// which were resolved before the host started tracking the resolution height
+// are reported at their proof deadline instead.
+func reportingHeight(so modules.StorageObligation) types.BlockHeight {
+	if so.ObligationStatus == obligationUnresolved.String() {
+		return so.NegotiationHeight
+	}
+	if so.ResolutionHeight != 0 {
+		return so.ResolutionHeight
+	}
+	if so.ProofDeadLine != 0 {
+		return so.ProofDeadLine
+	}
+	return so.ExpirationHeight
+}
+
+// exchangeRateAt returns the entry of the sorted exchange rate table that
+// applies at the given timestamp.
+func exchangeRateAt(rates []modules.HostExchangeRate, timestamp types.Timestamp) (modules.HostExchangeRate, bool) {
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Timestamp > timestamp
+	})
+	if i == 0 {
+		return modules.HostExchangeRate{}, false
+	}
+	return rates[i-1], true
+}
+
+// addFiat adds the fiat values of the record using the given exchange rate.
+func addFiat(record *modules.HostContractRecord, r modules.HostExchangeRate) error {
+	rate, err := types.ParseExchangeRate(r.Rate)
+	if err != nil {
+		return err
+	}
+	if rate == nil {
+		return types.ErrUnexpectedFormat
+	}
+	record.Fiat = &modules.HostContractRecordFiat{
+		Rate:             r.Rate,
+		ContractCost:     rate.Apply(record.ContractCost),
+		StorageRevenue:   rate.Apply(record.StorageRevenue),
+		UploadRevenue:    rate.Apply(record.UploadRevenue),
+		DownloadRevenue:  rate.Apply(record.DownloadRevenue),
+		AccountFunding:   rate.Apply(record.AccountFunding),
+		TransactionFees:  rate.Apply(record.TransactionFees),
+		LockedCollateral: rate.Apply(record.LockedCollateral),
+		RiskedCollateral: rate.Apply(record.RiskedCollateral),
+		LostCollateral:   rate.Apply(record.LostCollateral),
+		LostRevenue:      rate.Apply(record.LostRevenue),
+	}
+	return nil
+}
+
+// ContractRecords returns the records of the storage obligations of the host
+// that are selected by the export params, sorted by their reporting height.
+func (h *Host) ContractRecords(params modules.HostContractExportParams) ([]modules.HostContractRecord, error) {
+	if err := h.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+	rates := append([]modules.HostExchangeRate(nil), params.Rates...)
+	sort.SliceStable(rates, func(i, j int) bool {
+		return rates[i].Timestamp < rates[j].Timestamp
+	})
+
+	// Fetch the obligations before looking up the timestamps, the consensus
+	// set must not be called while holding the host's lock.
+	sos := h.StorageObligations()
+	sort.SliceStable(sos, func(i, j int) bool {
+		return reportingHeight(sos[i]) < reportingHeight(sos[j])
+	})
+	records := make([]modules.HostContractRecord, 0, len(sos))
+	for _, so := range sos {
+		if so.ObligationStatus == obligationUnresolved.String() && !params.IncludeUnresolved {
+			continue
+		}
+		height := reportingHeight(so)
+		if height < params.StartHeight || (params.EndHeight != 0 && height > params.EndHeight) {
+			continue
+		}
+		var timestamp types.Timestamp
+		if block, exists := h.cs.BlockAtHeight(height); exists {
+			timestamp = block.Timestamp
+		}
+		if timestamp < params.StartTime || (params.EndTime != 0 && timestamp > params.EndTime) {
+			continue
+		}
+		record := contractRecord(so, timestamp)
+		if r, exists := exchangeRateAt(rates, timestamp); exists {
+			if err := addFiat(&record, r); err != nil {
+				return nil, errors.AddContext(err, "unable to convert record to fiat")
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
@@ -0,0 +1,206 @@
+package host
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestContractRecord is a unit test for contractRecord.
+func TestContractRecord(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	sc := types.SiacoinPrecision.Mul64
+	so := modules.StorageObligation{
+		ContractCost:             sc(1),
+		LockedCollateral:         sc(20),
+		PotentialDownloadRevenue: sc(2),
+		PotentialStorageRevenue:  sc(3),
+		PotentialUploadRevenue:   sc(4),
+		RiskedCollateral:         sc(10),
+		NegotiationHeight:        5,
+		ExpirationHeight:         50,
+		ProofDeadLine:            60,
+		ProofHeight:              55,
+		ResolutionHeight:         60,
+		ObligationStatus:         obligationSucceeded.String(),
+	}
+
+	// Succeeded obligations are reported at their resolution height without
+	// any losses.
+	record := contractRecord(so, 1000)
+	if record.ReportingHeight != 60 || record.Timestamp != 1000 || record.ProofHeight != 55 {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	if !record.StorageRevenue.Equals(sc(3)) || !record.LostCollateral.IsZero() || !record.LostRevenue.IsZero() {
+		t.Fatalf("unexpected revenue %+v", record)
+	}
+
+	// Failed obligations lose the risked collateral and the revenue.
+	so.ObligationStatus = obligationFailed.String()
+	record = contractRecord(so, 1000)
+	if !record.LostCollateral.Equals(sc(10)) || !record.LostRevenue.Equals(sc(10)) {
+		t.Fatalf("unexpected losses %+v", record)
+	}
+
+	// Rejected obligations don't have any revenue.
+	so.ObligationStatus = obligationRejected.String()
+	if record := contractRecord(so, 1000); !record.ContractCost.IsZero() || !record.StorageRevenue.IsZero() || !record.UploadRevenue.IsZero() || !record.DownloadRevenue.IsZero() || !record.LostRevenue.IsZero() {
+		t.Fatalf("unexpected revenue %+v", record)
+	}
+
+	// Unresolved obligations are reported at their negotiation height with
+	// their potential revenue.
+	so.ObligationStatus = obligationUnresolved.String()
+	if record := contractRecord(so, 1000); record.ReportingHeight != 5 || !record.StorageRevenue.Equals(sc(3)) {
+		t.Fatalf("unexpected record %+v", record)
+	}
+
+	// The fiat values are computed with the given rate.
+	if err := addFiat(&record, modules.HostExchangeRate{Rate: "0.5 usd"}); err != nil {
+		t.Fatal(err)
+	}
+	if record.Fiat == nil || record.Fiat.StorageRevenue != "1.5000" || record.Fiat.LostCollateral != "5.0000" {
+		t.Fatalf("unexpected fiat values %+v", record.Fiat)
+	}
+}
+
+// TestExchangeRateAt is a unit test for exchangeRateAt.
+func TestExchangeRateAt(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rates, err := modules.ParseHostExchangeRates("200=0.02 usd,100=0.01 usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		timestamp types.Timestamp
+		rate      string
+		exists    bool
+	}{
+		{99, "", false},
+		{100, "0.01 usd", true},
+		{199, "0.01 usd", true},
+		{200, "0.02 usd", true},
+		{1000, "0.02 usd", true},
+	}
+	for _, test := range tests {
+		r, exists := exchangeRateAt(rates, test.timestamp)
+		if exists != test.exists || r.Rate != test.rate {
+			t.Errorf("exchangeRateAt(%v): expected %q %v, got %q %v", test.timestamp, test.rate, test.exists, r.Rate, exists)
+		}
+	}
+}
+
+// TestContractRecords tests exporting the contract records of a host.
+func TestContractRecords(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid params are rejected.
+	_, err = ht.host.ContractRecords(modules.HostContractExportParams{StartHeight: 10, EndHeight: 5})
+	if !errors.Contains(err, modules.ErrInvalidExportRange) {
+		t.Fatal("expected ErrInvalidExportRange but got", err)
+	}
+	_, err = ht.host.ContractRecords(modules.HostContractExportParams{
+		Rates: []modules.HostExchangeRate{{Timestamp: 1, Rate: "usd"}},
+	})
+	if !errors.Contains(err, types.ErrUnexpectedFormat) {
+		t.Fatal("expected ErrUnexpectedFormat but got", err)
+	}
+
+	// Add a storage obligation and export it.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := ht.host.ContractRecords(modules.HostContractExportParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatal("expected unresolved obligation to be skipped but got", len(records))
+	}
+	records, err = ht.host.ContractRecords(modules.HostContractExportParams{
+		Rates:             []modules.HostExchangeRate{{Timestamp: 0, Rate: "0.01 usd"}},
+		IncludeUnresolved: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].ObligationId != so.id() || records[0].OriginTransactionID != so.transactionID() {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if records[0].Timestamp == 0 || records[0].Fiat == nil || records[0].Fiat.Rate != "0.01 usd" {
+		t.Fatalf("unexpected record %+v", records[0])
+	}
+
+	// Obligations outside of the range are not exported.
+	height := records[0].ReportingHeight
+	records, err = ht.host.ContractRecords(modules.HostContractExportParams{StartHeight: height + 1, IncludeUnresolved: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 0 {
+		t.Fatal("expected no records but got", len(records))
+	}
+
+	// Obligations which were resolved before the resolution height was
+	// tracked are reported at their proof deadline.
+	legacy, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy.ObligationStatus = obligationSucceeded
+	err = ht.host.db.Update(func(tx *bolt.Tx) error {
+		return putStorageObligation(tx, legacy)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err = ht.host.ContractRecords(modules.HostContractExportParams{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, record := range records {
+		if record.ObligationId != legacy.id() {
+			continue
+		}
+		found = true
+		if record.ReportingHeight != legacy.proofDeadline() || record.ReportingHeight == 0 {
+			t.Fatalf("expected reporting height %v but got %v", legacy.proofDeadline(), record.ReportingHeight)
+		}
+	}
+	if !found {
+		t.Fatal("legacy obligation wasn't exported")
+	}
+}
@@ -65,6 +65,14 @@ const (
 	// sector counters on disk in AddSectorBatch and RemoveSectorBatch.
 	maxSectorBatchThreads = 100
 
+	// folderOperationThreads is the number of threads moving sectors during
+	// a storage folder migration or rebalance.
+	folderOperationThreads = 25
+
+	// redundancyRebuildThreads is the number of threads creating, moving and
+	// removing sector mirrors during a redundancy rebuild.
+	redundancyRebuildThreads = 10
+
 	// sectorMetadataDiskSize defines the number of bytes it takes to store the
 	// metadata of a single sector on disk.
 	sectorMetadataDiskSize = 14
@@ -149,3 +157,44 @@ var (
 		Testing:  time.Second * 8,
 	}).(time.Duration)
 )
+
+var (
+	// tierRebalanceInterval specifies how often the contract manager moves
+	// sectors between the fast and the slow storage folder tier.
+	tierRebalanceInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testnet:  time.Minute * 10,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// tierPromotionThreshold is the number of reads since the last rebalance,
+	// including the decayed reads of earlier periods, at which a sector is
+	// promoted to the fast tier.
+	tierPromotionThreshold = build.Select(build.Var{
+		Dev:      uint64(4),
+		Standard: uint64(8),
+		Testnet:  uint64(8),
+		Testing:  uint64(2),
+	}).(uint64)
+
+	// redundancyRebuildInterval specifies how often the contract manager
+	// checks the redundancy of all sectors if no rebuild was triggered by a
+	// storage folder change or a failed read.
+	redundancyRebuildInterval = build.Select(build.Var{
+		Dev:      time.Minute * 5,
+		Standard: time.Hour,
+		Testnet:  time.Hour,
+		Testing:  time.Second * 3,
+	}).(time.Duration)
+
+	// maxTierMovesPerRebalance is the maximum number of sectors that are
+	// promoted and demoted during a single rebalance, to limit the disk I/O
+	// caused by tiering.
+	maxTierMovesPerRebalance = build.Select(build.Var{
+		Dev:      10,
+		Standard: 250,
+		Testnet:  250,
+		Testing:  10,
+	}).(int)
+)
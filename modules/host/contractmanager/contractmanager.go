@@ -35,6 +35,7 @@ import (
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/persist"
 	siasync "go.thebigfile.com/bigd/sync"
+	"go.thebigfile.com/bigd/types"
 )
 
 // ContractManager is responsible for managing contracts that the host has with
@@ -114,10 +115,37 @@ type ContractManager struct {
 	sectorLocations map[sectorID]sectorLocation
 	storageFolders  map[uint16]*storageFolder
 
+	// redundancy is the local redundancy mode. If sectors are mirrored,
+	// sectorMirrors contains the location of the second copy of a sector.
+	// sectorRepairs contains the storage folder of sector copies that failed
+	// to be read and staleSectorCopies the surplus copies found at startup.
+	// All of them are protected by sectorMu and processed by staticRebuild.
+	redundancy        modules.StorageRedundancyMode
+	sectorMirrors     map[sectorID]sectorLocation
+	sectorRepairs     map[sectorID]uint16
+	staleSectorCopies []staleSectorCopy
+	staticRebuild     *redundancyRebuild
+
+	// temporarySectors maps the roots of the sectors that were stored without
+	// a contract to their expiry height. It is protected by sectorMu.
+	// temporarySectorMu serializes the adding and expiring of temporary
+	// sectors.
+	temporarySectors  map[crypto.Hash]types.BlockHeight
+	temporarySectorMu sync.Mutex
+
 	// sectors are removed from the store in a rate-limited queue to work around
 	// lock contention on extra large contracts.
 	sectorRemoval *sectorRemovalMap
 
+	// staticSectorAccess tracks how often sectors are read to decide which
+	// sectors belong on the fast storage folder tier.
+	staticSectorAccess *sectorAccessTracker
+
+	// folderOperation is the running or most recently finished storage folder
+	// migration or rebalance.
+	folderOperation   *folderOperation
+	folderOperationMu sync.Mutex
+
 	// Utilities.
 	dependencies  modules.Dependencies
 	staticAlerter *modules.GenericAlerter
@@ -141,6 +169,15 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 
 		lockedSectors: make(map[sectorID]*sectorLock),
 
+		redundancy:    modules.StorageRedundancyNone,
+		sectorMirrors: make(map[sectorID]sectorLocation),
+		sectorRepairs: make(map[sectorID]uint16),
+		staticRebuild: newRedundancyRebuild(),
+
+		temporarySectors: make(map[crypto.Hash]types.BlockHeight),
+
+		staticSectorAccess: newSectorAccessTracker(),
+
 		dependencies: dependencies,
 		persistDir:   persistDir,
 
@@ -254,6 +291,12 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (_
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
 
+	// Spin up the thread that moves sectors between the storage folder tiers.
+	go cm.threadedRebalanceTiers()
+
+	// Spin up the thread that restores the redundancy of the sectors.
+	go cm.threadedRebuildRedundancy()
+
 	// the removal map is loaded last so that the WAL and metadata is loaded.
 	cm.sectorRemoval, err = newSectorRemovalMap(filepath.Join(persistDir, sectorRemovalQueueFile), cm)
 	if err != nil {
@@ -1,6 +1,7 @@
 package contractmanager
 
 import (
+	"bytes"
 	"encoding/binary"
 	"os"
 	"path/filepath"
@@ -12,6 +13,7 @@ import (
 
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/persist"
 )
 
@@ -21,6 +23,7 @@ type (
 	savedStorageFolder struct {
 		Index uint16
 		Path  string
+		Tier  modules.StorageFolderTier
 		Usage []uint64
 	}
 
@@ -29,19 +32,30 @@ type (
 	savedSettings struct {
 		SectorSalt     crypto.Hash
 		StorageFolders []savedStorageFolder
+		Redundancy     modules.StorageRedundancyMode
+
+		TemporarySectors []savedTemporarySector
 	}
 )
 
 // equals tests if all settings are equal between two savedSettings.
 func (s *savedSettings) equals(sb savedSettings) bool {
-	if s.SectorSalt != sb.SectorSalt || len(s.StorageFolders) != len(sb.StorageFolders) {
+	if s.SectorSalt != sb.SectorSalt || s.Redundancy != sb.Redundancy || len(s.StorageFolders) != len(sb.StorageFolders) {
+		return false
+	}
+	if len(s.TemporarySectors) != len(sb.TemporarySectors) {
 		return false
 	}
+	for i := range s.TemporarySectors {
+		if s.TemporarySectors[i] != sb.TemporarySectors[i] {
+			return false
+		}
+	}
 
 	for i, sf := range s.StorageFolders {
 		sfb := sb.StorageFolders[i]
 
-		if sf.Index != sfb.Index || sf.Path != sfb.Path || len(sf.Usage) != len(sfb.Usage) {
+		if sf.Index != sfb.Index || sf.Path != sfb.Path || sf.Tier != sfb.Tier || len(sf.Usage) != len(sfb.Usage) {
 			return false
 		}
 
@@ -60,6 +74,7 @@ func (sf *storageFolder) savedStorageFolder() savedStorageFolder {
 	ssf := savedStorageFolder{
 		Index: sf.index,
 		Path:  sf.path,
+		Tier:  sf.tier,
 		Usage: make([]uint64, len(sf.usage)),
 	}
 	copy(ssf.Usage, sf.usage)
@@ -97,11 +112,17 @@ func (cm *ContractManager) loadSettings() error {
 
 	// Copy the saved settings into the contract manager.
 	cm.sectorSalt = ss.SectorSalt
+	cm.redundancy = storageRedundancyMode(ss.Redundancy)
+	for _, ts := range ss.TemporarySectors {
+		cm.temporarySectors[ts.Root] = ts.Expiry
+	}
 	for i := range ss.StorageFolders {
 		sf := new(storageFolder)
 		sf.index = ss.StorageFolders[i].Index
 		sf.path = ss.StorageFolders[i].Path
+		sf.tier = storageFolderTier(ss.StorageFolders[i].Tier)
 		sf.usage = ss.StorageFolders[i].Usage
+		sf.device = cm.storageFolderDevice(sf.path)
 		sf.metadataFile, err = cm.dependencies.OpenFile(filepath.Join(ss.StorageFolders[i].Path, metadataFile), os.O_RDWR, 0700)
 		if err != nil {
 			// Mark the folder as unavailable and log an error.
@@ -162,7 +183,7 @@ func (cm *ContractManager) loadSectorLocations(sf *storageFolder) {
 		}
 
 		// Add the sector to the sector location map.
-		cm.sectorLocations[id] = sl
+		cm.addLoadedSectorCopy(id, sl)
 		sf.sectors++
 	}
 	atomic.StoreUint64(&sf.atomicUnavailable, 0)
@@ -175,6 +196,13 @@ func (cm *ContractManager) savedSettings() savedSettings {
 		SectorSalt: cm.sectorSalt,
 	}
 	cm.sectorMu.Lock()
+	ss.Redundancy = cm.redundancy
+	for root, expiry := range cm.temporarySectors {
+		ss.TemporarySectors = append(ss.TemporarySectors, savedTemporarySector{
+			Root:   root,
+			Expiry: expiry,
+		})
+	}
 	for _, sf := range cm.storageFolders {
 		// Unset all of the usage bits in the storage folder for the queued sectors.
 		for _, sectorIndex := range sf.availableSectors {
@@ -196,5 +224,8 @@ func (cm *ContractManager) savedSettings() savedSettings {
 	sort.Slice(ss.StorageFolders, func(i, j int) bool {
 		return ss.StorageFolders[i].Index < ss.StorageFolders[j].Index
 	})
+	sort.Slice(ss.TemporarySectors, func(i, j int) bool {
+		return bytes.Compare(ss.TemporarySectors[i].Root[:], ss.TemporarySectors[j].Root[:]) < 0
+	})
 	return ss
 }
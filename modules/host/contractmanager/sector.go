@@ -13,7 +13,7 @@ import (
 
 var (
 	// ErrSectorNotFound is returned when a lookup for a sector fails.
-	ErrSectorNotFound = errors.New("could not find the desired sector")
+	ErrSectorNotFound = modules.ErrSectorNotFound
 
 	// errDiskTrouble is returned when the host is supposed to have enough
 	// storage to hold a new sector but failures that are likely related to the
@@ -134,20 +134,43 @@ func (cm *ContractManager) ReadPartialSector(root crypto.Hash, offset, length ui
 	cm.sectorMu.Lock()
 	sl, exists1 := cm.sectorLocations[id]
 	sf, exists2 := cm.storageFolders[sl.storageFolder]
+	ml, mirrored := cm.sectorMirrors[id]
+	msf := cm.storageFolders[ml.storageFolder]
 	cm.sectorMu.Unlock()
 	if !exists1 {
 		return nil, ErrSectorNotFound
 	}
-	if !exists2 {
+	if !exists2 && !mirrored {
 		cm.log.Critical("Unable to load storage folder despite having sector metadata")
 		return nil, ErrSectorNotFound
 	}
-	if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+
+	// Read the sector. Fall back to the mirror if the sector can't be read
+	// and replace the failed copy.
+	sectorData, err := readSectorCopy(sf, sl, offset, length)
+	if err != nil && mirrored {
+		var mirrorErr error
+		sectorData, mirrorErr = readSectorCopy(msf, ml, offset, length)
+		if mirrorErr != nil {
+			return nil, build.ComposeErrors(err, mirrorErr)
+		}
+		cm.managedQueueRepair(id, sl.storageFolder)
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cm.staticSectorAccess.managedRecordRead(id)
+	return sectorData, nil
+}
+
+// readSectorCopy reads 'length' bytes at offset 'offset' from the copy of a
+// sector at the given location.
+func readSectorCopy(sf *storageFolder, sl sectorLocation, offset, length uint64) ([]byte, error) {
+	if sf == nil || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
 		// TODO: Pick a new error instead.
 		return nil, ErrSectorNotFound
 	}
-
-	// Read the sector.
 	sectorData, err := readPartialSector(sf.sectorFile, sl.index, offset, length)
 	if err != nil {
 		atomic.AddUint64(&sf.atomicFailedReads, 1)
@@ -0,0 +1,617 @@
+package contractmanager
+
+// sectorredundancy.go stores a second copy of every sector in a storage folder
+// on a different device if the redundancy mode is set to mirror. Both copies
+// share the sector id and count. The copy in sectorLocations is the primary
+// copy which is read first, the copy in sectorMirrors is its mirror. Reads
+// fall back to the mirror if the primary copy is unavailable or can't be read.
+//
+// A background rebuild restores the redundancy. It creates missing mirrors,
+// replaces copies on unavailable storage folders, copies that failed to be
+// read and copies that share a device with the other copy, and it removes the
+// mirrors once mirroring is disabled. The rebuild runs periodically and
+// whenever a storage folder becomes available again or a read fails.
+//
+// Which copy is the primary one isn't persisted. When the sector metadata is
+// loaded, the first copy of a sector becomes the primary copy unless the
+// second copy has a higher count. Any further copies are freed by the next
+// rebuild.
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+)
+
+var (
+	// errNoHealthyCopy is returned if a sector can't be mirrored because
+	// none of its copies is stored in an available storage folder.
+	errNoHealthyCopy = errors.New("no healthy copy of the sector is available")
+)
+
+type (
+	// storageRedundancyUpdate is a WAL entry that sets the redundancy mode of
+	// the contract manager.
+	storageRedundancyUpdate struct {
+		Mode modules.StorageRedundancyMode
+	}
+
+	// staleSectorCopy is a surplus copy of a sector that was found while
+	// loading the sector metadata.
+	staleSectorCopy struct {
+		id sectorID
+		sl sectorLocation
+	}
+
+	// redundancyRebuild tracks the progress of the redundancy rebuild.
+	redundancyRebuild struct {
+		signalChan chan struct{}
+
+		running bool
+		total   uint64
+		done    uint64
+		failed  uint64
+		last    time.Time
+		mu      sync.Mutex
+	}
+)
+
+// newRedundancyRebuild creates a new redundancyRebuild.
+func newRedundancyRebuild() *redundancyRebuild {
+	return &redundancyRebuild{
+		signalChan: make(chan struct{}, 1),
+	}
+}
+
+// signal triggers a rebuild. It never blocks.
+func (rr *redundancyRebuild) signal() {
+	select {
+	case rr.signalChan <- struct{}{}:
+	default:
+	}
+}
+
+// managedStart resets the progress for a new rebuild of the given number of
+// sectors.
+func (rr *redundancyRebuild) managedStart(total uint64) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.running = true
+	rr.total = total
+	rr.done = 0
+	rr.failed = 0
+}
+
+// managedRecordSector updates the progress after a sector was rebuilt.
+func (rr *redundancyRebuild) managedRecordSector(err error) {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	if err != nil {
+		rr.failed++
+	} else {
+		rr.done++
+	}
+}
+
+// managedFinish marks the rebuild as finished.
+func (rr *redundancyRebuild) managedFinish() {
+	rr.mu.Lock()
+	defer rr.mu.Unlock()
+	rr.running = false
+	rr.last = time.Now()
+}
+
+// storageRedundancyMode returns the redundancy mode, defaulting to no
+// redundancy for contract managers that were created before redundancy modes
+// existed.
+func storageRedundancyMode(mode modules.StorageRedundancyMode) modules.StorageRedundancyMode {
+	if mode == "" {
+		return modules.StorageRedundancyNone
+	}
+	return mode
+}
+
+// storageFolderDevice returns the device of the storage folder at the given
+// path. If the device can't be determined, the path is returned instead so
+// that the storage folder is treated as a device of its own.
+func (cm *ContractManager) storageFolderDevice(path string) string {
+	if cm.dependencies.Disrupt("storageFolderDevicePerFolder") {
+		return path
+	}
+	device, err := deviceID(path)
+	if err != nil {
+		cm.log.Printf("WARN: unable to determine the device of storage folder %v: %v\n", path, err)
+		return path
+	}
+	return device
+}
+
+// otherDeviceStorageFolders returns the storage folders that are neither on
+// the device of any of the excluded storage folders nor one of them. The
+// caller must hold sectorMu.
+func otherDeviceStorageFolders(sfs []*storageFolder, excluded ...*storageFolder) []*storageFolder {
+	var others []*storageFolder
+OUTER:
+	for _, sf := range sfs {
+		for _, ex := range excluded {
+			if sf == ex || sf.device == ex.device {
+				continue OUTER
+			}
+		}
+		others = append(others, sf)
+	}
+	return others
+}
+
+// numDevices returns the number of distinct devices of the storage folders. If
+// available is set, only available storage folders are counted. The caller
+// must hold sectorMu.
+func (cm *ContractManager) numDevices(available bool) int {
+	devices := make(map[string]struct{})
+	for _, sf := range cm.storageFolders {
+		if available && atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+		devices[sf.device] = struct{}{}
+	}
+	return len(devices)
+}
+
+// availableStorageFolder returns the storage folder with the given index if it
+// exists and is available. The caller must hold sectorMu.
+func (cm *ContractManager) availableStorageFolder(index uint16) *storageFolder {
+	sf, exists := cm.storageFolders[index]
+	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return nil
+	}
+	return sf
+}
+
+// sectorCopy returns the location of the copy of a sector in the given storage
+// folder and whether that copy is the mirror. The caller must hold sectorMu.
+func (cm *ContractManager) sectorCopy(id sectorID, folder uint16) (sectorLocation, bool, bool) {
+	if sl, exists := cm.sectorLocations[id]; exists && sl.storageFolder == folder {
+		return sl, false, true
+	}
+	if sl, exists := cm.sectorMirrors[id]; exists && sl.storageFolder == folder {
+		return sl, true, true
+	}
+	return sectorLocation{}, false, false
+}
+
+// addLoadedSectorCopy adds a copy of a sector that was found in the metadata
+// of a storage folder. The caller must hold sectorMu.
+func (cm *ContractManager) addLoadedSectorCopy(id sectorID, sl sectorLocation) {
+	pl, exists := cm.sectorLocations[id]
+	ml, mirrored := cm.sectorMirrors[id]
+	switch {
+	case !exists || pl.storageFolder == sl.storageFolder:
+		cm.sectorLocations[id] = sl
+	case mirrored && ml.storageFolder == sl.storageFolder:
+		cm.sectorMirrors[id] = sl
+	case mirrored:
+		cm.staleSectorCopies = append(cm.staleSectorCopies, staleSectorCopy{id: id, sl: sl})
+	case sl.count > pl.count:
+		// The update of the other copy's count didn't complete.
+		cm.sectorLocations[id] = sl
+		cm.sectorMirrors[id] = pl
+	default:
+		cm.sectorMirrors[id] = sl
+	}
+}
+
+// sectorDegraded returns whether a sector lacks two copies in available
+// storage folders on different devices. The caller must hold sectorMu.
+func (cm *ContractManager) sectorDegraded(id sectorID, pl sectorLocation) bool {
+	ml, mirrored := cm.sectorMirrors[id]
+	if !mirrored {
+		return true
+	}
+	psf := cm.availableStorageFolder(pl.storageFolder)
+	msf := cm.availableStorageFolder(ml.storageFolder)
+	return psf == nil || msf == nil || psf.device == msf.device
+}
+
+// sectorNeedsRebuild returns whether the rebuild has to touch a sector. The
+// caller must hold sectorMu.
+func (cm *ContractManager) sectorNeedsRebuild(id sectorID, pl sectorLocation) bool {
+	ml, mirrored := cm.sectorMirrors[id]
+	if cm.redundancy != modules.StorageRedundancyMirror {
+		return mirrored
+	}
+	if _, repair := cm.sectorRepairs[id]; repair {
+		return true
+	}
+	return cm.sectorDegraded(id, pl) || ml.count != pl.count
+}
+
+// mirrorUpdate sets the count of the mirror of a sector, a count of 0 removes
+// the mirror. It returns the sector update that has to be added to the WAL
+// and whether the sector has a mirror at all. If the returned storage folder
+// isn't nil, the update has to be finished with managedFinishMirrorUpdate once
+// the change was synced. The caller must hold sectorMu.
+func (cm *ContractManager) mirrorUpdate(id sectorID, count uint64) (*storageFolder, sectorUpdate, bool) {
+	ml, mirrored := cm.sectorMirrors[id]
+	if !mirrored {
+		return nil, sectorUpdate{}, false
+	}
+	su := sectorUpdate{
+		Count:  count,
+		ID:     id,
+		Folder: ml.storageFolder,
+		Index:  ml.index,
+	}
+	if count == 0 {
+		delete(cm.sectorMirrors, id)
+	} else {
+		ml.count = count
+		cm.sectorMirrors[id] = ml
+	}
+	sf := cm.availableStorageFolder(ml.storageFolder)
+	if sf == nil {
+		return nil, su, true
+	}
+	if count == 0 {
+		sf.availableSectors[id] = ml.index
+	}
+	return sf, su, true
+}
+
+// managedFinishMirrorUpdate writes the metadata of an updated mirror or frees
+// the usage of a removed mirror after the update was synced. Failures are
+// only logged, the mirror is fixed by the next rebuild.
+func (wal *writeAheadLog) managedFinishMirrorUpdate(sf *storageFolder, su sectorUpdate) {
+	if sf == nil {
+		return
+	}
+	if su.Count != 0 {
+		err := wal.writeSectorMetadata(sf, su)
+		if err != nil {
+			wal.cm.log.Printf("ERROR: unable to update the mirror of sector %v: %v\n", su.ID, err)
+		}
+		return
+	}
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	sf.clearUsage(su.Index)
+	delete(sf.availableSectors, su.ID)
+	wal.cm.sectorMu.Unlock()
+	wal.mu.Unlock()
+}
+
+// managedUpdateMirror sets the count of the mirror of a sector and waits for
+// the update to be synced.
+func (wal *writeAheadLog) managedUpdateMirror(id sectorID, count uint64) {
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	sf, su, mirrored := wal.cm.mirrorUpdate(id, count)
+	wal.cm.sectorMu.Unlock()
+	if !mirrored {
+		wal.mu.Unlock()
+		return
+	}
+	wal.appendChange(stateChange{
+		SectorUpdates: []sectorUpdate{su},
+	})
+	syncChan := wal.syncChan
+	wal.mu.Unlock()
+	<-syncChan
+	wal.managedFinishMirrorUpdate(sf, su)
+}
+
+// managedQueueRepair marks the copy of a sector in the given storage folder as
+// damaged and triggers a rebuild to replace it.
+func (cm *ContractManager) managedQueueRepair(id sectorID, folder uint16) {
+	cm.sectorMu.Lock()
+	cm.sectorRepairs[id] = folder
+	cm.sectorMu.Unlock()
+	cm.staticRebuild.signal()
+}
+
+// managedRebuildSector restores the redundancy of a single sector.
+func (wal *writeAheadLog) managedRebuildSector(id sectorID) error {
+	wal.managedLockSector(id)
+	defer wal.managedUnlockSector(id)
+
+	wal.mu.Lock()
+	storageFolders := wal.cm.availableStorageFolders()
+	wal.mu.Unlock()
+
+	wal.cm.sectorMu.Lock()
+	pl, exists := wal.cm.sectorLocations[id]
+	ml, mirrored := wal.cm.sectorMirrors[id]
+	damaged, repair := wal.cm.sectorRepairs[id]
+	delete(wal.cm.sectorRepairs, id)
+	if !exists {
+		wal.cm.sectorMu.Unlock()
+		return nil
+	}
+	healthyFolder := func(sl sectorLocation) *storageFolder {
+		if repair && sl.storageFolder == damaged {
+			return nil
+		}
+		return wal.cm.availableStorageFolder(sl.storageFolder)
+	}
+	psf := healthyFolder(pl)
+	var msf *storageFolder
+	if mirrored {
+		msf = healthyFolder(ml)
+	}
+
+	// Make sure that the primary copy is the healthy one.
+	if psf == nil && msf != nil {
+		pl, ml = ml, pl
+		psf, msf = msf, psf
+		wal.cm.sectorLocations[id] = pl
+		wal.cm.sectorMirrors[id] = ml
+	}
+	mode := wal.cm.redundancy
+	replace := msf == nil || (psf != nil && msf.device == psf.device)
+	var mirrorFolders []*storageFolder
+	if psf != nil {
+		mirrorFolders = otherDeviceStorageFolders(storageFolders, psf)
+	}
+	if mirrored {
+		if sf, exists := wal.cm.storageFolders[ml.storageFolder]; exists {
+			mirrorFolders = otherDeviceStorageFolders(mirrorFolders, sf)
+		}
+	}
+	wal.cm.sectorMu.Unlock()
+
+	// Remove the mirror if mirroring is disabled.
+	if mode != modules.StorageRedundancyMirror {
+		if mirrored {
+			wal.managedUpdateMirror(id, 0)
+		}
+		return nil
+	}
+	if psf == nil {
+		return errNoHealthyCopy
+	}
+
+	// Fix the count of a healthy mirror.
+	if !replace {
+		if ml.count != pl.count {
+			wal.managedUpdateMirror(id, pl.count)
+		}
+		return nil
+	}
+	if len(mirrorFolders) == 0 {
+		return errors.New(modules.V1420HostOutOfStorageErrString)
+	}
+
+	// Write a new mirror and free the old one.
+	data, err := readSector(psf.sectorFile, pl.index)
+	if err != nil {
+		atomic.AddUint64(&psf.atomicFailedReads, 1)
+		return build.ExtendErr("unable to read sector selected for mirroring", err)
+	}
+	atomic.AddUint64(&psf.atomicSuccessfulReads, 1)
+	sf, su, err := wal.managedWriteSectorCopy(id, data, pl.count, mirrorFolders)
+	if err != nil {
+		return err
+	}
+	defer sf.mu.RUnlock()
+
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	sus := []sectorUpdate{su}
+	osf, osu, mirrored := wal.cm.mirrorUpdate(id, 0)
+	if mirrored {
+		sus = append(sus, osu)
+	}
+	delete(sf.availableSectors, id)
+	wal.cm.sectorMirrors[id] = sectorLocation{
+		index:         su.Index,
+		storageFolder: su.Folder,
+		count:         su.Count,
+	}
+	wal.appendChange(stateChange{
+		SectorUpdates: sus,
+	})
+	wal.cm.sectorMu.Unlock()
+	syncChan := wal.syncChan
+	wal.mu.Unlock()
+	<-syncChan
+	wal.managedFinishMirrorUpdate(osf, osu)
+	return nil
+}
+
+// managedFreeStaleCopies frees the surplus sector copies that were found while
+// loading the sector metadata.
+func (wal *writeAheadLog) managedFreeStaleCopies() {
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	var sus []sectorUpdate
+	var sfs []*storageFolder
+	for _, sc := range wal.cm.staleSectorCopies {
+		// Skip copies that are in use again.
+		if sl, _, exists := wal.cm.sectorCopy(sc.id, sc.sl.storageFolder); exists && sl.index == sc.sl.index {
+			continue
+		}
+		sf := wal.cm.availableStorageFolder(sc.sl.storageFolder)
+		if sf == nil {
+			continue
+		}
+		sus = append(sus, sectorUpdate{
+			Count:  0,
+			ID:     sc.id,
+			Folder: sc.sl.storageFolder,
+			Index:  sc.sl.index,
+		})
+		sfs = append(sfs, sf)
+	}
+	wal.cm.staleSectorCopies = nil
+	wal.cm.sectorMu.Unlock()
+	if len(sus) == 0 {
+		wal.mu.Unlock()
+		return
+	}
+	wal.appendChange(stateChange{
+		SectorUpdates: sus,
+	})
+	syncChan := wal.syncChan
+	wal.mu.Unlock()
+	<-syncChan
+
+	// Free the usage once the removal was synced.
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	for i, su := range sus {
+		sfs[i].clearUsage(su.Index)
+	}
+	wal.cm.sectorMu.Unlock()
+	wal.mu.Unlock()
+}
+
+// managedRebuildRedundancy restores the redundancy of all sectors that need
+// it. Mirrors can't be created or replaced without available storage folders
+// on at least two devices, so the rebuild is skipped in that case.
+func (cm *ContractManager) managedRebuildRedundancy() {
+	cm.wal.managedFreeStaleCopies()
+
+	cm.sectorMu.Lock()
+	if cm.redundancy == modules.StorageRedundancyMirror && cm.numDevices(true) < 2 {
+		cm.sectorMu.Unlock()
+		cm.log.Println("WARN: skipping the redundancy rebuild:", modules.ErrMirrorRequiresTwoDevices)
+		return
+	}
+	var ids []sectorID
+	for id, pl := range cm.sectorLocations {
+		if cm.sectorNeedsRebuild(id, pl) {
+			ids = append(ids, id)
+		}
+	}
+	for id := range cm.sectorRepairs {
+		if _, exists := cm.sectorLocations[id]; !exists {
+			delete(cm.sectorRepairs, id)
+		}
+	}
+	cm.sectorMu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+
+	cm.staticRebuild.managedStart(uint64(len(ids)))
+	defer cm.staticRebuild.managedFinish()
+	var wg sync.WaitGroup
+	workChan := make(chan sectorID)
+	for i := 0; i < redundancyRebuildThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range workChan {
+				err := cm.wal.managedRebuildSector(id)
+				if err != nil {
+					cm.log.Printf("Unable to rebuild the redundancy of sector %v: %v\n", id, err)
+				}
+				cm.staticRebuild.managedRecordSector(err)
+			}
+		}()
+	}
+LOOP:
+	for _, id := range ids {
+		select {
+		case <-cm.tg.StopChan():
+			break LOOP
+		case workChan <- id:
+		}
+	}
+	close(workChan)
+	wg.Wait()
+}
+
+// threadedRebuildRedundancy restores the redundancy of the sectors at startup,
+// periodically and whenever a rebuild is signaled.
+func (cm *ContractManager) threadedRebuildRedundancy() {
+	for {
+		func() {
+			if err := cm.tg.Add(); err != nil {
+				return
+			}
+			defer cm.tg.Done()
+			cm.managedRebuildRedundancy()
+		}()
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-cm.staticRebuild.signalChan:
+		case <-time.After(redundancyRebuildInterval):
+		}
+	}
+}
+
+// commitStorageRedundancyUpdate sets the redundancy mode of the contract
+// manager.
+func (wal *writeAheadLog) commitStorageRedundancyUpdate(sru storageRedundancyUpdate) {
+	wal.cm.sectorMu.Lock()
+	defer wal.cm.sectorMu.Unlock()
+	wal.cm.redundancy = storageRedundancyMode(sru.Mode)
+}
+
+// SetStorageRedundancy sets the redundancy mode of the contract manager.
+// Mirrors are created or removed in the background. Mirroring can only be
+// enabled if the storage folders are on at least two devices.
+func (cm *ContractManager) SetStorageRedundancy(mode modules.StorageRedundancyMode) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	if err := mode.Validate(); err != nil {
+		return err
+	}
+	if mode == modules.StorageRedundancyMirror {
+		cm.sectorMu.Lock()
+		devices := cm.numDevices(false)
+		cm.sectorMu.Unlock()
+		if devices < 2 {
+			return modules.ErrMirrorRequiresTwoDevices
+		}
+	}
+
+	// Submit the redundancy update to the WAL and wait until it is synced.
+	cm.wal.mu.Lock()
+	cm.wal.appendChange(stateChange{
+		StorageRedundancyUpdates: []storageRedundancyUpdate{{
+			Mode: mode,
+		}},
+	})
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	<-syncChan
+
+	cm.staticRebuild.signal()
+	return nil
+}
+
+// StorageRedundancy returns the redundancy status of the contract manager.
+func (cm *ContractManager) StorageRedundancy() modules.StorageRedundancyStatus {
+	cm.sectorMu.Lock()
+	status := modules.StorageRedundancyStatus{
+		Mode:            cm.redundancy,
+		Sectors:         uint64(len(cm.sectorLocations)),
+		MirroredSectors: uint64(len(cm.sectorMirrors)),
+	}
+	if cm.redundancy == modules.StorageRedundancyMirror {
+		for id, pl := range cm.sectorLocations {
+			if cm.sectorDegraded(id, pl) {
+				status.DegradedSectors++
+			}
+		}
+	}
+	cm.sectorMu.Unlock()
+
+	cm.staticRebuild.mu.Lock()
+	status.Rebuilding = cm.staticRebuild.running
+	status.RebuildSectorsTotal = cm.staticRebuild.total
+	status.RebuildSectorsDone = cm.staticRebuild.done
+	status.RebuildSectorsFailed = cm.staticRebuild.failed
+	status.LastRebuild = cm.staticRebuild.last
+	cm.staticRebuild.mu.Unlock()
+	return status
+}
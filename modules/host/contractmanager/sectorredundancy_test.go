@@ -0,0 +1,259 @@
+package contractmanager
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// dependencyDevicePerFolder treats every storage folder as a device of its own
+// and prevents the recheck loop from making unavailable folders available
+// again.
+type dependencyDevicePerFolder struct {
+	modules.ProductionDependencies
+}
+
+// Disrupt returns true for the storageFolderDevicePerFolder and noRecheck
+// disruptions.
+func (*dependencyDevicePerFolder) Disrupt(s string) bool {
+	return s == "storageFolderDevicePerFolder" || s == "noRecheck"
+}
+
+// waitForRedundancy waits until the redundancy rebuild of the contract manager
+// tester reached the expected number of mirrored and degraded sectors.
+func (cmt *contractManagerTester) waitForRedundancy(mirrored, degraded uint64) (status modules.StorageRedundancyStatus, err error) {
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		status = cmt.cm.StorageRedundancy()
+		if status.Rebuilding || status.MirroredSectors != mirrored || status.DegradedSectors != degraded {
+			return fmt.Errorf("unexpected redundancy %+v", status)
+		}
+		return nil
+	})
+	return
+}
+
+// TestAddLoadedSectorCopy is a unit test for addLoadedSectorCopy.
+func TestAddLoadedSectorCopy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	cm := &ContractManager{
+		sectorLocations: make(map[sectorID]sectorLocation),
+		sectorMirrors:   make(map[sectorID]sectorLocation),
+	}
+	var id sectorID
+	first := sectorLocation{storageFolder: 0, index: 1, count: 2}
+	second := sectorLocation{storageFolder: 1, index: 2, count: 3}
+	third := sectorLocation{storageFolder: 2, index: 3, count: 3}
+
+	// The copy with the higher count becomes the primary copy.
+	cm.addLoadedSectorCopy(id, first)
+	cm.addLoadedSectorCopy(id, second)
+	if cm.sectorLocations[id] != second || cm.sectorMirrors[id] != first {
+		t.Fatal("unexpected copies", cm.sectorLocations[id], cm.sectorMirrors[id])
+	}
+
+	// Reloading a storage folder updates the existing copy.
+	first.count = 3
+	cm.addLoadedSectorCopy(id, first)
+	if cm.sectorMirrors[id] != first || len(cm.staleSectorCopies) != 0 {
+		t.Fatal("reloaded copy wasn't updated")
+	}
+
+	// Further copies are stale.
+	cm.addLoadedSectorCopy(id, third)
+	if len(cm.staleSectorCopies) != 1 || cm.staleSectorCopies[0].sl != third {
+		t.Fatal("third copy wasn't marked as stale", cm.staleSectorCopies)
+	}
+}
+
+// TestStorageRedundancyMirror checks that sectors are mirrored to different
+// devices, that reads fall back to the mirror and that the mirrors are
+// rebuilt after a storage folder fails.
+func TestStorageRedundancyMirror(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := new(dependencyDevicePerFolder)
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity, storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]crypto.Hash, 5)
+	datas := make([][]byte, len(roots))
+	addSector := func(i int) {
+		roots[i], datas[i] = randSector()
+		if err := cmt.cm.AddSector(roots[i], datas[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 4; i++ {
+		addSector(i)
+	}
+	checkSectors := func() {
+		t.Helper()
+		for i, root := range roots {
+			data, err := cmt.cm.ReadSector(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, datas[i]) {
+				t.Fatal("sector has the wrong data")
+			}
+		}
+	}
+	if status := cmt.cm.StorageRedundancy(); status.Mode != modules.StorageRedundancyNone || status.Sectors != 4 || status.MirroredSectors != 0 {
+		t.Fatalf("unexpected redundancy %+v", status)
+	}
+
+	// Invalid modes are rejected.
+	if err := cmt.cm.SetStorageRedundancy("raid"); !errors.Is(err, modules.ErrUnknownStorageRedundancyMode) {
+		t.Fatal("expected ErrUnknownStorageRedundancyMode but got", err)
+	}
+
+	// Enabling mirroring mirrors the existing sectors and new sectors.
+	if err := cmt.cm.SetStorageRedundancy(modules.StorageRedundancyMirror); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.waitForRedundancy(4, 0); err != nil {
+		t.Fatal(err)
+	}
+	addSector(4)
+	if status := cmt.cm.StorageRedundancy(); status.MirroredSectors != 5 || status.DegradedSectors != 0 {
+		t.Fatalf("new sector wasn't mirrored %+v", status)
+	}
+	for _, path := range paths {
+		if sf, _ := cmt.folder(path); sf.Capacity-sf.CapacityRemaining != 5*modules.SectorSize {
+			t.Fatal("expected every folder to store a copy of every sector")
+		}
+	}
+
+	// The mode and the mirrors survive a restart.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = newContractManager(d, filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, err := cmt.waitForRedundancy(5, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Mode != modules.StorageRedundancyMirror || status.Sectors != 5 {
+		t.Fatalf("unexpected redundancy after restart %+v", status)
+	}
+
+	// Fail the first folder, the sectors are read from the mirrors.
+	failed, _ := cmt.folder(paths[0])
+	cmt.cm.sectorMu.Lock()
+	sf := cmt.cm.storageFolders[failed.Index]
+	atomic.StoreUint64(&sf.atomicUnavailable, 1)
+	err = build.ComposeErrors(sf.metadataFile.Close(), sf.sectorFile.Close())
+	cmt.cm.sectorMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkSectors()
+
+	// The mirrors are rebuilt once another folder is added.
+	if _, err := cmt.waitForRedundancy(5, 5); err != nil {
+		t.Fatal(err)
+	}
+	newPaths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.waitForRedundancy(5, 0); err != nil {
+		t.Fatal(err)
+	}
+	if sf, _ := cmt.folder(newPaths[0]); sf.Capacity-sf.CapacityRemaining != 5*modules.SectorSize {
+		t.Fatal("mirrors weren't rebuilt in the new folder")
+	}
+	checkSectors()
+
+	// Disabling mirroring removes the mirrors.
+	if err := cmt.cm.SetStorageRedundancy(modules.StorageRedundancyNone); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.waitForRedundancy(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if sf, _ := cmt.folder(newPaths[0]); sf.CapacityRemaining != sf.Capacity {
+		t.Fatal("mirrors weren't removed")
+	}
+	checkSectors()
+}
+
+// TestStorageRedundancyMirrorSingleDevice checks that mirroring can't be
+// enabled with a single device and that the rebuild is skipped while only a
+// single device is available.
+func TestStorageRedundancyMirrorSingleDevice(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newMockedContractManagerTester(new(dependencyDevicePerFolder), t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Mirroring is rejected with a single device.
+	if _, err := cmt.addTestStorageFolders(storageFolderGranularity); err != nil {
+		t.Fatal(err)
+	}
+	root, data := randSector()
+	if err := cmt.cm.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.SetStorageRedundancy(modules.StorageRedundancyMirror); !errors.Is(err, modules.ErrMirrorRequiresTwoDevices) {
+		t.Fatal("expected ErrMirrorRequiresTwoDevices but got", err)
+	}
+	if status := cmt.cm.StorageRedundancy(); status.Mode != modules.StorageRedundancyNone {
+		t.Fatal("mode shouldn't have changed", status.Mode)
+	}
+
+	// With a second device the sector is mirrored.
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.SetStorageRedundancy(modules.StorageRedundancyMirror); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.waitForRedundancy(1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once one of the devices is unavailable, the rebuild is skipped instead
+	// of failing for every sector.
+	unavailable, _ := cmt.folder(paths[0])
+	cmt.cm.sectorMu.Lock()
+	atomic.StoreUint64(&cmt.cm.storageFolders[unavailable.Index].atomicUnavailable, 1)
+	cmt.cm.sectorMu.Unlock()
+	cmt.cm.managedRebuildRedundancy()
+	cmt.cm.staticRebuild.mu.Lock()
+	failed := cmt.cm.staticRebuild.failed
+	cmt.cm.staticRebuild.mu.Unlock()
+	if failed != 0 {
+		t.Fatal("expected the rebuild to be skipped but sectors failed:", failed)
+	}
+}
@@ -0,0 +1,123 @@
+package contractmanager
+
+// sectortemporary.go tracks the sectors that were stored without a contract.
+// Every temporary sector holds a single reference to the sector until it
+// expires. Adding a sector that is already tracked only extends its expiry.
+//
+// The expiry is tracked after the sector was added and the tracking is removed
+// before the sector is removed. That way an unclean shutdown can only leak a
+// reference but never remove a reference that belongs to a contract.
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/types"
+)
+
+type (
+	// savedTemporarySector is the persisted expiry of a temporary sector. It
+	// is also used as a WAL entry, an expiry of 0 stops tracking the sector.
+	savedTemporarySector struct {
+		Root   crypto.Hash
+		Expiry types.BlockHeight
+	}
+)
+
+// commitTemporarySectorUpdate sets or removes the expiry of a temporary
+// sector.
+func (wal *writeAheadLog) commitTemporarySectorUpdate(tsu savedTemporarySector) {
+	wal.cm.sectorMu.Lock()
+	defer wal.cm.sectorMu.Unlock()
+	if tsu.Expiry == 0 {
+		delete(wal.cm.temporarySectors, tsu.Root)
+		return
+	}
+	wal.cm.temporarySectors[tsu.Root] = tsu.Expiry
+}
+
+// managedUpdateTemporarySectors submits the temporary sector updates to the
+// WAL and waits until they are synced.
+func (cm *ContractManager) managedUpdateTemporarySectors(updates []savedTemporarySector) {
+	cm.wal.mu.Lock()
+	cm.wal.appendChange(stateChange{
+		TemporarySectorUpdates: updates,
+	})
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	<-syncChan
+}
+
+// AddTemporarySector stores a sector that doesn't belong to a contract until
+// the provided expiry height. If the sector is already stored as a temporary
+// sector, its expiry is extended.
+func (cm *ContractManager) AddTemporarySector(sectorRoot crypto.Hash, sectorData []byte, expiry types.BlockHeight) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	if expiry == 0 {
+		return errors.New("expiry of a temporary sector can't be zero")
+	}
+
+	cm.temporarySectorMu.Lock()
+	defer cm.temporarySectorMu.Unlock()
+	cm.sectorMu.Lock()
+	current, exists := cm.temporarySectors[sectorRoot]
+	cm.sectorMu.Unlock()
+	if exists && current >= expiry {
+		return nil
+	}
+	if !exists {
+		err = cm.AddSector(sectorRoot, sectorData)
+		if err != nil {
+			return errors.AddContext(err, "failed to add temporary sector")
+		}
+	}
+	cm.managedUpdateTemporarySectors([]savedTemporarySector{{
+		Root:   sectorRoot,
+		Expiry: expiry,
+	}})
+	return nil
+}
+
+// RemoveExpiredTemporarySectors removes all temporary sectors which expire at
+// or before the provided height. It returns the number of removed sectors.
+func (cm *ContractManager) RemoveExpiredTemporarySectors(height types.BlockHeight) (uint64, error) {
+	err := cm.tg.Add()
+	if err != nil {
+		return 0, err
+	}
+	defer cm.tg.Done()
+
+	cm.temporarySectorMu.Lock()
+	defer cm.temporarySectorMu.Unlock()
+	var expired []crypto.Hash
+	var updates []savedTemporarySector
+	cm.sectorMu.Lock()
+	for root, expiry := range cm.temporarySectors {
+		if expiry <= height {
+			expired = append(expired, root)
+			updates = append(updates, savedTemporarySector{Root: root})
+		}
+	}
+	cm.sectorMu.Unlock()
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	// Stop tracking the sectors before removing them.
+	cm.managedUpdateTemporarySectors(updates)
+	err = cm.MarkSectorsForRemoval(expired)
+	if err != nil {
+		return 0, errors.AddContext(err, "failed to remove expired temporary sectors")
+	}
+	return uint64(len(expired)), nil
+}
+
+// TemporarySectors returns the number of temporary sectors.
+func (cm *ContractManager) TemporarySectors() uint64 {
+	cm.sectorMu.Lock()
+	defer cm.sectorMu.Unlock()
+	return uint64(len(cm.temporarySectors))
+}
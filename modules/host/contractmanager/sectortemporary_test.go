@@ -0,0 +1,116 @@
+package contractmanager
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestTemporarySectors checks that temporary sectors are tracked across
+// restarts, that their expiry can be extended and that they are removed once
+// they expire without affecting sectors that belong to a contract.
+func TestTemporarySectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An expiry of 0 is invalid.
+	root, data := randSector()
+	if err := cmt.cm.AddTemporarySector(root, data, 0); err == nil {
+		t.Fatal("expected error for expiry 0")
+	}
+
+	// Add a temporary sector and store the same sector again with a lower and
+	// a higher expiry. Only the higher expiry should be used and the sector
+	// should only be stored once.
+	if err := cmt.cm.AddTemporarySector(root, data, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddTemporarySector(root, data, 5); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddTemporarySector(root, data, 20); err != nil {
+		t.Fatal(err)
+	}
+	if n := cmt.cm.TemporarySectors(); n != 1 {
+		t.Fatal("expected 1 temporary sector but got", n)
+	}
+	if sf, _ := cmt.folder(paths[0]); sf.Capacity-sf.CapacityRemaining != modules.SectorSize {
+		t.Fatal("expected the sector to be stored once")
+	}
+
+	// Add a second temporary sector which is also used by a contract.
+	contractRoot, contractData := randSector()
+	if err := cmt.cm.AddSector(contractRoot, contractData); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddTemporarySector(contractRoot, contractData, 15); err != nil {
+		t.Fatal(err)
+	}
+
+	// The temporary sectors survive a restart.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := cmt.cm.TemporarySectors(); n != 2 {
+		t.Fatal("expected 2 temporary sectors after restart but got", n)
+	}
+
+	// Nothing expires before height 15.
+	if n, err := cmt.cm.RemoveExpiredTemporarySectors(14); err != nil || n != 0 {
+		t.Fatal("expected no sectors to expire", n, err)
+	}
+
+	// At height 15 the sector of the contract expires and at height 20 the
+	// other sector expires.
+	if n, err := cmt.cm.RemoveExpiredTemporarySectors(15); err != nil || n != 1 {
+		t.Fatal("expected 1 sector to expire", n, err)
+	}
+	if n, err := cmt.cm.RemoveExpiredTemporarySectors(20); err != nil || n != 1 {
+		t.Fatal("expected 1 sector to expire", n, err)
+	}
+	if n := cmt.cm.TemporarySectors(); n != 0 {
+		t.Fatal("expected no temporary sectors but got", n)
+	}
+
+	// Sectors are removed in the background. Only the sector of the contract
+	// should remain since the contract still holds a reference.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if _, err := cmt.cm.ReadSector(root); err == nil {
+			return errors.New("expired sector wasn't removed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sector, err := cmt.cm.ReadSector(contractRoot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sector, contractData) {
+		t.Fatal("contract sector was corrupted")
+	}
+	if sf, _ := cmt.folder(paths[0]); sf.Capacity-sf.CapacityRemaining != modules.SectorSize {
+		t.Fatal("expected only the contract sector to be stored")
+	}
+}
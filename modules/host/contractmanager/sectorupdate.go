@@ -39,136 +39,156 @@ func (wal *writeAheadLog) commitUpdateSector(su sectorUpdate) {
 	sf.setUsage(su.Index)
 }
 
-// managedAddPhysicalSector is a WAL operation to add a physical sector to the
-// contract manager.
-func (wal *writeAheadLog) managedAddPhysicalSector(id sectorID, data []byte) error {
-	// Sanity check - data should have modules.SectorSize bytes.
-	if uint64(len(data)) != modules.SectorSize {
-		wal.cm.log.Critical("sector has the wrong size", modules.SectorSize, len(data))
-		return errors.New("malformed sector")
-	}
-
-	// Find a committed storage folder that has enough space to receive
-	// this sector. Keep trying new storage folders if some return
-	// errors during disk operations.
-	wal.mu.Lock()
-	storageFolders := wal.cm.availableStorageFolders()
-	wal.mu.Unlock()
-	var syncChan chan struct{}
+// managedWriteSectorCopy writes a copy of a sector into one of the provided
+// storage folders, trying the next folder if a folder returns errors during
+// disk operations. On success the storage folder is returned read-locked with
+// the sector marked as available. The caller has to add the returned sector
+// update to the WAL before unlocking the storage folder.
+func (wal *writeAheadLog) managedWriteSectorCopy(id sectorID, data []byte, count uint64, storageFolders []*storageFolder) (*storageFolder, sectorUpdate, error) {
+	// Copy the storage folders, failing folders are removed from the slice.
+	storageFolders = append([]*storageFolder(nil), storageFolders...)
 	for len(storageFolders) >= 1 {
-		var storageFolderIndex int
-		err := func() error {
-			// NOTE: Convention is broken when working with WAL lock here, due
-			// to the complexity required with managing both the WAL lock and
-			// the storage folder lock. Pay close attention when reviewing and
-			// modifying.
-
-			// Grab a vacant storage folder.
-			wal.mu.Lock()
-			wal.cm.sectorMu.Lock()
-			var sf *storageFolder
-			sf, storageFolderIndex = vacancyStorageFolder(storageFolders)
-			if sf == nil {
-				// None of the storage folders have enough room to house the
-				// sector.
-				wal.cm.sectorMu.Unlock()
-				wal.mu.Unlock()
-				return errors.New(modules.V1420HostOutOfStorageErrString)
-			}
-			defer sf.mu.RUnlock()
+		// NOTE: Convention is broken when working with WAL lock here, due to
+		// the complexity required with managing both the WAL lock and the
+		// storage folder lock. Pay close attention when reviewing and
+		// modifying.
 
-			// Grab a sector from the storage folder. WAL lock cannot be
-			// released between grabbing the storage folder and grabbing a
-			// sector lest another thread request the final available sector in
-			// the storage folder.
-			sectorIndex, err := randFreeSector(sf.usage)
-			if err != nil {
-				wal.mu.Unlock()
-				wal.cm.log.Critical("a storage folder with full usage was returned from emptiestStorageFolder")
-				return err
-			}
-			// Set the usage, but mark it as uncommitted.
-			sf.setUsage(sectorIndex)
-			sf.availableSectors[id] = sectorIndex
+		// Grab a vacant storage folder.
+		wal.mu.Lock()
+		wal.cm.sectorMu.Lock()
+		sf, storageFolderIndex := vacancyStorageFolder(storageFolders)
+		if sf == nil {
+			// None of the storage folders have enough room to house the
+			// sector.
 			wal.cm.sectorMu.Unlock()
 			wal.mu.Unlock()
+			break
+		}
 
-			// NOTE: The usage has been set, in the event of failure the usage
-			// must be cleared.
+		// Grab a sector from the storage folder. WAL lock cannot be released
+		// between grabbing the storage folder and grabbing a sector lest
+		// another thread request the final available sector in the storage
+		// folder.
+		sectorIndex, err := randFreeSector(sf.usage)
+		if err != nil {
+			wal.cm.sectorMu.Unlock()
+			wal.mu.Unlock()
+			sf.mu.RUnlock()
+			wal.cm.log.Critical("a storage folder with full usage was returned from emptiestStorageFolder")
+			storageFolders = append(storageFolders[:storageFolderIndex], storageFolders[storageFolderIndex+1:]...)
+			continue
+		}
+		// Set the usage, but mark it as uncommitted.
+		sf.setUsage(sectorIndex)
+		sf.availableSectors[id] = sectorIndex
+		wal.cm.sectorMu.Unlock()
+		wal.mu.Unlock()
 
-			// Try writing the new sector to disk.
-			err = writeSector(sf.sectorFile, sectorIndex, data)
-			if err != nil {
-				wal.cm.log.Printf("ERROR: Unable to write sector for folder %v: %v\n", sf.path, err)
-				atomic.AddUint64(&sf.atomicFailedWrites, 1)
-				wal.mu.Lock()
-				wal.cm.sectorMu.Lock()
-				sf.clearUsage(sectorIndex)
-				delete(sf.availableSectors, id)
-				wal.cm.sectorMu.Unlock()
-				wal.mu.Unlock()
-				return errDiskTrouble
-			}
+		// NOTE: The usage has been set, in the event of failure the usage must
+		// be cleared.
 
-			// Try writing the sector metadata to disk.
-			count := uint64(1)
-			su := sectorUpdate{
-				Count:  count,
-				ID:     id,
-				Folder: sf.index,
-				Index:  sectorIndex,
-			}
+		// Try writing the new sector to disk and then its metadata.
+		su := sectorUpdate{
+			Count:  count,
+			ID:     id,
+			Folder: sf.index,
+			Index:  sectorIndex,
+		}
+		err = writeSector(sf.sectorFile, sectorIndex, data)
+		if err != nil {
+			wal.cm.log.Printf("ERROR: Unable to write sector for folder %v: %v\n", sf.path, err)
+			atomic.AddUint64(&sf.atomicFailedWrites, 1)
+		} else {
 			err = wal.writeSectorMetadata(sf, su)
 			if err != nil {
 				wal.cm.log.Printf("ERROR: Unable to write sector metadata for folder %v: %v\n", sf.path, err)
 				atomic.AddUint64(&sf.atomicFailedWrites, 1)
-				wal.mu.Lock()
-				wal.cm.sectorMu.Lock()
-				sf.clearUsage(sectorIndex)
-				delete(sf.availableSectors, id)
-				wal.cm.sectorMu.Unlock()
-				wal.mu.Unlock()
-				return errDiskTrouble
-			}
-
-			// Sector added successfully, update the WAL and the state.
-			sl := sectorLocation{
-				index:         sectorIndex,
-				storageFolder: sf.index,
-				count:         count,
 			}
+		}
+		if err != nil {
 			wal.mu.Lock()
-			wal.appendChange(stateChange{
-				SectorUpdates: []sectorUpdate{su},
-			})
 			wal.cm.sectorMu.Lock()
-			delete(wal.cm.storageFolders[su.Folder].availableSectors, id)
-			wal.cm.sectorLocations[id] = sl
+			sf.clearUsage(sectorIndex)
+			delete(sf.availableSectors, id)
 			wal.cm.sectorMu.Unlock()
-			syncChan = wal.syncChan
 			wal.mu.Unlock()
-			return nil
-		}()
-		if err != nil {
-			// End the loop if no storage folder proved suitable.
-			if storageFolderIndex == -1 {
-				storageFolders = nil
-				break
-			}
+			sf.mu.RUnlock()
 
 			// Remove the storage folder that failed and try the next one.
 			storageFolders = append(storageFolders[:storageFolderIndex], storageFolders[storageFolderIndex+1:]...)
 			continue
 		}
-		// Sector added successfully, break.
-		break
+		return sf, su, nil
+	}
+	return nil, sectorUpdate{}, errors.New(modules.V1420HostOutOfStorageErrString)
+}
+
+// managedAddPhysicalSector is a WAL operation to add a physical sector to the
+// contract manager. If mirroring is enabled, a second copy of the sector is
+// stored on a different device.
+func (wal *writeAheadLog) managedAddPhysicalSector(id sectorID, data []byte) error {
+	// Sanity check - data should have modules.SectorSize bytes.
+	if uint64(len(data)) != modules.SectorSize {
+		wal.cm.log.Critical("sector has the wrong size", modules.SectorSize, len(data))
+		return errors.New("malformed sector")
+	}
+
+	// Find a committed storage folder that has enough space to receive
+	// this sector.
+	wal.mu.Lock()
+	storageFolders := wal.cm.availableStorageFolders()
+	wal.mu.Unlock()
+	sf, su, err := wal.managedWriteSectorCopy(id, data, 1, storageFolders)
+	if err != nil {
+		return err
+	}
+	defer sf.mu.RUnlock()
+
+	// Store the mirror in a storage folder on a different device. The sector
+	// is added even if that fails, the rebuild will retry later.
+	var msf *storageFolder
+	var msu sectorUpdate
+	wal.cm.sectorMu.Lock()
+	mirror := wal.cm.redundancy == modules.StorageRedundancyMirror
+	mirrorFolders := otherDeviceStorageFolders(storageFolders, sf)
+	wal.cm.sectorMu.Unlock()
+	if mirror {
+		msf, msu, err = wal.managedWriteSectorCopy(id, data, 1, mirrorFolders)
+		if err != nil {
+			wal.cm.log.Printf("WARN: Unable to mirror sector %v: %v\n", id, err)
+			msf = nil
+		} else {
+			defer msf.mu.RUnlock()
+		}
+	}
+
+	// Sector added successfully, update the WAL and the state.
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	sus := []sectorUpdate{su}
+	delete(sf.availableSectors, id)
+	wal.cm.sectorLocations[id] = sectorLocation{
+		index:         su.Index,
+		storageFolder: su.Folder,
+		count:         su.Count,
 	}
-	if len(storageFolders) < 1 {
-		return errors.New(modules.V1420HostOutOfStorageErrString)
+	if msf != nil {
+		sus = append(sus, msu)
+		delete(msf.availableSectors, id)
+		wal.cm.sectorMirrors[id] = sectorLocation{
+			index:         msu.Index,
+			storageFolder: msu.Folder,
+			count:         msu.Count,
+		}
 	}
+	wal.appendChange(stateChange{
+		SectorUpdates: sus,
+	})
+	wal.cm.sectorMu.Unlock()
+	syncChan := wal.syncChan
+	wal.mu.Unlock()
 
 	// Wait for the synchronize.
-	// sectors.
 	<-syncChan
 	return nil
 }
@@ -196,17 +216,24 @@ func (wal *writeAheadLog) managedAddVirtualSector(id sectorID, location sectorLo
 	if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
 		// Need to check that the storage folder exists before syncing the
 		// commit that increases the virtual sector count.
+		wal.cm.sectorMu.Unlock()
 		wal.mu.Unlock()
 		return errStorageFolderNotFound
 	}
+	sus := []sectorUpdate{su}
+	msf, msu, mirrored := wal.cm.mirrorUpdate(id, location.count)
+	if mirrored {
+		sus = append(sus, msu)
+	}
 	wal.appendChange(stateChange{
-		SectorUpdates: []sectorUpdate{su},
+		SectorUpdates: sus,
 	})
 	wal.cm.sectorLocations[id] = location
 	wal.cm.sectorMu.Unlock()
 	syncChan := wal.syncChan
 	wal.mu.Unlock()
 	<-syncChan
+	wal.managedFinishMirrorUpdate(msf, msu)
 
 	// Update the metadata on disk. Metadata is updated on disk after the sync
 	// so that there is no risk of obliterating the previous count in the event
@@ -218,14 +245,20 @@ func (wal *writeAheadLog) managedAddVirtualSector(id sectorID, location sectorLo
 		su.Count--
 		location.count--
 		wal.mu.Lock()
+		wal.cm.sectorMu.Lock()
+		sus := []sectorUpdate{su}
+		msf, msu, mirrored := wal.cm.mirrorUpdate(id, location.count)
+		if mirrored {
+			sus = append(sus, msu)
+		}
 		wal.appendChange(stateChange{
-			SectorUpdates: []sectorUpdate{su},
+			SectorUpdates: sus,
 		})
-		wal.cm.sectorMu.Lock()
 		wal.cm.sectorLocations[id] = location
 		wal.cm.sectorMu.Unlock()
 		wal.mu.Unlock()
 		<-syncChan
+		wal.managedFinishMirrorUpdate(msf, msu)
 		return build.ExtendErr("unable to write sector metadata during addSector call", err)
 	}
 	return nil
@@ -236,7 +269,8 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 	// Write the sector delete to the WAL.
 	var location sectorLocation
 	var syncChan chan struct{}
-	var sf *storageFolder
+	var sf, msf *storageFolder
+	var msu sectorUpdate
 	err := func() error {
 		wal.mu.Lock()
 		defer wal.mu.Unlock()
@@ -257,13 +291,19 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 		}
 
 		// Inform the WAL of the sector update.
+		sus := []sectorUpdate{{
+			Count:  0,
+			ID:     id,
+			Folder: location.storageFolder,
+			Index:  location.index,
+		}}
+		var mirrored bool
+		msf, msu, mirrored = wal.cm.mirrorUpdate(id, 0)
+		if mirrored {
+			sus = append(sus, msu)
+		}
 		wal.appendChange(stateChange{
-			SectorUpdates: []sectorUpdate{{
-				Count:  0,
-				ID:     id,
-				Folder: location.storageFolder,
-				Index:  location.index,
-			}},
+			SectorUpdates: sus,
 		})
 
 		// Delete the sector and mark the usage as available.
@@ -287,6 +327,7 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 	sf.clearUsage(location.index)
 	wal.cm.sectorMu.Unlock()
 	wal.mu.Unlock()
+	wal.managedFinishMirrorUpdate(msf, msu)
 	return nil
 }
 
@@ -295,8 +336,8 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 	// Inform the WAL of the removed sector.
 	var location sectorLocation
-	var su sectorUpdate
-	var sf *storageFolder
+	var su, msu sectorUpdate
+	var sf, msf *storageFolder
 	var syncChan chan struct{}
 	err := func() error {
 		wal.mu.Lock()
@@ -326,8 +367,14 @@ func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 			Folder: location.storageFolder,
 			Index:  location.index,
 		}
+		sus := []sectorUpdate{su}
+		var mirrored bool
+		msf, msu, mirrored = wal.cm.mirrorUpdate(id, location.count)
+		if mirrored {
+			sus = append(sus, msu)
+		}
 		wal.appendChange(stateChange{
-			SectorUpdates: []sectorUpdate{su},
+			SectorUpdates: sus,
 		})
 
 		// Update the in-memeory representation of the sector.
@@ -347,6 +394,7 @@ func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 	}
 	// synchronize before updating the metadata or clearing the usage.
 	<-syncChan
+	wal.managedFinishMirrorUpdate(msf, msu)
 
 	// Update the metadata, and the usage.
 	if location.count != 0 {
@@ -363,6 +411,8 @@ func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
 			wal.cm.sectorLocations[id] = location
 			wal.cm.sectorMu.Unlock()
 			wal.mu.Unlock()
+
+			// The mirror keeps the reduced count until the next rebuild.
 			return build.ExtendErr("failed to write sector metadata", err)
 		}
 	}
@@ -389,6 +439,8 @@ func (wal *writeAheadLog) managedRemoveSectors(sectors map[sectorID]uint64) erro
 	wal.cm.sectorMu.Lock()
 
 	changes := make([]sectorUpdate, 0, len(sectors))
+	var mirrorFolders []*storageFolder
+	var mirrorChanges []sectorUpdate
 	for id, count := range sectors {
 		var exists bool
 		location, exists := wal.cm.sectorLocations[id]
@@ -417,6 +469,10 @@ func (wal *writeAheadLog) managedRemoveSectors(sectors map[sectorID]uint64) erro
 			Index:  location.index,
 		}
 		changes = append(changes, su)
+		if msf, msu, mirrored := wal.cm.mirrorUpdate(id, location.count); mirrored {
+			mirrorFolders = append(mirrorFolders, msf)
+			mirrorChanges = append(mirrorChanges, msu)
+		}
 
 		// Update the in-memory representation of the sector.
 		if location.count == 0 {
@@ -436,7 +492,7 @@ func (wal *writeAheadLog) managedRemoveSectors(sectors map[sectorID]uint64) erro
 	}
 
 	wal.appendChange(stateChange{
-		SectorUpdates: changes,
+		SectorUpdates: append(changes, mirrorChanges...),
 	})
 
 	ch := wal.syncChan
@@ -445,6 +501,9 @@ func (wal *writeAheadLog) managedRemoveSectors(sectors map[sectorID]uint64) erro
 
 	// synchronize before updating the metadata or clearing the usage.
 	<-ch
+	for i, msu := range mirrorChanges {
+		wal.managedFinishMirrorUpdate(mirrorFolders[i], msu)
+	}
 
 	for _, su := range changes {
 		sf, exists := wal.cm.storageFolders[su.Folder]
@@ -18,6 +18,7 @@ import (
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"gitlab.com/NebulousLabs/fastrand"
 
@@ -2345,3 +2346,50 @@ func TestAddVirtualSectorOverflow(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestAddVirtualSectorMissingFolder checks that managedAddVirtualSector
+// releases all of its locks when the sector's storage folder doesn't exist.
+func TestAddVirtualSectorMissingFolder(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder to the contract manager tester.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, persist.DefaultDiskPermissionsTest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a virtual sector to a storage folder that doesn't exist.
+	root, data := randSector()
+	id := cmt.cm.managedSectorID(root)
+	err = cmt.cm.wal.managedAddVirtualSector(id, sectorLocation{storageFolder: math.MaxUint16, count: 1})
+	if !errors.Is(err, errStorageFolderNotFound) {
+		t.Fatal("expected errStorageFolderNotFound but got", err)
+	}
+
+	// Adding a sector needs the same locks and shouldn't block.
+	done := make(chan error)
+	go func() {
+		done <- cmt.cm.AddSector(root, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second * 10):
+		t.Fatal("AddSector blocked after a failed managedAddVirtualSector")
+	}
+}
@@ -95,11 +95,16 @@ type storageFolder struct {
 	// an error if it is queried.
 	atomicUnavailable uint64 // uint64 for alignment
 
-	// The index, path, and usage are all saved directly to disk.
+	// The index, path, tier and usage are all saved directly to disk.
 	index uint16
 	path  string
+	tier  modules.StorageFolderTier
 	usage []uint64
 
+	// device identifies the device that stores the storage folder. The two
+	// copies of a mirrored sector are always stored on different devices.
+	device string
+
 	// availableSectors indicates sectors which are marked as consumed in the
 	// usage field but are actually available. They cannot be marked as free in
 	// the usage until the action which freed them has synced to disk, but the
@@ -200,36 +205,37 @@ func usageSectors(usage []uint64) (usageSectors []uint32) {
 // vacancyStorageFolder takes a set of storage folders and returns a storage
 // folder with vacancy for a sector along with its index. 'nil' and '-1' are
 // returned if none of the storage folders are available to accept a sector.
-// The returned storage folder will be holding an RLock on its mutex.
+// The returned storage folder will be holding an RLock on its mutex. Storage
+// folders of the slow tier are preferred, the fast tier is reserved for
+// sectors that are promoted because they are read frequently.
 func vacancyStorageFolder(sfs []*storageFolder) (*storageFolder, int) {
-	enoughRoom := false
-	var winningIndex int
+	for _, fast := range []bool{false, true} {
+		// Go through the folders in random order.
+		for _, index := range fastrand.Perm(len(sfs)) {
+			sf := sfs[index]
+
+			// Skip past this storage folder if it's not in the current tier.
+			if (sf.tier == modules.StorageFolderTierFast) != fast {
+				continue
+			}
 
-	// Go through the folders in random order.
-	for _, index := range fastrand.Perm(len(sfs)) {
-		sf := sfs[index]
+			// Skip past this storage folder if there is not enough room for at
+			// least one sector.
+			if sf.sectors >= uint64(len(sf.usage))*storageFolderGranularity {
+				continue
+			}
 
-		// Skip past this storage folder if there is not enough room for at
-		// least one sector.
-		if sf.sectors >= uint64(len(sf.usage))*storageFolderGranularity {
-			continue
-		}
+			// Skip past this storage folder if it's not available to receive
+			// new data.
+			if !sf.mu.TryRLock() {
+				continue
+			}
 
-		// Skip past this storage folder if it's not available to receive new
-		// data.
-		if !sf.mu.TryRLock() {
-			continue
+			// Select this storage folder.
+			return sf, index
 		}
-
-		// Select this storage folder.
-		enoughRoom = true
-		winningIndex = index
-		break
 	}
-	if !enoughRoom {
-		return nil, -1
-	}
-	return sfs[winningIndex], winningIndex
+	return nil, -1
 }
 
 // clearUsage will unset the usage bit at the provided sector index for this
@@ -330,10 +336,12 @@ func (cm *ContractManager) threadedFolderRecheck() {
 			sf.sectorFile, err2 = cm.dependencies.OpenFile(filepath.Join(sf.path, sectorFile), os.O_RDWR, 0700)
 			if err1 == nil && err2 == nil {
 				// The storage folder has been found, and loading can be
-				// completed.
+				// completed. The folder might be on a new device.
 				cm.sectorMu.Lock()
+				sf.device = cm.storageFolderDevice(sf.path)
 				cm.loadSectorLocations(sf)
 				cm.sectorMu.Unlock()
+				cm.staticRebuild.signal()
 			} else {
 				// One of the opens failed, close the file handle for the
 				// opens that did not fail.
@@ -373,6 +381,10 @@ func (cm *ContractManager) ResetStorageFolderHealth(index uint16) error {
 	atomic.StoreUint64(&sf.atomicFailedWrites, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulReads, 0)
 	atomic.StoreUint64(&sf.atomicSuccessfulWrites, 0)
+
+	// The folder might have been repaired or replaced, check the redundancy
+	// of the sectors.
+	cm.staticRebuild.signal()
 	return nil
 }
 
@@ -454,6 +466,7 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
 			Index:             sf.index,
 			Path:              sf.path,
+			Tier:              sf.tier,
 		}
 
 		// Set some of the values to extreme numbers if the storage folder is
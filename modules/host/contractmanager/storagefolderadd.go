@@ -329,8 +329,11 @@ func (wal *writeAheadLog) commitAddStorageFolder(ssf savedStorageFolder) {
 	sf = &storageFolder{
 		index: ssf.Index,
 		path:  ssf.Path,
+		tier:  storageFolderTier(ssf.Tier),
 		usage: ssf.Usage,
 
+		device: wal.cm.storageFolderDevice(ssf.Path),
+
 		availableSectors: make(map[sectorID]uint32),
 	}
 
@@ -385,8 +388,11 @@ func (cm *ContractManager) AddStorageFolder(path string, size uint64) error {
 	// Create a storage folder object and add it to the WAL.
 	newSF := &storageFolder{
 		path:  path,
+		tier:  modules.StorageFolderTierSlow,
 		usage: make([]uint64, sectors/64),
 
+		device: cm.storageFolderDevice(path),
+
 		availableSectors: make(map[sectorID]uint32),
 	}
 
@@ -405,5 +411,8 @@ func (cm *ContractManager) AddStorageFolder(path string, size uint64) error {
 		cm.log.Println("Call to AddStorageFolder has failed:", err)
 		return err
 	}
+
+	// The new folder might provide room for missing mirrors.
+	cm.staticRebuild.signal()
 	return nil
 }
@@ -0,0 +1,25 @@
+//go:build !windows
+// +build !windows
+
+package contractmanager
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// deviceID returns an identifier of the device that stores the file or folder
+// at the provided path.
+func deviceID(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	stat, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", errors.New("unable to determine the device of " + path)
+	}
+	return strconv.FormatUint(uint64(stat.Dev), 10), nil
+}
@@ -0,0 +1,17 @@
+package contractmanager
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+)
+
+// deviceID returns an identifier of the device that stores the file or folder
+// at the provided path. On Windows the volume name is used.
+func deviceID(path string) (string, error) {
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return "", errors.New("unable to determine the volume of " + path)
+	}
+	return strings.ToLower(volume), nil
+}
@@ -19,16 +19,33 @@ var (
 	ErrPartialRelocation = errors.New("unable to migrate all sectors")
 )
 
-// managedMoveSector will move a sector from its current storage folder to
-// another.
-func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
+// managedMoveSector will move the copy of a sector in the given storage folder
+// to another storage folder.
+func (wal *writeAheadLog) managedMoveSector(id sectorID, from uint16) error {
+	wal.mu.Lock()
+	storageFolders := wal.cm.availableStorageFolders()
+	wal.mu.Unlock()
+	return wal.managedMoveSectorToFolders(id, from, storageFolders)
+}
+
+// managedMoveSectorToFolders will move the copy of a sector in the given
+// storage folder to one of the provided storage folders. The copies of a
+// mirrored sector are kept on different devices.
+func (wal *writeAheadLog) managedMoveSectorToFolders(id sectorID, from uint16, storageFolders []*storageFolder) error {
 	wal.managedLockSector(id)
 	defer wal.managedUnlockSector(id)
 
 	// Find the sector to be moved.
 	wal.cm.sectorMu.Lock()
-	oldLocation, exists1 := wal.cm.sectorLocations[id]
-	oldFolder, exists2 := wal.cm.storageFolders[oldLocation.storageFolder]
+	oldLocation, mirror, exists1 := wal.cm.sectorCopy(id, from)
+	oldFolder, exists2 := wal.cm.storageFolders[from]
+	otherLocation, mirrored := wal.cm.sectorMirrors[id]
+	if mirror {
+		otherLocation, mirrored = wal.cm.sectorLocations[id]
+	}
+	if otherFolder, exists := wal.cm.storageFolders[otherLocation.storageFolder]; mirrored && exists {
+		storageFolders = otherDeviceStorageFolders(storageFolders, otherFolder)
+	}
 	wal.cm.sectorMu.Unlock()
 	if !exists1 || !exists2 || atomic.LoadUint64(&oldFolder.atomicUnavailable) == 1 {
 		return errors.New("unable to find sector that is targeted for move")
@@ -51,113 +68,34 @@ func (wal *writeAheadLog) managedMoveSector(id sectorID) error {
 		Index:  oldLocation.index,
 	}
 
-	// Place the sector into its new folder and add the atomic move to the WAL.
-	wal.mu.Lock()
-	storageFolders := wal.cm.availableStorageFolders()
-	wal.mu.Unlock()
-	for len(storageFolders) >= 1 {
-		var storageFolderIndex int
-		err := func() error {
-			// NOTE: Convention is broken when working with WAL lock here, due
-			// to the complexity required with managing both the WAL lock and
-			// the storage folder lock. Pay close attention when reviewing and
-			// modifying.
-
-			// Grab a vacant storage folder.
-			wal.mu.Lock()
-			wal.cm.sectorMu.Lock()
-			var sf *storageFolder
-			sf, storageFolderIndex = vacancyStorageFolder(storageFolders)
-			if sf == nil {
-				// None of the storage folders have enough room to house the
-				// sector.
-				wal.cm.sectorMu.Unlock()
-				wal.mu.Unlock()
-				return errors.New(modules.V1420HostOutOfStorageErrString)
-			}
-			defer sf.mu.RUnlock()
-
-			// Grab a sector from the storage folder. WAL lock cannot be
-			// released between grabbing the storage folder and grabbing a
-			// sector lest another thread request the final available sector in
-			// the storage folder.
-			sectorIndex, err := randFreeSector(sf.usage)
-			if err != nil {
-				wal.mu.Unlock()
-				wal.cm.log.Critical("a storage folder with full usage was returned from emptiestStorageFolder")
-				return err
-			}
-			// Set the usage, but mark it as uncommitted.
-			sf.setUsage(sectorIndex)
-			sf.availableSectors[id] = sectorIndex
-			wal.cm.sectorMu.Unlock()
-			wal.mu.Unlock()
-
-			// NOTE: The usage has been set, in the event of failure the usage
-			// must be cleared.
-
-			// Try writing the new sector to disk.
-			err = writeSector(sf.sectorFile, sectorIndex, sectorData)
-			if err != nil {
-				wal.cm.log.Printf("ERROR: Unable to write sector for folder %v: %v\n", sf.path, err)
-				atomic.AddUint64(&sf.atomicFailedWrites, 1)
-				wal.mu.Lock()
-				sf.clearUsage(sectorIndex)
-				delete(sf.availableSectors, id)
-				wal.mu.Unlock()
-				return errDiskTrouble
-			}
-
-			// Try writing the sector metadata to disk.
-			su := sectorUpdate{
-				Count:  oldLocation.count,
-				ID:     id,
-				Folder: sf.index,
-				Index:  sectorIndex,
-			}
-			err = wal.writeSectorMetadata(sf, su)
-			if err != nil {
-				wal.cm.log.Printf("ERROR: Unable to write sector metadata for folder %v: %v\n", sf.path, err)
-				atomic.AddUint64(&sf.atomicFailedWrites, 1)
-				wal.mu.Lock()
-				sf.clearUsage(sectorIndex)
-				delete(sf.availableSectors, id)
-				wal.mu.Unlock()
-				return errDiskTrouble
-			}
-
-			// Sector added successfully, update the WAL and the state.
-			sl := sectorLocation{
-				index:         sectorIndex,
-				storageFolder: sf.index,
-				count:         oldLocation.count,
-			}
-			wal.mu.Lock()
-			wal.cm.sectorMu.Lock()
-			wal.appendChange(stateChange{
-				SectorUpdates: []sectorUpdate{oldSU, su},
-			})
-			oldFolder.clearUsage(oldLocation.index)
-			delete(wal.cm.sectorLocations, oldSU.ID)
-			delete(sf.availableSectors, id)
-			wal.cm.sectorLocations[id] = sl
-			wal.cm.sectorMu.Unlock()
-			wal.mu.Unlock()
-			return nil
-		}()
-		if err != nil && err.Error() == modules.V1420HostOutOfStorageErrString {
-			return err
-		} else if err != nil {
-			// Try the next storage folder.
-			storageFolders = append(storageFolders[:storageFolderIndex], storageFolders[storageFolderIndex+1:]...)
-			continue
-		}
-		// Sector added successfully, break.
-		break
+	// Place the sector into its new folder.
+	sf, su, err := wal.managedWriteSectorCopy(id, sectorData, oldLocation.count, storageFolders)
+	if err != nil {
+		return err
 	}
-	if len(storageFolders) < 1 {
-		return errors.New(modules.V1420HostOutOfStorageErrString)
+	defer sf.mu.RUnlock()
+
+	// Sector added successfully, add the atomic move to the WAL and update
+	// the state.
+	sl := sectorLocation{
+		index:         su.Index,
+		storageFolder: su.Folder,
+		count:         su.Count,
 	}
+	wal.mu.Lock()
+	wal.cm.sectorMu.Lock()
+	wal.appendChange(stateChange{
+		SectorUpdates: []sectorUpdate{oldSU, su},
+	})
+	oldFolder.clearUsage(oldLocation.index)
+	delete(sf.availableSectors, id)
+	if mirror {
+		wal.cm.sectorMirrors[id] = sl
+	} else {
+		wal.cm.sectorLocations[id] = sl
+	}
+	wal.cm.sectorMu.Unlock()
+	wal.mu.Unlock()
 	return nil
 }
 
@@ -213,7 +151,7 @@ func (wal *writeAheadLog) managedEmptyStorageFolder(sfIndex uint16, startingPoin
 			for {
 				select {
 				case id := <-workChan:
-					err := wal.managedMoveSector(id)
+					err := wal.managedMoveSector(id, sfIndex)
 					if errors.Contains(err, errDiskTrouble) {
 						wal.cm.staticAlerter.RegisterAlert(modules.AlertIDHostDiskTrouble, AlertMSGHostDiskTrouble, "", modules.SeverityCritical)
 					}
@@ -256,7 +194,7 @@ func (wal *writeAheadLog) managedEmptyStorageFolder(sfIndex uint16, startingPoin
 				// Reference the sector locations map to get the most
 				// up-to-date status for the sector.
 				wal.cm.sectorMu.Lock()
-				_, exists := wal.cm.sectorLocations[id]
+				_, _, exists := wal.cm.sectorCopy(id, sfIndex)
 				wal.cm.sectorMu.Unlock()
 				if !exists {
 					// The sector has been deleted, but the usage has not been
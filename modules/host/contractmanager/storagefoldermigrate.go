@@ -0,0 +1,389 @@
+package contractmanager
+
+// storagefoldermigrate.go implements the migration of all sectors from one
+// storage folder into another and the rebalancing of the free space across
+// storage folders. Both operations run in the background, report their
+// progress, can be throttled to a max bandwidth and can be cancelled. Only one
+// operation can run at a time. Sectors are moved with the same WAL-safe
+// machinery that is used when emptying a storage folder.
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/modules"
+)
+
+var (
+	// errMigrateToSameFolder is returned when migrating a storage folder into
+	// itself.
+	errMigrateToSameFolder = errors.New("source and destination storage folder must differ")
+
+	// errStorageFolderBusy is returned when migrating a storage folder that is
+	// being added, resized or removed.
+	errStorageFolderBusy = errors.New("storage folder is busy with another operation")
+
+	// errStorageFolderUnavailable is returned when migrating from or to a
+	// storage folder that is unavailable.
+	errStorageFolderUnavailable = errors.New("storage folder is unavailable")
+
+	// errInterruptedByShutdown is the error of a storage folder operation that
+	// didn't finish before the contract manager was closed.
+	errInterruptedByShutdown = errors.New("interrupted by shutdown")
+)
+
+type (
+	// folderOperation is a running or finished storage folder operation.
+	folderOperation struct {
+		op       modules.StorageFolderOperation
+		cancel   chan struct{}
+		nextMove time.Time
+		mu       sync.Mutex
+	}
+
+	// rebalanceFolder is a storage folder that takes part in a rebalance.
+	rebalanceFolder struct {
+		sf       *storageFolder
+		capacity uint64
+		used     uint64
+		sectors  []sectorID
+	}
+
+	// sectorMove is a planned move of the copy of a sector in one storage
+	// folder into another storage folder.
+	sectorMove struct {
+		id   sectorID
+		from uint16
+		dest *storageFolder
+	}
+)
+
+// managedRecordMove updates the progress of the operation after a sector move.
+func (fo *folderOperation) managedRecordMove(err error) {
+	fo.mu.Lock()
+	defer fo.mu.Unlock()
+	if err != nil {
+		fo.op.SectorsFailed++
+	} else {
+		fo.op.SectorsMoved++
+	}
+}
+
+// managedThrottle blocks until the next sector may be moved without exceeding
+// the max bandwidth of the operation. It returns an error if the operation was
+// cancelled or the contract manager is shutting down.
+func (fo *folderOperation) managedThrottle(stop <-chan struct{}) error {
+	fo.mu.Lock()
+	var wait time.Duration
+	if fo.op.MaxBandwidth > 0 {
+		now := time.Now()
+		if fo.nextMove.Before(now) {
+			fo.nextMove = now
+		}
+		wait = fo.nextMove.Sub(now)
+		fo.nextMove = fo.nextMove.Add(time.Duration(modules.SectorSize * uint64(time.Second) / fo.op.MaxBandwidth))
+	}
+	fo.mu.Unlock()
+
+	select {
+	case <-fo.cancel:
+		return errors.New("cancelled")
+	case <-stop:
+		return errInterruptedByShutdown
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// planRebalance returns the moves that even out the relative free space of
+// the given storage folders.
+func planRebalance(folders []*rebalanceFolder) []sectorMove {
+	var capacity, used uint64
+	for _, rf := range folders {
+		capacity += rf.capacity
+		used += rf.used
+	}
+	if capacity == 0 {
+		return nil
+	}
+	// target returns the number of sectors a folder stores once the folders
+	// are balanced.
+	target := func(rf *rebalanceFolder) uint64 {
+		return rf.capacity * used / capacity
+	}
+
+	// Move sectors out of the fullest folders into the emptiest ones.
+	var moves []sectorMove
+	var dests []*rebalanceFolder
+	for _, rf := range folders {
+		if rf.used < target(rf) {
+			dests = append(dests, rf)
+		}
+	}
+	for _, rf := range folders {
+		for _, id := range rf.sectors {
+			if rf.used <= target(rf) {
+				break
+			}
+			sort.Slice(dests, func(i, j int) bool {
+				return dests[i].used*dests[j].capacity < dests[j].used*dests[i].capacity
+			})
+			if len(dests) == 0 || dests[0].used >= target(dests[0]) {
+				return moves
+			}
+			moves = append(moves, sectorMove{id: id, from: rf.sf.index, dest: dests[0].sf})
+			dests[0].used++
+			rf.used--
+		}
+	}
+	return moves
+}
+
+// managedStartFolderOperation registers a new storage folder operation. Only
+// one operation can run at a time.
+func (cm *ContractManager) managedStartFolderOperation(op modules.StorageFolderOperation) (*folderOperation, error) {
+	cm.folderOperationMu.Lock()
+	defer cm.folderOperationMu.Unlock()
+	if cm.folderOperation != nil {
+		cm.folderOperation.mu.Lock()
+		running := cm.folderOperation.op.Running
+		cm.folderOperation.mu.Unlock()
+		if running {
+			return nil, modules.ErrStorageFolderOperationRunning
+		}
+	}
+	op.StartTime = time.Now()
+	op.Running = true
+	cm.folderOperation = &folderOperation{
+		op:     op,
+		cancel: make(chan struct{}),
+	}
+	return cm.folderOperation, nil
+}
+
+// threadedRunFolderOperation moves the planned sectors, waits for the moves to
+// be synced and then calls finish. The caller needs to call tg.Add before
+// starting the thread.
+func (cm *ContractManager) threadedRunFolderOperation(fo *folderOperation, moves []sectorMove, finish func(completed bool) error) {
+	defer cm.tg.Done()
+
+	// Move the sectors in parallel.
+	var wg sync.WaitGroup
+	workChan := make(chan sectorMove)
+	for i := 0; i < folderOperationThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for move := range workChan {
+				err := cm.wal.managedMoveSectorToFolders(move.id, move.from, []*storageFolder{move.dest})
+				if err != nil {
+					cm.log.Println("Unable to move sector:", err)
+				}
+				fo.managedRecordMove(err)
+			}
+		}()
+	}
+	var interruptErr error
+	for _, move := range moves {
+		interruptErr = fo.managedThrottle(cm.tg.StopChan())
+		if interruptErr != nil {
+			break
+		}
+		workChan <- move
+	}
+	close(workChan)
+	wg.Wait()
+
+	// Wait for a synchronize to confirm that all of the moves have succeeded
+	// in full.
+	cm.wal.mu.Lock()
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	<-syncChan
+
+	fo.mu.Lock()
+	completed := interruptErr == nil && fo.op.SectorsFailed == 0
+	fo.mu.Unlock()
+	err := finish(completed)
+	if errors.Contains(interruptErr, errInterruptedByShutdown) {
+		err = errors.Compose(interruptErr, err)
+	} else if interruptErr == nil && !completed {
+		err = errors.Compose(ErrPartialRelocation, err)
+	}
+
+	fo.mu.Lock()
+	fo.op.Running = false
+	fo.op.EndTime = time.Now()
+	if err != nil {
+		fo.op.Error = err.Error()
+	}
+	fo.mu.Unlock()
+}
+
+// CancelStorageFolderOperation cancels the running storage folder operation.
+// Sectors that were moved already stay in their new storage folder.
+func (cm *ContractManager) CancelStorageFolderOperation() error {
+	cm.folderOperationMu.Lock()
+	defer cm.folderOperationMu.Unlock()
+	if cm.folderOperation == nil {
+		return modules.ErrNoStorageFolderOperation
+	}
+	fo := cm.folderOperation
+	fo.mu.Lock()
+	defer fo.mu.Unlock()
+	if !fo.op.Running || fo.op.Cancelled {
+		return modules.ErrNoStorageFolderOperation
+	}
+	fo.op.Cancelled = true
+	close(fo.cancel)
+	return nil
+}
+
+// MigrateStorageFolder starts moving all sectors of the source storage folder
+// into the destination storage folder. No new sectors are added to the source
+// folder while the migration is running. If removeSource is set, the source
+// folder is removed once all of its sectors were moved.
+func (cm *ContractManager) MigrateStorageFolder(source, destination uint16, removeSource bool, maxBandwidth uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	if source == destination {
+		cm.tg.Done()
+		return errMigrateToSameFolder
+	}
+
+	// Retrieve the specified storage folders.
+	cm.sectorMu.Lock()
+	sf, exists1 := cm.storageFolders[source]
+	dest, exists2 := cm.storageFolders[destination]
+	cm.sectorMu.Unlock()
+	if !exists1 || !exists2 {
+		cm.tg.Done()
+		return errStorageFolderNotFound
+	}
+	if atomic.LoadUint64(&sf.atomicUnavailable) == 1 || atomic.LoadUint64(&dest.atomicUnavailable) == 1 {
+		cm.tg.Done()
+		return errStorageFolderUnavailable
+	}
+
+	fo, err := cm.managedStartFolderOperation(modules.StorageFolderOperation{
+		Type:         modules.StorageFolderOperationMigrate,
+		Source:       source,
+		Destination:  destination,
+		RemoveSource: removeSource,
+		MaxBandwidth: maxBandwidth,
+	})
+	if err != nil {
+		cm.tg.Done()
+		return err
+	}
+
+	// Lock the source folder for the duration of the migration to prevent
+	// new sectors from being added to it.
+	if !sf.mu.TryLock() {
+		fo.mu.Lock()
+		fo.op.Running = false
+		fo.op.EndTime = time.Now()
+		fo.op.Error = errStorageFolderBusy.Error()
+		fo.mu.Unlock()
+		cm.tg.Done()
+		return errStorageFolderBusy
+	}
+
+	// Plan the moves of the sectors and mirrors in the source folder.
+	var moves []sectorMove
+	cm.sectorMu.Lock()
+	for _, sls := range []map[sectorID]sectorLocation{cm.sectorLocations, cm.sectorMirrors} {
+		for id, sl := range sls {
+			if sl.storageFolder == source {
+				moves = append(moves, sectorMove{id: id, from: source, dest: dest})
+			}
+		}
+	}
+	cm.sectorMu.Unlock()
+	fo.mu.Lock()
+	fo.op.SectorsTotal = uint64(len(moves))
+	fo.mu.Unlock()
+
+	go cm.threadedRunFolderOperation(fo, moves, func(completed bool) error {
+		sf.mu.Unlock()
+		if !completed || !removeSource {
+			return nil
+		}
+		return errors.AddContext(cm.RemoveStorageFolder(source, false), "unable to remove source folder")
+	})
+	return nil
+}
+
+// RebalanceStorageFolders starts moving sectors between the storage folders of
+// each tier until all of them have the same relative amount of free space.
+func (cm *ContractManager) RebalanceStorageFolders(maxBandwidth uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	fo, err := cm.managedStartFolderOperation(modules.StorageFolderOperation{
+		Type:         modules.StorageFolderOperationRebalance,
+		MaxBandwidth: maxBandwidth,
+	})
+	if err != nil {
+		cm.tg.Done()
+		return err
+	}
+
+	// Group the available storage folders by tier.
+	cm.wal.mu.Lock()
+	sfs := cm.availableStorageFolders()
+	cm.wal.mu.Unlock()
+	tiers := make(map[modules.StorageFolderTier][]*rebalanceFolder)
+	folders := make(map[uint16]*rebalanceFolder)
+	cm.sectorMu.Lock()
+	for _, sf := range sfs {
+		rf := &rebalanceFolder{
+			sf:       sf,
+			capacity: uint64(len(sf.usage)) * storageFolderGranularity,
+			used:     sf.sectors,
+		}
+		tiers[sf.tier] = append(tiers[sf.tier], rf)
+		folders[sf.index] = rf
+	}
+	for _, sls := range []map[sectorID]sectorLocation{cm.sectorLocations, cm.sectorMirrors} {
+		for id, sl := range sls {
+			if rf, exists := folders[sl.storageFolder]; exists {
+				rf.sectors = append(rf.sectors, id)
+			}
+		}
+	}
+	cm.sectorMu.Unlock()
+
+	// Plan the moves of every tier.
+	var moves []sectorMove
+	for _, rfs := range tiers {
+		moves = append(moves, planRebalance(rfs)...)
+	}
+	fo.mu.Lock()
+	fo.op.SectorsTotal = uint64(len(moves))
+	fo.mu.Unlock()
+
+	go cm.threadedRunFolderOperation(fo, moves, func(bool) error {
+		return nil
+	})
+	return nil
+}
+
+// StorageFolderOperation returns the running or most recently finished
+// storage folder operation.
+func (cm *ContractManager) StorageFolderOperation() (modules.StorageFolderOperation, bool) {
+	cm.folderOperationMu.Lock()
+	defer cm.folderOperationMu.Unlock()
+	if cm.folderOperation == nil {
+		return modules.StorageFolderOperation{}, false
+	}
+	cm.folderOperation.mu.Lock()
+	defer cm.folderOperation.mu.Unlock()
+	return cm.folderOperation.op, true
+}
@@ -0,0 +1,282 @@
+package contractmanager
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// addTestStorageFolders adds storage folders of the given number of sectors
+// to the contract manager tester and returns their paths.
+func (cmt *contractManagerTester) addTestStorageFolders(sectors ...uint64) ([]string, error) {
+	var paths []string
+	existing := len(cmt.cm.StorageFolders())
+	for i, n := range sectors {
+		path := filepath.Join(cmt.persistDir, "storageFolder"+string(rune('A'+existing+i)))
+		if err := os.MkdirAll(path, 0700); err != nil {
+			return nil, err
+		}
+		if err := cmt.cm.AddStorageFolder(path, modules.SectorSize*n); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// folder returns the metadata of the storage folder with the given path.
+func (cmt *contractManagerTester) folder(path string) (modules.StorageFolderMetadata, bool) {
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Path == path {
+			return sf, true
+		}
+	}
+	return modules.StorageFolderMetadata{}, false
+}
+
+// waitForFolderOperation waits until the storage folder operation of the
+// contract manager tester finished.
+func (cmt *contractManagerTester) waitForFolderOperation() (op modules.StorageFolderOperation, err error) {
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		var exists bool
+		op, exists = cmt.cm.StorageFolderOperation()
+		if !exists {
+			return errors.New("no operation")
+		}
+		if op.Running {
+			return errors.New("operation still running")
+		}
+		return nil
+	})
+	return
+}
+
+// TestPlanRebalance is a unit test for planRebalance.
+func TestPlanRebalance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ids := func(n int) []sectorID {
+		ids := make([]sectorID, n)
+		for i := range ids {
+			ids[i][0] = byte(i)
+		}
+		return ids
+	}
+	full := &rebalanceFolder{sf: &storageFolder{index: 0}, capacity: 128, used: 100, sectors: ids(100)}
+	empty := &rebalanceFolder{sf: &storageFolder{index: 1}, capacity: 64, used: 0}
+	half := &rebalanceFolder{sf: &storageFolder{index: 2}, capacity: 64, used: 33, sectors: ids(33)}
+
+	// 133 of 256 sectors are used, so the small folders should store 33
+	// sectors each and the full folder the remaining 67.
+	moves := planRebalance([]*rebalanceFolder{full, empty, half})
+	if len(moves) != 33 {
+		t.Fatal("expected 33 moves but got", len(moves))
+	}
+	for _, move := range moves {
+		if move.dest != empty.sf {
+			t.Fatal("sector moved to the wrong folder", move.dest.index)
+		}
+	}
+	if full.used != 67 || empty.used != 33 {
+		t.Fatal("unexpected usage after rebalance", full.used, empty.used)
+	}
+
+	// Balanced folders aren't touched.
+	if moves := planRebalance([]*rebalanceFolder{full, empty, half}); len(moves) != 0 {
+		t.Fatal("expected no moves but got", len(moves))
+	}
+}
+
+// TestMigrateStorageFolder tests migrating all sectors of a storage folder
+// into another one and removing the source folder afterwards.
+func TestMigrateStorageFolder(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := make([]crypto.Hash, 10)
+	datas := make([][]byte, len(roots))
+	for i := range roots {
+		roots[i], datas[i] = randSector()
+		if err := cmt.cm.AddSector(roots[i], datas[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newPaths, err := cmt.addTestStorageFolders(storageFolderGranularity, storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, _ := cmt.folder(paths[0])
+	dest, _ := cmt.folder(newPaths[1])
+
+	// Invalid migrations are rejected.
+	if err := cmt.cm.MigrateStorageFolder(source.Index, source.Index, false, 0); !errors.Is(err, errMigrateToSameFolder) {
+		t.Fatal("expected errMigrateToSameFolder but got", err)
+	}
+	if err := cmt.cm.MigrateStorageFolder(source.Index, 1000, false, 0); !errors.Is(err, errStorageFolderNotFound) {
+		t.Fatal("expected errStorageFolderNotFound but got", err)
+	}
+
+	err = cmt.cm.MigrateStorageFolder(source.Index, dest.Index, true, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op, err := cmt.waitForFolderOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Type != modules.StorageFolderOperationMigrate || op.SectorsTotal != 10 || op.SectorsMoved != 10 || op.SectorsFailed != 0 || op.Error != "" {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+
+	// The source folder was removed and the sectors are in the destination.
+	if _, exists := cmt.folder(paths[0]); exists {
+		t.Fatal("source folder wasn't removed")
+	}
+	if sf, _ := cmt.folder(newPaths[1]); sf.Capacity-sf.CapacityRemaining != 10*modules.SectorSize {
+		t.Fatal("sectors weren't moved into the destination")
+	}
+	for i, root := range roots {
+		data, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, datas[i]) {
+			t.Fatal("migrated sector has the wrong data")
+		}
+	}
+}
+
+// TestRebalanceStorageFolders tests evening out the free space of the
+// storage folders.
+func TestRebalanceStorageFolders(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newPaths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmt.cm.RebalanceStorageFolders(0); err != nil {
+		t.Fatal(err)
+	}
+	op, err := cmt.waitForFolderOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op.Type != modules.StorageFolderOperationRebalance || op.SectorsMoved != 10 || op.Error != "" {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+	for _, path := range append(paths, newPaths...) {
+		if sf, _ := cmt.folder(path); sf.Capacity-sf.CapacityRemaining != 10*modules.SectorSize {
+			t.Fatal("folders weren't balanced", sf.Capacity-sf.CapacityRemaining)
+		}
+	}
+}
+
+// TestCancelStorageFolderOperation tests throttling and cancelling a storage
+// folder operation.
+func TestCancelStorageFolderOperation(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	if err := cmt.cm.CancelStorageFolderOperation(); !errors.Is(err, modules.ErrNoStorageFolderOperation) {
+		t.Fatal("expected ErrNoStorageFolderOperation but got", err)
+	}
+
+	paths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newPaths, err := cmt.addTestStorageFolders(storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source, _ := cmt.folder(paths[0])
+	dest, _ := cmt.folder(newPaths[0])
+
+	// Migrate at a rate of 2 sectors per second.
+	err = cmt.cm.MigrateStorageFolder(source.Index, dest.Index, true, 2*modules.SectorSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.RebalanceStorageFolders(0); !errors.Is(err, modules.ErrStorageFolderOperationRunning) {
+		t.Fatal("expected ErrStorageFolderOperationRunning but got", err)
+	}
+	time.Sleep(time.Second)
+	if err := cmt.cm.CancelStorageFolderOperation(); err != nil {
+		t.Fatal(err)
+	}
+	op, err := cmt.waitForFolderOperation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !op.Cancelled || op.SectorsMoved == 0 || op.SectorsMoved >= op.SectorsTotal {
+		t.Fatalf("unexpected operation %+v", op)
+	}
+
+	// The source folder wasn't removed and can receive sectors again.
+	if _, exists := cmt.folder(paths[0]); !exists {
+		t.Fatal("source folder was removed")
+	}
+	if err := cmt.cm.CancelStorageFolderOperation(); !errors.Is(err, modules.ErrNoStorageFolderOperation) {
+		t.Fatal("expected ErrNoStorageFolderOperation but got", err)
+	}
+	if err := cmt.cm.RebalanceStorageFolders(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cmt.waitForFolderOperation(); err != nil {
+		t.Fatal(err)
+	}
+}
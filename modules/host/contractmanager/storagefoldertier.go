@@ -0,0 +1,216 @@
+package contractmanager
+
+// storagefoldertier.go moves sectors between the fast and the slow storage
+// folder tier. Every read of a sector is counted and the counts decay by half
+// on every rebalance, so that they reflect how often a sector was read
+// recently. Sectors on the slow tier that are read often are promoted to the
+// fast tier. If the fast tier runs out of room for promotions, the sectors on
+// the fast tier that haven't been read recently are demoted to the slow tier
+// first. Moves use the same WAL-safe machinery as emptying a storage folder.
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.thebigfile.com/bigd/modules"
+)
+
+type (
+	// sectorAccessTracker counts the reads of sectors.
+	sectorAccessTracker struct {
+		reads map[sectorID]uint64
+		mu    sync.Mutex
+	}
+
+	// storageFolderTierUpdate is a WAL entry that sets the tier of a storage
+	// folder.
+	storageFolderTierUpdate struct {
+		Index uint16
+		Tier  modules.StorageFolderTier
+	}
+
+	// tierCandidate is a sector that is considered for a move between tiers.
+	tierCandidate struct {
+		id    sectorID
+		from  uint16
+		reads uint64
+	}
+)
+
+// newSectorAccessTracker creates a new sectorAccessTracker.
+func newSectorAccessTracker() *sectorAccessTracker {
+	return &sectorAccessTracker{
+		reads: make(map[sectorID]uint64),
+	}
+}
+
+// managedRecordRead records a read of the sector with the given id.
+func (sat *sectorAccessTracker) managedRecordRead(id sectorID) {
+	sat.mu.Lock()
+	defer sat.mu.Unlock()
+	sat.reads[id]++
+}
+
+// managedDecay returns the current read counts and halves them afterwards.
+// Sectors without reads are dropped from the tracker.
+func (sat *sectorAccessTracker) managedDecay() map[sectorID]uint64 {
+	sat.mu.Lock()
+	defer sat.mu.Unlock()
+	reads := make(map[sectorID]uint64, len(sat.reads))
+	for id, n := range sat.reads {
+		reads[id] = n
+		if n/2 == 0 {
+			delete(sat.reads, id)
+		} else {
+			sat.reads[id] = n / 2
+		}
+	}
+	return reads
+}
+
+// storageFolderTier returns the tier of a storage folder, defaulting to the
+// slow tier for storage folders that were added before tiers existed.
+func storageFolderTier(tier modules.StorageFolderTier) modules.StorageFolderTier {
+	if tier == "" {
+		return modules.StorageFolderTierSlow
+	}
+	return tier
+}
+
+// commitStorageFolderTierUpdate sets the tier of a storage folder.
+func (wal *writeAheadLog) commitStorageFolderTierUpdate(sftu storageFolderTierUpdate) {
+	wal.cm.sectorMu.Lock()
+	defer wal.cm.sectorMu.Unlock()
+	sf, exists := wal.cm.storageFolders[sftu.Index]
+	if !exists {
+		wal.cm.log.Printf("Error: unable to set the tier of unknown storage folder %v\n", sftu.Index)
+		return
+	}
+	sf.tier = storageFolderTier(sftu.Tier)
+}
+
+// SetStorageFolderTier sets the tier of a storage folder. Sectors are moved
+// between the tiers in the background.
+func (cm *ContractManager) SetStorageFolderTier(index uint16, tier modules.StorageFolderTier) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	if err := tier.Validate(); err != nil {
+		return err
+	}
+
+	cm.sectorMu.Lock()
+	_, exists := cm.storageFolders[index]
+	cm.sectorMu.Unlock()
+	if !exists {
+		return errStorageFolderNotFound
+	}
+
+	// Submit the tier update to the WAL and wait until it is synced.
+	cm.wal.mu.Lock()
+	cm.wal.appendChange(stateChange{
+		StorageFolderTierUpdates: []storageFolderTierUpdate{{
+			Index: index,
+			Tier:  tier,
+		}},
+	})
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	<-syncChan
+	return nil
+}
+
+// managedRebalanceTiers promotes frequently read sectors to the fast tier and
+// demotes sectors that aren't read anymore to make room for them.
+func (cm *ContractManager) managedRebalanceTiers() {
+	reads := cm.staticSectorAccess.managedDecay()
+
+	// Split the available storage folders into tiers.
+	var fast, slow []*storageFolder
+	var fastFree uint64
+	cm.wal.mu.Lock()
+	sfs := cm.availableStorageFolders()
+	cm.wal.mu.Unlock()
+	cm.sectorMu.Lock()
+	for _, sf := range sfs {
+		if sf.tier == modules.StorageFolderTierFast {
+			fast = append(fast, sf)
+			fastFree += uint64(len(sf.usage))*storageFolderGranularity - sf.sectors
+		} else {
+			slow = append(slow, sf)
+		}
+	}
+	if len(fast) == 0 || len(slow) == 0 {
+		cm.sectorMu.Unlock()
+		return
+	}
+
+	// Find the hot sectors on the slow tier and the cold sectors on the fast
+	// tier.
+	var hot, cold []tierCandidate
+	for id, sl := range cm.sectorLocations {
+		sf, exists := cm.storageFolders[sl.storageFolder]
+		if !exists || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+			continue
+		}
+		n := reads[id]
+		if sf.tier == modules.StorageFolderTierFast && n == 0 {
+			cold = append(cold, tierCandidate{id: id, from: sl.storageFolder})
+		} else if sf.tier != modules.StorageFolderTierFast && n >= tierPromotionThreshold {
+			hot = append(hot, tierCandidate{id: id, from: sl.storageFolder, reads: n})
+		}
+	}
+	cm.sectorMu.Unlock()
+
+	// Promote the hottest sectors first.
+	sort.Slice(hot, func(i, j int) bool {
+		return hot[i].reads > hot[j].reads
+	})
+	if len(hot) > maxTierMovesPerRebalance {
+		hot = hot[:maxTierMovesPerRebalance]
+	}
+
+	// Demote cold sectors if the fast tier doesn't have enough room for the
+	// promotions.
+	for i := 0; i < len(cold) && uint64(len(hot)) > fastFree; i++ {
+		err := cm.wal.managedMoveSectorToFolders(cold[i].id, cold[i].from, append([]*storageFolder(nil), slow...))
+		if err != nil {
+			cm.log.Println("Unable to demote sector to the slow tier:", err)
+			break
+		}
+		fastFree++
+	}
+	if uint64(len(hot)) > fastFree {
+		hot = hot[:fastFree]
+	}
+	for _, c := range hot {
+		err := cm.wal.managedMoveSectorToFolders(c.id, c.from, append([]*storageFolder(nil), fast...))
+		if err != nil {
+			cm.log.Println("Unable to promote sector to the fast tier:", err)
+			break
+		}
+	}
+}
+
+// threadedRebalanceTiers periodically moves sectors between the storage
+// folder tiers.
+func (cm *ContractManager) threadedRebalanceTiers() {
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(tierRebalanceInterval):
+		}
+		func() {
+			if err := cm.tg.Add(); err != nil {
+				return
+			}
+			defer cm.tg.Done()
+			cm.managedRebalanceTiers()
+		}()
+	}
+}
@@ -0,0 +1,135 @@
+package contractmanager
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestSectorAccessTrackerDecay checks that the read counts of the access
+// tracker are halved on every decay.
+func TestSectorAccessTrackerDecay(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	sat := newSectorAccessTracker()
+	var id1, id2 sectorID
+	id2[0] = 1
+	for i := 0; i < 4; i++ {
+		sat.managedRecordRead(id1)
+	}
+	sat.managedRecordRead(id2)
+
+	reads := sat.managedDecay()
+	if reads[id1] != 4 || reads[id2] != 1 {
+		t.Fatal("unexpected reads", reads)
+	}
+	reads = sat.managedDecay()
+	if reads[id1] != 2 || reads[id2] != 0 {
+		t.Fatal("unexpected reads after decay", reads)
+	}
+	if _, exists := sat.reads[id2]; exists {
+		t.Fatal("sector without reads wasn't dropped")
+	}
+}
+
+// TestStorageFolderTiers checks that the tier of a storage folder is persisted
+// and that frequently read sectors are promoted to the fast tier.
+func TestStorageFolderTiers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add two storage folders.
+	slowDir := filepath.Join(cmt.persistDir, "slow")
+	fastDir := filepath.Join(cmt.persistDir, "fast")
+	for _, dir := range []string{slowDir, fastDir} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := cmt.cm.AddStorageFolder(dir, modules.SectorSize*storageFolderGranularity); err != nil {
+			t.Fatal(err)
+		}
+	}
+	folder := func(path string) modules.StorageFolderMetadata {
+		for _, sf := range cmt.cm.StorageFolders() {
+			if sf.Path == path {
+				return sf
+			}
+		}
+		t.Fatal("storage folder not found", path)
+		return modules.StorageFolderMetadata{}
+	}
+	if tier := folder(fastDir).Tier; tier != modules.StorageFolderTierSlow {
+		t.Fatal("expected new folder to be on the slow tier but was", tier)
+	}
+
+	// Invalid tiers are rejected.
+	if err := cmt.cm.SetStorageFolderTier(folder(fastDir).Index, "medium"); !errors.Is(err, modules.ErrUnknownStorageFolderTier) {
+		t.Fatal("expected ErrUnknownStorageFolderTier but got", err)
+	}
+	if err := cmt.cm.SetStorageFolderTier(folder(fastDir).Index, modules.StorageFolderTierFast); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tier survives a restart.
+	if err := cmt.cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tier := folder(fastDir).Tier; tier != modules.StorageFolderTierFast {
+		t.Fatal("expected fast tier after restart but got", tier)
+	}
+
+	// New sectors are stored on the slow tier.
+	root, data := randSector()
+	if err := cmt.cm.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+	if sf := folder(slowDir); sf.Capacity-sf.CapacityRemaining != modules.SectorSize {
+		t.Fatal("expected sector to be stored on the slow tier")
+	}
+
+	// Read the sector until it is promoted to the fast tier.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		for i := uint64(0); i < tierPromotionThreshold; i++ {
+			if _, err := cmt.cm.ReadSector(root); err != nil {
+				return err
+			}
+		}
+		if sf := folder(fastDir); sf.Capacity-sf.CapacityRemaining != modules.SectorSize {
+			return errors.New("sector wasn't promoted")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf := folder(slowDir); sf.CapacityRemaining != sf.Capacity {
+		t.Fatal("sector wasn't removed from the slow tier")
+	}
+	sector, err := cmt.cm.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(sector, data) {
+		t.Fatal("promoted sector has the wrong data")
+	}
+}
@@ -54,6 +54,9 @@ type (
 		StorageFolderExtensions           []storageFolderExtension
 		StorageFolderRemovals             []storageFolderRemoval
 		StorageFolderReductions           []storageFolderReduction
+		StorageFolderTierUpdates          []storageFolderTierUpdate
+		StorageRedundancyUpdates          []storageRedundancyUpdate
+		TemporarySectorUpdates            []savedTemporarySector
 		UnfinishedStorageFolderAdditions  []savedStorageFolder
 		UnfinishedStorageFolderExtensions []unfinishedStorageFolderExtension
 
@@ -187,6 +190,21 @@ func (wal *writeAheadLog) commitChange(sc stateChange) {
 			wal.commitStorageFolderRemoval(sfr)
 		}
 	}
+	for _, sftu := range sc.StorageFolderTierUpdates {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitStorageFolderTierUpdate(sftu)
+		}
+	}
+	for _, sru := range sc.StorageRedundancyUpdates {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitStorageRedundancyUpdate(sru)
+		}
+	}
+	for _, tsu := range sc.TemporarySectorUpdates {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitTemporarySectorUpdate(tsu)
+		}
+	}
 	for _, su := range sc.SectorUpdates {
 		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
 			wal.commitUpdateSector(su)
@@ -145,6 +145,15 @@ func (wal *writeAheadLog) syncResources() {
 		for _, sfr := range sc.StorageFolderRemovals {
 			wal.commitStorageFolderRemoval(sfr)
 		}
+		for _, sftu := range sc.StorageFolderTierUpdates {
+			wal.commitStorageFolderTierUpdate(sftu)
+		}
+		for _, sru := range sc.StorageRedundancyUpdates {
+			wal.commitStorageRedundancyUpdate(sru)
+		}
+		for _, tsu := range sc.TemporarySectorUpdates {
+			wal.commitTemporarySectorUpdate(tsu)
+		}
 
 		// TODO: Virtual sector handling here.
 	}
@@ -15,7 +15,7 @@ import (
 // file, based on the number of '\n' characters. countFileLines will load the
 // file into memory using ioutil.ReadAll.
 //
-// countFileLines will ignore all lines with the string 'DEBUG' in it.
+// countFileLines will ignore all lines with the debug level.
 func countFileLines(filepath string) (uint64, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -25,7 +25,7 @@ func countFileLines(filepath string) (uint64, error) {
 	lines := uint64(0)
 	for scanner.Scan() {
 		line := scanner.Text()
-		if !strings.Contains(line, "[DEBUG]") {
+		if !strings.Contains(line, " level=debug") {
 			lines++
 		}
 	}
@@ -64,6 +64,7 @@ package host
 // TODO: update_test.go has commented out tests.
 
 import (
+	"encoding/hex"
 	"fmt"
 	"net"
 	"os"
@@ -196,10 +197,22 @@ type Host struct {
 	// of such conditions are congestion, load, liquidity, etc.
 	staticPriceTables *hostPrices
 
+	// The state of the pricing engine, which adjusts the host's prices if
+	// enabled.
+	pricingEngine pricingEngineState
+
 	// Fields related to RHP3 bandwidhth.
 	atomicStreamUpload   uint64
 	atomicStreamDownload uint64
 
+	// staticBandwidthShaper shapes the bandwidth of the RPCs per class and
+	// per renter.
+	staticBandwidthShaper *bandwidthShaper
+
+	// staticTemporarySectorMu serializes adding temporary sectors to make
+	// sure the host doesn't exceed its temporary storage limit.
+	staticTemporarySectorMu sync.Mutex
+
 	// Misc state.
 	db            *persist.BoltDatabase
 	listener      net.Listener
@@ -334,6 +347,7 @@ func (h *Host) managedUpdatePriceTable() {
 	minRecommended, maxRecommended := h.tpool.FeeEstimation()
 	h.mu.Lock()
 	hes := h.externalSettings(maxRecommended) // use externalSettings to avoid another fee estimation
+	temporaryStoragePrice := h.settings.MinTemporaryStoragePrice
 	h.mu.Unlock()
 	priceTable := modules.RPCPriceTable{
 		// TODO: hardcoded cost should be updated to use a better value.
@@ -357,6 +371,9 @@ func (h *Host) managedUpdatePriceTable() {
 		WriteLengthCost: types.NewCurrency64(1),
 		WriteStoreCost:  hes.StoragePrice,
 
+		// Temporary storage costs.
+		TemporaryStoreCost: temporaryStoragePrice,
+
 		// Init costs.
 		InitBaseCost: hes.BaseRPCPrice,
 
@@ -458,6 +475,7 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 			},
 		},
 		staticRegistrySubscriptions: newRegistrySubscriptions(),
+		staticBandwidthShaper:       newBandwidthShaper(),
 		persistDir:                  persistDir,
 	}
 
@@ -520,6 +538,9 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 		}
 	})
 
+	// Apply the loaded bandwidth limits.
+	h.staticBandwidthShaper.managedSetLimits(h.settings.BandwidthLimits)
+
 	// Load the registry.
 	err = h.managedInitRegistry()
 	if err != nil {
@@ -563,6 +584,9 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	// Ensure the expired RPC tables get pruned as to not leak memory
 	go h.threadedPruneExpiredPriceTables()
 
+	// Periodically update the prices of the pricing engine
+	go h.threadedUpdateDynamicPrices()
+
 	return h, nil
 }
 
@@ -655,6 +679,43 @@ func (h *Host) FinancialMetrics() modules.HostFinancialMetrics {
 	return h.financialMetrics
 }
 
+// EphemeralAccounts returns information about the ephemeral accounts on the
+// host.
+func (h *Host) EphemeralAccounts() []modules.HostEphemeralAccount {
+	his := h.managedInternalSettings()
+	return h.staticAccountManager.callAccounts(his.EphemeralAccountExpiry)
+}
+
+// EphemeralAccountsSummary returns aggregate information about the ephemeral
+// accounts on the host.
+func (h *Host) EphemeralAccountsSummary() modules.HostEphemeralAccountsSummary {
+	his := h.managedInternalSettings()
+	summary := h.staticAccountManager.callAccountsSummary()
+	summary.MaxBalance = his.MaxEphemeralAccountBalance
+	summary.MaxRisk = his.MaxEphemeralAccountRisk
+	return summary
+}
+
+// ExpireEphemeralAccount expires the ephemeral account with the given id
+// immediately, the host keeps its balance.
+func (h *Host) ExpireEphemeralAccount(id modules.AccountID) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	return h.staticAccountManager.managedExpireAccount(id)
+}
+
+// SetEphemeralAccountMaxBalance caps the balance of the ephemeral account with
+// the given id. A max balance of 0 removes the cap.
+func (h *Host) SetEphemeralAccountMaxBalance(id modules.AccountID, maxBalance types.Currency) error {
+	if err := h.tg.Add(); err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	return h.staticAccountManager.managedSetAccountMaxBalance(id, maxBalance)
+}
+
 // PublicKey returns the public key of the host that is used to facilitate
 // relationships between the host and renter.
 func (h *Host) PublicKey() types.SiaPublicKey {
@@ -687,6 +748,13 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 		}
 	}
 
+	if settings.PricingEngine.Enabled {
+		err := settings.PricingEngine.Validate(settings)
+		if err != nil {
+			return errors.AddContext(err, "internal settings not updated, invalid pricing engine settings")
+		}
+	}
+
 	if settings.NetAddress != "" {
 		err := settings.NetAddress.IsValid()
 		if err != nil {
@@ -727,6 +795,7 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 
 	h.settings = settings
 	h.revisionNumber++
+	h.staticBandwidthShaper.managedSetLimits(settings.BandwidthLimits)
 
 	// The locked storage collateral was altered, we potentially want to
 	// unregister the insufficient collateral budget alert
@@ -805,6 +874,62 @@ func (h *Host) RegistryUpdate(rv modules.SignedRegistryValue, pubKey types.SiaPu
 	return existingSRV, nil
 }
 
+// PruneRegistry deletes the expired entries from the registry.
+func (h *Host) PruneRegistry() (uint64, error) {
+	if err := h.tg.Add(); err != nil {
+		return 0, err
+	}
+	defer h.tg.Done()
+	return h.staticRegistry.Prune(h.BlockHeight())
+}
+
+// RegistryEntry returns the registry entry with the given id.
+func (h *Host) RegistryEntry(eid modules.RegistryEntryID) (modules.HostRegistryEntry, bool) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostRegistryEntry{}, false
+	}
+	defer h.tg.Done()
+	spk, srv, expiry, found := h.staticRegistry.GetWithExpiry(eid)
+	if !found {
+		return modules.HostRegistryEntry{}, false
+	}
+	return modules.HostRegistryEntry{
+		EntryID:   eid,
+		PublicKey: spk,
+		Tweak:     srv.Tweak,
+		Data:      hex.EncodeToString(srv.Data),
+		Revision:  srv.Revision,
+		Signature: hex.EncodeToString(srv.Signature[:]),
+		Type:      srv.Type,
+		Expiry:    expiry,
+	}, true
+}
+
+// RegistryExport writes the entries of the registry to a new file at the
+// given path.
+func (h *Host) RegistryExport(path string) (uint64, error) {
+	if err := h.tg.Add(); err != nil {
+		return 0, err
+	}
+	defer h.tg.Done()
+	return h.staticRegistry.Export(path)
+}
+
+// RegistryImport adds the entries of a registry export to the registry.
+// Expired entries are skipped.
+func (h *Host) RegistryImport(path string) (modules.HostRegistryImport, error) {
+	if err := h.tg.Add(); err != nil {
+		return modules.HostRegistryImport{}, err
+	}
+	defer h.tg.Done()
+	return h.staticRegistry.Import(path, h.BlockHeight())
+}
+
+// RegistryStats returns usage statistics of the registry.
+func (h *Host) RegistryStats() modules.HostRegistryStats {
+	return h.staticRegistry.Stats(h.BlockHeight())
+}
+
 // managedInitRegistry initializes the host's registry on startup. If the
 // registry on disk is larger than the expected size in the settings, it updates
 // the settings to allow the host to boot. Since a registry should not be
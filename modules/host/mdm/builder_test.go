@@ -96,6 +96,16 @@ func (tb *testProgramBuilder) AddReadSectorInstruction(length, offset uint64, me
 	tb.staticValues.AddReadSectorInstruction(length)
 }
 
+// AddReadSectorRangeInstruction adds a readsectorrange instruction to the
+// builder, keeping track of running values.
+func (tb *testProgramBuilder) AddReadSectorRangeInstruction(length, offset uint64, merkleRoots []crypto.Hash, merkleProof bool) {
+	err := tb.staticPB.AddReadSectorRangeInstruction(length, offset, merkleRoots, merkleProof)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddReadSectorRangeInstruction(length, uint64(len(merkleRoots)))
+}
+
 // AddRevisionInstruction adds a revision instruction to the builder, keeping
 // track of running values.
 func (tb *testProgramBuilder) AddRevisionInstruction() {
@@ -110,6 +120,26 @@ func (tb *testProgramBuilder) AddSwapSectorInstruction(sector1Idx, sector2Idx ui
 	tb.staticValues.AddSwapSectorInstruction()
 }
 
+// AddStoreSectorInstruction adds a StoreSector instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddStoreSectorInstruction(data []byte, duration types.BlockHeight) {
+	err := tb.staticPB.AddStoreSectorInstruction(data, duration)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddStoreSectorInstruction(data, duration)
+}
+
+// AddUpdateSectorInstruction adds an UpdateSector instruction to the builder,
+// keeping track of running values.
+func (tb *testProgramBuilder) AddUpdateSectorInstruction(offset uint64, data []byte, merkleProof bool) {
+	err := tb.staticPB.AddUpdateSectorInstruction(offset, data, merkleProof)
+	if err != nil {
+		panic(err)
+	}
+	tb.staticValues.AddUpdateSectorInstruction(data)
+}
+
 // AddUpdateRegistryInstruction adds an UpdateRegistry instruction to the
 // builder, keeping track of running values.
 func (tb *testProgramBuilder) AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv modules.SignedRegistryValue) {
@@ -0,0 +1,156 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// instructionReadSectorRange is an instruction which reads the same range from
+// multiple sectors specified by their merkle roots.
+type instructionReadSectorRange struct {
+	commonInstruction
+
+	lengthOffset   uint64
+	offsetOffset   uint64
+	numRootsOffset uint64
+	rootsOffset    uint64
+}
+
+// staticDecodeReadSectorRangeInstruction creates a new 'ReadSectorRange'
+// instruction from the provided generic instruction.
+func (p *program) staticDecodeReadSectorRangeInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierReadSectorRange {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierReadSectorRange, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIReadSectorRangeLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIReadSectorRangeLen, len(instruction.Args))
+	}
+	// Read args.
+	lengthOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	offsetOffset := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	numRootsOffset := binary.LittleEndian.Uint64(instruction.Args[16:24])
+	rootsOffset := binary.LittleEndian.Uint64(instruction.Args[24:32])
+
+	// Return instruction.
+	return &instructionReadSectorRange{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: instruction.Args[32] == 1,
+			staticState:       p.staticProgramState,
+		},
+		lengthOffset:   lengthOffset,
+		offsetOffset:   offsetOffset,
+		numRootsOffset: numRootsOffset,
+		rootsOffset:    rootsOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionReadSectorRange) Batch() bool {
+	return false
+}
+
+// Execute executes the 'ReadSectorRange' instruction. The output is the
+// concatenation of the requested range of every sector in the order of the
+// provided roots. The proof is the concatenation of the range proofs, which
+// all have the same length.
+func (i *instructionReadSectorRange) Execute(previousOutput output) (output, types.Currency) {
+	// Fetch the operands.
+	length, err := i.staticData.Uint64(i.lengthOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	offset, err := i.staticData.Uint64(i.offsetOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	numRoots, err := i.staticData.Uint64(i.numRootsOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	if err := readSectorRangeVerify(length, numRoots); err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Read the range from every sector.
+	var data []byte
+	var proof []crypto.Hash
+	for n := uint64(0); n < numRoots; n++ {
+		sectorRoot, err := i.staticData.Hash(i.rootsOffset + n*crypto.HashSize)
+		if err != nil {
+			return errOutput(err), types.ZeroCurrency
+		}
+		out, _ := executeReadSector(previousOutput, i.staticState, length, offset, sectorRoot, i.staticMerkleProof)
+		if out.Error != nil {
+			return errOutput(errors.AddContext(out.Error, fmt.Sprintf("failed to read sector %v", n))), types.ZeroCurrency
+		}
+		data = append(data, out.Output...)
+		proof = append(proof, out.Proof...)
+	}
+
+	// Return the output.
+	return output{
+		NewSize:       previousOutput.NewSize,       // size stays the same
+		NewMerkleRoot: previousOutput.NewMerkleRoot, // root stays the same
+		Output:        data,
+		Proof:         proof,
+	}, types.ZeroCurrency
+}
+
+// readSectorRangeVerify verifies the input to a ReadSectorRange instruction.
+func readSectorRangeVerify(length, numRoots uint64) error {
+	switch {
+	case numRoots == 0:
+		return errors.New("numRoots cannot be zero")
+	case length > modules.SectorSize:
+		return fmt.Errorf("length %v is larger than a sector", length)
+	case length*numRoots > modules.MDMReadSectorRangeMaxLength:
+		return fmt.Errorf("total length %v exceeds the maximum of %v", length*numRoots, modules.MDMReadSectorRangeMaxLength)
+	}
+	return nil
+}
+
+// Collateral is zero for the ReadSectorRange instruction.
+func (i *instructionReadSectorRange) Collateral() types.Currency {
+	return modules.MDMReadCollateral()
+}
+
+// Cost returns the cost of a ReadSectorRange instruction.
+func (i *instructionReadSectorRange) Cost() (executionCost, _ types.Currency, err error) {
+	var length, numRoots uint64
+	length, err = i.staticData.Uint64(i.lengthOffset)
+	if err != nil {
+		return
+	}
+	numRoots, err = i.staticData.Uint64(i.numRootsOffset)
+	if err != nil {
+		return
+	}
+	executionCost = modules.MDMReadSectorRangeCost(i.staticState.priceTable, length, numRoots)
+	return
+}
+
+// Memory returns the memory allocated by the 'ReadSectorRange' instruction
+// beyond the lifetime of the instruction.
+func (i *instructionReadSectorRange) Memory() uint64 {
+	return modules.MDMReadMemory()
+}
+
+// Time returns the execution time of a 'ReadSectorRange' instruction.
+func (i *instructionReadSectorRange) Time() (uint64, error) {
+	numRoots, err := i.staticData.Uint64(i.numRootsOffset)
+	if err != nil {
+		return 0, err
+	}
+	return modules.MDMReadSectorRangeTime(numRoots), nil
+}
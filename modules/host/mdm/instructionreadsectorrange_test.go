@@ -0,0 +1,113 @@
+package mdm
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestInstructionReadSectorRange tests executing a program with a single
+// ReadSectorRange instruction.
+func TestInstructionReadSectorRange(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a priceTable.
+	pt := newTestPriceTable()
+	// Prepare storage obligation.
+	so := host.newTestStorageObligation(true)
+	so.AddRandomSectors(initialContractSectors)
+	roots := append([]crypto.Hash{}, so.sectorRoots...)
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+
+	// Read a random range of segments from every sector.
+	numSegments := fastrand.Uint64n(modules.SectorSize/2/crypto.SegmentSize) + 1
+	offset := modules.SectorSize / 2
+	length := numSegments * crypto.SegmentSize
+
+	// Use a builder to build the program.
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddReadSectorRangeInstruction(length, offset, roots, true)
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+
+	// Execute it.
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Compute the expected output and proof.
+	proofStart := int(offset) / crypto.SegmentSize
+	proofEnd := int(offset+length) / crypto.SegmentSize
+	var expectedData []byte
+	var expectedProof []crypto.Hash
+	for _, root := range roots {
+		sectorData, err := host.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectedData = append(expectedData, sectorData[offset:][:length]...)
+		expectedProof = append(expectedProof, crypto.MerkleRangeProof(sectorData, proofStart, proofEnd)...)
+	}
+	err = outputs[0].assert(ics, imr, expectedProof, expectedData, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the proof of every sector.
+	proofLen := len(outputs[0].Proof) / len(roots)
+	for i, root := range roots {
+		data := outputs[0].Output[uint64(i)*length:][:length]
+		proof := outputs[0].Proof[i*proofLen:][:proofLen]
+		if !crypto.VerifyRangeProof(data, proof, proofStart, proofEnd, root) {
+			t.Fatal("failed to verify proof of sector", i)
+		}
+	}
+
+	// Reading a sector the host doesn't have should fail.
+	host = newCustomTestHost(false)
+	mdm = New(host)
+	defer mdm.Stop()
+	so = host.newTestStorageObligation(true)
+	so.AddRandomSectors(2)
+	roots = append(so.sectorRoots, crypto.Hash{1})
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddReadSectorRangeInstruction(length, offset, roots, false)
+	outputs, err = mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Error == nil {
+		t.Fatal("expected read of unknown sector to fail")
+	}
+}
+
+// TestReadSectorRangeVerify tests the input validation of the ReadSectorRange
+// instruction.
+func TestReadSectorRangeVerify(t *testing.T) {
+	tests := []struct {
+		length   uint64
+		numRoots uint64
+		valid    bool
+	}{
+		{modules.SectorSize, 1, true},
+		{modules.SectorSize, modules.MDMReadSectorRangeMaxLength / modules.SectorSize, true},
+		{modules.SectorSize, modules.MDMReadSectorRangeMaxLength/modules.SectorSize + 1, false},
+		{modules.SectorSize + 1, 1, false},
+		{crypto.SegmentSize, 0, false},
+	}
+	for i, test := range tests {
+		err := readSectorRangeVerify(test.length, test.numRoots)
+		if test.valid && err != nil {
+			t.Fatalf("%v: unexpected error: %v", i, err)
+		} else if !test.valid && err == nil {
+			t.Fatalf("%v: expected error", i)
+		}
+	}
+}
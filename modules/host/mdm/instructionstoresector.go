@@ -0,0 +1,115 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// instructionStoreSector is an instruction which stores a sector on the host
+// for a certain duration without adding it to a contract.
+type instructionStoreSector struct {
+	commonInstruction
+
+	dataOffset     uint64
+	durationOffset uint64
+}
+
+// staticDecodeStoreSectorInstruction creates a new 'StoreSector' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeStoreSectorInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierStoreSector {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierStoreSector, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIStoreSectorLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIStoreSectorLen, len(instruction.Args))
+	}
+	// Read args.
+	dataOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	durationOffset := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	return &instructionStoreSector{
+		commonInstruction: commonInstruction{
+			staticData:  p.staticData,
+			staticState: p.staticProgramState,
+		},
+		dataOffset:     dataOffset,
+		durationOffset: durationOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionStoreSector) Batch() bool {
+	return false
+}
+
+// Execute executes the 'StoreSector' instruction. The output is the merkle root
+// of the stored sector.
+func (i *instructionStoreSector) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the data.
+	duration, err := i.staticData.Uint64(i.durationOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	if duration == 0 {
+		return errOutput(errors.New("duration cannot be zero")), types.ZeroCurrency
+	}
+	sectorData, err := i.staticData.Bytes(i.dataOffset, modules.SectorSize)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Store the sector.
+	host, ok := i.staticState.host.(TemporarySectorHost)
+	if !ok {
+		return errOutput(errors.New("host doesn't support temporary sectors")), types.ZeroCurrency
+	}
+	sectorRoot := crypto.MerkleRoot(sectorData)
+	expiry := i.staticState.host.BlockHeight() + types.BlockHeight(duration)
+	err = host.AddTemporarySector(sectorRoot, sectorData, expiry)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	return output{
+		NewSize:       prevOutput.NewSize,       // size stays the same
+		NewMerkleRoot: prevOutput.NewMerkleRoot, // root stays the same
+		Output:        sectorRoot[:],
+	}, types.ZeroCurrency
+}
+
+// Collateral returns the collateral the host has to put up for this
+// instruction.
+func (i *instructionStoreSector) Collateral() types.Currency {
+	return modules.MDMStoreSectorCollateral()
+}
+
+// Cost returns the Cost of this `StoreSector` instruction.
+func (i *instructionStoreSector) Cost() (executionCost, storage types.Currency, err error) {
+	var duration uint64
+	duration, err = i.staticData.Uint64(i.durationOffset)
+	if err != nil {
+		return
+	}
+	executionCost, storage = modules.MDMStoreSectorCost(i.staticState.priceTable, types.BlockHeight(duration))
+	return
+}
+
+// Memory returns the memory allocated by the 'StoreSector' instruction beyond
+// the lifetime of the instruction.
+func (i *instructionStoreSector) Memory() uint64 {
+	return modules.MDMStoreSectorMemory()
+}
+
+// Time returns the execution time of a 'StoreSector' instruction.
+func (i *instructionStoreSector) Time() (uint64, error) {
+	return modules.MDMTimeStoreSector, nil
+}
@@ -0,0 +1,123 @@
+package mdm
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestInstructionStoreSector tests executing a program with a single
+// StoreSector instruction.
+func TestInstructionStoreSector(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Prepare a priceTable and an empty storage obligation. The instruction
+	// doesn't use the contract.
+	pt := newTestPriceTable()
+	so := host.newTestStorageObligation(true)
+	duration := types.BlockHeight(fastrand.Uint64n(100) + 1)
+
+	// Use a builder to build the program.
+	data := fastrand.Bytes(int(modules.SectorSize))
+	root := crypto.MerkleRoot(data)
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddStoreSectorInstruction(data, duration)
+
+	// The program should be readonly and not require a snapshot.
+	program, _ := tb.Program()
+	if !program.ReadOnly() {
+		t.Fatal("program should be readonly")
+	}
+	if program.RequiresSnapshot() {
+		t.Fatal("program shouldn't require a snapshot")
+	}
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+	bh := host.blockHeight
+
+	// Execute it.
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert the output.
+	err = outputs[0].assert(ics, imr, []crypto.Hash{}, root[:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host should have the sector with the right expiry.
+	stored, err := host.ReadSector(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stored, data) {
+		t.Fatal("stored data doesn't match")
+	}
+	// The TestHost increments its height on every call so we check for a
+	// range.
+	if expiry := host.temporarySectors[root]; expiry <= bh+duration || expiry > host.blockHeight+duration {
+		t.Fatalf("expected expiry in (%v, %v] but was %v", bh+duration, host.blockHeight+duration, expiry)
+	}
+}
+
+// TestInstructionStoreSectorFailedProgram tests that the storage cost of a
+// StoreSector instruction isn't refunded when a later instruction of the
+// program fails since the sector is stored anyway.
+func TestInstructionStoreSectorFailedProgram(t *testing.T) {
+	host := newTestHost()
+	host.generateSectors = false
+	mdm := New(host)
+	defer mdm.Stop()
+
+	pt := newTestPriceTable()
+	pt.TemporaryStoreCost = types.SiacoinPrecision
+	so := host.newTestStorageObligation(true)
+	duration := types.BlockHeight(fastrand.Uint64n(100) + 1)
+
+	// Store a sector and then read a sector the host doesn't have.
+	data := fastrand.Bytes(int(modules.SectorSize))
+	root := crypto.MerkleRoot(data)
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddStoreSectorInstruction(data, duration)
+	tb.AddReadSectorInstruction(modules.SectorSize, 0, crypto.Hash{1}, false)
+
+	// Execute it.
+	program, programData := tb.Program()
+	values := tb.Cost()
+	_, _, collateral, _ := values.Cost()
+	_, outputChan, err := mdm.ExecuteProgram(context.Background(), pt, program, values.Budget(false), collateral, so, duration, uint64(len(programData)), bytes.NewReader(programData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var outputs []Output
+	for output := range outputChan {
+		outputs = append(outputs, output)
+	}
+	if len(outputs) != 2 {
+		t.Fatalf("expected 2 outputs but got %v", len(outputs))
+	}
+	if outputs[0].Error != nil {
+		t.Fatal(outputs[0].Error)
+	}
+	if outputs[1].Error == nil {
+		t.Fatal("expected the read to fail")
+	}
+
+	// The sector was stored but the storage isn't refunded.
+	if _, err := host.ReadSector(root); err != nil {
+		t.Fatal(err)
+	}
+	if !outputs[1].FailureRefund.IsZero() {
+		t.Fatal("storage cost shouldn't be refunded", outputs[1].FailureRefund)
+	}
+}
@@ -0,0 +1,154 @@
+package mdm
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// instructionUpdateSector is an instruction that overwrites part of a sector
+// of a file contract.
+type instructionUpdateSector struct {
+	commonInstruction
+
+	offsetOffset uint64
+	lengthOffset uint64
+	dataOffset   uint64
+}
+
+// staticDecodeUpdateSectorInstruction creates a new 'UpdateSector' instruction
+// from the provided generic instruction.
+func (p *program) staticDecodeUpdateSectorInstruction(instruction modules.Instruction) (instruction, error) {
+	// Check specifier.
+	if instruction.Specifier != modules.SpecifierUpdateSector {
+		return nil, fmt.Errorf("expected specifier %v but got %v",
+			modules.SpecifierUpdateSector, instruction.Specifier)
+	}
+	// Check args.
+	if len(instruction.Args) != modules.RPCIUpdateSectorLen {
+		return nil, fmt.Errorf("expected instruction to have len %v but was %v",
+			modules.RPCIUpdateSectorLen, len(instruction.Args))
+	}
+	// Read args.
+	offsetOffset := binary.LittleEndian.Uint64(instruction.Args[:8])
+	lengthOffset := binary.LittleEndian.Uint64(instruction.Args[8:16])
+	dataOffset := binary.LittleEndian.Uint64(instruction.Args[16:24])
+	return &instructionUpdateSector{
+		commonInstruction: commonInstruction{
+			staticData:        p.staticData,
+			staticMerkleProof: instruction.Args[24] == 1,
+			staticState:       p.staticProgramState,
+		},
+		offsetOffset: offsetOffset,
+		lengthOffset: lengthOffset,
+		dataOffset:   dataOffset,
+	}, nil
+}
+
+// Batch declares whether or not this instruction can be batched together with
+// the previous instruction.
+func (i instructionUpdateSector) Batch() bool {
+	return false
+}
+
+// Execute executes the 'UpdateSector' instruction.
+func (i *instructionUpdateSector) Execute(prevOutput output) (output, types.Currency) {
+	// Fetch the operands.
+	offset, err := i.staticData.Uint64(i.offsetOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	length, err := i.staticData.Uint64(i.lengthOffset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Translate the contract offset and validate the request before fetching
+	// the data.
+	ps := i.staticState
+	relOffset, secIdx, err := ps.sectors.translateOffset(offset)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	switch {
+	case length == 0:
+		err = errors.New("length cannot be zero")
+	case relOffset+length > modules.SectorSize:
+		err = fmt.Errorf("update crosses sector boundary %v + %v = %v > %v", relOffset, length, relOffset+length, modules.SectorSize)
+	}
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	data, err := i.staticData.Bytes(i.dataOffset, length)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// Patch a copy of the sector.
+	oldRoot := ps.sectors.merkleRoots[secIdx]
+	oldSector, err := ps.sectors.readSector(ps.host, oldRoot)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+	newSector := make([]byte, len(oldSector))
+	copy(newSector, oldSector)
+	copy(newSector[relOffset:], data)
+	newMerkleRoot, err := ps.sectors.updateSector(secIdx, newSector)
+	if err != nil {
+		return errOutput(err), types.ZeroCurrency
+	}
+
+	// If no proof was requested we are done.
+	if !i.staticMerkleProof {
+		return output{
+			NewSize:       prevOutput.NewSize,
+			NewMerkleRoot: newMerkleRoot,
+		}, types.ZeroCurrency
+	}
+
+	// Create the proof for the modified sector and return its old leaf hash
+	// as the data. The renter verifies the proof against the old contract
+	// root using the old leaf hash and against the new root using the root
+	// of the updated sector.
+	ranges := []crypto.ProofRange{{
+		Start: secIdx,
+		End:   secIdx + 1,
+	}}
+	newRoots := ps.sectors.merkleRoots
+	proof := crypto.MerkleDiffProof(ranges, uint64(len(newRoots)), nil, newRoots)
+
+	return output{
+		NewSize:       prevOutput.NewSize,
+		NewMerkleRoot: newMerkleRoot,
+		Output:        encoding.Marshal([]crypto.Hash{oldRoot}),
+		Proof:         proof,
+	}, types.ZeroCurrency
+}
+
+// Collateral returns the collateral the host has to put up for this
+// instruction.
+func (i *instructionUpdateSector) Collateral() types.Currency {
+	return modules.MDMUpdateSectorCollateral()
+}
+
+// Cost returns the Cost of this `UpdateSector` instruction.
+func (i *instructionUpdateSector) Cost() (executionCost, storage types.Currency, err error) {
+	executionCost = modules.MDMUpdateSectorCost(i.staticState.priceTable)
+	return
+}
+
+// Memory returns the memory allocated by the 'UpdateSector' instruction beyond
+// the lifetime of the instruction.
+func (i *instructionUpdateSector) Memory() uint64 {
+	return modules.MDMUpdateSectorMemory()
+}
+
+// Time returns the execution time of an 'UpdateSector' instruction.
+func (i *instructionUpdateSector) Time() (uint64, error) {
+	return modules.MDMTimeUpdateSector, nil
+}
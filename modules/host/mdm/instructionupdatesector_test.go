@@ -0,0 +1,108 @@
+package mdm
+
+import (
+	"bytes"
+	"testing"
+
+	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestInstructionUpdateSector tests executing a program with a single
+// UpdateSector instruction.
+func TestInstructionUpdateSector(t *testing.T) {
+	host := newTestHost()
+	mdm := New(host)
+	defer mdm.Stop()
+
+	// Create a storage obligation with some random sectors.
+	numSectors := uint64(5)
+	so := host.newTestStorageObligation(true)
+	so.AddRandomSectors(int(numSectors))
+	for _, root := range so.sectorRoots {
+		so.sectorMap[root] = host.sectors[root]
+	}
+
+	// Prepare a priceTable and duration.
+	pt := newTestPriceTable()
+	duration := types.BlockHeight(fastrand.Uint64n(5))
+
+	// Update a random part of a random sector.
+	idx := fastrand.Uint64n(numSectors)
+	relOffset := fastrand.Uint64n(modules.SectorSize)
+	length := fastrand.Uint64n(modules.SectorSize-relOffset) + 1
+	offset := idx*modules.SectorSize + relOffset
+	data := fastrand.Bytes(int(length))
+
+	ics := so.ContractSize()
+	imr := so.MerkleRoot()
+	oldRoots := append([]crypto.Hash{}, so.sectorRoots...)
+
+	// Use a builder to build the program.
+	tb := newTestProgramBuilder(pt, duration)
+	tb.AddUpdateSectorInstruction(offset, data, true)
+
+	// Execute it.
+	outputs, err := mdm.ExecuteProgramWithBuilder(tb, so, duration, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := outputs[0]
+
+	// Compute the expected sector and roots.
+	expectedSector := append([]byte{}, host.sectors[oldRoots[idx]]...)
+	copy(expectedSector[relOffset:], data)
+	newRoot := crypto.MerkleRoot(expectedSector)
+	newRoots := append([]crypto.Hash{}, oldRoots...)
+	newRoots[idx] = newRoot
+	nmr := cachedMerkleRoot(newRoots)
+
+	// Compute the expected proof.
+	ranges := []crypto.ProofRange{{Start: idx, End: idx + 1}}
+	expectedProof := crypto.MerkleDiffProof(ranges, numSectors, nil, oldRoots)
+	expectedOutput := encoding.Marshal([]crypto.Hash{oldRoots[idx]})
+
+	// Assert the output.
+	err = output.assert(ics, nmr, expectedProof, expectedOutput, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the proof against the old and new root.
+	var leafHashes []crypto.Hash
+	err = encoding.Unmarshal(output.Output, &leafHashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !crypto.VerifyDiffProof(ranges, numSectors, output.Proof, leafHashes, imr) {
+		t.Fatal("failed to verify proof against old root")
+	}
+	if !crypto.VerifyDiffProof(ranges, numSectors, output.Proof, []crypto.Hash{newRoot}, nmr) {
+		t.Fatal("failed to verify proof against new root")
+	}
+
+	// The storage obligation should contain the updated sector.
+	if so.sectorRoots[idx] != newRoot {
+		t.Fatal("root wasn't updated")
+	}
+	if !bytes.Equal(so.sectorMap[newRoot], expectedSector) {
+		t.Fatal("updated sector wasn't stored")
+	}
+	if _, exists := so.sectorMap[oldRoots[idx]]; exists {
+		t.Fatal("old sector wasn't removed")
+	}
+
+	// An update outside of the contract should fail.
+	tb = newTestProgramBuilder(pt, duration)
+	tb.AddUpdateSectorInstruction(numSectors*modules.SectorSize, []byte{1}, false)
+	outputs, err = mdm.ExecuteProgramWithBuilder(tb, so, duration, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs[0].Error == nil {
+		t.Fatal("expected out-of-bounds update to fail")
+	}
+}
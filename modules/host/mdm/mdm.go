@@ -39,6 +39,13 @@ type Host interface {
 	RegistryGet(sid modules.RegistryEntryID) (types.SiaPublicKey, modules.SignedRegistryValue, bool)
 }
 
+// TemporarySectorHost is implemented by hosts which can store sectors that
+// don't belong to a contract. Hosts which don't implement it reject the
+// 'StoreSector' instruction.
+type TemporarySectorHost interface {
+	AddTemporarySector(sectorRoot crypto.Hash, sectorData []byte, expiry types.BlockHeight) error
+}
+
 // MDM (Merklized Data Machine) is a virtual machine that executes instructions
 // on the data in a Sia file contract. The file contract tracks the size and
 // Merkle root of the underlying data, which the MDM will update when running
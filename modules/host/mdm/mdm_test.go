@@ -22,11 +22,12 @@ var errSectorNotFound = errors.New("sector not found")
 type (
 	// TestHost is a dummy host for testing which satisfies the Host interface.
 	TestHost struct {
-		generateSectors bool
-		blockHeight     types.BlockHeight
-		sectors         map[crypto.Hash][]byte
-		registry        map[modules.RegistryEntryID]TestRegistryValue
-		mu              sync.Mutex
+		generateSectors  bool
+		blockHeight      types.BlockHeight
+		sectors          map[crypto.Hash][]byte
+		temporarySectors map[crypto.Hash]types.BlockHeight
+		registry         map[modules.RegistryEntryID]TestRegistryValue
+		mu               sync.Mutex
 	}
 	TestRegistryValue struct {
 		modules.SignedRegistryValue
@@ -51,9 +52,10 @@ func newTestHost() *TestHost {
 
 func newCustomTestHost(generateSectors bool) *TestHost {
 	return &TestHost{
-		generateSectors: generateSectors,
-		registry:        make(map[modules.RegistryEntryID]TestRegistryValue),
-		sectors:         make(map[crypto.Hash][]byte),
+		generateSectors:  generateSectors,
+		registry:         make(map[modules.RegistryEntryID]TestRegistryValue),
+		sectors:          make(map[crypto.Hash][]byte),
+		temporarySectors: make(map[crypto.Hash]types.BlockHeight),
 	}
 }
 
@@ -66,6 +68,17 @@ func (h *TestHost) newTestStorageObligation(locked bool) *TestStorageObligation
 	}
 }
 
+// AddTemporarySector adds a sector to the host and remembers its expiry.
+func (h *TestHost) AddTemporarySector(sectorRoot crypto.Hash, sectorData []byte, expiry types.BlockHeight) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sectors[sectorRoot] = sectorData
+	if expiry > h.temporarySectors[sectorRoot] {
+		h.temporarySectors[sectorRoot] = expiry
+	}
+	return nil
+}
+
 // BlockHeight returns an incremented blockheight.
 func (h *TestHost) BlockHeight() types.BlockHeight {
 	h.mu.Lock()
@@ -237,6 +250,7 @@ func newTestPriceTable() *modules.RPCPriceTable {
 		ReadBaseCost:        types.NewCurrency64(1),
 		ReadLengthCost:      types.NewCurrency64(1),
 		SwapSectorCost:      types.NewCurrency64(1),
+		TemporaryStoreCost:  types.NewCurrency64(1),
 		WriteBaseCost:       types.NewCurrency64(1),
 		WriteLengthCost:     types.NewCurrency64(1),
 		WriteStoreCost:      types.NewCurrency64(1),
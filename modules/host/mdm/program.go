@@ -95,12 +95,18 @@ func decodeInstruction(p *program, i modules.Instruction) (instruction, error) {
 		return p.staticDecodeHasSectorInstruction(i)
 	case modules.SpecifierReadSector:
 		return p.staticDecodeReadSectorInstruction(i)
+	case modules.SpecifierReadSectorRange:
+		return p.staticDecodeReadSectorRangeInstruction(i)
 	case modules.SpecifierReadOffset:
 		return p.staticDecodeReadOffsetInstruction(i)
 	case modules.SpecifierRevision:
 		return p.staticDecodeRevisionInstruction(i)
+	case modules.SpecifierStoreSector:
+		return p.staticDecodeStoreSectorInstruction(i)
 	case modules.SpecifierSwapSector:
 		return p.staticDecodeSwapSectorInstruction(i)
+	case modules.SpecifierUpdateSector:
+		return p.staticDecodeUpdateSectorInstruction(i)
 	case modules.SpecifierUpdateRegistry:
 		return p.staticDecodeUpdateRegistryInstruction(i)
 	case modules.SpecifierReadRegistry:
@@ -102,6 +102,37 @@ func (s *sectors) swapSectors(idx1, idx2 uint64) (crypto.Hash, error) {
 	return cachedMerkleRoot(s.merkleRoots), nil
 }
 
+// updateSector replaces the sector at idx with the provided data and returns
+// the new merkle root.
+func (s *sectors) updateSector(idx uint64, sectorData []byte) (crypto.Hash, error) {
+	if idx >= uint64(len(s.merkleRoots)) {
+		return crypto.Hash{}, fmt.Errorf("idx out-of-bounds: %v >= %v", idx, len(s.merkleRoots))
+	}
+	if uint64(len(sectorData)) != modules.SectorSize {
+		return crypto.Hash{}, fmt.Errorf("trying to update sector with data of length %v", len(sectorData))
+	}
+	oldRoot := s.merkleRoots[idx]
+	newRoot := crypto.MerkleRoot(sectorData)
+
+	// Update the program cache for the old sector.
+	if _, gained := s.sectorsGained[oldRoot]; gained {
+		delete(s.sectorsGained, oldRoot)
+	} else {
+		s.sectorsRemoved[oldRoot] = struct{}{}
+	}
+
+	// Update the program cache for the new sector.
+	if _, removed := s.sectorsRemoved[newRoot]; removed {
+		delete(s.sectorsRemoved, newRoot)
+	} else {
+		s.sectorsGained[newRoot] = sectorData
+	}
+
+	// Update the roots.
+	s.merkleRoots[idx] = newRoot
+	return cachedMerkleRoot(s.merkleRoots), nil
+}
+
 // translateOffset translates an offset within a filecontract into a relative
 // offset within a sector and the sector's index within the contract.
 func (s *sectors) translateOffset(offset uint64) (uint64, uint64, error) {
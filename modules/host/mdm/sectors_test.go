@@ -244,6 +244,50 @@ func TestDropSectors(t *testing.T) {
 	}
 }
 
+// TestUpdateSector tests replacing a sector in the cache.
+func TestUpdateSector(t *testing.T) {
+	// Initialize the sectors.
+	sectorRoots := randomSectorRoots(initialContractSectors)
+	s := newSectors(append([]crypto.Hash{}, sectorRoots...))
+
+	// Replace the first sector twice.
+	data1 := fastrand.Bytes(int(modules.SectorSize))
+	data2 := fastrand.Bytes(int(modules.SectorSize))
+	root1, root2 := crypto.MerkleRoot(data1), crypto.MerkleRoot(data2)
+	if _, err := s.updateSector(0, data1); err != nil {
+		t.Fatal(err)
+	}
+	root, err := s.updateSector(0, data2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldRoot := sectorRoots[0]
+	sectorRoots[0] = root2
+	if root != cachedMerkleRoot(sectorRoots) {
+		t.Fatal("unexpected merkle root")
+	}
+
+	// Only the original sector should be removed and only the last sector
+	// should be gained.
+	if _, removed := s.sectorsRemoved[oldRoot]; !removed || len(s.sectorsRemoved) != 1 {
+		t.Fatal("expected original sector to be removed", len(s.sectorsRemoved))
+	}
+	if _, gained := s.sectorsGained[root1]; gained {
+		t.Fatal("intermediate sector shouldn't be gained")
+	}
+	if _, gained := s.sectorsGained[root2]; !gained || len(s.sectorsGained) != 1 {
+		t.Fatal("expected new sector to be gained", len(s.sectorsGained))
+	}
+
+	// Updating an out-of-bounds index or with a partial sector should fail.
+	if _, err := s.updateSector(initialContractSectors, data1); err == nil {
+		t.Fatal("expected out-of-bounds update to fail")
+	}
+	if _, err := s.updateSector(0, data1[1:]); err == nil {
+		t.Fatal("expected update with partial sector to fail")
+	}
+}
+
 // TestHasSector tests checking if a sector exists in the cache or host.
 func TestHasSector(t *testing.T) {
 	// Initialize the sectors.
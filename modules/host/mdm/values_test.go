@@ -113,6 +113,19 @@ func (v *TestValues) AddReadSectorInstruction(length uint64) {
 	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
 }
 
+// AddReadSectorRangeInstruction adds a readsectorrange instruction to the
+// builder, keeping track of running values.
+func (v *TestValues) AddReadSectorRangeInstruction(length, numRoots uint64) {
+	collateral := modules.MDMReadCollateral()
+	cost := modules.MDMReadSectorRangeCost(v.staticPT, length, numRoots)
+	memory := modules.MDMReadMemory()
+	time := modules.MDMReadSectorRangeTime(numRoots)
+	newData := 8 + 8 + 8 + int(numRoots)*crypto.HashSize
+	readonly := true
+	batch := false
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
 // AddRevisionInstruction adds a revision instruction to the builder, keeping
 // track of running values.
 func (v *TestValues) AddRevisionInstruction() {
@@ -138,6 +151,32 @@ func (v *TestValues) AddSwapSectorInstruction() {
 	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
 }
 
+// AddStoreSectorInstruction adds a storesector instruction to the builder,
+// keeping track of running values.
+func (v *TestValues) AddStoreSectorInstruction(data []byte, duration types.BlockHeight) {
+	memory := modules.MDMStoreSectorMemory()
+	collateral := modules.MDMStoreSectorCollateral()
+	cost, refund := modules.MDMStoreSectorCost(v.staticPT, duration)
+	time := uint64(modules.MDMTimeStoreSector)
+	newData := 8 + len(data)
+	readonly := true
+	batch := false
+	v.addInstruction(collateral, cost, refund, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
+// AddUpdateSectorInstruction adds an updatesector instruction to the builder,
+// keeping track of running values.
+func (v *TestValues) AddUpdateSectorInstruction(data []byte) {
+	memory := modules.MDMUpdateSectorMemory()
+	collateral := modules.MDMUpdateSectorCollateral()
+	cost := modules.MDMUpdateSectorCost(v.staticPT)
+	time := uint64(modules.MDMTimeUpdateSector)
+	newData := 8 + 8 + len(data)
+	readonly := false
+	batch := false
+	v.addInstruction(collateral, cost, types.ZeroCurrency, types.ZeroCurrency, memory, time, newData, readonly, batch)
+}
+
 // AddUpdateRegistryInstruction adds a revision instruction to the builder, keeping
 // track of running values.
 func (v *TestValues) AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv modules.SignedRegistryValue) {
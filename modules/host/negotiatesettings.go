@@ -64,6 +64,16 @@ func (h *Host) externalSettings(maxFeeEstimation types.Currency) modules.HostExt
 		maxCollateral = h.settings.CollateralBudget.Sub(h.financialMetrics.LockedStorageCollateral)
 	}
 
+	// Use the prices of the pricing engine if it is enabled.
+	downloadBandwidthPrice := h.settings.MinDownloadBandwidthPrice
+	storagePrice := h.settings.MinStoragePrice
+	uploadBandwidthPrice := h.settings.MinUploadBandwidthPrice
+	if prices := h.pricingEngine.prices; h.settings.PricingEngine.Enabled && prices != nil {
+		downloadBandwidthPrice = prices.DownloadBandwidthPrice
+		storagePrice = prices.StoragePrice
+		uploadBandwidthPrice = prices.UploadBandwidthPrice
+	}
+
 	// Extract the port from the SiaMux's address
 	_, port, err := net.SplitHostPort(h.staticMux.Address().String())
 	if err != nil {
@@ -87,10 +97,10 @@ func (h *Host) externalSettings(maxFeeEstimation types.Currency) modules.HostExt
 
 		BaseRPCPrice:           h.settings.MinBaseRPCPrice,
 		ContractPrice:          contractPrice,
-		DownloadBandwidthPrice: h.settings.MinDownloadBandwidthPrice,
+		DownloadBandwidthPrice: downloadBandwidthPrice,
 		SectorAccessPrice:      h.settings.MinSectorAccessPrice,
-		StoragePrice:           h.settings.MinStoragePrice,
-		UploadBandwidthPrice:   h.settings.MinUploadBandwidthPrice,
+		StoragePrice:           storagePrice,
+		UploadBandwidthPrice:   uploadBandwidthPrice,
 
 		EphemeralAccountExpiry:     h.settings.EphemeralAccountExpiry,
 		MaxEphemeralAccountBalance: h.settings.MaxEphemeralAccountBalance,
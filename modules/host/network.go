@@ -483,5 +483,9 @@ func (h *Host) NetworkMetrics() modules.HostNetworkMetrics {
 		ReviseCalls:       atomic.LoadUint64(&h.atomicReviseCalls),
 		SettingsCalls:     atomic.LoadUint64(&h.atomicSettingsCalls),
 		UnrecognizedCalls: atomic.LoadUint64(&h.atomicUnrecognizedCalls),
+
+		DownloadBandwidth: h.staticBandwidthShaper.staticQueues[bandwidthClassDownload].managedMetrics(),
+		UploadBandwidth:   h.staticBandwidthShaper.staticQueues[bandwidthClassUpload].managedMetrics(),
+		RegistryBandwidth: h.staticBandwidthShaper.staticQueues[bandwidthClassRegistry].managedMetrics(),
 	}
 }
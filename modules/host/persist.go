@@ -75,6 +75,9 @@ func (h *Host) establishDefaults() error {
 		EphemeralAccountExpiry:     modules.DefaultEphemeralAccountExpiry,
 		MaxEphemeralAccountBalance: modules.DefaultMaxEphemeralAccountBalance,
 		MaxEphemeralAccountRisk:    defaultMaxEphemeralAccountRisk,
+
+		MaxTemporaryStorage:      modules.DefaultMaxTemporaryStorage,
+		MinTemporaryStoragePrice: modules.DefaultTemporaryStoragePrice,
 	}
 
 	// Load the host's key pair, use the same keys as the SiaMux.
@@ -0,0 +1,185 @@
+package host
+
+// pricingengine.go contains the host's pricing engine. If enabled, the engine
+// periodically adjusts the storage and bandwidth prices of the host within the
+// bounds set by the host operator. The base price of every category is either
+// derived from a target fiat price and the configured exchange rate or, if no
+// target is set, equal to the minimum price. The base price is then scaled by
+// the utilization of the host's storage or bandwidth. At a utilization of 50%
+// the price equals the base price, an idle host charges half of it and a fully
+// utilized host charges 1.5 times as much. To avoid thrashing, prices are
+// updated at most once per update interval and every update can only change a
+// price by a limited percentage.
+
+import (
+	"time"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// dynamicPrices are the prices computed by the host's pricing engine.
+type dynamicPrices struct {
+	DownloadBandwidthPrice types.Currency
+	StoragePrice           types.Currency
+	UploadBandwidthPrice   types.Currency
+}
+
+// pricingEngineState is the state of the pricing engine between two updates.
+type pricingEngineState struct {
+	prices       *dynamicPrices
+	lastUpdate   time.Time
+	lastSent     uint64
+	lastReceived uint64
+}
+
+// utilizationMultiplier returns the factor by which a base price is scaled for
+// the given utilization.
+func utilizationMultiplier(utilization float64) float64 {
+	if utilization < 0 {
+		utilization = 0
+	} else if utilization > 1 {
+		utilization = 1
+	}
+	return 0.5 + utilization
+}
+
+// dynamicPrice computes a single price of the pricing engine. The price is
+// derived from the base price and the utilization, limited to the max change
+// relative to the current price and finally clamped to the operator's bounds.
+func dynamicPrice(base, min, max, current types.Currency, utilization, maxChange float64) types.Currency {
+	price := base.MulFloat(utilizationMultiplier(utilization))
+	if !current.IsZero() {
+		lower := current.MulFloat(1 - maxChange)
+		upper := current.MulFloat(1 + maxChange)
+		if price.Cmp(lower) < 0 {
+			price = lower
+		} else if price.Cmp(upper) > 0 {
+			price = upper
+		}
+	}
+	if !max.IsZero() && price.Cmp(max) > 0 {
+		price = max
+	}
+	if price.Cmp(min) < 0 {
+		price = min
+	}
+	return price
+}
+
+// computeDynamicPrices computes the prices of the pricing engine for the given
+// settings and utilization. The current prices are nil if the engine didn't
+// set any prices yet.
+func computeDynamicPrices(settings modules.HostInternalSettings, current *dynamicPrices, storageUtilization, uploadUtilization, downloadUtilization float64) (dynamicPrices, error) {
+	pes := settings.PricingEngine
+	rate, err := types.ParseExchangeRate(pes.ExchangeRate)
+	if err != nil {
+		return dynamicPrices{}, err
+	}
+	base := func(target float64, unit, min types.Currency) types.Currency {
+		if rate == nil || target == 0 {
+			return min
+		}
+		return rate.FromFiat(target).Div(unit)
+	}
+	if current == nil {
+		current = &dynamicPrices{}
+	}
+	maxChange := pes.PriceChange()
+	return dynamicPrices{
+		DownloadBandwidthPrice: dynamicPrice(base(pes.TargetDownloadBandwidthPrice, modules.BytesPerTerabyte, settings.MinDownloadBandwidthPrice), settings.MinDownloadBandwidthPrice, pes.MaxDownloadBandwidthPrice, current.DownloadBandwidthPrice, downloadUtilization, maxChange),
+		StoragePrice:           dynamicPrice(base(pes.TargetStoragePrice, modules.BlockBytesPerMonthTerabyte, settings.MinStoragePrice), settings.MinStoragePrice, pes.MaxStoragePrice, current.StoragePrice, storageUtilization, maxChange),
+		UploadBandwidthPrice:   dynamicPrice(base(pes.TargetUploadBandwidthPrice, modules.BytesPerTerabyte, settings.MinUploadBandwidthPrice), settings.MinUploadBandwidthPrice, pes.MaxUploadBandwidthPrice, current.UploadBandwidthPrice, uploadUtilization, maxChange),
+	}, nil
+}
+
+// bandwidthUtilization returns the utilization of the host's bandwidth given
+// the number of bytes transferred within the elapsed time. Without a target
+// bandwidth the utilization is neutral.
+func bandwidthUtilization(bytes uint64, elapsed time.Duration, target uint64) float64 {
+	if target == 0 || elapsed <= 0 {
+		return 0.5
+	}
+	return float64(bytes) / elapsed.Seconds() / float64(target)
+}
+
+// managedUpdateDynamicPrices updates the prices of the pricing engine if the
+// engine is enabled and the update interval has passed since the last update.
+func (h *Host) managedUpdateDynamicPrices() {
+	settings := h.managedInternalSettings()
+	if !settings.PricingEngine.Enabled {
+		h.mu.Lock()
+		h.pricingEngine = pricingEngineState{}
+		h.mu.Unlock()
+		return
+	}
+	h.mu.RLock()
+	state := h.pricingEngine
+	h.mu.RUnlock()
+	if state.prices != nil && time.Since(state.lastUpdate) < settings.PricingEngine.Interval() {
+		return
+	}
+
+	// Compute the utilization of the host's storage and bandwidth.
+	sent, received, _, err := h.BandwidthCounters()
+	if err != nil {
+		return
+	}
+	storageUtilization := 0.5
+	if total, remaining := h.capacity(); total > 0 {
+		storageUtilization = float64(total-remaining) / float64(total)
+	}
+	var uploadUtilization, downloadUtilization float64
+	if state.prices == nil {
+		// Without a previous update the bandwidth usage is unknown.
+		uploadUtilization, downloadUtilization = 0.5, 0.5
+	} else {
+		elapsed := time.Since(state.lastUpdate)
+		target := settings.PricingEngine.TargetBandwidth
+		uploadUtilization = bandwidthUtilization(received-state.lastReceived, elapsed, target)
+		downloadUtilization = bandwidthUtilization(sent-state.lastSent, elapsed, target)
+	}
+
+	prices, err := computeDynamicPrices(settings, state.prices, storageUtilization, uploadUtilization, downloadUtilization)
+	if err != nil {
+		h.log.Println("WARN: failed to compute dynamic prices:", err)
+		return
+	}
+	h.mu.Lock()
+	h.pricingEngine = pricingEngineState{
+		prices:       &prices,
+		lastUpdate:   time.Now(),
+		lastSent:     sent,
+		lastReceived: received,
+	}
+	h.mu.Unlock()
+	h.log.Debugf("Pricing engine updated prices: storage %v, upload %v, download %v", prices.StoragePrice, prices.UploadBandwidthPrice, prices.DownloadBandwidthPrice)
+
+	// Update the price table to reflect the new prices.
+	h.managedUpdatePriceTable()
+}
+
+// threadedUpdateDynamicPrices periodically updates the prices of the pricing
+// engine.
+//
+// Note: threadgroup counter must be inside for loop. If not, calling 'Flush'
+// on the threadgroup would deadlock.
+func (h *Host) threadedUpdateDynamicPrices() {
+	for {
+		func() {
+			if err := h.tg.Add(); err != nil {
+				return
+			}
+			defer h.tg.Done()
+			h.managedUpdateDynamicPrices()
+		}()
+
+		// Block until next cycle.
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(pricingEngineCheckFrequency):
+			continue
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestDynamicPrice is a unit test for dynamicPrice.
+func TestDynamicPrice(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	c := types.NewCurrency64
+	tests := []struct {
+		base, min, max, current types.Currency
+		utilization, maxChange  float64
+		result                  types.Currency
+	}{
+		// Utilization scales the base price between 0.5x and 1.5x.
+		{c(100), c(0), c(0), c(0), 0, 0.1, c(50)},
+		{c(100), c(0), c(0), c(0), 0.5, 0.1, c(100)},
+		{c(100), c(0), c(0), c(0), 1, 0.1, c(150)},
+		{c(100), c(0), c(0), c(0), 2, 0.1, c(150)},
+		// The change relative to the current price is limited.
+		{c(100), c(0), c(0), c(100), 1, 0.1, c(110)},
+		{c(100), c(0), c(0), c(100), 0, 0.1, c(90)},
+		{c(100), c(0), c(0), c(100), 0.55, 0.1, c(105)},
+		// The bounds are applied last.
+		{c(100), c(120), c(0), c(100), 0.5, 0.1, c(120)},
+		{c(100), c(0), c(80), c(100), 0.5, 0.1, c(80)},
+		{c(100), c(0), c(80), c(0), 1, 0.1, c(80)},
+	}
+	for i, test := range tests {
+		price := dynamicPrice(test.base, test.min, test.max, test.current, test.utilization, test.maxChange)
+		if !price.Equals(test.result) {
+			t.Errorf("%v: expected %v but got %v", i, test.result, price)
+		}
+	}
+}
+
+// TestComputeDynamicPrices tests computing the prices of the pricing engine
+// from target fiat prices.
+func TestComputeDynamicPrices(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	settings := modules.HostInternalSettings{
+		MinDownloadBandwidthPrice: types.NewCurrency64(1),
+		MinStoragePrice:           types.NewCurrency64(1),
+		MinUploadBandwidthPrice:   types.SiacoinPrecision,
+		PricingEngine: modules.HostPricingEngineSettings{
+			Enabled:                      true,
+			ExchangeRate:                 "0.5 usd",
+			TargetDownloadBandwidthPrice: 10,
+			TargetStoragePrice:           2,
+		},
+	}
+	prices, err := computeDynamicPrices(settings, nil, 0.5, 0.5, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 10 usd / TB are 20 SC / TB.
+	if expected := types.SiacoinPrecision.Mul64(20).Div(modules.BytesPerTerabyte); !prices.DownloadBandwidthPrice.Equals(expected) {
+		t.Fatal("unexpected download price", prices.DownloadBandwidthPrice, expected)
+	}
+	// 2 usd / TB / month are 4 SC / TB / month.
+	if expected := types.SiacoinPrecision.Mul64(4).Div(modules.BlockBytesPerMonthTerabyte); !prices.StoragePrice.Equals(expected) {
+		t.Fatal("unexpected storage price", prices.StoragePrice, expected)
+	}
+	// Without a target the min price is the base price.
+	if !prices.UploadBandwidthPrice.Equals(types.SiacoinPrecision) {
+		t.Fatal("unexpected upload price", prices.UploadBandwidthPrice)
+	}
+
+	// Doubling the exchange rate halves the prices, but only by the max
+	// change per update.
+	settings.PricingEngine.ExchangeRate = "1 usd"
+	updated, err := computeDynamicPrices(settings, &prices, 0.5, 0.5, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := prices.StoragePrice.MulFloat(1 - modules.DefaultPricingEngineMaxPriceChange); !updated.StoragePrice.Equals(expected) {
+		t.Fatal("unexpected storage price", updated.StoragePrice, expected)
+	}
+}
+
+// TestPricingEngine tests that the host applies the prices of the pricing
+// engine to its external settings and price table.
+func TestPricingEngine(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := ht.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid settings are rejected.
+	settings := ht.host.InternalSettings()
+	settings.PricingEngine = modules.HostPricingEngineSettings{
+		Enabled:            true,
+		TargetStoragePrice: 2,
+	}
+	if err := ht.host.SetInternalSettings(settings); !errors.Contains(err, modules.ErrPricingTargetWithoutRate) {
+		t.Fatal("expected ErrPricingTargetWithoutRate but got", err)
+	}
+	settings.PricingEngine.ExchangeRate = "0.01 usd"
+	settings.PricingEngine.MaxStoragePrice = settings.MinStoragePrice.Div64(2)
+	if err := ht.host.SetInternalSettings(settings); !errors.Contains(err, modules.ErrMaxPriceBelowMinPrice) {
+		t.Fatal("expected ErrMaxPriceBelowMinPrice but got", err)
+	}
+
+	// Enable the engine with a storage target above the min price.
+	settings.PricingEngine.MaxStoragePrice = types.ZeroCurrency
+	settings.PricingEngine.UpdateInterval = time.Hour
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	minPrice := settings.MinStoragePrice
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		es := ht.host.ExternalSettings()
+		if es.StoragePrice.Cmp(minPrice) <= 0 {
+			return errors.New("storage price wasn't updated")
+		}
+		if pt := ht.host.PriceTable(); !pt.WriteStoreCost.Equals(es.StoragePrice) {
+			return errors.New("price table wasn't updated")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The prices don't change within the update interval.
+	price := ht.host.ExternalSettings().StoragePrice
+	settings = ht.host.InternalSettings()
+	settings.PricingEngine.TargetStoragePrice = 4
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * pricingEngineCheckFrequency)
+	if es := ht.host.ExternalSettings(); !es.StoragePrice.Equals(price) {
+		t.Fatal("price changed within the update interval", es.StoragePrice, price)
+	}
+
+	// Disabling the engine restores the min price.
+	settings.PricingEngine.Enabled = false
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if es := ht.host.ExternalSettings(); !es.StoragePrice.Equals(minPrice) {
+		t.Fatal("expected min price but got", es.StoragePrice)
+	}
+}
@@ -3,6 +3,7 @@ package registry
 import (
 	"fmt"
 	"math"
+	"math/bits"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
@@ -62,6 +63,18 @@ func (b *bitfield) SetRandom() (uint64, error) {
 	return 0, ErrNoFreeBit
 }
 
+// Count returns the number of set bits in the bitfield and the number of
+// 64-bit words without an unset bit.
+func (b bitfield) Count() (set, fullWords uint64) {
+	for _, word := range b {
+		set += uint64(bits.OnesCount64(word))
+		if word == math.MaxUint64 {
+			fullWords++
+		}
+	}
+	return
+}
+
 // IsSet returns whether the gap at the specified index is set.
 func (b bitfield) IsSet(index uint64) bool {
 	// Each index covers 8 bytes which are 8 bits each. So 64 bits in total.
@@ -228,3 +228,26 @@ func TestNewBitfield(t *testing.T) {
 		t.Fatal("wrong length")
 	}
 }
+
+// TestBitfieldCount tests counting the set bits and full words of a bitfield.
+func TestBitfieldCount(t *testing.T) {
+	b, err := newBitfield(192)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if set, full := b.Count(); set != 0 || full != 0 {
+		t.Fatal("wrong count", set, full)
+	}
+	// Fill the first word and set a bit in the last one.
+	for i := uint64(0); i < 64; i++ {
+		if err := b.Set(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := b.Set(191); err != nil {
+		t.Fatal(err)
+	}
+	if set, full := b.Count(); set != 65 || full != 1 {
+		t.Fatal("wrong count", set, full)
+	}
+}
@@ -0,0 +1,152 @@
+package registry
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// Export writes the entries of the registry to a new file at the provided
+// path. The exported file uses the registry's on-disk format without the
+// unused slots, which means that it can also be loaded as a registry. It
+// returns the number of exported entries.
+func (r *Registry) Export(path string) (_ uint64, err error) {
+	if !filepath.IsAbs(path) {
+		return 0, errPathNotAbsolute
+	}
+
+	// Create the file only if it doesn't exist yet.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, modules.DefaultFilePerm)
+	if err != nil {
+		return 0, errors.AddContext(err, "Export: failed to create export file")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+		if err != nil {
+			err = errors.Compose(err, os.Remove(path))
+		}
+	}()
+	if err := writeMetadata(f); err != nil {
+		return 0, errors.AddContext(err, "Export: failed to write metadata")
+	}
+
+	// Get a slice of entries. We only hold the lock during the map access.
+	r.mu.Lock()
+	entries := make([]*value, 0, len(r.entries))
+	for _, v := range r.entries {
+		entries = append(entries, v)
+	}
+	r.mu.Unlock()
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].staticIndex < entries[j].staticIndex
+	})
+
+	// Write the entries after the metadata page.
+	if _, err := f.Seek(PersistedEntrySize, io.SeekStart); err != nil {
+		return 0, errors.AddContext(err, "Export: failed to seek past metadata")
+	}
+	w := bufio.NewWriter(f)
+	var exported uint64
+	for _, entry := range entries {
+		entry.mu.Lock()
+		if entry.invalid {
+			entry.mu.Unlock()
+			continue // already deleted
+		}
+		pe, err := newPersistedEntry(entry)
+		entry.mu.Unlock()
+		if err != nil {
+			return 0, errors.AddContext(err, "Export: failed to get persistedEntry")
+		}
+		b, err := pe.Marshal()
+		if err != nil {
+			return 0, errors.AddContext(err, "Export: failed to marshal persistedEntry")
+		}
+		if _, err := w.Write(b); err != nil {
+			return 0, errors.AddContext(err, "Export: failed to write entry")
+		}
+		exported++
+	}
+	if err := w.Flush(); err != nil {
+		return 0, errors.AddContext(err, "Export: failed to flush entries")
+	}
+	if err := f.Sync(); err != nil {
+		return 0, errors.AddContext(err, "Export: failed to sync export file")
+	}
+	return exported, nil
+}
+
+// Import adds the entries of a file created by Export, or of another registry
+// file, to the registry. Every entry is verified and only added if it is more
+// recent than the known entry. Entries that expire at a height smaller than or
+// equal to the provided height are skipped.
+func (r *Registry) Import(path string, bh types.BlockHeight) (_ modules.HostRegistryImport, err error) {
+	if !filepath.IsAbs(path) {
+		return modules.HostRegistryImport{}, errPathNotAbsolute
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return modules.HostRegistryImport{}, errors.AddContext(err, "Import: failed to open import file")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+	fi, err := f.Stat()
+	if err != nil {
+		return modules.HostRegistryImport{}, errors.AddContext(err, "Import: failed to get size of import file")
+	}
+	if fi.Size()%int64(PersistedEntrySize) != 0 || fi.Size() == 0 {
+		return modules.HostRegistryImport{}, errors.New("Import: expected size of import file to be multiple of entry size and not 0")
+	}
+	numEntries := fi.Size() / PersistedEntrySize
+
+	// Load the metadata and entries. The bitfield only tracks the slots
+	// within the import file.
+	b, err := newBitfield(uint64((numEntries + 63) / 64 * 64))
+	if err != nil {
+		return modules.HostRegistryImport{}, errors.AddContext(err, "Import: failed to create bitfield")
+	}
+	rd := bufio.NewReader(f)
+	err = loadRegistryMetadata(rd, b)
+	compatV100 := errors.Contains(err, errCompat100)
+	if err != nil && !compatV100 {
+		return modules.HostRegistryImport{}, errors.AddContext(err, "Import: failed to load and verify metadata")
+	}
+	loaded, err := loadRegistryEntries(rd, numEntries, b, compatV100)
+	if err != nil {
+		return modules.HostRegistryImport{}, errors.AddContext(err, "Import: failed to load entries")
+	}
+	entries := make([]*value, 0, len(loaded))
+	for _, v := range loaded {
+		entries = append(entries, v)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].staticIndex < entries[j].staticIndex
+	})
+
+	// Add the entries to the registry.
+	var result modules.HostRegistryImport
+	for _, v := range entries {
+		if v.expiry <= bh {
+			result.Skipped++
+			continue
+		}
+		rv := modules.NewSignedRegistryValue(v.tweak, v.data, v.revision, v.signature, v.entryType)
+		_, err := r.Update(rv, v.key, v.expiry)
+		if modules.IsRegistryEntryExistErr(err) {
+			result.Skipped++
+			continue
+		}
+		if err != nil {
+			return result, errors.AddContext(err, "Import: failed to add entry")
+		}
+		result.Imported++
+	}
+	return result, nil
+}
@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestExportImport tests exporting a registry and importing it into another
+// registry.
+func TestExportImport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := testDir(t.Name())
+
+	// Create the source registry with 10 entries, the first one expiring at
+	// height 5.
+	src, err := New(filepath.Join(dir, "src"), testingDefaultMaxEntries, types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(src)
+	var values []*value
+	var sks []crypto.SecretKey
+	for i := 0; i < 10; i++ {
+		rv, v, sk := randomValue(0)
+		v.expiry = 10
+		if i == 0 {
+			v.expiry = 5
+		}
+		if _, err := src.Update(rv, v.key, v.expiry); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+		sks = append(sks, sk)
+	}
+
+	// Relative paths are rejected.
+	if _, err := src.Export("export"); err != errPathNotAbsolute {
+		t.Fatal("expected errPathNotAbsolute", err)
+	}
+
+	// Export the registry.
+	exportPath := filepath.Join(dir, "export")
+	n, err := src.Export(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 10 {
+		t.Fatal("wrong number of exported entries", n)
+	}
+	fi, err := os.Stat(exportPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 11*PersistedEntrySize {
+		t.Fatal("wrong size of export", fi.Size())
+	}
+
+	// Exporting to the same path again fails.
+	if _, err := src.Export(exportPath); err == nil {
+		t.Fatal("export should fail if the file exists")
+	}
+
+	// Create the destination registry which already knows a more recent
+	// revision of the second entry.
+	dst, err := New(filepath.Join(dir, "dst"), testingDefaultMaxEntries, types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(dst)
+	newer := modules.NewRegistryValue(values[1].tweak, values[1].data, values[1].revision+1, values[1].entryType).Sign(sks[1])
+	if _, err := dst.Update(newer, values[1].key, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Import the export at height 5. The expired entry and the outdated entry
+	// are skipped.
+	result, err := dst.Import(exportPath, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 8 || result.Skipped != 2 {
+		t.Fatal("unexpected import result", result)
+	}
+	if dst.Len() != 9 {
+		t.Fatal("wrong number of entries", dst.Len())
+	}
+	for _, v := range values[2:] {
+		spk1, srv1, expiry1, found1 := src.GetWithExpiry(v.mapKey())
+		spk2, srv2, expiry2, found2 := dst.GetWithExpiry(v.mapKey())
+		if !found1 || !found2 {
+			t.Fatal("entry not found")
+		}
+		if !reflect.DeepEqual(spk1, spk2) || !reflect.DeepEqual(srv1, srv2) || expiry1 != expiry2 {
+			t.Fatal("imported entry doesn't match")
+		}
+	}
+	if _, _, found := dst.Get(values[0].mapKey()); found {
+		t.Fatal("expired entry was imported")
+	}
+
+	// Importing again at height 0 only adds the previously expired entry.
+	result, err = dst.Import(exportPath, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Imported != 1 || result.Skipped != 9 {
+		t.Fatal("unexpected import result", result)
+	}
+
+	// The more recent entry wasn't replaced.
+	_, srv, _ := dst.Get(values[1].mapKey())
+	if srv.Revision != newer.Revision {
+		t.Fatal("entry was overwritten")
+	}
+}
+
+// TestStats tests the registry usage statistics.
+func TestStats(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := testDir(t.Name())
+	path := filepath.Join(dir, "registry")
+	r, err := New(path, 128, types.SiaPublicKey{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(c io.Closer) {
+		if err := c.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}(r)
+
+	// Add 70 entries, half of them expiring at height 1.
+	for i := 0; i < 70; i++ {
+		rv, v, _ := randomValue(0)
+		if _, err := r.Update(rv, v.key, types.BlockHeight(i%2+1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats := r.Stats(1)
+	if stats.Path != path || stats.Entries != 70 || stats.Capacity != 128 || stats.UsedSlots != 70 {
+		t.Fatal("unexpected stats", stats)
+	}
+	if stats.ExpiredEntries != 35 || stats.BitfieldWords != 2 || stats.FullBitfieldWords > 1 {
+		t.Fatal("unexpected stats", stats)
+	}
+
+	// Prune the expired entries.
+	if _, err := r.Prune(1); err != nil {
+		t.Fatal(err)
+	}
+	stats = r.Stats(1)
+	if stats.Entries != 35 || stats.UsedSlots != 35 || stats.ExpiredEntries != 0 {
+		t.Fatal("unexpected stats after prune", stats)
+	}
+}
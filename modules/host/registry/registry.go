@@ -139,6 +139,20 @@ func (r *Registry) Get(sid modules.RegistryEntryID) (types.SiaPublicKey, modules
 	return v.key, modules.NewSignedRegistryValue(v.tweak, v.data, v.revision, v.signature, v.entryType), true
 }
 
+// GetWithExpiry fetches the data associated with a key and tweak from the
+// registry together with the height at which the entry expires.
+func (r *Registry) GetWithExpiry(sid modules.RegistryEntryID) (types.SiaPublicKey, modules.SignedRegistryValue, types.BlockHeight, bool) {
+	r.mu.Lock()
+	v, ok := r.entries[sid]
+	r.mu.Unlock()
+	if !ok {
+		return types.SiaPublicKey{}, modules.SignedRegistryValue{}, 0, false
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.key, modules.NewSignedRegistryValue(v.tweak, v.data, v.revision, v.signature, v.entryType), v.expiry, true
+}
+
 // Len returns the length of the registry.
 func (r *Registry) Len() uint64 {
 	r.mu.Lock()
@@ -146,6 +160,36 @@ func (r *Registry) Len() uint64 {
 	return uint64(len(r.entries))
 }
 
+// Stats returns usage statistics of the registry. Entries that expire at a
+// height smaller than or equal to the provided height are counted as expired.
+func (r *Registry) Stats(bh types.BlockHeight) modules.HostRegistryStats {
+	r.mu.Lock()
+	entries := make([]*value, 0, len(r.entries))
+	for _, v := range r.entries {
+		entries = append(entries, v)
+	}
+	usedSlots, fullWords := r.usage.Count()
+	stats := modules.HostRegistryStats{
+		Path:              r.staticPath,
+		Entries:           uint64(len(r.entries)),
+		Capacity:          r.usage.Len(),
+		UsedSlots:         usedSlots,
+		BitfieldWords:     uint64(len(r.usage)),
+		FullBitfieldWords: fullWords,
+	}
+	r.mu.Unlock()
+
+	// Count the expired entries without holding the registry lock.
+	for _, v := range entries {
+		v.mu.Lock()
+		if !v.invalid && v.expiry <= bh {
+			stats.ExpiredEntries++
+		}
+		v.mu.Unlock()
+	}
+	return stats
+}
+
 // Truncate resizes the registry. If 'force' was specified, it will allow to
 // shrink the registry below its current size. This will cause random values to
 // be lost.
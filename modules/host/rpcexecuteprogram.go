@@ -98,6 +98,17 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		}
 	}
 
+	// Shape the bandwidth of the program according to its class. The renter
+	// is only identified by the contract if the program requires one, since
+	// the contract id of other programs isn't checked.
+	var renterContract types.FileContractID
+	if program.RequiresSnapshot() {
+		renterContract = fcid
+	}
+	readClass, writeClass := programBandwidthClasses(program)
+	shaped := h.staticBandwidthShaper.newShapedReadWriter(stream, h.tg.StopChan())
+	shaped.managedSetClasses(bandwidthRenterKey(renterContract, stream.RemoteAddr(), refundAccount), readClass, writeClass)
+
 	// Get the remaining unallocated collateral.
 	collateralBudget := sos.UnallocatedCollateral()
 
@@ -117,7 +128,7 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 	}()
 
 	// Execute the program.
-	finalize, outputs, err := h.staticMDM.ExecuteProgram(ctx, pt, program, budget, collateralBudget, sos, duration, dataLength, stream)
+	finalize, outputs, err := h.staticMDM.ExecuteProgram(ctx, pt, program, budget, collateralBudget, sos, duration, dataLength, shaped)
 	if err != nil {
 		return errors.AddContext(err, "Failed to start execution of the program")
 	}
@@ -130,7 +141,7 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 	// buffer are written to the stream.
 	defer func() {
 		if buffer.Len() > 0 {
-			_, err = buffer.WriteTo(stream)
+			_, err = buffer.WriteTo(shaped)
 			if err != nil {
 				h.log.Print("failed to flush buffer", err)
 			}
@@ -194,7 +205,7 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		}
 
 		instructionSpecifier := program[numOutputs-1].Specifier
-		readInstruction := instructionSpecifier == modules.SpecifierReadOffset || instructionSpecifier == modules.SpecifierReadSector
+		readInstruction := instructionSpecifier == modules.SpecifierReadOffset || instructionSpecifier == modules.SpecifierReadSector || instructionSpecifier == modules.SpecifierReadSectorRange
 		updateRegistryInstruction := instructionSpecifier == modules.SpecifierUpdateRegistry
 		if (readInstruction || updateRegistryInstruction) && h.dependencies.Disrupt("CorruptMDMOutput") {
 			// Replace output with same amount of random data.
@@ -231,7 +242,7 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 		}
 
 		// Write contents of the buffer.
-		_, err = buffer.WriteTo(stream)
+		_, err = buffer.WriteTo(shaped)
 		if err != nil {
 			return errors.AddContext(err, "failed to send data to peer")
 		}
@@ -259,7 +270,7 @@ func (h *Host) managedRPCExecuteProgram(stream siamux.Stream) error {
 
 	// Call finalize if the program is not readonly.
 	if !readonly {
-		err := h.managedFinalizeWriteProgram(stream, fcid, finalize, output, bh)
+		err := h.managedFinalizeWriteProgram(shaped, fcid, finalize, output, bh)
 		if err != nil {
 			return errors.AddContext(err, "failed to finalize write program")
 		}
@@ -346,6 +357,19 @@ func (h *Host) managedFinalizeWriteProgram(stream io.ReadWriter, fcid types.File
 	return errors.AddContext(finalize(so), "program finalizer failed")
 }
 
+// programBandwidthClasses returns the bandwidth classes of the data received
+// and sent by the host while executing the program. Programs that access the
+// registry use the registry class for both directions.
+func programBandwidthClasses(p modules.Program) (readClass, writeClass bandwidthClass) {
+	for _, instruction := range p {
+		switch instruction.Specifier {
+		case modules.SpecifierUpdateRegistry, modules.SpecifierReadRegistry, modules.SpecifierReadRegistryEID:
+			return bandwidthClassRegistry, bandwidthClassRegistry
+		}
+	}
+	return bandwidthClassUpload, bandwidthClassDownload
+}
+
 // verifyExecuteProgramRevision verifies that the new revision is sane in
 // relation to the old one.
 func verifyExecuteProgramRevision(currentRevision, newRevision types.FileContractRevision, blockHeight types.BlockHeight, maxTransfer types.Currency, newFileSize uint64, newRoot crypto.Hash) error {
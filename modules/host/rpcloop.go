@@ -50,6 +50,30 @@ func (s *rpcSession) writeError(err error) error {
 	return modules.WriteRPCResponse(s.conn, s.aead, nil, err)
 }
 
+// bandwidthRenterKey returns the key that identifies the renter of the session
+// for bandwidth shaping. Sessions with a locked contract are identified by the
+// contract.
+func (s *rpcSession) bandwidthRenterKey() string {
+	var fcid types.FileContractID
+	if len(s.so.OriginTransactionSet) != 0 {
+		fcid = s.so.id()
+	}
+	return bandwidthRenterKey(fcid, s.conn.RemoteAddr(), modules.AccountID{})
+}
+
+// rpcLoopBandwidthClasses returns the bandwidth classes of the data received
+// and sent by the host during the loop RPC with the given id.
+func rpcLoopBandwidthClasses(id types.Specifier) (readClass, writeClass bandwidthClass) {
+	switch id {
+	case modules.RPCLoopRead, modules.RPCLoopSectorRoots:
+		return bandwidthClassNone, bandwidthClassDownload
+	case modules.RPCLoopWrite:
+		return bandwidthClassUpload, bandwidthClassNone
+	default:
+		return bandwidthClassNone, bandwidthClassNone
+	}
+}
+
 // managedRPCLoop reads new RPCs from the renter, each consisting of a single
 // request and response. The loop terminates when the an RPC encounters an
 // error or the renter sends modules.RPCLoopExit.
@@ -96,9 +120,11 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 		build.Critical("could not create cipher")
 		return err
 	}
-	// create the session object
+	// create the session object, the RPCs of the session are shaped
+	// according to their bandwidth class
+	sc := h.staticBandwidthShaper.newShapedConn(conn, h.tg.StopChan())
 	s := &rpcSession{
-		conn: conn,
+		conn: sc,
 		aead: aead,
 	}
 	fastrand.Read(s.challenge[:])
@@ -140,9 +166,13 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 		} else if id == modules.RPCLoopExit {
 			return nil
 		}
-		if rpcFn, ok := rpcs[id]; !ok {
+		rpcFn, ok := rpcs[id]
+		if !ok {
 			return errors.New("invalid or unknown RPC ID: " + id.String())
-		} else if err := rpcFn(s); err != nil {
+		}
+		readClass, writeClass := rpcLoopBandwidthClasses(id)
+		sc.managedSetClasses(s.bandwidthRenterKey(), readClass, writeClass)
+		if err := rpcFn(s); err != nil {
 			return extendErr("incoming RPC"+id.String()+" failed: ", err)
 		}
 	}
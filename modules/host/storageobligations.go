@@ -176,6 +176,13 @@ type storageObligation struct {
 	RevisionConfirmed   bool
 	RevisionConstructed bool
 
+	// The height and the transaction of the confirmed storage proof, and the
+	// height at which the host resolved the storage obligation. They are
+	// used to report the host's revenue.
+	ProofHeight        types.BlockHeight
+	ProofTransactionID types.TransactionID
+	ResolutionHeight   types.BlockHeight
+
 	h *Host
 }
 
@@ -360,6 +367,11 @@ func (so *storageObligation) StorageObligation() modules.StorageObligation {
 		RevisionConfirmed:   so.RevisionConfirmed,
 		RevisionConstructed: so.RevisionConstructed,
 
+		ProofHeight:           so.ProofHeight,
+		ProofTransactionID:    so.ProofTransactionID,
+		ResolutionHeight:      so.ResolutionHeight,
+		RevisionTransactionID: so.revisionTransactionID(),
+
 		ValidProofOutputs:  valid,
 		MissedProofOutputs: missed,
 	}
@@ -514,6 +526,16 @@ func (so storageObligation) transactionID() types.TransactionID {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].ID()
 }
 
+// revisionTransactionID returns the id of the transaction containing the
+// most recent revision of the file contract, or an empty id if the contract
+// wasn't revised.
+func (so storageObligation) revisionTransactionID() types.TransactionID {
+	if len(so.RevisionTransactionSet) == 0 {
+		return types.TransactionID{}
+	}
+	return so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].ID()
+}
+
 // value returns the value of fulfilling the storage obligation to the host.
 func (so storageObligation) value() types.Currency {
 	return so.ContractCost.Add(so.PotentialDownloadRevenue).Add(so.PotentialStorageRevenue).Add(so.PotentialUploadRevenue).Add(so.RiskedCollateral)
@@ -1068,6 +1090,7 @@ func (h *Host) removeStorageObligation(so storageObligation, sos storageObligati
 	// objects with little purpose once storage proofs are no longer needed.
 	h.financialMetrics.ContractCount--
 	so.ObligationStatus = sos
+	so.ResolutionHeight = h.blockHeight
 	so.SectorRoots = nil
 	return h.db.Update(func(tx *bolt.Tx) error {
 		return putStorageObligation(tx, so)
@@ -714,6 +714,9 @@ func TestSingleSectorStorageObligationStack(t *testing.T) {
 	if !so.ProofConfirmed {
 		t.Fatal("storage obligation is not saying that the storage proof was confirmed on the blockchain")
 	}
+	if so.ProofHeight != ht.host.blockHeight || so.ProofTransactionID == (types.TransactionID{}) {
+		t.Fatal("storage proof height and transaction weren't recorded", so.ProofHeight, ht.host.blockHeight)
+	}
 
 	// Mine blocks until the storage proof has enough confirmations that the
 	// host will finalize the obligation.
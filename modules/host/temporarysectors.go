@@ -0,0 +1,79 @@
+package host
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+var (
+	// errTemporarySectorsNotAccepted is returned when a renter tries to store
+	// a sector without a contract on a host that doesn't accept that.
+	errTemporarySectorsNotAccepted = errors.New("host doesn't accept temporary sectors")
+
+	// errTemporarySectorExpiryTooHigh is returned when a renter tries to
+	// store a temporary sector for longer than the host's max duration.
+	errTemporarySectorExpiryTooHigh = errors.New("temporary sector expiry exceeds the host's max duration")
+
+	// errTemporarySectorExpired is returned when a renter tries to store a
+	// temporary sector with an expiry that is not in the future.
+	errTemporarySectorExpired = errors.New("temporary sector expiry must be in the future")
+
+	// errTemporaryStorageFull is returned when storing a temporary sector
+	// would exceed the host's temporary storage limit.
+	errTemporaryStorageFull = errors.New("host's temporary storage is full")
+)
+
+// AddTemporarySector stores a sector which isn't part of a contract until the
+// provided expiry. Storing a sector that is already stored as a temporary
+// sector extends its expiry.
+func (h *Host) AddTemporarySector(sectorRoot crypto.Hash, sectorData []byte, expiry types.BlockHeight) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	settings := h.settings
+	bh := h.blockHeight
+	h.mu.RUnlock()
+	if !settings.AcceptingTemporarySectors {
+		return errTemporarySectorsNotAccepted
+	}
+	if expiry <= bh {
+		return errTemporarySectorExpired
+	}
+	if expiry > bh+settings.MaxDuration {
+		return errTemporarySectorExpiryTooHigh
+	}
+
+	// Check the limit and add the sector while holding the lock to prevent
+	// concurrent adds from exceeding the limit.
+	h.staticTemporarySectorMu.Lock()
+	defer h.staticTemporarySectorMu.Unlock()
+	if (h.StorageManager.TemporarySectors()+1)*modules.SectorSize > settings.MaxTemporaryStorage {
+		return errTemporaryStorageFull
+	}
+	return h.StorageManager.AddTemporarySector(sectorRoot, sectorData, expiry)
+}
+
+// threadedRemoveExpiredTemporarySectors removes the temporary sectors that
+// expired at or before the provided height.
+func (h *Host) threadedRemoveExpiredTemporarySectors(height types.BlockHeight) {
+	err := h.tg.Add()
+	if err != nil {
+		return
+	}
+	defer h.tg.Done()
+
+	n, err := h.StorageManager.RemoveExpiredTemporarySectors(height)
+	if err != nil {
+		h.log.Println("ERROR: failed to remove expired temporary sectors:", err)
+		return
+	}
+	if n > 0 {
+		h.log.Debugf("Removed %v expired temporary sectors at height %v", n, height)
+	}
+}
@@ -0,0 +1,84 @@
+package host
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestAddTemporarySector checks that the host only accepts temporary sectors
+// if enabled, within its limits and that they are removed once they expire.
+func TestAddTemporarySector(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := ht.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+	h := ht.host
+	bh := h.BlockHeight()
+
+	// Temporary sectors are not accepted by default.
+	data := fastrand.Bytes(int(modules.SectorSize))
+	root := crypto.MerkleRoot(data)
+	if err := h.AddTemporarySector(root, data, bh+1); !errors.Is(err, errTemporarySectorsNotAccepted) {
+		t.Fatal("expected errTemporarySectorsNotAccepted but got", err)
+	}
+
+	// Accept a single temporary sector.
+	settings := h.InternalSettings()
+	settings.AcceptingTemporarySectors = true
+	settings.MaxTemporaryStorage = modules.SectorSize
+	if err := h.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// The expiry needs to be in the future and within the max duration.
+	if err := h.AddTemporarySector(root, data, bh); !errors.Is(err, errTemporarySectorExpired) {
+		t.Fatal("expected errTemporarySectorExpired but got", err)
+	}
+	if err := h.AddTemporarySector(root, data, bh+settings.MaxDuration+1); !errors.Is(err, errTemporarySectorExpiryTooHigh) {
+		t.Fatal("expected errTemporarySectorExpiryTooHigh but got", err)
+	}
+	if err := h.AddTemporarySector(root, data, bh+1); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second sector exceeds the limit.
+	data2 := fastrand.Bytes(int(modules.SectorSize))
+	if err := h.AddTemporarySector(crypto.MerkleRoot(data2), data2, bh+1); !errors.Is(err, errTemporaryStorageFull) {
+		t.Fatal("expected errTemporaryStorageFull but got", err)
+	}
+
+	// The price table reports the temporary storage price.
+	if pt := h.staticPriceTables.managedCurrent(); !pt.TemporaryStoreCost.Equals(settings.MinTemporaryStoragePrice) {
+		t.Fatal("unexpected temporary store cost", pt.TemporaryStoreCost)
+	}
+
+	// Mine a block to expire the sector.
+	if _, err := ht.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		if n := h.StorageManager.TemporarySectors(); n != 0 {
+			return errors.New("temporary sector didn't expire")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -38,6 +38,8 @@ func (h *Host) initRescan() error {
 			so.OriginConfirmed = false
 			so.RevisionConfirmed = false
 			so.ProofConfirmed = false
+			so.ProofHeight = 0
+			so.ProofTransactionID = types.TransactionID{}
 			allObligations = append(allObligations, so)
 			soBytes, err = json.Marshal(so)
 			if err != nil {
@@ -191,6 +193,8 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 							continue
 						}
 						so.ProofConfirmed = false
+						so.ProofHeight = 0
+						so.ProofTransactionID = types.TransactionID{}
 						err = putStorageObligation(tx, so)
 						if err != nil {
 							continue
@@ -202,6 +206,12 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 		h.blockHeight = cc.InitialHeight()
 		for _, block := range cc.AppliedBlocks {
+			// Determine the height of the block for the storage proofs.
+			blockHeight := h.blockHeight
+			if block.ID() != types.GenesisID {
+				blockHeight++
+			}
+
 			// Look for transactions relevant to open storage obligations.
 			for _, txn := range block.Transactions {
 				// Check for file contracts.
@@ -255,6 +265,8 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 							continue
 						}
 						so.ProofConfirmed = true
+						so.ProofHeight = blockHeight
+						so.ProofTransactionID = txn.ID()
 						err = putStorageObligation(tx, so)
 						if err != nil {
 							continue
@@ -300,6 +312,11 @@ func (h *Host) ProcessConsensusChange(cc modules.ConsensusChange) {
 		go h.threadedHandleActionItem(actionItems[i])
 	}
 
+	// Remove the temporary sectors that expired.
+	if h.blockHeight > oldHeight {
+		go h.threadedRemoveExpiredTemporarySectors(h.blockHeight)
+	}
+
 	// Update the host's recent change pointer to point to the most recent
 	// change.
 	h.recentChange = cc.ID
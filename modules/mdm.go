@@ -88,6 +88,10 @@ const (
 	// MDMTimeReadSector is the time for executing a 'ReadSector' instruction.
 	MDMTimeReadSector = 1000
 
+	// MDMTimeStoreSector is the time for executing a 'StoreSector'
+	// instruction.
+	MDMTimeStoreSector = 10000
+
 	// MDMTimeRevision is the time for executing a 'Revision' instruction.
 	MDMTimeRevision = 1
 
@@ -97,6 +101,10 @@ const (
 	// MDMTimeWriteSector is the time for executing a 'WriteSector' instruction.
 	MDMTimeWriteSector = 10000
 
+	// MDMTimeUpdateSector is the time for executing an 'UpdateSector'
+	// instruction. The sector is read and written in full.
+	MDMTimeUpdateSector = MDMTimeReadSector + MDMTimeWriteSector
+
 	// MDMTimeUpdateRegistry is the time for executing an 'UpdateRegistry'
 	// instruction.
 	MDMTimeUpdateRegistry = 10000
@@ -121,6 +129,12 @@ const (
 	// instruction.
 	RPCIReadSectorLen = 25
 
+	// RPCIReadSectorRangeLen is the expected length of the 'Args' of a
+	// ReadSectorRange instruction.
+	// lengthOffset + offsetOffset + numRootsOffset + rootsOffset + merkle
+	// proof flag = 4 * 8 + 1 bytes = 33 byte
+	RPCIReadSectorRangeLen = 33
+
 	// RPCIReadOffsetLen is the expected length of the 'Args' of a ReadOffset
 	// instruction.
 	RPCIReadOffsetLen = 17
@@ -133,6 +147,16 @@ const (
 	// instructon.
 	RPCISwapSectorLen = 17 // 2 uint64 offsets + merkle proof flag
 
+	// RPCIStoreSectorLen is the expected length of the 'Args' of a
+	// StoreSector instruction.
+	RPCIStoreSectorLen = 16 // dataOffset + durationOffset
+
+	// RPCIUpdateSectorLen is the expected length of the 'Args' of an
+	// UpdateSector instruction.
+	// offsetOffset + lengthOffset + dataOffset + merkle proof flag = 3 * 8 + 1
+	// bytes = 25 byte
+	RPCIUpdateSectorLen = 25
+
 	// RPCIUpdateRegistryLen is the expected length of the 'Args' of an
 	// UpdateRegistry instruction.
 	// tweakOffset + revisionOffset + signatureOffset + pubKeyOffset +
@@ -168,6 +192,13 @@ const (
 	RPCIReadRegistryEIDWithVersionLen = 10
 )
 
+const (
+	// MDMReadSectorRangeMaxLength is the maximum number of bytes a single
+	// 'ReadSectorRange' instruction may return. It corresponds to reading 16
+	// full sectors.
+	MDMReadSectorRangeMaxLength = 1 << 26 // 64 MiB
+)
+
 var (
 	// MDMProgramWriteResponseTime defines the amount of time that the host
 	// allows to write the output of an instruction to the stream. The time is
@@ -195,12 +226,22 @@ var (
 	// SpecifierReadSector is the specifier for the ReadSector instruction.
 	SpecifierReadSector = InstructionSpecifier{'R', 'e', 'a', 'd', 'S', 'e', 'c', 't', 'o', 'r'}
 
+	// SpecifierReadSectorRange is the specifier for the ReadSectorRange
+	// instruction.
+	SpecifierReadSectorRange = InstructionSpecifier{'R', 'e', 'a', 'd', 'S', 'e', 'c', 't', 'o', 'r', 'R', 'a', 'n', 'g', 'e'}
+
 	// SpecifierRevision is the specifier for the Revision instruction.
 	SpecifierRevision = InstructionSpecifier{'R', 'e', 'v', 'i', 's', 'i', 'o', 'n'}
 
 	// SpecifierSwapSector is the specifier for the SwapSector instruction.
 	SpecifierSwapSector = InstructionSpecifier{'S', 'w', 'a', 'p', 'S', 'e', 'c', 't', 'o', 'r'}
 
+	// SpecifierStoreSector is the specifier for the StoreSector instruction.
+	SpecifierStoreSector = InstructionSpecifier{'S', 't', 'o', 'r', 'e', 'S', 'e', 'c', 't', 'o', 'r'}
+
+	// SpecifierUpdateSector is the specifier for the UpdateSector instruction.
+	SpecifierUpdateSector = InstructionSpecifier{'U', 'p', 'd', 'a', 't', 'e', 'S', 'e', 'c', 't', 'o', 'r'}
+
 	// SpecifierUpdateRegistry is the specifier for the UpdateRegistry
 	// instruction.
 	SpecifierUpdateRegistry = InstructionSpecifier{'U', 'p', 'd', 'a', 't', 'e', 'R', 'e', 'g', 'i', 's', 't', 'r', 'y'}
@@ -310,17 +351,42 @@ func MDMReadCost(pt *RPCPriceTable, readLength uint64) types.Currency {
 	return cost
 }
 
+// MDMReadSectorRangeCost is the cost of executing a 'ReadSectorRange'
+// instruction. It is the same as executing one 'Read' instruction per root.
+func MDMReadSectorRangeCost(pt *RPCPriceTable, readLength, numRoots uint64) types.Currency {
+	return MDMReadCost(pt, readLength).Mul64(numRoots)
+}
+
 // MDMRevisionCost is the cost of executing a 'Revision' instruction.
 func MDMRevisionCost(pt *RPCPriceTable) types.Currency {
 	cost := pt.RevisionBaseCost
 	return cost
 }
 
+// MDMStoreSectorCost is the cost of executing a 'StoreSector' instruction.
+// The sector is paid for upfront for the whole duration. Since the sector is
+// stored as soon as the instruction is executed, the storage cost isn't
+// refunded if the program fails afterwards.
+func MDMStoreSectorCost(pt *RPCPriceTable, duration types.BlockHeight) (types.Currency, types.Currency) {
+	// Cost for writing the Data.
+	writeCost := MDMWriteCost(pt, SectorSize)
+	// Cost of storing for the duration.
+	storeCost := pt.TemporaryStoreCost.Mul64(SectorSize).Mul64(uint64(duration))
+	return writeCost.Add(storeCost), types.ZeroCurrency
+}
+
 // MDMSwapSectorCost is the cost of executing a 'SwapSector' instruction.
 func MDMSwapSectorCost(pt *RPCPriceTable) types.Currency {
 	return pt.SwapSectorCost
 }
 
+// MDMUpdateSectorCost is the cost of executing an 'UpdateSector' instruction.
+// Since the sector is rewritten, it is the cost of reading and writing a full
+// sector.
+func MDMUpdateSectorCost(pt *RPCPriceTable) types.Currency {
+	return MDMReadCost(pt, SectorSize).Add(MDMWriteCost(pt, SectorSize))
+}
+
 // V154MDMUpdateRegistryCost is the cost of executing a 'UpdateRegistry'
 // instruction in host versions 1.5.4 and below.
 func V154MDMUpdateRegistryCost(pt *RPCPriceTable) (_, _ types.Currency) {
@@ -435,12 +501,24 @@ func MDMRevisionMemory() uint64 {
 	return 0 // 'Revision' doesn't hold on to any memory beyond the lifetime of the instruction.
 }
 
+// MDMStoreSectorMemory returns the additional memory consumption of a
+// 'StoreSector' instruction.
+func MDMStoreSectorMemory() uint64 {
+	return 0 // 'StoreSector' writes the sector to disk right away.
+}
+
 // MDMSwapSectorMemory returns the additional memory consumption of a
 // 'SwapSector' instruction.
 func MDMSwapSectorMemory() uint64 {
 	return 0 // 'SwapSector' doesn't hold on to any memory beyond the lifetime of the instruction.
 }
 
+// MDMUpdateSectorMemory returns the additional memory consumption of an
+// 'UpdateSector' instruction.
+func MDMUpdateSectorMemory() uint64 {
+	return SectorSize // The updated sector is added to the program's memory until the program is finalized.
+}
+
 // MDMUpdateRegistryMemory returns the additional memory consumption of a
 // 'UpdateRegistry' instruction.
 func MDMUpdateRegistryMemory() uint64 {
@@ -466,6 +544,12 @@ func MDMMemoryCost(pt *RPCPriceTable, usedMemory, time uint64) types.Currency {
 	return pt.MemoryTimeCost.Mul64(usedMemory * time)
 }
 
+// MDMReadSectorRangeTime returns the time for a 'ReadSectorRange' instruction
+// given the number of roots to read from.
+func MDMReadSectorRangeTime(numRoots uint64) uint64 {
+	return MDMTimeReadSector * numRoots
+}
+
 // MDMDropSectorsTime returns the time for a `DropSectors` instruction given
 // `numSectorsDropped`.
 func MDMDropSectorsTime(numSectorsDropped uint64) uint64 {
@@ -508,6 +592,18 @@ func MDMSwapSectorCollateral() types.Currency {
 	return types.ZeroCurrency
 }
 
+// MDMStoreSectorCollateral returns the additional collateral a 'StoreSector'
+// instruction requires the host to put up.
+func MDMStoreSectorCollateral() types.Currency {
+	return types.ZeroCurrency // no contract is involved
+}
+
+// MDMUpdateSectorCollateral returns the additional collateral an
+// 'UpdateSector' instruction requires the host to put up.
+func MDMUpdateSectorCollateral() types.Currency {
+	return types.ZeroCurrency // the contract size doesn't change
+}
+
 // MDMUpdateRegistryCollateral returns the additional collateral a
 // 'UpdateRegistry' instruction requires the host to put up.
 func MDMUpdateRegistryCollateral() types.Currency {
@@ -531,9 +627,14 @@ func (p Program) ReadOnly() bool {
 		case SpecifierHasSector:
 		case SpecifierReadOffset:
 		case SpecifierReadSector:
+		case SpecifierReadSectorRange:
 		case SpecifierRevision:
+		case SpecifierStoreSector:
+			// considered read-only cause it doesn't update a contract
 		case SpecifierSwapSector:
 			return false
+		case SpecifierUpdateSector:
+			return false
 		case SpecifierUpdateRegistry:
 			// considered read-only cause it doesn't update a contract
 		case SpecifierReadRegistry:
@@ -560,10 +661,14 @@ func (p Program) RequiresSnapshot() bool {
 		case SpecifierReadOffset:
 			return true
 		case SpecifierReadSector:
+		case SpecifierReadSectorRange:
 		case SpecifierRevision:
 			return true
+		case SpecifierStoreSector:
 		case SpecifierSwapSector:
 			return true
+		case SpecifierUpdateSector:
+			return true
 		case SpecifierUpdateRegistry:
 		case SpecifierReadRegistry:
 		case SpecifierReadRegistryEID:
@@ -144,6 +144,41 @@ func (pb *ProgramBuilder) AddReadSectorInstruction(length, offset uint64, merkle
 	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
 }
 
+// AddReadSectorRangeInstruction adds a ReadSectorRange instruction to the
+// program. It reads the same range from every one of the provided sectors.
+func (pb *ProgramBuilder) AddReadSectorRangeInstruction(length, offset uint64, merkleRoots []crypto.Hash, merkleProof bool) error {
+	numRoots := uint64(len(merkleRoots))
+	if numRoots == 0 {
+		return errors.New("AddReadSectorRangeInstruction: no merkle roots provided")
+	}
+	if length > SectorSize || length*numRoots > MDMReadSectorRangeMaxLength {
+		return fmt.Errorf("AddReadSectorRangeInstruction: total read length can't exceed %v bytes", MDMReadSectorRangeMaxLength)
+	}
+	// Compute the argument offsets.
+	lengthOffset := uint64(pb.programData.Len())
+	offsetOffset := lengthOffset + 8
+	numRootsOffset := offsetOffset + 8
+	rootsOffset := numRootsOffset + 8
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, length)
+	binary.Write(pb.programData, binary.LittleEndian, offset)
+	binary.Write(pb.programData, binary.LittleEndian, numRoots)
+	for _, root := range merkleRoots {
+		binary.Write(pb.programData, binary.LittleEndian, root[:])
+	}
+	// Create the instruction.
+	i := NewReadSectorRangeInstruction(lengthOffset, offsetOffset, numRootsOffset, rootsOffset, merkleProof)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMReadCollateral()
+	cost := MDMReadSectorRangeCost(pb.staticPT, length, numRoots)
+	memory := MDMReadMemory()
+	time := MDMReadSectorRangeTime(numRoots)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+	return nil
+}
+
 // AddRevisionInstruction adds a Revision instruction to the program.
 func (pb *ProgramBuilder) AddRevisionInstruction() {
 	// Compute the argument offsets.
@@ -181,6 +216,68 @@ func (pb *ProgramBuilder) AddSwapSectorInstruction(sector1Idx, sector2Idx uint64
 	pb.readonly = false
 }
 
+// AddStoreSectorInstruction adds a StoreSector instruction to the program. The
+// sector is stored by the host for the provided duration without being added
+// to a contract.
+func (pb *ProgramBuilder) AddStoreSectorInstruction(data []byte, duration types.BlockHeight) error {
+	if uint64(len(data)) != SectorSize {
+		return fmt.Errorf("expected stored data to have size %v but was %v", SectorSize, len(data))
+	}
+	if duration == 0 {
+		return errors.New("AddStoreSectorInstruction: duration can't be zero")
+	}
+	// Compute the argument offsets.
+	durationOffset := uint64(pb.programData.Len())
+	dataOffset := durationOffset + 8
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, uint64(duration))
+	binary.Write(pb.programData, binary.LittleEndian, data)
+	// Create the instruction.
+	i := NewStoreSectorInstruction(dataOffset, durationOffset)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMStoreSectorCollateral()
+	cost, refund := MDMStoreSectorCost(pb.staticPT, duration)
+	memory := MDMStoreSectorMemory()
+	time := uint64(MDMTimeStoreSector)
+	pb.addInstruction(collateral, cost, refund, memory, time)
+	return nil
+}
+
+// AddUpdateSectorInstruction adds an UpdateSector instruction to the program.
+// The data is written to the contract at the provided offset and must not
+// cross a sector boundary.
+func (pb *ProgramBuilder) AddUpdateSectorInstruction(offset uint64, data []byte, merkleProof bool) error {
+	length := uint64(len(data))
+	if length == 0 {
+		return errors.New("AddUpdateSectorInstruction: data can't be empty")
+	}
+	if offset%SectorSize+length > SectorSize {
+		return fmt.Errorf("AddUpdateSectorInstruction: update of %v bytes at offset %v crosses a sector boundary", length, offset)
+	}
+	// Compute the argument offsets.
+	offsetOffset := uint64(pb.programData.Len())
+	lengthOffset := offsetOffset + 8
+	dataOffset := lengthOffset + 8
+	// Extend the programData.
+	binary.Write(pb.programData, binary.LittleEndian, offset)
+	binary.Write(pb.programData, binary.LittleEndian, length)
+	binary.Write(pb.programData, binary.LittleEndian, data)
+	// Create the instruction.
+	i := NewUpdateSectorInstruction(offsetOffset, lengthOffset, dataOffset, merkleProof)
+	// Append instruction
+	pb.program = append(pb.program, i)
+	// Update cost, collateral and memory usage.
+	collateral := MDMUpdateSectorCollateral()
+	cost := MDMUpdateSectorCost(pb.staticPT)
+	memory := MDMUpdateSectorMemory()
+	time := uint64(MDMTimeUpdateSector)
+	pb.addInstruction(collateral, cost, types.ZeroCurrency, memory, time)
+	pb.readonly = false
+	return nil
+}
+
 // V156AddUpdateRegistryInstruction adds an UpdateRegistry instruction to the
 // program.
 func (pb *ProgramBuilder) V156AddUpdateRegistryInstruction(spk types.SiaPublicKey, rv SignedRegistryValue) error {
@@ -575,6 +672,48 @@ func NewReadSectorInstruction(lengthOffset, offsetOffset, merkleRootOffset uint6
 	return i
 }
 
+// NewReadSectorRangeInstruction creates a modules.Instruction from arguments.
+func NewReadSectorRangeInstruction(lengthOffset, offsetOffset, numRootsOffset, rootsOffset uint64, merkleProof bool) Instruction {
+	i := Instruction{
+		Specifier: SpecifierReadSectorRange,
+		Args:      make([]byte, RPCIReadSectorRangeLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], lengthOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], offsetOffset)
+	binary.LittleEndian.PutUint64(i.Args[16:24], numRootsOffset)
+	binary.LittleEndian.PutUint64(i.Args[24:32], rootsOffset)
+	if merkleProof {
+		i.Args[32] = 1
+	}
+	return i
+}
+
+// NewStoreSectorInstruction creates a modules.Instruction from arguments.
+func NewStoreSectorInstruction(dataOffset, durationOffset uint64) Instruction {
+	i := Instruction{
+		Specifier: SpecifierStoreSector,
+		Args:      make([]byte, RPCIStoreSectorLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], dataOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], durationOffset)
+	return i
+}
+
+// NewUpdateSectorInstruction creates a modules.Instruction from arguments.
+func NewUpdateSectorInstruction(offsetOffset, lengthOffset, dataOffset uint64, merkleProof bool) Instruction {
+	i := Instruction{
+		Specifier: SpecifierUpdateSector,
+		Args:      make([]byte, RPCIUpdateSectorLen),
+	}
+	binary.LittleEndian.PutUint64(i.Args[:8], offsetOffset)
+	binary.LittleEndian.PutUint64(i.Args[8:16], lengthOffset)
+	binary.LittleEndian.PutUint64(i.Args[16:24], dataOffset)
+	if merkleProof {
+		i.Args[24] = 1
+	}
+	return i
+}
+
 // NewSwapSectorInstruction creates a modules.Instruction from arguments.
 func NewSwapSectorInstruction(sector1Offset, sector2Offset uint64, merkleProof bool) Instruction {
 	i := Instruction{
@@ -194,6 +194,11 @@ func (aid *AccountID) UnmarshalSia(r io.Reader) error {
 	return err
 }
 
+// String returns the account id as a string.
+func (aid AccountID) String() string {
+	return aid.spk
+}
+
 // SPK returns the account id as a types.SiaPublicKey.
 func (aid AccountID) SPK() (spk types.SiaPublicKey) {
 	if aid.IsZeroAccount() {
@@ -5,7 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -229,6 +233,14 @@ const (
 	// StreamUploadSize is the size of downloaded in a single streaming upload
 	// request.
 	StreamUploadSize = uint64(1 << 16) // 64 KiB
+
+	// DefaultVerificationSamples is the default number of pieces per host and
+	// file that are sampled when verifying the integrity of a file.
+	DefaultVerificationSamples = 3
+
+	// DefaultHostPreferenceMultiplier is the default factor by which the
+	// score of a host in a preferred region or ASN is multiplied.
+	DefaultHostPreferenceMultiplier = 10
 )
 
 type (
@@ -236,6 +248,9 @@ type (
 	// download history.
 	DownloadID string
 
+	// UploadID is a unique identifier used to identify resumable uploads.
+	UploadID string
+
 	// CombinedChunkID is a unique identifier for a combined chunk which makes up
 	// part of its filename on disk.
 	CombinedChunkID string
@@ -302,6 +317,34 @@ type Allowance struct {
 	MaxSectorAccessPrice      types.Currency `json:"maxsectoraccessprice"`
 	MaxStoragePrice           types.Currency `json:"maxstorageprice"`
 	MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+
+	// The following fields cap the amount of money that can be spent on each
+	// spending category within a single period. A zero value means that the
+	// category is not capped. Once a cap is reached, the workers and the
+	// contractor stop spending money on that category until the next period.
+	// Repairs are not subject to the upload, download and storage caps. Once
+	// the fund account cap is reached, user downloads stop and ephemeral
+	// accounts are only refilled for repairs.
+	MaxUploadSpending      types.Currency `json:"maxuploadspending"`
+	MaxDownloadSpending    types.Currency `json:"maxdownloadspending"`
+	MaxStorageSpending     types.Currency `json:"maxstoragespending"`
+	MaxFeeSpending         types.Currency `json:"maxfeespending"`
+	MaxFundAccountSpending types.Currency `json:"maxfundaccountspending"`
+
+	// SpendingAlertThreshold is the fraction of a spending cap at which an
+	// alert is registered to warn the user that the cap is about to be
+	// reached. A zero value disables the warning.
+	SpendingAlertThreshold float64 `json:"spendingalertthreshold"`
+}
+
+// SpendingCapStatus reports which of the allowance's spending caps have been
+// reached in the current period.
+type SpendingCapStatus struct {
+	UploadCapReached      bool `json:"uploadcapreached"`
+	DownloadCapReached    bool `json:"downloadcapreached"`
+	StorageCapReached     bool `json:"storagecapreached"`
+	FeeCapReached         bool `json:"feecapreached"`
+	FundAccountCapReached bool `json:"fundaccountcapreached"`
 }
 
 // Active returns true if and only if this allowance has been set in the
@@ -436,6 +479,196 @@ type FileUploadParams struct {
 	CipherKey crypto.CipherKey
 }
 
+// ResumableUploadInfo provides information about a resumable upload.
+type ResumableUploadInfo struct {
+	// ID identifies the upload in subsequent calls.
+	ID UploadID `json:"uploadid"`
+
+	// SiaPath is the path of the siafile the data is uploaded to.
+	SiaPath SiaPath `json:"siapath"`
+
+	// Offset is the number of bytes the renter has committed so far. The next
+	// write needs to start at this offset.
+	Offset uint64 `json:"offset"`
+
+	// ChunkSize is the size of a chunk of the siafile. Data is only uploaded
+	// to hosts in full chunks; bytes beyond the last full chunk are staged on
+	// disk until the chunk is complete or the upload is finalized.
+	ChunkSize uint64 `json:"chunksize"`
+}
+
+// VerificationReport describes the outcome of verifying the integrity of one
+// or more files by fetching random samples of their data from the hosts.
+type VerificationReport struct {
+	// FilesVerified is the number of files that were sampled.
+	FilesVerified uint64 `json:"filesverified"`
+
+	// Hosts contains the results of the sampled hosts.
+	Hosts []HostVerification `json:"hosts"`
+
+	// CorruptPieces contains the sampled pieces for which the host returned
+	// data that failed to verify against their merkle root or which the host
+	// no longer stores.
+	CorruptPieces []UnreadablePiece `json:"corruptpieces"`
+
+	// UnverifiedPieces contains the sampled pieces which couldn't be
+	// retrieved for other reasons, e.g. because the host was offline or the
+	// read timed out.
+	UnverifiedPieces []UnreadablePiece `json:"unverifiedpieces"`
+
+	// PiecesRemoved is the number of corrupt pieces which were removed from
+	// their files to have them repaired.
+	PiecesRemoved uint64 `json:"piecesremoved"`
+}
+
+// HostVerification contains the verification results of a single host.
+type HostVerification struct {
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	Samples       uint64             `json:"samples"`
+	Failures      uint64             `json:"failures"`
+	Unverified    uint64             `json:"unverified"`
+
+	// TrustScore is a value between 0 and 1 which reflects the share of
+	// successful samples of the host across all verifications, weighing
+	// recent samples more heavily. Unverified samples are not taken into
+	// account.
+	TrustScore float64 `json:"trustscore"`
+}
+
+// UnreadablePiece describes a piece which couldn't be read from a host or
+// failed to verify during verification.
+type UnreadablePiece struct {
+	SiaPath       SiaPath            `json:"siapath"`
+	ChunkIndex    uint64             `json:"chunkindex"`
+	PieceIndex    uint64             `json:"pieceindex"`
+	HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+	Error         string             `json:"error"`
+}
+
+// BulkOperationType is the type of a bulk operation on the renter's
+// filesystem.
+type BulkOperationType string
+
+const (
+	// BulkOperationDelete deletes files or directories.
+	BulkOperationDelete BulkOperationType = "delete"
+
+	// BulkOperationRename moves files or directories to a new siapath.
+	BulkOperationRename BulkOperationType = "rename"
+
+	// BulkOperationSetLocalPath changes the local path of files.
+	BulkOperationSetLocalPath BulkOperationType = "setlocalpath"
+
+	// BulkOperationSetStuck sets the 'stuck' status of files.
+	BulkOperationSetStuck BulkOperationType = "setstuck"
+
+	// BulkOperationUpload uploads a local file or the files within a local
+	// directory.
+	BulkOperationUpload BulkOperationType = "upload"
+)
+
+var (
+	// ErrBulkGlobAndPrefix is returned if a bulk operation specifies both a
+	// glob and a prefix.
+	ErrBulkGlobAndPrefix = errors.New("a bulk operation can't select files by glob and prefix at the same time")
+
+	// ErrUnknownBulkOperation is returned if the type of a bulk operation is
+	// unknown.
+	ErrUnknownBulkOperation = errors.New("unknown bulk operation type")
+)
+
+// BulkOperation is a single operation of a bulk request against the renter's
+// filesystem.
+//
+// Without a Glob or Prefix, the operation acts on the file or directory at
+// SiaPath. Deleting or renaming a directory acts on the directory itself while
+// the other operations act on all files within the directory. With a Glob or
+// Prefix, the operation acts on all files within the directory at SiaPath
+// whose path relative to SiaPath matches.
+type BulkOperation struct {
+	Type    BulkOperationType `json:"type"`
+	SiaPath SiaPath           `json:"siapath"`
+
+	// Glob selects files by matching their relative path against a pattern
+	// using the syntax of path.Match. Prefix selects files whose relative
+	// path starts with the prefix.
+	Glob   string `json:"glob,omitempty"`
+	Prefix string `json:"prefix,omitempty"`
+
+	// Recursive matches the Glob against the names of the files at any depth
+	// below SiaPath instead of their relative paths.
+	Recursive bool `json:"recursive,omitempty"`
+
+	// NewSiaPath is the destination of a rename. Selected files keep their
+	// path relative to SiaPath within NewSiaPath.
+	NewSiaPath SiaPath `json:"newsiapath"`
+
+	// LocalPath is the new local path of a 'setlocalpath' operation or the
+	// source of an 'upload' operation. Files within a directory are mapped to
+	// their relative path within LocalPath.
+	LocalPath string `json:"localpath,omitempty"`
+
+	// Stuck is the new 'stuck' status of a 'setstuck' operation.
+	Stuck bool `json:"stuck,omitempty"`
+
+	// Force overwrites existing files during an 'upload' operation.
+	Force bool `json:"force,omitempty"`
+}
+
+// BulkOperationResult is the outcome of a bulk operation on a single file or
+// directory.
+type BulkOperationResult struct {
+	// Operation is the index of the operation within the bulk request.
+	Operation int `json:"operation"`
+
+	SiaPath    SiaPath `json:"siapath"`
+	NewSiaPath SiaPath `json:"newsiapath"`
+	LocalPath  string  `json:"localpath,omitempty"`
+
+	// Error is empty if the operation succeeded.
+	Error string `json:"error,omitempty"`
+}
+
+// IsSelection returns whether the operation selects files by glob or prefix.
+func (op BulkOperation) IsSelection() bool {
+	return op.Glob != "" || op.Prefix != ""
+}
+
+// Selects returns whether a file with the provided path relative to the
+// operation's SiaPath is selected by the operation. An operation without glob
+// or prefix selects all files.
+func (op BulkOperation) Selects(relPath string) bool {
+	if op.Glob != "" && op.Recursive {
+		relPath = path.Base(relPath)
+	}
+	if op.Glob != "" {
+		match, err := path.Match(op.Glob, relPath)
+		return err == nil && match
+	}
+	return strings.HasPrefix(relPath, op.Prefix)
+}
+
+// Validate checks the operation for errors which can be detected without
+// touching the filesystem.
+func (op BulkOperation) Validate() error {
+	switch op.Type {
+	case BulkOperationDelete, BulkOperationRename, BulkOperationSetStuck:
+	case BulkOperationSetLocalPath, BulkOperationUpload:
+		if !filepath.IsAbs(op.LocalPath) {
+			return errors.New("'localpath' must be an absolute path")
+		}
+	default:
+		return errors.AddContext(ErrUnknownBulkOperation, string(op.Type))
+	}
+	if op.Glob != "" && op.Prefix != "" {
+		return ErrBulkGlobAndPrefix
+	}
+	if _, err := path.Match(op.Glob, ""); err != nil {
+		return errors.AddContext(err, "invalid glob")
+	}
+	return nil
+}
+
 // FileInfo provides information about a file.
 type FileInfo struct {
 	AccessTime       time.Time         `json:"accesstime"`
@@ -508,6 +741,15 @@ type HostDBEntry struct {
 
 	LastHistoricUpdate types.BlockHeight `json:"lasthistoricupdate"`
 
+	// Performance measurements of the host. The latencies are measured
+	// during scans, the throughputs in bytes per second are measured during
+	// uploads and downloads. All of them are exponential moving averages and
+	// zero if no measurement was taken yet.
+	RTT                time.Duration `json:"rtt"`
+	PriceTableLatency  time.Duration `json:"pricetablelatency"`
+	UploadThroughput   float64       `json:"uploadthroughput"`
+	DownloadThroughput float64       `json:"downloadthroughput"`
+
 	// Measurements related to the IP subnet mask.
 	IPNets          []string  `json:"ipnets"`
 	LastIPNetChange time.Time `json:"lastipnetchange"`
@@ -549,6 +791,129 @@ type HostScoreBreakdown struct {
 	StorageRemainingAdjustment float64 `json:"storageremainingadjustment"`
 	UptimeAdjustment           float64 `json:"uptimeadjustment"`
 	VersionAdjustment          float64 `json:"versionadjustment"`
+
+	// PerformanceAdjustment reflects the measured latencies and throughputs
+	// of the host.
+	PerformanceAdjustment float64 `json:"performanceadjustment"`
+
+	// PolicyAdjustment reflects the price caps, minimum requirements and
+	// location preferences of the hostdb's selection policy.
+	PolicyAdjustment float64 `json:"policyadjustment"`
+}
+
+// HostSelectionPolicy is a user-configurable policy which controls how the
+// hostdb scores hosts on top of the default scoring.
+type HostSelectionPolicy struct {
+	// Weights are the exponents applied to the individual adjustments of the
+	// host score. A weight of 1 leaves the adjustment unchanged, a weight of
+	// 0 ignores it and larger weights emphasize it.
+	Weights HostAdjustmentWeights `json:"weights"`
+
+	// The maximum prices a host may charge. Hosts exceeding a cap are never
+	// selected for new contracts and existing contracts with them lose their
+	// utility. A zero value disables the cap.
+	MaxContractPrice          types.Currency `json:"maxcontractprice"`
+	MaxDownloadBandwidthPrice types.Currency `json:"maxdownloadbandwidthprice"`
+	MaxStoragePrice           types.Currency `json:"maxstorageprice"`
+	MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+
+	// MinAge is the number of blocks since its announcement a host needs to
+	// have been seen for. MinUptime is the minimum ratio of successful
+	// scans. Hosts not meeting the requirements are excluded like hosts
+	// exceeding a price cap.
+	MinAge    types.BlockHeight `json:"minage"`
+	MinUptime float64           `json:"minuptime"`
+
+	// LocationDatabase is the path to an offline database which maps IP
+	// networks to their region and ASN. It is required for preferring or
+	// excluding regions and ASNs.
+	LocationDatabase string `json:"locationdatabase"`
+
+	// Hosts in excluded regions or ASNs are excluded like hosts exceeding a
+	// price cap. Hosts in preferred regions or ASNs have their score multiplied by the
+	// PreferenceMultiplier.
+	ExcludedASNs         []uint32 `json:"excludedasns"`
+	ExcludedRegions      []string `json:"excludedregions"`
+	PreferredASNs        []uint32 `json:"preferredasns"`
+	PreferredRegions     []string `json:"preferredregions"`
+	PreferenceMultiplier float64  `json:"preferencemultiplier"`
+
+	// MaxHostsPerASN and MaxHostsPerRegion limit the number of hosts within
+	// the same ASN or region the renter forms contracts with. MinPieceRegions
+	// is the minimum number of distinct regions the pieces of a chunk are
+	// uploaded to. A zero value disables the rule.
+	MaxHostsPerASN    uint64 `json:"maxhostsperasn"`
+	MaxHostsPerRegion uint64 `json:"maxhostsperregion"`
+	MinPieceRegions   uint64 `json:"minpieceregions"`
+}
+
+// HostLocation is the location of a host's address according to the location
+// database of the host selection policy.
+type HostLocation struct {
+	ASN    uint32 `json:"asn"`
+	Region string `json:"region"`
+}
+
+// HostAdjustmentWeights contains the weights of the adjustments which make up
+// a host's score.
+type HostAdjustmentWeights struct {
+	AcceptContract   float64 `json:"acceptcontract"`
+	Age              float64 `json:"age"`
+	BasePrice        float64 `json:"baseprice"`
+	Collateral       float64 `json:"collateral"`
+	Duration         float64 `json:"duration"`
+	Interaction      float64 `json:"interaction"`
+	Performance      float64 `json:"performance"`
+	Price            float64 `json:"price"`
+	StorageRemaining float64 `json:"storageremaining"`
+	Uptime           float64 `json:"uptime"`
+	Version          float64 `json:"version"`
+}
+
+// DefaultHostSelectionPolicy returns the policy which leaves the default
+// scoring of the hostdb unchanged.
+func DefaultHostSelectionPolicy() HostSelectionPolicy {
+	return HostSelectionPolicy{
+		Weights: HostAdjustmentWeights{
+			AcceptContract:   1,
+			Age:              1,
+			BasePrice:        1,
+			Collateral:       1,
+			Duration:         1,
+			Interaction:      1,
+			Performance:      1,
+			Price:            1,
+			StorageRemaining: 1,
+			Uptime:           1,
+			Version:          1,
+		},
+		PreferenceMultiplier: DefaultHostPreferenceMultiplier,
+	}
+}
+
+// Validate checks the policy for invalid values.
+func (p HostSelectionPolicy) Validate() error {
+	w := p.Weights
+	for _, weight := range []float64{w.AcceptContract, w.Age, w.BasePrice, w.Collateral, w.Duration, w.Interaction, w.Performance, w.Price, w.StorageRemaining, w.Uptime, w.Version} {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return errors.New("adjustment weights must be finite and non-negative")
+		}
+	}
+	if p.MinUptime < 0 || p.MinUptime > 1 {
+		return errors.New("minimum uptime must be between 0 and 1")
+	}
+	if p.PreferenceMultiplier < 1 || math.IsInf(p.PreferenceMultiplier, 0) {
+		return errors.New("preference multiplier must be at least 1")
+	}
+	usesLocations := len(p.ExcludedASNs) > 0 || len(p.ExcludedRegions) > 0 || len(p.PreferredASNs) > 0 || len(p.PreferredRegions) > 0
+	if usesLocations && p.LocationDatabase == "" {
+		return errors.New("a location database is required to prefer or exclude regions and ASNs")
+	}
+	usesDiversity := p.MaxHostsPerASN > 0 || p.MaxHostsPerRegion > 0 || p.MinPieceRegions > 0
+	if usesDiversity && p.LocationDatabase == "" {
+		return errors.New("a location database is required for diversity rules")
+	}
+	return nil
 }
 
 // MemoryStatus contains information about the status of the memory managers in
@@ -615,12 +980,23 @@ type RenterPriceEstimation struct {
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
 	Allowance        Allowance     `json:"allowance"`
+	ChunkCacheSize   uint64        `json:"chunkcachesize"`
 	IPViolationCheck bool          `json:"ipviolationcheck"`
 	MaxUploadSpeed   int64         `json:"maxuploadspeed"`
 	MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
 	UploadsStatus    UploadsStatus `json:"uploadsstatus"`
 }
 
+// ChunkCacheStatus contains information about the renter's on-disk chunk
+// cache.
+type ChunkCacheStatus struct {
+	Hits      uint64 `json:"hits"`
+	MaxSize   uint64 `json:"maxsize"`
+	Misses    uint64 `json:"misses"`
+	NumChunks uint64 `json:"numchunks"`
+	Size      uint64 `json:"size"`
+}
+
 // UploadsStatus contains information about the Renter's Uploads
 type UploadsStatus struct {
 	Paused       bool      `json:"paused"`
@@ -798,6 +1174,10 @@ type ContractorSpending struct {
 	ContractFees types.Currency `json:"contractfees"`
 	// DownloadSpending is the money currently spent on downloads.
 	DownloadSpending types.Currency `json:"downloadspending"`
+	// AccountDownloadSpending is the money spent on downloads paid for by
+	// ephemeral accounts in the current period. It is already accounted for
+	// in FundAccountSpending.
+	AccountDownloadSpending types.Currency `json:"accountdownloadspending"`
 	// FundAccountSpending is the money used to fund an ephemeral account on the
 	// host.
 	FundAccountSpending types.Currency `json:"fundaccountspending"`
@@ -856,6 +1236,216 @@ type ContractorChurnStatus struct {
 	MaxPeriodChurn uint64 `json:"maxperiodchurn"`
 }
 
+// ContractEventType describes the kind of event that was recorded in the
+// contractor's event journal.
+type ContractEventType string
+
+const (
+	// ContractEventFormed is recorded when a new contract is formed with a
+	// host.
+	ContractEventFormed ContractEventType = "formed"
+
+	// ContractEventRenewed is recorded when a contract is renewed at the end
+	// of its period.
+	ContractEventRenewed ContractEventType = "renewed"
+
+	// ContractEventRefreshed is recorded when a contract that ran out of funds
+	// is renewed before the end of its period.
+	ContractEventRefreshed ContractEventType = "refreshed"
+
+	// ContractEventNotGoodForUpload is recorded when a contract is marked as
+	// !GoodForUpload.
+	ContractEventNotGoodForUpload ContractEventType = "notgoodforupload"
+
+	// ContractEventNotGoodForRenew is recorded when a contract is marked as
+	// !GoodForRenew.
+	ContractEventNotGoodForRenew ContractEventType = "notgoodforrenew"
+
+	// ContractEventChurned is recorded when a contract counts towards the
+	// churn of the current period.
+	ContractEventChurned ContractEventType = "churned"
+
+	// ContractEventDoubleSpend is recorded when the watchdog finds that the
+	// inputs of a contract's formation transaction were double-spent.
+	ContractEventDoubleSpend ContractEventType = "doublespend"
+)
+
+// ContractEventTypes contains all the known contract event types.
+var ContractEventTypes = []ContractEventType{
+	ContractEventFormed,
+	ContractEventRenewed,
+	ContractEventRefreshed,
+	ContractEventNotGoodForUpload,
+	ContractEventNotGoodForRenew,
+	ContractEventChurned,
+	ContractEventDoubleSpend,
+}
+
+// ContractEvent is an entry in the contractor's event journal.
+type ContractEvent struct {
+	Type          ContractEventType    `json:"type"`
+	ContractID    types.FileContractID `json:"contractid"`
+	HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+	BlockHeight   types.BlockHeight    `json:"blockheight"`
+	Timestamp     time.Time            `json:"timestamp"`
+
+	// RenewedFrom is the ID of the contract that was renewed or refreshed
+	// into ContractID.
+	RenewedFrom types.FileContractID `json:"renewedfrom,omitempty"`
+
+	// Funds is the amount allocated to the contract and Fees the fees that
+	// were paid to form it. Size is the amount of data stored in the contract.
+	Funds types.Currency `json:"funds"`
+	Fees  types.Currency `json:"fees"`
+	Size  uint64         `json:"size"`
+
+	// Reason explains why the event happened.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ContractEventFilter restricts the events returned from the contractor's
+// event journal. Zero values match all events.
+type ContractEventFilter struct {
+	Types         []ContractEventType
+	ContractID    types.FileContractID
+	HostPublicKey types.SiaPublicKey
+	MinHeight     types.BlockHeight
+	MaxHeight     types.BlockHeight
+	Limit         int
+}
+
+// Matches returns true if the event passes the filter.
+func (f ContractEventFilter) Matches(e ContractEvent) bool {
+	if len(f.Types) > 0 {
+		found := false
+		for _, t := range f.Types {
+			found = found || t == e.Type
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.ContractID != (types.FileContractID{}) && f.ContractID != e.ContractID && f.ContractID != e.RenewedFrom {
+		return false
+	}
+	if len(f.HostPublicKey.Key) > 0 && !f.HostPublicKey.Equals(e.HostPublicKey) {
+		return false
+	}
+	if e.BlockHeight < f.MinHeight {
+		return false
+	}
+	if f.MaxHeight > 0 && e.BlockHeight > f.MaxHeight {
+		return false
+	}
+	return true
+}
+
+// Validate checks that the filter only contains known event types.
+func (f ContractEventFilter) Validate() error {
+	for _, t := range f.Types {
+		known := false
+		for _, kt := range ContractEventTypes {
+			known = known || t == kt
+		}
+		if !known {
+			return fmt.Errorf("unknown contract event type '%v'", t)
+		}
+	}
+	if f.MaxHeight > 0 && f.MaxHeight < f.MinHeight {
+		return errors.New("maximum height can't be smaller than minimum height")
+	}
+	if f.Limit < 0 {
+		return errors.New("limit can't be negative")
+	}
+	return nil
+}
+
+var (
+	// ErrUnknownTenant is returned when a tenant doesn't exist.
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrTenantExists is returned when creating a tenant which already
+	// exists.
+	ErrTenantExists = errors.New("tenant already exists")
+
+	// ErrInvalidTenantName is returned when a tenant name contains
+	// characters other than lowercase letters, digits, '-' and '_'.
+	ErrInvalidTenantName = errors.New("tenant name must be between 1 and 64 characters and only contain lowercase letters, digits, '-' and '_'")
+
+	// ErrTenantAuthentication is returned when a tenant token is invalid.
+	ErrTenantAuthentication = errors.New("tenant authentication failed")
+
+	// ErrTenantQuotaExceeded is returned when a request would exceed the
+	// quota of a tenant.
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+)
+
+// TenantQuota limits the resources a tenant can use. A zero value means that
+// the resource isn't limited. Upload and Download are reset at the beginning
+// of every allowance period.
+type TenantQuota struct {
+	Storage  uint64 `json:"storage"`  // bytes
+	Upload   uint64 `json:"upload"`   // bytes per period
+	Download uint64 `json:"download"` // bytes per period
+}
+
+// TenantUsage contains the resources a tenant used. The spending fields are
+// estimates of the share of the current period's spending which is
+// attributable to the tenant, based on the tenant's share of the stored and
+// downloaded data.
+type TenantUsage struct {
+	Storage    uint64 `json:"storage"`    // bytes
+	Uploaded   uint64 `json:"uploaded"`   // bytes in current period
+	Downloaded uint64 `json:"downloaded"` // bytes in current period
+
+	StorageSpending  types.Currency `json:"storagespending"`
+	UploadSpending   types.Currency `json:"uploadspending"`
+	DownloadSpending types.Currency `json:"downloadspending"`
+}
+
+// TenantInfo contains information about a tenant of the renter.
+type TenantInfo struct {
+	Name  string      `json:"name"`
+	Root  SiaPath     `json:"root"`
+	Quota TenantQuota `json:"quota"`
+	Usage TenantUsage `json:"usage"`
+}
+
+// RemainingUpload returns the number of bytes the tenant can still upload
+// before exceeding its storage or upload quota. The bool is false if neither
+// quota is set.
+func (ti TenantInfo) RemainingUpload() (uint64, bool) {
+	storage, storageLimited := remainingQuota(ti.Quota.Storage, ti.Usage.Storage)
+	upload, uploadLimited := remainingQuota(ti.Quota.Upload, ti.Usage.Uploaded)
+	switch {
+	case storageLimited && uploadLimited && storage < upload:
+		return storage, true
+	case uploadLimited:
+		return upload, true
+	default:
+		return storage, storageLimited
+	}
+}
+
+// RemainingDownload returns the number of bytes the tenant can still download
+// before exceeding its download quota. The bool is false if the quota isn't
+// set.
+func (ti TenantInfo) RemainingDownload() (uint64, bool) {
+	return remainingQuota(ti.Quota.Download, ti.Usage.Downloaded)
+}
+
+// remainingQuota returns the remainder of a quota and whether the quota is
+// set.
+func remainingQuota(quota, used uint64) (uint64, bool) {
+	if quota == 0 {
+		return 0, false
+	}
+	if used >= quota {
+		return 0, true
+	}
+	return quota - used, true
+}
+
 // UploadedBackup contains metadata about an uploaded backup.
 type UploadedBackup struct {
 	Name           string
@@ -1010,6 +1600,11 @@ type Renter interface {
 	// AllHosts returns the full list of hosts known to the renter.
 	AllHosts() ([]HostDBEntry, error)
 
+	// BulkOperations executes a list of operations against the renter's
+	// filesystem in order and returns the results of the individual files
+	// and directories the operations acted on.
+	BulkOperations(ops []BulkOperation) ([]BulkOperationResult, error)
+
 	// Close closes the Renter.
 	Close() error
 
@@ -1023,6 +1618,10 @@ type Renter interface {
 	// watchdog, and a bool indicating whether or not the watchdog is aware of it.
 	ContractStatus(fcID types.FileContractID) (ContractWatchStatus, bool)
 
+	// ContractEvents returns the events from the contractor's event journal
+	// that match the filter, oldest first.
+	ContractEvents(filter ContractEventFilter) ([]ContractEvent, error)
+
 	// CreateBackup creates a backup of the renter's siafiles. If a secret is not
 	// nil, the backup will be encrypted using the provided secret.
 	CreateBackup(dst string, secret []byte) error
@@ -1142,6 +1741,13 @@ type Renter interface {
 	// SetFilterMode sets the renter's hostdb filter mode
 	SetFilterMode(fm FilterMode, hosts []types.SiaPublicKey, netAddresses []string) error
 
+	// HostSelectionPolicy returns the hostdb's host selection policy.
+	HostSelectionPolicy() (HostSelectionPolicy, error)
+
+	// SetHostSelectionPolicy sets the hostdb's host selection policy and
+	// rescores all hosts accordingly.
+	SetHostSelectionPolicy(policy HostSelectionPolicy) error
+
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
@@ -1159,6 +1765,28 @@ type Renter interface {
 	// RenameDir changes the path of a dir.
 	RenameDir(oldPath, newPath SiaPath) error
 
+	// ReencodeFile starts migrating a file to a new erasure code and/or
+	// cipher type in the background by re-uploading its data chunk by chunk.
+	ReencodeFile(siaPath SiaPath, ec ErasureCoder, ct crypto.CipherType) error
+
+	// ReencodeDir starts migrating all files within a directory and its
+	// subdirectories to a new erasure code and/or cipher type in the
+	// background.
+	ReencodeDir(siaPath SiaPath, ec ErasureCoder, ct crypto.CipherType) error
+
+	// VerifyFile checks that random samples of a file's data can be
+	// retrieved from its hosts and optionally removes unreadable pieces to
+	// have them repaired.
+	VerifyFile(siaPath SiaPath, samples uint64, repair bool) (VerificationReport, error)
+
+	// VerifyDir verifies all files within a directory and its
+	// subdirectories.
+	VerifyDir(siaPath SiaPath, samples uint64, repair bool) (VerificationReport, error)
+
+	// HostTrustScores returns the trust scores of all hosts that were
+	// sampled by previous verifications.
+	HostTrustScores() ([]HostVerification, error)
+
 	// EstimateHostScore will return the score for a host with the provided
 	// settings, assuming perfect age and uptime adjustments
 	EstimateHostScore(entry HostDBEntry, allowance Allowance) (HostScoreBreakdown, error)
@@ -1206,6 +1834,26 @@ type Renter interface {
 	// reached and upload the data to the Sia network.
 	UploadStreamFromReader(up FileUploadParams, reader io.Reader) error
 
+	// CreateResumableUpload prepares a siafile for a resumable upload and
+	// returns the information required to write data to it.
+	CreateResumableUpload(up FileUploadParams) (ResumableUploadInfo, error)
+
+	// ResumableUpload returns the current state of a resumable upload.
+	ResumableUpload(id UploadID) (ResumableUploadInfo, error)
+
+	// ResumableUploadWrite writes the data read from the reader to a
+	// resumable upload, starting at offset. The offset needs to match the
+	// upload's committed offset. Even if reading from the reader fails, all
+	// data read up to that point is committed.
+	ResumableUploadWrite(id UploadID, offset uint64, reader io.Reader) (ResumableUploadInfo, error)
+
+	// FinalizeResumableUpload uploads any remaining staged data of a resumable
+	// upload and completes it.
+	FinalizeResumableUpload(id UploadID) error
+
+	// AbortResumableUpload cancels a resumable upload and deletes its siafile.
+	AbortResumableUpload(id UploadID) error
+
 	// CreateDir creates a directory for the renter
 	CreateDir(siaPath SiaPath, mode os.FileMode) error
 
@@ -1218,6 +1866,9 @@ type Renter interface {
 	// WorkerPoolStatus returns the current status of the Renter's worker pool
 	WorkerPoolStatus() (WorkerPoolStatus, error)
 
+	// ChunkCacheStatus returns the current status of the Renter's chunk cache
+	ChunkCacheStatus() (ChunkCacheStatus, error)
+
 	// BubbleMetadata calculates the updated values of a directory's metadata and
 	// updates the siadir metadata on disk then calls callThreadedBubbleMetadata
 	// on the parent directory so that it is only blocking for the current
@@ -1228,6 +1879,33 @@ type Renter interface {
 	// If the force boolean is supplied, the LastHealthCheckTime of the directories
 	// will be ignored so all directories will be considered.
 	BubbleMetadata(siaPath SiaPath, force, recursive bool) error
+
+	// CreateTenant creates a new tenant with its own root directory and
+	// returns the token the tenant authenticates with.
+	CreateTenant(name string, quota TenantQuota) (string, error)
+
+	// DeleteTenant removes a tenant. The files within the tenant's root
+	// directory are not deleted.
+	DeleteTenant(name string) error
+
+	// SetTenantQuota updates the quota of a tenant.
+	SetTenantQuota(name string, quota TenantQuota) error
+
+	// Tenant returns information about a tenant.
+	Tenant(name string) (TenantInfo, error)
+
+	// Tenants returns information about all of the renter's tenants.
+	Tenants() ([]TenantInfo, error)
+
+	// AuthenticateTenant returns information about the tenant with the given
+	// name if the token is valid. The spending estimates of the tenant's
+	// usage are not set.
+	AuthenticateTenant(name, token string) (TenantInfo, error)
+
+	// RecordTenantTraffic adds uploaded and downloaded bytes to the usage of
+	// a tenant in the current period. An empty name records downloads which
+	// don't belong to any tenant.
+	RecordTenantTraffic(name string, uploaded, downloaded uint64)
 }
 
 // Streamer is the interface implemented by the Renter's streamer type which
@@ -1309,6 +1987,17 @@ type HostDB interface {
 	// ones that violate the rules of the addressFilter.
 	CheckForIPViolations([]types.SiaPublicKey) ([]types.SiaPublicKey, error)
 
+	// CheckForDiversityViolations accepts a number of host public keys and
+	// returns the ones that violate the diversity rules of the host
+	// selection policy. Hosts passed in earlier take precedence.
+	CheckForDiversityViolations([]types.SiaPublicKey) ([]types.SiaPublicKey, error)
+
+	// CheckForPolicyViolations accepts a number of host public keys and
+	// returns the ones that exceed a price cap, don't meet the minimum
+	// requirements or are located in an excluded region or ASN of the host
+	// selection policy.
+	CheckForPolicyViolations([]types.SiaPublicKey) ([]types.SiaPublicKey, error)
+
 	// Close closes the hostdb.
 	Close() error
 
@@ -1322,13 +2011,32 @@ type HostDB interface {
 	// SetFilterMode sets the renter's hostdb filter mode
 	SetFilterMode(lm FilterMode, hosts []types.SiaPublicKey, netAddresses []string) error
 
+	// HostSelectionPolicy returns the hostdb's host selection policy.
+	HostSelectionPolicy() (HostSelectionPolicy, error)
+
+	// SetHostSelectionPolicy sets the hostdb's host selection policy and
+	// rescores all hosts accordingly.
+	SetHostSelectionPolicy(policy HostSelectionPolicy) error
+
 	// Host returns the HostDBEntry for a given host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
+	// HostLocation returns the location of a host according to the location
+	// database of the host selection policy.
+	HostLocation(pk types.SiaPublicKey) (HostLocation, bool, error)
+
 	// IncrementSuccessfulInteractions increments the number of successful
 	// interactions with a host for a given key
 	IncrementSuccessfulInteractions(types.SiaPublicKey) error
 
+	// RecordDownloadThroughput records the time it took to download size
+	// bytes from a host.
+	RecordDownloadThroughput(pk types.SiaPublicKey, size uint64, elapsed time.Duration) error
+
+	// RecordUploadThroughput records the time it took to upload size bytes
+	// to a host.
+	RecordUploadThroughput(pk types.SiaPublicKey, size uint64, elapsed time.Duration) error
+
 	// IncrementFailedInteractions increments the number of failed interactions with
 	// a host for a given key
 	IncrementFailedInteractions(types.SiaPublicKey) error
@@ -0,0 +1,255 @@
+package renter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem"
+)
+
+var (
+	// bulkOperationThreads is the number of files a bulk operation acts on
+	// in parallel.
+	bulkOperationThreads = build.Select(build.Var{
+		Dev:      10,
+		Standard: 20,
+		Testnet:  20,
+		Testing:  4,
+	}).(int)
+
+	// errBulkInterrupted is returned for the items of a bulk operation which
+	// weren't processed due to a shutdown.
+	errBulkInterrupted = errors.New("bulk operation interrupted by shutdown")
+)
+
+// BulkOperations executes a list of operations against the renter's
+// filesystem. The operations are executed in order, the files selected by a
+// single operation are processed in parallel. Failing items don't abort the
+// request; their errors are reported in the results instead.
+func (r *Renter) BulkOperations(ops []modules.BulkOperation) ([]modules.BulkOperationResult, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+
+	// Validate all operations before executing any of them.
+	for i, op := range ops {
+		if err := op.Validate(); err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid operation %v", i))
+		}
+	}
+
+	var results []modules.BulkOperationResult
+	for i, op := range ops {
+		items, err := r.managedBulkItems(op)
+		if err != nil {
+			results = append(results, modules.BulkOperationResult{
+				Operation: i,
+				SiaPath:   op.SiaPath,
+				LocalPath: op.LocalPath,
+				Error:     err.Error(),
+			})
+			continue
+		}
+		for j := range items {
+			items[j].Operation = i
+		}
+		r.managedBulkExecute(op, items)
+		results = append(results, items...)
+	}
+	return results, nil
+}
+
+// bulkRelPath returns the path of a file relative to the directory at
+// dirSiaPath.
+func bulkRelPath(dirSiaPath, siaPath modules.SiaPath) string {
+	if dirSiaPath.IsRoot() {
+		return siaPath.Path
+	}
+	return strings.TrimPrefix(siaPath.Path, dirSiaPath.Path+"/")
+}
+
+// managedBulkItems resolves the files and directories a bulk operation acts
+// on.
+func (r *Renter) managedBulkItems(op modules.BulkOperation) ([]modules.BulkOperationResult, error) {
+	// Uploads select files from the local filesystem.
+	if op.Type == modules.BulkOperationUpload {
+		return bulkUploadItems(op)
+	}
+
+	// Without a selection, a file or a directory is targeted directly.
+	if !op.IsSelection() {
+		isFile, err := r.staticFileSystem.FileExists(op.SiaPath)
+		if err != nil {
+			return nil, err
+		}
+		isDir, err := r.staticFileSystem.DirExists(op.SiaPath)
+		if err != nil {
+			return nil, err
+		}
+		isDirOp := op.Type == modules.BulkOperationDelete || op.Type == modules.BulkOperationRename
+		if !isFile && !isDir {
+			return nil, filesystem.ErrNotExist
+		}
+		if isFile || isDirOp {
+			return []modules.BulkOperationResult{{
+				SiaPath:    op.SiaPath,
+				NewSiaPath: op.NewSiaPath,
+				LocalPath:  op.LocalPath,
+			}}, nil
+		}
+	}
+
+	// Select the files within the directory. The files are collected first
+	// to avoid iterating over the directory while it is modified.
+	var mu sync.Mutex
+	var siaPaths []modules.SiaPath
+	err := r.staticFileSystem.CachedList(op.SiaPath, true, func(fi modules.FileInfo) {
+		if !op.Selects(bulkRelPath(op.SiaPath, fi.SiaPath)) {
+			return
+		}
+		mu.Lock()
+		siaPaths = append(siaPaths, fi.SiaPath)
+		mu.Unlock()
+	}, func(modules.DirectoryInfo) {})
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to list files")
+	}
+	sort.Slice(siaPaths, func(i, j int) bool {
+		return siaPaths[i].Path < siaPaths[j].Path
+	})
+
+	items := make([]modules.BulkOperationResult, 0, len(siaPaths))
+	for _, sp := range siaPaths {
+		relPath := bulkRelPath(op.SiaPath, sp)
+		item := modules.BulkOperationResult{SiaPath: sp}
+		switch op.Type {
+		case modules.BulkOperationRename:
+			item.NewSiaPath, err = op.NewSiaPath.Join(relPath)
+			if err != nil {
+				return nil, errors.AddContext(err, "unable to create new siapath")
+			}
+		case modules.BulkOperationSetLocalPath:
+			item.LocalPath = filepath.Join(op.LocalPath, filepath.FromSlash(relPath))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// bulkUploadItems resolves the local files selected by an upload operation.
+func bulkUploadItems(op modules.BulkOperation) ([]modules.BulkOperationResult, error) {
+	fi, err := os.Stat(op.LocalPath)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []modules.BulkOperationResult{{
+			SiaPath:   op.SiaPath,
+			LocalPath: op.LocalPath,
+		}}, nil
+	}
+	var items []modules.BulkOperationResult
+	err = filepath.Walk(op.LocalPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(op.LocalPath, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if !op.Selects(relPath) {
+			return nil
+		}
+		siaPath, err := op.SiaPath.Join(relPath)
+		if err != nil {
+			return err
+		}
+		items = append(items, modules.BulkOperationResult{
+			SiaPath:   siaPath,
+			LocalPath: path,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to walk local directory")
+	}
+	return items, nil
+}
+
+// managedBulkExecute applies a bulk operation to its items in parallel and
+// records the errors within the items.
+func (r *Renter) managedBulkExecute(op modules.BulkOperation, items []modules.BulkOperationResult) {
+	itemChan := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < bulkOperationThreads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range itemChan {
+				if err := r.managedBulkExecuteItem(op, items[i]); err != nil {
+					items[i].Error = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range items {
+		select {
+		case <-r.tg.StopChan():
+			items[i].Error = errBulkInterrupted.Error()
+			continue
+		default:
+		}
+		itemChan <- i
+	}
+	close(itemChan)
+	wg.Wait()
+}
+
+// managedBulkExecuteItem applies a bulk operation to a single item.
+func (r *Renter) managedBulkExecuteItem(op modules.BulkOperation, item modules.BulkOperationResult) error {
+	switch op.Type {
+	case modules.BulkOperationDelete, modules.BulkOperationRename:
+		// Files take precedence over directories of the same name.
+		isFile, err := r.staticFileSystem.FileExists(item.SiaPath)
+		if err != nil {
+			return err
+		}
+		if op.Type == modules.BulkOperationDelete {
+			if isFile {
+				return r.DeleteFile(item.SiaPath)
+			}
+			return r.DeleteDir(item.SiaPath)
+		}
+		if isFile {
+			return r.RenameFile(item.SiaPath, item.NewSiaPath)
+		}
+		return r.RenameDir(item.SiaPath, item.NewSiaPath)
+	case modules.BulkOperationSetLocalPath:
+		return r.SetFileTrackingPath(item.SiaPath, item.LocalPath)
+	case modules.BulkOperationSetStuck:
+		return r.SetFileStuck(item.SiaPath, op.Stuck)
+	case modules.BulkOperationUpload:
+		return r.Upload(modules.FileUploadParams{
+			Source:              item.LocalPath,
+			SiaPath:             item.SiaPath,
+			Force:               op.Force,
+			DisablePartialChunk: true,
+			CipherType:          crypto.TypeDefaultRenter,
+		})
+	}
+	return modules.ErrUnknownBulkOperation
+}
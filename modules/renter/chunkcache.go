@@ -0,0 +1,271 @@
+package renter
+
+import (
+	"container/list"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+)
+
+// Chunk Cache Overview:
+// The chunk cache is a persistent, on-disk LRU cache of recovered chunks which
+// sits in front of the download code and therefore in front of both streams
+// and regular downloads. Every cached chunk is stored in its own file within
+// the cache directory, named after the UID of the siafile and the index of the
+// chunk. Since siafiles are never modified in place, the UID and chunk index
+// uniquely identify the data of a chunk. Cached chunks are encrypted with a key
+// derived from the master key of the siafile, so the cache doesn't reveal the
+// data of files which are encrypted on the hosts.
+//
+// When the cache is enabled, user downloads first check the cache for every
+// chunk they need. Chunks which are downloaded from the hosts in full are added
+// to the cache once they are recovered. Chunks of which only a range is
+// downloaded aren't cached. Repeated reads of the same data are then served
+// from disk without paying for host bandwidth again. A cache size of 0
+// disables the cache and deletes all cached chunks.
+
+const (
+	// chunkCacheDir is the name of the directory within the renter's persist
+	// directory which holds the cached chunks.
+	chunkCacheDir = "chunkcache"
+
+	// chunkCacheExtension is the extension of a cached chunk.
+	chunkCacheExtension = ".echunk"
+
+	// chunkCacheLegacyExtension is the extension of the unencrypted chunks
+	// of older versions. They are removed when the cache is loaded.
+	chunkCacheLegacyExtension = ".chunk"
+
+	// chunkCachePieceIndex is the piece index used to derive the key of a
+	// cached chunk from the master key of its siafile. No piece of a chunk
+	// uses this index, so the key stream of the cache is never used for
+	// different data.
+	chunkCachePieceIndex = math.MaxUint64
+
+	// chunkCacheTmpExtension is the extension of a chunk which is being
+	// written to the cache.
+	chunkCacheTmpExtension = ".tmp"
+)
+
+type (
+	// chunkCache is a persistent LRU cache of recovered chunks.
+	chunkCache struct {
+		// lru contains the cached chunks ordered from most recently used at
+		// the front to least recently used at the back. The map indexes the
+		// elements of the list by their key.
+		entries map[string]*list.Element
+		lru     *list.List
+
+		hits    uint64
+		maxSize uint64
+		misses  uint64
+		size    uint64
+
+		staticDir string
+		mu        sync.Mutex
+	}
+
+	// chunkCacheEntry is a single cached chunk.
+	chunkCacheEntry struct {
+		key  string
+		size uint64
+	}
+)
+
+// ChunkCacheStatus returns the current status of the renter's chunk cache.
+func (r *Renter) ChunkCacheStatus() (modules.ChunkCacheStatus, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.ChunkCacheStatus{}, err
+	}
+	defer r.tg.Done()
+	return r.staticChunkCache.callStatus(), nil
+}
+
+// chunkCacheKey returns the key of a chunk within the cache.
+func chunkCacheKey(uid siafile.SiafileUID, chunkIndex uint64) string {
+	return fmt.Sprintf("%v-%v", uid, chunkIndex)
+}
+
+// newChunkCache creates a chunk cache with the provided maximum size and loads
+// the chunks which are already cached in the directory. The least recently
+// modified chunks are evicted if the cache exceeds the maximum size.
+func newChunkCache(dir string, maxSize uint64) (*chunkCache, error) {
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		return nil, errors.AddContext(err, "unable to create chunk cache dir")
+	}
+	cc := &chunkCache{
+		entries:   make(map[string]*list.Element),
+		lru:       list.New(),
+		maxSize:   maxSize,
+		staticDir: dir,
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to read chunk cache dir")
+	}
+	// Add the chunks from the least to the most recently modified one.
+	sort.Slice(fis, func(i, j int) bool {
+		return fis[i].ModTime().Before(fis[j].ModTime())
+	})
+	for _, fi := range fis {
+		name := fi.Name()
+		if fi.IsDir() {
+			continue
+		}
+		// Remove leftovers of interrupted writes and unencrypted chunks.
+		if strings.HasSuffix(name, chunkCacheTmpExtension) || strings.HasSuffix(name, chunkCacheLegacyExtension) {
+			if err := os.Remove(filepath.Join(dir, name)); err != nil {
+				return nil, errors.AddContext(err, "unable to remove temporary chunk")
+			}
+			continue
+		}
+		if !strings.HasSuffix(name, chunkCacheExtension) {
+			continue
+		}
+		key := strings.TrimSuffix(name, chunkCacheExtension)
+		cc.entries[key] = cc.lru.PushFront(&chunkCacheEntry{
+			key:  key,
+			size: uint64(fi.Size()),
+		})
+		cc.size += uint64(fi.Size())
+	}
+	return cc, cc.managedEvict()
+}
+
+// callEnabled returns whether the cache is enabled.
+func (cc *chunkCache) callEnabled() bool {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.maxSize > 0
+}
+
+// callGet returns the cached data of a chunk decrypted with the master key of
+// its siafile. The returned bool indicates whether the chunk was found.
+func (cc *chunkCache) callGet(uid siafile.SiafileUID, chunkIndex uint64, masterKey crypto.CipherKey) ([]byte, bool) {
+	key := chunkCacheKey(uid, chunkIndex)
+	cc.mu.Lock()
+	elem, exists := cc.entries[key]
+	if !exists {
+		cc.misses++
+		cc.mu.Unlock()
+		return nil, false
+	}
+	cc.lru.MoveToFront(elem)
+	cc.mu.Unlock()
+
+	// Read the chunk. If it was evicted in the meantime, it's a miss.
+	data, err := ioutil.ReadFile(cc.chunkPath(key))
+	if err == nil {
+		data, err = masterKey.Derive(chunkIndex, chunkCachePieceIndex).DecryptBytes(data)
+	}
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if err != nil {
+		cc.misses++
+		return nil, false
+	}
+	cc.hits++
+	return data, true
+}
+
+// callAdd encrypts a chunk with the master key of its siafile, adds it to the
+// cache and evicts the least recently used chunks if the cache exceeds its
+// maximum size. Like the pieces of the chunk, the chunk's size needs to be a
+// multiple of the cipher's block size.
+func (cc *chunkCache) callAdd(uid siafile.SiafileUID, chunkIndex uint64, masterKey crypto.CipherKey, data []byte) error {
+	cc.mu.Lock()
+	maxSize := cc.maxSize
+	cc.mu.Unlock()
+	if uint64(len(data)) > maxSize {
+		return nil // chunk doesn't fit
+	}
+
+	// Write the chunk to a temporary file first to avoid readers seeing
+	// partially written chunks.
+	key := chunkCacheKey(uid, chunkIndex)
+	ciphertext := masterKey.Derive(chunkIndex, chunkCachePieceIndex).EncryptBytes(data)
+	tmpPath := filepath.Join(cc.staticDir, key+"-"+hex.EncodeToString(fastrand.Bytes(8))+chunkCacheTmpExtension)
+	if err := ioutil.WriteFile(tmpPath, ciphertext, modules.DefaultFilePerm); err != nil {
+		return errors.Compose(err, os.Remove(tmpPath))
+	}
+
+	cc.mu.Lock()
+	if err := os.Rename(tmpPath, cc.chunkPath(key)); err != nil {
+		cc.mu.Unlock()
+		return errors.Compose(err, os.Remove(tmpPath))
+	}
+	if elem, exists := cc.entries[key]; exists {
+		entry := elem.Value.(*chunkCacheEntry)
+		cc.size -= entry.size
+		entry.size = uint64(len(data))
+		cc.lru.MoveToFront(elem)
+	} else {
+		cc.entries[key] = cc.lru.PushFront(&chunkCacheEntry{
+			key:  key,
+			size: uint64(len(data)),
+		})
+	}
+	cc.size += uint64(len(data))
+	cc.mu.Unlock()
+	return cc.managedEvict()
+}
+
+// callSetMaxSize updates the maximum size of the cache and evicts chunks until
+// the cache fits.
+func (cc *chunkCache) callSetMaxSize(maxSize uint64) error {
+	cc.mu.Lock()
+	cc.maxSize = maxSize
+	cc.mu.Unlock()
+	return cc.managedEvict()
+}
+
+// callStatus returns the status of the cache.
+func (cc *chunkCache) callStatus() modules.ChunkCacheStatus {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return modules.ChunkCacheStatus{
+		Hits:      cc.hits,
+		MaxSize:   cc.maxSize,
+		Misses:    cc.misses,
+		NumChunks: uint64(cc.lru.Len()),
+		Size:      cc.size,
+	}
+}
+
+// chunkPath returns the path of a cached chunk.
+func (cc *chunkCache) chunkPath(key string) string {
+	return filepath.Join(cc.staticDir, key+chunkCacheExtension)
+}
+
+// managedEvict removes the least recently used chunks until the cache doesn't
+// exceed its maximum size anymore.
+func (cc *chunkCache) managedEvict() error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	var errs error
+	for cc.size > cc.maxSize {
+		elem := cc.lru.Back()
+		entry := elem.Value.(*chunkCacheEntry)
+		cc.lru.Remove(elem)
+		delete(cc.entries, entry.key)
+		cc.size -= entry.size
+		err := os.Remove(cc.chunkPath(entry.key))
+		if err != nil && !os.IsNotExist(err) {
+			errs = errors.Compose(errs, err)
+		}
+	}
+	return errs
+}
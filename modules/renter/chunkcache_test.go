@@ -0,0 +1,130 @@
+package renter
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+)
+
+// TestChunkCache tests adding, fetching and evicting chunks from the chunk
+// cache as well as reloading it from disk.
+func TestChunkCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("renter", t.Name())
+	cc, err := newChunkCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid := siafile.SiafileUID("foo")
+	key := crypto.GenerateSiaKey(crypto.TypeXChaCha20)
+
+	// Add 2 chunks.
+	chunk0 := fastrand.Bytes(100)
+	chunk1 := fastrand.Bytes(100)
+	if err := cc.callAdd(uid, 0, key, chunk0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cc.callAdd(uid, 1, key, chunk1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The chunks are encrypted on disk and can't be decrypted with a
+	// different key.
+	onDisk, err := ioutil.ReadFile(cc.chunkPath(chunkCacheKey(uid, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(onDisk, chunk0) {
+		t.Fatal("chunk should be encrypted on disk")
+	}
+	otherKey := crypto.GenerateSiaKey(crypto.TypeXChaCha20)
+	if data, ok := cc.callGet(uid, 1, otherKey); ok && bytes.Equal(data, chunk1) {
+		t.Fatal("chunk shouldn't be decrypted with a different key")
+	}
+
+	// Fetch the first one to make it the most recently used chunk.
+	data, ok := cc.callGet(uid, 0, key)
+	if !ok || !bytes.Equal(data, chunk0) {
+		t.Fatal("wrong data for chunk 0")
+	}
+	if _, ok := cc.callGet(uid, 2, key); ok {
+		t.Fatal("chunk 2 shouldn't exist")
+	}
+
+	// Adding another chunk should evict chunk 1.
+	chunk2 := fastrand.Bytes(100)
+	if err := cc.callAdd(uid, 2, key, chunk2); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cc.callGet(uid, 1, key); ok {
+		t.Fatal("chunk 1 should have been evicted")
+	}
+	status := cc.callStatus()
+	if status.Hits != 2 || status.Misses != 2 || status.NumChunks != 2 || status.Size != 200 || status.MaxSize != 250 {
+		t.Fatal("wrong status", status)
+	}
+
+	// Chunks larger than the cache are ignored.
+	if err := cc.callAdd(uid, 3, key, fastrand.Bytes(300)); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cc.callGet(uid, 3, key); ok {
+		t.Fatal("chunk 3 shouldn't have been added")
+	}
+
+	// Reload the cache. The chunks should still be there.
+	cc, err = newChunkCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, ok = cc.callGet(uid, 2, key)
+	if !ok || !bytes.Equal(data, chunk2) {
+		t.Fatal("wrong data for chunk 2")
+	}
+	if status := cc.callStatus(); status.NumChunks != 2 || status.Size != 200 {
+		t.Fatal("wrong status after reload", status)
+	}
+
+	// Unencrypted chunks of older versions are removed.
+	legacyPath := filepath.Join(dir, chunkCacheKey(uid, 4)+chunkCacheLegacyExtension)
+	if err := ioutil.WriteFile(legacyPath, fastrand.Bytes(10), modules.DefaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	cc, err = newChunkCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacyPath); !os.IsNotExist(err) {
+		t.Fatal("unencrypted chunk should have been removed", err)
+	}
+	if status := cc.callStatus(); status.NumChunks != 2 || status.Size != 200 {
+		t.Fatal("wrong status after removing unencrypted chunk", status)
+	}
+
+	// Disabling the cache should remove all chunks.
+	if err := cc.callSetMaxSize(0); err != nil {
+		t.Fatal(err)
+	}
+	if cc.callEnabled() {
+		t.Fatal("cache should be disabled")
+	}
+	cc, err = newChunkCache(dir, 250)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status := cc.callStatus(); status.NumChunks != 0 || status.Size != 0 {
+		t.Fatal("cache should be empty", status)
+	}
+}
@@ -3,6 +3,7 @@ package contractor
 import (
 	"errors"
 	"reflect"
+	"time"
 
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/types"
@@ -38,6 +39,9 @@ var (
 	// ErrAllowanceZeroMaxPeriodChurn is returned if the allowance max period
 	// churn is being set to zero when not cancelling the allowance
 	ErrAllowanceZeroMaxPeriodChurn = errors.New("max period churn must be non-zero")
+	// ErrAllowanceInvalidSpendingAlertThreshold is returned if the allowance
+	// spending alert threshold is not between 0 and 1
+	ErrAllowanceInvalidSpendingAlertThreshold = errors.New("spending alert threshold must be between 0 and 1")
 )
 
 // SetAllowance sets the amount of money the Contractor is allowed to spend on
@@ -84,6 +88,8 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 		return ErrAllowanceZeroExpectedRedundancy
 	} else if a.MaxPeriodChurn == 0 {
 		return ErrAllowanceZeroMaxPeriodChurn
+	} else if a.SpendingAlertThreshold < 0 || a.SpendingAlertThreshold > 1 {
+		return ErrAllowanceInvalidSpendingAlertThreshold
 	} else if !c.cs.Synced() {
 		return errAllowanceNotSynced
 	}
@@ -117,6 +123,7 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 		unlockContracts = true
 	}
 	c.allowance = a
+	c.spendingCapStatusUpdated = time.Time{}
 	err := c.save()
 	c.mu.Unlock()
 	if err != nil {
@@ -134,7 +141,7 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 			}
 			utility := contract.Utility()
 			utility.Locked = false
-			err := c.callUpdateUtility(contract, utility, false)
+			err := c.callUpdateUtility(contract, utility, false, "")
 			c.staticContracts.Return(contract)
 			if err != nil {
 				return err
@@ -200,6 +207,7 @@ func (c *Contractor) managedCancelAllowance() error {
 	// Clear out the allowance and save.
 	c.mu.Lock()
 	c.allowance = modules.Allowance{}
+	c.spendingCapStatusUpdated = time.Time{}
 	c.currentPeriod = 0
 	err := c.save()
 	c.mu.Unlock()
@@ -221,7 +229,7 @@ func (c *Contractor) managedCancelAllowance() error {
 		utility.GoodForRenew = false
 		utility.GoodForUpload = false
 		utility.Locked = true
-		err := c.callUpdateUtility(contract, utility, false)
+		err := c.callUpdateUtility(contract, utility, false, "allowance was canceled")
 		c.staticContracts.Return(contract)
 		if err != nil {
 			return err
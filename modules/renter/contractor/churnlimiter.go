@@ -164,7 +164,7 @@ func (cl *churnLimiter) managedProcessSuggestedUpdates(queue []contractScoreAndU
 		}
 
 		// Apply changes.
-		err := cl.contractor.managedAcquireAndUpdateContractUtility(queuedContract.contract.ID, queuedContract.util)
+		err := cl.contractor.managedAcquireAndUpdateContractUtility(queuedContract.contract.ID, queuedContract.util, reasonLowHostScore)
 		if err != nil {
 			return err
 		}
@@ -235,7 +235,7 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	// Get host from hostdb and check that it's not filtered.
 	host, u, needsUpdate := c.managedHostInHostDBCheck(contract)
 	if needsUpdate {
-		if err := c.managedUpdateContractUtility(sc, u); err != nil {
+		if err := c.managedUpdateContractUtility(sc, u, "host is not in the hostdb or is filtered"); err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
 			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after hostdb check")
 		}
@@ -243,9 +243,9 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	}
 
 	// Do critical contract checks and update the utility if any checks fail.
-	u, needsUpdate = c.managedCriticalUtilityChecks(sc, host)
+	u, reason, needsUpdate := c.managedCriticalUtilityChecks(sc, host)
 	if needsUpdate {
-		err := c.managedUpdateContractUtility(sc, u)
+		err := c.managedUpdateContractUtility(sc, u, reason)
 		if err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
 			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after criticalUtilityChecks")
@@ -272,7 +272,7 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 
 	case necessaryUtilityUpdate:
 		// Apply changes.
-		err = c.managedUpdateContractUtility(sc, u)
+		err = c.managedUpdateContractUtility(sc, u, reasonLowHostScore)
 		if err != nil {
 			c.log.Println("Unable to acquire and update contract utility:", err)
 			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after checkHostScore")
@@ -290,7 +290,7 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 	u.GoodForUpload = true
 	u.GoodForRenew = true
 	// Apply changes.
-	err = c.managedUpdateContractUtility(sc, u)
+	err = c.managedUpdateContractUtility(sc, u, "")
 	if err != nil {
 		c.log.Println("Unable to acquire and update contract utility:", err)
 		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after all checks passed.")
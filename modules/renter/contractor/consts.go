@@ -1,6 +1,8 @@
 package contractor
 
 import (
+	"time"
+
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/types"
@@ -20,6 +22,14 @@ var (
 	// AlertMSGFailedContractRenewal indicates that the contract renewal failed
 	AlertMSGFailedContractRenewal = "Contractor is attempting to renew/refresh contracts but failed"
 
+	// AlertMSGSpendingCapApproaching indicates that the spending in at least
+	// one category is close to the category's cap.
+	AlertMSGSpendingCapApproaching = "At least one spending category of the allowance is close to its cap"
+
+	// AlertMSGSpendingCapReached indicates that the spending in at least one
+	// category has reached the category's cap.
+	AlertMSGSpendingCapReached = "At least one spending category of the allowance has reached its cap"
+
 	// AlertMSGWalletLockedDuringMaintenance indicates that forming/renewing a
 	// contract during contract maintenance isn't possible due to a locked wallet.
 	AlertMSGWalletLockedDuringMaintenance = "At least one contract failed to form/renew due to the wallet being locked"
@@ -90,6 +100,15 @@ var (
 
 	// oosRetryInterval is the time we wait for a host that ran out of storage to
 	// add more storage before trying to upload to it again.
+	// spendingCapRecheckInterval is the amount of time after which the cached
+	// status of the allowance's spending caps is recomputed.
+	spendingCapRecheckInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testnet:  time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
 	oosRetryInterval = build.Select(build.Var{
 		Dev:      types.BlockHeight(types.BlocksPerHour / 2), // 30 minutes
 		Standard: types.BlockHeight(types.BlocksPerWeek),     // 7 days
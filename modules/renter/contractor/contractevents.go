@@ -0,0 +1,255 @@
+package contractor
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/persist"
+	"go.thebigfile.com/bigd/types"
+)
+
+const (
+	// contractEventsFile is the name of the file that contains the
+	// contractor's event journal.
+	contractEventsFile = "contractevents.dat"
+
+	// contractEventsTmpFile is the name of the file the event journal is
+	// compacted into before it replaces contractEventsFile.
+	contractEventsTmpFile = "contractevents.dat_temp"
+
+	// reasonLowHostScore is the reason recorded for contracts that lose their
+	// utility because the host's score is too low.
+	reasonLowHostScore = "host score is too low"
+
+	// reasonRenewed is the reason for contracts that lose their utility
+	// because they were renewed. No utility events are recorded for them since
+	// the renewal is recorded as a single renewed event.
+	reasonRenewed = "contract was renewed"
+)
+
+var (
+	// contractEventsMetadataHeader is the header of the metadata for the
+	// contract event journal.
+	contractEventsMetadataHeader = types.NewSpecifier("ContractEvents\n")
+
+	// contractEventsMaxEvents is the number of events the journal retains.
+	// Once it is exceeded the oldest events are dropped.
+	contractEventsMaxEvents = build.Select(build.Var{
+		Dev:      10000,
+		Standard: 100000,
+		Testnet:  100000,
+		Testing:  10,
+	}).(int)
+)
+
+// contractEventJournal is a persisted, append-only record of the events in
+// the lifetime of the contractor's contracts. It retains the most recent
+// contractEventsMaxEvents events.
+type contractEventJournal struct {
+	events []modules.ContractEvent
+
+	// persisted is the number of events in the persist file, which can
+	// exceed the number of retained events until the file is compacted.
+	persisted int
+
+	aop       *persist.AppendOnlyPersist
+	staticDir string
+	mu        sync.Mutex
+}
+
+// newContractEventJournal loads the contract event journal from the provided
+// directory or creates a new one.
+func newContractEventJournal(dir string) (*contractEventJournal, error) {
+	aop, reader, err := persist.NewAppendOnlyPersist(dir, contractEventsFile, contractEventsMetadataHeader, persist.MetadataVersionv156)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to create AppendOnlyPersist")
+	}
+	events, err := unmarshalContractEvents(reader)
+	if err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "unable to unmarshal contract events"), aop.Close())
+	}
+	j := &contractEventJournal{
+		events:    events,
+		persisted: len(events),
+		aop:       aop,
+		staticDir: dir,
+	}
+	if len(j.events) > contractEventsMaxEvents {
+		j.events = j.events[len(j.events)-contractEventsMaxEvents:]
+		if err := j.compact(); err != nil {
+			return nil, errors.Compose(errors.AddContext(err, "unable to compact contract events"), j.aop.Close())
+		}
+	}
+	return j, nil
+}
+
+// compact rewrites the persist file to only contain the retained events. The
+// events are written to a temporary file first which then atomically
+// replaces the persist file.
+func (j *contractEventJournal) compact() (err error) {
+	tmpPath := filepath.Join(j.staticDir, contractEventsTmpFile)
+	if err := os.RemoveAll(tmpPath); err != nil {
+		return errors.AddContext(err, "unable to remove stale temp file")
+	}
+	tmp, _, err := persist.NewAppendOnlyPersist(j.staticDir, contractEventsTmpFile, contractEventsMetadataHeader, persist.MetadataVersionv156)
+	if err != nil {
+		return errors.AddContext(err, "unable to create temp file")
+	}
+	for _, e := range j.events {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return errors.Compose(errors.AddContext(err, "unable to marshal contract event"), tmp.Close())
+		}
+		if _, err := tmp.Write(data); err != nil {
+			return errors.Compose(errors.AddContext(err, "unable to write contract event"), tmp.Close())
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.AddContext(err, "unable to close temp file")
+	}
+
+	// Swap the files and reopen the journal.
+	if err := j.aop.Close(); err != nil {
+		return errors.AddContext(err, "unable to close persist file")
+	}
+	if err := os.Rename(tmpPath, j.aop.FilePath()); err != nil {
+		return errors.AddContext(err, "unable to replace persist file")
+	}
+	aop, _, err := persist.NewAppendOnlyPersist(j.staticDir, contractEventsFile, contractEventsMetadataHeader, persist.MetadataVersionv156)
+	if err != nil {
+		return errors.AddContext(err, "unable to reopen persist file")
+	}
+	j.aop = aop
+	j.persisted = len(j.events)
+	return nil
+}
+
+// callClose closes the journal's persist file.
+func (j *contractEventJournal) callClose() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.aop.Close()
+}
+
+// unmarshalContractEvents uses a json Decoder to read the persisted events.
+func unmarshalContractEvents(r io.Reader) ([]modules.ContractEvent, error) {
+	d := json.NewDecoder(r)
+	var events []modules.ContractEvent
+	for {
+		var e modules.ContractEvent
+		err := d.Decode(&e)
+		if errors.Contains(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// callAppend adds an event to the journal and persists it. If the journal
+// exceeds contractEventsMaxEvents, the oldest event is dropped and the persist
+// file is compacted once it holds twice as many events as are retained.
+func (j *contractEventJournal) callAppend(e modules.ContractEvent) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return errors.AddContext(err, "unable to marshal contract event")
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.aop.Write(data)
+	if err != nil {
+		return errors.AddContext(err, "unable to persist contract event")
+	}
+	j.persisted++
+	j.events = append(j.events, e)
+	if len(j.events) > contractEventsMaxEvents {
+		j.events = j.events[len(j.events)-contractEventsMaxEvents:]
+	}
+	if j.persisted >= 2*contractEventsMaxEvents {
+		return errors.AddContext(j.compact(), "unable to compact contract events")
+	}
+	return nil
+}
+
+// callEvents returns the events matching the filter, oldest first. If the
+// filter has a limit, only the most recent matching events are returned.
+func (j *contractEventJournal) callEvents(filter modules.ContractEventFilter) []modules.ContractEvent {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	events := make([]modules.ContractEvent, 0)
+	for _, e := range j.events {
+		if filter.Matches(e) {
+			events = append(events, e)
+		}
+	}
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[len(events)-filter.Limit:]
+	}
+	return events
+}
+
+// ContractEvents returns the events from the contract event journal that
+// match the filter.
+func (c *Contractor) ContractEvents(filter modules.ContractEventFilter) ([]modules.ContractEvent, error) {
+	if err := c.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer c.tg.Done()
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+	return c.staticContractEvents.callEvents(filter), nil
+}
+
+// callRecordContractEvent adds an event for a contract to the event journal.
+// The block height and timestamp are set by the contractor.
+func (c *Contractor) callRecordContractEvent(eventType modules.ContractEventType, contract modules.RenterContract, reason string) {
+	c.mu.RLock()
+	bh := c.blockHeight
+	c.mu.RUnlock()
+	e := modules.ContractEvent{
+		Type:          eventType,
+		ContractID:    contract.ID,
+		HostPublicKey: contract.HostPublicKey,
+		BlockHeight:   bh,
+		Timestamp:     time.Now(),
+		Funds:         contract.TotalCost,
+		Fees:          contract.TxnFee.Add(contract.SiafundFee).Add(contract.ContractFee),
+		Size:          contract.Size(),
+		Reason:        reason,
+	}
+	if eventType == modules.ContractEventRenewed || eventType == modules.ContractEventRefreshed {
+		c.mu.RLock()
+		e.RenewedFrom = c.renewedFrom[contract.ID]
+		c.mu.RUnlock()
+	}
+	if err := c.staticContractEvents.callAppend(e); err != nil {
+		c.log.Println("WARN: failed to record contract event:", err)
+	}
+}
+
+// callRecordUtilityEvents records the events for a contract losing its
+// GoodForUpload or GoodForRenew status. Contracts that were renewed are
+// skipped.
+func (c *Contractor) callRecordUtilityEvents(contract modules.RenterContract, newUtility modules.ContractUtility, reason string) {
+	if reason == reasonRenewed {
+		return
+	}
+	if contract.Utility.GoodForUpload && !newUtility.GoodForUpload {
+		c.callRecordContractEvent(modules.ContractEventNotGoodForUpload, contract, reason)
+	}
+	if contract.Utility.GoodForRenew && !newUtility.GoodForRenew {
+		c.callRecordContractEvent(modules.ContractEventNotGoodForRenew, contract, reason)
+	}
+}
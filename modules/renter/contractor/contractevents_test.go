@@ -0,0 +1,181 @@
+package contractor
+
+import (
+	"testing"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestContractEventJournal tests recording, filtering and reloading contract
+// events.
+func TestContractEventJournal(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("contractor", t.Name())
+	j, err := newContractEventJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record some events for two hosts.
+	hpk1 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	hpk2 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	events := []modules.ContractEvent{
+		{Type: modules.ContractEventFormed, ContractID: types.FileContractID{1}, HostPublicKey: hpk1, BlockHeight: 10},
+		{Type: modules.ContractEventFormed, ContractID: types.FileContractID{2}, HostPublicKey: hpk2, BlockHeight: 10},
+		{Type: modules.ContractEventNotGoodForUpload, ContractID: types.FileContractID{1}, HostPublicKey: hpk1, BlockHeight: 20, Reason: reasonLowHostScore},
+		{Type: modules.ContractEventRenewed, ContractID: types.FileContractID{3}, RenewedFrom: types.FileContractID{2}, HostPublicKey: hpk2, BlockHeight: 30},
+	}
+	for _, e := range events {
+		if err := j.callAppend(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Check the filters.
+	tests := []struct {
+		filter modules.ContractEventFilter
+		want   []int
+	}{
+		{modules.ContractEventFilter{}, []int{0, 1, 2, 3}},
+		{modules.ContractEventFilter{Types: []modules.ContractEventType{modules.ContractEventFormed}}, []int{0, 1}},
+		{modules.ContractEventFilter{HostPublicKey: hpk1}, []int{0, 2}},
+		{modules.ContractEventFilter{ContractID: types.FileContractID{2}}, []int{1, 3}},
+		{modules.ContractEventFilter{MinHeight: 20}, []int{2, 3}},
+		{modules.ContractEventFilter{MaxHeight: 20}, []int{0, 1, 2}},
+		{modules.ContractEventFilter{Limit: 1}, []int{3}},
+	}
+	for i, test := range tests {
+		got := j.callEvents(test.filter)
+		if len(got) != len(test.want) {
+			t.Fatalf("%v: expected %v events but got %v", i, len(test.want), len(got))
+		}
+		for k, idx := range test.want {
+			if got[k].ContractID != events[idx].ContractID || got[k].Type != events[idx].Type {
+				t.Fatalf("%v: unexpected event %v", i, got[k])
+			}
+		}
+	}
+
+	// Unknown types are rejected.
+	filter := modules.ContractEventFilter{Types: []modules.ContractEventType{"unknown"}}
+	if err := filter.Validate(); err == nil {
+		t.Fatal("expected unknown event type to be rejected")
+	}
+
+	// Reload the journal.
+	if err := j.callClose(); err != nil {
+		t.Fatal(err)
+	}
+	j, err = newContractEventJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := j.callClose(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	got := j.callEvents(modules.ContractEventFilter{})
+	if len(got) != len(events) {
+		t.Fatalf("expected %v events after reload but got %v", len(events), len(got))
+	}
+	if got[2].Reason != reasonLowHostScore || got[3].RenewedFrom != events[3].RenewedFrom {
+		t.Fatal("events weren't persisted correctly", got)
+	}
+}
+
+// TestContractEventJournalBounded tests that the journal only retains the most
+// recent events and compacts its persist file.
+func TestContractEventJournalBounded(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("contractor", t.Name())
+	j, err := newContractEventJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Record more events than the journal retains.
+	numEvents := 2*contractEventsMaxEvents + 3
+	for i := 0; i < numEvents; i++ {
+		e := modules.ContractEvent{Type: modules.ContractEventFormed, BlockHeight: types.BlockHeight(i)}
+		if err := j.callAppend(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	checkEvents := func() {
+		t.Helper()
+		got := j.callEvents(modules.ContractEventFilter{})
+		if len(got) != contractEventsMaxEvents {
+			t.Fatalf("expected %v events but got %v", contractEventsMaxEvents, len(got))
+		}
+		for i, e := range got {
+			if e.BlockHeight != types.BlockHeight(numEvents-contractEventsMaxEvents+i) {
+				t.Fatal("unexpected event", i, e)
+			}
+		}
+	}
+	checkEvents()
+
+	// The persist file was compacted.
+	if j.persisted >= 2*contractEventsMaxEvents {
+		t.Fatal("persist file wasn't compacted", j.persisted)
+	}
+
+	// Reload the journal.
+	if err := j.callClose(); err != nil {
+		t.Fatal(err)
+	}
+	j, err = newContractEventJournal(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := j.callClose(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	checkEvents()
+	if j.persisted != contractEventsMaxEvents {
+		t.Fatal("persist file wasn't compacted on load", j.persisted)
+	}
+}
+
+// TestRecordUtilityEventsRenewed tests that no utility events are recorded for
+// contracts that lose their utility because they were renewed.
+func TestRecordUtilityEventsRenewed(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	j, err := newContractEventJournal(build.TempDir("contractor", t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := j.callClose(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	c := &Contractor{staticContractEvents: j}
+
+	contract := modules.RenterContract{
+		ID:      types.FileContractID{1},
+		Utility: modules.ContractUtility{GoodForUpload: true, GoodForRenew: true},
+	}
+	c.callRecordUtilityEvents(contract, modules.ContractUtility{Locked: true}, reasonRenewed)
+	if events := j.callEvents(modules.ContractEventFilter{}); len(events) != 0 {
+		t.Fatal("expected no events for a renewed contract", events)
+	}
+	c.callRecordUtilityEvents(contract, modules.ContractUtility{}, reasonLowHostScore)
+	if events := j.callEvents(modules.ContractEventFilter{}); len(events) != 2 {
+		t.Fatal("expected two utility events", events)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/proto"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/types"
 )
 
@@ -49,6 +50,10 @@ type (
 		id         types.FileContractID
 		amount     types.Currency
 		hostPubKey types.SiaPublicKey
+
+		// refresh indicates that the contract is renewed because it ran
+		// out of funds rather than because it is about to expire.
+		refresh bool
 	}
 )
 
@@ -57,17 +62,33 @@ type (
 // marks down the host score, and marks the contract as !GoodForRenew and
 // !GoodForUpload.
 func (c *Contractor) callNotifyDoubleSpend(fcID types.FileContractID, blockHeight types.BlockHeight) {
-	c.log.Println("Watchdog found a double-spend: ", fcID, blockHeight)
+	doubleSpendLog := c.log.WithFields(persist.Field(persist.LogKeyContractID, fcID))
+	doubleSpendLog.Println("Watchdog found a double-spend at height", blockHeight)
 
 	// Mark the contract as double-spent. This will cause the contract to be
 	// excluded in period spending.
 	c.mu.Lock()
 	c.doubleSpentContracts[fcID] = blockHeight
+	contract, ok := c.oldContracts[fcID]
 	c.mu.Unlock()
 
-	err := c.MarkContractBad(fcID)
+	// Record the double-spend in the event journal.
+	if active, exists := c.staticContracts.View(fcID); exists {
+		contract, ok = active, true
+	}
+	if ok {
+		c.callRecordContractEvent(modules.ContractEventDoubleSpend, contract, fmt.Sprintf("formation transaction double-spent at height %v", blockHeight))
+	}
+
+	sc, exists := c.staticContracts.Acquire(fcID)
+	if !exists {
+		doubleSpendLog.WithFields(persist.Field(persist.LogKeyError, errContractNotFound)).Println("callNotifyDoubleSpend error in MarkContractBad")
+		return
+	}
+	defer c.staticContracts.Return(sc)
+	err := c.managedMarkContractBad(sc, "formation transaction was double-spent")
 	if err != nil {
-		c.log.Println("callNotifyDoubleSpend error in MarkContractBad", err)
+		doubleSpendLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("callNotifyDoubleSpend error in MarkContractBad")
 	}
 }
 
@@ -455,7 +476,11 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 	c.mu.Unlock()
 
 	contractValue := contract.RenterFunds
-	c.log.Printf("Formed contract %v with %v for %v", contract.ID, host.NetAddress, contractValue.HumanString())
+	c.log.WithFields(
+		persist.Field(persist.LogKeyContractID, contract.ID),
+		persist.Field(persist.LogKeyHostKey, contract.HostPublicKey),
+	).Printf("Formed contract with %v for %v", host.NetAddress, contractValue.HumanString())
+	c.callRecordContractEvent(modules.ContractEventFormed, contract, "")
 
 	// Update the hostdb to include the new contract.
 	err = c.hdb.UpdateContracts(c.staticContracts.ViewAll())
@@ -495,7 +520,7 @@ func (c *Contractor) managedPrunedRedundantAddressRange() {
 		return
 	}
 	for _, host := range badHosts {
-		if err := c.managedCancelContract(cids[host.String()]); err != nil {
+		if err := c.managedCancelContract(cids[host.String()], "host violates the address range rules"); err != nil {
 			c.log.Print("WARNING: Wasn't able to cancel contract in managedPrunedRedundantAddressRange", err)
 		}
 	}
@@ -547,7 +572,7 @@ func (c *Contractor) managedLimitGFUHosts() {
 		}
 		u := sc.Utility()
 		u.GoodForUpload = false
-		err := c.managedUpdateContractUtility(sc, u)
+		err := c.managedUpdateContractUtility(sc, u, "too many hosts are good for upload")
 		c.staticContracts.Return(sc)
 		if err != nil {
 			c.log.Print("managedLimitGFUHosts: failed to update GFU contract utility")
@@ -766,6 +791,10 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	id := renewInstructions.id
 	amount := renewInstructions.amount
 	hostPubKey := renewInstructions.hostPubKey
+	renewLog := c.log.WithFields(
+		persist.Field(persist.LogKeyContractID, id),
+		persist.Field(persist.LogKeyHostKey, hostPubKey),
+	)
 
 	// Get a session with the host, before marking it as being renewed.
 	hs, err := c.Session(hostPubKey, c.tg.StopChan())
@@ -777,12 +806,12 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 
 	// Mark the contract as being renewed, and defer logic to unmark it
 	// once renewing is complete.
-	c.log.Debugln("Marking a contract for renew:", id)
+	renewLog.Debugln("Marking a contract for renew")
 	c.mu.Lock()
 	c.renewing[id] = true
 	c.mu.Unlock()
 	defer func() {
-		c.log.Debugln("Unmarking the contract for renew", id)
+		renewLog.Debugln("Unmarking the contract for renew")
 		c.mu.Lock()
 		delete(c.renewing, id)
 		c.mu.Unlock()
@@ -796,14 +825,14 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	d, dok := c.downloaders[id]
 	c.mu.RUnlock()
 	if eok {
-		c.log.Debugln("Waiting for editor invalidation")
+		renewLog.Debugln("Waiting for editor invalidation")
 		e.invalidate()
-		c.log.Debugln("Got editor invalidation")
+		renewLog.Debugln("Got editor invalidation")
 	}
 	if dok {
-		c.log.Debugln("Waiting for downloader invalidation")
+		renewLog.Debugln("Waiting for downloader invalidation")
 		d.invalidate()
-		c.log.Debugln("Got downloader invalidation")
+		renewLog.Debugln("Got downloader invalidation")
 	}
 
 	// Use the Settings RPC with the host and then invalidate the session.
@@ -812,18 +841,18 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 		err = errors.AddContext(err, "Unable to get host settings")
 		return
 	}
-	c.log.Debugln("Waiting for session invalidation")
+	renewLog.Debugln("Waiting for session invalidation")
 	s.invalidate()
-	c.log.Debugln("Got session invalidation")
+	renewLog.Debugln("Got session invalidation")
 
 	// Perform the actual renew. If the renew fails, return the
 	// contract. If the renew fails we check how often it has failed
 	// before. Once it has failed for a certain number of blocks in a
 	// row and reached its second half of the renew window, we give up
 	// on renewing it and set goodForRenew to false.
-	c.log.Debugln("calling managedRenew on contract", id)
+	renewLog.Debugln("calling managedRenew on contract")
 	newContract, errRenew := c.managedRenew(id, hostPubKey, amount, endHeight, hostSettings)
-	c.log.Debugln("managedRenew has returned with error:", errRenew)
+	renewLog.Debugln("managedRenew has returned with error:", errRenew)
 	oldContract, exists := c.staticContracts.Acquire(id)
 	if !exists {
 		return types.ZeroCurrency, errors.AddContext(errContractNotFound, "failed to acquire oldContract after renewal")
@@ -837,7 +866,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 			c.numFailedRenews[oldContract.Metadata().ID]++
 			totalFailures := c.numFailedRenews[oldContract.Metadata().ID]
 			c.mu.Unlock()
-			c.log.Debugln("remote host determined to be at fault, tallying up failed renews", totalFailures, id)
+			renewLog.Debugln("remote host determined to be at fault, tallying up failed renews", totalFailures)
 		}
 
 		// Check if contract has to be replaced.
@@ -845,30 +874,30 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 		c.mu.RLock()
 		numRenews, failedBefore := c.numFailedRenews[md.ID]
 		c.mu.RUnlock()
+		failLog := renewLog.WithFields(persist.Field(persist.LogKeyError, errRenew))
 		secondHalfOfWindow := blockHeight+allowance.RenewWindow/2 >= md.EndHeight
 		replace := numRenews >= consecutiveRenewalsBeforeReplacement
 		if failedBefore && secondHalfOfWindow && replace {
 			oldUtility.GoodForRenew = false
 			oldUtility.GoodForUpload = false
 			oldUtility.Locked = true
-			err := c.callUpdateUtility(oldContract, oldUtility, true)
+			err := c.callUpdateUtility(oldContract, oldUtility, true, "too many consecutive failed renewals: "+errRenew.Error())
 			if err != nil {
-				c.log.Println("WARN: failed to mark contract as !goodForRenew:", err)
+				renewLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("WARN: failed to mark contract as !goodForRenew")
 			}
-			c.log.Printf("WARN: consistently failed to renew %v, marked as bad and locked: %v\n",
-				oldContract.Metadata().HostPublicKey, errRenew)
+			failLog.Println("WARN: consistently failed to renew contract, marked as bad and locked")
 			c.staticContracts.Return(oldContract)
 			return types.ZeroCurrency, errors.AddContext(errRenew, "contract marked as bad for too many consecutive failed renew attempts")
 		}
 
 		// Seems like it doesn't have to be replaced yet. Log the
 		// failure and number of renews that have failed so far.
-		c.log.Printf("WARN: failed to renew contract %v [%v]: '%v', current height: %v, proposed end height: %v, max duration: %v",
-			oldContract.Metadata().HostPublicKey, numRenews, errRenew, blockHeight, endHeight, hostSettings.MaxDuration)
+		failLog.Printf("WARN: failed to renew contract [%v], current height: %v, proposed end height: %v, max duration: %v",
+			numRenews, blockHeight, endHeight, hostSettings.MaxDuration)
 		c.staticContracts.Return(oldContract)
 		return types.ZeroCurrency, errors.AddContext(errRenew, "contract renewal with host was unsuccessful")
 	}
-	c.log.Printf("Renewed contract %v\n", id)
+	renewLog.Println("Renewed contract")
 
 	// Skip the deletion of the old contract if required and delete the new
 	// contract to make sure we keep using the old one even though it has been
@@ -888,16 +917,16 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 		GoodForUpload: true,
 		GoodForRenew:  true,
 	}
-	if err := c.managedAcquireAndUpdateContractUtility(newContract.ID, newUtility); err != nil {
-		c.log.Println("Failed to update the contract utilities", err)
+	if err := c.managedAcquireAndUpdateContractUtility(newContract.ID, newUtility, ""); err != nil {
+		renewLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("Failed to update the contract utilities")
 		c.staticContracts.Return(oldContract)
 		return amount, nil // Error is not returned because the renew succeeded.
 	}
 	oldUtility.GoodForRenew = false
 	oldUtility.GoodForUpload = false
 	oldUtility.Locked = true
-	if err := c.callUpdateUtility(oldContract, oldUtility, true); err != nil {
-		c.log.Println("Failed to update the contract utilities", err)
+	if err := c.callUpdateUtility(oldContract, oldUtility, true, reasonRenewed); err != nil {
+		renewLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("Failed to update the contract utilities")
 		c.staticContracts.Return(oldContract)
 		return amount, nil // Error is not returned because the renew succeeded.
 	}
@@ -917,12 +946,19 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	// Save the contractor.
 	err = c.save()
 	if err != nil {
-		c.log.Println("Failed to save the contractor after creating a new contract.")
+		renewLog.Println("Failed to save the contractor after creating a new contract.")
 	}
 	c.mu.Unlock()
 	// Delete the old contract.
 	c.staticContracts.Delete(oldContract)
 
+	// Record the renewal in the event journal.
+	if renewInstructions.refresh {
+		c.callRecordContractEvent(modules.ContractEventRefreshed, newContract, "contract ran out of funds")
+	} else {
+		c.callRecordContractEvent(modules.ContractEventRenewed, newContract, "contract is up for renewal")
+	}
+
 	// Signal to the watchdog that it should immediately post the last
 	// revision for this contract.
 	go c.staticWatchdog.threadedSendMostRecentRevision(oldContract.Metadata())
@@ -947,19 +983,19 @@ func (c *Contractor) managedFindRecoverableContracts() {
 
 // managedAcquireAndUpdateContractUtility is a helper function that acquires a contract, updates
 // its ContractUtility and returns the contract again.
-func (c *Contractor) managedAcquireAndUpdateContractUtility(id types.FileContractID, utility modules.ContractUtility) error {
+func (c *Contractor) managedAcquireAndUpdateContractUtility(id types.FileContractID, utility modules.ContractUtility, reason string) error {
 	safeContract, ok := c.staticContracts.Acquire(id)
 	if !ok {
 		return errors.New("failed to acquire contract for update")
 	}
 	defer c.staticContracts.Return(safeContract)
 
-	return c.managedUpdateContractUtility(safeContract, utility)
+	return c.managedUpdateContractUtility(safeContract, utility, reason)
 }
 
 // managedUpdateContractUtility is a helper function that updates the contract
 // with the given utility.
-func (c *Contractor) managedUpdateContractUtility(safeContract *proto.SafeContract, utility modules.ContractUtility) error {
+func (c *Contractor) managedUpdateContractUtility(safeContract *proto.SafeContract, utility modules.ContractUtility, reason string) error {
 	// Sanity check to verify that we aren't attempting to set a good utility on
 	// a contract that has been renewed.
 	c.mu.Lock()
@@ -969,23 +1005,35 @@ func (c *Contractor) managedUpdateContractUtility(safeContract *proto.SafeContra
 		c.log.Critical("attempting to update contract utility on a contract that has been renewed")
 	}
 
-	return c.callUpdateUtility(safeContract, utility, false)
+	return c.callUpdateUtility(safeContract, utility, false, reason)
 }
 
 // callUpdateUtility updates the utility of a contract and notifies the
 // churnLimiter of churn if necessary. This method should *always* be used as
 // opposed to calling UpdateUtility directly on a safe contract from the
 // contractor. Pass in renewed as true if the contract has been renewed and is
-// not churn.
-func (c *Contractor) callUpdateUtility(safeContract *proto.SafeContract, newUtility modules.ContractUtility, renewed bool) error {
+// not churn. The reason is recorded in the contract event journal if the
+// contract loses its utility.
+func (c *Contractor) callUpdateUtility(safeContract *proto.SafeContract, newUtility modules.ContractUtility, renewed bool, reason string) error {
 	contract := safeContract.Metadata()
 
 	// If the contract is going from GFR to !GFR, notify the churn limiter.
-	if !renewed && contract.Utility.GoodForRenew && !newUtility.GoodForRenew {
+	churned := !renewed && contract.Utility.GoodForRenew && !newUtility.GoodForRenew
+	if churned {
 		c.staticChurnLimiter.callNotifyChurnedContract(contract)
 	}
 
-	return safeContract.UpdateUtility(newUtility)
+	err := safeContract.UpdateUtility(newUtility)
+	if err != nil {
+		return err
+	}
+
+	// Record the loss of utility in the event journal.
+	c.callRecordUtilityEvents(contract, newUtility, reason)
+	if churned && contract.Size() > 0 {
+		c.callRecordContractEvent(modules.ContractEventChurned, contract, reason)
+	}
+	return nil
 }
 
 // threadedContractMaintenance checks the set of contracts that the contractor
@@ -1091,21 +1139,25 @@ func (c *Contractor) threadedContractMaintenance() {
 	// Iterate through the contracts again, figuring out which contracts to
 	// renew and how much extra funds to renew them with.
 	for _, contract := range c.staticContracts.ViewAll() {
-		c.log.Debugln("Examining a contract:", contract.HostPublicKey, contract.ID)
+		contractLog := c.log.WithFields(
+			persist.Field(persist.LogKeyContractID, contract.ID),
+			persist.Field(persist.LogKeyHostKey, contract.HostPublicKey),
+		)
+		contractLog.Debugln("Examining a contract")
 		// Skip any host that does not match our whitelist/blacklist filter
 		// settings.
 		host, _, err := c.hdb.Host(contract.HostPublicKey)
 		if err != nil {
-			c.log.Println("WARN: error getting host", err)
+			contractLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("WARN: error getting host")
 			continue
 		}
 		if host.Filtered {
-			c.log.Debugln("Contract skipped because it is filtered")
+			contractLog.Debugln("Contract skipped because it is filtered")
 			continue
 		}
 		// Skip hosts that can't use the current renter-host protocol.
 		if build.VersionCmp(host.Version, modules.MinimumSupportedRenterHostProtocolVersion) < 0 {
-			c.log.Debugln("Contract skipped because host is using an outdated version", host.Version)
+			contractLog.Debugln("Contract skipped because host is using an outdated version", host.Version)
 			continue
 		}
 
@@ -1114,9 +1166,9 @@ func (c *Contractor) threadedContractMaintenance() {
 		utility, ok := c.managedContractUtility(contract.ID)
 		if !ok || !utility.GoodForRenew {
 			if blockHeight-contract.StartHeight < types.BlocksPerWeek {
-				c.log.Debugln("Contract did not last 1 week and is not being renewed", contract.ID)
+				contractLog.Debugln("Contract did not last 1 week and is not being renewed")
 			}
-			c.log.Debugln("Contract skipped because it is not good for renew (utility.GoodForRenew, exists)", utility.GoodForRenew, ok)
+			contractLog.Debugln("Contract skipped because it is not good for renew (utility.GoodForRenew, exists)", utility.GoodForRenew, ok)
 			continue
 		}
 
@@ -1127,7 +1179,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		if blockHeight+allowance.RenewWindow >= contract.EndHeight && !c.staticDeps.Disrupt("disableRenew") {
 			renewAmount, err := c.managedEstimateRenewFundingRequirements(contract, blockHeight, allowance)
 			if err != nil {
-				c.log.Debugln("Contract skipped because there was an error estimating renew funding requirements", renewAmount, err)
+				contractLog.Debugln("Contract skipped because there was an error estimating renew funding requirements", renewAmount, err)
 				continue
 			}
 			renewSet = append(renewSet, fileContractRenewal{
@@ -1135,7 +1187,7 @@ func (c *Contractor) threadedContractMaintenance() {
 				amount:     renewAmount,
 				hostPubKey: contract.HostPublicKey,
 			})
-			c.log.Debugln("Contract has been added to the renew set for being past the renew height")
+			contractLog.Debugln("Contract has been added to the renew set for being past the renew height")
 			continue
 		}
 
@@ -1173,10 +1225,11 @@ func (c *Contractor) threadedContractMaintenance() {
 				id:         contract.ID,
 				amount:     refreshAmount,
 				hostPubKey: contract.HostPublicKey,
+				refresh:    true,
 			})
-			c.log.Debugln("Contract identified as needing to be added to refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
+			contractLog.Debugln("Contract identified as needing to be added to refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
 		} else {
-			c.log.Debugln("Contract did not get added to the refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
+			contractLog.Debugln("Contract did not get added to the refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
 		}
 	}
 	if len(renewSet) != 0 || len(refreshSet) != 0 {
@@ -1219,6 +1272,15 @@ func (c *Contractor) threadedContractMaintenance() {
 	}
 	c.log.Debugln("Remaining funds in allowance:", fundsRemaining.HumanString())
 
+	// Once the fee cap is reached, no more contracts are formed or refreshed
+	// in this period. Contracts which are about to expire are still renewed
+	// to avoid losing data.
+	capStatus := c.managedUpdateSpendingCapStatus(spending, allowance)
+	if capStatus.FeeCapReached && len(refreshSet) > 0 {
+		c.log.Printf("skipping %v contract refreshes because the fee spending cap was reached", len(refreshSet))
+		refreshSet = nil
+	}
+
 	// Keep track of the total number of renews that failed for any reason.
 	var numRenewFails int
 
@@ -1271,10 +1333,14 @@ func (c *Contractor) threadedContractMaintenance() {
 			return
 		}
 
-		c.log.Println("Attempting to perform a renewal:", renewal.id)
+		renewalLog := c.log.WithFields(
+			persist.Field(persist.LogKeyContractID, renewal.id),
+			persist.Field(persist.LogKeyHostKey, renewal.hostPubKey),
+		)
+		renewalLog.Println("Attempting to perform a renewal")
 		// Skip this renewal if we don't have enough funds remaining.
 		if renewal.amount.Cmp(fundsRemaining) > 0 || c.staticDeps.Disrupt("LowFundsRenewal") {
-			c.log.Println("Skipping renewal because there are not enough funds remaining in the allowance", renewal.id, renewal.amount, fundsRemaining)
+			renewalLog.Println("Skipping renewal because there are not enough funds remaining in the allowance", renewal.amount, fundsRemaining)
 			registerLowFundsAlert = true
 			continue
 		}
@@ -1285,13 +1351,13 @@ func (c *Contractor) threadedContractMaintenance() {
 		fundsSpent, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
 		if errors.Contains(err, errContractNotGFR) {
 			// Do not add a renewal error.
-			c.log.Debugln("Contract skipped because it is not good for renew", renewal.id)
+			renewalLog.Debugln("Contract skipped because it is not good for renew")
 		} else if err != nil {
-			c.log.Println("Error renewing a contract", renewal.id, err)
+			renewalLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("Error renewing a contract")
 			renewErr = errors.Compose(renewErr, err)
 			numRenewFails++
 		} else {
-			c.log.Println("Renewal completed without error")
+			renewalLog.Println("Renewal completed without error")
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 	}
@@ -1315,9 +1381,13 @@ func (c *Contractor) threadedContractMaintenance() {
 		}
 
 		// Skip this renewal if we don't have enough funds remaining.
-		c.log.Debugln("Attempting to perform a contract refresh:", renewal.id)
+		refreshLog := c.log.WithFields(
+			persist.Field(persist.LogKeyContractID, renewal.id),
+			persist.Field(persist.LogKeyHostKey, renewal.hostPubKey),
+		)
+		refreshLog.Debugln("Attempting to perform a contract refresh")
 		if renewal.amount.Cmp(fundsRemaining) > 0 || c.staticDeps.Disrupt("LowFundsRefresh") {
-			c.log.Println("skipping refresh because there are not enough funds remaining in the allowance", renewal.amount.HumanString(), fundsRemaining.HumanString())
+			refreshLog.Println("skipping refresh because there are not enough funds remaining in the allowance", renewal.amount.HumanString(), fundsRemaining.HumanString())
 			registerLowFundsAlert = true
 			continue
 		}
@@ -1327,11 +1397,11 @@ func (c *Contractor) threadedContractMaintenance() {
 		// 'fundsSpent' will return '0'.
 		fundsSpent, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
 		if err != nil {
-			c.log.Println("Error refreshing a contract", renewal.id, err)
+			refreshLog.WithFields(persist.Field(persist.LogKeyError, err)).Println("Error refreshing a contract")
 			renewErr = errors.Compose(renewErr, err)
 			numRenewFails++
 		} else {
-			c.log.Println("Refresh completed without error")
+			refreshLog.Println("Refresh completed without error")
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 	}
@@ -1347,6 +1417,10 @@ func (c *Contractor) threadedContractMaintenance() {
 	c.mu.RLock()
 	neededContracts := int(c.allowance.Hosts) - uploadContracts
 	c.mu.RUnlock()
+	if capStatus.FeeCapReached && neededContracts > 0 {
+		c.log.Println("not forming new contracts because the fee spending cap was reached")
+		neededContracts = 0
+	}
 	if neededContracts > 0 {
 		c.log.Println("need more contracts:", neededContracts)
 	}
@@ -1377,6 +1451,10 @@ func (c *Contractor) threadedContractMaintenance() {
 	minInitialContractFunds := c.allowance.Funds.Div64(c.allowance.Hosts).Div64(MinInitialContractFundingDivFactor)
 	c.mu.RUnlock()
 
+	// The hosts of the active contracts count towards the diversity limits of
+	// new hosts.
+	diversityHosts := append([]types.SiaPublicKey{}, addressBlacklist...)
+
 	// Get Hosts
 	hosts, err := c.hdb.RandomHosts(neededContracts*4+randomHostsBufferForScore, blacklist, addressBlacklist)
 	if err != nil {
@@ -1438,6 +1516,28 @@ func (c *Contractor) threadedContractMaintenance() {
 			break
 		}
 
+		// Skip hosts which would violate the diversity rules.
+		violations, err := c.hdb.CheckForDiversityViolations(append(diversityHosts, host.PublicKey))
+		if err != nil {
+			c.log.Println("WARN: error checking for diversity violations:", err)
+			continue
+		}
+		if len(violations) > 0 && violations[len(violations)-1].Equals(host.PublicKey) {
+			c.log.Debugln("skipping host because of diversity rules:", host.PublicKey)
+			continue
+		}
+
+		// Skip hosts which violate the host selection policy.
+		violations, err = c.hdb.CheckForPolicyViolations([]types.SiaPublicKey{host.PublicKey})
+		if err != nil {
+			c.log.Println("WARN: error checking for policy violations:", err)
+			continue
+		}
+		if len(violations) > 0 {
+			c.log.Debugln("skipping host because of the host selection policy:", host.PublicKey)
+			continue
+		}
+
 		// If we are using a custom resolver we need to replace the domain name
 		// with 127.0.0.1 to be able to form contracts.
 		if c.staticDeps.Disrupt("customResolver") {
@@ -1454,6 +1554,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 		neededContracts--
+		diversityHosts = append(diversityHosts, host.PublicKey)
 
 		sb, err := c.hdb.ScoreBreakdown(host)
 		if err == nil {
@@ -1465,6 +1566,8 @@ func (c *Contractor) threadedContractMaintenance() {
 			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
 			c.log.Println("Duration Adjustment:   ", sb.DurationAdjustment)
 			c.log.Println("Interaction Adjustment:", sb.InteractionAdjustment)
+			c.log.Println("Performance Adjustment:", sb.PerformanceAdjustment)
+			c.log.Println("Policy Adjustment:     ", sb.PolicyAdjustment)
 			c.log.Println("Price Adjustment:      ", sb.PriceAdjustment)
 			c.log.Println("Storage Adjustment:    ", sb.StorageRemainingAdjustment)
 			c.log.Println("Uptime Adjustment:     ", sb.UptimeAdjustment)
@@ -1475,7 +1578,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		err = c.managedAcquireAndUpdateContractUtility(newContract.ID, modules.ContractUtility{
 			GoodForUpload: true,
 			GoodForRenew:  true,
-		})
+		}, "")
 		if err != nil {
 			c.log.Println("Failed to update the contract utilities", err)
 			return
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/ratelimit"
@@ -83,6 +84,16 @@ type Contractor struct {
 	// is unlocked.
 	recentRecoveryChange modules.ConsensusChangeID
 
+	// accountDownloadSpending is the money spent on downloads paid for by
+	// ephemeral accounts in the current period. The workers report it since
+	// the contracts only know about the money used to fund the accounts.
+	accountDownloadSpending types.Currency
+
+	// spendingCapStatus is the most recently computed status of the
+	// allowance's spending caps.
+	spendingCapStatus        modules.SpendingCapStatus
+	spendingCapStatusUpdated time.Time
+
 	downloaders     map[types.FileContractID]*hostDownloader
 	editors         map[types.FileContractID]*hostEditor
 	sessions        map[types.FileContractID]*hostSession
@@ -105,8 +116,9 @@ type Contractor struct {
 	renewedFrom          map[types.FileContractID]types.FileContractID
 	renewedTo            map[types.FileContractID]types.FileContractID
 
-	staticChurnLimiter *churnLimiter
-	staticWatchdog     *watchdog
+	staticChurnLimiter   *churnLimiter
+	staticContractEvents *contractEventJournal
+	staticWatchdog       *watchdog
 }
 
 // PaymentDetails is a helper struct that contains extra information on a
@@ -182,6 +194,7 @@ func (c *Contractor) PeriodSpending() (modules.ContractorSpending, error) {
 		spending.UploadSpending = spending.UploadSpending.Add(contract.UploadSpending)
 		spending.StorageSpending = spending.StorageSpending.Add(contract.StorageSpending)
 	}
+	spending.AccountDownloadSpending = c.accountDownloadSpending
 
 	// Calculate needed spending to be reported from old contracts
 	for _, contract := range c.oldContracts {
@@ -318,7 +331,7 @@ func (c *Contractor) ProvidePayment(stream io.ReadWriter, pt *modules.RPCPriceTa
 	if err := modules.RPCRead(stream, &payByResponse); err != nil {
 		if strings.Contains(err.Error(), "storage obligation not found") {
 			c.log.Printf("Marking contract %v as bad because host %v did not recognize it: %v", contract.ID, host, err)
-			mbcErr := c.managedMarkContractBad(sc)
+			mbcErr := c.managedMarkContractBad(sc, "host did not recognize the contract")
 			if mbcErr != nil {
 				c.log.Printf("Unable to mark contract %v on host %v as bad: %v", contract.ID, host, mbcErr)
 			}
@@ -512,6 +525,16 @@ func contractorBlockingStartup(cs modules.ConsensusSet, w modules.Wallet, tp mod
 		return nil, err
 	}
 
+	// Load the contract event journal.
+	c.staticContractEvents, err = newContractEventJournal(persistDir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load contract event journal")
+	}
+	err = c.tg.AfterStop(c.staticContractEvents.callClose)
+	if err != nil {
+		return nil, err
+	}
+
 	// Load the prior persistence structures.
 	err = c.load()
 	if err != nil && !os.IsNotExist(err) {
@@ -17,13 +17,14 @@ func (c *Contractor) contractEndHeight() types.BlockHeight {
 
 // managedCancelContract cancels a contract by setting its utility fields to
 // false and locking the utilities. The contract can still be used for
-// downloads after this but it won't be used for uploads or renewals.
-func (c *Contractor) managedCancelContract(cid types.FileContractID) error {
+// downloads after this but it won't be used for uploads or renewals. The
+// reason is recorded in the contract event journal.
+func (c *Contractor) managedCancelContract(cid types.FileContractID, reason string) error {
 	return c.managedAcquireAndUpdateContractUtility(cid, modules.ContractUtility{
 		GoodForRenew:  false,
 		GoodForUpload: false,
 		Locked:        true,
-	})
+	}, reason)
 }
 
 // managedContractByPublicKey returns the contract with the key specified, if
@@ -127,7 +128,7 @@ func (c *Contractor) CancelContract(id types.FileContractID) error {
 	}
 	defer c.tg.Done()
 	defer c.threadedContractMaintenance()
-	return c.managedCancelContract(id)
+	return c.managedCancelContract(id, "contract was canceled")
 }
 
 // Contracts returns the contracts formed by the contractor in the current
@@ -160,7 +161,7 @@ func (c *Contractor) MarkContractBad(id types.FileContractID) error {
 		return errors.New("contract not found")
 	}
 	defer c.staticContracts.Return(sc)
-	return c.managedMarkContractBad(sc)
+	return c.managedMarkContractBad(sc, "contract was marked as bad")
 }
 
 // OldContracts returns the contracts formed by the contractor that have
@@ -189,12 +190,13 @@ func (c *Contractor) RecoverableContracts() []modules.RecoverableContract {
 	return contracts
 }
 
-// managedMarkContractBad marks an already acquired SafeContract as bad.
-func (c *Contractor) managedMarkContractBad(sc *proto.SafeContract) error {
+// managedMarkContractBad marks an already acquired SafeContract as bad. The
+// reason is recorded in the contract event journal.
+func (c *Contractor) managedMarkContractBad(sc *proto.SafeContract, reason string) error {
 	u := sc.Utility()
 	u.GoodForUpload = false
 	u.GoodForRenew = false
 	u.BadContract = true
-	err := c.callUpdateUtility(sc, u, false)
+	err := c.callUpdateUtility(sc, u, false, reason)
 	return errors.AddContext(err, "unable to mark contract as bad")
 }
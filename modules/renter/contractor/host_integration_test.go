@@ -526,7 +526,7 @@ func TestIntegrationRenew(t *testing.T) {
 	hostSettings := editor.HostSettings()
 
 	// renew the contract
-	err = c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{GoodForRenew: true})
+	err = c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{GoodForRenew: true}, "")
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -558,7 +558,7 @@ func TestIntegrationRenew(t *testing.T) {
 	}
 
 	// renew to a lower height
-	err = c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{GoodForRenew: true})
+	err = c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{GoodForRenew: true}, "")
 	if err != nil {
 		t.Fatal(err)
 	}
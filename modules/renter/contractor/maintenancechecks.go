@@ -77,6 +77,8 @@ func (c *Contractor) managedCheckHostScore(contract modules.RenterContract, sb m
 			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
 			c.log.Println("Duration Adjustment:   ", sb.DurationAdjustment)
 			c.log.Println("Interaction Adjustment:", sb.InteractionAdjustment)
+			c.log.Println("Performance Adjustment:", sb.PerformanceAdjustment)
+			c.log.Println("Policy Adjustment:     ", sb.PolicyAdjustment)
 			c.log.Println("Price Adjustment:      ", sb.PriceAdjustment)
 			c.log.Println("Storage Adjustment:    ", sb.StorageRemainingAdjustment)
 			c.log.Println("Uptime Adjustment:     ", sb.UptimeAdjustment)
@@ -107,6 +109,8 @@ func (c *Contractor) managedCheckHostScore(contract modules.RenterContract, sb m
 			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
 			c.log.Println("Duration Adjustment:   ", sb.DurationAdjustment)
 			c.log.Println("Interaction Adjustment:", sb.InteractionAdjustment)
+			c.log.Println("Performance Adjustment:", sb.PerformanceAdjustment)
+			c.log.Println("Policy Adjustment:     ", sb.PolicyAdjustment)
 			c.log.Println("Price Adjustment:      ", sb.PriceAdjustment)
 			c.log.Println("Storage Adjustment:    ", sb.StorageRemainingAdjustment)
 			c.log.Println("Uptime Adjustment:     ", sb.UptimeAdjustment)
@@ -126,13 +130,13 @@ func (c *Contractor) managedCheckHostScore(contract modules.RenterContract, sb m
 // managedCriticalUtilityChecks performs critical checks on a contract that
 // would require, with no exceptions, marking the contract as !GFR and/or !GFU.
 // Returns true if and only if and of the checks passed and require the utility
-// to be updated.
+// to be updated. The returned reason describes the failed check.
 //
 // NOTE: 'needsUpdate' should return 'true' if the contract should be marked as
 // !GFR and !GFU, even if the contract is already marked as such. If
 // 'needsUpdate' is set to true, other checks which may change those values will
 // be ignored and the contract will remain marked as having no utility.
-func (c *Contractor) managedCriticalUtilityChecks(sc *proto.SafeContract, host modules.HostDBEntry) (modules.ContractUtility, bool) {
+func (c *Contractor) managedCriticalUtilityChecks(sc *proto.SafeContract, host modules.HostDBEntry) (modules.ContractUtility, string, bool) {
 	contract := sc.Metadata()
 
 	c.mu.RLock()
@@ -145,40 +149,45 @@ func (c *Contractor) managedCriticalUtilityChecks(sc *proto.SafeContract, host m
 	// A contract that has been renewed should be set to !GFU and !GFR.
 	u, needsUpdate := c.renewedCheck(contract.Utility, renewed)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, reasonRenewed, needsUpdate
 	}
 
 	u, needsUpdate = c.maxRevisionCheck(contract.Utility, sc.LastRevision().NewRevisionNumber)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "contract reached its maximum revision number", needsUpdate
 	}
 
 	u, needsUpdate = c.badContractCheck(contract.Utility)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "contract is marked as bad", needsUpdate
 	}
 
 	u, needsUpdate = c.offlineCheck(contract, host)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "host is offline", needsUpdate
+	}
+
+	u, needsUpdate = c.managedHostPolicyCheck(contract)
+	if needsUpdate {
+		return u, "host violates the host selection policy", needsUpdate
 	}
 
 	u, needsUpdate = c.upForRenewalCheck(contract, renewWindow, blockHeight)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "contract is up for renewal", needsUpdate
 	}
 
 	u, needsUpdate = c.sufficientFundsCheck(contract, host, period)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "contract has insufficient funds", needsUpdate
 	}
 
 	u, needsUpdate = c.outOfStorageCheck(contract, blockHeight)
 	if needsUpdate {
-		return u, needsUpdate
+		return u, "host is out of storage", needsUpdate
 	}
 
-	return contract.Utility, false
+	return contract.Utility, "", false
 }
 
 // managedHostInHostDBCheck checks if the host is in the hostdb and not
@@ -207,6 +216,29 @@ func (c *Contractor) managedHostInHostDBCheck(contract modules.RenterContract) (
 	return host, u, false
 }
 
+// managedHostPolicyCheck checks if the host for this contract exceeds a price
+// cap, doesn't meet the minimum requirements or is located in an excluded
+// region or ASN of the host selection policy. Returns true if a check fails and
+// the utility returned must be used to update the contract state.
+func (c *Contractor) managedHostPolicyCheck(contract modules.RenterContract) (modules.ContractUtility, bool) {
+	u := contract.Utility
+	violations, err := c.hdb.CheckForPolicyViolations([]types.SiaPublicKey{contract.HostPublicKey})
+	if err != nil {
+		c.log.Println("WARN: error checking for policy violations:", err)
+		return u, false
+	}
+	if len(violations) == 0 {
+		return u, false
+	}
+	// Log if the utility has changed.
+	if u.GoodForUpload || u.GoodForRenew {
+		c.log.Println("Marking contract as having no utility because the host violates the host selection policy", contract.ID)
+	}
+	u.GoodForUpload = false
+	u.GoodForRenew = false
+	return u, true
+}
+
 // offLineCheck checks if the host for this contract is offline.
 // Returns true if a check fails and the utility returned must be used to update
 // the contract state.
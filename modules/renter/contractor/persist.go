@@ -27,17 +27,18 @@ var (
 
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
-	Allowance            modules.Allowance               `json:"allowance"`
-	BlockHeight          types.BlockHeight               `json:"blockheight"`
-	CurrentPeriod        types.BlockHeight               `json:"currentperiod"`
-	LastChange           modules.ConsensusChangeID       `json:"lastchange"`
-	RecentRecoveryChange modules.ConsensusChangeID       `json:"recentrecoverychange"`
-	OldContracts         []modules.RenterContract        `json:"oldcontracts"`
-	DoubleSpentContracts map[string]types.BlockHeight    `json:"doublespentcontracts"`
-	RecoverableContracts []modules.RecoverableContract   `json:"recoverablecontracts"`
-	RenewedFrom          map[string]types.FileContractID `json:"renewedfrom"`
-	RenewedTo            map[string]types.FileContractID `json:"renewedto"`
-	Synced               bool                            `json:"synced"`
+	AccountDownloadSpending types.Currency                  `json:"accountdownloadspending"`
+	Allowance               modules.Allowance               `json:"allowance"`
+	BlockHeight             types.BlockHeight               `json:"blockheight"`
+	CurrentPeriod           types.BlockHeight               `json:"currentperiod"`
+	LastChange              modules.ConsensusChangeID       `json:"lastchange"`
+	RecentRecoveryChange    modules.ConsensusChangeID       `json:"recentrecoverychange"`
+	OldContracts            []modules.RenterContract        `json:"oldcontracts"`
+	DoubleSpentContracts    map[string]types.BlockHeight    `json:"doublespentcontracts"`
+	RecoverableContracts    []modules.RecoverableContract   `json:"recoverablecontracts"`
+	RenewedFrom             map[string]types.FileContractID `json:"renewedfrom"`
+	RenewedTo               map[string]types.FileContractID `json:"renewedto"`
+	Synced                  bool                            `json:"synced"`
 
 	// Subsystem persistence:
 	ChurnLimiter churnLimiterPersist `json:"churnlimiter"`
@@ -53,15 +54,16 @@ func (c *Contractor) persistData() contractorPersist {
 	default:
 	}
 	data := contractorPersist{
-		Allowance:            c.allowance,
-		BlockHeight:          c.blockHeight,
-		CurrentPeriod:        c.currentPeriod,
-		LastChange:           c.lastChange,
-		RecentRecoveryChange: c.recentRecoveryChange,
-		RenewedFrom:          make(map[string]types.FileContractID),
-		RenewedTo:            make(map[string]types.FileContractID),
-		DoubleSpentContracts: make(map[string]types.BlockHeight),
-		Synced:               synced,
+		AccountDownloadSpending: c.accountDownloadSpending,
+		Allowance:               c.allowance,
+		BlockHeight:             c.blockHeight,
+		CurrentPeriod:           c.currentPeriod,
+		LastChange:              c.lastChange,
+		RecentRecoveryChange:    c.recentRecoveryChange,
+		RenewedFrom:             make(map[string]types.FileContractID),
+		RenewedTo:               make(map[string]types.FileContractID),
+		DoubleSpentContracts:    make(map[string]types.BlockHeight),
+		Synced:                  synced,
 	}
 	for k, v := range c.renewedFrom {
 		data.RenewedFrom[k.String()] = v
@@ -113,6 +115,7 @@ func (c *Contractor) load() error {
 		}
 	}
 
+	c.accountDownloadSpending = data.AccountDownloadSpending
 	c.allowance = data.Allowance
 	c.blockHeight = data.BlockHeight
 	c.currentPeriod = data.CurrentPeriod
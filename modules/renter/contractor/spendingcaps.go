@@ -0,0 +1,102 @@
+package contractor
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// spendingCap pairs the money spent on a spending category in the current
+// period with the allowance's cap for that category and the status field that
+// reports whether the cap was reached.
+type spendingCap struct {
+	name    string
+	spent   types.Currency
+	cap     types.Currency
+	reached *bool
+}
+
+// spendingCaps returns the spending caps of the allowance together with the
+// money spent on each category.
+func spendingCaps(spending modules.ContractorSpending, a modules.Allowance, status *modules.SpendingCapStatus) []spendingCap {
+	return []spendingCap{
+		{"upload", spending.UploadSpending, a.MaxUploadSpending, &status.UploadCapReached},
+		{"download", spending.DownloadSpending.Add(spending.AccountDownloadSpending), a.MaxDownloadSpending, &status.DownloadCapReached},
+		{"storage", spending.StorageSpending, a.MaxStorageSpending, &status.StorageCapReached},
+		{"fee", spending.ContractFees, a.MaxFeeSpending, &status.FeeCapReached},
+		{"fund account", spending.FundAccountSpending, a.MaxFundAccountSpending, &status.FundAccountCapReached},
+	}
+}
+
+// checkSpendingCaps compares the spending against the allowance's caps. It
+// returns the resulting status as well as descriptions of the categories that
+// reached their cap and of the categories that are past the allowance's alert
+// threshold without having reached their cap.
+func checkSpendingCaps(spending modules.ContractorSpending, a modules.Allowance) (status modules.SpendingCapStatus, reached, approaching []string) {
+	for _, sc := range spendingCaps(spending, a, &status) {
+		if sc.cap.IsZero() {
+			continue
+		}
+		desc := fmt.Sprintf("%v spending of %v out of %v", sc.name, sc.spent.HumanString(), sc.cap.HumanString())
+		if sc.spent.Cmp(sc.cap) < 0 {
+			if a.SpendingAlertThreshold > 0 && sc.spent.Cmp(sc.cap.MulFloat(a.SpendingAlertThreshold)) >= 0 {
+				approaching = append(approaching, desc)
+			}
+			continue
+		}
+		*sc.reached = true
+		reached = append(reached, desc)
+	}
+	return
+}
+
+// RecordAccountDownloadSpending adds money spent on a download paid for by an
+// ephemeral account to the current period's download spending.
+func (c *Contractor) RecordAccountDownloadSpending(amount types.Currency) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accountDownloadSpending = c.accountDownloadSpending.Add(amount)
+}
+
+// SpendingCapStatus returns which of the allowance's spending caps have been
+// reached in the current period. The status is cached and recomputed at most
+// once every spendingCapRecheckInterval.
+func (c *Contractor) SpendingCapStatus() modules.SpendingCapStatus {
+	c.mu.RLock()
+	status := c.spendingCapStatus
+	updated := c.spendingCapStatusUpdated
+	allowance := c.allowance
+	c.mu.RUnlock()
+	if time.Since(updated) < spendingCapRecheckInterval {
+		return status
+	}
+	spending, err := c.PeriodSpending()
+	if err != nil {
+		c.log.Println("WARN: unable to get period spending to check spending caps:", err)
+		return status
+	}
+	return c.managedUpdateSpendingCapStatus(spending, allowance)
+}
+
+// managedUpdateSpendingCapStatus recomputes the status of the spending caps,
+// caches it and registers or unregisters the spending cap alert.
+func (c *Contractor) managedUpdateSpendingCapStatus(spending modules.ContractorSpending, a modules.Allowance) modules.SpendingCapStatus {
+	status, reached, approaching := checkSpendingCaps(spending, a)
+	c.mu.Lock()
+	c.spendingCapStatus = status
+	c.spendingCapStatusUpdated = time.Now()
+	c.mu.Unlock()
+
+	cause := strings.Join(append(reached, approaching...), "; ")
+	if len(reached) > 0 {
+		c.staticAlerter.RegisterAlert(modules.AlertIDRenterSpendingCap, AlertMSGSpendingCapReached, cause, modules.SeverityError)
+	} else if len(approaching) > 0 {
+		c.staticAlerter.RegisterAlert(modules.AlertIDRenterSpendingCap, AlertMSGSpendingCapApproaching, cause, modules.SeverityWarning)
+	} else {
+		c.staticAlerter.UnregisterAlert(modules.AlertIDRenterSpendingCap)
+	}
+	return status
+}
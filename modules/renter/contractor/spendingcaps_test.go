@@ -0,0 +1,82 @@
+package contractor
+
+import (
+	"testing"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestSpendingCaps tests checking the spending against the allowance's
+// spending caps and the registration of the corresponding alert.
+func TestSpendingCaps(t *testing.T) {
+	t.Parallel()
+
+	spending := modules.ContractorSpending{
+		ContractFees:            types.NewCurrency64(50),
+		DownloadSpending:        types.NewCurrency64(60),
+		AccountDownloadSpending: types.NewCurrency64(40),
+		FundAccountSpending:     types.NewCurrency64(100),
+		StorageSpending:         types.NewCurrency64(80),
+		UploadSpending:          types.NewCurrency64(10),
+	}
+
+	// Without caps nothing is reached.
+	status, reached, approaching := checkSpendingCaps(spending, modules.Allowance{})
+	if status != (modules.SpendingCapStatus{}) || len(reached) != 0 || len(approaching) != 0 {
+		t.Fatal("unexpected result without caps", status, reached, approaching)
+	}
+
+	// The download cap includes the ephemeral account downloads and the
+	// storage cap is approached.
+	a := modules.Allowance{
+		MaxDownloadSpending:    types.NewCurrency64(100),
+		MaxStorageSpending:     types.NewCurrency64(100),
+		MaxUploadSpending:      types.NewCurrency64(100),
+		SpendingAlertThreshold: 0.8,
+	}
+	status, reached, approaching = checkSpendingCaps(spending, a)
+	expected := modules.SpendingCapStatus{DownloadCapReached: true}
+	if status != expected {
+		t.Fatal("unexpected status", status)
+	}
+	if len(reached) != 1 || len(approaching) != 1 {
+		t.Fatal("unexpected number of reached or approaching caps", reached, approaching)
+	}
+
+	// Without a threshold there is no warning.
+	a.SpendingAlertThreshold = 0
+	_, _, approaching = checkSpendingCaps(spending, a)
+	if len(approaching) != 0 {
+		t.Fatal("expected no approaching caps without a threshold", approaching)
+	}
+
+	// Check the alerts.
+	c := &Contractor{staticAlerter: modules.NewAlerter("contractor")}
+	spendingCapAlert := func() (modules.Alert, bool) {
+		crit, err, warn, info := c.Alerts()
+		for _, alert := range append(append(append(crit, err...), warn...), info...) {
+			if alert.Msg == AlertMSGSpendingCapReached || alert.Msg == AlertMSGSpendingCapApproaching {
+				return alert, true
+			}
+		}
+		return modules.Alert{}, false
+	}
+	status = c.managedUpdateSpendingCapStatus(spending, a)
+	if !status.DownloadCapReached || c.spendingCapStatus != status {
+		t.Fatal("status wasn't cached", c.spendingCapStatus)
+	}
+	if alert, ok := spendingCapAlert(); !ok || alert.Severity != modules.SeverityError {
+		t.Fatal("expected error alert", alert, ok)
+	}
+	a.MaxDownloadSpending = types.ZeroCurrency
+	a.SpendingAlertThreshold = 0.8
+	c.managedUpdateSpendingCapStatus(spending, a)
+	if alert, ok := spendingCapAlert(); !ok || alert.Severity != modules.SeverityWarning {
+		t.Fatal("expected warning alert", alert, ok)
+	}
+	c.managedUpdateSpendingCapStatus(spending, modules.Allowance{})
+	if alert, ok := spendingCapAlert(); ok {
+		t.Fatal("expected alert to be unregistered", alert)
+	}
+}
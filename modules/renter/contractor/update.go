@@ -118,6 +118,7 @@ func (c *Contractor) ProcessConsensusChange(cc modules.ConsensusChange) {
 	if c.allowance.Active() && c.blockHeight >= c.currentPeriod+c.allowance.Period {
 		c.currentPeriod += c.allowance.Period
 		c.staticChurnLimiter.callResetAggregateChurn()
+		c.accountDownloadSpending = types.ZeroCurrency
 
 		// COMPATv1.0.4-lts
 		// if we were storing a special metrics contract, it will be invalid
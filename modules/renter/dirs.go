@@ -16,6 +16,9 @@ func (r *Renter) CreateDir(siaPath modules.SiaPath, mode os.FileMode) error {
 		return err
 	}
 	defer r.tg.Done()
+	if err := checkUserSiaPath(siaPath); err != nil {
+		return err
+	}
 	return r.staticFileSystem.NewSiaDir(siaPath, mode)
 }
 
@@ -69,5 +72,9 @@ func (r *Renter) RenameDir(oldPath, newPath modules.SiaPath) error {
 	if newPath.IsRoot() {
 		return errors.New("cannot rename a file to the root directory")
 	}
+	err := errors.Compose(checkUserSiaPath(oldPath), checkUserSiaPath(newPath))
+	if err != nil {
+		return err
+	}
 	return r.staticFileSystem.RenameDir(oldPath, newPath)
 }
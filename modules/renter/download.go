@@ -19,6 +19,7 @@ import (
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/types"
 )
 
@@ -458,7 +459,10 @@ func (d *download) Start() error {
 				// the same chunk.
 				_, exists := chunkMaps[chunkIndex-minChunk][piece.HostPubKey.String()]
 				if exists {
-					d.r.log.Println("ERROR: Worker has multiple pieces uploaded for the same chunk.", params.file.SiaPath(), chunkIndex, pieceIndex, piece.HostPubKey.String())
+					d.r.log.WithFields(
+						persist.Field(persist.LogKeySiaPath, params.file.SiaPath()),
+						persist.Field(persist.LogKeyHostKey, piece.HostPubKey),
+					).Println("ERROR: Worker has multiple pieces uploaded for the same chunk.", chunkIndex, pieceIndex)
 				}
 				chunkMaps[chunkIndex-minChunk][piece.HostPubKey.String()] = downloadPieceInfo{
 					index: uint64(pieceIndex),
@@ -468,6 +472,9 @@ func (d *download) Start() error {
 		}
 	}
 
+	// User downloads make use of the chunk cache if it is enabled.
+	useChunkCache := params.staticSpendingCategory == categoryDownload && d.r.staticChunkCache.callEnabled()
+
 	// Queue the downloads for each chunk.
 	writeOffset := int64(0) // where to write a chunk within the download destination.
 	d.chunksRemaining += maxChunk - minChunk + 1
@@ -484,6 +491,7 @@ func (d *download) Start() error {
 			staticPieceSize:  params.file.PieceSize(),
 
 			staticSpendingCategory: d.staticParams.staticSpendingCategory,
+			staticCacheLookup:      useChunkCache && !params.file.IsIncompletePartialChunk(i),
 
 			// TODO: 25ms is just a guess for a good default. Really, we want to
 			// set the latency target such that slower workers will pick up the
@@ -522,6 +530,8 @@ func (d *download) Start() error {
 		} else {
 			udc.staticFetchLength = params.file.ChunkSize() - udc.staticFetchOffset
 		}
+		// Only chunks which are fetched in full are added to the chunk cache.
+		udc.staticCacheFill = udc.staticCacheLookup && udc.staticFetchOffset == 0 && udc.staticFetchLength == udc.staticChunkSize
 		// Set the writeOffset within the destination for where the data should
 		// be written.
 		udc.staticWriteOffset = writeOffset
@@ -1,6 +1,7 @@
 package renter
 
 import (
+	"bytes"
 	"fmt"
 	"sync"
 	"time"
@@ -11,6 +12,7 @@ import (
 	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+	"go.thebigfile.com/bigd/persist"
 )
 
 // downloadPieceInfo contains all the information required to download and
@@ -53,6 +55,13 @@ type unfinishedDownloadChunk struct {
 	// Spending details.
 	staticSpendingCategory spendingCategory
 
+	// staticCacheLookup indicates that the chunk is served from the chunk
+	// cache if it is cached. staticCacheFill indicates that the chunk is
+	// added to the chunk cache after being recovered, which is only the case
+	// if the whole chunk is fetched from the hosts anyway.
+	staticCacheLookup bool
+	staticCacheFill   bool
+
 	// Fetch + Write instructions - read only or otherwise thread safe.
 	staticDisableDiskFetch bool
 	staticLatencyTarget    time.Duration
@@ -234,11 +243,32 @@ func (udc *unfinishedDownloadChunk) threadedRecoverLogicalData() error {
 		udc.mu.Unlock()
 		return errors.AddContext(err, "unable to write to download destination")
 	}
+	// Add the recovered chunk to the chunk cache. Failing to do so doesn't
+	// affect the download.
+	if udc.staticCacheFill {
+		udc.managedAddToChunkCache()
+	}
 	// finalize the chunk.
 	udc.managedFinalizeRecovery()
 	return nil
 }
 
+// managedAddToChunkCache recovers the whole chunk and adds it to the renter's
+// chunk cache.
+func (udc *unfinishedDownloadChunk) managedAddToChunkCache() {
+	buf := bytes.NewBuffer(make([]byte, 0, udc.staticChunkSize))
+	err := udc.erasureCode.Recover(udc.physicalChunkData, udc.staticChunkSize, buf)
+	if err == nil {
+		err = udc.download.r.staticChunkCache.callAdd(udc.renterFile.UID(), udc.staticChunkIndex, udc.masterKey, buf.Bytes())
+	}
+	if err != nil {
+		udc.download.r.log.WithFields(
+			persist.Field(persist.LogKeySiaPath, udc.renterFile.SiaPath()),
+			persist.Field(persist.LogKeyError, err),
+		).Printf("WARN: failed to add chunk %v to chunk cache", udc.staticChunkIndex)
+	}
+}
+
 // bytesToRecover returns the number of bytes we need to recover from the
 // erasure coded segments. The number of bytes we need to recover doesn't
 // always match the chunkFetchLength. e.g. a user might want to fetch 500 bytes
@@ -12,6 +12,7 @@ package renter
 // subsystem.
 
 import (
+	"bytes"
 	"container/heap"
 	"context"
 	"io"
@@ -95,6 +96,9 @@ func (r *Renter) managedAddChunkToDownloadHeap(udc *unfinishedDownloadChunk) {
 		// disabled, there will be an attempt to fetch the data from disk, and
 		// the work will only be distributed for downloading if the disk fetch
 		// fails.
+		if r.managedTryFetchChunkFromCache(udc) {
+			return
+		}
 		if udc.staticDisableDiskFetch || !r.managedTryFetchChunkFromDisk(udc) {
 			r.managedDistributeDownloadChunkToWorkers(udc)
 		}
@@ -158,6 +162,64 @@ func (r *Renter) managedNextDownloadChunk() *unfinishedDownloadChunk {
 	}
 }
 
+// managedTryFetchChunkFromCache will try to serve the chunk from the chunk
+// cache. Similar to fetching a chunk from disk, the data is written to the
+// destination in a goroutine and the chunk is downloaded from the network if
+// that fails.
+func (r *Renter) managedTryFetchChunkFromCache(chunk *unfinishedDownloadChunk) bool {
+	if !chunk.staticCacheLookup {
+		return false
+	}
+	data, ok := r.staticChunkCache.callGet(chunk.renterFile.UID(), chunk.staticChunkIndex, chunk.masterKey)
+	if !ok || uint64(len(data)) < chunk.staticFetchOffset+chunk.staticFetchLength {
+		return false
+	}
+	if err := r.tg.Add(); err != nil {
+		return false
+	}
+	go func() (success bool) {
+		defer r.tg.Done()
+		// Download the chunk if serving it from the cache failed.
+		defer func() {
+			if success {
+				// Return the memory for the chunk on success and finalize the
+				// recovery.
+				atomic.AddUint64(&chunk.download.atomicDataReceived, chunk.staticFetchLength)
+				chunk.managedFinalizeRecovery()
+				chunk.returnMemory()
+			} else {
+				r.managedDistributeDownloadChunkToWorkers(chunk)
+			}
+		}()
+		// Check if download was already aborted.
+		select {
+		case <-chunk.download.completeChan:
+			return false
+		default:
+		}
+		// Write the requested range of the chunk to the destination.
+		ec := chunk.renterFile.ErasureCode()
+		sr := bytes.NewReader(data[chunk.staticFetchOffset : chunk.staticFetchOffset+chunk.staticFetchLength])
+		pieces, _, err := readDataPieces(sr, ec, chunk.renterFile.PieceSize())
+		if err != nil {
+			r.log.Debugf("managedTryFetchChunkFromCache failed to read data pieces for %v: %v", chunk.renterFile.SiaPath(), err)
+			return false
+		}
+		shards, err := ec.EncodeShards(pieces)
+		if err != nil {
+			r.log.Debugf("managedTryFetchChunkFromCache failed to encode data pieces for %v: %v", chunk.renterFile.SiaPath(), err)
+			return false
+		}
+		err = chunk.destination.WritePieces(ec, shards, 0, chunk.staticWriteOffset, chunk.staticFetchLength)
+		if err != nil {
+			r.log.Debugf("managedTryFetchChunkFromCache failed to write data pieces for %v: %v", chunk.renterFile.SiaPath(), err)
+			return false
+		}
+		return true
+	}()
+	return true
+}
+
 // managedTryFetchChunkFromDisk will try to fetch the chunk from disk if
 // possible.
 //
@@ -313,6 +375,10 @@ LOOP:
 				// The renter shut down before memory could be acquired.
 				return
 			}
+			// Check if we can serve the chunk from the chunk cache.
+			if r.managedTryFetchChunkFromCache(nextChunk) {
+				continue
+			}
 			// Check if we can serve the chunk from disk.
 			if !nextChunk.staticDisableDiskFetch && r.managedTryFetchChunkFromDisk(nextChunk) {
 				continue
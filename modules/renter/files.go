@@ -96,9 +96,13 @@ func (r *Renter) RenameFile(currentName, newName modules.SiaPath) error {
 		return err
 	}
 	defer r.tg.Done()
+	err := errors.Compose(checkUserSiaPath(currentName), checkUserSiaPath(newName))
+	if err != nil {
+		return err
+	}
 
 	// Rename file.
-	err := r.staticFileSystem.RenameFile(currentName, newName)
+	err = r.staticFileSystem.RenameFile(currentName, newName)
 	if err != nil {
 		return err
 	}
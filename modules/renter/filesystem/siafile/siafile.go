@@ -29,6 +29,9 @@ var (
 	// ErrDeleted is returned when an operation failed due to the siafile being
 	// deleted already.
 	ErrDeleted = errors.New("files was deleted")
+	// ErrPieceNotFound is returned when a piece which is supposed to be
+	// removed from a chunk doesn't exist.
+	ErrPieceNotFound = errors.New("piece not found")
 )
 
 type (
@@ -444,6 +447,79 @@ func (sf *SiaFile) AddPiece(pk types.SiaPublicKey, chunkIndex, pieceIndex uint64
 	return sf.createAndApplyTransaction(append(updates, chunkUpdate)...)
 }
 
+// RemovePiece removes the piece with the given merkle root which is stored on
+// the host with the given public key from the file. This allows the repair
+// code to replace pieces that a host is no longer able to serve.
+func (sf *SiaFile) RemovePiece(pk types.SiaPublicKey, chunkIndex, pieceIndex uint64, merkleRoot crypto.Hash) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	// If the file was deleted we can't remove a piece since it would write
+	// the file to disk again.
+	if sf.deleted {
+		return errors.AddContext(ErrDeleted, "can't remove piece from deleted file")
+	}
+	// Incomplete partial chunks don't have any pieces.
+	if sf.isIncompletePartialChunk(chunkIndex) {
+		return errors.New("can't remove piece from incomplete partial chunk")
+	}
+	// Backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	// Update cache.
+	defer sf.uploadProgressAndBytes()
+
+	// Handle piece being removed from the partial chunk.
+	if cci, ok := sf.isIncludedPartialChunk(chunkIndex); ok {
+		return sf.partialsSiaFile.RemovePiece(pk, cci.Index, pieceIndex, merkleRoot)
+	}
+
+	// Check if the chunkIndex is valid.
+	if chunkIndex >= uint64(sf.numChunks) {
+		return fmt.Errorf("chunkIndex %v out of bounds (%v)", chunkIndex, sf.numChunks)
+	}
+	// Get the chunk from disk.
+	chunk, err := sf.chunk(int(chunkIndex))
+	if err != nil {
+		return errors.AddContext(err, "failed to get chunk")
+	}
+	// Check if the pieceIndex is valid.
+	if pieceIndex >= uint64(len(chunk.Pieces)) {
+		return fmt.Errorf("pieceIndex %v out of bounds (%v)", pieceIndex, len(chunk.Pieces))
+	}
+	// Find the piece.
+	pieces := chunk.Pieces[pieceIndex]
+	found := -1
+	for i, p := range pieces {
+		if p.MerkleRoot == merkleRoot && sf.hostKey(p.HostTableOffset).PublicKey.Equals(pk) {
+			found = i
+			break
+		}
+	}
+	if found == -1 {
+		return ErrPieceNotFound
+	}
+	// Remove the piece from the chunk.
+	chunk.Pieces[pieceIndex] = append(pieces[:found:found], pieces[found+1:]...)
+
+	// Update the AccessTime, ChangeTime and ModTime.
+	sf.staticMetadata.AccessTime = time.Now()
+	sf.staticMetadata.ChangeTime = sf.staticMetadata.AccessTime
+	sf.staticMetadata.ModTime = sf.staticMetadata.AccessTime
+
+	// Update the file atomically.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	chunkUpdate := sf.saveChunkUpdate(chunk)
+	return sf.createAndApplyTransaction(append(updates, chunkUpdate)...)
+}
+
 // chunkHealth returns the health and user health of the chunk which is defined
 // as the percent of parity pieces remaining. When calculating the user health
 // we assume that an incomplete partial chunk has full health. For the regular
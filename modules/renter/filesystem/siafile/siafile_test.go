@@ -1161,6 +1161,63 @@ func TestStuckChunks(t *testing.T) {
 	}
 }
 
+// TestRemovePiece tests removing pieces from a siafile.
+func TestRemovePiece(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create siafile and add a piece for two hosts to the first chunk.
+	sf := newTestFile()
+	pk1 := types.SiaPublicKey{Key: fastrand.Bytes(crypto.EntropySize)}
+	pk2 := types.SiaPublicKey{Key: fastrand.Bytes(crypto.EntropySize)}
+	var mr crypto.Hash
+	fastrand.Read(mr[:])
+	if err := sf.AddPiece(pk1, 0, 0, mr); err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.AddPiece(pk2, 0, 0, mr); err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := sf.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numPieces := len(pieces[0])
+
+	// Remove the piece of the first host.
+	if err := sf.RemovePiece(pk1, 0, 0, mr); err != nil {
+		t.Fatal(err)
+	}
+	// Removing it again should fail.
+	if err := sf.RemovePiece(pk1, 0, 0, mr); !errors.Contains(err, ErrPieceNotFound) {
+		t.Fatal("expected ErrPieceNotFound but got", err)
+	}
+
+	// Reload the file and check that only the piece of the second host is
+	// left.
+	sf, err = LoadSiaFile(sf.SiaFilePath(), sf.wal)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err = sf.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces[0]) != numPieces-1 {
+		t.Fatalf("expected %v pieces but got %v", numPieces-1, len(pieces[0]))
+	}
+	for _, p := range pieces[0] {
+		if p.HostPubKey.Equals(pk1) {
+			t.Fatal("piece of first host wasn't removed")
+		}
+	}
+	if p := pieces[0][len(pieces[0])-1]; !p.HostPubKey.Equals(pk2) || p.MerkleRoot != mr {
+		t.Fatal("piece of second host is missing")
+	}
+}
+
 // TestUploadedBytes tests that uploadedBytes() returns the expected values for
 // total and unique uploaded bytes.
 func TestUploadedBytes(t *testing.T) {
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
 )
 
 const (
@@ -41,6 +42,15 @@ const (
 	// case timeout.
 	minScansForSpeedup = 25
 
+	// performanceMeasurementDecay is the weight of a new measurement in the
+	// moving averages of a host's latencies and throughputs.
+	performanceMeasurementDecay = 0.2
+
+	// priceTableLatencyPenaltyThreshold is the price table latency above
+	// which a host's score is penalized. The penalty grows linearly with the
+	// latency.
+	priceTableLatencyPenaltyThreshold = 500 * time.Millisecond
+
 	// recentInteractionWeightLimit caps the number of recent interactions as a
 	// percentage of the historic interactions, to be certain that a large
 	// amount of activity in a short period of time does not overwhelm the
@@ -50,6 +60,10 @@ const (
 	// than half the total weight at this limit.
 	recentInteractionWeightLimit = 0.01
 
+	// rttPenaltyThreshold is the round-trip time above which a host's score
+	// is penalized. The penalty grows linearly with the round-trip time.
+	rttPenaltyThreshold = 250 * time.Millisecond
+
 	// saveFrequency defines how frequently the hostdb will save to disk. Hostdb
 	// will also save immediately prior to shutdown.
 	saveFrequency = 2 * time.Minute
@@ -96,6 +110,22 @@ const (
 )
 
 var (
+	// MinThroughputSampleSize is the minimum number of bytes a transfer needs
+	// to have to be used as a throughput measurement. Smaller transfers are
+	// dominated by latency.
+	MinThroughputSampleSize = modules.SectorSize / 4
+
+	// targetThroughput is the throughput in bytes per second below which a
+	// host's score is penalized. The penalty grows linearly as the throughput
+	// drops. Testing uses tiny sectors which don't reach realistic
+	// throughputs.
+	targetThroughput = build.Select(build.Var{
+		Standard: float64(4 << 20), // 4 MiB/s
+		Testnet:  float64(4 << 20),
+		Dev:      float64(4 << 20),
+		Testing:  float64(1 << 10),
+	}).(float64)
+
 	// hostCheckupQuantity specifies the number of hosts that get scanned every
 	// time there is a regular scanning operation.
 	hostCheckupQuantity = build.Select(build.Var{
@@ -200,6 +200,12 @@ type HostDB struct {
 	// filteredDomains tracks blocked domains for the hostdb.
 	filteredDomains *filteredDomains
 
+	// selectionPolicy is the user-configured policy which is applied on top
+	// of the default host scoring. locations is the location database of the
+	// policy, it is nil if the policy doesn't specify one.
+	selectionPolicy modules.HostSelectionPolicy
+	locations       *locationDB
+
 	blockHeight types.BlockHeight
 	lastChange  modules.ConsensusChangeID
 }
@@ -347,6 +353,7 @@ func hostdbBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 		filteredHosts:   make(map[string]types.SiaPublicKey),
 		knownContracts:  make(map[string]contractInfo),
 		scanMap:         make(map[string]struct{}),
+		selectionPolicy: modules.DefaultHostSelectionPolicy(),
 		staticAlerter:   modules.NewAlerter("hostdb"),
 	}
 
@@ -807,6 +814,54 @@ func (hdb *HostDB) SetAllowance(allowance modules.Allowance) error {
 	return hdb.managedSetWeightFunction(wf)
 }
 
+// HostSelectionPolicy returns the hostdb's host selection policy.
+func (hdb *HostDB) HostSelectionPolicy() (modules.HostSelectionPolicy, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return modules.HostSelectionPolicy{}, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	return hdb.selectionPolicy, nil
+}
+
+// SetHostSelectionPolicy sets the hostdb's host selection policy and updates
+// the weight function to rescore all hosts.
+func (hdb *HostDB) SetHostSelectionPolicy(policy modules.HostSelectionPolicy) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	if err := policy.Validate(); err != nil {
+		return errors.AddContext(err, "invalid host selection policy")
+	}
+
+	// Load the location database before acquiring the lock.
+	var locations *locationDB
+	if policy.LocationDatabase != "" {
+		var err error
+		locations, err = loadLocationDB(policy.LocationDatabase)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update and persist the policy.
+	hdb.mu.Lock()
+	hdb.selectionPolicy = policy
+	hdb.locations = locations
+	allowance := hdb.allowance
+	err := hdb.saveSync()
+	hdb.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "unable to persist host selection policy")
+	}
+
+	// Update the weight function.
+	wf := hdb.managedCalculateHostWeightFn(allowance)
+	return hdb.managedSetWeightFunction(wf)
+}
+
 // SetIPViolationCheck enables or disables the IP violation check. If disabled,
 // CheckForIPViolations won't return bad hosts and RandomHosts will return the
 // address blacklist.
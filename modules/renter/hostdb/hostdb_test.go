@@ -48,9 +48,10 @@ func bareHostDB() *HostDB {
 		panic(err)
 	}
 	hdb := &HostDB{
-		allowance:      modules.DefaultAllowance,
-		staticLog:      logger,
-		knownContracts: make(map[string]contractInfo),
+		allowance:       modules.DefaultAllowance,
+		staticLog:       logger,
+		knownContracts:  make(map[string]contractInfo),
+		selectionPolicy: modules.DefaultHostSelectionPolicy(),
 	}
 	hdb.weightFunc = hdb.managedCalculateHostWeightFn(hdb.allowance)
 	hdb.staticHostTree = hosttree.New(hdb.weightFunc, &modules.ProductionResolver{})
@@ -425,6 +426,66 @@ func TestRandomHosts(t *testing.T) {
 	}
 }
 
+// TestRandomHostsSelectionPolicy checks that hosts which violate the host
+// selection policy are never returned by RandomHosts, independent of their
+// score.
+func TestRandomHostsSelectionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdbt, err := newHDBTesterDeps(t.Name(), &disableScanLoopDeps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hdb := hdbt.hdb
+
+	// Add a few hosts and one which charges more than the others.
+	nEntries := 10
+	var pks []types.SiaPublicKey
+	for i := 0; i < nEntries; i++ {
+		entry := makeHostDBEntry()
+		if err := hdb.filteredTree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+		pks = append(pks, entry.PublicKey)
+	}
+	expensive := makeHostDBEntry()
+	expensive.StoragePrice = expensive.StoragePrice.Mul64(2)
+	if err := hdb.filteredTree.Insert(expensive); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cap the storage price without updating the weight function. The
+	// expensive host keeps its regular score but still must not be selected.
+	hdb.mu.Lock()
+	hdb.selectionPolicy.MaxStoragePrice = DefaultHostDBEntry.StoragePrice
+	hdb.mu.Unlock()
+	for i := 0; i < 25; i++ {
+		hosts, err := hdb.RandomHosts(nEntries+1, nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(hosts) != nEntries {
+			t.Fatalf("expected %v hosts but got %v", nEntries, len(hosts))
+		}
+		for _, host := range hosts {
+			if host.PublicKey.Equals(expensive.PublicKey) {
+				t.Fatal("host exceeding the price cap was selected")
+			}
+		}
+	}
+
+	// The host should be reported as violating the policy.
+	bad, err := hdb.CheckForPolicyViolations(append(pks, expensive.PublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 1 || !bad[0].Equals(expensive.PublicKey) {
+		t.Fatal("unexpected violations", bad)
+	}
+}
+
 // TestRemoveNonexistingHostFromHostTree checks that the host tree interface
 // correctly responds to having a nonexisting host removed from the host tree.
 func TestRemoveNonexistingHostFromHostTree(t *testing.T) {
@@ -606,6 +667,61 @@ func TestUpdateHistoricInteractions(t *testing.T) {
 	}
 }
 
+// TestRecordThroughput checks that throughput measurements are recorded as
+// moving averages and that small transfers are ignored.
+func TestRecordThroughput(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	hdbt, err := newHDBTesterDeps(t.Name(), &disableScanLoopDeps{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	host := makeHostDBEntry()
+	if err := hdbt.hdb.staticHostTree.Insert(host); err != nil {
+		t.Fatal(err)
+	}
+
+	// Record 4 MiB uploaded within a second and 1 MiB downloaded within a
+	// second. Small transfers are ignored.
+	err = errors.Compose(
+		hdbt.hdb.RecordUploadThroughput(host.PublicKey, 4<<20, time.Second),
+		hdbt.hdb.RecordDownloadThroughput(host.PublicKey, 1<<20, time.Second),
+		hdbt.hdb.RecordDownloadThroughput(host.PublicKey, 1, time.Hour),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _, err = hdbt.hdb.Host(host.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if host.UploadThroughput != 4<<20 || host.DownloadThroughput != 1<<20 {
+		t.Fatal("unexpected throughputs", host.UploadThroughput, host.DownloadThroughput)
+	}
+
+	// Another measurement should be averaged with the first one.
+	if err := hdbt.hdb.RecordDownloadThroughput(host.PublicKey, 2<<20, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	host, _, err = hdbt.hdb.Host(host.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := performanceMeasurementDecay*(2<<20) + (1-performanceMeasurementDecay)*(1<<20)
+	if math.Abs(host.DownloadThroughput-expected) > 1e-6 {
+		t.Fatalf("expected throughput %v but got %v", expected, host.DownloadThroughput)
+	}
+
+	// Recording the throughput of an unknown host fails.
+	unknown := makeHostDBEntry()
+	if err := hdbt.hdb.RecordUploadThroughput(unknown.PublicKey, 4<<20, time.Second); !errors.Contains(err, errHostNotFoundInTree) {
+		t.Fatal("expected errHostNotFoundInTree but got", err)
+	}
+}
+
 // testCheckForIPViolationsResolver is a resolver for the TestTwoAddresses test.
 type testCheckForIPViolationsResolver struct{}
 
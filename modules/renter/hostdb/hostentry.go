@@ -2,6 +2,7 @@ package hostdb
 
 import (
 	"math"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"go.thebigfile.com/bigd/modules"
@@ -129,3 +130,62 @@ func (hdb *HostDB) IncrementFailedInteractions(key types.SiaPublicKey) error {
 	hdb.staticHostTree.Modify(host)
 	return nil
 }
+
+// updatePerformanceAverage adds a measurement to the moving average of a
+// performance metric. An average of zero means that there are no previous
+// measurements.
+func updatePerformanceAverage(avg, measurement float64) float64 {
+	if avg == 0 {
+		return measurement
+	}
+	return performanceMeasurementDecay*measurement + (1-performanceMeasurementDecay)*avg
+}
+
+// updateHostScanPerformance adds the latencies measured during a successful
+// scan to the host's performance metrics.
+func updateHostScanPerformance(host *modules.HostDBEntry, rtt, priceTableLatency time.Duration) {
+	host.RTT = time.Duration(updatePerformanceAverage(float64(host.RTT), float64(rtt)))
+	host.PriceTableLatency = time.Duration(updatePerformanceAverage(float64(host.PriceTableLatency), float64(priceTableLatency)))
+}
+
+// RecordDownloadThroughput records the time it took to download size bytes
+// from a host. Transfers smaller than MinThroughputSampleSize are ignored.
+func (hdb *HostDB) RecordDownloadThroughput(key types.SiaPublicKey, size uint64, elapsed time.Duration) error {
+	return hdb.managedRecordThroughput(key, size, elapsed, false)
+}
+
+// RecordUploadThroughput records the time it took to upload size bytes to a
+// host. Transfers smaller than MinThroughputSampleSize are ignored.
+func (hdb *HostDB) RecordUploadThroughput(key types.SiaPublicKey, size uint64, elapsed time.Duration) error {
+	return hdb.managedRecordThroughput(key, size, elapsed, true)
+}
+
+// managedRecordThroughput adds a throughput measurement to the upload or
+// download throughput of a host.
+func (hdb *HostDB) managedRecordThroughput(key types.SiaPublicKey, size uint64, elapsed time.Duration, upload bool) error {
+	if size < MinThroughputSampleSize || elapsed <= 0 {
+		return nil
+	}
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.staticHostTree.Select(key)
+	if !haveHost {
+		return errors.AddContext(errHostNotFoundInTree, "unable to record throughput:")
+	}
+
+	// Update the throughput.
+	throughput := float64(size) / elapsed.Seconds()
+	if upload {
+		host.UploadThroughput = updatePerformanceAverage(host.UploadThroughput, throughput)
+	} else {
+		host.DownloadThroughput = updatePerformanceAverage(host.DownloadThroughput, throughput)
+	}
+	return hdb.staticHostTree.Modify(host)
+}
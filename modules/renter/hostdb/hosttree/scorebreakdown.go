@@ -1,6 +1,7 @@
 package hosttree
 
 import (
+	"math"
 	"math/big"
 
 	"go.thebigfile.com/bigd/modules"
@@ -24,10 +25,12 @@ type HostAdjustments struct {
 	CollateralAdjustment       float64
 	DurationAdjustment         float64
 	InteractionAdjustment      float64
+	PerformanceAdjustment      float64
 	PriceAdjustment            float64
 	StorageRemainingAdjustment float64
 	UptimeAdjustment           float64
 	VersionAdjustment          float64
+	PolicyAdjustment           float64
 }
 
 var (
@@ -76,10 +79,12 @@ func (h HostAdjustments) HostScoreBreakdown(totalScore types.Currency, ignoreAge
 		CollateralAdjustment:       h.CollateralAdjustment,
 		DurationAdjustment:         h.DurationAdjustment,
 		InteractionAdjustment:      h.InteractionAdjustment,
+		PerformanceAdjustment:      h.PerformanceAdjustment,
 		PriceAdjustment:            h.PriceAdjustment,
 		StorageRemainingAdjustment: h.StorageRemainingAdjustment,
 		UptimeAdjustment:           h.UptimeAdjustment,
 		VersionAdjustment:          h.VersionAdjustment,
+		PolicyAdjustment:           h.PolicyAdjustment,
 	}
 }
 
@@ -94,10 +99,20 @@ func (h HostAdjustments) Score() types.Currency {
 		h.CollateralAdjustment *
 		h.DurationAdjustment *
 		h.InteractionAdjustment *
+		h.PerformanceAdjustment *
 		h.PriceAdjustment *
 		h.StorageRemainingAdjustment *
 		h.UptimeAdjustment *
-		h.VersionAdjustment
+		h.VersionAdjustment *
+		h.PolicyAdjustment
+
+	// Extreme weights of the host selection policy can push the combined
+	// adjustments out of the range of a float64.
+	if math.IsNaN(fullPenalty) {
+		fullPenalty = 0
+	} else if math.IsInf(fullPenalty, 1) {
+		fullPenalty = math.MaxFloat64
+	}
 
 	// Return a types.Currency.
 	weight := baseWeight.MulFloat(fullPenalty)
@@ -231,6 +231,27 @@ func (hdb *HostDB) interactionAdjustments(entry modules.HostDBEntry) float64 {
 	return math.Pow(ratio, interactionExponentiation)
 }
 
+// performanceAdjustments will adjust the weight of the entry according to its
+// measured latencies and throughputs. Hosts are penalized linearly once their
+// latencies exceed the penalty thresholds or their throughputs drop below the
+// target throughput. Metrics without measurements don't affect the weight.
+func performanceAdjustments(entry modules.HostDBEntry) float64 {
+	adjustment := 1.0
+	if entry.RTT > rttPenaltyThreshold {
+		adjustment *= float64(rttPenaltyThreshold) / float64(entry.RTT)
+	}
+	if entry.PriceTableLatency > priceTableLatencyPenaltyThreshold {
+		adjustment *= float64(priceTableLatencyPenaltyThreshold) / float64(entry.PriceTableLatency)
+	}
+	if entry.UploadThroughput > 0 && entry.UploadThroughput < targetThroughput {
+		adjustment *= entry.UploadThroughput / targetThroughput
+	}
+	if entry.DownloadThroughput > 0 && entry.DownloadThroughput < targetThroughput {
+		adjustment *= entry.DownloadThroughput / targetThroughput
+	}
+	return adjustment
+}
+
 // priceAdjustments will adjust the weight of the entry according to the prices
 // that it has set.
 //
@@ -453,6 +474,44 @@ func (hdb *HostDB) lifetimeAdjustments(entry modules.HostDBEntry) float64 {
 	return base
 }
 
+// hostUptime computes the total measured uptime and downtime of a host. The
+// returned bool is false if the scan history of the host wasn't sorted, in
+// which case the unsorted scans are ignored.
+func hostUptime(entry modules.HostDBEntry) (uptime, downtime time.Duration, sorted bool) {
+	downtime = entry.HistoricDowntime
+	uptime = entry.HistoricUptime
+	sorted = true
+	if len(entry.ScanHistory) == 0 {
+		return
+	}
+	recentTime := entry.ScanHistory[0].Timestamp
+	recentSuccess := entry.ScanHistory[0].Success
+	for _, scan := range entry.ScanHistory[1:] {
+		if recentTime.After(scan.Timestamp) {
+			// Ignore the unsorted scan entry.
+			sorted = false
+			continue
+		}
+		if recentSuccess {
+			uptime += scan.Timestamp.Sub(recentTime)
+		} else {
+			downtime += scan.Timestamp.Sub(recentTime)
+		}
+		recentTime = scan.Timestamp
+		recentSuccess = scan.Success
+	}
+
+	// One more check to incorporate the uptime or downtime of the most recent
+	// scan, we assume that if we scanned them right now, their uptime /
+	// downtime status would be equal to what it currently is.
+	if recentSuccess {
+		uptime += time.Now().Sub(recentTime)
+	} else {
+		downtime += time.Now().Sub(recentTime)
+	}
+	return
+}
+
 // uptimeAdjustments penalizes the host for having poor uptime, and for being
 // offline.
 //
@@ -489,36 +548,13 @@ func (hdb *HostDB) uptimeAdjustments(entry modules.HostDBEntry) float64 {
 
 	// Compute the total measured uptime and total measured downtime for this
 	// host.
-	downtime := entry.HistoricDowntime
-	uptime := entry.HistoricUptime
-	recentTime := entry.ScanHistory[0].Timestamp
-	recentSuccess := entry.ScanHistory[0].Success
-	for _, scan := range entry.ScanHistory[1:] {
-		if recentTime.After(scan.Timestamp) {
-			if build.DEBUG {
-				hdb.staticLog.Critical("Host entry scan history not sorted.")
-			} else {
-				hdb.staticLog.Print("WARN: Host entry scan history not sorted.")
-			}
-			// Ignore the unsorted scan entry.
-			continue
-		}
-		if recentSuccess {
-			uptime += scan.Timestamp.Sub(recentTime)
+	uptime, downtime, sorted := hostUptime(entry)
+	if !sorted {
+		if build.DEBUG {
+			hdb.staticLog.Critical("Host entry scan history not sorted.")
 		} else {
-			downtime += scan.Timestamp.Sub(recentTime)
+			hdb.staticLog.Print("WARN: Host entry scan history not sorted.")
 		}
-		recentTime = scan.Timestamp
-		recentSuccess = scan.Success
-	}
-
-	// One more check to incorporate the uptime or downtime of the most recent
-	// scan, we assume that if we scanned them right now, their uptime /
-	// downtime status would be equal to what it currently is.
-	if recentSuccess {
-		uptime += time.Now().Sub(recentTime)
-	} else {
-		downtime += time.Now().Sub(recentTime)
 	}
 
 	// Sanity check against 0 total time.
@@ -557,30 +593,175 @@ func (hdb *HostDB) uptimeAdjustments(entry modules.HostDBEntry) float64 {
 	return math.Pow(uptimeRatio, exp)
 }
 
+// policyExcludesHosts returns whether the host selection policy contains any
+// price caps, minimum requirements or exclusions.
+func policyExcludesHosts(policy modules.HostSelectionPolicy) bool {
+	return !policy.MaxContractPrice.IsZero() ||
+		!policy.MaxDownloadBandwidthPrice.IsZero() ||
+		!policy.MaxStoragePrice.IsZero() ||
+		!policy.MaxUploadBandwidthPrice.IsZero() ||
+		policy.MinAge > 0 ||
+		policy.MinUptime > 0 ||
+		len(policy.ExcludedASNs) > 0 ||
+		len(policy.ExcludedRegions) > 0
+}
+
+// violatesPolicy returns whether the host exceeds a price cap, doesn't meet
+// the minimum requirements or is located in an excluded region or ASN of the
+// host selection policy. Such hosts are never selected, independent of their
+// score.
+//
+// NOTE: the hostdb lock must be held.
+func (hdb *HostDB) violatesPolicy(entry modules.HostDBEntry, policy modules.HostSelectionPolicy, locations *locationDB) bool {
+	// Check the price caps.
+	exceedsCap := func(price, maxPrice types.Currency) bool {
+		return !maxPrice.IsZero() && price.Cmp(maxPrice) > 0
+	}
+	if exceedsCap(entry.ContractPrice, policy.MaxContractPrice) ||
+		exceedsCap(entry.DownloadBandwidthPrice, policy.MaxDownloadBandwidthPrice) ||
+		exceedsCap(entry.StoragePrice, policy.MaxStoragePrice) ||
+		exceedsCap(entry.UploadBandwidthPrice, policy.MaxUploadBandwidthPrice) {
+		return true
+	}
+
+	// Check the minimum age and uptime.
+	if policy.MinAge > 0 && (hdb.blockHeight < entry.FirstSeen || hdb.blockHeight-entry.FirstSeen < policy.MinAge) {
+		return true
+	}
+	if policy.MinUptime > 0 {
+		uptime, downtime, _ := hostUptime(entry)
+		if uptime+downtime == 0 || float64(uptime)/float64(uptime+downtime) < policy.MinUptime {
+			return true
+		}
+	}
+
+	// Check the location of the host.
+	if locations == nil {
+		return false
+	}
+	for _, loc := range locations.hostLocations(entry) {
+		if containsRegion(policy.ExcludedRegions, loc.Region) || containsASN(policy.ExcludedASNs, loc.ASN) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyAdjustments adjusts the weight of the host according to the price
+// caps, minimum requirements and location preferences of the host selection
+// policy.
+func (hdb *HostDB) policyAdjustments(entry modules.HostDBEntry, policy modules.HostSelectionPolicy, locations *locationDB) float64 {
+	if hdb.violatesPolicy(entry, policy, locations) {
+		return math.SmallestNonzeroFloat64
+	}
+
+	// Check if the host is located in a preferred region or ASN.
+	if locations == nil {
+		return 1
+	}
+	adjustment := float64(1)
+	for _, loc := range locations.hostLocations(entry) {
+		if containsRegion(policy.PreferredRegions, loc.Region) || containsASN(policy.PreferredASNs, loc.ASN) {
+			adjustment = policy.PreferenceMultiplier
+		}
+	}
+	return adjustment
+}
+
+// managedPolicyViolations returns the public keys of the provided hosts which
+// violate the host selection policy.
+func (hdb *HostDB) managedPolicyViolations(entries []modules.HostDBEntry) []types.SiaPublicKey {
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	policy := hdb.selectionPolicy
+	locations := hdb.locations
+	if !policyExcludesHosts(policy) {
+		return nil
+	}
+	var badHosts []types.SiaPublicKey
+	for _, entry := range entries {
+		if hdb.violatesPolicy(entry, policy, locations) {
+			badHosts = append(badHosts, entry.PublicKey)
+		}
+	}
+	return badHosts
+}
+
+// CheckForPolicyViolations accepts a number of host public keys and returns
+// the ones that exceed a price cap, don't meet the minimum requirements or are
+// located in an excluded region or ASN of the host selection policy. Hosts
+// which are unknown to the hostdb are ignored.
+func (hdb *HostDB) CheckForPolicyViolations(hosts []types.SiaPublicKey) ([]types.SiaPublicKey, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer hdb.tg.Done()
+	var entries []modules.HostDBEntry
+	for _, host := range hosts {
+		entry, exists := hdb.staticHostTree.Select(host)
+		if !exists {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return hdb.managedPolicyViolations(entries), nil
+}
+
+// containsASN returns whether the list of ASNs contains the ASN.
+func containsASN(asns []uint32, asn uint32) bool {
+	for _, a := range asns {
+		if a == asn {
+			return true
+		}
+	}
+	return false
+}
+
+// containsRegion returns whether the list of regions contains the region.
+// Regions are compared case-insensitively.
+func containsRegion(regions []string, region string) bool {
+	for _, r := range regions {
+		if strings.EqualFold(r, region) {
+			return true
+		}
+	}
+	return false
+}
+
 // managedCalculateHostWeightFn creates a hosttree.WeightFunc given an
 // Allowance.
 //
 // NOTE: the hosttree.WeightFunc that is returned accesses fields of the hostdb.
 // The hostdb lock must be held while utilizing the WeightFunc
 func (hdb *HostDB) managedCalculateHostWeightFn(allowance modules.Allowance) hosttree.WeightFunc {
-	// Get the txnFees.
+	// Get the txnFees and the selection policy.
 	hdb.mu.RLock()
 	txnFees := hdb.txnFees
+	policy := hdb.selectionPolicy
+	locations := hdb.locations
 	hdb.mu.RUnlock()
-	// Create the weight function.
+	return hdb.calculateHostWeightFn(allowance, txnFees, policy, locations)
+}
+
+// calculateHostWeightFn creates a hosttree.WeightFunc which applies the
+// weights of the host selection policy to the individual adjustments.
+func (hdb *HostDB) calculateHostWeightFn(allowance modules.Allowance, txnFees types.Currency, policy modules.HostSelectionPolicy, locations *locationDB) hosttree.WeightFunc {
+	w := policy.Weights
 	return func(entry modules.HostDBEntry) hosttree.ScoreBreakdown {
 		return hosttree.HostAdjustments{
-			AcceptContractAdjustment:   hdb.acceptContractAdjustments(entry),
-			AgeAdjustment:              hdb.lifetimeAdjustments(entry),
-			BasePriceAdjustment:        hdb.basePriceAdjustments(entry),
+			AcceptContractAdjustment:   math.Pow(hdb.acceptContractAdjustments(entry), w.AcceptContract),
+			AgeAdjustment:              math.Pow(hdb.lifetimeAdjustments(entry), w.Age),
+			BasePriceAdjustment:        math.Pow(hdb.basePriceAdjustments(entry), w.BasePrice),
 			BurnAdjustment:             1,
-			CollateralAdjustment:       hdb.collateralAdjustments(entry, allowance),
-			DurationAdjustment:         hdb.durationAdjustments(entry, allowance),
-			InteractionAdjustment:      hdb.interactionAdjustments(entry),
-			PriceAdjustment:            hdb.priceAdjustments(entry, allowance, txnFees),
-			StorageRemainingAdjustment: hdb.storageRemainingAdjustments(entry, allowance),
-			UptimeAdjustment:           hdb.uptimeAdjustments(entry),
-			VersionAdjustment:          versionAdjustments(entry),
+			CollateralAdjustment:       math.Pow(hdb.collateralAdjustments(entry, allowance), w.Collateral),
+			DurationAdjustment:         math.Pow(hdb.durationAdjustments(entry, allowance), w.Duration),
+			InteractionAdjustment:      math.Pow(hdb.interactionAdjustments(entry), w.Interaction),
+			PerformanceAdjustment:      math.Pow(performanceAdjustments(entry), w.Performance),
+			PriceAdjustment:            math.Pow(hdb.priceAdjustments(entry, allowance, txnFees), w.Price),
+			StorageRemainingAdjustment: math.Pow(hdb.storageRemainingAdjustments(entry, allowance), w.StorageRemaining),
+			UptimeAdjustment:           math.Pow(hdb.uptimeAdjustments(entry), w.Uptime),
+			VersionAdjustment:          math.Pow(versionAdjustments(entry), w.Version),
+			PolicyAdjustment:           hdb.policyAdjustments(entry, policy, locations),
 		}
 	}
 }
@@ -746,3 +746,124 @@ func TestHostWeightAcceptContract(t *testing.T) {
 		t.Error("Entry2 should have smallest weight")
 	}
 }
+
+// TestHostWeightSelectionPolicy checks that the host selection policy is
+// applied to the host score.
+func TestHostWeightSelectionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdb := bareHostDB()
+	hdb.blockHeight = 1000
+	entry := DefaultHostDBEntry
+	entry.FirstSeen = 500
+	entry.IPNets = []string{"203.0.113.0/24"}
+	baseScore := hdb.weightFunc(entry).Score()
+
+	// The default policy shouldn't change the score.
+	policy := modules.DefaultHostSelectionPolicy()
+	locations := &locationDB{
+		networks: map[int]map[string]modules.HostLocation{
+			24: {"203.0.113.0/24": {Region: "DE", ASN: 64500}},
+		},
+		prefixLens: []int{24},
+	}
+	score := func() types.Currency {
+		return hdb.calculateHostWeightFn(hdb.allowance, hdb.txnFees, policy, locations)(entry).Score()
+	}
+	if !score().Equals(baseScore) {
+		t.Fatal("default policy changed the score", score(), baseScore)
+	}
+
+	// Exceeding a price cap should result in the minimum score.
+	policy.MaxStoragePrice = entry.StoragePrice.Sub64(1)
+	if s := score(); s.Cmp64(1) > 0 {
+		t.Fatal("expected minimal score for host exceeding price cap but got", s)
+	}
+	policy.MaxStoragePrice = entry.StoragePrice
+
+	// So should being younger than the minimum age.
+	policy.MinAge = 501
+	if s := score(); s.Cmp64(1) > 0 {
+		t.Fatal("expected minimal score for young host but got", s)
+	}
+	policy.MinAge = 500
+
+	// So should an excluded region or ASN.
+	policy.ExcludedRegions = []string{"DE"}
+	if s := score(); s.Cmp64(1) > 0 {
+		t.Fatal("expected minimal score for excluded region but got", s)
+	}
+	policy.ExcludedRegions = nil
+	policy.ExcludedASNs = []uint32{64500}
+	if s := score(); s.Cmp64(1) > 0 {
+		t.Fatal("expected minimal score for excluded ASN but got", s)
+	}
+	policy.ExcludedASNs = nil
+	if !score().Equals(baseScore) {
+		t.Fatal("policy without violations changed the score", score(), baseScore)
+	}
+
+	// A preferred region should increase the score.
+	policy.PreferredRegions = []string{"DE"}
+	if s := score(); s.Cmp(baseScore) <= 0 {
+		t.Fatal("expected preferred host to have higher score", s, baseScore)
+	}
+	policy.PreferredRegions = nil
+
+	// Increasing the weight of an adjustment below 1 should decrease the
+	// score, ignoring it should increase the score.
+	entry.RemainingStorage = 1e9
+	baseScore = score()
+	policy.Weights.StorageRemaining = 2
+	if s := score(); s.Cmp(baseScore) >= 0 {
+		t.Fatal("expected higher weight to decrease the score", s, baseScore)
+	}
+	policy.Weights.StorageRemaining = 0
+	if s := score(); s.Cmp(baseScore) <= 0 {
+		t.Fatal("expected ignored adjustment to increase the score", s, baseScore)
+	}
+}
+
+// TestHostWeightPerformance checks that slow hosts receive a lower score than
+// fast ones and that missing measurements don't affect the score.
+func TestHostWeightPerformance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdb := bareHostDB()
+
+	// Fast hosts and hosts without measurements aren't penalized.
+	entry := DefaultHostDBEntry
+	if adj := performanceAdjustments(entry); adj != 1 {
+		t.Fatal("expected no adjustment without measurements but got", adj)
+	}
+	entry.RTT = rttPenaltyThreshold
+	entry.PriceTableLatency = priceTableLatencyPenaltyThreshold
+	entry.UploadThroughput = targetThroughput
+	entry.DownloadThroughput = 2 * targetThroughput
+	if adj := performanceAdjustments(entry); adj != 1 {
+		t.Fatal("expected no adjustment for fast host but got", adj)
+	}
+	fastScore := hdb.weightFunc(entry).Score()
+
+	// Every slow metric should lower the score.
+	slowEntries := []func(e *modules.HostDBEntry){
+		func(e *modules.HostDBEntry) { e.RTT = 2 * rttPenaltyThreshold },
+		func(e *modules.HostDBEntry) { e.PriceTableLatency = 2 * priceTableLatencyPenaltyThreshold },
+		func(e *modules.HostDBEntry) { e.UploadThroughput = targetThroughput / 2 },
+		func(e *modules.HostDBEntry) { e.DownloadThroughput = targetThroughput / 2 },
+	}
+	for i, slow := range slowEntries {
+		slowEntry := entry
+		slow(&slowEntry)
+		if adj := performanceAdjustments(slowEntry); adj != 0.5 {
+			t.Errorf("%v: expected adjustment of 0.5 but got %v", i, adj)
+		}
+		if slowScore := hdb.weightFunc(slowEntry).Score(); slowScore.Cmp(fastScore) >= 0 {
+			t.Errorf("%v: expected slow host to have lower score: %v >= %v", i, slowScore, fastScore)
+		}
+	}
+}
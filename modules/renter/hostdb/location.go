@@ -0,0 +1,198 @@
+package hostdb
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// The location database is an offline CSV file which maps IP networks to the
+// region and the autonomous system they belong to. Every line contains a
+// network in CIDR notation, a region code and an ASN, e.g.
+//
+//   203.0.113.0/24,DE,64500
+//
+// Empty lines and lines starting with '#' are ignored. When a host's address is
+// covered by multiple networks, the most specific network wins.
+
+// locationDB maps IP networks to their locations. The networks are indexed by
+// their prefix length to look up addresses with one map access per prefix
+// length.
+type locationDB struct {
+	networks   map[int]map[string]modules.HostLocation
+	prefixLens []int
+}
+
+// loadLocationDB loads the location database from the file at path.
+func loadLocationDB(path string) (_ *locationDB, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to open location database")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+
+	db := &locationDB{
+		networks: make(map[int]map[string]modules.HostLocation),
+	}
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) != 3 {
+			return nil, errors.New("invalid location database entry in line " + strconv.Itoa(lineNum))
+		}
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(fields[0]))
+		if err != nil {
+			return nil, errors.AddContext(err, "invalid network in line "+strconv.Itoa(lineNum))
+		}
+		asn, err := strconv.ParseUint(strings.TrimSpace(fields[2]), 10, 32)
+		if err != nil {
+			return nil, errors.AddContext(err, "invalid ASN in line "+strconv.Itoa(lineNum))
+		}
+		prefixLen, _ := ipNet.Mask.Size()
+		if _, exists := db.networks[prefixLen]; !exists {
+			db.networks[prefixLen] = make(map[string]modules.HostLocation)
+			db.prefixLens = append(db.prefixLens, prefixLen)
+		}
+		db.networks[prefixLen][ipNet.String()] = modules.HostLocation{
+			Region: strings.ToUpper(strings.TrimSpace(fields[1])),
+			ASN:    uint32(asn),
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.AddContext(err, "unable to read location database")
+	}
+	// Check the most specific networks first.
+	sort.Sort(sort.Reverse(sort.IntSlice(db.prefixLens)))
+	return db, nil
+}
+
+// lookup returns the location of an IP address.
+func (db *locationDB) lookup(ip net.IP) (modules.HostLocation, bool) {
+	bits := 8 * net.IPv6len
+	if ip4 := ip.To4(); ip4 != nil {
+		ip, bits = ip4, 8*net.IPv4len
+	}
+	for _, prefixLen := range db.prefixLens {
+		if prefixLen > bits {
+			continue
+		}
+		ipNet := net.IPNet{
+			IP:   ip.Mask(net.CIDRMask(prefixLen, bits)),
+			Mask: net.CIDRMask(prefixLen, bits),
+		}
+		if loc, exists := db.networks[prefixLen][ipNet.String()]; exists {
+			return loc, true
+		}
+	}
+	return modules.HostLocation{}, false
+}
+
+// hostLocations returns the locations of the subnets a host uses.
+func (db *locationDB) hostLocations(entry modules.HostDBEntry) []modules.HostLocation {
+	var locations []modules.HostLocation
+	for _, ipNetStr := range entry.IPNets {
+		ip, _, err := net.ParseCIDR(ipNetStr)
+		if err != nil {
+			continue
+		}
+		if loc, exists := db.lookup(ip); exists {
+			locations = append(locations, loc)
+		}
+	}
+	return locations
+}
+
+// HostLocation returns the location of a host according to the location
+// database of the host selection policy. A host which uses multiple subnets is
+// located by the first subnet found in the database.
+func (hdb *HostDB) HostLocation(pk types.SiaPublicKey) (modules.HostLocation, bool, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return modules.HostLocation{}, false, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	locations := hdb.locations
+	hdb.mu.RUnlock()
+	if locations == nil {
+		return modules.HostLocation{}, false, nil
+	}
+	entry, exists := hdb.staticHostTree.Select(pk)
+	if !exists {
+		return modules.HostLocation{}, false, errHostNotFoundInTree
+	}
+	hostLocations := locations.hostLocations(entry)
+	if len(hostLocations) == 0 {
+		return modules.HostLocation{}, false, nil
+	}
+	return hostLocations[0], true, nil
+}
+
+// CheckForDiversityViolations accepts a number of host public keys and returns
+// the ones that exceed the maximum number of hosts per region or ASN of the
+// host selection policy. Hosts which are passed in earlier take precedence
+// over later ones. Hosts without a known location don't count towards any
+// limit.
+func (hdb *HostDB) CheckForDiversityViolations(hosts []types.SiaPublicKey) ([]types.SiaPublicKey, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	policy := hdb.selectionPolicy
+	locations := hdb.locations
+	hdb.mu.RUnlock()
+	if locations == nil || (policy.MaxHostsPerASN == 0 && policy.MaxHostsPerRegion == 0) {
+		return nil, nil
+	}
+
+	var badHosts []types.SiaPublicKey
+	asnHosts := make(map[uint32]uint64)
+	regionHosts := make(map[string]uint64)
+	for _, host := range hosts {
+		entry, exists := hdb.staticHostTree.Select(host)
+		if !exists {
+			continue
+		}
+		// Collect the distinct ASNs and regions of the host.
+		asns := make(map[uint32]struct{})
+		regions := make(map[string]struct{})
+		for _, loc := range locations.hostLocations(entry) {
+			asns[loc.ASN] = struct{}{}
+			regions[loc.Region] = struct{}{}
+		}
+		// Check if the host exceeds any of the limits.
+		violation := false
+		for asn := range asns {
+			violation = violation || (policy.MaxHostsPerASN > 0 && asnHosts[asn] >= policy.MaxHostsPerASN)
+		}
+		for region := range regions {
+			violation = violation || (policy.MaxHostsPerRegion > 0 && regionHosts[region] >= policy.MaxHostsPerRegion)
+		}
+		if violation {
+			badHosts = append(badHosts, host)
+			continue
+		}
+		// If it didn't then it counts towards the limits.
+		for asn := range asns {
+			asnHosts[asn]++
+		}
+		for region := range regions {
+			regionHosts[region]++
+		}
+	}
+	return badHosts, nil
+}
@@ -0,0 +1,154 @@
+package hostdb
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestLoadLocationDB tests loading a location database and looking up the
+// locations of hosts.
+func TestLoadLocationDB(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("hostdb", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a database with overlapping networks.
+	path := filepath.Join(dir, "locations.csv")
+	data := `# network,region,asn
+203.0.0.0/8,us,64501
+
+203.0.113.0/24, DE ,64500
+2001:db8::/32,NL,64502
+`
+	if err := ioutil.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := loadLocationDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The most specific network should win.
+	tests := []struct {
+		ip     string
+		loc    modules.HostLocation
+		exists bool
+	}{
+		{"203.0.113.5", modules.HostLocation{Region: "DE", ASN: 64500}, true},
+		{"203.1.2.3", modules.HostLocation{Region: "US", ASN: 64501}, true},
+		{"2001:db8::1", modules.HostLocation{Region: "NL", ASN: 64502}, true},
+		{"198.51.100.1", modules.HostLocation{}, false},
+	}
+	for _, test := range tests {
+		loc, exists := db.lookup(net.ParseIP(test.ip))
+		if exists != test.exists || loc != test.loc {
+			t.Errorf("%v: expected %v %v but got %v %v", test.ip, test.loc, test.exists, loc, exists)
+		}
+	}
+
+	// Check the locations of a host.
+	entry := modules.HostDBEntry{IPNets: []string{"203.0.113.0/24", "198.51.100.0/24"}}
+	locs := db.hostLocations(entry)
+	if len(locs) != 1 || locs[0].Region != "DE" {
+		t.Fatal("unexpected host locations", locs)
+	}
+
+	// Invalid entries should be rejected.
+	if err := ioutil.WriteFile(path, []byte("203.0.113.0/24,DE"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadLocationDB(path); err == nil {
+		t.Fatal("expected invalid database to be rejected")
+	}
+}
+
+// TestCheckForDiversityViolations checks that hosts exceeding the maximum
+// number of hosts per region or ASN are reported.
+func TestCheckForDiversityViolations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdb := bareHostDB()
+	hdb.locations = &locationDB{
+		networks: map[int]map[string]modules.HostLocation{
+			24: {
+				"203.0.113.0/24":  {Region: "DE", ASN: 64500},
+				"198.51.100.0/24": {Region: "DE", ASN: 64501},
+				"192.0.2.0/24":    {Region: "NL", ASN: 64500},
+			},
+		},
+		prefixLens: []int{24},
+	}
+
+	// Create hosts in the networks and one without a known location.
+	var pks []types.SiaPublicKey
+	for _, ipNet := range []string{"203.0.113.0/24", "198.51.100.0/24", "192.0.2.0/24", "10.0.0.0/24"} {
+		entry := makeHostDBEntry()
+		entry.IPNets = []string{ipNet}
+		if err := hdb.staticHostTree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+		pks = append(pks, entry.PublicKey)
+	}
+
+	// Without limits there are no violations.
+	bad, err := hdb.CheckForDiversityViolations(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 0 {
+		t.Fatal("expected no violations", bad)
+	}
+
+	// Allow a single host per region. The second host in DE is bad.
+	hdb.selectionPolicy.MaxHostsPerRegion = 1
+	bad, err = hdb.CheckForDiversityViolations(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 1 || !bad[0].Equals(pks[1]) {
+		t.Fatal("unexpected violations", bad)
+	}
+
+	// Earlier hosts take precedence.
+	bad, err = hdb.CheckForDiversityViolations([]types.SiaPublicKey{pks[1], pks[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 1 || !bad[0].Equals(pks[0]) {
+		t.Fatal("unexpected violations", bad)
+	}
+
+	// Allow a single host per ASN. The host in NL is bad as well now.
+	hdb.selectionPolicy.MaxHostsPerASN = 1
+	bad, err = hdb.CheckForDiversityViolations(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bad) != 2 || !bad[0].Equals(pks[1]) || !bad[1].Equals(pks[2]) {
+		t.Fatal("unexpected violations", bad)
+	}
+
+	// The location of a host is available.
+	loc, located, err := hdb.HostLocation(pks[2])
+	if err != nil || !located || loc.Region != "NL" {
+		t.Fatal("unexpected location", loc, located, err)
+	}
+	_, located, err = hdb.HostLocation(pks[3])
+	if err != nil || located {
+		t.Fatal("host shouldn't be located", located, err)
+	}
+}
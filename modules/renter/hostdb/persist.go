@@ -33,6 +33,7 @@ type hdbPersist struct {
 	LastChange               modules.ConsensusChangeID
 	FilteredHosts            map[string]types.SiaPublicKey
 	FilterMode               modules.FilterMode
+	SelectionPolicy          modules.HostSelectionPolicy
 }
 
 // persistData returns the data in the hostdb that will be saved to disk.
@@ -45,6 +46,7 @@ func (hdb *HostDB) persistData() (data hdbPersist) {
 	data.LastChange = hdb.lastChange
 	data.FilteredHosts = hdb.filteredHosts
 	data.FilterMode = hdb.filterMode
+	data.SelectionPolicy = hdb.selectionPolicy
 	return data
 }
 
@@ -58,6 +60,7 @@ func (hdb *HostDB) load() error {
 	// Fetch the data from the file.
 	var data hdbPersist
 	data.FilteredHosts = make(map[string]types.SiaPublicKey)
+	data.SelectionPolicy = modules.DefaultHostSelectionPolicy()
 	err := hdb.staticDeps.LoadFile(persistMetadata, &data, filepath.Join(hdb.persistDir, persistFilename))
 	if err != nil {
 		return err
@@ -75,6 +78,21 @@ func (hdb *HostDB) load() error {
 	// from disk
 	hdb.filteredDomains = newFilteredDomains(data.FilteredDomains)
 
+	// Load the host selection policy and update the weight function before
+	// the hosts are inserted. A missing location database shouldn't prevent
+	// the hostdb from starting.
+	hdb.selectionPolicy = data.SelectionPolicy
+	if hdb.selectionPolicy.LocationDatabase != "" {
+		hdb.locations, err = loadLocationDB(hdb.selectionPolicy.LocationDatabase)
+		if err != nil {
+			hdb.staticLog.Println("WARN: unable to load location database of host selection policy:", err)
+		}
+	}
+	hdb.weightFunc = hdb.calculateHostWeightFn(hdb.allowance, hdb.txnFees, hdb.selectionPolicy, hdb.locations)
+	if err := hdb.staticHostTree.SetWeightFunction(hdb.weightFunc); err != nil {
+		return err
+	}
+
 	if len(hdb.filteredHosts) > 0 {
 		hdb.filteredTree = hosttree.New(hdb.weightFunc, modules.ProdDependencies.Resolver())
 	}
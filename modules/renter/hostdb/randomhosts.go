@@ -17,10 +17,16 @@ func (hdb *HostDB) RandomHosts(n int, blacklist, addressBlacklist []types.SiaPub
 	initialScanComplete := hdb.initialScanComplete
 	ipCheckDisabled := hdb.disableIPViolationCheck
 	filteredTree := hdb.filteredTree
+	excludesHosts := policyExcludesHosts(hdb.selectionPolicy)
 	hdb.mu.RUnlock()
 	if !initialScanComplete {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
 	}
+	// Hosts which violate the host selection policy are never selected.
+	if excludesHosts {
+		violations := hdb.managedPolicyViolations(filteredTree.All())
+		blacklist = append(append([]types.SiaPublicKey{}, blacklist...), violations...)
+	}
 	if ipCheckDisabled {
 		return filteredTree.SelectRandom(n, blacklist, nil), nil
 	}
@@ -35,6 +41,8 @@ func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist [
 	initialScanComplete := hdb.initialScanComplete
 	filteredHosts := hdb.filteredHosts
 	filterType := hdb.filterMode
+	policy := hdb.selectionPolicy
+	locations := hdb.locations
 	hdb.mu.RUnlock()
 	if !initialScanComplete && !hdb.staticDeps.Disrupt("InitialScanComplete") {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
@@ -54,6 +62,10 @@ func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist [
 		if isWhitelist != ok {
 			continue
 		}
+		// Filter out hosts which violate the host selection policy.
+		if hdb.violatesPolicy(host, policy, locations) {
+			continue
+		}
 		if err := ht.Insert(host); err != nil {
 			insertErrs = errors.Compose(insertErrs, err)
 		}
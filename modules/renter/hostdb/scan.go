@@ -229,6 +229,8 @@ func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, netErr error) {
 		newEntry.HostExternalSettings = entry.HostExternalSettings
 		newEntry.IPNets = entry.IPNets
 		newEntry.LastIPNetChange = entry.LastIPNetChange
+		newEntry.RTT = entry.RTT
+		newEntry.PriceTableLatency = entry.PriceTableLatency
 	} else {
 		newEntry = entry
 	}
@@ -395,7 +397,7 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 	hdb.mu.Unlock()
 
 	var settings modules.HostExternalSettings
-	var latency time.Duration
+	var latency, priceTableLatency time.Duration
 	err = func() error {
 		timeout := hostRequestTimeout
 		hdb.mu.RLock()
@@ -476,7 +478,9 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 
 		// Try opening a connection to the siamux, this is a very lightweight
 		// way of checking that RHP3 is supported.
+		start = time.Now()
 		_, err = fetchPriceTable(hdb.staticMux, siamuxAddr, timeout, modules.SiaPKToMuxPK(entry.PublicKey))
+		priceTableLatency = time.Since(start)
 		if err != nil {
 			hdb.staticLog.Debugf("%v siamux ping not successful: %v\n", entry.PublicKey, err)
 			return err
@@ -498,6 +502,12 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 	oldEntry, exists := hdb.staticHostTree.Select(entry.PublicKey)
 	if exists {
 		entry.NetAddress = oldEntry.NetAddress
+		entry.RTT = oldEntry.RTT
+		entry.PriceTableLatency = oldEntry.PriceTableLatency
+	}
+	// Add the latencies of a successful scan to the host's performance.
+	if success {
+		updateHostScanPerformance(&entry, latency, priceTableLatency)
 	}
 	// Update the host tree to have a new entry, including the new error. Then
 	// delete the entry from the scan map as the scan has been successful.
@@ -56,6 +56,7 @@ var (
 type (
 	// persist contains all of the persistent renter data.
 	persistence struct {
+		ChunkCacheSize   uint64
 		MaxDownloadSpeed int64
 		MaxUploadSpeed   int64
 		UploadedBackups  []modules.UploadedBackup
@@ -423,8 +423,13 @@ func (pdc *projectDownloadChunk) launchWorker(w *worker, pieceIndex uint64, isOv
 		staticSector: pdc.workerSet.staticPieceRoots[pieceIndex],
 	}
 
-	// Submit the job.
-	expectedCompleteTime, added := w.staticJobReadQueue.callAddWithEstimate(jrs)
+	// Submit the job, unless the download or account funding spending caps were
+	// reached.
+	var expectedCompleteTime time.Time
+	var added bool
+	if !w.staticCache().staticDownloadCapReached(jrs.staticJobReadMetadata().staticSpendingCategory) {
+		expectedCompleteTime, added = w.staticJobReadQueue.callAddWithEstimate(jrs)
+	}
 
 	// Track the launched worker
 	if added {
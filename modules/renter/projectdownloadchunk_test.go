@@ -581,6 +581,7 @@ func TestGetPieceOffsetAndLenWithRecover(t *testing.T) {
 // estimates depending on the given jobTime value.
 func mockWorker(jobTime time.Duration) *worker {
 	worker := new(worker)
+	worker.newCache()
 	worker.newPriceTable()
 	worker.staticPriceTable().staticPriceTable = newDefaultPriceTable()
 	worker.initJobReadQueue()
@@ -0,0 +1,592 @@
+package renter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem"
+	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+	"go.thebigfile.com/bigd/persist"
+)
+
+// Re-encoding Overview:
+// Re-encoding migrates an existing siafile to a new erasure code and/or cipher
+// type without requiring a full local copy of the file. The file's data is
+// streamed from the network (or the local file if it is still available)
+// through a download streamer, which is passed as the source reader of a
+// regular streaming upload to a temporary siafile within the reserved
+// re-encoding folder. Since the upload streamer reads and uploads the data one
+// chunk at a time, at most a few chunks are kept in memory at any point.
+//
+// Every re-encoding is tracked by a persisted marker. Once the temporary
+// siafile has reached full redundancy, the marker is updated to indicate that
+// the files are being swapped. The original file is then moved into the
+// re-encoding folder, the temporary file is moved into its place and the
+// original is deleted. If the renter is interrupted during the swap, the swap
+// is completed on startup. Re-encodings which were interrupted before the swap
+// are rolled back by deleting their temporary file. If the original file was
+// deleted, or replaced by a different file, while it was being re-encoded, the
+// re-encoding is rolled back instead of swapping the files.
+//
+// Re-encodings run in the background. Their progress can be followed by
+// looking at the temporary files within the re-encoding folder.
+
+const (
+	// reencodePersistFile is the name of the file within the renter's
+	// persist directory which holds the markers of ongoing re-encodings.
+	reencodePersistFile = "reencode.json"
+
+	// reencodeBackupSuffix is the suffix appended to the temporary name of the
+	// original siafile while it is being swapped with its re-encoded version.
+	reencodeBackupSuffix = "-backup"
+)
+
+var (
+	// errReencodeInProgress is returned if a file is re-encoded while it is
+	// already being re-encoded.
+	errReencodeInProgress = errors.New("file is already being re-encoded")
+
+	// errReencodeNoChange is returned if a file is re-encoded to the same
+	// erasure code and cipher type it already uses.
+	errReencodeNoChange = errors.New("file already uses the requested erasure code and cipher type")
+
+	// errReencodeOriginalGone is returned if the original file was deleted or
+	// replaced by a different file while it was being re-encoded.
+	errReencodeOriginalGone = errors.New("original file was deleted or replaced during the re-encoding")
+
+	// errReencodeNoParams is returned if neither a new erasure code nor a new
+	// cipher type is provided.
+	errReencodeNoParams = errors.New("must provide either a new erasure code or a new cipher type")
+
+	// errReencodeRedundancyTimeout is returned if the re-encoded file doesn't
+	// reach full redundancy in time.
+	errReencodeRedundancyTimeout = errors.New("re-encoded file didn't reach full redundancy in time")
+
+	// errReservedSiaPath is returned if a user tries to create a file or
+	// folder within a folder that is reserved for the renter.
+	errReservedSiaPath = errors.New("siapath is reserved for internal use")
+
+	// reencodeMetadata is the metadata of the persisted re-encoding markers.
+	reencodeMetadata = persist.Metadata{
+		Header:  "Renter Reencode",
+		Version: "1.0",
+	}
+
+	// reencodeRedundancyCheckInterval is the interval at which the redundancy
+	// of a re-encoded file is checked before swapping it with the original.
+	reencodeRedundancyCheckInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: time.Minute,
+		Testnet:  time.Minute,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// reencodeRedundancyTimeout is the amount of time a re-encoded file has to
+	// reach full redundancy before the re-encoding is aborted.
+	reencodeRedundancyTimeout = build.Select(build.Var{
+		Dev:      30 * time.Minute,
+		Standard: 24 * time.Hour,
+		Testnet:  24 * time.Hour,
+		Testing:  time.Minute,
+	}).(time.Duration)
+)
+
+type (
+	// reencodeMarkers keeps track of the ongoing re-encodings.
+	reencodeMarkers struct {
+		markers map[string]reencodeMarker
+
+		staticPath string
+		mu         sync.Mutex
+	}
+
+	// reencodeMarker is the persisted state of a single re-encoding. The UID
+	// identifies the original file in case its siapath is reused while the
+	// file is re-encoded.
+	reencodeMarker struct {
+		ID       string             `json:"id"`
+		SiaPath  modules.SiaPath    `json:"siapath"`
+		UID      siafile.SiafileUID `json:"uid"`
+		Swapping bool               `json:"swapping"`
+	}
+
+	// reencodeJob contains the information required to re-encode a file.
+	reencodeJob struct {
+		marker reencodeMarker
+
+		ec        modules.ErasureCoder
+		ct        crypto.CipherType
+		localPath string
+		mode      os.FileMode
+	}
+)
+
+// newReencodeMarkers loads the persisted re-encoding markers from the
+// provided directory.
+func newReencodeMarkers(dir string) (*reencodeMarkers, error) {
+	rm := &reencodeMarkers{
+		markers:    make(map[string]reencodeMarker),
+		staticPath: filepath.Join(dir, reencodePersistFile),
+	}
+	var markers []reencodeMarker
+	err := persist.LoadJSON(reencodeMetadata, &markers, rm.staticPath)
+	if os.IsNotExist(err) {
+		return rm, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load re-encoding markers")
+	}
+	for _, m := range markers {
+		rm.markers[m.ID] = m
+	}
+	return rm, nil
+}
+
+// callAdd adds a marker for a new re-encoding of the file with the provided
+// UID at siaPath.
+func (rm *reencodeMarkers) callAdd(siaPath modules.SiaPath, uid siafile.SiafileUID) (reencodeMarker, error) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	for _, m := range rm.markers {
+		if m.SiaPath.Equals(siaPath) {
+			return reencodeMarker{}, errReencodeInProgress
+		}
+	}
+	m := reencodeMarker{
+		ID:      hex.EncodeToString(fastrand.Bytes(16)),
+		SiaPath: siaPath,
+		UID:     uid,
+	}
+	rm.markers[m.ID] = m
+	if err := rm.save(); err != nil {
+		delete(rm.markers, m.ID)
+		return reencodeMarker{}, err
+	}
+	return m, nil
+}
+
+// callMarkers returns all the markers.
+func (rm *reencodeMarkers) callMarkers() []reencodeMarker {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	markers := make([]reencodeMarker, 0, len(rm.markers))
+	for _, m := range rm.markers {
+		markers = append(markers, m)
+	}
+	return markers
+}
+
+// callRemove removes a marker.
+func (rm *reencodeMarkers) callRemove(id string) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	delete(rm.markers, id)
+	return rm.save()
+}
+
+// callUpdate updates an existing marker.
+func (rm *reencodeMarkers) callUpdate(m reencodeMarker) error {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.markers[m.ID] = m
+	return rm.save()
+}
+
+// save persists the markers.
+func (rm *reencodeMarkers) save() error {
+	markers := make([]reencodeMarker, 0, len(rm.markers))
+	for _, m := range rm.markers {
+		markers = append(markers, m)
+	}
+	return persist.SaveJSON(reencodeMetadata, markers, rm.staticPath)
+}
+
+// tmpSiaPath returns the path of the re-encoded file while it is uploaded.
+func (m reencodeMarker) tmpSiaPath() (modules.SiaPath, error) {
+	return modules.ReencodeFolder.Join(m.ID)
+}
+
+// backupSiaPath returns the path of the original file while it is swapped
+// with the re-encoded file.
+func (m reencodeMarker) backupSiaPath() (modules.SiaPath, error) {
+	return modules.ReencodeFolder.Join(m.ID + reencodeBackupSuffix)
+}
+
+// isReservedSiaPath returns true if the siapath is within a folder that is
+// reserved for the renter.
+func isReservedSiaPath(siaPath modules.SiaPath) bool {
+	return siaPath.Equals(modules.ReencodeFolder) || strings.HasPrefix(siaPath.Path, modules.ReencodeFolder.Path+"/")
+}
+
+// checkUserSiaPath returns an error if a user isn't allowed to create a file
+// or folder at siaPath.
+func checkUserSiaPath(siaPath modules.SiaPath) error {
+	if isReservedSiaPath(siaPath) {
+		return errors.AddContext(errReservedSiaPath, siaPath.String())
+	}
+	return nil
+}
+
+// ReencodeFile starts migrating the file at siaPath to the provided erasure
+// code and cipher type in the background. A nil erasure code or an invalid
+// cipher type keeps the file's current setting.
+func (r *Renter) ReencodeFile(siaPath modules.SiaPath, ec modules.ErasureCoder, ct crypto.CipherType) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	job, err := r.managedPrepareReencode(siaPath, ec, ct)
+	if err != nil {
+		return err
+	}
+	go r.threadedReencode(job)
+	return nil
+}
+
+// ReencodeDir starts migrating all the files within the directory at siaPath
+// and its subdirectories to the provided erasure code and cipher type in the
+// background. Files which already use the requested settings are skipped.
+func (r *Renter) ReencodeDir(siaPath modules.SiaPath, ec modules.ErasureCoder, ct crypto.CipherType) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	if ec == nil && !crypto.IsValidCipherType(ct) {
+		return errReencodeNoParams
+	}
+
+	// Collect the files first to avoid iterating over the directory while
+	// files are being added to and removed from it.
+	var mu sync.Mutex
+	var siaPaths []modules.SiaPath
+	err := r.staticFileSystem.CachedList(siaPath, true, func(fi modules.FileInfo) {
+		if isReservedSiaPath(fi.SiaPath) {
+			return
+		}
+		mu.Lock()
+		siaPaths = append(siaPaths, fi.SiaPath)
+		mu.Unlock()
+	}, func(modules.DirectoryInfo) {})
+	if err != nil {
+		return errors.AddContext(err, "unable to list files for re-encoding")
+	}
+	go r.threadedReencodeFiles(siaPaths, ec, ct)
+	return nil
+}
+
+// threadedReencodeFiles re-encodes the provided files one after another. The
+// method continues after a file fails to be re-encoded.
+func (r *Renter) threadedReencodeFiles(siaPaths []modules.SiaPath, ec modules.ErasureCoder, ct crypto.CipherType) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	for _, sp := range siaPaths {
+		select {
+		case <-r.tg.StopChan():
+			return
+		default:
+		}
+		job, err := r.managedPrepareReencode(sp, ec, ct)
+		if errors.Contains(err, errReencodeNoChange) {
+			continue
+		}
+		if err == nil {
+			err = r.managedReencode(job)
+		}
+		if err != nil {
+			r.log.Printf("WARN: failed to re-encode '%v': %v", sp, err)
+		}
+	}
+}
+
+// threadedReencode performs a prepared re-encoding.
+func (r *Renter) threadedReencode(job *reencodeJob) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	if err := r.managedReencode(job); err != nil {
+		r.log.Printf("WARN: failed to re-encode '%v': %v", job.marker.SiaPath, err)
+	}
+}
+
+// managedPrepareReencode checks the requested settings against the settings
+// of the file at siaPath and adds a marker for the re-encoding.
+func (r *Renter) managedPrepareReencode(siaPath modules.SiaPath, ec modules.ErasureCoder, ct crypto.CipherType) (*reencodeJob, error) {
+	if ec == nil && !crypto.IsValidCipherType(ct) {
+		return nil, errReencodeNoParams
+	}
+	if isReservedSiaPath(siaPath) {
+		return nil, errors.AddContext(errReservedSiaPath, siaPath.String())
+	}
+
+	// Grab the settings of the original file.
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to open siafile")
+	}
+	job := &reencodeJob{
+		ec:        ec,
+		ct:        ct,
+		localPath: entry.LocalPath(),
+		mode:      entry.Mode(),
+	}
+	uid := entry.UID()
+	oldEC := entry.ErasureCode()
+	oldCT := entry.MasterKey().Type()
+	if err := entry.Close(); err != nil {
+		return nil, err
+	}
+	if job.ec == nil {
+		job.ec = oldEC
+	}
+	if !crypto.IsValidCipherType(job.ct) {
+		job.ct = oldCT
+	}
+	if job.ec.Identifier() == oldEC.Identifier() && job.ct == oldCT {
+		return nil, errReencodeNoChange
+	}
+
+	job.marker, err = r.staticReencodeMarkers.callAdd(siaPath, uid)
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// managedReencode streams the data of the file into a new siafile with the
+// job's erasure code and cipher type and then replaces the original file with
+// the new one once the new file reached full redundancy.
+func (r *Renter) managedReencode(job *reencodeJob) error {
+	// Roll back the re-encoding if it fails before the swap.
+	if err := r.managedUploadReencode(job); err != nil {
+		return errors.Compose(err, r.managedAbortReencode(job.marker))
+	}
+	return r.managedSwapReencode(job.marker)
+}
+
+// managedUploadReencode uploads the re-encoded file to the temporary siapath
+// of the re-encoding and waits for it to reach full redundancy.
+func (r *Renter) managedUploadReencode(job *reencodeJob) (err error) {
+	m := job.marker
+	tmpSiaPath, err := m.tmpSiaPath()
+	if err != nil {
+		return err
+	}
+
+	// Open a streamer for the original file. Local fetches are allowed to save
+	// bandwidth if the original file is still available on disk.
+	_, streamer, err := r.Streamer(m.SiaPath, false)
+	if err != nil {
+		return errors.AddContext(err, "unable to open streamer for original file")
+	}
+	defer func() {
+		err = errors.Compose(err, streamer.Close())
+	}()
+
+	// Upload the data to the temporary file.
+	up := modules.FileUploadParams{
+		Source:      job.localPath,
+		SiaPath:     tmpSiaPath,
+		ErasureCode: job.ec,
+		CipherType:  job.ct,
+	}
+	fileNode, err := r.callUploadStreamFromReader(up, streamer)
+	if err != nil {
+		return errors.AddContext(err, "unable to upload re-encoded file")
+	}
+	err = errors.Compose(fileNode.SetMode(job.mode), fileNode.Close())
+	if err != nil {
+		return err
+	}
+
+	// Wait for the new file to be fully uploaded before replacing the
+	// original.
+	return r.managedWaitForFullRedundancy(tmpSiaPath)
+}
+
+// managedSwapReencode marks the re-encoding as swapping and replaces the
+// original file with the re-encoded file. Once the marker is persisted, a
+// failed swap is no longer rolled back. Instead the marker is kept so that the
+// swap is completed on startup.
+func (r *Renter) managedSwapReencode(m reencodeMarker) error {
+	m.Swapping = true
+	if err := r.staticReencodeMarkers.callUpdate(m); err != nil {
+		err = errors.AddContext(err, "unable to persist re-encoding marker")
+		m.Swapping = false
+		return errors.Compose(err, r.managedAbortReencode(m))
+	}
+	return r.managedFinishReencodeSwap(m)
+}
+
+// managedWaitForFullRedundancy blocks until the file at siaPath reaches full
+// redundancy.
+func (r *Renter) managedWaitForFullRedundancy(siaPath modules.SiaPath) error {
+	timeout := time.After(reencodeRedundancyTimeout)
+	for {
+		entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+		if err != nil {
+			return errors.AddContext(err, "unable to open re-encoded file")
+		}
+		offline, goodForRenew, _ := r.managedContractUtilityMaps()
+		redundancy, _, err := entry.Redundancy(offline, goodForRenew)
+		ec := entry.ErasureCode()
+		err = errors.Compose(err, entry.Close())
+		if err != nil {
+			return errors.AddContext(err, "unable to get redundancy of re-encoded file")
+		}
+		// A redundancy of -1 indicates an empty file.
+		if redundancy < 0 || redundancy >= float64(ec.NumPieces())/float64(ec.MinPieces()) {
+			return nil
+		}
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("re-encoding interrupted by shutdown")
+		case <-timeout:
+			return errReencodeRedundancyTimeout
+		case <-time.After(reencodeRedundancyCheckInterval):
+		}
+	}
+}
+
+// managedFinishReencodeSwap replaces the original file of a re-encoding with
+// the re-encoded file. The swap isn't atomic. The original is renamed to the
+// backup siapath, the re-encoded file is renamed to the original siapath and
+// then the backup is deleted before the marker is removed. Until the marker is
+// removed, managedRecoverReencodes calls this method again on startup, which
+// derives the remaining steps from the files that exist. If the temporary file
+// and the original exist, both renames are redone. If the temporary file and
+// the backup exist, the renter crashed between the renames and only the
+// re-encoded file is moved into place. Until then no file exists at the
+// original siapath. If only the backup exists, both renames completed and the
+// backup is deleted. If neither exists, only the marker is removed. If the
+// temporary file exists but neither the original nor the backup does, the
+// original was deleted and the re-encoding is rolled back.
+func (r *Renter) managedFinishReencodeSwap(m reencodeMarker) error {
+	tmpSiaPath, err := m.tmpSiaPath()
+	if err != nil {
+		return err
+	}
+	backupSiaPath, err := m.backupSiaPath()
+	if err != nil {
+		return err
+	}
+
+	// If the re-encoded file wasn't moved into place yet, move the original
+	// file out of the way unless that already happened and move the
+	// re-encoded file into its place.
+	tmpExists, err := r.staticFileSystem.FileExists(tmpSiaPath)
+	if err != nil {
+		return err
+	}
+	if tmpExists {
+		originalExists, err := r.managedReencodeOriginalExists(m)
+		if err != nil {
+			return err
+		}
+		backupExists, err := r.staticFileSystem.FileExists(backupSiaPath)
+		if err != nil {
+			return err
+		}
+		// The original may only be missing if it was already moved out of
+		// the way.
+		if !originalExists && !backupExists {
+			return errors.Compose(errReencodeOriginalGone, r.managedAbortReencode(m))
+		}
+		if originalExists {
+			err = r.staticFileSystem.RenameFile(m.SiaPath, backupSiaPath)
+			if err != nil {
+				return errors.AddContext(err, "unable to move original file out of the way")
+			}
+		}
+		if r.deps.Disrupt("InterruptReencodeSwap") {
+			return errors.New("re-encoding swap interrupted by dependency")
+		}
+		err = r.staticFileSystem.RenameFile(tmpSiaPath, m.SiaPath)
+		if err != nil {
+			return errors.AddContext(err, "unable to move re-encoded file into place")
+		}
+	}
+
+	// Delete the original file.
+	err = r.staticFileSystem.DeleteFile(backupSiaPath)
+	if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+		return errors.AddContext(err, "unable to delete original file")
+	}
+	if err := r.staticReencodeMarkers.callRemove(m.ID); err != nil {
+		return errors.AddContext(err, "unable to remove re-encoding marker")
+	}
+
+	// Queue a bubble for the directory to update the metadata.
+	dirSiaPath, err := m.SiaPath.Dir()
+	if err != nil {
+		return err
+	}
+	_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	return nil
+}
+
+// managedReencodeOriginalExists returns whether the original file of a
+// re-encoding still exists at its siapath. A different file at the same
+// siapath doesn't count as the original.
+func (r *Renter) managedReencodeOriginalExists(m reencodeMarker) (bool, error) {
+	exists, err := r.staticFileSystem.FileExists(m.SiaPath)
+	if err != nil || !exists {
+		return false, err
+	}
+	entry, err := r.staticFileSystem.OpenSiaFile(m.SiaPath)
+	if err != nil {
+		return false, errors.AddContext(err, "unable to open original file")
+	}
+	uid := entry.UID()
+	if err := entry.Close(); err != nil {
+		return false, err
+	}
+	// Markers of older versions don't contain the UID.
+	return m.UID == "" || uid == m.UID, nil
+}
+
+// managedAbortReencode deletes the temporary file of a re-encoding which
+// didn't reach the swap and removes its marker.
+func (r *Renter) managedAbortReencode(m reencodeMarker) error {
+	tmpSiaPath, err := m.tmpSiaPath()
+	if err != nil {
+		return err
+	}
+	err = r.staticFileSystem.DeleteFile(tmpSiaPath)
+	if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+		return errors.AddContext(err, "unable to delete temporary re-encoding file")
+	}
+	return r.staticReencodeMarkers.callRemove(m.ID)
+}
+
+// managedRecoverReencodes completes the swaps of re-encodings which were
+// interrupted during the swap and rolls back all other interrupted
+// re-encodings.
+func (r *Renter) managedRecoverReencodes() error {
+	var errs error
+	for _, m := range r.staticReencodeMarkers.callMarkers() {
+		var err error
+		if m.Swapping {
+			err = r.managedFinishReencodeSwap(m)
+		} else {
+			err = r.managedAbortReencode(m)
+		}
+		if err != nil {
+			errs = errors.Compose(errs, errors.AddContext(err, fmt.Sprintf("unable to recover re-encoding of '%v'", m.SiaPath)))
+		}
+	}
+	return errs
+}
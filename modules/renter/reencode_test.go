@@ -0,0 +1,434 @@
+package renter
+
+import (
+	"os"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+	"go.thebigfile.com/bigd/persist"
+	"go.thebigfile.com/bigd/siatest/dependencies"
+)
+
+// TestReencodeMarkers tests adding, updating, removing and reloading
+// re-encoding markers.
+func TestReencodeMarkers(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, persist.DefaultDiskPermissionsTest); err != nil {
+		t.Fatal(err)
+	}
+	rm, err := newReencodeMarkers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add markers for two files.
+	sp1, sp2 := modules.RandomSiaPath(), modules.RandomSiaPath()
+	m1, err := rm.callAdd(sp1, "uid1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m2, err := rm.callAdd(sp2, "uid2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m1.ID == m2.ID {
+		t.Fatal("markers should have different IDs")
+	}
+	// A file can't be re-encoded twice at the same time.
+	if _, err := rm.callAdd(sp1, "uid1"); !errors.Contains(err, errReencodeInProgress) {
+		t.Fatal("expected errReencodeInProgress but got", err)
+	}
+	// Update one marker and remove the other one.
+	m1.Swapping = true
+	if err := rm.callUpdate(m1); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.callRemove(m2.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reload the markers.
+	rm, err = newReencodeMarkers(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	markers := rm.callMarkers()
+	if len(markers) != 1 {
+		t.Fatalf("expected 1 marker but got %v", len(markers))
+	}
+	if markers[0] != m1 {
+		t.Fatalf("marker wasn't persisted correctly: %v != %v", markers[0], m1)
+	}
+}
+
+// TestReencodeReservedSiaPath tests that users can't create files or folders
+// within the re-encoding folder.
+func TestReencodeReservedSiaPath(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	reserved, err := modules.ReencodeFolder.Join("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateDir(modules.ReencodeFolder, modules.DefaultDirPerm); !errors.Contains(err, errReservedSiaPath) {
+		t.Fatal("expected errReservedSiaPath but got", err)
+	}
+	if err := r.CreateDir(reserved, modules.DefaultDirPerm); !errors.Contains(err, errReservedSiaPath) {
+		t.Fatal("expected errReservedSiaPath but got", err)
+	}
+
+	// Files can't be renamed into or out of the folder.
+	entry, err := r.newRenterTestFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	siaPath := r.staticFileSystem.FileSiaPath(entry)
+	if err := entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenameFile(siaPath, reserved); !errors.Contains(err, errReservedSiaPath) {
+		t.Fatal("expected errReservedSiaPath but got", err)
+	}
+	if err := r.RenameFile(reserved, siaPath); !errors.Contains(err, errReservedSiaPath) {
+		t.Fatal("expected errReservedSiaPath but got", err)
+	}
+
+	// Paths which only share a prefix with the folder are fine.
+	sp, err := modules.NewSiaPath(modules.ReencodeFolder.Path + "-not-reserved")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.CreateDir(sp, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// reencodeTestFile creates an empty siafile with the provided local path to
+// tell files apart and returns its UID.
+func reencodeTestFile(t *testing.T, r *Renter, siaPath modules.SiaPath, localPath string) siafile.SiafileUID {
+	entry, err := r.createRenterTestFile(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid := entry.UID()
+	if err := errors.Compose(entry.SetLocalPath(localPath), entry.Close()); err != nil {
+		t.Fatal(err)
+	}
+	return uid
+}
+
+// reencodeTestLocalPath returns the local path of the file at siaPath.
+func reencodeTestLocalPath(t *testing.T, r *Renter, siaPath modules.SiaPath) string {
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lp := entry.LocalPath()
+	if err := entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return lp
+}
+
+// reencodeTestFileExists returns whether a file exists at siaPath.
+func reencodeTestFileExists(t *testing.T, r *Renter, siaPath modules.SiaPath) bool {
+	exists, err := r.staticFileSystem.FileExists(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return exists
+}
+
+// TestRecoverReencodes tests that interrupted re-encodings are rolled back
+// before the swap and completed during the swap.
+func TestRecoverReencodes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// A re-encoding which was interrupted before the swap.
+	aborted, err := r.staticReencodeMarkers.callAdd(modules.RandomSiaPath(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	abortedTmp, err := aborted.tmpSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, aborted.SiaPath, "original")
+	reencodeTestFile(t, r, abortedTmp, "reencoded")
+
+	// A re-encoding which was interrupted before moving the original out of
+	// the way.
+	swapping, err := r.staticReencodeMarkers.callAdd(modules.RandomSiaPath(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	swapping.Swapping = true
+	if err := r.staticReencodeMarkers.callUpdate(swapping); err != nil {
+		t.Fatal(err)
+	}
+	swappingTmp, err := swapping.tmpSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, swapping.SiaPath, "original")
+	reencodeTestFile(t, r, swappingTmp, "reencoded")
+
+	// A re-encoding which was interrupted after moving the original out of
+	// the way.
+	moved, err := r.staticReencodeMarkers.callAdd(modules.RandomSiaPath(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	moved.Swapping = true
+	if err := r.staticReencodeMarkers.callUpdate(moved); err != nil {
+		t.Fatal(err)
+	}
+	movedTmp, err := moved.tmpSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	movedBackup, err := moved.backupSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, movedBackup, "original")
+	reencodeTestFile(t, r, movedTmp, "reencoded")
+
+	// Recover the re-encodings.
+	if err := r.managedRecoverReencodes(); err != nil {
+		t.Fatal(err)
+	}
+	if markers := r.staticReencodeMarkers.callMarkers(); len(markers) != 0 {
+		t.Fatalf("expected no markers but got %v", len(markers))
+	}
+	if lp := reencodeTestLocalPath(t, r, aborted.SiaPath); lp != "original" {
+		t.Fatal("original file should have been kept but got", lp)
+	}
+	if lp := reencodeTestLocalPath(t, r, swapping.SiaPath); lp != "reencoded" {
+		t.Fatal("re-encoded file should have replaced the original but got", lp)
+	}
+	if lp := reencodeTestLocalPath(t, r, moved.SiaPath); lp != "reencoded" {
+		t.Fatal("re-encoded file should have replaced the original but got", lp)
+	}
+	for _, sp := range []modules.SiaPath{abortedTmp, swappingTmp, movedTmp, movedBackup} {
+		if reencodeTestFileExists(t, r, sp) {
+			t.Fatalf("temporary file %v should have been deleted", sp)
+		}
+	}
+}
+
+// TestReencodeSwapInterrupted simulates crashes during the swap of a
+// re-encoding and checks that restarting the renter completes the swap from
+// the persisted marker. The first crash happens between moving the original
+// out of the way and moving the re-encoded file into place, the second one
+// after both renames but before the original was deleted.
+func TestReencodeSwapInterrupted(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	deps := dependencies.NewDependencyInterruptReencodeSwap()
+	rt, err := newRenterTesterWithDependency(t.Name(), deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	siaPath := modules.RandomSiaPath()
+	uid := reencodeTestFile(t, r, siaPath, "original")
+	m, err := r.staticReencodeMarkers.callAdd(siaPath, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpSiaPath, err := m.tmpSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupSiaPath, err := m.backupSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, tmpSiaPath, "reencoded")
+
+	// Interrupt the swap between moving the original out of the way and
+	// moving the re-encoded file into place.
+	deps.Fail()
+	if err := r.managedSwapReencode(m); err == nil {
+		t.Fatal("expected the swap to fail")
+	}
+	markers := r.staticReencodeMarkers.callMarkers()
+	if len(markers) != 1 || !markers[0].Swapping {
+		t.Fatal("marker should have been kept", markers)
+	}
+	if !reencodeTestFileExists(t, r, tmpSiaPath) || !reencodeTestFileExists(t, r, backupSiaPath) {
+		t.Fatal("files of the interrupted swap should have been kept")
+	}
+	if reencodeTestFileExists(t, r, siaPath) {
+		t.Fatal("no file should be at the original siapath between the renames")
+	}
+
+	// checkSwapped restarts the renter and checks that the swap was completed
+	// on startup.
+	checkSwapped := func() {
+		t.Helper()
+		r, err = rt.reloadRenter(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if markers := r.staticReencodeMarkers.callMarkers(); len(markers) != 0 {
+			t.Fatalf("expected no markers but got %v", len(markers))
+		}
+		if lp := reencodeTestLocalPath(t, r, siaPath); lp != "reencoded" {
+			t.Fatal("re-encoded file should have replaced the original but got", lp)
+		}
+		if reencodeTestFileExists(t, r, tmpSiaPath) || reencodeTestFileExists(t, r, backupSiaPath) {
+			t.Fatal("temporary files should have been deleted")
+		}
+	}
+	checkSwapped()
+
+	// Crash after both renames, before the original is deleted.
+	if err := r.staticFileSystem.DeleteFile(siaPath); err != nil {
+		t.Fatal(err)
+	}
+	uid = reencodeTestFile(t, r, siaPath, "original")
+	m, err = r.staticReencodeMarkers.callAdd(siaPath, uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Swapping = true
+	if err := r.staticReencodeMarkers.callUpdate(m); err != nil {
+		t.Fatal(err)
+	}
+	tmpSiaPath, err = m.tmpSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	backupSiaPath, err = m.backupSiaPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.staticFileSystem.RenameFile(siaPath, backupSiaPath); err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, siaPath, "reencoded")
+	checkSwapped()
+}
+
+// TestReencodeOriginalGone tests that a re-encoding is rolled back if the
+// original file was deleted or replaced before the swap.
+func TestReencodeOriginalGone(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// prepare creates an original file and a re-encoded file and returns the
+	// marker of the re-encoding.
+	prepare := func() reencodeMarker {
+		siaPath := modules.RandomSiaPath()
+		uid := reencodeTestFile(t, r, siaPath, "original")
+		m, err := r.staticReencodeMarkers.callAdd(siaPath, uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tmpSiaPath, err := m.tmpSiaPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		reencodeTestFile(t, r, tmpSiaPath, "reencoded")
+		return m
+	}
+	// checkAborted checks that the re-encoding was rolled back.
+	checkAborted := func(m reencodeMarker) {
+		if err := r.managedSwapReencode(m); !errors.Contains(err, errReencodeOriginalGone) {
+			t.Fatal("expected errReencodeOriginalGone but got", err)
+		}
+		tmpSiaPath, err := m.tmpSiaPath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if reencodeTestFileExists(t, r, tmpSiaPath) {
+			t.Fatal("re-encoded file should have been deleted")
+		}
+		for _, marker := range r.staticReencodeMarkers.callMarkers() {
+			if marker.ID == m.ID {
+				t.Fatal("marker should have been removed")
+			}
+		}
+	}
+
+	// The original was deleted.
+	deleted := prepare()
+	if err := r.DeleteFile(deleted.SiaPath); err != nil {
+		t.Fatal(err)
+	}
+	checkAborted(deleted)
+	if reencodeTestFileExists(t, r, deleted.SiaPath) {
+		t.Fatal("deleted file shouldn't have been restored")
+	}
+
+	// The original was replaced by a different file.
+	replaced := prepare()
+	if err := r.DeleteFile(replaced.SiaPath); err != nil {
+		t.Fatal(err)
+	}
+	reencodeTestFile(t, r, replaced.SiaPath, "replaced")
+	checkAborted(replaced)
+	if lp := reencodeTestLocalPath(t, r, replaced.SiaPath); lp != "replaced" {
+		t.Fatal("file at the original's siapath shouldn't have been touched but got", lp)
+	}
+}
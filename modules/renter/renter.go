@@ -101,6 +101,10 @@ type hostContractor interface {
 	// watchdog.
 	ContractStatus(fcID types.FileContractID) (modules.ContractWatchStatus, bool)
 
+	// ContractEvents returns the events from the contract event journal that
+	// match the filter.
+	ContractEvents(filter modules.ContractEventFilter) ([]modules.ContractEvent, error)
+
 	// CurrentPeriod returns the height at which the current allowance period
 	// began.
 	CurrentPeriod() types.BlockHeight
@@ -113,6 +117,14 @@ type hostContractor interface {
 	// billing period.
 	PeriodSpending() (modules.ContractorSpending, error)
 
+	// RecordAccountDownloadSpending adds money spent on a download paid for
+	// by an ephemeral account to the current period's download spending.
+	RecordAccountDownloadSpending(amount types.Currency)
+
+	// SpendingCapStatus returns which of the allowance's spending caps have
+	// been reached in the current period.
+	SpendingCapStatus() modules.SpendingCapStatus
+
 	// ProvidePayment takes a stream and a set of payment details and handles
 	// the payment for an RPC by sending and processing payment request and
 	// response objects to the host. It returns an error in case of failure.
@@ -257,6 +269,11 @@ type Renter struct {
 	staticAlerter                      *modules.GenericAlerter
 	staticFileSystem                   *filesystem.FileSystem
 	staticFuseManager                  renterFuseManager
+	staticReencodeMarkers              *reencodeMarkers
+	staticResumableUploads             *resumableUploadManager
+	staticTenants                      *tenantManager
+	staticTrustScores                  *hostTrustScores
+	staticChunkCache                   *chunkCache
 	staticStreamBufferSet              *streamBufferSet
 	tg                                 threadgroup.ThreadGroup
 	tpool                              modules.TransactionPool
@@ -620,8 +637,15 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 		return err
 	}
 
+	// Resize the chunk cache.
+	err = r.staticChunkCache.callSetMaxSize(s.ChunkCacheSize)
+	if err != nil {
+		return errors.AddContext(err, "unable to resize chunk cache")
+	}
+
 	// Save the changes.
 	id := r.mu.Lock()
+	r.persist.ChunkCacheSize = s.ChunkCacheSize
 	r.persist.MaxDownloadSpeed = s.MaxDownloadSpeed
 	r.persist.MaxUploadSpeed = s.MaxUploadSpeed
 	err = r.saveSync()
@@ -715,6 +739,24 @@ func (r *Renter) SetFilterMode(lm modules.FilterMode, hosts []types.SiaPublicKey
 	return nil
 }
 
+// HostSelectionPolicy returns the hostdb's host selection policy.
+func (r *Renter) HostSelectionPolicy() (modules.HostSelectionPolicy, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.HostSelectionPolicy{}, err
+	}
+	defer r.tg.Done()
+	return r.hostDB.HostSelectionPolicy()
+}
+
+// SetHostSelectionPolicy sets the hostdb's host selection policy.
+func (r *Renter) SetHostSelectionPolicy(policy modules.HostSelectionPolicy) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.hostDB.SetHostSelectionPolicy(policy)
+}
+
 // Host returns the host associated with the given public key
 func (r *Renter) Host(spk types.SiaPublicKey) (modules.HostDBEntry, bool, error) {
 	return r.hostDB.Host(spk)
@@ -767,6 +809,12 @@ func (r *Renter) ContractStatus(fcID types.FileContractID) (modules.ContractWatc
 	return r.hostContractor.ContractStatus(fcID)
 }
 
+// ContractEvents returns the events from the contractor's event journal that
+// match the filter.
+func (r *Renter) ContractEvents(filter modules.ContractEventFilter) ([]modules.ContractEvent, error) {
+	return r.hostContractor.ContractEvents(filter)
+}
+
 // ContractorChurnStatus returns contract churn stats for the current period.
 func (r *Renter) ContractorChurnStatus() modules.ContractorChurnStatus {
 	return r.hostContractor.ChurnStatus()
@@ -819,6 +867,7 @@ func (r *Renter) Settings() (modules.RenterSettings, error) {
 	paused, endTime := r.uploadHeap.managedPauseStatus()
 	return modules.RenterSettings{
 		Allowance:        r.hostContractor.Allowance(),
+		ChunkCacheSize:   r.staticChunkCache.callStatus().MaxSize,
 		IPViolationCheck: enabled,
 		MaxDownloadSpeed: download,
 		MaxUploadSpeed:   upload,
@@ -971,6 +1020,26 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	r.repairMemoryManager = newMemoryManager(repairMemoryDefault, repairMemoryPriorityDefault, r.tg.StopChan())
 
 	r.staticFuseManager = newFuseManager(r)
+	r.staticResumableUploads, err = newResumableUploadManager(filepath.Join(r.persistDir, resumableUploadsDir))
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to create resumable upload manager")
+	}
+	r.staticTrustScores, err = newHostTrustScores(r.persistDir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load host trust scores")
+	}
+	r.staticReencodeMarkers, err = newReencodeMarkers(r.persistDir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load re-encoding markers")
+	}
+	r.staticTenants, err = newTenantManager(r.persistDir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load tenants")
+	}
+	err = r.tg.AfterStop(r.staticTenants.callSave)
+	if err != nil {
+		return nil, err
+	}
 	r.stuckStack = callNewStuckStack()
 
 	// Load all saved data.
@@ -979,6 +1048,18 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 		return nil, err
 	}
 
+	// Finish or roll back re-encodings which were interrupted by a shutdown.
+	err = r.managedRecoverReencodes()
+	if err != nil {
+		return nil, err
+	}
+
+	// After persist is initialized, load the chunk cache.
+	r.staticChunkCache, err = newChunkCache(filepath.Join(r.persistDir, chunkCacheDir), r.persist.ChunkCacheSize)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load chunk cache")
+	}
+
 	// After persist is initialized, create the worker pool.
 	r.staticWorkerPool = r.newWorkerPool()
 
@@ -1005,6 +1086,9 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	// for bubble updates are processed.
 	go r.staticBubbleScheduler.callThreadedProcessBubbleUpdates()
 
+	// Spin up the thread which aborts abandoned resumable uploads.
+	go r.threadedPruneResumableUploads()
+
 	// Unsubscribe on shutdown.
 	err = r.tg.OnStop(func() error {
 		cs.Unsubscribe(r)
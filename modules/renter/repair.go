@@ -13,6 +13,7 @@ import (
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/filesystem"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/types"
 )
 
@@ -291,7 +292,7 @@ func (r *Renter) managedStuckDirectory() (modules.SiaPath, error) {
 		if directories[0].AggregateNumStuckChunks == 0 {
 			// Log error if we are not at the root directory
 			if !siaPath.IsRoot() {
-				r.log.Println("WARN: ended up in directory with no stuck chunks that is not root directory:", siaPath)
+				r.log.WithFields(persist.Field(persist.LogKeySiaPath, siaPath)).Println("WARN: ended up in directory with no stuck chunks that is not root directory")
 			}
 			return siaPath, errNoStuckFiles
 		}
@@ -606,7 +607,10 @@ func (r *Renter) threadedUpdateRenterHealth() {
 		urp, err := r.callPrepareForBubble(siaPath, false)
 		if err != nil {
 			// Log the error
-			r.log.Println("Error calling callPrepareForBubble on `", siaPath.String(), "`:", err)
+			r.log.WithFields(
+				persist.Field(persist.LogKeySiaPath, siaPath),
+				persist.Field(persist.LogKeyError, err),
+			).Println("Error calling callPrepareForBubble")
 
 			// Check if urp is nil. This should only happen if the first call to Add
 			// the Root dir fails.
@@ -678,7 +682,7 @@ func (r *Renter) callPrepareForBubble(rootDir modules.SiaPath, force bool) (*uni
 		// Add the directory to uniqueRefreshPaths
 		addErr := urp.callAdd(di.SiaPath)
 		if addErr != nil {
-			r.log.Printf("WARN: unable to add siapath `%v` to uniqueRefreshPaths; err: %v", di.SiaPath, addErr)
+			r.log.WithFields(persist.Field(persist.LogKeySiaPath, di.SiaPath), persist.Field(persist.LogKeyError, addErr)).Println("WARN: unable to add siapath to uniqueRefreshPaths")
 			err = errors.Compose(err, addErr)
 			return
 		}
@@ -0,0 +1,430 @@
+package renter
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/persist"
+	"go.thebigfile.com/bigd/types"
+)
+
+// Tenants Overview:
+// Tenants share the renter's contracts and workers, but every tenant is
+// confined to its own root directory within the tenants folder. Tenants
+// authenticate against the API with their name and a random token which is
+// only returned when the tenant is created. The renter only stores the hash of
+// the token.
+//
+// The renter keeps track of the data that tenants uploaded and downloaded
+// through the API in the current period, which is used to enforce the
+// tenants' quotas. A share of the period's spending is attributed to every
+// tenant. The storage and upload spending is split by the share of stored
+// data. The download spending is split by the share of downloaded data, which
+// is why the downloads which don't belong to any tenant are tracked as well.
+
+const (
+	// tenantsPersistFile is the name of the file within the renter's persist
+	// directory which holds the tenants.
+	tenantsPersistFile = "tenants.json"
+
+	// tenantTokenSize is the number of random bytes of a tenant's token.
+	tenantTokenSize = 32
+)
+
+var (
+	// tenantsMetadata is the metadata of the persisted tenants.
+	tenantsMetadata = persist.Metadata{
+		Header:  "Renter Tenants",
+		Version: "1.0",
+	}
+
+	// tenantTrafficPersistInterval is the minimum amount of time between two
+	// saves of the tenants that are caused by recording traffic.
+	tenantTrafficPersistInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testnet:  time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// tenantNameRegex matches valid tenant names.
+	tenantNameRegex = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+)
+
+type (
+	// tenantManager keeps track of the renter's tenants and their traffic.
+	tenantManager struct {
+		tenants map[string]*tenant
+
+		// period is the allowance period the traffic was recorded in and
+		// downloaded is the downloaded data which doesn't belong to any
+		// tenant.
+		period     types.BlockHeight
+		downloaded uint64
+
+		lastSave   time.Time
+		staticPath string
+		mu         sync.Mutex
+	}
+
+	// tenant is the persisted state of a single tenant.
+	tenant struct {
+		Name       string              `json:"name"`
+		TokenHash  crypto.Hash         `json:"tokenhash"`
+		Quota      modules.TenantQuota `json:"quota"`
+		Uploaded   uint64              `json:"uploaded"`
+		Downloaded uint64              `json:"downloaded"`
+	}
+
+	// tenantsPersist is the persisted state of the tenant manager.
+	tenantsPersist struct {
+		Tenants    []tenant          `json:"tenants"`
+		Period     types.BlockHeight `json:"period"`
+		Downloaded uint64            `json:"downloaded"`
+	}
+)
+
+// newTenantManager loads the persisted tenants from the provided directory.
+func newTenantManager(dir string) (*tenantManager, error) {
+	tm := &tenantManager{
+		tenants:    make(map[string]*tenant),
+		staticPath: filepath.Join(dir, tenantsPersistFile),
+	}
+	var data tenantsPersist
+	err := persist.LoadJSON(tenantsMetadata, &data, tm.staticPath)
+	if os.IsNotExist(err) {
+		return tm, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load tenants")
+	}
+	for i := range data.Tenants {
+		tm.tenants[data.Tenants[i].Name] = &data.Tenants[i]
+	}
+	tm.period = data.Period
+	tm.downloaded = data.Downloaded
+	return tm, nil
+}
+
+// tenantRoot returns the root directory of the tenant with the given name.
+func tenantRoot(name string) modules.SiaPath {
+	root, err := modules.TenantsFolder.Join(name)
+	if err != nil {
+		build.Critical("tenant name should always be a valid siapath", name, err)
+	}
+	return root
+}
+
+// save persists the tenants.
+func (tm *tenantManager) save() error {
+	data := tenantsPersist{
+		Tenants:    make([]tenant, 0, len(tm.tenants)),
+		Period:     tm.period,
+		Downloaded: tm.downloaded,
+	}
+	for _, t := range tm.tenants {
+		data.Tenants = append(data.Tenants, *t)
+	}
+	sort.Slice(data.Tenants, func(i, j int) bool {
+		return data.Tenants[i].Name < data.Tenants[j].Name
+	})
+	tm.lastSave = time.Now()
+	return persist.SaveJSON(tenantsMetadata, data, tm.staticPath)
+}
+
+// resetPeriod resets the traffic of all tenants if a new period started.
+func (tm *tenantManager) resetPeriod(period types.BlockHeight) {
+	if tm.period == period {
+		return
+	}
+	tm.period = period
+	tm.downloaded = 0
+	for _, t := range tm.tenants {
+		t.Uploaded = 0
+		t.Downloaded = 0
+	}
+}
+
+// callCreate adds a new tenant and returns its token.
+func (tm *tenantManager) callCreate(name string, quota modules.TenantQuota) (string, error) {
+	if !tenantNameRegex.MatchString(name) {
+		return "", modules.ErrInvalidTenantName
+	}
+	token := hex.EncodeToString(fastrand.Bytes(tenantTokenSize))
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if _, exists := tm.tenants[name]; exists {
+		return "", modules.ErrTenantExists
+	}
+	tm.tenants[name] = &tenant{
+		Name:      name,
+		TokenHash: crypto.HashBytes([]byte(token)),
+		Quota:     quota,
+	}
+	if err := tm.save(); err != nil {
+		delete(tm.tenants, name)
+		return "", errors.AddContext(err, "unable to save tenants")
+	}
+	return token, nil
+}
+
+// callDelete removes a tenant.
+func (tm *tenantManager) callDelete(name string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, exists := tm.tenants[name]
+	if !exists {
+		return modules.ErrUnknownTenant
+	}
+	delete(tm.tenants, name)
+	if err := tm.save(); err != nil {
+		tm.tenants[name] = t
+		return errors.AddContext(err, "unable to save tenants")
+	}
+	return nil
+}
+
+// callSetQuota updates the quota of a tenant.
+func (tm *tenantManager) callSetQuota(name string, quota modules.TenantQuota) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, exists := tm.tenants[name]
+	if !exists {
+		return modules.ErrUnknownTenant
+	}
+	t.Quota = quota
+	return tm.save()
+}
+
+// callAuthenticate checks the token of a tenant.
+func (tm *tenantManager) callAuthenticate(name, token string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	t, exists := tm.tenants[name]
+	if !exists {
+		return modules.ErrUnknownTenant
+	}
+	if crypto.HashBytes([]byte(token)) != t.TokenHash {
+		return modules.ErrTenantAuthentication
+	}
+	return nil
+}
+
+// callRecordTraffic adds traffic to a tenant or, if the name is empty, to the
+// downloads which don't belong to any tenant.
+func (tm *tenantManager) callRecordTraffic(period types.BlockHeight, name string, uploaded, downloaded uint64) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.resetPeriod(period)
+	if name == "" {
+		tm.downloaded += downloaded
+	} else if t, exists := tm.tenants[name]; exists {
+		t.Uploaded += uploaded
+		t.Downloaded += downloaded
+	} else {
+		return modules.ErrUnknownTenant
+	}
+	if time.Since(tm.lastSave) < tenantTrafficPersistInterval {
+		return nil
+	}
+	return tm.save()
+}
+
+// callSave persists the tenants.
+func (tm *tenantManager) callSave() error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.save()
+}
+
+// callTenants returns the persisted state of the tenants with the given names
+// or of all tenants if no names are provided. It also returns the total
+// amount of data downloaded in the period.
+func (tm *tenantManager) callTenants(period types.BlockHeight, names ...string) (tenants []tenant, downloaded uint64, err error) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.resetPeriod(period)
+	if len(names) == 0 {
+		for name := range tm.tenants {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	for _, name := range names {
+		t, exists := tm.tenants[name]
+		if !exists {
+			return nil, 0, modules.ErrUnknownTenant
+		}
+		tenants = append(tenants, *t)
+	}
+	downloaded = tm.downloaded
+	for _, t := range tm.tenants {
+		downloaded += t.Downloaded
+	}
+	return tenants, downloaded, nil
+}
+
+// attributeSpending returns the share of spending which corresponds to the
+// share of part in total.
+func attributeSpending(spending types.Currency, part, total uint64) types.Currency {
+	if total == 0 || part == 0 {
+		return types.ZeroCurrency
+	}
+	if part >= total {
+		return spending
+	}
+	return spending.Mul64(part).Div64(total)
+}
+
+// managedTenantInfos returns the info of the tenants with the given names or
+// of all tenants if no names are provided. The spending estimates are only
+// computed if withSpending is set, since they require the period spending of
+// the contractor and the size of the whole filesystem.
+func (r *Renter) managedTenantInfos(withSpending bool, names ...string) ([]modules.TenantInfo, error) {
+	tenants, downloaded, err := r.staticTenants.callTenants(r.hostContractor.CurrentPeriod(), names...)
+	if err != nil {
+		return nil, err
+	}
+	if len(tenants) == 0 {
+		return []modules.TenantInfo{}, nil
+	}
+	var spending modules.ContractorSpending
+	var rootInfo modules.DirectoryInfo
+	if withSpending {
+		spending, err = r.hostContractor.PeriodSpending()
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to get period spending")
+		}
+		rootInfo, err = r.staticFileSystem.DirInfo(modules.RootSiaPath())
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to get size of the filesystem")
+		}
+	}
+	downloadSpending := spending.DownloadSpending.Add(spending.AccountDownloadSpending)
+	infos := make([]modules.TenantInfo, 0, len(tenants))
+	for _, t := range tenants {
+		root := tenantRoot(t.Name)
+		di, err := r.staticFileSystem.DirInfo(root)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to get size of tenant root")
+		}
+		storage := di.AggregateSize
+		infos = append(infos, modules.TenantInfo{
+			Name:  t.Name,
+			Root:  root,
+			Quota: t.Quota,
+			Usage: modules.TenantUsage{
+				Storage:          storage,
+				Uploaded:         t.Uploaded,
+				Downloaded:       t.Downloaded,
+				StorageSpending:  attributeSpending(spending.StorageSpending, storage, rootInfo.AggregateSize),
+				UploadSpending:   attributeSpending(spending.UploadSpending, storage, rootInfo.AggregateSize),
+				DownloadSpending: attributeSpending(downloadSpending, t.Downloaded, downloaded),
+			},
+		})
+	}
+	return infos, nil
+}
+
+// CreateTenant creates a new tenant with its own root directory and returns
+// the token the tenant authenticates with.
+func (r *Renter) CreateTenant(name string, quota modules.TenantQuota) (string, error) {
+	if err := r.tg.Add(); err != nil {
+		return "", err
+	}
+	defer r.tg.Done()
+	token, err := r.staticTenants.callCreate(name, quota)
+	if err != nil {
+		return "", err
+	}
+	err = r.staticFileSystem.NewSiaDir(tenantRoot(name), modules.DefaultDirPerm)
+	if err != nil {
+		return "", errors.Compose(errors.AddContext(err, "unable to create tenant root"), r.staticTenants.callDelete(name))
+	}
+	return token, nil
+}
+
+// DeleteTenant removes a tenant. The files within the tenant's root directory
+// are not deleted.
+func (r *Renter) DeleteTenant(name string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.staticTenants.callDelete(name)
+}
+
+// SetTenantQuota updates the quota of a tenant.
+func (r *Renter) SetTenantQuota(name string, quota modules.TenantQuota) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.staticTenants.callSetQuota(name, quota)
+}
+
+// Tenant returns information about a tenant.
+func (r *Renter) Tenant(name string) (modules.TenantInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.TenantInfo{}, err
+	}
+	defer r.tg.Done()
+	infos, err := r.managedTenantInfos(true, name)
+	if err != nil {
+		return modules.TenantInfo{}, err
+	}
+	return infos[0], nil
+}
+
+// Tenants returns information about all of the renter's tenants.
+func (r *Renter) Tenants() ([]modules.TenantInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.managedTenantInfos(true)
+}
+
+// AuthenticateTenant returns information about the tenant with the given name
+// if the token is valid. It is called for every request of a tenant, so the
+// spending estimates of the tenant's usage are not computed. The storage usage
+// is taken from the aggregate metadata of the tenant's root directory.
+func (r *Renter) AuthenticateTenant(name, token string) (modules.TenantInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.TenantInfo{}, err
+	}
+	defer r.tg.Done()
+	if err := r.staticTenants.callAuthenticate(name, token); err != nil {
+		return modules.TenantInfo{}, err
+	}
+	infos, err := r.managedTenantInfos(false, name)
+	if err != nil {
+		return modules.TenantInfo{}, err
+	}
+	return infos[0], nil
+}
+
+// RecordTenantTraffic adds uploaded and downloaded bytes to the usage of a
+// tenant in the current period. An empty name records downloads which don't
+// belong to any tenant.
+func (r *Renter) RecordTenantTraffic(name string, uploaded, downloaded uint64) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	err := r.staticTenants.callRecordTraffic(r.hostContractor.CurrentPeriod(), name, uploaded, downloaded)
+	if err != nil {
+		r.log.Println("WARN: unable to record tenant traffic:", err)
+	}
+}
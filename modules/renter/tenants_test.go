@@ -0,0 +1,205 @@
+package renter
+
+import (
+	"os"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestTenantManager tests creating, authenticating and deleting tenants as
+// well as the accounting and persistence of their traffic.
+func TestTenantManager(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	tm, err := newTenantManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid names and duplicates are rejected.
+	for _, name := range []string{"", "Foo", "foo/bar", "../foo"} {
+		if _, err := tm.callCreate(name, modules.TenantQuota{}); !errors.Contains(err, modules.ErrInvalidTenantName) {
+			t.Fatalf("expected %v for %q but got %v", modules.ErrInvalidTenantName, name, err)
+		}
+	}
+	quota := modules.TenantQuota{Storage: 100, Upload: 50}
+	token, err := tm.callCreate("foo", quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.callCreate("foo", quota); !errors.Contains(err, modules.ErrTenantExists) {
+		t.Fatal("expected ErrTenantExists but got", err)
+	}
+
+	// Check authentication.
+	if err := tm.callAuthenticate("foo", token); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callAuthenticate("foo", token+"0"); !errors.Contains(err, modules.ErrTenantAuthentication) {
+		t.Fatal("expected ErrTenantAuthentication but got", err)
+	}
+	if err := tm.callAuthenticate("bar", token); !errors.Contains(err, modules.ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+
+	// Record traffic of the tenant and of other users.
+	if err := tm.callRecordTraffic(1, "foo", 20, 30); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callRecordTraffic(1, "", 0, 70); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callRecordTraffic(1, "bar", 1, 1); !errors.Contains(err, modules.ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+	tenants, downloaded, err := tm.callTenants(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 1 || tenants[0].Uploaded != 20 || tenants[0].Downloaded != 30 || downloaded != 100 {
+		t.Fatal("unexpected traffic", tenants, downloaded)
+	}
+
+	// The traffic is persisted.
+	if err := tm.callSave(); err != nil {
+		t.Fatal(err)
+	}
+	tm, err = newTenantManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callAuthenticate("foo", token); err != nil {
+		t.Fatal(err)
+	}
+	tenants, downloaded, err = tm.callTenants(1, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenants[0].Uploaded != 20 || tenants[0].Quota != quota || downloaded != 100 {
+		t.Fatal("tenant wasn't persisted", tenants[0], downloaded)
+	}
+
+	// A new period resets the traffic.
+	tenants, downloaded, err = tm.callTenants(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tenants[0].Uploaded != 0 || tenants[0].Downloaded != 0 || downloaded != 0 {
+		t.Fatal("traffic wasn't reset", tenants[0], downloaded)
+	}
+
+	// Update the quota and delete the tenant.
+	quota.Download = 10
+	if err := tm.callSetQuota("foo", quota); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callDelete("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.callDelete("foo"); !errors.Contains(err, modules.ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+	if err := tm.callSetQuota("foo", quota); !errors.Contains(err, modules.ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+}
+
+// TestAttributeSpending is a unit test for attributeSpending.
+func TestAttributeSpending(t *testing.T) {
+	spending := types.NewCurrency64(1000)
+	tests := []struct {
+		part, total uint64
+		expected    uint64
+	}{
+		{0, 0, 0},
+		{0, 100, 0},
+		{25, 100, 250},
+		{100, 100, 1000},
+		{200, 100, 1000},
+	}
+	for _, test := range tests {
+		if s := attributeSpending(spending, test.part, test.total); !s.Equals64(test.expected) {
+			t.Errorf("attributeSpending(%v, %v) = %v, expected %v", test.part, test.total, s, test.expected)
+		}
+	}
+}
+
+// TestRenterTenants tests creating tenants through the renter.
+func TestRenterTenants(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	quota := modules.TenantQuota{Download: 100}
+	token, err := r.CreateTenant("foo", quota)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The tenant's root directory is created.
+	ti, err := r.AuthenticateTenant("foo", token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedRoot, err := modules.TenantsFolder.Join("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ti.Root.Equals(expectedRoot) || ti.Quota != quota {
+		t.Fatal("unexpected tenant info", ti)
+	}
+	if _, err := r.staticFileSystem.DirInfo(ti.Root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Downloads count towards the download quota.
+	r.RecordTenantTraffic("foo", 0, 60)
+	ti, err = r.Tenant("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining, limited := ti.RemainingDownload(); !limited || remaining != 40 {
+		t.Fatal("unexpected remaining download", remaining, limited)
+	}
+	if _, limited := ti.RemainingUpload(); limited {
+		t.Fatal("upload shouldn't be limited")
+	}
+
+	// Deleting the tenant prevents authentication.
+	if err := r.DeleteTenant("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.AuthenticateTenant("foo", token); !errors.Contains(err, modules.ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+	tenants, err := r.Tenants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tenants) != 0 {
+		t.Fatal("expected no tenants", tenants)
+	}
+}
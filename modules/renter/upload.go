@@ -32,6 +32,9 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 		return err
 	}
 	defer r.tg.Done()
+	if err := checkUserSiaPath(up.SiaPath); err != nil {
+		return err
+	}
 
 	// Check if the file is a directory.
 	sourceInfo, err := os.Stat(up.Source)
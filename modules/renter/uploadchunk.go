@@ -13,6 +13,7 @@ import (
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/modules/renter/filesystem"
 	"go.thebigfile.com/bigd/modules/renter/filesystem/siafile"
+	"go.thebigfile.com/bigd/persist"
 )
 
 // uploadChunkID is a unique identifier for each chunk in the renter.
@@ -39,6 +40,8 @@ type unfinishedUploadChunk struct {
 	offset                 int64  // Offset of the chunk within the file.
 	onDisk                 bool   // indicates if there is a local file accessible on disk
 	staticPiecesNeeded     int    // number of pieces to achieve a 100% complete upload
+	staticMinPieceRegions  int    // minimum number of distinct regions the pieces are uploaded to
+	staticRepair           bool   // indicates if pieces of the chunk were uploaded before
 	stuck                  bool   // indicates if the chunk was marked as stuck during last repair
 	stuckRepair            bool   // indicates if the chunk was identified for repair by the stuck loop
 
@@ -112,6 +115,7 @@ type unfinishedUploadChunk struct {
 	mu               sync.Mutex
 	pieceUsage       []bool              // 'true' if a piece is either uploaded, or a worker is attempting to upload that piece.
 	piecesCompleted  int                 // number of pieces that have been fully uploaded.
+	pieceRegions     map[string]int      // number of completed or registered pieces per region, only tracked if staticMinPieceRegions is set.
 	piecesRegistered int                 // number of pieces that are being uploaded, but aren't finished yet (may fail).
 	released         bool                // whether this chunk has been released from the active chunks set.
 	unusedHosts      map[string]struct{} // hosts that aren't yet storing any pieces or performing any work.
@@ -217,6 +221,43 @@ func (uc *unfinishedUploadChunk) chunkComplete() bool {
 	return false
 }
 
+// regionAllowed returns whether a host in the given region may upload a piece
+// of the chunk without preventing the chunk from reaching the minimum number
+// of distinct piece regions. Hosts without a known location don't add a
+// region.
+func (uc *unfinishedUploadChunk) regionAllowed(loc modules.HostLocation, located bool) bool {
+	missingRegions := uc.staticMinPieceRegions - len(uc.pieceRegions)
+	if missingRegions <= 0 {
+		return true
+	}
+	if located && uc.pieceRegions[loc.Region] == 0 {
+		return true
+	}
+	// The host doesn't add a region. Only allow it if enough pieces are left
+	// for the missing regions.
+	freePieces := uc.staticPiecesNeeded - uc.piecesCompleted - uc.piecesRegistered
+	return freePieces > missingRegions
+}
+
+// addPieceRegion tracks a completed or registered piece in the given region.
+func (uc *unfinishedUploadChunk) addPieceRegion(loc modules.HostLocation, located bool) {
+	if uc.staticMinPieceRegions == 0 || !located {
+		return
+	}
+	uc.pieceRegions[loc.Region]++
+}
+
+// removePieceRegion removes a piece which was tracked by addPieceRegion.
+func (uc *unfinishedUploadChunk) removePieceRegion(loc modules.HostLocation, located bool) {
+	if uc.staticMinPieceRegions == 0 || !located || uc.pieceRegions[loc.Region] == 0 {
+		return
+	}
+	uc.pieceRegions[loc.Region]--
+	if uc.pieceRegions[loc.Region] == 0 {
+		delete(uc.pieceRegions, loc.Region)
+	}
+}
+
 // readDataPieces reads dataPieces from a io.Reader and stores them in a
 // [][]byte ready to be encoded using an ErasureCoder.
 func readDataPieces(r io.Reader, ec modules.ErasureCoder, pieceSize uint64) ([][]byte, uint64, error) {
@@ -398,7 +439,8 @@ func (r *Renter) threadedFetchAndRepairChunk(chunk *unfinishedUploadChunk) {
 		chunk.mu.Unlock()
 
 		// Log error.
-		r.repairLog.Printf(err.Error())
+		chunkLog := r.repairLog.WithFields(persist.Field(persist.LogKeySiaPath, chunk.staticSiaPath))
+		chunkLog.Println(err.Error())
 
 		// Cleanup the failed chunk without holding the lock.
 		r.managedCleanUpUploadChunk(chunk)
@@ -413,7 +455,7 @@ func (r *Renter) threadedFetchAndRepairChunk(chunk *unfinishedUploadChunk) {
 		// logical data.
 		err = chunk.fileEntry.SetStuck(chunk.staticIndex, true)
 		if err != nil {
-			r.repairLog.Printf("Error marking chunk %v of file %s as stuck: %v", chunk.staticIndex, chunk.staticSiaPath, err)
+			chunkLog.WithFields(persist.Field(persist.LogKeyError, err)).Printf("Error marking chunk %v as stuck", chunk.staticIndex)
 		}
 		return
 	}
@@ -565,7 +607,7 @@ func (r *Renter) managedFetchLogicalChunkData(uc *unfinishedUploadChunk) error {
 			// NOTE: we are removing the localpath here to avoid potential
 			// future corruption by a different file with the same filename
 			// being added at the localpath location.
-			r.log.Println("WARN: local file not found on disk, setting localpath to '' to avoid corruption for", uc.fileEntry.SiaFilePath())
+			r.log.WithFields(persist.Field(persist.LogKeySiaPath, uc.staticSiaPath)).Println("WARN: local file not found on disk, setting localpath to '' to avoid corruption")
 			err = errors.Compose(err, uc.fileEntry.SetLocalPath(""))
 		}
 		if err != nil {
@@ -587,7 +629,10 @@ func (r *Renter) managedFetchLogicalChunkData(uc *unfinishedUploadChunk) error {
 		return nil
 	}()
 	if err != nil {
-		r.log.Printf("falling back to remote download for repair: fetch from local file %v failed: %v", uc.fileEntry.LocalPath(), err)
+		r.log.WithFields(
+			persist.Field(persist.LogKeySiaPath, uc.staticSiaPath),
+			persist.Field(persist.LogKeyError, err),
+		).Printf("falling back to remote download for repair: fetch from local file %v failed", uc.fileEntry.LocalPath())
 		return r.managedDownloadLogicalChunkData(uc)
 	}
 	return nil
@@ -643,10 +688,11 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 	released := uc.released
 	canceled := uc.canceled
 	if chunkComplete && !released {
+		chunkLog := r.repairLog.WithFields(persist.Field(persist.LogKeySiaPath, uc.staticSiaPath))
 		if uc.piecesCompleted >= uc.staticPiecesNeeded {
-			r.repairLog.Printf("Completed repair for chunk %v of %s, %v pieces were completed out of %v", uc.staticIndex, uc.staticSiaPath, uc.piecesCompleted, uc.staticPiecesNeeded)
+			chunkLog.Printf("Completed repair for chunk %v, %v pieces were completed out of %v", uc.staticIndex, uc.piecesCompleted, uc.staticPiecesNeeded)
 		} else {
-			r.repairLog.Printf("Repair of chunk %v of %s was unsuccessful, %v pieces were completed out of %v", uc.staticIndex, uc.staticSiaPath, uc.piecesCompleted, uc.staticPiecesNeeded)
+			chunkLog.Printf("Repair of chunk %v was unsuccessful, %v pieces were completed out of %v", uc.staticIndex, uc.piecesCompleted, uc.staticPiecesNeeded)
 		}
 		if !uc.staticAvailable() {
 			uc.err = errors.New("unable to upload file, file is not available on the network")
@@ -757,6 +803,7 @@ func (r *Renter) managedUpdateUploadChunkStuckStatus(uc *unfinishedUploadChunk)
 	// Determine if repair was successful.
 	health := siafile.CalculateHealth(piecesCompleted, minimumPieces, piecesNeeded)
 	successfulRepair := !modules.NeedsRepair(health)
+	chunkLog := r.log.WithFields(persist.Field(persist.LogKeySiaPath, uc.staticSiaPath))
 
 	// Check if renter is shutting down
 	var renterError bool
@@ -772,27 +819,27 @@ func (r *Renter) managedUpdateUploadChunkStuckStatus(uc *unfinishedUploadChunk)
 
 	// If the repair was unsuccessful and there was a renter error then return
 	if !successfulRepair && renterError {
-		r.log.Debugln("WARN: repair unsuccessful for chunk", uc.id, "due to an error with the renter")
+		chunkLog.Debugln("WARN: repair unsuccessful for chunk", uc.id, "due to an error with the renter")
 		return
 	}
 	// Log if the repair was unsuccessful
 	if !successfulRepair {
-		r.log.Debugln("WARN: repair unsuccessful, marking chunk", uc.id, "as stuck", float64(piecesCompleted)/float64(piecesNeeded))
+		chunkLog.Debugln("WARN: repair unsuccessful, marking chunk", uc.id, "as stuck", float64(piecesCompleted)/float64(piecesNeeded))
 	} else {
-		r.log.Debugln("SUCCESS: repair successful, marking chunk as non-stuck:", uc.id)
+		chunkLog.Debugln("SUCCESS: repair successful, marking chunk as non-stuck:", uc.id)
 	}
 	// Update chunk stuck status unless the dependency to skip this step is
 	// enabled.
 	if !r.deps.Disrupt("DontUpdateChunkStatus") {
 		if err := uc.fileEntry.SetStuck(index, !successfulRepair); err != nil {
-			r.log.Printf("WARN: could not set chunk %v stuck status for file %v: %v", uc.id, uc.fileEntry.SiaFilePath(), err)
+			chunkLog.WithFields(persist.Field(persist.LogKeyError, err)).Printf("WARN: could not set chunk %v stuck status", uc.id)
 		}
 	}
 
 	// Check to see if the chunk was stuck and now is successfully repaired by
 	// the stuck loop
 	if stuck && successfulRepair && stuckRepair {
-		r.log.Debugln("Stuck chunk", uc.id, "successfully repaired")
+		chunkLog.Debugln("Stuck chunk", uc.id, "successfully repaired")
 		// Add file to the successful stuck repair stack if there are still
 		// stuck chunks to repair
 		if uc.fileEntry.NumStuckChunks() > 0 {
@@ -565,6 +565,17 @@ func (r *Renter) managedBuildUnfinishedChunk(entry *filesystem.FileNode, chunkIn
 		uuc.unusedHosts[host] = struct{}{}
 	}
 
+	// Track the regions of the pieces if the host selection policy requires
+	// a minimum number of piece regions.
+	policy, err := r.hostDB.HostSelectionPolicy()
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to get host selection policy")
+	}
+	if policy.MinPieceRegions > 0 {
+		uuc.staticMinPieceRegions = int(policy.MinPieceRegions)
+		uuc.pieceRegions = make(map[string]int)
+	}
+
 	// Iterate through the pieces of all chunks of the file and mark which
 	// hosts are already in use for a particular chunk. As you delete hosts
 	// from the 'unusedHosts' map, also increment the 'piecesCompleted' value.
@@ -596,6 +607,10 @@ func (r *Renter) managedBuildUnfinishedChunk(entry *filesystem.FileNode, chunkIn
 			if exists && goodForRenew && exists2 && !offline && exists3 && !redundantPiece {
 				uuc.pieceUsage[pieceIndex] = true
 				uuc.piecesCompleted++
+				if uuc.staticMinPieceRegions > 0 {
+					loc, located, _ := r.hostDB.HostLocation(piece.HostPubKey)
+					uuc.addPieceRegion(loc, located)
+				}
 			}
 
 			// In all cases, if this host already has a piece, the host cannot
@@ -608,6 +623,7 @@ func (r *Renter) managedBuildUnfinishedChunk(entry *filesystem.FileNode, chunkIn
 		// a local (and therefore potentially altered or corrupt) file.
 		if len(pieceSet) > 0 {
 			uuc.staticExpectedPieceRoots[pieceIndex] = pieceSet[0].MerkleRoot
+			uuc.staticRepair = true
 		}
 	}
 	// Now that we have calculated the completed pieces for the chunk we can
@@ -124,6 +124,9 @@ func (r *Renter) UploadStreamFromReader(up modules.FileUploadParams, reader io.R
 		return err
 	}
 	defer r.tg.Done()
+	if err := checkUserSiaPath(up.SiaPath); err != nil {
+		return err
+	}
 
 	// Perform the upload, close the filenode, and return.
 	fileNode, err := r.callUploadStreamFromReader(up, reader)
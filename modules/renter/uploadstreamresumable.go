@@ -0,0 +1,574 @@
+package renter
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem"
+	"go.thebigfile.com/bigd/persist"
+	"go.thebigfile.com/bigd/types"
+)
+
+// Resumable Upload Overview:
+// A resumable upload splits a streaming upload into multiple requests which
+// each append data to the upload at the upload's committed offset. The data of
+// each request is first appended to a staging file on disk. After syncing the
+// staging file, the number of staged bytes is persisted in the upload's
+// metadata. Whenever a full chunk is staged, the chunk is pushed into the
+// upload pipeline and the renter waits for the chunk to become available on
+// the network. Afterwards the number of committed chunks is incremented and
+// the number of staged bytes is reset in a single metadata update before the
+// staging file is truncated.
+//
+// The committed offset, which is the size of the committed chunks plus the
+// number of staged bytes, is therefore only derived from the metadata. Bytes
+// in the staging file beyond the staged bytes were never acknowledged and are
+// discarded before the next write. If the renter crashes after uploading a
+// chunk but before updating the metadata, the chunk is still fully staged and
+// the next write or finalize uploads it again, which is a no-op for chunks
+// that are already available on the network. If a request is interrupted, the
+// client can query the committed offset and continue from there. Bytes which
+// are resent from an earlier offset are skipped.
+//
+// Finalizing the upload pushes the remaining staged data as the last chunk of
+// the file. Since the SiaFile currently treats partial chunks as full chunks
+// (see siafile.New), that chunk follows the same path as the last chunk of
+// any other streamed upload.
+//
+// Uploads which are neither written to nor finalized for
+// resumableUploadTimeout are considered abandoned. They are aborted by a
+// background thread, which deletes their siafile, staging file and metadata.
+
+const (
+	// resumableUploadsDir is the name of the directory within the renter's
+	// persist directory which holds the state of resumable uploads.
+	resumableUploadsDir = "resumableuploads"
+
+	// resumableUploadMetadataExtension is the extension of the file holding
+	// the persisted state of a resumable upload.
+	resumableUploadMetadataExtension = ".json"
+
+	// resumableUploadStagingExtension is the extension of the file holding
+	// the staged data of a resumable upload.
+	resumableUploadStagingExtension = ".partial"
+)
+
+var (
+	// ErrResumableUploadNotFound is returned if a resumable upload doesn't
+	// exist.
+	ErrResumableUploadNotFound = errors.New("resumable upload not found")
+
+	// ErrResumableUploadOffsetMismatch is returned if data is written to a
+	// resumable upload at an offset that isn't the committed offset.
+	ErrResumableUploadOffsetMismatch = errors.New("offset doesn't match the committed offset of the upload")
+
+	// resumableUploadMetadata is the metadata of the persisted state of a
+	// resumable upload.
+	resumableUploadMetadata = persist.Metadata{
+		Header:  "Resumable Upload",
+		Version: "1.0",
+	}
+
+	// resumableUploadTimeout is the amount of time after the last write to a
+	// resumable upload after which the upload is considered abandoned and
+	// aborted.
+	resumableUploadTimeout = build.Select(build.Var{
+		Dev:      time.Hour,
+		Standard: time.Hour * 24 * 7,
+		Testnet:  time.Hour * 24 * 7,
+		Testing:  time.Minute,
+	}).(time.Duration)
+
+	// resumableUploadPruneInterval is the interval at which the renter checks
+	// for abandoned resumable uploads.
+	resumableUploadPruneInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testnet:  time.Hour,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+)
+
+type (
+	// resumableUploadManager keeps track of the renter's resumable uploads.
+	resumableUploadManager struct {
+		uploads map[modules.UploadID]*resumableUpload
+
+		staticDir string
+		mu        sync.Mutex
+	}
+
+	// resumableUpload is the state of a single resumable upload. The mutex is
+	// held for the whole duration of a write to serialize writes.
+	resumableUpload struct {
+		persist resumableUploadPersist
+
+		staticMetadataPath string
+		staticStagingPath  string
+		mu                 sync.Mutex
+	}
+
+	// resumableUploadPersist is the persisted state of a resumable upload.
+	resumableUploadPersist struct {
+		ID              modules.UploadID `json:"id"`
+		SiaPath         modules.SiaPath  `json:"siapath"`
+		ChunkSize       uint64           `json:"chunksize"`
+		CommittedChunks uint64           `json:"committedchunks"`
+		StagedBytes     uint64           `json:"stagedbytes"`
+		LastWrite       time.Time        `json:"lastwrite"`
+	}
+)
+
+// newResumableUploadManager creates a new manager and loads the persisted
+// resumable uploads from dir.
+func newResumableUploadManager(dir string) (*resumableUploadManager, error) {
+	err := os.MkdirAll(dir, modules.DefaultDirPerm)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to create resumable uploads dir")
+	}
+	rum := &resumableUploadManager{
+		uploads:   make(map[modules.UploadID]*resumableUpload),
+		staticDir: dir,
+	}
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to read resumable uploads dir")
+	}
+	for _, fi := range fis {
+		if filepath.Ext(fi.Name()) != resumableUploadMetadataExtension {
+			continue
+		}
+		id := modules.UploadID(strings.TrimSuffix(fi.Name(), resumableUploadMetadataExtension))
+		ru := rum.newUpload(id)
+		err = persist.LoadJSON(resumableUploadMetadata, &ru.persist, ru.staticMetadataPath)
+		if err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("unable to load resumable upload %v", id))
+		}
+		// Uploads which were persisted before the last write was tracked
+		// start their timeout now.
+		if ru.persist.LastWrite.IsZero() {
+			ru.persist.LastWrite = time.Now()
+		}
+		rum.uploads[id] = ru
+	}
+	return rum, nil
+}
+
+// newUpload creates a resumable upload object with the given id without
+// adding it to the manager.
+func (rum *resumableUploadManager) newUpload(id modules.UploadID) *resumableUpload {
+	return &resumableUpload{
+		persist: resumableUploadPersist{
+			ID: id,
+		},
+		staticMetadataPath: filepath.Join(rum.staticDir, string(id)+resumableUploadMetadataExtension),
+		staticStagingPath:  filepath.Join(rum.staticDir, string(id)+resumableUploadStagingExtension),
+	}
+}
+
+// callAdd adds a resumable upload to the manager.
+func (rum *resumableUploadManager) callAdd(ru *resumableUpload) {
+	rum.mu.Lock()
+	defer rum.mu.Unlock()
+	rum.uploads[ru.persist.ID] = ru
+}
+
+// callRemove removes a resumable upload from the manager.
+func (rum *resumableUploadManager) callRemove(id modules.UploadID) {
+	rum.mu.Lock()
+	defer rum.mu.Unlock()
+	delete(rum.uploads, id)
+}
+
+// callUploads returns all resumable uploads of the manager.
+func (rum *resumableUploadManager) callUploads() []*resumableUpload {
+	rum.mu.Lock()
+	defer rum.mu.Unlock()
+	uploads := make([]*resumableUpload, 0, len(rum.uploads))
+	for _, ru := range rum.uploads {
+		uploads = append(uploads, ru)
+	}
+	return uploads
+}
+
+// callUpload returns the resumable upload with the given id.
+func (rum *resumableUploadManager) callUpload(id modules.UploadID) (*resumableUpload, error) {
+	rum.mu.Lock()
+	defer rum.mu.Unlock()
+	ru, exists := rum.uploads[id]
+	if !exists {
+		return nil, ErrResumableUploadNotFound
+	}
+	return ru, nil
+}
+
+// info returns the current state of the upload.
+func (ru *resumableUpload) info() modules.ResumableUploadInfo {
+	return modules.ResumableUploadInfo{
+		ID:        ru.persist.ID,
+		SiaPath:   ru.persist.SiaPath,
+		Offset:    ru.persist.CommittedChunks*ru.persist.ChunkSize + ru.persist.StagedBytes,
+		ChunkSize: ru.persist.ChunkSize,
+	}
+}
+
+// saveSync persists the state of the upload.
+func (ru *resumableUpload) saveSync() error {
+	return persist.SaveJSON(resumableUploadMetadata, ru.persist, ru.staticMetadataPath)
+}
+
+// openStaging opens the upload's staging file for appending and discards any
+// data beyond the persisted number of staged bytes.
+func (ru *resumableUpload) openStaging() (*os.File, error) {
+	staging, err := os.OpenFile(ru.staticStagingPath, os.O_RDWR|os.O_CREATE|os.O_APPEND, modules.DefaultFilePerm)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to open staging file")
+	}
+	if err := staging.Truncate(int64(ru.persist.StagedBytes)); err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "unable to truncate staging file"), staging.Close())
+	}
+	return staging, nil
+}
+
+// deleteFiles deletes the persisted state and the staging file of the upload.
+func (ru *resumableUpload) deleteFiles() error {
+	err1 := os.Remove(ru.staticMetadataPath)
+	if os.IsNotExist(err1) {
+		err1 = nil
+	}
+	err2 := os.Remove(ru.staticStagingPath)
+	if os.IsNotExist(err2) {
+		err2 = nil
+	}
+	return errors.Compose(err1, err2)
+}
+
+// CreateResumableUpload prepares a siafile for a resumable upload and returns
+// the information required to write data to it.
+func (r *Renter) CreateResumableUpload(up modules.FileUploadParams) (modules.ResumableUploadInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	defer r.tg.Done()
+	if up.Repair {
+		return modules.ResumableUploadInfo{}, errors.New("resumable uploads don't support repairs")
+	}
+	if err := checkUserSiaPath(up.SiaPath); err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+
+	// Create the siafile.
+	fileNode, err := r.managedInitUploadStream(up)
+	if err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	chunkSize := fileNode.ChunkSize()
+	if err := fileNode.Close(); err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+
+	// Create and persist the upload.
+	id := modules.UploadID(hex.EncodeToString(fastrand.Bytes(16)))
+	ru := r.staticResumableUploads.newUpload(id)
+	ru.persist.SiaPath = up.SiaPath
+	ru.persist.ChunkSize = chunkSize
+	ru.persist.LastWrite = time.Now()
+	if err := ru.saveSync(); err != nil {
+		return modules.ResumableUploadInfo{}, errors.AddContext(err, "unable to persist resumable upload")
+	}
+	r.staticResumableUploads.callAdd(ru)
+	return ru.info(), nil
+}
+
+// ResumableUpload returns the current state of a resumable upload.
+func (r *Renter) ResumableUpload(id modules.UploadID) (modules.ResumableUploadInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	defer r.tg.Done()
+	ru, err := r.staticResumableUploads.callUpload(id)
+	if err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	ru.mu.Lock()
+	defer ru.mu.Unlock()
+	return ru.info(), nil
+}
+
+// ResumableUploadWrite writes the data read from the reader to a resumable
+// upload, starting at offset. If offset is lower than the committed offset,
+// the bytes which are already committed are skipped. Every full chunk is
+// uploaded and waited on before the next one is read. Even if reading from the
+// reader fails, all data read up to that point is committed.
+func (r *Renter) ResumableUploadWrite(id modules.UploadID, offset uint64, reader io.Reader) (_ modules.ResumableUploadInfo, err error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	defer r.tg.Done()
+	ru, err := r.staticResumableUploads.callUpload(id)
+	if err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	ru.mu.Lock()
+	defer ru.mu.Unlock()
+	ru.persist.LastWrite = time.Now()
+
+	// Check the offset and skip the bytes that were already committed.
+	info := ru.info()
+	if offset > info.Offset {
+		return info, errors.AddContext(ErrResumableUploadOffsetMismatch, fmt.Sprintf("expected offset %v but got %v", info.Offset, offset))
+	}
+	_, err = io.CopyN(ioutil.Discard, reader, int64(info.Offset-offset))
+	if errors.Contains(err, io.EOF) {
+		// All of the data was committed before.
+		return info, nil
+	} else if err != nil {
+		return info, errors.AddContext(err, "failed to skip committed data")
+	}
+
+	// Open the staging file.
+	staging, err := ru.openStaging()
+	if err != nil {
+		return info, err
+	}
+	defer func() {
+		err = errors.Compose(err, staging.Close())
+	}()
+
+	chunkSize := ru.persist.ChunkSize
+	for {
+		// Upload a full chunk and reset the staging file. The chunk might
+		// have been staged by a previous write which was interrupted before
+		// the chunk was committed.
+		if ru.persist.StagedBytes == chunkSize {
+			if err := r.managedCommitResumableChunk(ru, staging); err != nil {
+				return ru.info(), err
+			}
+		}
+
+		// Fill the staging file up to a full chunk.
+		n, readErr := io.CopyN(staging, reader, int64(chunkSize-ru.persist.StagedBytes))
+		if n > 0 {
+			if err := staging.Sync(); err != nil {
+				return ru.info(), errors.AddContext(err, "unable to sync staging file")
+			}
+			ru.persist.StagedBytes += uint64(n)
+			if err := ru.saveSync(); err != nil {
+				ru.persist.StagedBytes -= uint64(n)
+				return ru.info(), errors.AddContext(err, "unable to persist resumable upload")
+			}
+		}
+		if errors.Contains(readErr, io.EOF) {
+			break
+		} else if readErr != nil {
+			// The data read so far is committed, return the updated
+			// info alongside the error.
+			return ru.info(), errors.AddContext(readErr, "failed to read upload data")
+		}
+	}
+	// Don't leave a full chunk staged.
+	if ru.persist.StagedBytes == chunkSize {
+		if err := r.managedCommitResumableChunk(ru, staging); err != nil {
+			return ru.info(), err
+		}
+	}
+	return ru.info(), nil
+}
+
+// FinalizeResumableUpload uploads any remaining staged data of a resumable
+// upload and completes it.
+func (r *Renter) FinalizeResumableUpload(id modules.UploadID) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	ru, err := r.staticResumableUploads.callUpload(id)
+	if err != nil {
+		return err
+	}
+	ru.mu.Lock()
+	defer ru.mu.Unlock()
+
+	// Upload the staged data as the last chunk.
+	if ru.persist.StagedBytes > 0 {
+		staging, err := ru.openStaging()
+		if err != nil {
+			return err
+		}
+		err = r.managedCommitResumableChunk(ru, staging)
+		err = errors.Compose(err, staging.Close())
+		if err != nil {
+			return errors.AddContext(err, "unable to upload last chunk")
+		}
+	}
+
+	// Remove the upload.
+	r.staticResumableUploads.callRemove(id)
+	return ru.deleteFiles()
+}
+
+// managedCommitResumableChunk uploads the staged data as the next chunk of the
+// upload. Once the chunk is available, the committed chunks and staged bytes
+// are updated in a single metadata update before the staging file is
+// truncated.
+func (r *Renter) managedCommitResumableChunk(ru *resumableUpload, staging *os.File) error {
+	if err := staging.Sync(); err != nil {
+		return errors.AddContext(err, "unable to sync staging file")
+	}
+	if err := r.managedUploadResumableChunk(ru, ru.persist.CommittedChunks); err != nil {
+		return errors.AddContext(err, "unable to upload chunk")
+	}
+	ru.persist.CommittedChunks++
+	staged := ru.persist.StagedBytes
+	ru.persist.StagedBytes = 0
+	if err := ru.saveSync(); err != nil {
+		ru.persist.CommittedChunks--
+		ru.persist.StagedBytes = staged
+		return errors.AddContext(err, "unable to persist resumable upload")
+	}
+	// A crash before truncating is fine since the staging file is truncated
+	// to the persisted staged bytes when it is opened again.
+	if err := staging.Truncate(0); err != nil {
+		return errors.AddContext(err, "unable to truncate staging file")
+	}
+	return nil
+}
+
+// AbortResumableUpload cancels a resumable upload and deletes its siafile.
+func (r *Renter) AbortResumableUpload(id modules.UploadID) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	ru, err := r.staticResumableUploads.callUpload(id)
+	if err != nil {
+		return err
+	}
+	ru.mu.Lock()
+	defer ru.mu.Unlock()
+	return r.abortResumableUpload(ru)
+}
+
+// abortResumableUpload removes a resumable upload from the manager and
+// deletes its siafile and files. The upload's mutex needs to be held.
+func (r *Renter) abortResumableUpload(ru *resumableUpload) error {
+	r.staticResumableUploads.callRemove(ru.persist.ID)
+	err := r.DeleteFile(ru.persist.SiaPath)
+	if errors.Contains(err, filesystem.ErrNotExist) {
+		err = nil
+	}
+	return errors.Compose(err, ru.deleteFiles())
+}
+
+// managedPruneResumableUploads aborts all resumable uploads which weren't
+// written to within resumableUploadTimeout.
+func (r *Renter) managedPruneResumableUploads() {
+	for _, ru := range r.staticResumableUploads.callUploads() {
+		ru.mu.Lock()
+		// Make sure the upload wasn't finalized or aborted while waiting
+		// for the lock.
+		current, err := r.staticResumableUploads.callUpload(ru.persist.ID)
+		if err == nil && current == ru && time.Since(ru.persist.LastWrite) > resumableUploadTimeout {
+			err = r.abortResumableUpload(ru)
+			if err != nil {
+				r.log.Printf("WARN: failed to abort abandoned resumable upload %v: %v", ru.persist.ID, err)
+			}
+		}
+		ru.mu.Unlock()
+	}
+}
+
+// threadedPruneResumableUploads periodically aborts abandoned resumable
+// uploads.
+func (r *Renter) threadedPruneResumableUploads() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(resumableUploadPruneInterval):
+		}
+		r.managedPruneResumableUploads()
+	}
+}
+
+// managedUploadResumableChunk uploads the chunk with the given index using the
+// upload's staging file as the source and blocks until the chunk is available
+// on the network.
+func (r *Renter) managedUploadResumableChunk(ru *resumableUpload, chunkIndex uint64) (err error) {
+	fileNode, err := r.staticFileSystem.OpenSiaFile(ru.persist.SiaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open siafile")
+	}
+	defer func() {
+		err = errors.Compose(err, fileNode.Close())
+	}()
+
+	// Grow the SiaFile to the right size. If the siafile already contains the
+	// chunk, this is a no-op.
+	if err := fileNode.SiaFile.GrowNumChunks(chunkIndex + 1); err != nil {
+		return err
+	}
+
+	// Build the chunk.
+	pks := make(map[string]types.SiaPublicKey)
+	for _, pk := range fileNode.HostPublicKeys() {
+		pks[string(pk.Key)] = pk
+	}
+	hosts := r.managedRefreshHostsAndWorkers()
+	offline, goodForRenew, _ := r.managedContractUtilityMaps()
+	uuc, err := r.managedBuildUnfinishedChunk(fileNode, chunkIndex, hosts, pks, memoryPriorityHigh, offline, goodForRenew, r.userUploadMemoryManager)
+	if err != nil {
+		return errors.AddContext(err, "unable to build chunk")
+	}
+	if uuc.piecesCompleted >= uuc.staticPiecesNeeded {
+		// The chunk was already uploaded before the renter was able to
+		// persist the committed chunks.
+		return nil
+	}
+
+	// Set the staging file as the source of the chunk.
+	source, err := os.Open(ru.staticStagingPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open staging file")
+	}
+	defer func() {
+		err = errors.Compose(err, source.Close())
+	}()
+	ss := NewStreamShard(source, nil)
+	uuc.sourceReader = ss
+
+	// Push the chunk and wait for it to become available.
+	pushed, err := r.managedPushChunkForRepair(uuc, chunkTypeStreamChunk)
+	if err != nil {
+		return errors.AddContext(err, "unable to push chunk")
+	}
+	if !pushed {
+		return errors.New("chunk is already being repaired, try again later")
+	}
+	select {
+	case <-r.tg.StopChan():
+		return errors.New("interrupted by shutdown")
+	case <-uuc.staticAvailableChan:
+	}
+	uuc.mu.Lock()
+	err = uuc.err
+	uuc.mu.Unlock()
+	return err
+}
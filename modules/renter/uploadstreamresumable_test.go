@@ -0,0 +1,149 @@
+package renter
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestResumableUploadManagerPersist checks that the resumable upload manager
+// loads previously persisted uploads, that the committed offset includes the
+// staged data and that unacknowledged data in the staging file is discarded.
+func TestResumableUploadManagerPersist(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	rum, err := newResumableUploadManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add an upload with a committed chunk and some staged data. The staging
+	// file contains 5 more bytes which were never acknowledged.
+	ru := rum.newUpload("foo")
+	ru.persist.SiaPath = modules.RandomSiaPath()
+	ru.persist.ChunkSize = 100
+	ru.persist.CommittedChunks = 2
+	ru.persist.StagedBytes = 10
+	if err := ru.saveSync(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ru.staticStagingPath, fastrand.Bytes(15), modules.DefaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	rum.callAdd(ru)
+
+	// Reload the manager.
+	rum, err = newResumableUploadManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ru, err = rum.callUpload("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := ru.info()
+	if info.Offset != 210 {
+		t.Fatal("wrong offset", info.Offset)
+	}
+	if info.ChunkSize != 100 || !info.SiaPath.Equals(ru.persist.SiaPath) {
+		t.Fatal("wrong info", info)
+	}
+
+	// Opening the staging file discards the unacknowledged data.
+	staging, err := ru.openStaging()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := staging.Stat()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := staging.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if fi.Size() != 10 {
+		t.Fatal("expected 10 staged bytes but got", fi.Size())
+	}
+
+	// Delete the upload's files. Reloading the manager shouldn't find the
+	// upload anymore.
+	if err := ru.deleteFiles(); err != nil {
+		t.Fatal(err)
+	}
+	rum, err = newResumableUploadManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rum.callUpload("foo"); err != ErrResumableUploadNotFound {
+		t.Fatal("expected upload to be gone", err)
+	}
+}
+
+// TestPruneResumableUploads checks that resumable uploads which weren't
+// written to within the timeout are aborted while other uploads are kept.
+func TestPruneResumableUploads(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// Create an abandoned upload and an active one.
+	abandoned, err := r.CreateResumableUpload(modules.FileUploadParams{SiaPath: modules.RandomSiaPath(), CipherType: crypto.TypeDefaultRenter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, err := r.CreateResumableUpload(modules.FileUploadParams{SiaPath: modules.RandomSiaPath(), CipherType: crypto.TypeDefaultRenter})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ru, err := r.staticResumableUploads.callUpload(abandoned.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ru.mu.Lock()
+	ru.persist.LastWrite = time.Now().Add(-resumableUploadTimeout - time.Second)
+	ru.mu.Unlock()
+
+	// Prune the uploads. Only the abandoned upload and its files should be
+	// gone.
+	r.managedPruneResumableUploads()
+	if _, err := r.ResumableUpload(abandoned.ID); err != ErrResumableUploadNotFound {
+		t.Fatal("expected abandoned upload to be gone", err)
+	}
+	if _, err := os.Stat(ru.staticMetadataPath); !os.IsNotExist(err) {
+		t.Fatal("expected metadata to be deleted", err)
+	}
+	if _, err := r.File(abandoned.SiaPath); err == nil {
+		t.Fatal("expected siafile to be deleted")
+	}
+	if _, err := r.ResumableUpload(active.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.File(active.SiaPath); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,458 @@
+package renter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter/filesystem"
+	"go.thebigfile.com/bigd/persist"
+	"go.thebigfile.com/bigd/types"
+)
+
+// Verification Overview:
+// Verifying a file proactively checks that its data is still retrievable from
+// the hosts storing it. For every host of the file, a random sample of the
+// pieces stored on that host is picked and a random segment of each piece is
+// fetched using a read sector job. The job verifies the returned data against
+// the piece's merkle root which is stored in the siafile, so a successful read
+// proves that the host still stores the correct data. The outcome of every
+// verified sample is folded into a per-host trust score which is persisted
+// across restarts and returned by HostTrustScores. Pieces for which the host
+// returned data that doesn't match the merkle root or which the host no longer
+// stores are reported as corrupt and, if requested, removed from the siafile
+// which lowers the file's health and causes the repair loop to replace them.
+// Pieces which couldn't be read for other reasons, e.g. because the host is
+// offline, the worker is on cooldown or the read timed out, are only reported
+// as unverified since the failure might be temporary.
+
+const (
+	// verificationPersistFile is the name of the file within the renter's
+	// persist directory which holds the hosts' trust scores.
+	verificationPersistFile = "verification.json"
+
+	// verificationTrustDecay is the weight of a host's previous trust score
+	// when the outcome of a new sample is added to it.
+	verificationTrustDecay = 0.9
+)
+
+var (
+	// verificationSampleTimeout is the amount of time a single sample may
+	// take before it is considered a failure.
+	verificationSampleTimeout = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 2 * time.Minute,
+		Testnet:  2 * time.Minute,
+		Testing:  30 * time.Second,
+	}).(time.Duration)
+
+	// verificationMetadata is the metadata of the persisted trust scores.
+	verificationMetadata = persist.Metadata{
+		Header:  "Renter Verification",
+		Version: "1.0",
+	}
+
+	// errVerificationNoSamples is returned if a verification is started with
+	// zero samples per host.
+	errVerificationNoSamples = errors.New("number of samples per host must be greater than zero")
+)
+
+type (
+	// hostTrustScores keeps track of the trust scores of the hosts that were
+	// sampled during verifications.
+	hostTrustScores struct {
+		scores map[string]*hostTrustScore
+
+		staticPath string
+		mu         sync.Mutex
+	}
+
+	// hostTrustScore is the persisted trust score of a single host.
+	hostTrustScore struct {
+		HostPublicKey types.SiaPublicKey `json:"hostpublickey"`
+		Score         float64            `json:"score"`
+		Samples       uint64             `json:"samples"`
+		Failures      uint64             `json:"failures"`
+	}
+
+	// verificationSample is a single piece of a file which is sampled from a
+	// host.
+	verificationSample struct {
+		chunkIndex uint64
+		pieceIndex uint64
+		root       crypto.Hash
+	}
+)
+
+// newHostTrustScores loads the persisted trust scores from the provided
+// directory.
+func newHostTrustScores(dir string) (*hostTrustScores, error) {
+	hts := &hostTrustScores{
+		scores:     make(map[string]*hostTrustScore),
+		staticPath: filepath.Join(dir, verificationPersistFile),
+	}
+	var scores []hostTrustScore
+	err := persist.LoadJSON(verificationMetadata, &scores, hts.staticPath)
+	if os.IsNotExist(err) {
+		return hts, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load trust scores")
+	}
+	for i := range scores {
+		hts.scores[scores[i].HostPublicKey.String()] = &scores[i]
+	}
+	return hts, nil
+}
+
+// callUpdate adds the outcome of a host's verified samples to its trust score
+// and returns the updated score. Unverified samples don't affect the score.
+func (hts *hostTrustScores) callUpdate(hpk types.SiaPublicKey, samples, failures, unverified uint64) modules.HostVerification {
+	hts.mu.Lock()
+	defer hts.mu.Unlock()
+	hs, exists := hts.scores[hpk.String()]
+	if !exists {
+		hs = &hostTrustScore{
+			HostPublicKey: hpk,
+			Score:         1,
+		}
+		hts.scores[hpk.String()] = hs
+	}
+	verified := samples - unverified
+	for i := uint64(0); i < verified; i++ {
+		outcome := 1.0
+		if i < failures {
+			outcome = 0
+		}
+		hs.Score = hs.Score*verificationTrustDecay + outcome*(1-verificationTrustDecay)
+	}
+	hs.Samples += verified
+	hs.Failures += failures
+	return modules.HostVerification{
+		HostPublicKey: hpk,
+		Samples:       samples,
+		Failures:      failures,
+		Unverified:    unverified,
+		TrustScore:    hs.Score,
+	}
+}
+
+// callScores returns the trust scores of all sampled hosts sorted by their
+// public keys.
+func (hts *hostTrustScores) callScores() []modules.HostVerification {
+	hts.mu.Lock()
+	defer hts.mu.Unlock()
+	scores := make([]modules.HostVerification, 0, len(hts.scores))
+	for _, hs := range hts.scores {
+		scores = append(scores, modules.HostVerification{
+			HostPublicKey: hs.HostPublicKey,
+			Samples:       hs.Samples,
+			Failures:      hs.Failures,
+			TrustScore:    hs.Score,
+		})
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		return scores[i].HostPublicKey.String() < scores[j].HostPublicKey.String()
+	})
+	return scores
+}
+
+// callSave persists the trust scores.
+func (hts *hostTrustScores) callSave() error {
+	hts.mu.Lock()
+	defer hts.mu.Unlock()
+	scores := make([]hostTrustScore, 0, len(hts.scores))
+	for _, hs := range hts.scores {
+		scores = append(scores, *hs)
+	}
+	return persist.SaveJSON(verificationMetadata, scores, hts.staticPath)
+}
+
+// HostTrustScores returns the trust scores of all hosts that were sampled by
+// previous verifications.
+func (r *Renter) HostTrustScores() ([]modules.HostVerification, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.staticTrustScores.callScores(), nil
+}
+
+// VerifyFile samples the data of the file at siaPath from every host storing
+// it and checks it against the file's merkle roots. If repair is set,
+// unreadable pieces are removed from the file to have them repaired.
+func (r *Renter) VerifyFile(siaPath modules.SiaPath, samples uint64, repair bool) (modules.VerificationReport, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.VerificationReport{}, err
+	}
+	defer r.tg.Done()
+	if samples == 0 {
+		return modules.VerificationReport{}, errVerificationNoSamples
+	}
+	report := newVerificationReport()
+	err := r.managedVerifyFile(siaPath, samples, repair, report)
+	if err != nil {
+		return modules.VerificationReport{}, err
+	}
+	return report.finalize(), r.staticTrustScores.callSave()
+}
+
+// VerifyDir verifies all the files within the directory at siaPath and its
+// subdirectories. The method continues after a file fails to be verified and
+// returns the composed errors together with the report at the end.
+func (r *Renter) VerifyDir(siaPath modules.SiaPath, samples uint64, repair bool) (modules.VerificationReport, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.VerificationReport{}, err
+	}
+	defer r.tg.Done()
+	if samples == 0 {
+		return modules.VerificationReport{}, errVerificationNoSamples
+	}
+
+	// Collect the files first to avoid holding on to the directory while the
+	// files are verified.
+	var mu sync.Mutex
+	var siaPaths []modules.SiaPath
+	err := r.staticFileSystem.CachedList(siaPath, true, func(fi modules.FileInfo) {
+		mu.Lock()
+		siaPaths = append(siaPaths, fi.SiaPath)
+		mu.Unlock()
+	}, func(modules.DirectoryInfo) {})
+	if err != nil {
+		return modules.VerificationReport{}, errors.AddContext(err, "unable to list files for verification")
+	}
+
+	report := newVerificationReport()
+	var errs error
+	for _, sp := range siaPaths {
+		// Skip the temporary files of re-encodings.
+		if isReservedSiaPath(sp) {
+			continue
+		}
+		select {
+		case <-r.tg.StopChan():
+			return report.finalize(), errors.Compose(errs, errors.New("verification interrupted by shutdown"))
+		default:
+		}
+		err := r.managedVerifyFile(sp, samples, repair, report)
+		if err != nil {
+			errs = errors.Compose(errs, errors.AddContext(err, fmt.Sprintf("failed to verify '%v'", sp)))
+		}
+	}
+	return report.finalize(), errors.Compose(errs, r.staticTrustScores.callSave())
+}
+
+// managedVerifyFile samples the pieces of a single file and adds the results
+// to the report.
+func (r *Renter) managedVerifyFile(siaPath modules.SiaPath, samples uint64, repair bool, report *verificationReport) error {
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open siafile")
+	}
+	defer func() {
+		_ = entry.Close()
+	}()
+	snap, err := entry.Snapshot(siaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to create snapshot of siafile")
+	}
+
+	// Group the pieces of the file by host.
+	piecesByHost := make(map[string][]verificationSample)
+	hostKeys := make(map[string]types.SiaPublicKey)
+	for chunkIndex := uint64(0); chunkIndex < snap.NumChunks(); chunkIndex++ {
+		for pieceIndex, pieceSet := range snap.Pieces(chunkIndex) {
+			for _, piece := range pieceSet {
+				hpk := piece.HostPubKey.String()
+				hostKeys[hpk] = piece.HostPubKey
+				piecesByHost[hpk] = append(piecesByHost[hpk], verificationSample{
+					chunkIndex: chunkIndex,
+					pieceIndex: uint64(pieceIndex),
+					root:       piece.MerkleRoot,
+				})
+			}
+		}
+	}
+
+	// Sample the pieces of every host in parallel.
+	var wg sync.WaitGroup
+	for hpk, pieces := range piecesByHost {
+		n := int(samples)
+		if n > len(pieces) {
+			n = len(pieces)
+		}
+		sampled := make([]verificationSample, 0, n)
+		for _, i := range fastrand.Perm(len(pieces))[:n] {
+			sampled = append(sampled, pieces[i])
+		}
+		wg.Add(1)
+		go func(hostKey types.SiaPublicKey, sampled []verificationSample) {
+			defer wg.Done()
+			r.managedVerifyHostSamples(entry, siaPath, hostKey, sampled, repair, report)
+		}(hostKeys[hpk], sampled)
+	}
+	wg.Wait()
+	report.addFile()
+
+	// Queue a bubble to update the health of the file if pieces were removed.
+	if repair {
+		dirSiaPath, err := siaPath.Dir()
+		if err != nil {
+			return err
+		}
+		_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	}
+	return nil
+}
+
+// isVerificationFailure returns whether the error of a sampled read proves that
+// the host lost or corrupted the piece. Errors of discarded jobs are never
+// failures since the job might have been discarded because of another job.
+func isVerificationFailure(err error) bool {
+	if err == nil || errors.Contains(err, ErrJobDiscarded) {
+		return false
+	}
+	return errors.Contains(err, errProofVerificationFailed) || modules.IsSectorNotFoundErr(err)
+}
+
+// managedVerifyHostSamples fetches a random segment of each of the sampled
+// pieces from the host and updates the host's trust score. Only pieces for
+// which the host returned data that doesn't match the merkle root or which the
+// host no longer stores are removed.
+func (r *Renter) managedVerifyHostSamples(entry *filesystem.FileNode, siaPath modules.SiaPath, hpk types.SiaPublicKey, sampled []verificationSample, repair bool, report *verificationReport) {
+	// If there is no worker for the host, all pieces are unverified. This
+	// doesn't affect the host's trust score since the host might just be
+	// offline which is already accounted for by the file's health.
+	w, err := r.staticWorkerPool.callWorker(hpk)
+	if err != nil {
+		for _, s := range sampled {
+			report.addUnverified(siaPath, hpk, s, err)
+		}
+		return
+	}
+
+	var failures, unverified uint64
+	for _, s := range sampled {
+		offset := uint64(fastrand.Intn(int(modules.SectorSize/crypto.SegmentSize))) * crypto.SegmentSize
+		ctx, cancel := context.WithTimeout(r.tg.StopCtx(), verificationSampleTimeout)
+		_, err := w.ReadSector(ctx, categoryDownload, s.root, offset, crypto.SegmentSize)
+		cancel()
+		if err == nil {
+			continue
+		}
+		if !isVerificationFailure(err) {
+			unverified++
+			report.addUnverified(siaPath, hpk, s, err)
+			continue
+		}
+		failures++
+		report.addCorrupt(siaPath, hpk, s, err)
+		if !repair {
+			continue
+		}
+		err = entry.RemovePiece(hpk, s.chunkIndex, s.pieceIndex, s.root)
+		if err != nil {
+			r.log.Printf("WARN: failed to remove corrupt piece of '%v': %v", siaPath, err)
+			continue
+		}
+		report.addRemoved()
+	}
+	report.addHost(r.staticTrustScores.callUpdate(hpk, uint64(len(sampled)), failures, unverified))
+}
+
+// verificationReport is a thread-safe wrapper around a
+// modules.VerificationReport which is filled in by the verification
+// goroutines.
+type verificationReport struct {
+	hosts  map[string]*modules.HostVerification
+	report modules.VerificationReport
+	mu     sync.Mutex
+}
+
+// newVerificationReport creates a new, empty verificationReport.
+func newVerificationReport() *verificationReport {
+	return &verificationReport{
+		hosts: make(map[string]*modules.HostVerification),
+	}
+}
+
+// addFile increments the number of verified files.
+func (vr *verificationReport) addFile() {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.report.FilesVerified++
+}
+
+// addHost adds the outcome of sampling a host to the report.
+func (vr *verificationReport) addHost(hv modules.HostVerification) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	existing, exists := vr.hosts[hv.HostPublicKey.String()]
+	if !exists {
+		vr.hosts[hv.HostPublicKey.String()] = &hv
+		return
+	}
+	existing.Samples += hv.Samples
+	existing.Failures += hv.Failures
+	existing.Unverified += hv.Unverified
+	existing.TrustScore = hv.TrustScore
+}
+
+// addRemoved increments the number of removed pieces.
+func (vr *verificationReport) addRemoved() {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.report.PiecesRemoved++
+}
+
+// addCorrupt adds a piece for which the host returned corrupt data to the
+// report.
+func (vr *verificationReport) addCorrupt(siaPath modules.SiaPath, hpk types.SiaPublicKey, s verificationSample, err error) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.report.CorruptPieces = append(vr.report.CorruptPieces, newUnreadablePiece(siaPath, hpk, s, err))
+}
+
+// addUnverified adds a piece which couldn't be read to the report.
+func (vr *verificationReport) addUnverified(siaPath modules.SiaPath, hpk types.SiaPublicKey, s verificationSample, err error) {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	vr.report.UnverifiedPieces = append(vr.report.UnverifiedPieces, newUnreadablePiece(siaPath, hpk, s, err))
+}
+
+// newUnreadablePiece creates the report entry of a sampled piece.
+func newUnreadablePiece(siaPath modules.SiaPath, hpk types.SiaPublicKey, s verificationSample, err error) modules.UnreadablePiece {
+	return modules.UnreadablePiece{
+		SiaPath:       siaPath,
+		ChunkIndex:    s.chunkIndex,
+		PieceIndex:    s.pieceIndex,
+		HostPublicKey: hpk,
+		Error:         err.Error(),
+	}
+}
+
+// finalize returns the final report with the hosts sorted by their public
+// keys.
+func (vr *verificationReport) finalize() modules.VerificationReport {
+	vr.mu.Lock()
+	defer vr.mu.Unlock()
+	report := vr.report
+	report.Hosts = make([]modules.HostVerification, 0, len(vr.hosts))
+	for _, hv := range vr.hosts {
+		report.Hosts = append(report.Hosts, *hv)
+	}
+	sort.Slice(report.Hosts, func(i, j int) bool {
+		return report.Hosts[i].HostPublicKey.String() < report.Hosts[j].HostPublicKey.String()
+	})
+	return report
+}
@@ -0,0 +1,58 @@
+package renter
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestIsVerificationFailure checks which errors of sampled reads count as a
+// failure of the host.
+func TestIsVerificationFailure(t *testing.T) {
+	// The host's errors are received as strings over the network.
+	sectorNotFound := errors.New(modules.ErrSectorNotFound.Error())
+	tests := []struct {
+		err     error
+		failure bool
+	}{
+		{nil, false},
+		{errors.AddContext(errProofVerificationFailed, "jobReadSector: failed to verify proof"), true},
+		{errors.AddContext(sectorNotFound, "managedRead: program execution was interrupted"), true},
+		{errors.New("Read interrupted"), false},
+		{errors.New("worker unavailable"), false},
+		{errors.Extend(errors.AddContext(sectorNotFound, "discarding all jobs in this queue and going on cooldown"), ErrJobDiscarded), false},
+	}
+	for _, test := range tests {
+		if isVerificationFailure(test.err) != test.failure {
+			t.Errorf("%v: expected failure to be %v", test.err, test.failure)
+		}
+	}
+}
+
+// TestHostTrustScores checks that the trust scores only count verified samples
+// and are returned sorted by the hosts' public keys.
+func TestHostTrustScores(t *testing.T) {
+	hts := &hostTrustScores{scores: make(map[string]*hostTrustScore)}
+	hpk1 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	hpk2 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	hts.callUpdate(hpk1, 3, 1, 1)
+	hts.callUpdate(hpk1, 2, 0, 0)
+	hts.callUpdate(hpk2, 2, 0, 2)
+
+	scores := hts.callScores()
+	if len(scores) != 2 {
+		t.Fatal("expected 2 scores but got", len(scores))
+	}
+	if !scores[0].HostPublicKey.Equals(hpk2) || !scores[1].HostPublicKey.Equals(hpk1) {
+		t.Fatal("scores aren't sorted", scores)
+	}
+	if scores[0].Samples != 0 || scores[0].Failures != 0 || scores[0].TrustScore != 1 {
+		t.Fatal("unverified samples shouldn't affect the score", scores[0])
+	}
+	if scores[1].Samples != 4 || scores[1].Failures != 1 || scores[1].TrustScore >= 1 {
+		t.Fatal("unexpected score", scores[1])
+	}
+}
@@ -116,6 +116,14 @@ type (
 		// registry entries.
 		staticRegistryCache *registryRevisionCache
 
+		// staticThroughput accumulates the throughput measurements of the
+		// worker until they are flushed to the hostdb.
+		staticThroughput *workerThroughput
+
+		// staticSpending tracks the money the worker spends from its account
+		// until the download spending is flushed to the contractor.
+		staticSpending *workerSpending
+
 		// staticSetInitialEstimates is an object that ensures the initial queue
 		// estimates of the HS and RJ queues are only set once.
 		staticSetInitialEstimates sync.Once
@@ -237,6 +245,8 @@ func (r *Renter) newWorker(hostPubKey types.SiaPublicKey) (*worker, error) {
 		staticBalanceTarget: balanceTarget,
 
 		staticRegistryCache: newRegistryCache(registryCacheSize),
+		staticThroughput:    new(workerThroughput),
+		staticSpending:      new(workerSpending),
 
 		staticSubscriptionInfo: &subscriptionInfos{
 			subscriptions:  make(map[modules.RegistryEntryID]*subscription),
@@ -606,6 +606,12 @@ func (w *worker) managedNeedsToRefillAccount() bool {
 	if !w.staticPriceTable().staticValid() {
 		return false
 	}
+	// Once the account funding spending cap is reached, the account is only
+	// refilled while the worker is needed for repairs. User downloads are
+	// blocked by the cap, so the refills are reserved for repairs.
+	if w.staticCache().staticSpendingCaps.FundAccountCapReached && !w.staticSpending.callRepairsPending() {
+		return false
+	}
 
 	return w.staticAccount.managedNeedsToRefill(w.staticBalanceTarget.Div64(2))
 }
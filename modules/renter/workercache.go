@@ -30,8 +30,11 @@ type (
 		staticContractID      types.FileContractID
 		staticContractUtility modules.ContractUtility
 		staticHostVersion     string
+		staticHostLocation    modules.HostLocation
+		staticHostLocated     bool
 		staticRenterAllowance modules.Allowance
 		staticHostMuxAddress  string
+		staticSpendingCaps    modules.SpendingCapStatus
 		staticSynced          bool
 
 		staticLastUpdate time.Time
@@ -52,6 +55,12 @@ func (w *worker) managedUpdateCache() {
 		return
 	}
 
+	// Flush the throughput measurements to the hostdb before fetching the
+	// host and the account download spending to the contractor before
+	// fetching the spending cap status.
+	w.managedFlushThroughput()
+	w.managedFlushSpending()
+
 	// Grab the host to check the version.
 	host, ok, err := w.renter.hostDB.Host(w.staticHostPubKey)
 	if !ok || err != nil {
@@ -70,14 +79,24 @@ func (w *worker) managedUpdateCache() {
 		return
 	}
 
+	// Grab the location of the host. The location is only known if the host
+	// selection policy has a location database.
+	location, located, err := w.renter.hostDB.HostLocation(w.staticHostPubKey)
+	if err != nil {
+		w.renter.log.Debugf("Worker %v could not get the host location: %v", w.staticHostPubKeyStr, err)
+	}
+
 	// Create the cache object.
 	newCache := &workerCache{
 		staticBlockHeight:     w.renter.cs.Height(),
 		staticContractID:      renterContract.ID,
 		staticContractUtility: renterContract.Utility,
 		staticHostMuxAddress:  host.SiaMuxAddress(),
+		staticHostLocation:    location,
+		staticHostLocated:     located,
 		staticHostVersion:     host.Version,
 		staticRenterAllowance: w.renter.hostContractor.Allowance(),
+		staticSpendingCaps:    w.renter.hostContractor.SpendingCapStatus(),
 		staticSynced:          w.renter.cs.Synced(),
 
 		staticLastUpdate: time.Now(),
@@ -132,3 +151,17 @@ func (w *worker) staticCache() *workerCache {
 	ptr := atomic.LoadPointer(&w.atomicCache)
 	return (*workerCache)(ptr)
 }
+
+// staticDownloadCapReached returns whether the download or account funding
+// spending caps block downloads of the provided spending category. Repair
+// downloads are exempt so that user downloads can't starve repairs.
+func (wc *workerCache) staticDownloadCapReached(category spendingCategory) bool {
+	return category == categoryDownload && (wc.staticSpendingCaps.DownloadCapReached || wc.staticSpendingCaps.FundAccountCapReached)
+}
+
+// staticUploadCapReached returns whether the upload or storage spending caps
+// block uploading a chunk. Repairs are exempt so that the redundancy of
+// existing files can be maintained once the caps are reached.
+func (wc *workerCache) staticUploadCapReached(repair bool) bool {
+	return !repair && (wc.staticSpendingCaps.UploadCapReached || wc.staticSpendingCaps.StorageCapReached)
+}
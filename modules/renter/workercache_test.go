@@ -0,0 +1,55 @@
+package renter
+
+import (
+	"testing"
+
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestWorkerCacheSpendingCaps checks that the spending caps only block new
+// uploads and user downloads but not repairs.
+func TestWorkerCacheSpendingCaps(t *testing.T) {
+	var wc workerCache
+	if wc.staticUploadCapReached(false) || wc.staticUploadCapReached(true) {
+		t.Fatal("uploads shouldn't be blocked without reached caps")
+	}
+	if wc.staticDownloadCapReached(categoryDownload) || wc.staticDownloadCapReached(categoryRepairDownload) {
+		t.Fatal("downloads shouldn't be blocked without reached caps")
+	}
+
+	// Both the upload and the storage cap block new uploads but not repairs.
+	for _, caps := range []modules.SpendingCapStatus{{UploadCapReached: true}, {StorageCapReached: true}} {
+		wc.staticSpendingCaps = caps
+		if !wc.staticUploadCapReached(false) {
+			t.Fatal("new uploads should be blocked", caps)
+		}
+		if wc.staticUploadCapReached(true) {
+			t.Fatal("repairs shouldn't be blocked", caps)
+		}
+	}
+
+	// The download cap blocks user downloads but not repair downloads.
+	wc.staticSpendingCaps = modules.SpendingCapStatus{DownloadCapReached: true}
+	if !wc.staticDownloadCapReached(categoryDownload) {
+		t.Fatal("user downloads should be blocked")
+	}
+	if wc.staticDownloadCapReached(categoryRepairDownload) {
+		t.Fatal("repair downloads shouldn't be blocked")
+	}
+	if wc.staticUploadCapReached(false) {
+		t.Fatal("the download cap shouldn't block uploads")
+	}
+}
+
+// TestWorkerCacheFundAccountCap checks that the account funding cap blocks
+// user downloads but not repair downloads.
+func TestWorkerCacheFundAccountCap(t *testing.T) {
+	var wc workerCache
+	wc.staticSpendingCaps = modules.SpendingCapStatus{FundAccountCapReached: true}
+	if !wc.staticDownloadCapReached(categoryDownload) {
+		t.Fatal("user downloads should be blocked")
+	}
+	if wc.staticDownloadCapReached(categoryRepairDownload) {
+		t.Fatal("repair downloads shouldn't be blocked")
+	}
+}
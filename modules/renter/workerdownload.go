@@ -129,6 +129,21 @@ func (w *worker) threadedPerformDownloadChunkJob(udc *unfinishedDownloadChunk) {
 		return
 	}
 
+	// Repair downloads keep the account refilled once the account funding
+	// spending cap is reached.
+	if udc.staticSpendingCategory == categoryRepairDownload {
+		w.staticSpending.callRecordRepairDownload()
+	}
+
+	// User downloads are not performed once the download or account funding
+	// spending caps are reached. Repairs are exempt so that they can't be
+	// starved by downloads.
+	if w.staticCache().staticDownloadCapReached(udc.staticSpendingCategory) {
+		w.renter.log.Debugln("worker downloader is not being used because the download spending cap was reached")
+		udc.managedUnregisterWorker(w)
+		return
+	}
+
 	// Fetch the sector. If fetching the sector fails, the worker needs to be
 	// unregistered with the chunk.
 	fetchOffset, fetchLength := sectorOffsetAndLength(udc.staticFetchOffset, udc.staticFetchLength, udc.erasureCode)
@@ -144,6 +144,9 @@ func (j *jobRead) managedFinishExecute(readData []byte, readErr error, readJobTi
 	// failures stat can be reset.
 	jq := j.staticQueue.(*jobReadQueue)
 	jq.callUpdateJobTimeMetrics(j.staticLength, readJobTime)
+
+	// Record the throughput of the download.
+	w.staticThroughput.callRecordDownload(j.staticLength, readJobTime)
 }
 
 // callExpectedBandwidth returns the bandwidth that gets consumed by a
@@ -62,6 +62,11 @@ func (w *worker) managedExecuteProgram(p modules.Program, data []byte, fcid type
 	defer func() {
 		withdrawn := cost.Sub(refund)
 		w.staticAccount.managedCommitWithdrawal(category, withdrawn, refund, err == nil)
+		// Downloads paid for by the account count towards the download
+		// spending cap.
+		if err == nil && category == categoryDownload {
+			w.staticSpending.callRecordAccountDownload(withdrawn)
+		}
 	}()
 
 	// create a new stream
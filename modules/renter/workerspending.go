@@ -0,0 +1,74 @@
+package renter
+
+import (
+	"sync"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/types"
+)
+
+var (
+	// repairRefillWindow is the amount of time after the last repair download
+	// of a worker during which the worker keeps refilling its account although
+	// the account funding spending cap was reached.
+	repairRefillWindow = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Minute * 10,
+		Testnet:  time.Minute * 10,
+		Testing:  time.Second * 10,
+	}).(time.Duration)
+)
+
+type (
+	// workerSpending tracks the money a worker spends from its ephemeral
+	// account. Account download spending is accumulated and flushed to the
+	// contractor together with the cache update instead of locking the
+	// contractor on every program.
+	workerSpending struct {
+		accountDownload    types.Currency
+		lastRepairDownload time.Time
+		mu                 sync.Mutex
+	}
+)
+
+// callRecordAccountDownload adds money spent on a user download paid for by
+// the worker's account.
+func (ws *workerSpending) callRecordAccountDownload(amount types.Currency) {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.accountDownload = ws.accountDownload.Add(amount)
+}
+
+// callRecordRepairDownload records that the worker was asked to download a
+// piece for a repair.
+func (ws *workerSpending) callRecordRepairDownload() {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	ws.lastRepairDownload = time.Now()
+}
+
+// callRepairsPending returns whether the worker was recently needed for
+// repair downloads.
+func (ws *workerSpending) callRepairsPending() bool {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	return time.Since(ws.lastRepairDownload) < repairRefillWindow
+}
+
+// callReset returns the accumulated account download spending and resets it.
+func (ws *workerSpending) callReset() types.Currency {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	spent := ws.accountDownload
+	ws.accountDownload = types.ZeroCurrency
+	return spent
+}
+
+// managedFlushSpending records the account download spending of the worker
+// since the last flush in the contractor.
+func (w *worker) managedFlushSpending() {
+	if spent := w.staticSpending.callReset(); !spent.IsZero() {
+		w.renter.hostContractor.RecordAccountDownloadSpending(spent)
+	}
+}
@@ -0,0 +1,35 @@
+package renter
+
+import (
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/types"
+)
+
+// TestWorkerSpendingTracker checks that the worker accumulates its account download
+// spending and tracks whether it is needed for repairs.
+func TestWorkerSpendingTracker(t *testing.T) {
+	var ws workerSpending
+	ws.callRecordAccountDownload(types.NewCurrency64(2))
+	ws.callRecordAccountDownload(types.NewCurrency64(3))
+	if spent := ws.callReset(); !spent.Equals(types.NewCurrency64(5)) {
+		t.Fatal("unexpected spending", spent)
+	}
+	if spent := ws.callReset(); !spent.IsZero() {
+		t.Fatal("spending wasn't reset", spent)
+	}
+
+	// Repairs are only pending right after a repair download.
+	if ws.callRepairsPending() {
+		t.Fatal("repairs shouldn't be pending without repair downloads")
+	}
+	ws.callRecordRepairDownload()
+	if !ws.callRepairsPending() {
+		t.Fatal("repairs should be pending after a repair download")
+	}
+	ws.lastRepairDownload = time.Now().Add(-repairRefillWindow)
+	if ws.callRepairsPending() {
+		t.Fatal("repairs shouldn't be pending once the window has passed")
+	}
+}
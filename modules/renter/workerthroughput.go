@@ -0,0 +1,73 @@
+package renter
+
+import (
+	"sync"
+	"time"
+
+	"go.thebigfile.com/bigd/modules/renter/hostdb"
+)
+
+type (
+	// workerThroughput accumulates the throughput measurements of a worker's
+	// uploads and downloads. They are flushed to the hostdb together with the
+	// cache update instead of updating the host tree on every transfer.
+	workerThroughput struct {
+		uploaded     uint64
+		uploadTime   time.Duration
+		downloaded   uint64
+		downloadTime time.Duration
+		mu           sync.Mutex
+	}
+)
+
+// callRecordUpload adds an upload to the worker's throughput measurements.
+// Transfers that are too small to be a meaningful measurement are ignored.
+func (wt *workerThroughput) callRecordUpload(size uint64, elapsed time.Duration) {
+	if size < hostdb.MinThroughputSampleSize || elapsed <= 0 {
+		return
+	}
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.uploaded += size
+	wt.uploadTime += elapsed
+}
+
+// callRecordDownload adds a download to the worker's throughput
+// measurements. Transfers that are too small to be a meaningful measurement
+// are ignored.
+func (wt *workerThroughput) callRecordDownload(size uint64, elapsed time.Duration) {
+	if size < hostdb.MinThroughputSampleSize || elapsed <= 0 {
+		return
+	}
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	wt.downloaded += size
+	wt.downloadTime += elapsed
+}
+
+// callReset returns the accumulated measurements and resets them.
+func (wt *workerThroughput) callReset() (uploaded uint64, uploadTime time.Duration, downloaded uint64, downloadTime time.Duration) {
+	wt.mu.Lock()
+	defer wt.mu.Unlock()
+	uploaded, uploadTime, downloaded, downloadTime = wt.uploaded, wt.uploadTime, wt.downloaded, wt.downloadTime
+	wt.uploaded, wt.uploadTime, wt.downloaded, wt.downloadTime = 0, 0, 0, 0
+	return
+}
+
+// managedFlushThroughput records the throughput the worker measured since the
+// last flush in the hostdb.
+func (w *worker) managedFlushThroughput() {
+	uploaded, uploadTime, downloaded, downloadTime := w.staticThroughput.callReset()
+	if uploaded > 0 {
+		err := w.renter.hostDB.RecordUploadThroughput(w.staticHostPubKey, uploaded, uploadTime)
+		if err != nil {
+			w.renter.log.Debugln("managedFlushThroughput: failed to record upload throughput:", err)
+		}
+	}
+	if downloaded > 0 {
+		err := w.renter.hostDB.RecordDownloadThroughput(w.staticHostPubKey, downloaded, downloadTime)
+		if err != nil {
+			w.renter.log.Debugln("managedFlushThroughput: failed to record download throughput:", err)
+		}
+	}
+}
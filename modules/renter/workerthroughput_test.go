@@ -0,0 +1,33 @@
+package renter
+
+import (
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestWorkerThroughput checks that the worker accumulates its throughput
+// measurements and ignores small transfers.
+func TestWorkerThroughput(t *testing.T) {
+	var wt workerThroughput
+	wt.callRecordUpload(modules.SectorSize, time.Second)
+	wt.callRecordUpload(modules.SectorSize, 2*time.Second)
+	wt.callRecordDownload(modules.SectorSize, time.Second)
+	wt.callRecordDownload(1, time.Hour)
+	wt.callRecordDownload(modules.SectorSize, 0)
+
+	uploaded, uploadTime, downloaded, downloadTime := wt.callReset()
+	if uploaded != 2*modules.SectorSize || uploadTime != 3*time.Second {
+		t.Fatal("unexpected upload measurements", uploaded, uploadTime)
+	}
+	if downloaded != modules.SectorSize || downloadTime != time.Second {
+		t.Fatal("unexpected download measurements", downloaded, downloadTime)
+	}
+
+	// The measurements are reset.
+	uploaded, uploadTime, downloaded, downloadTime = wt.callReset()
+	if uploaded != 0 || uploadTime != 0 || downloaded != 0 || downloadTime != 0 {
+		t.Fatal("measurements weren't reset")
+	}
+}
@@ -122,10 +122,11 @@ func (w *worker) callQueueUploadChunk(uc *unfinishedUploadChunk) bool {
 	_, candidateHost := uc.unusedHosts[w.staticHostPubKeyStr]
 	uc.mu.Unlock()
 	goodForUpload := cache.staticContractUtility.GoodForUpload
+	capReached := cache.staticUploadCapReached(uc.staticRepair)
 	w.mu.Lock()
 	onCooldown, _ := w.onUploadCooldown()
 	uploadTerminated := w.uploadTerminated
-	if !goodForUpload || uploadTerminated || onCooldown || !candidateHost {
+	if !goodForUpload || capReached || uploadTerminated || onCooldown || !candidateHost {
 		// The worker should not be uploading, remove the chunk.
 		w.mu.Unlock()
 		w.managedDropChunk(uc)
@@ -206,7 +207,9 @@ func (w *worker) managedPerformUploadChunkJob() {
 	//
 	// Ignore the error if it's a ErrMaxVirtualSectors coming from a pre-1.5.5
 	// host.
+	uploadStart := time.Now()
 	root, err := e.Upload(uc.physicalChunkData[pieceIndex])
+	uploadTime := time.Since(uploadStart)
 	ignoreErr := build.VersionCmp(hostSettings.Version, "1.5.5") < 0 && err != nil && strings.Contains(err.Error(), modules.ErrMaxVirtualSectors.Error())
 	if err != nil && !ignoreErr {
 		failureErr := fmt.Errorf("Worker failed to upload root %v via the editor: %v", root, err)
@@ -217,6 +220,9 @@ func (w *worker) managedPerformUploadChunkJob() {
 	w.uploadConsecutiveFailures = 0
 	w.mu.Unlock()
 
+	// Record the throughput of the upload.
+	w.staticThroughput.callRecordUpload(uint64(len(uc.physicalChunkData[pieceIndex])), uploadTime)
+
 	// Add piece to renterFile
 	err = uc.fileEntry.AddPiece(w.staticHostPubKey, uc.staticIndex, pieceIndex, root)
 	if err != nil {
@@ -288,6 +294,14 @@ func (w *worker) managedProcessUploadChunk(uc *unfinishedUploadChunk) (nextChunk
 		return nil, 0
 	}
 
+	// If the worker would prevent the chunk from reaching the minimum number
+	// of piece regions, it can't help with the chunk.
+	if !uc.regionAllowed(cache.staticHostLocation, cache.staticHostLocated) {
+		uc.mu.Unlock()
+		w.managedDropChunk(uc)
+		return nil, 0
+	}
+
 	// If the chunk needs help from this worker, find a piece to upload and
 	// return the stats for that piece.
 	//
@@ -307,6 +321,7 @@ func (w *worker) managedProcessUploadChunk(uc *unfinishedUploadChunk) (nextChunk
 		return nil, 0
 	}
 	delete(uc.unusedHosts, w.staticHostPubKey.String())
+	uc.addPieceRegion(cache.staticHostLocation, cache.staticHostLocated)
 	uc.piecesRegistered++
 	uc.workersRemaining--
 	uc.mu.Unlock()
@@ -328,7 +343,9 @@ func (w *worker) managedUploadFailed(uc *unfinishedUploadChunk, pieceIndex uint6
 	}
 
 	// Unregister the piece from the chunk and hunt for a replacement.
+	cache := w.staticCache()
 	uc.mu.Lock()
+	uc.removePieceRegion(cache.staticHostLocation, cache.staticHostLocated)
 	uc.piecesRegistered--
 	uc.pieceUsage[pieceIndex] = false
 	uc.chunkFailedProcessTimes = append(uc.chunkFailedProcessTimes, time.Now())
@@ -609,3 +609,62 @@ func TestProcessUploadChunk(t *testing.T) {
 		testProcessUploadChunkNotGoodForUpload(t, chunk)
 	})
 }
+
+// TestUploadChunkRegionAllowed checks that workers are only allowed to upload
+// pieces of a chunk if the chunk can still reach its minimum number of piece
+// regions.
+func TestUploadChunkRegionAllowed(t *testing.T) {
+	t.Parallel()
+
+	de := modules.HostLocation{Region: "DE", ASN: 1}
+	nl := modules.HostLocation{Region: "NL", ASN: 2}
+	us := modules.HostLocation{Region: "US", ASN: 3}
+
+	// Without a minimum, every host is allowed.
+	uc := &unfinishedUploadChunk{staticPiecesNeeded: 3}
+	if !uc.regionAllowed(de, true) || !uc.regionAllowed(modules.HostLocation{}, false) {
+		t.Fatal("expected hosts to be allowed without minimum")
+	}
+
+	// Require 3 regions for 4 pieces.
+	uc = &unfinishedUploadChunk{
+		staticMinPieceRegions: 3,
+		staticPiecesNeeded:    4,
+		pieceRegions:          make(map[string]int),
+	}
+	uc.addPieceRegion(de, true)
+	uc.piecesRegistered++
+
+	// A second piece in DE or an unknown region uses up the last spare piece.
+	if !uc.regionAllowed(de, true) || !uc.regionAllowed(modules.HostLocation{}, false) {
+		t.Fatal("expected host to be allowed while there are spare pieces")
+	}
+	uc.addPieceRegion(de, true)
+	uc.piecesRegistered++
+
+	// Now only new regions are allowed.
+	if uc.regionAllowed(de, true) || uc.regionAllowed(modules.HostLocation{}, false) {
+		t.Fatal("expected host without new region to be rejected")
+	}
+	if !uc.regionAllowed(nl, true) {
+		t.Fatal("expected host in new region to be allowed")
+	}
+	uc.addPieceRegion(nl, true)
+	uc.piecesRegistered++
+
+	// A failed upload frees up the region again.
+	uc.removePieceRegion(nl, true)
+	uc.piecesRegistered--
+	if len(uc.pieceRegions) != 1 || !uc.regionAllowed(nl, true) || uc.regionAllowed(de, true) {
+		t.Fatal("region wasn't removed", uc.pieceRegions)
+	}
+	uc.addPieceRegion(nl, true)
+	uc.piecesRegistered++
+	uc.addPieceRegion(us, true)
+	uc.piecesRegistered++
+
+	// Once the minimum is reached, every host is allowed.
+	if !uc.regionAllowed(de, true) || len(uc.pieceRegions) != 3 {
+		t.Fatal("expected minimum to be reached", uc.pieceRegions)
+	}
+}
@@ -282,3 +282,52 @@ func BenchmarkSliceCryptoHashSave(b *testing.B) {
 		}
 	}
 }
+
+// TestBulkOperationSelects tests selecting files by glob and prefix.
+func TestBulkOperationSelects(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		op      BulkOperation
+		relPath string
+		selects bool
+	}{
+		{BulkOperation{}, "a/b.txt", true},
+		{BulkOperation{Glob: "*.txt"}, "b.txt", true},
+		{BulkOperation{Glob: "*.txt"}, "a/b.txt", false},
+		{BulkOperation{Glob: "*.txt", Recursive: true}, "a/b.txt", true},
+		{BulkOperation{Glob: "*.txt", Recursive: true}, "a/b.dat", false},
+		{BulkOperation{Glob: "a/*"}, "a/b.txt", true},
+		{BulkOperation{Prefix: "a/"}, "a/b/c.txt", true},
+		{BulkOperation{Prefix: "a/"}, "ab.txt", false},
+	}
+	for i, test := range tests {
+		if selects := test.op.Selects(test.relPath); selects != test.selects {
+			t.Errorf("%v: expected %v but got %v", i, test.selects, selects)
+		}
+	}
+}
+
+// TestBulkOperationValidate tests validating bulk operations.
+func TestBulkOperationValidate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		op    BulkOperation
+		valid bool
+	}{
+		{BulkOperation{Type: BulkOperationDelete}, true},
+		{BulkOperation{Type: BulkOperationRename, Glob: "*"}, true},
+		{BulkOperation{Type: BulkOperationSetStuck, Prefix: "a"}, true},
+		{BulkOperation{Type: BulkOperationSetLocalPath, LocalPath: "/foo"}, true},
+		{BulkOperation{Type: BulkOperationUpload, LocalPath: "foo"}, false},
+		{BulkOperation{Type: "foo"}, false},
+		{BulkOperation{Type: BulkOperationDelete, Glob: "*", Prefix: "a"}, false},
+		{BulkOperation{Type: BulkOperationDelete, Glob: "["}, false},
+	}
+	for i, test := range tests {
+		if err := test.op.Validate(); (err == nil) != test.valid {
+			t.Errorf("%v: expected valid to be %v but got %v", i, test.valid, err)
+		}
+	}
+}
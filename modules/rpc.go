@@ -140,6 +140,10 @@ type RPCPriceTable struct {
 	// SwapSectorCost is the cost of swapping 2 full sectors by root.
 	SwapSectorCost types.Currency `json:"swapsectorcost"`
 
+	// TemporaryStoreCost is the cost of storing a byte in a temporary sector
+	// for a block. It is used by the StoreSector instruction.
+	TemporaryStoreCost types.Currency `json:"temporarystorecost"`
+
 	// Cost values specific to the Write instruction.
 	WriteBaseCost   types.Currency `json:"writebasecost"`   // per write
 	WriteLengthCost types.Currency `json:"writelengthcost"` // per byte written
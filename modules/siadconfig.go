@@ -19,6 +19,10 @@ type (
 		WriteBPS           int64  `json:"writebps"`
 		PacketSize         uint64 `json:"packetsize"`
 
+		// LogLevels contains the log levels of the modules which have a level
+		// of their own and the default level.
+		LogLevels map[string]persist.LogLevel `json:"loglevels,omitempty"`
+
 		// path of config on disk.
 		path string
 		mu   sync.Mutex
@@ -59,6 +63,42 @@ func (cfg *SiadConfig) SetRatelimit(readBPS, writeBPS int64) error {
 	return cfg.save()
 }
 
+// SetLogLevels updates the log levels of the given modules and persists them
+// to disk. persist.DefaultLogModule sets the default level. An empty level
+// removes the level of a module, which then uses the default level again.
+func (cfg *SiadConfig) SetLogLevels(levels map[string]persist.LogLevel) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	// Input validation.
+	for module, level := range levels {
+		if level == "" && module == persist.DefaultLogModule {
+			return errors.New("the default log level can't be removed")
+		}
+		if level == "" {
+			continue
+		}
+		if err := level.Valid(); err != nil {
+			return err
+		}
+	}
+	applyLogLevels(levels)
+	// Persist settings.
+	cfg.LogLevels = persist.LogLevels()
+	return cfg.save()
+}
+
+// applyLogLevels sets the given log levels. Empty levels are removed.
+func applyLogLevels(levels map[string]persist.LogLevel) {
+	for module, level := range levels {
+		if level == "" {
+			persist.ClearLogLevel(module)
+			continue
+		}
+		// Invalid levels in the config are ignored.
+		_ = persist.SetLogLevel(module, level)
+	}
+}
+
 // save saves the config to disk.
 func (cfg *SiadConfig) save() error {
 	return persist.SaveJSON(configMetadata, cfg, cfg.path)
@@ -99,5 +139,7 @@ func NewConfig(path string) (*SiadConfig, error) {
 	}
 	// Init the global ratelimit.
 	GlobalRateLimits.SetLimits(cfg.ReadBPS, cfg.WriteBPS, cfg.PacketSize)
+	// Init the global log levels.
+	applyLogLevels(cfg.LogLevels)
 	return &cfg, nil
 }
@@ -55,6 +55,14 @@ var (
 
 	// UserFolder is the Sia folder that is used to store the renter's siafiles.
 	UserFolder = NewGlobalSiaPath("/home/user")
+
+	// TenantsFolder is the Sia folder that contains the root directories of
+	// the renter's tenants.
+	TenantsFolder = NewGlobalSiaPath("/home/tenants")
+
+	// ReencodeFolder is the Sia folder that holds the temporary siafiles of
+	// ongoing re-encodings. Users can't create files or folders within it.
+	ReencodeFolder = NewGlobalSiaPath("/.reencode")
 )
 
 type (
@@ -1,7 +1,11 @@
 package modules
 
 import (
+	"errors"
+	"time"
+
 	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/types"
 )
 
 const (
@@ -14,14 +18,97 @@ const (
 	StorageManagerDir = "storagemanager"
 )
 
+const (
+	// StorageFolderTierFast is the tier of storage folders on fast media such
+	// as SSDs. Frequently read sectors are moved to the fast tier.
+	StorageFolderTierFast = StorageFolderTier("fast")
+
+	// StorageFolderTierSlow is the tier of storage folders on slow media such
+	// as HDDs. It is the default tier and new sectors are stored on the slow
+	// tier if possible.
+	StorageFolderTierSlow = StorageFolderTier("slow")
+)
+
+const (
+	// StorageRedundancyNone stores a single copy of every sector.
+	StorageRedundancyNone = StorageRedundancyMode("none")
+
+	// StorageRedundancyMirror stores a second copy of every sector in a
+	// storage folder on a different device than the first copy.
+	StorageRedundancyMirror = StorageRedundancyMode("mirror")
+)
+
+const (
+	// StorageFolderOperationMigrate is the type of an operation that moves
+	// all sectors of one storage folder into another.
+	StorageFolderOperationMigrate = "migrate"
+
+	// StorageFolderOperationRebalance is the type of an operation that moves
+	// sectors between the storage folders of a tier until all of them have
+	// the same relative amount of free space.
+	StorageFolderOperationRebalance = "rebalance"
+)
+
+var (
+	// ErrNoStorageFolderOperation is returned when cancelling a storage
+	// folder operation while none is running.
+	ErrNoStorageFolderOperation = errors.New("no storage folder operation is running")
+
+	// ErrStorageFolderOperationRunning is returned when starting a storage
+	// folder operation while another one is still running.
+	ErrStorageFolderOperationRunning = errors.New("another storage folder operation is running")
+
+	// ErrUnknownStorageRedundancyMode is returned if a storage redundancy
+	// mode is neither none nor mirror.
+	ErrUnknownStorageRedundancyMode = errors.New("unknown storage redundancy mode")
+
+	// ErrMirrorRequiresTwoDevices is returned if mirroring is enabled while
+	// all storage folders are on the same device.
+	ErrMirrorRequiresTwoDevices = errors.New("mirroring requires storage folders on at least two different devices")
+
+	// ErrUnknownStorageFolderTier is returned if a storage folder tier is
+	// neither fast nor slow.
+	ErrUnknownStorageFolderTier = errors.New("unknown storage folder tier")
+)
+
 type (
+	// StorageFolderTier describes the speed of the medium backing a storage
+	// folder.
+	StorageFolderTier string
+
+	// StorageRedundancyMode describes how many copies of a sector are stored
+	// by the storage manager.
+	StorageRedundancyMode string
+
+	// StorageRedundancyStatus describes the local redundancy of the sectors
+	// stored by the storage manager.
+	StorageRedundancyStatus struct {
+		Mode StorageRedundancyMode `json:"mode"`
+
+		// Sectors is the number of distinct sectors, MirroredSectors the
+		// number of sectors that have a second copy. DegradedSectors is the
+		// number of sectors without two healthy copies on different devices
+		// while mirroring is enabled.
+		Sectors         uint64 `json:"sectors"`
+		MirroredSectors uint64 `json:"mirroredsectors"`
+		DegradedSectors uint64 `json:"degradedsectors"`
+
+		// Progress of the running or most recent rebuild.
+		Rebuilding           bool      `json:"rebuilding"`
+		RebuildSectorsTotal  uint64    `json:"rebuildsectorstotal"`
+		RebuildSectorsDone   uint64    `json:"rebuildsectorsdone"`
+		RebuildSectorsFailed uint64    `json:"rebuildsectorsfailed"`
+		LastRebuild          time.Time `json:"lastrebuild"`
+	}
+
 	// StorageFolderMetadata contains metadata about a storage folder that is
 	// tracked by the storage folder manager.
 	StorageFolderMetadata struct {
-		Capacity          uint64 `json:"capacity"`          // bytes
-		CapacityRemaining uint64 `json:"capacityremaining"` // bytes
-		Index             uint16 `json:"index"`
-		Path              string `json:"path"`
+		Capacity          uint64            `json:"capacity"`          // bytes
+		CapacityRemaining uint64            `json:"capacityremaining"` // bytes
+		Index             uint16            `json:"index"`
+		Path              string            `json:"path"`
+		Tier              StorageFolderTier `json:"tier"`
 
 		// Below are statistics about the filesystem. FailedReads and
 		// FailedWrites are only incremented if the filesystem is returning
@@ -42,6 +129,34 @@ type (
 		ProgressDenominator uint64
 	}
 
+	// StorageFolderOperation describes a long running migration or
+	// rebalancing of storage folders.
+	StorageFolderOperation struct {
+		Type string `json:"type"`
+
+		// Source and Destination are the indices of the storage folders of
+		// a migration. If RemoveSource is set, the source folder is removed
+		// after all of its sectors were migrated.
+		Source       uint16 `json:"source"`
+		Destination  uint16 `json:"destination"`
+		RemoveSource bool   `json:"removesource"`
+
+		// MaxBandwidth limits the number of bytes per second that are moved
+		// between storage folders. 0 means unlimited.
+		MaxBandwidth uint64 `json:"maxbandwidth"`
+
+		// Progress of the operation.
+		SectorsTotal  uint64 `json:"sectorstotal"`
+		SectorsMoved  uint64 `json:"sectorsmoved"`
+		SectorsFailed uint64 `json:"sectorsfailed"`
+
+		StartTime time.Time `json:"starttime"`
+		EndTime   time.Time `json:"endtime"`
+		Running   bool      `json:"running"`
+		Cancelled bool      `json:"cancelled"`
+		Error     string    `json:"error"`
+	}
+
 	// A StorageManager is responsible for managing storage folders and
 	// sectors. Sectors are the base unit of storage that gets moved between
 	// renters and hosts, and primarily is stored on the hosts.
@@ -69,12 +184,23 @@ type (
 		// successfully renewing.
 		AddSectorBatch(sectorRoots []crypto.Hash) error
 
+		// AddTemporarySector adds a sector that doesn't belong to a contract.
+		// The sector is removed by RemoveExpiredTemporarySectors once the
+		// expiry height is reached. Adding the same sector again extends its
+		// expiry.
+		AddTemporarySector(sectorRoot crypto.Hash, sectorData []byte, expiry types.BlockHeight) error
+
 		// AddStorageFolder adds a storage folder to the manager. The manager
 		// may not check that there is enough space available on-disk to
 		// support as much storage as requested, though the manager should
 		// gracefully handle running out of storage unexpectedly.
 		AddStorageFolder(path string, size uint64) error
 
+		// CancelStorageFolderOperation cancels the running storage folder
+		// operation. Sectors that were moved already stay in their new
+		// storage folder.
+		CancelStorageFolderOperation() error
+
 		// The storage manager needs to be able to shut down.
 		Close() error
 
@@ -86,6 +212,13 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// MigrateStorageFolder starts moving all sectors of the source
+		// storage folder into the destination storage folder in the
+		// background. No new sectors are added to the source folder while
+		// the migration is running. If removeSource is set, the source folder
+		// is removed once it is empty.
+		MigrateStorageFolder(source, destination uint16, removeSource bool, maxBandwidth uint64) error
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
@@ -94,6 +227,11 @@ type (
 		// returning the bytes that match the input sector root.
 		ReadPartialSector(sectorRoot crypto.Hash, offset, length uint64) ([]byte, error)
 
+		// RebalanceStorageFolders starts moving sectors between the storage
+		// folders of each tier in the background until all of them have the
+		// same relative amount of free space.
+		RebalanceStorageFolders(maxBandwidth uint64) error
+
 		// RemoveSector will remove a sector from the storage manager. The
 		// height at which the sector expires should be provided, so that the
 		// auto-expiry information for that sector can be properly updated.
@@ -105,6 +243,10 @@ type (
 		// of all at once.
 		MarkSectorsForRemoval(sectorRoots []crypto.Hash) error
 
+		// RemoveExpiredTemporarySectors removes all temporary sectors that
+		// expire at or before the provided height and returns their number.
+		RemoveExpiredTemporarySectors(height types.BlockHeight) (uint64, error)
+
 		// RemoveStorageFolder will remove a storage folder from the manager.
 		// All storage on the folder will be moved to other storage folders,
 		// meaning that no data will be lost. If the manager is unable to save
@@ -129,8 +271,46 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// SetStorageRedundancy sets the local redundancy mode of the storage
+		// manager. Missing copies are created and superfluous copies removed
+		// in the background.
+		SetStorageRedundancy(mode StorageRedundancyMode) error
+
+		// SetStorageFolderTier sets the tier of a storage folder. Sectors are
+		// moved between the tiers in the background depending on how often
+		// they are read.
+		SetStorageFolderTier(index uint16, tier StorageFolderTier) error
+
+		// StorageFolderOperation returns the running or most recently
+		// finished storage folder operation. The bool is false if no
+		// operation was started since the storage manager was opened.
+		StorageFolderOperation() (StorageFolderOperation, bool)
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
+
+		// StorageRedundancy returns the local redundancy status of the
+		// storage manager.
+		StorageRedundancy() StorageRedundancyStatus
+
+		// TemporarySectors returns the number of temporary sectors.
+		TemporarySectors() uint64
 	}
 )
+
+// Validate returns an error if the redundancy mode is unknown.
+func (m StorageRedundancyMode) Validate() error {
+	if m != StorageRedundancyNone && m != StorageRedundancyMirror {
+		return ErrUnknownStorageRedundancyMode
+	}
+	return nil
+}
+
+// Validate returns an error if the tier is unknown.
+func (t StorageFolderTier) Validate() error {
+	if t != StorageFolderTierFast && t != StorageFolderTierSlow {
+		return ErrUnknownStorageFolderTier
+	}
+	return nil
+}
@@ -126,10 +126,12 @@ type (
 
 		requiredUserAgent string
 		requiredPassword  string
+		staticTokens      *TokenStore
 		Shutdown          func() error
 		siadConfig        *modules.SiadConfig
 
-		staticStartTime time.Time
+		staticStartTime      time.Time
+		staticRequestMetrics *requestMetrics
 
 		staticDeps modules.Dependencies
 	}
@@ -193,8 +195,8 @@ func (api *API) StartTime() time.Time {
 // New creates a new Sia API from the provided modules. The API will require
 // authentication using HTTP basic auth for certain endpoints of the supplied
 // password is not the empty string.  Usernames are ignored for authentication.
-func New(cfg *modules.SiadConfig, requiredUserAgent string, requiredPassword string, acc modules.Accounting, cs modules.ConsensusSet, e modules.Explorer, g modules.Gateway, h modules.Host, m modules.Miner, r modules.Renter, tp modules.TransactionPool, w modules.Wallet) *API {
-	return NewCustom(cfg, requiredUserAgent, requiredPassword, acc, cs, e, g, h, m, r, tp, w, modules.ProdDependencies)
+func New(cfg *modules.SiadConfig, tokens *TokenStore, requiredUserAgent string, requiredPassword string, acc modules.Accounting, cs modules.ConsensusSet, e modules.Explorer, g modules.Gateway, h modules.Host, m modules.Miner, r modules.Renter, tp modules.TransactionPool, w modules.Wallet) *API {
+	return NewCustom(cfg, tokens, requiredUserAgent, requiredPassword, acc, cs, e, g, h, m, r, tp, w, modules.ProdDependencies)
 }
 
 // NewCustom creates a new Sia API from the provided modules. The API will
@@ -202,7 +204,7 @@ func New(cfg *modules.SiadConfig, requiredUserAgent string, requiredPassword str
 // supplied password is not the empty string. Usernames are ignored for
 // authentication. It is custom because it allows to inject custom dependencies
 // into the API.
-func NewCustom(cfg *modules.SiadConfig, requiredUserAgent string, requiredPassword string, acc modules.Accounting, cs modules.ConsensusSet, e modules.Explorer, g modules.Gateway, h modules.Host, m modules.Miner, r modules.Renter, tp modules.TransactionPool, w modules.Wallet, deps modules.Dependencies) *API {
+func NewCustom(cfg *modules.SiadConfig, tokens *TokenStore, requiredUserAgent string, requiredPassword string, acc modules.Accounting, cs modules.ConsensusSet, e modules.Explorer, g modules.Gateway, h modules.Host, m modules.Miner, r modules.Renter, tp modules.TransactionPool, w modules.Wallet, deps modules.Dependencies) *API {
 	api := &API{
 		accounting:        acc,
 		cs:                cs,
@@ -217,9 +219,11 @@ func NewCustom(cfg *modules.SiadConfig, requiredUserAgent string, requiredPasswo
 		requiredUserAgent: requiredUserAgent,
 		requiredPassword:  requiredPassword,
 		siadConfig:        cfg,
+		staticTokens:      tokens,
 
-		staticDeps:      deps,
-		staticStartTime: time.Now(),
+		staticDeps:           deps,
+		staticStartTime:      time.Now(),
+		staticRequestMetrics: newRequestMetrics(),
 	}
 
 	// Register API handlers
@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,10 +31,19 @@ type (
 		// Password must match the password of the siad server.
 		Password string
 
+		// Username is sent along with the password. It is the name of the
+		// renter tenant the client authenticates as, in which case Password
+		// must be the token of the tenant.
+		Username string
+
 		// UserAgent must match the User-Agent required by the siad server. If not
 		// set, it defaults to "Sia-Agent".
 		UserAgent string
 
+		// TLSConfig is used to connect to a siad API served over TLS. If set,
+		// requests use https. See NewTLSConfig.
+		TLSConfig *tls.Config
+
 		// CheckRedirect is an optional handler to be called if the request
 		// receives a redirect status code.
 		// For more see https://golang.org/pkg/net/http/#Client
@@ -79,7 +89,7 @@ func (uc *UnsafeClient) GetWithHeaders(resource string, headers http.Header) (*h
 		}
 	}
 
-	httpClient := http.Client{CheckRedirect: uc.CheckRedirect}
+	httpClient := uc.httpClient()
 	return httpClient.Do(req)
 }
 
@@ -110,7 +120,7 @@ func DefaultOptions() (Options, error) {
 // NewRequest constructs a request to the siad HTTP API, setting the correct
 // User-Agent and Basic Auth. The resource path must begin with /.
 func (c *Client) NewRequest(method, resource string, body io.Reader) (*http.Request, error) {
-	url := "http://" + c.Address + resource
+	url := c.scheme() + "://" + c.Address + resource
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
@@ -120,8 +130,8 @@ func (c *Client) NewRequest(method, resource string, body io.Reader) (*http.Requ
 		agent = "Sia-Agent"
 	}
 	req.Header.Set("User-Agent", agent)
-	if c.Password != "" {
-		req.SetBasicAuth("", c.Password)
+	if c.Password != "" || c.Username != "" {
+		req.SetBasicAuth(c.Username, c.Password)
 	}
 	return req, nil
 }
@@ -173,7 +183,7 @@ func (c *Client) getReaderResponse(resource string) (http.Header, io.ReadCloser,
 	if err != nil {
 		return nil, nil, errors.AddContext(err, "failed to construct GET request")
 	}
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return nil, nil, errors.AddContext(err, "GET request failed")
@@ -211,7 +221,7 @@ func (c *Client) getRawPartialResponse(resource string, from, to uint64) ([]byte
 	}
 	req.Header.Add("Range", fmt.Sprintf("bytes=%d-%d", from, to-1))
 
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return nil, errors.AddContext(err, "GET request failed")
@@ -268,7 +278,7 @@ func (c *Client) head(resource string) (int, http.Header, error) {
 	if err != nil {
 		return 0, nil, errors.AddContext(err, "failed to construct HEAD request")
 	}
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return 0, nil, errors.AddContext(err, "HEAD request failed")
@@ -302,7 +312,7 @@ func (c *Client) postRawResponseWithHeaders(resource string, body io.Reader, hea
 		}
 	}
 
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return http.Header{}, nil, errors.AddContext(err, "POST request failed")
@@ -330,6 +340,39 @@ func (c *Client) postRawResponseWithHeaders(resource string, body io.Reader, hea
 	return res.Header, d, err
 }
 
+// putRawResponse requests the specified resource with a PUT request, using
+// body as the request body. The response, if provided, will be returned in a
+// byte slice.
+func (c *Client) putRawResponse(resource string, body io.Reader) ([]byte, error) {
+	req, err := c.NewRequest("PUT", resource, body)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to construct PUT request")
+	}
+	httpClient := c.httpClient()
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, errors.AddContext(err, "PUT request failed")
+	}
+	defer drainAndClose(res.Body)
+
+	if res.StatusCode == api.StatusModuleNotLoaded || res.StatusCode == api.StatusModuleDisabled {
+		err = errors.Compose(readAPIError(res.Body), api.ErrAPICallNotRecognized)
+		return nil, errors.AddContext(err, "unable to perform PUT on "+resource)
+	}
+
+	// If the status code is not 2xx, decode and return the accompanying
+	// api.Error.
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, errors.AddContext(readAPIError(res.Body), "PUT request error")
+	}
+
+	if res.StatusCode == http.StatusNoContent {
+		// no reason to read the response
+		return []byte{}, nil
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
 // post makes a POST request to the resource at `resource`, using `data` as the
 // request body. The response, if provided, will be decoded into `obj`.
 func (c *Client) post(resource string, data string, obj interface{}) error {
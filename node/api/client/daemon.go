@@ -2,9 +2,13 @@ package client
 
 import (
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 
+	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/node/api"
+	"go.thebigfile.com/bigd/persist"
 )
 
 // DaemonGlobalRateLimitPost uses the /daemon/settings endpoint to change the
@@ -18,6 +22,21 @@ func (c *Client) DaemonGlobalRateLimitPost(downloadSpeed, uploadSpeed int64) (er
 	return
 }
 
+// DaemonLogLevelsPost uses the /daemon/settings endpoint to change the log
+// levels of siad's modules. persist.DefaultLogModule sets the default level
+// and an empty level removes the level of a module.
+func (c *Client) DaemonLogLevelsPost(levels map[string]persist.LogLevel) (err error) {
+	pairs := make([]string, 0, len(levels))
+	for module, level := range levels {
+		pairs = append(pairs, module+":"+string(level))
+	}
+	sort.Strings(pairs)
+	values := url.Values{}
+	values.Set("loglevels", strings.Join(pairs, ","))
+	err = c.post("/daemon/settings", values.Encode(), nil)
+	return
+}
+
 // DaemonAlertsGet requests the /daemon/alerts resource.
 func (c *Client) DaemonAlertsGet() (dag api.DaemonAlertsGet, err error) {
 	err = c.get("/daemon/alerts", &dag)
@@ -74,3 +93,44 @@ func (c *Client) DaemonUpdatePost() (err error) {
 	err = c.post("/daemon/update", "", nil)
 	return
 }
+
+// DaemonTokensGet requests the /daemon/tokens resource.
+func (c *Client) DaemonTokensGet() (dtg api.DaemonTokensGet, err error) {
+	err = c.get("/daemon/tokens", &dtg)
+	return
+}
+
+// DaemonTokensCreatePost uses the /daemon/tokens/create endpoint to create an
+// API token.
+func (c *Client) DaemonTokensCreatePost(name string, scopes []api.APIScope, pathPrefixes []modules.SiaPath) (dtc api.DaemonTokensCreatePost, err error) {
+	scopeStrs := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		scopeStrs = append(scopeStrs, string(s))
+	}
+	prefixStrs := make([]string, 0, len(pathPrefixes))
+	for _, p := range pathPrefixes {
+		prefixStrs = append(prefixStrs, p.String())
+	}
+	values := url.Values{}
+	values.Set("name", name)
+	values.Set("scopes", strings.Join(scopeStrs, ","))
+	values.Set("pathprefixes", strings.Join(prefixStrs, ","))
+	err = c.post("/daemon/tokens/create", values.Encode(), &dtc)
+	return
+}
+
+// DaemonTokensRevokePost uses the /daemon/tokens/revoke endpoint to revoke an
+// API token.
+func (c *Client) DaemonTokensRevokePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/daemon/tokens/revoke", values.Encode(), nil)
+	return
+}
+
+// MetricsGet requests the /metrics endpoint and returns the metrics in the
+// Prometheus text exposition format.
+func (c *Client) MetricsGet() (string, error) {
+	_, resp, err := c.getRawResponse("/metrics")
+	return string(resp), err
+}
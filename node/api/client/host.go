@@ -68,8 +68,93 @@ const (
 	// HostParamCustomRegistryPath is the locataion of the host's registry on
 	// disk.
 	HostParamCustomRegistryPath = HostParam("customregistrypath")
+	// HostParamAcceptingTemporarySectors indicates if the host is accepting
+	// sectors that don't belong to a contract.
+	HostParamAcceptingTemporarySectors = HostParam("acceptingtemporarysectors")
+	// HostParamMaxTemporaryStorage is the maximum number of bytes the host
+	// stores in temporary sectors.
+	HostParamMaxTemporaryStorage = HostParam("maxtemporarystorage")
+	// HostParamMinTemporaryStoragePrice is the minimum price for storing data
+	// in temporary sectors in hastings/byte/block.
+	HostParamMinTemporaryStoragePrice = HostParam("mintemporarystorageprice")
+	// HostParamBandwidthLimitDownload is the max bandwidth in bytes/second
+	// the host uses for sending data of downloads.
+	HostParamBandwidthLimitDownload = HostParam("bandwidthlimitdownload")
+	// HostParamBandwidthLimitUpload is the max bandwidth in bytes/second
+	// the host uses for receiving data of uploads.
+	HostParamBandwidthLimitUpload = HostParam("bandwidthlimitupload")
+	// HostParamBandwidthLimitRegistry is the max bandwidth in bytes/second
+	// the host uses for registry RPCs.
+	HostParamBandwidthLimitRegistry = HostParam("bandwidthlimitregistry")
+	// HostParamBandwidthLimitRenterDownload is the max download bandwidth in
+	// bytes/second of a single renter.
+	HostParamBandwidthLimitRenterDownload = HostParam("bandwidthlimitrenterdownload")
+	// HostParamBandwidthLimitRenterUpload is the max upload bandwidth in
+	// bytes/second of a single renter.
+	HostParamBandwidthLimitRenterUpload = HostParam("bandwidthlimitrenterupload")
+	// HostParamBandwidthLimitRenterRegistry is the max registry bandwidth in
+	// bytes/second of a single renter.
+	HostParamBandwidthLimitRenterRegistry = HostParam("bandwidthlimitrenterregistry")
+	// HostParamPricingEngine indicates if the pricing engine is enabled.
+	HostParamPricingEngine = HostParam("pricingengine")
+	// HostParamPricingEngineExchangeRate is the fiat value of one siacoin used by
+	// the pricing engine.
+	HostParamPricingEngineExchangeRate = HostParam("pricingengineexchangerate")
+	// HostParamPricingEngineTargetDownloadBandwidthPrice is the target download
+	// bandwidth price in fiat/TB.
+	HostParamPricingEngineTargetDownloadBandwidthPrice = HostParam("pricingenginetargetdownloadbandwidthprice")
+	// HostParamPricingEngineTargetStoragePrice is the target storage price in
+	// fiat/TB/month.
+	HostParamPricingEngineTargetStoragePrice = HostParam("pricingenginetargetstorageprice")
+	// HostParamPricingEngineTargetUploadBandwidthPrice is the target upload
+	// bandwidth price in fiat/TB.
+	HostParamPricingEngineTargetUploadBandwidthPrice = HostParam("pricingenginetargetuploadbandwidthprice")
+	// HostParamPricingEngineTargetBandwidth is the bandwidth in bytes/second at
+	// which the bandwidth prices equal their base price.
+	HostParamPricingEngineTargetBandwidth = HostParam("pricingenginetargetbandwidth")
+	// HostParamPricingEngineMaxDownloadBandwidthPrice is the max download
+	// bandwidth price in hastings/byte.
+	HostParamPricingEngineMaxDownloadBandwidthPrice = HostParam("pricingenginemaxdownloadbandwidthprice")
+	// HostParamPricingEngineMaxStoragePrice is the max storage price in
+	// hastings/byte/block.
+	HostParamPricingEngineMaxStoragePrice = HostParam("pricingenginemaxstorageprice")
+	// HostParamPricingEngineMaxUploadBandwidthPrice is the max upload bandwidth
+	// price in hastings/byte.
+	HostParamPricingEngineMaxUploadBandwidthPrice = HostParam("pricingenginemaxuploadbandwidthprice")
+	// HostParamPricingEngineMaxPriceChange is the max relative change of a price
+	// per update.
+	HostParamPricingEngineMaxPriceChange = HostParam("pricingenginemaxpricechange")
+	// HostParamPricingEngineUpdateInterval is the minimum time between two price
+	// updates in seconds.
+	HostParamPricingEngineUpdateInterval = HostParam("pricingengineupdateinterval")
 )
 
+// HostAccountsGet uses the /host/accounts endpoint to get information about
+// the host's ephemeral accounts.
+func (c *Client) HostAccountsGet() (hag api.HostAccountsGET, err error) {
+	err = c.get("/host/accounts", &hag)
+	return
+}
+
+// HostAccountsExpirePost uses the /host/accounts/expire endpoint to expire an
+// ephemeral account on the host.
+func (c *Client) HostAccountsExpirePost(id modules.AccountID) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	err = c.post("/host/accounts/expire", values.Encode(), nil)
+	return
+}
+
+// HostAccountsMaxBalancePost uses the /host/accounts/maxbalance endpoint to
+// cap the balance of an ephemeral account on the host.
+func (c *Client) HostAccountsMaxBalancePost(id modules.AccountID, maxBalance types.Currency) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	values.Set("maxbalance", maxBalance.String())
+	err = c.post("/host/accounts/maxbalance", values.Encode(), nil)
+	return
+}
+
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
 // the network
 func (c *Client) HostAnnouncePost() (err error) {
@@ -104,6 +189,20 @@ func (c *Client) HostEstimateScoreGet(param, value string) (eg api.HostEstimateS
 	return
 }
 
+// HostExportGet uses the /host/export endpoint to get the records of the
+// host's storage obligations for revenue reporting.
+func (c *Client) HostExportGet(params modules.HostContractExportParams) (heg api.HostExportGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(params.StartHeight))
+	values.Set("endheight", fmt.Sprint(params.EndHeight))
+	values.Set("starttime", fmt.Sprint(params.StartTime))
+	values.Set("endtime", fmt.Sprint(params.EndTime))
+	values.Set("rates", modules.EncodeHostExchangeRates(params.Rates))
+	values.Set("unresolved", strconv.FormatBool(params.IncludeUnresolved))
+	err = c.get("/host/export?"+values.Encode(), &heg)
+	return
+}
+
 // HostGet requests the /host endpoint.
 func (c *Client) HostGet() (hg api.HostGET, err error) {
 	err = c.get("/host", &hg)
@@ -123,6 +222,45 @@ func (c *Client) HostBandwidthGet() (gbg api.GatewayBandwidthGET, err error) {
 	return
 }
 
+// HostRegistryGet uses the /host/registry endpoint to get the usage
+// statistics of the host's registry.
+func (c *Client) HostRegistryGet() (hrg api.HostRegistryGET, err error) {
+	err = c.get("/host/registry", &hrg)
+	return
+}
+
+// HostRegistryEntryGet uses the /host/registry/entry/:entryid endpoint to
+// look up an entry of the host's registry.
+func (c *Client) HostRegistryEntryGet(eid modules.RegistryEntryID) (hreg api.HostRegistryEntryGET, err error) {
+	err = c.get("/host/registry/entry/"+crypto.Hash(eid).String(), &hreg)
+	return
+}
+
+// HostRegistryExportPost uses the /host/registry/export endpoint to export the
+// entries of the host's registry to a file.
+func (c *Client) HostRegistryExportPost(destination string) (hrep api.HostRegistryExportPOST, err error) {
+	values := url.Values{}
+	values.Set("destination", destination)
+	err = c.post("/host/registry/export", values.Encode(), &hrep)
+	return
+}
+
+// HostRegistryImportPost uses the /host/registry/import endpoint to import
+// the entries of a registry export into the host's registry.
+func (c *Client) HostRegistryImportPost(source string) (hrip api.HostRegistryImportPOST, err error) {
+	values := url.Values{}
+	values.Set("source", source)
+	err = c.post("/host/registry/import", values.Encode(), &hrip)
+	return
+}
+
+// HostRegistryPrunePost uses the /host/registry/prune endpoint to delete the
+// expired entries of the host's registry.
+func (c *Client) HostRegistryPrunePost() (hrpp api.HostRegistryPrunePOST, err error) {
+	err = c.post("/host/registry/prune", "", &hrpp)
+	return
+}
+
 // HostStorageFoldersAddPost uses the /host/storage/folders/add api endpoint to
 // add a storage folder to a host
 func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error) {
@@ -153,6 +291,54 @@ func (c *Client) HostStorageFoldersResizePost(path string, size uint64) (err err
 	return
 }
 
+// HostStorageFoldersTierPost uses the /host/storage/folders/tier api endpoint
+// to set the tier of an existing storage folder.
+func (c *Client) HostStorageFoldersTierPost(path string, tier modules.StorageFolderTier) (err error) {
+	values := url.Values{}
+	values.Set("path", path)
+	values.Set("tier", string(tier))
+	err = c.post("/host/storage/folders/tier", values.Encode(), nil)
+	return
+}
+
+// HostStorageFoldersCancelPost uses the /host/storage/folders/cancel api
+// endpoint to cancel the running storage folder operation.
+func (c *Client) HostStorageFoldersCancelPost() (err error) {
+	err = c.post("/host/storage/folders/cancel", "", nil)
+	return
+}
+
+// HostStorageFoldersMigratePost uses the /host/storage/folders/migrate api
+// endpoint to move all sectors of the source storage folder into the
+// destination storage folder.
+func (c *Client) HostStorageFoldersMigratePost(source, destination string, removeSource bool, maxBandwidth uint64) (err error) {
+	values := url.Values{}
+	values.Set("source", source)
+	values.Set("destination", destination)
+	values.Set("removesource", strconv.FormatBool(removeSource))
+	values.Set("maxbandwidth", strconv.FormatUint(maxBandwidth, 10))
+	err = c.post("/host/storage/folders/migrate", values.Encode(), nil)
+	return
+}
+
+// HostStorageFoldersRebalancePost uses the /host/storage/folders/rebalance api
+// endpoint to even out the free space of the storage folders.
+func (c *Client) HostStorageFoldersRebalancePost(maxBandwidth uint64) (err error) {
+	values := url.Values{}
+	values.Set("maxbandwidth", strconv.FormatUint(maxBandwidth, 10))
+	err = c.post("/host/storage/folders/rebalance", values.Encode(), nil)
+	return
+}
+
+// HostStorageRedundancyPost uses the /host/storage/redundancy api endpoint to
+// set the local redundancy mode of the host's storage.
+func (c *Client) HostStorageRedundancyPost(mode modules.StorageRedundancyMode) (err error) {
+	values := url.Values{}
+	values.Set("mode", string(mode))
+	err = c.post("/host/storage/redundancy", values.Encode(), nil)
+	return
+}
+
 // HostStorageGet requests the /host/storage endpoint.
 func (c *Client) HostStorageGet() (sg api.StorageGET, err error) {
 	err = c.get("/host/storage", &sg)
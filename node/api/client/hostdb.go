@@ -49,6 +49,22 @@ func (c *Client) HostDbFilterModePost(fm modules.FilterMode, hosts []types.SiaPu
 	return
 }
 
+// HostDbPolicyGet requests the /hostdb/policy GET endpoint.
+func (c *Client) HostDbPolicyGet() (hdpg api.HostdbPolicyGET, err error) {
+	err = c.get("/hostdb/policy", &hdpg)
+	return
+}
+
+// HostDbPolicyPost requests the /hostdb/policy POST endpoint.
+func (c *Client) HostDbPolicyPost(policy modules.HostSelectionPolicy) (err error) {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	err = c.post("/hostdb/policy", string(data), nil)
+	return
+}
+
 // HostDbHostsGet request the /hostdb/hosts/:pubkey endpoint's resources.
 func (c *Client) HostDbHostsGet(pk types.SiaPublicKey) (hhg api.HostdbHostsGET, err error) {
 	err = c.get("/hostdb/hosts/"+pk.String(), &hhg)
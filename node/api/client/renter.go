@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -12,6 +13,7 @@ import (
 
 	"gitlab.com/NebulousLabs/errors"
 
+	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/node/api"
 	"go.thebigfile.com/bigd/types"
@@ -123,6 +125,43 @@ func (a *AllowanceRequestPost) WithMaxUploadBandwidthPrice(price types.Currency)
 	return a
 }
 
+// WithMaxUploadSpending adds the maxuploadspending field to the request.
+func (a *AllowanceRequestPost) WithMaxUploadSpending(spending types.Currency) *AllowanceRequestPost {
+	a.values.Set("maxuploadspending", spending.String())
+	return a
+}
+
+// WithMaxDownloadSpending adds the maxdownloadspending field to the request.
+func (a *AllowanceRequestPost) WithMaxDownloadSpending(spending types.Currency) *AllowanceRequestPost {
+	a.values.Set("maxdownloadspending", spending.String())
+	return a
+}
+
+// WithMaxStorageSpending adds the maxstoragespending field to the request.
+func (a *AllowanceRequestPost) WithMaxStorageSpending(spending types.Currency) *AllowanceRequestPost {
+	a.values.Set("maxstoragespending", spending.String())
+	return a
+}
+
+// WithMaxFeeSpending adds the maxfeespending field to the request.
+func (a *AllowanceRequestPost) WithMaxFeeSpending(spending types.Currency) *AllowanceRequestPost {
+	a.values.Set("maxfeespending", spending.String())
+	return a
+}
+
+// WithMaxFundAccountSpending adds the maxfundaccountspending field to the request.
+func (a *AllowanceRequestPost) WithMaxFundAccountSpending(spending types.Currency) *AllowanceRequestPost {
+	a.values.Set("maxfundaccountspending", spending.String())
+	return a
+}
+
+// WithSpendingAlertThreshold adds the spendingalertthreshold field to the
+// request.
+func (a *AllowanceRequestPost) WithSpendingAlertThreshold(threshold float64) *AllowanceRequestPost {
+	a.values.Set("spendingalertthreshold", fmt.Sprint(threshold))
+	return a
+}
+
 // Send finalizes and sends the request.
 func (a *AllowanceRequestPost) Send() (err error) {
 	if a.sent {
@@ -188,6 +227,36 @@ func (c *Client) RenterContractsGet() (rc api.RenterContracts, err error) {
 	return
 }
 
+// RenterContractEventsGet requests the /renter/contracts/events resource and
+// returns the contract events matching the filter.
+func (c *Client) RenterContractEventsGet(filter modules.ContractEventFilter) (rce api.RenterContractEvents, err error) {
+	values := url.Values{}
+	if len(filter.Types) > 0 {
+		eventTypes := make([]string, 0, len(filter.Types))
+		for _, t := range filter.Types {
+			eventTypes = append(eventTypes, string(t))
+		}
+		values.Set("type", strings.Join(eventTypes, ","))
+	}
+	if filter.ContractID != (types.FileContractID{}) {
+		values.Set("id", filter.ContractID.String())
+	}
+	if len(filter.HostPublicKey.Key) > 0 {
+		values.Set("host", filter.HostPublicKey.String())
+	}
+	if filter.MinHeight > 0 {
+		values.Set("minheight", fmt.Sprint(filter.MinHeight))
+	}
+	if filter.MaxHeight > 0 {
+		values.Set("maxheight", fmt.Sprint(filter.MaxHeight))
+	}
+	if filter.Limit > 0 {
+		values.Set("limit", fmt.Sprint(filter.Limit))
+	}
+	err = c.get("/renter/contracts/events?"+values.Encode(), &rce)
+	return
+}
+
 // RenterContractStatus requests the /watchdog/contractstatus resource and returns
 // the status of a contract.
 func (c *Client) RenterContractStatus(fcID types.FileContractID) (status modules.ContractWatchStatus, err error) {
@@ -497,6 +566,21 @@ func (c *Client) RenterRateLimitPost(readBPS, writeBPS int64) (err error) {
 	return
 }
 
+// RenterChunkCacheSizePost uses the /renter endpoint to change the maximum
+// size of the renter's chunk cache.
+func (c *Client) RenterChunkCacheSizePost(size uint64) (err error) {
+	values := url.Values{}
+	values.Set("chunkcachesize", strconv.FormatUint(size, 10))
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
+// RenterChunkCacheGet requests the /renter/chunkcache resource.
+func (c *Client) RenterChunkCacheGet() (ccs modules.ChunkCacheStatus, err error) {
+	err = c.get("/renter/chunkcache", &ccs)
+	return
+}
+
 // RenterRenamePost uses the /renter/rename/:siapath endpoint to rename a file.
 func (c *Client) RenterRenamePost(siaPathOld, siaPathNew modules.SiaPath, root bool) (err error) {
 	spo := escapeSiaPath(siaPathOld)
@@ -568,6 +652,57 @@ func (c *Client) RenterSetFileStuckPost(siaPath modules.SiaPath, root, stuck boo
 	return
 }
 
+// RenterFileReencodePost uses the /renter/file/:siapath endpoint to migrate a
+// file to new erasure code settings and cipher type. Passing 0 data and parity
+// pieces or an invalid cipher type keeps the respective setting of the file.
+func (c *Client) RenterFileReencodePost(siaPath modules.SiaPath, dataPieces, parityPieces uint64, ct crypto.CipherType) (err error) {
+	sp := escapeSiaPath(siaPath)
+	err = c.post(fmt.Sprintf("/renter/file/%v", sp), reencodeValues(dataPieces, parityPieces, ct).Encode(), nil)
+	return
+}
+
+// RenterDirReencodePost uses the /renter/dir/ endpoint to migrate all files
+// within a directory to new erasure code settings and cipher type.
+func (c *Client) RenterDirReencodePost(siaPath modules.SiaPath, dataPieces, parityPieces uint64, ct crypto.CipherType) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := reencodeValues(dataPieces, parityPieces, ct)
+	values.Set("action", "reencode")
+	err = c.post(fmt.Sprintf("/renter/dir/%s", sp), values.Encode(), nil)
+	return
+}
+
+// reencodeValues creates the query values for a re-encoding request.
+func reencodeValues(dataPieces, parityPieces uint64, ct crypto.CipherType) url.Values {
+	values := url.Values{}
+	if dataPieces != 0 || parityPieces != 0 {
+		values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
+		values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
+	}
+	if ct != crypto.TypeInvalid {
+		values.Set("ciphertype", ct.String())
+	}
+	return values
+}
+
+// RenterVerifyGet uses the /renter/verify endpoint to get the trust scores of
+// the hosts that were sampled by previous verifications.
+func (c *Client) RenterVerifyGet() (rvg api.RenterVerifyGET, err error) {
+	err = c.get("/renter/verify", &rvg)
+	return
+}
+
+// RenterVerifyPost uses the /renter/verify endpoint to verify the integrity
+// of a file or, if recursive is set, of all files within a directory.
+func (c *Client) RenterVerifyPost(siaPath modules.SiaPath, samples uint64, recursive, repair bool) (report modules.VerificationReport, err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("samples", strconv.FormatUint(samples, 10))
+	values.Set("recursive", fmt.Sprint(recursive))
+	values.Set("repair", fmt.Sprint(repair))
+	err = c.post(fmt.Sprintf("/renter/verify/%s", sp), values.Encode(), &report)
+	return
+}
+
 // RenterUploadPost uses the /renter/upload endpoint to upload a file
 func (c *Client) RenterUploadPost(path string, siaPath modules.SiaPath, dataPieces, parityPieces uint64) (err error) {
 	return c.RenterUploadForcePost(path, siaPath, dataPieces, parityPieces, false)
@@ -620,6 +755,53 @@ func (c *Client) RenterUploadStreamRepairPost(r io.Reader, siaPath modules.SiaPa
 	return err
 }
 
+// RenterUploadStreamResumablePost uses the /renter/uploadstream endpoint to
+// create a resumable upload.
+func (c *Client) RenterUploadStreamResumablePost(siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool) (info modules.ResumableUploadInfo, err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
+	values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
+	values.Set("force", strconv.FormatBool(force))
+	values.Set("resumable", strconv.FormatBool(true))
+	err = c.post(fmt.Sprintf("/renter/uploadstream/%s?%s", sp, values.Encode()), "", &info)
+	return
+}
+
+// RenterUploadResumableGet uses the /renter/uploads/resumable/:uploadid
+// endpoint to fetch the state of a resumable upload.
+func (c *Client) RenterUploadResumableGet(id modules.UploadID) (info modules.ResumableUploadInfo, err error) {
+	err = c.get(fmt.Sprintf("/renter/uploads/resumable/%s", id), &info)
+	return
+}
+
+// RenterUploadResumablePut uses the /renter/uploads/resumable/:uploadid
+// endpoint to append the data read from r to a resumable upload.
+func (c *Client) RenterUploadResumablePut(id modules.UploadID, offset uint64, r io.Reader) (info modules.ResumableUploadInfo, err error) {
+	values := url.Values{}
+	values.Set("offset", strconv.FormatUint(offset, 10))
+	resp, err := c.putRawResponse(fmt.Sprintf("/renter/uploads/resumable/%s?%s", id, values.Encode()), r)
+	if err != nil {
+		return modules.ResumableUploadInfo{}, err
+	}
+	err = json.Unmarshal(resp, &info)
+	return
+}
+
+// RenterUploadResumableFinalizePost uses the /renter/uploads/resumable/:uploadid
+// endpoint to finalize a resumable upload.
+func (c *Client) RenterUploadResumableFinalizePost(id modules.UploadID) (err error) {
+	err = c.post(fmt.Sprintf("/renter/uploads/resumable/%s", id), "action=finalize", nil)
+	return
+}
+
+// RenterUploadResumableAbortPost uses the /renter/uploads/resumable/:uploadid
+// endpoint to abort a resumable upload.
+func (c *Client) RenterUploadResumableAbortPost(id modules.UploadID) (err error) {
+	err = c.post(fmt.Sprintf("/renter/uploads/resumable/%s", id), "action=abort", nil)
+	return
+}
+
 // RenterDirCreatePost uses the /renter/dir/ endpoint to create a directory for the
 // renter
 func (c *Client) RenterDirCreatePost(siaPath modules.SiaPath) (err error) {
@@ -777,8 +959,70 @@ func (c *Client) RenterBubblePost(siaPath modules.SiaPath, force, recursive bool
 	return
 }
 
+// RenterBulkPost uses the /renter/bulk endpoint to execute a list of
+// operations against the renter's filesystem. If root is set, the siapaths of
+// the operations are relative to the root instead of the user folder.
+func (c *Client) RenterBulkPost(ops []modules.BulkOperation, root bool) (rbr api.RenterBulkResults, err error) {
+	data, err := json.Marshal(api.RenterBulkPOST{
+		Operations: ops,
+		Root:       root,
+	})
+	if err != nil {
+		return api.RenterBulkResults{}, err
+	}
+	err = c.post("/renter/bulk", string(data), &rbr)
+	return
+}
+
 func (c *Client) RenterFileHosts(siaPath modules.SiaPath) (hosts []modules.HostDBEntry, err error) {
 	sp := escapeSiaPath(siaPath)
 	err = c.get("/renter/hosts/"+sp, &hosts)
 	return
 }
+
+// tenantQuotaValues encodes a tenant quota as the query parameters of the
+// tenant endpoints.
+func tenantQuotaValues(name string, quota modules.TenantQuota) url.Values {
+	values := url.Values{}
+	values.Set("name", name)
+	values.Set("storagequota", fmt.Sprint(quota.Storage))
+	values.Set("uploadquota", fmt.Sprint(quota.Upload))
+	values.Set("downloadquota", fmt.Sprint(quota.Download))
+	return values
+}
+
+// RenterTenantGet requests the /renter/tenant resource and returns information
+// about the tenant the client is authenticated as.
+func (c *Client) RenterTenantGet() (ti modules.TenantInfo, err error) {
+	err = c.get("/renter/tenant", &ti)
+	return
+}
+
+// RenterTenantsGet requests the /renter/tenants resource.
+func (c *Client) RenterTenantsGet() (rt api.RenterTenants, err error) {
+	err = c.get("/renter/tenants", &rt)
+	return
+}
+
+// RenterTenantsCreatePost uses the /renter/tenants/create endpoint to create a
+// new tenant.
+func (c *Client) RenterTenantsCreatePost(name string, quota modules.TenantQuota) (rtc api.RenterTenantsCreatePOST, err error) {
+	err = c.post("/renter/tenants/create", tenantQuotaValues(name, quota).Encode(), &rtc)
+	return
+}
+
+// RenterTenantsDeletePost uses the /renter/tenants/delete endpoint to delete a
+// tenant.
+func (c *Client) RenterTenantsDeletePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/tenants/delete", values.Encode(), nil)
+	return
+}
+
+// RenterTenantsQuotaPost uses the /renter/tenants/quota endpoint to update
+// the quota of a tenant.
+func (c *Client) RenterTenantsQuotaPost(name string, quota modules.TenantQuota) (err error) {
+	err = c.post("/renter/tenants/quota", tenantQuotaValues(name, quota).Encode(), nil)
+	return
+}
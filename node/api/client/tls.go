@@ -0,0 +1,71 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// tlsTransports caches the transports of clients with a TLS config to reuse
+// connections across requests.
+var tlsTransports sync.Map
+
+// NewTLSConfig creates a TLS config for connecting to a siad API served over
+// TLS. If caFile is set, the server certificate is verified against the PEM
+// encoded CA certificate instead of the system roots. If certFile and keyFile
+// are set, the client authenticates with the PEM encoded client certificate.
+func NewTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		caPEM, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to read CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("CA file doesn't contain any certificates")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("client certificate and key must be used together")
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// scheme returns the URL scheme of requests to the siad API.
+func (c *Client) scheme() string {
+	if c.TLSConfig != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// httpClient returns the http.Client used to make requests to the siad API.
+func (c *Client) httpClient() *http.Client {
+	httpClient := &http.Client{CheckRedirect: c.CheckRedirect}
+	if c.TLSConfig == nil {
+		return httpClient
+	}
+	transport, ok := tlsTransports.Load(c.TLSConfig)
+	if !ok {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.TLSClientConfig = c.TLSConfig
+		transport, _ = tlsTransports.LoadOrStore(c.TLSConfig, t)
+	}
+	httpClient.Transport = transport.(*http.Transport)
+	return httpClient
+}
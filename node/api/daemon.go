@@ -25,6 +25,7 @@ import (
 	"gitlab.com/NebulousLabs/errors"
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/profile"
 	"go.thebigfile.com/bigd/types"
 )
@@ -134,9 +135,22 @@ type (
 
 	// DaemonSettingsGet contains information about global daemon settings.
 	DaemonSettingsGet struct {
-		MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
-		MaxUploadSpeed   int64         `json:"maxuploadspeed"`
-		Modules          configModules `json:"modules"`
+		LogLevels        map[string]persist.LogLevel `json:"loglevels"`
+		MaxDownloadSpeed int64                       `json:"maxdownloadspeed"`
+		MaxUploadSpeed   int64                       `json:"maxuploadspeed"`
+		Modules          configModules               `json:"modules"`
+	}
+
+	// DaemonTokensGet lists the API tokens.
+	DaemonTokensGet struct {
+		Tokens []APIToken `json:"tokens"`
+	}
+
+	// DaemonTokensCreatePost contains a newly created API token together
+	// with the secret it authenticates with.
+	DaemonTokensCreatePost struct {
+		APIToken
+		Token string `json:"token"`
 	}
 
 	// DaemonVersion holds the version information for siad
@@ -493,6 +507,64 @@ func (api *API) daemonVersionHandler(w http.ResponseWriter, _ *http.Request, _ h
 	WriteJSON(w, DaemonVersion{Version: build.NodeVersion, GitRevision: build.GitRevision, BuildTime: build.BuildTime})
 }
 
+// daemonTokensHandlerGET handles the API call to list the API tokens.
+func (api *API) daemonTokensHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	if api.staticTokens == nil {
+		WriteError(w, Error{"API tokens are not available"}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, DaemonTokensGet{Tokens: api.staticTokens.Tokens()})
+}
+
+// daemonTokensCreateHandlerPOST handles the API call to create an API token.
+func (api *API) daemonTokensCreateHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if api.staticTokens == nil {
+		WriteError(w, Error{"API tokens are not available"}, http.StatusBadRequest)
+		return
+	}
+	var scopes []APIScope
+	for _, s := range strings.Split(req.FormValue("scopes"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, APIScope(s))
+		}
+	}
+	var prefixes []modules.SiaPath
+	for _, p := range strings.Split(req.FormValue("pathprefixes"), ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		prefix, err := modules.NewSiaPath(p)
+		if err != nil {
+			WriteError(w, Error{"unable to parse path prefix: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	secret, token, err := api.staticTokens.Create(req.FormValue("name"), scopes, prefixes)
+	if err != nil {
+		WriteError(w, Error{"unable to create API token: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, DaemonTokensCreatePost{APIToken: token, Token: secret})
+}
+
+// daemonTokensRevokeHandlerPOST handles the API call to revoke an API token.
+func (api *API) daemonTokensRevokeHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if api.staticTokens == nil {
+		WriteError(w, Error{"API tokens are not available"}, http.StatusBadRequest)
+		return
+	}
+	err := api.staticTokens.Revoke(req.FormValue("name"))
+	if errors.Contains(err, ErrUnknownAPIToken) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"unable to revoke API token: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // daemonStopHandler handles the API call to stop the daemon cleanly.
 func (api *API) daemonStopHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	// can't write after we stop the server, so lie a bit.
@@ -506,11 +578,32 @@ func (api *API) daemonStopHandler(w http.ResponseWriter, _ *http.Request, _ http
 	}()
 }
 
+// parseLogLevels parses a comma separated list of module:level pairs. An empty
+// level removes the level of the module.
+func parseLogLevels(s string) (map[string]persist.LogLevel, error) {
+	levels := make(map[string]persist.LogLevel)
+	for _, pair := range strings.Split(s, ",") {
+		split := strings.Split(pair, ":")
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("expected module:level but got %q", pair)
+		}
+		level := persist.LogLevel(split[1])
+		if level != "" {
+			if err := level.Valid(); err != nil {
+				return nil, err
+			}
+		}
+		levels[split[0]] = level
+	}
+	return levels, nil
+}
+
 // daemonSettingsHandlerGET handles the API call asking for the daemon's
 // settings.
 func (api *API) daemonSettingsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	gmds, gmus, _ := modules.GlobalRateLimits.Limits()
 	WriteJSON(w, DaemonSettingsGet{
+		LogLevels:        persist.LogLevels(),
 		MaxDownloadSpeed: gmds,
 		MaxUploadSpeed:   gmus,
 		Modules:          api.staticConfigModules,
@@ -539,10 +632,27 @@ func (api *API) daemonSettingsHandlerPOST(w http.ResponseWriter, req *http.Reque
 		}
 		maxUploadSpeed = uploadSpeed
 	}
+	// Scan the log levels. (optional parameter)
+	var logLevels map[string]persist.LogLevel
+	if l := req.FormValue("loglevels"); l != "" {
+		var err error
+		logLevels, err = parseLogLevels(l)
+		if err != nil {
+			WriteError(w, Error{"unable to parse loglevels: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	// Set the limit.
 	if err := api.siadConfig.SetRatelimit(maxDownloadSpeed, maxUploadSpeed); err != nil {
 		WriteError(w, Error{"unable to set limits: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
+	// Set the log levels.
+	if logLevels != nil {
+		if err := api.siadConfig.SetLogLevels(logLevels); err != nil {
+			WriteError(w, Error{"unable to set log levels: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	WriteSuccess(w)
 }
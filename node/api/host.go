@@ -5,10 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
 
+	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/modules"
 	"go.thebigfile.com/bigd/types"
 )
@@ -41,12 +43,25 @@ type (
 		Contracts []modules.StorageObligation `json:"contracts"`
 	}
 
+	// HostAccountsGET contains the information about the ephemeral accounts
+	// that is returned by a GET request to /host/accounts.
+	HostAccountsGET struct {
+		Accounts []modules.HostEphemeralAccount       `json:"accounts"`
+		Summary  modules.HostEphemeralAccountsSummary `json:"summary"`
+	}
+
 	// HostContractGET contains information about the storage contract returned
 	// by a GET request to /host/contracts/:id
 	HostContractGET struct {
 		Contract modules.StorageObligation `json:"contract"`
 	}
 
+	// HostExportGET contains the records of the storage obligations returned
+	// by a GET request to /host/export.
+	HostExportGET struct {
+		Records []modules.HostContractRecord `json:"records"`
+	}
+
 	// HostGET contains the information that is returned after a GET request to
 	// /host - a bunch of information about the status of the host.
 	HostGET struct {
@@ -67,11 +82,43 @@ type (
 		ConversionRate float64        `json:"conversionrate"`
 	}
 
+	// HostRegistryGET contains the usage statistics of the registry that are
+	// returned by a GET request to /host/registry.
+	HostRegistryGET struct {
+		Stats modules.HostRegistryStats `json:"stats"`
+	}
+
+	// HostRegistryEntryGET contains the registry entry returned by a GET
+	// request to /host/registry/entry/:entryid.
+	HostRegistryEntryGET struct {
+		Entry modules.HostRegistryEntry `json:"entry"`
+	}
+
+	// HostRegistryExportPOST contains the number of entries that were
+	// exported by a POST request to /host/registry/export.
+	HostRegistryExportPOST struct {
+		Exported uint64 `json:"exported"`
+	}
+
+	// HostRegistryImportPOST contains the result of a POST request to
+	// /host/registry/import.
+	HostRegistryImportPOST struct {
+		modules.HostRegistryImport
+	}
+
+	// HostRegistryPrunePOST contains the number of entries that were pruned by
+	// a POST request to /host/registry/prune.
+	HostRegistryPrunePOST struct {
+		Pruned uint64 `json:"pruned"`
+	}
+
 	// StorageGET contains the information that is returned after a GET request
 	// to /host/storage - a bunch of information about the status of storage
 	// management on the host.
 	StorageGET struct {
-		Folders []modules.StorageFolderMetadata `json:"folders"`
+		Folders    []modules.StorageFolderMetadata `json:"folders"`
+		Operation  *modules.StorageFolderOperation `json:"operation"`
+		Redundancy modules.StorageRedundancyStatus `json:"redundancy"`
 	}
 )
 
@@ -87,12 +134,39 @@ func RegisterRoutesHost(router *httprouter.Router, h modules.Host, deps modules.
 	router.POST("/host/announce", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostAnnounceHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.GET("/host/accounts", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/accounts/expire", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsExpireHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/accounts/maxbalance", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostAccountsMaxBalanceHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.GET("/host/contracts", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostContractInfoHandler(h, w, req, ps)
 	})
 	router.GET("/host/contracts/:contractID", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostContractGetHandler(h, w, req, ps)
 	})
+	router.GET("/host/export", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostExportHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/registry", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryHandlerGET(h, w, req, ps)
+	})
+	router.GET("/host/registry/entry/:entryid", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryEntryHandlerGET(h, w, req, ps)
+	})
+	router.POST("/host/registry/export", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryExportHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/registry/import", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryImportHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/registry/prune", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		hostRegistryPruneHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.GET("/host/bandwidth", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		hostBandwidthHandlerGET(h, w, req, ps)
 	})
@@ -101,15 +175,30 @@ func RegisterRoutesHost(router *httprouter.Router, h modules.Host, deps modules.
 	router.GET("/host/storage", func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageHandler(h, w, req, ps)
 	})
+	router.POST("/host/storage/folders/cancel", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersCancelHandler(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/folders/add", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersAddHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.POST("/host/storage/folders/migrate", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersMigrateHandler(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/storage/folders/rebalance", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersRebalanceHandler(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/folders/remove", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersRemoveHandler(h, w, req, ps)
 	}, requiredPassword))
 	router.POST("/host/storage/folders/resize", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageFoldersResizeHandler(h, w, req, ps)
 	}, requiredPassword))
+	router.POST("/host/storage/folders/tier", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageFoldersTierHandler(h, w, req, ps)
+	}, requiredPassword))
+	router.POST("/host/storage/redundancy", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		storageRedundancyHandlerPOST(h, w, req, ps)
+	}, requiredPassword))
 	router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		storageSectorsDeleteHandler(h, w, req, ps)
 	}, requiredPassword))
@@ -126,6 +215,64 @@ func folderIndex(folderPath string, storageFolders []modules.StorageFolderMetada
 	return -1, errStorageFolderNotFound
 }
 
+// parseAccountID parses the account id of a request to the /host/accounts
+// endpoints.
+func parseAccountID(req *http.Request) (modules.AccountID, error) {
+	idStr := req.FormValue("id")
+	if idStr == "" {
+		return modules.AccountID{}, errors.New("id parameter is required")
+	}
+	var id modules.AccountID
+	if err := id.LoadString(idStr); err != nil {
+		return modules.AccountID{}, err
+	}
+	return id, nil
+}
+
+// hostAccountsHandlerGET handles the API call to get information about the
+// host's ephemeral accounts.
+func hostAccountsHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostAccountsGET{
+		Accounts: host.EphemeralAccounts(),
+		Summary:  host.EphemeralAccountsSummary(),
+	})
+}
+
+// hostAccountsExpireHandlerPOST handles the API call to expire an ephemeral
+// account.
+func hostAccountsExpireHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id, err := parseAccountID(req)
+	if err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := host.ExpireEphemeralAccount(id); err != nil {
+		WriteError(w, Error{"unable to expire account: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostAccountsMaxBalanceHandlerPOST handles the API call to cap the balance of
+// an ephemeral account.
+func hostAccountsMaxBalanceHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id, err := parseAccountID(req)
+	if err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var maxBalance types.Currency
+	if _, err := fmt.Sscan(req.FormValue("maxbalance"), &maxBalance); err != nil {
+		WriteError(w, Error{"unable to parse maxbalance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := host.SetEphemeralAccountMaxBalance(id, maxBalance); err != nil {
+		WriteError(w, Error{"unable to set max balance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // hostContractGetHandler handles the API call to get information about a contract.
 func hostContractGetHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
 	var obligationID types.FileContractID
@@ -159,6 +306,128 @@ func hostContractInfoHandler(host modules.Host, w http.ResponseWriter, _ *http.R
 	WriteJSON(w, cg)
 }
 
+// hostRegistryHandlerGET handles the API call to get the usage statistics of
+// the host's registry.
+func hostRegistryHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostRegistryGET{
+		Stats: host.RegistryStats(),
+	})
+}
+
+// hostRegistryEntryHandlerGET handles the API call to look up an entry of the
+// host's registry.
+func hostRegistryEntryHandlerGET(host modules.Host, w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	var eid crypto.Hash
+	if err := eid.LoadString(ps.ByName("entryid")); err != nil {
+		WriteError(w, Error{fmt.Sprintf("error parsing registry entry id: %v", err)}, http.StatusBadRequest)
+		return
+	}
+	entry, found := host.RegistryEntry(modules.RegistryEntryID(eid))
+	if !found {
+		WriteError(w, Error{"registry entry not found"}, http.StatusNotFound)
+		return
+	}
+	WriteJSON(w, HostRegistryEntryGET{
+		Entry: entry,
+	})
+}
+
+// hostRegistryExportHandlerPOST handles the API call to export the entries of
+// the host's registry to a file.
+func hostRegistryExportHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	destination := req.FormValue("destination")
+	if destination == "" {
+		WriteError(w, Error{"destination parameter is required"}, http.StatusBadRequest)
+		return
+	}
+	exported, err := host.RegistryExport(destination)
+	if err != nil {
+		WriteError(w, Error{"failed to export registry: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostRegistryExportPOST{
+		Exported: exported,
+	})
+}
+
+// hostRegistryImportHandlerPOST handles the API call to import the entries of
+// a registry export into the host's registry.
+func hostRegistryImportHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	source := req.FormValue("source")
+	if source == "" {
+		WriteError(w, Error{"source parameter is required"}, http.StatusBadRequest)
+		return
+	}
+	result, err := host.RegistryImport(source)
+	if err != nil {
+		WriteError(w, Error{"failed to import registry: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostRegistryImportPOST{result})
+}
+
+// hostRegistryPruneHandlerPOST handles the API call to delete the expired
+// entries of the host's registry.
+func hostRegistryPruneHandlerPOST(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	pruned, err := host.PruneRegistry()
+	if err != nil {
+		WriteError(w, Error{"failed to prune registry: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, HostRegistryPrunePOST{
+		Pruned: pruned,
+	})
+}
+
+// hostExportHandlerGET handles the API call to export the records of the
+// host's storage obligations for revenue reporting.
+func hostExportHandlerGET(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params modules.HostContractExportParams
+	bounds := []struct {
+		name  string
+		value *uint64
+	}{
+		{"startheight", (*uint64)(&params.StartHeight)},
+		{"endheight", (*uint64)(&params.EndHeight)},
+		{"starttime", (*uint64)(&params.StartTime)},
+		{"endtime", (*uint64)(&params.EndTime)},
+	}
+	for _, b := range bounds {
+		str := req.FormValue(b.name)
+		if str == "" {
+			continue
+		}
+		value, err := strconv.ParseUint(str, 10, 64)
+		if err != nil {
+			WriteError(w, Error{fmt.Sprintf("unable to parse %v: %v", b.name, err)}, http.StatusBadRequest)
+			return
+		}
+		*b.value = value
+	}
+	rates, err := modules.ParseHostExchangeRates(req.FormValue("rates"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse rates: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	params.Rates = rates
+	if str := req.FormValue("unresolved"); str != "" {
+		params.IncludeUnresolved, err = strconv.ParseBool(str)
+		if err != nil {
+			WriteError(w, Error{"unable to parse unresolved: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	records, err := host.ContractRecords(params)
+	if err != nil {
+		WriteError(w, Error{"unable to export contracts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostExportGET{
+		Records: records,
+	})
+}
+
 // hostHandlerGET handles GET requests to the /host API endpoint, returning key
 // information about the host.
 func hostHandlerGET(host modules.Host, w http.ResponseWriter, deps modules.Dependencies, _ *http.Request, _ httprouter.Params) {
@@ -367,6 +636,164 @@ func parseHostSettings(host modules.Host, req *http.Request) (modules.HostIntern
 		settings.CustomRegistryPath = req.FormValue("customregistrypath")
 	}
 
+	if req.FormValue("acceptingtemporarysectors") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("acceptingtemporarysectors"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AcceptingTemporarySectors = x
+	}
+	if req.FormValue("maxtemporarystorage") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("maxtemporarystorage"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MaxTemporaryStorage = x
+	}
+	if req.FormValue("mintemporarystorageprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("mintemporarystorageprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.MinTemporaryStoragePrice = x
+	}
+
+	if req.FormValue("bandwidthlimitdownload") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitdownload"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.Download = x
+	}
+	if req.FormValue("bandwidthlimitupload") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitupload"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.Upload = x
+	}
+	if req.FormValue("bandwidthlimitregistry") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitregistry"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.Registry = x
+	}
+	if req.FormValue("bandwidthlimitrenterdownload") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitrenterdownload"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.RenterDownload = x
+	}
+	if req.FormValue("bandwidthlimitrenterupload") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitrenterupload"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.RenterUpload = x
+	}
+	if req.FormValue("bandwidthlimitrenterregistry") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("bandwidthlimitrenterregistry"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.BandwidthLimits.RenterRegistry = x
+	}
+
+	if req.FormValue("pricingengine") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("pricingengine"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.Enabled = x
+	}
+	if req.FormValue("pricingengineexchangerate") != "" {
+		settings.PricingEngine.ExchangeRate = req.FormValue("pricingengineexchangerate")
+	}
+	if req.FormValue("pricingenginetargetdownloadbandwidthprice") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("pricingenginetargetdownloadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.TargetDownloadBandwidthPrice = x
+	}
+	if req.FormValue("pricingenginetargetstorageprice") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("pricingenginetargetstorageprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.TargetStoragePrice = x
+	}
+	if req.FormValue("pricingenginetargetuploadbandwidthprice") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("pricingenginetargetuploadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.TargetUploadBandwidthPrice = x
+	}
+	if req.FormValue("pricingenginetargetbandwidth") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("pricingenginetargetbandwidth"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.TargetBandwidth = x
+	}
+	if req.FormValue("pricingenginemaxdownloadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("pricingenginemaxdownloadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.MaxDownloadBandwidthPrice = x
+	}
+	if req.FormValue("pricingenginemaxstorageprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("pricingenginemaxstorageprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.MaxStoragePrice = x
+	}
+	if req.FormValue("pricingenginemaxuploadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("pricingenginemaxuploadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.MaxUploadBandwidthPrice = x
+	}
+	if req.FormValue("pricingenginemaxpricechange") != "" {
+		var x float64
+		_, err := fmt.Sscan(req.FormValue("pricingenginemaxpricechange"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.MaxPriceChange = x
+	}
+	if req.FormValue("pricingengineupdateinterval") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("pricingengineupdateinterval"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.PricingEngine.UpdateInterval = time.Duration(x) * time.Second
+	}
+
 	// Validate the RPC, Sector Access, and Download Prices
 	minBaseRPCPrice := settings.MinBaseRPCPrice
 	maxBaseRPCPrice := settings.MaxBaseRPCPrice()
@@ -477,9 +904,24 @@ func hostAnnounceHandler(host modules.Host, w http.ResponseWriter, req *http.Req
 // storageHandler returns a bunch of information about storage management on
 // the host.
 func storageHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-	WriteJSON(w, StorageGET{
-		Folders: host.StorageFolders(),
-	})
+	sg := StorageGET{
+		Folders:    host.StorageFolders(),
+		Redundancy: host.StorageRedundancy(),
+	}
+	if op, exists := host.StorageFolderOperation(); exists {
+		sg.Operation = &op
+	}
+	WriteJSON(w, sg)
+}
+
+// storageFoldersCancelHandler cancels the running storage folder operation.
+func storageFoldersCancelHandler(host modules.Host, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	err := host.CancelStorageFolderOperation()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
 }
 
 // storageFoldersAddHandler adds a storage folder to the storage manager.
@@ -491,11 +933,30 @@ func storageFoldersAddHandler(host modules.Host, w http.ResponseWriter, req *htt
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
+	tier := modules.StorageFolderTier(req.FormValue("tier"))
+	if tier != "" {
+		if err := tier.Validate(); err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	err = host.AddStorageFolder(folderPath, folderSize)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
+	if tier != "" {
+		folderIndex, err := folderIndex(folderPath, host.StorageFolders())
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err = host.SetStorageFolderTier(uint16(folderIndex), tier)
+		if err != nil {
+			WriteError(w, Error{"storage folder added, but failed to set the tier: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	WriteSuccess(w)
 }
 
@@ -528,6 +989,118 @@ func storageFoldersResizeHandler(host modules.Host, w http.ResponseWriter, req *
 	WriteSuccess(w)
 }
 
+// storageFoldersTierHandler sets the tier of a storage folder in the storage
+// manager.
+func storageFoldersTierHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	folderPath := req.FormValue("path")
+	if folderPath == "" {
+		WriteError(w, Error{"path parameter is required"}, http.StatusBadRequest)
+		return
+	}
+
+	storageFolders := host.StorageFolders()
+	folderIndex, err := folderIndex(folderPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	tier := modules.StorageFolderTier(req.FormValue("tier"))
+	err = host.SetStorageFolderTier(uint16(folderIndex), tier)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// storageFoldersMigrateHandler starts moving all sectors of one storage folder
+// into another.
+func storageFoldersMigrateHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	sourcePath, destinationPath := req.FormValue("source"), req.FormValue("destination")
+	if sourcePath == "" || destinationPath == "" {
+		WriteError(w, Error{"source and destination parameters are required"}, http.StatusBadRequest)
+		return
+	}
+
+	storageFolders := host.StorageFolders()
+	source, err := folderIndex(sourcePath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{"invalid source: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	destination, err := folderIndex(destinationPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{"invalid destination: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	var removeSource bool
+	if rs := req.FormValue("removesource"); rs != "" {
+		removeSource, err = strconv.ParseBool(rs)
+		if err != nil {
+			WriteError(w, Error{"unable to parse removesource: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	maxBandwidth, err := parseMaxBandwidth(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = host.MigrateStorageFolder(uint16(source), uint16(destination), removeSource, maxBandwidth)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// storageFoldersRebalanceHandler starts evening out the free space of the
+// storage folders.
+func storageFoldersRebalanceHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	maxBandwidth, err := parseMaxBandwidth(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = host.RebalanceStorageFolders(maxBandwidth)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// storageRedundancyHandlerPOST sets the local redundancy mode of the storage
+// manager.
+func storageRedundancyHandlerPOST(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	mode := modules.StorageRedundancyMode(req.FormValue("mode"))
+	if mode == "" {
+		WriteError(w, Error{"mode parameter is required"}, http.StatusBadRequest)
+		return
+	}
+	err := host.SetStorageRedundancy(mode)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// parseMaxBandwidth parses the optional maxbandwidth parameter of a storage
+// folder operation.
+func parseMaxBandwidth(req *http.Request) (uint64, error) {
+	var maxBandwidth uint64
+	if mb := req.FormValue("maxbandwidth"); mb != "" {
+		_, err := fmt.Sscan(mb, &maxBandwidth)
+		if err != nil {
+			return 0, fmt.Errorf("unable to parse maxbandwidth: %v", err)
+		}
+	}
+	return maxBandwidth, nil
+}
+
 // storageFoldersRemoveHandler removes a storage folder from the storage
 // manager.
 func storageFoldersRemoveHandler(host modules.Host, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
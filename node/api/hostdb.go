@@ -50,6 +50,11 @@ type (
 		NetAddresses []string `json:"netaddresses"`
 	}
 
+	// HostdbPolicyGET contains the hostdb's host selection policy.
+	HostdbPolicyGET struct {
+		Policy modules.HostSelectionPolicy `json:"policy"`
+	}
+
 	// HostdbFilterModePOST contains the information needed to set the the
 	// FilterMode of the hostDB
 	HostdbFilterModePOST struct {
@@ -211,3 +216,38 @@ func (api *API) hostdbFilterModeHandlerPOST(w http.ResponseWriter, req *http.Req
 	}
 	WriteSuccess(w)
 }
+
+// hostdbPolicyHandlerGET handles the API call to get the hostdb's host
+// selection policy.
+func (api *API) hostdbPolicyHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	policy, err := api.renter.HostSelectionPolicy()
+	if err != nil {
+		WriteError(w, Error{"unable to get host selection policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostdbPolicyGET{
+		Policy: policy,
+	})
+}
+
+// hostdbPolicyHandlerPOST handles the API call to set the hostdb's host
+// selection policy. Fields which are omitted from the request keep their
+// current value.
+func (api *API) hostdbPolicyHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	policy, err := api.renter.HostSelectionPolicy()
+	if err != nil {
+		WriteError(w, Error{"unable to get host selection policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Parse parameters on top of the current policy.
+	err = json.NewDecoder(req.Body).Decode(&policy)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.SetHostSelectionPolicy(policy); err != nil {
+		WriteError(w, Error{"failed to set the host selection policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,484 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/types"
+)
+
+const (
+	// metricsContentType is the content type of the Prometheus text
+	// exposition format.
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+	// metricsNamespace is the prefix of all metric names.
+	metricsNamespace = "siad_"
+)
+
+const (
+	metricTypeCounter   = "counter"
+	metricTypeGauge     = "gauge"
+	metricTypeHistogram = "histogram"
+)
+
+var (
+	// requestDurationBuckets are the upper bounds of the histogram buckets of
+	// the API request durations in seconds.
+	requestDurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+	// workerJobBuckets are the upper bounds of the histogram buckets of the
+	// average job times of the renter's workers in seconds.
+	workerJobBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+)
+
+type (
+	// metricLabel is a label of a metric sample.
+	metricLabel struct {
+		name  string
+		value string
+	}
+
+	// metricsWriter writes metrics in the Prometheus text exposition format.
+	metricsWriter struct {
+		buf bytes.Buffer
+	}
+
+	// histogram is a Prometheus histogram with fixed buckets.
+	histogram struct {
+		bounds []float64
+		counts []uint64
+		count  uint64
+		sum    float64
+	}
+
+	// requestMetrics collects metrics of the requests served by the API.
+	requestMetrics struct {
+		// requests counts the requests by method and status code.
+		requests map[[2]string]uint64
+
+		// durations contains the durations of the requests by method.
+		durations map[string]*histogram
+
+		mu sync.Mutex
+	}
+
+	// statusResponseWriter is a http.ResponseWriter which remembers the status
+	// code of the response.
+	statusResponseWriter struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// label creates a metricLabel.
+func label(name, value string) metricLabel {
+	return metricLabel{name: name, value: value}
+}
+
+// newHistogram creates a histogram with the given bucket bounds.
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)),
+	}
+}
+
+// observe adds a value to the histogram.
+func (h *histogram) observe(v float64) {
+	for i, bound := range h.bounds {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// formatMetricValue formats a value of a metric sample.
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabelValue escapes a label value of a metric sample.
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// header writes the HELP and TYPE lines of a metric.
+func (mw *metricsWriter) header(name, typ, help string) {
+	fmt.Fprintf(&mw.buf, "# HELP %s%s %s\n", metricsNamespace, name, help)
+	fmt.Fprintf(&mw.buf, "# TYPE %s%s %s\n", metricsNamespace, name, typ)
+}
+
+// sample writes a single sample of a metric.
+func (mw *metricsWriter) sample(name string, v float64, labels ...metricLabel) {
+	mw.buf.WriteString(metricsNamespace + name)
+	if len(labels) > 0 {
+		mw.buf.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				mw.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&mw.buf, `%s="%s"`, l.name, escapeLabelValue(l.value))
+		}
+		mw.buf.WriteByte('}')
+	}
+	mw.buf.WriteString(" " + formatMetricValue(v) + "\n")
+}
+
+// gauge writes a gauge with a single sample.
+func (mw *metricsWriter) gauge(name, help string, v float64) {
+	mw.header(name, metricTypeGauge, help)
+	mw.sample(name, v)
+}
+
+// counter writes a counter with a single sample.
+func (mw *metricsWriter) counter(name, help string, v float64) {
+	mw.header(name, metricTypeCounter, help)
+	mw.sample(name, v)
+}
+
+// histogram writes the samples of a histogram.
+func (mw *metricsWriter) histogram(name string, h *histogram, labels ...metricLabel) {
+	for i, bound := range h.bounds {
+		mw.sample(name+"_bucket", float64(h.counts[i]), append(labels, label("le", formatMetricValue(bound)))...)
+	}
+	mw.sample(name+"_bucket", float64(h.count), append(labels, label("le", "+Inf"))...)
+	mw.sample(name+"_sum", h.sum, labels...)
+	mw.sample(name+"_count", float64(h.count), labels...)
+}
+
+// currencyValue converts a currency to a metric value.
+func currencyValue(c types.Currency) float64 {
+	f, _ := c.Float64()
+	return f
+}
+
+// boolValue converts a bool to a metric value.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// newRequestMetrics creates a new requestMetrics.
+func newRequestMetrics() *requestMetrics {
+	return &requestMetrics{
+		requests:  make(map[[2]string]uint64),
+		durations: make(map[string]*histogram),
+	}
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *statusResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements io.Writer.
+func (w *statusResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *statusResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// instrument is middleware that records the number and duration of the
+// requests to the API.
+func (rm *requestMetrics) instrument(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		sw := &statusResponseWriter{ResponseWriter: w}
+		h.ServeHTTP(sw, req)
+		if sw.status == 0 {
+			sw.status = http.StatusOK
+		}
+		rm.record(req.Method, sw.status, time.Since(start))
+	})
+}
+
+// record records a request. Methods which the API doesn't use are recorded as
+// "other" to limit the number of samples.
+func (rm *requestMetrics) record(method string, status int, d time.Duration) {
+	if method != http.MethodGet && method != http.MethodPost {
+		method = "other"
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.requests[[2]string{method, strconv.Itoa(status)}]++
+	h, ok := rm.durations[method]
+	if !ok {
+		h = newHistogram(requestDurationBuckets)
+		rm.durations[method] = h
+	}
+	h.observe(d.Seconds())
+}
+
+// write writes the request metrics.
+func (rm *requestMetrics) write(mw *metricsWriter) {
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+
+	keys := make([][2]string, 0, len(rm.requests))
+	for k := range rm.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	mw.header("api_requests_total", metricTypeCounter, "Number of API requests by method and status code.")
+	for _, k := range keys {
+		mw.sample("api_requests_total", float64(rm.requests[k]), label("method", k[0]), label("code", k[1]))
+	}
+
+	methods := make([]string, 0, len(rm.durations))
+	for method := range rm.durations {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+	mw.header("api_request_duration_seconds", metricTypeHistogram, "Duration of API requests by method.")
+	for _, method := range methods {
+		mw.histogram("api_request_duration_seconds", rm.durations[method], label("method", method))
+	}
+}
+
+// writeDaemonMetrics writes the metrics of the daemon process.
+func (api *API) writeDaemonMetrics(mw *metricsWriter) {
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	mw.gauge("uptime_seconds", "Time since the API was started.", time.Since(api.staticStartTime).Seconds())
+	mw.gauge("go_goroutines", "Number of goroutines.", float64(runtime.NumGoroutine()))
+	mw.gauge("go_heap_alloc_bytes", "Bytes of allocated heap objects.", float64(ms.HeapAlloc))
+	mw.gauge("go_sys_bytes", "Bytes of memory obtained from the OS.", float64(ms.Sys))
+	api.staticRequestMetrics.write(mw)
+}
+
+// writeConsensusMetrics writes the metrics of the consensus set.
+func (api *API) writeConsensusMetrics(mw *metricsWriter) {
+	mw.gauge("consensus_height", "Height of the current block.", float64(api.cs.Height()))
+	mw.gauge("consensus_synced", "Whether the consensus set is synced.", boolValue(api.cs.Synced()))
+}
+
+// writeGatewayMetrics writes the metrics of the gateway.
+func (api *API) writeGatewayMetrics(mw *metricsWriter) {
+	var inbound, outbound int
+	for _, p := range api.gateway.Peers() {
+		if p.Inbound {
+			inbound++
+		} else {
+			outbound++
+		}
+	}
+	mw.header("gateway_peers", metricTypeGauge, "Number of connected peers by direction.")
+	mw.sample("gateway_peers", float64(inbound), label("direction", "inbound"))
+	mw.sample("gateway_peers", float64(outbound), label("direction", "outbound"))
+
+	upload, download, _, err := api.gateway.BandwidthCounters()
+	if err != nil {
+		return
+	}
+	mw.counter("gateway_upload_bytes_total", "Bytes uploaded by the gateway.", float64(upload))
+	mw.counter("gateway_download_bytes_total", "Bytes downloaded by the gateway.", float64(download))
+}
+
+// writeHostMetrics writes the metrics of the host.
+func (api *API) writeHostMetrics(mw *metricsWriter) {
+	nm := api.host.NetworkMetrics()
+	mw.header("host_rpc_calls_total", metricTypeCounter, "Number of RPC calls to the host by RPC.")
+	for _, c := range []struct {
+		rpc   string
+		calls uint64
+	}{
+		{"download", nm.DownloadCalls},
+		{"error", nm.ErrorCalls},
+		{"formcontract", nm.FormContractCalls},
+		{"renew", nm.RenewCalls},
+		{"revise", nm.ReviseCalls},
+		{"settings", nm.SettingsCalls},
+		{"unrecognized", nm.UnrecognizedCalls},
+	} {
+		mw.sample("host_rpc_calls_total", float64(c.calls), label("rpc", c.rpc))
+	}
+
+	fm := api.host.FinancialMetrics()
+	mw.gauge("host_contracts", "Number of active contracts of the host.", float64(fm.ContractCount))
+	mw.header("host_revenue_hastings_total", metricTypeCounter, "Revenue of the host by source.")
+	mw.sample("host_revenue_hastings_total", currencyValue(fm.AccountFunding), label("source", "account"))
+	mw.sample("host_revenue_hastings_total", currencyValue(fm.ContractCompensation), label("source", "contract"))
+	mw.sample("host_revenue_hastings_total", currencyValue(fm.DownloadBandwidthRevenue), label("source", "download"))
+	mw.sample("host_revenue_hastings_total", currencyValue(fm.StorageRevenue), label("source", "storage"))
+	mw.sample("host_revenue_hastings_total", currencyValue(fm.UploadBandwidthRevenue), label("source", "upload"))
+	mw.header("host_potential_revenue_hastings", metricTypeGauge, "Revenue of the host which is not yet realized by source.")
+	mw.sample("host_potential_revenue_hastings", currencyValue(fm.PotentialAccountFunding), label("source", "account"))
+	mw.sample("host_potential_revenue_hastings", currencyValue(fm.PotentialContractCompensation), label("source", "contract"))
+	mw.sample("host_potential_revenue_hastings", currencyValue(fm.PotentialDownloadBandwidthRevenue), label("source", "download"))
+	mw.sample("host_potential_revenue_hastings", currencyValue(fm.PotentialStorageRevenue), label("source", "storage"))
+	mw.sample("host_potential_revenue_hastings", currencyValue(fm.PotentialUploadBandwidthRevenue), label("source", "upload"))
+	mw.counter("host_lost_revenue_hastings_total", "Revenue the host lost because of failed storage proofs.", currencyValue(fm.LostRevenue))
+	mw.gauge("host_locked_collateral_hastings", "Collateral locked in the host's contracts.", currencyValue(fm.LockedStorageCollateral))
+	mw.gauge("host_risked_collateral_hastings", "Collateral risked by the host's contracts.", currencyValue(fm.RiskedStorageCollateral))
+	mw.counter("host_lost_collateral_hastings_total", "Collateral the host lost because of failed storage proofs.", currencyValue(fm.LostStorageCollateral))
+
+	eas := api.host.EphemeralAccountsSummary()
+	mw.gauge("host_ephemeral_accounts", "Number of ephemeral accounts on the host.", float64(eas.Accounts))
+	mw.gauge("host_ephemeral_account_balance_hastings", "Total balance of the ephemeral accounts on the host.", currencyValue(eas.TotalBalance))
+	mw.gauge("host_ephemeral_account_risk_hastings", "Outstanding risk of the host's ephemeral accounts which is not persisted yet.", currencyValue(eas.CurrentRisk))
+	mw.gauge("host_ephemeral_account_max_risk_hastings", "Max risk of the host's ephemeral accounts.", currencyValue(eas.MaxRisk))
+
+	var capacity, remaining, failedReads, failedWrites uint64
+	for _, sf := range api.host.StorageFolders() {
+		capacity += sf.Capacity
+		remaining += sf.CapacityRemaining
+		failedReads += sf.FailedReads
+		failedWrites += sf.FailedWrites
+	}
+	mw.gauge("host_storage_capacity_bytes", "Capacity of the host's storage folders.", float64(capacity))
+	mw.gauge("host_storage_remaining_bytes", "Remaining capacity of the host's storage folders.", float64(remaining))
+	mw.header("host_storage_failures_total", metricTypeCounter, "Number of failed operations of the host's storage folders.")
+	mw.sample("host_storage_failures_total", float64(failedReads), label("operation", "read"))
+	mw.sample("host_storage_failures_total", float64(failedWrites), label("operation", "write"))
+}
+
+// writeRenterMetrics writes the metrics of the renter.
+func (api *API) writeRenterMetrics(mw *metricsWriter) {
+	if dirs, err := api.renter.DirList(modules.RootSiaPath()); err == nil && len(dirs) > 0 {
+		root := dirs[0]
+		mw.gauge("renter_health", "Aggregate health of the renter's files, 0 is full health.", root.AggregateHealth)
+		mw.gauge("renter_stuck_health", "Aggregate health of the renter's stuck chunks.", root.AggregateStuckHealth)
+		mw.gauge("renter_min_redundancy", "Minimum redundancy of the renter's files.", root.AggregateMinRedundancy)
+		mw.gauge("renter_files", "Number of the renter's files.", float64(root.AggregateNumFiles))
+		mw.gauge("renter_files_bytes", "Size of the renter's files.", float64(root.AggregateSize))
+		mw.gauge("renter_repair_bytes", "Bytes of the renter's files which need to be repaired.", float64(root.AggregateRepairSize))
+		mw.gauge("renter_stuck_chunks", "Number of the renter's stuck chunks.", float64(root.AggregateNumStuckChunks))
+	}
+
+	if ms, err := api.renter.MemoryStatus(); err == nil {
+		managers := []struct {
+			name   string
+			status modules.MemoryManagerStatus
+		}{
+			{"registry", ms.Registry},
+			{"system", ms.System},
+			{"userdownload", ms.UserDownload},
+			{"userupload", ms.UserUpload},
+		}
+		for _, metric := range []struct {
+			name, help string
+			value      func(modules.MemoryManagerStatus) uint64
+		}{
+			{"renter_memory_available_bytes", "Available memory of the renter's memory managers.", func(s modules.MemoryManagerStatus) uint64 { return s.Available }},
+			{"renter_memory_base_bytes", "Base memory of the renter's memory managers.", func(s modules.MemoryManagerStatus) uint64 { return s.Base }},
+			{"renter_memory_requested_bytes", "Requested memory of the renter's memory managers.", func(s modules.MemoryManagerStatus) uint64 { return s.Requested }},
+		} {
+			mw.header(metric.name, metricTypeGauge, metric.help)
+			for _, m := range managers {
+				mw.sample(metric.name, float64(metric.value(m.status)), label("manager", m.name))
+			}
+		}
+	}
+
+	wps, err := api.renter.WorkerPoolStatus()
+	if err != nil {
+		return
+	}
+	mw.gauge("renter_workers", "Number of the renter's workers.", float64(wps.NumWorkers))
+	mw.header("renter_workers_on_cooldown", metricTypeGauge, "Number of the renter's workers on cooldown by type.")
+	mw.sample("renter_workers_on_cooldown", float64(wps.TotalDownloadCoolDown), label("type", "download"))
+	mw.sample("renter_workers_on_cooldown", float64(wps.TotalMaintenanceCoolDown), label("type", "maintenance"))
+	mw.sample("renter_workers_on_cooldown", float64(wps.TotalUploadCoolDown), label("type", "upload"))
+
+	var downloads, uploads, downloadSnapshots, uploadSnapshots, reads, hasSectors uint64
+	readTimes := newHistogram(workerJobBuckets)
+	hasSectorTimes := newHistogram(workerJobBuckets)
+	for _, w := range wps.Workers {
+		downloads += uint64(w.DownloadQueueSize)
+		uploads += uint64(w.UploadQueueSize)
+		downloadSnapshots += uint64(w.DownloadSnapshotJobQueueSize)
+		uploadSnapshots += uint64(w.UploadSnapshotJobQueueSize)
+		reads += w.ReadJobsStatus.JobQueueSize
+		hasSectors += w.HasSectorJobsStatus.JobQueueSize
+		// Workers without completed jobs don't have an average job time.
+		if w.ReadJobsStatus.AvgJobTime64k > 0 {
+			readTimes.observe(float64(w.ReadJobsStatus.AvgJobTime64k) / 1e3)
+		}
+		if w.HasSectorJobsStatus.AvgJobTime > 0 {
+			hasSectorTimes.observe(float64(w.HasSectorJobsStatus.AvgJobTime) / 1e3)
+		}
+	}
+	mw.header("renter_worker_queue_jobs", metricTypeGauge, "Number of jobs queued by the renter's workers by queue.")
+	mw.sample("renter_worker_queue_jobs", float64(downloads), label("queue", "download"))
+	mw.sample("renter_worker_queue_jobs", float64(downloadSnapshots), label("queue", "downloadsnapshot"))
+	mw.sample("renter_worker_queue_jobs", float64(hasSectors), label("queue", "hassector"))
+	mw.sample("renter_worker_queue_jobs", float64(reads), label("queue", "read"))
+	mw.sample("renter_worker_queue_jobs", float64(uploads), label("queue", "upload"))
+	mw.sample("renter_worker_queue_jobs", float64(uploadSnapshots), label("queue", "uploadsnapshot"))
+	mw.header("renter_worker_job_seconds", metricTypeHistogram, "Distribution of the average job times of the renter's workers by job.")
+	mw.histogram("renter_worker_job_seconds", hasSectorTimes, label("job", "hassector"))
+	mw.histogram("renter_worker_job_seconds", readTimes, label("job", "read64k"))
+}
+
+// writeTpoolMetrics writes the metrics of the transaction pool.
+func (api *API) writeTpoolMetrics(mw *metricsWriter) {
+	mw.gauge("tpool_transactions", "Number of transactions in the transaction pool.", float64(len(api.tpool.TransactionList())))
+	minFee, maxFee := api.tpool.FeeEstimation()
+	mw.header("tpool_fee_hastings_per_byte", metricTypeGauge, "Recommended transaction fee by bound.")
+	mw.sample("tpool_fee_hastings_per_byte", currencyValue(minFee), label("bound", "min"))
+	mw.sample("tpool_fee_hastings_per_byte", currencyValue(maxFee), label("bound", "max"))
+}
+
+// metricsHandlerGET handles the API call that returns the metrics of siad and
+// its modules in the Prometheus text exposition format.
+func (api *API) metricsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	var mw metricsWriter
+	api.writeDaemonMetrics(&mw)
+	if api.cs != nil {
+		api.writeConsensusMetrics(&mw)
+	}
+	if api.gateway != nil {
+		api.writeGatewayMetrics(&mw)
+	}
+	if api.host != nil {
+		api.writeHostMetrics(&mw)
+	}
+	if api.renter != nil {
+		api.writeRenterMetrics(&mw)
+	}
+	if api.tpool != nil {
+		api.writeTpoolMetrics(&mw)
+	}
+	w.Header().Set("Content-Type", metricsContentType)
+	_, _ = w.Write(mw.buf.Bytes())
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestMetricsWriter tests the output of the metricsWriter.
+func TestMetricsWriter(t *testing.T) {
+	h := newHistogram([]float64{0.1, 1})
+	h.observe(0.05)
+	h.observe(0.5)
+	h.observe(2)
+
+	var mw metricsWriter
+	mw.gauge("foo", "Foo.", 1.5)
+	mw.header("bar_seconds", metricTypeHistogram, "Bar.")
+	mw.histogram("bar_seconds", h, label("name", "a\"b"))
+
+	expected := `# HELP siad_foo Foo.
+# TYPE siad_foo gauge
+siad_foo 1.5
+# HELP siad_bar_seconds Bar.
+# TYPE siad_bar_seconds histogram
+siad_bar_seconds_bucket{name="a\"b",le="0.1"} 1
+siad_bar_seconds_bucket{name="a\"b",le="1"} 2
+siad_bar_seconds_bucket{name="a\"b",le="+Inf"} 3
+siad_bar_seconds_sum{name="a\"b"} 2.55
+siad_bar_seconds_count{name="a\"b"} 3
+`
+	if out := mw.buf.String(); out != expected {
+		t.Fatalf("unexpected output:\n%v\nexpected:\n%v", out, expected)
+	}
+}
+
+// TestRequestMetrics tests recording API requests.
+func TestRequestMetrics(t *testing.T) {
+	rm := newRequestMetrics()
+	rm.record("GET", 200, 0)
+	rm.record("GET", 200, 0)
+	rm.record("POST", 400, 0)
+	rm.record("PROPFIND", 404, 0)
+
+	var mw metricsWriter
+	rm.write(&mw)
+	out := mw.buf.String()
+	for _, sample := range []string{
+		`siad_api_requests_total{method="GET",code="200"} 2`,
+		`siad_api_requests_total{method="POST",code="400"} 1`,
+		`siad_api_requests_total{method="other",code="404"} 1`,
+		`siad_api_request_duration_seconds_count{method="GET"} 2`,
+	} {
+		if !strings.Contains(out, sample+"\n") {
+			t.Errorf("output is missing %q:\n%v", sample, out)
+		}
+	}
+}
+
+// TestRequestMetricsFlush tests that instrumented handlers can still flush
+// their responses.
+func TestRequestMetricsFlush(t *testing.T) {
+	rm := newRequestMetrics()
+	h := rm.instrument(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		f, ok := w.(http.Flusher)
+		if !ok {
+			t.Fatal("response writer should implement http.Flusher")
+		}
+		f.Flush()
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !rec.Flushed {
+		t.Fatal("flush wasn't passed on")
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -145,6 +146,30 @@ type (
 		BadContract bool `json:"badcontract"`
 	}
 
+	// RenterVerifyGET contains the trust scores of the hosts that were
+	// sampled by previous verifications.
+	RenterVerifyGET struct {
+		Hosts []modules.HostVerification `json:"hosts"`
+	}
+
+	// RenterBulkPOST contains the operations of a bulk request against the
+	// renter's filesystem.
+	RenterBulkPOST struct {
+		Operations []modules.BulkOperation `json:"operations"`
+
+		// Root indicates whether the siapaths of the operations are relative
+		// to the root instead of the user folder.
+		Root bool `json:"root"`
+	}
+
+	// RenterBulkResults contains the results of a bulk request against the
+	// renter's filesystem.
+	RenterBulkResults struct {
+		Results   []modules.BulkOperationResult `json:"results"`
+		Succeeded uint64                        `json:"succeeded"`
+		Failed    uint64                        `json:"failed"`
+	}
+
 	// RenterContracts contains the renter's contracts.
 	RenterContracts struct {
 		// Compatibility Fields
@@ -161,6 +186,12 @@ type (
 		RecoverableContracts      []modules.RecoverableContract `json:"recoverablecontracts"`
 	}
 
+	// RenterContractEvents contains the events from the renter's contract
+	// event journal.
+	RenterContractEvents struct {
+		Events []modules.ContractEvent `json:"events"`
+	}
+
 	// RenterDirectory lists the files and directories contained in the queried
 	// directory
 	RenterDirectory struct {
@@ -210,6 +241,18 @@ type (
 		ASCIIsia string `json:"asciisia"`
 	}
 
+	// RenterTenants lists the renter's tenants.
+	RenterTenants struct {
+		Tenants []modules.TenantInfo `json:"tenants"`
+	}
+
+	// RenterTenantsCreatePOST contains the token of a newly created tenant.
+	RenterTenantsCreatePOST struct {
+		Name  string          `json:"name"`
+		Root  modules.SiaPath `json:"root"`
+		Token string          `json:"token"`
+	}
+
 	// RenterUploadedBackup describes an uploaded backup.
 	RenterUploadedBackup struct {
 		Name           string          `json:"name"`
@@ -275,22 +318,32 @@ func isCalledWithRootFlag(req *http.Request) (bool, error) {
 	return root, nil
 }
 
+// homeFolder returns the folder the siapaths of a request are relative to.
+// That is the root directory of the tenant for requests by tenants and the
+// user folder otherwise.
+func homeFolder(req *http.Request) modules.SiaPath {
+	if ti, ok := tenantFromRequest(req); ok {
+		return ti.Root
+	}
+	return modules.UserFolder
+}
+
 // rebaseInputSiaPath rebases the SiaPath provided by the user to one that is
 // prefixed by the user's home directory.
-func rebaseInputSiaPath(siaPath modules.SiaPath) (modules.SiaPath, error) {
-	// Prepend the provided siapath with the /home/siafiles dir.
+func rebaseInputSiaPath(req *http.Request, siaPath modules.SiaPath) (modules.SiaPath, error) {
+	// Prepend the provided siapath with the home dir.
 	if siaPath.IsRoot() {
-		return modules.UserFolder, nil
+		return homeFolder(req), nil
 	}
-	return modules.UserFolder.Join(siaPath.String())
+	return homeFolder(req).Join(siaPath.String())
 }
 
 // trimSiaDirFolder is a helper method to trim /home/siafiles off of the
 // siapaths of the dirinfos since the user expects a path relative to
 // /home/siafiles and not relative to root.
-func trimSiaDirFolder(dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo, err error) {
+func trimSiaDirFolder(req *http.Request, dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo, err error) {
 	for i := range dis {
-		dis[i].SiaPath, err = dis[i].SiaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+		dis[i].SiaPath, err = dis[i].SiaPath.Rebase(homeFolder(req), modules.RootSiaPath())
 		if err != nil {
 			return nil, err
 		}
@@ -301,9 +354,9 @@ func trimSiaDirFolder(dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo,
 // trimSiaDirFolderOnFiles is a helper method to trim /home/siafiles off of the
 // siapaths of the fileinfos since the user expects a path relative to
 // /home/siafiles and not relative to root.
-func trimSiaDirFolderOnFiles(fis ...modules.FileInfo) (_ []modules.FileInfo, err error) {
+func trimSiaDirFolderOnFiles(req *http.Request, fis ...modules.FileInfo) (_ []modules.FileInfo, err error) {
 	for i := range fis {
-		fis[i].SiaPath, err = fis[i].SiaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+		fis[i].SiaPath, err = fis[i].SiaPath.Rebase(homeFolder(req), modules.RootSiaPath())
 		if err != nil {
 			return nil, errors.AddContext(err, "unable to trim the user sia path from a provided fileinfo")
 		}
@@ -833,6 +886,54 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		}
 		settings.Allowance.MaxUploadBandwidthPrice = price
 	}
+	if str := req.FormValue("maxuploadspending"); str != "" {
+		spending, ok := scanAmount(str)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxuploadspending"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxUploadSpending = spending
+	}
+	if str := req.FormValue("maxdownloadspending"); str != "" {
+		spending, ok := scanAmount(str)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxdownloadspending"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxDownloadSpending = spending
+	}
+	if str := req.FormValue("maxstoragespending"); str != "" {
+		spending, ok := scanAmount(str)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxstoragespending"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxStorageSpending = spending
+	}
+	if str := req.FormValue("maxfeespending"); str != "" {
+		spending, ok := scanAmount(str)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxfeespending"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxFeeSpending = spending
+	}
+	if str := req.FormValue("maxfundaccountspending"); str != "" {
+		spending, ok := scanAmount(str)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxfundaccountspending"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MaxFundAccountSpending = spending
+	}
+	if str := req.FormValue("spendingalertthreshold"); str != "" {
+		var threshold float64
+		if _, err := fmt.Sscan(str, &threshold); err != nil {
+			WriteError(w, Error{"unable to parse spendingalertthreshold: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.SpendingAlertThreshold = threshold
+	}
 
 	// Validate any allowance changes. Funds and Period are the only required
 	// fields.
@@ -949,6 +1050,16 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.MaxUploadSpeed = uploadSpeed
 	}
 
+	// Scan the chunk cache size. (optional parameter)
+	if c := req.FormValue("chunkcachesize"); c != "" {
+		var chunkCacheSize uint64
+		if _, err := fmt.Sscan(c, &chunkCacheSize); err != nil {
+			WriteError(w, Error{"unable to parse chunkcachesize: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.ChunkCacheSize = chunkCacheSize
+	}
+
 	// Scan the checkforipviolation flag.
 	if ipc := req.FormValue("checkforipviolation"); ipc != "" {
 		var ipviolationcheck bool
@@ -1262,6 +1373,53 @@ func (api *API) renterClearDownloadsHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// renterContractEventsHandler handles the API call to request the events from
+// the renter's contract event journal.
+func (api *API) renterContractEventsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var filter modules.ContractEventFilter
+	if eventTypes := req.FormValue("type"); eventTypes != "" {
+		for _, t := range strings.Split(eventTypes, ",") {
+			filter.Types = append(filter.Types, modules.ContractEventType(strings.TrimSpace(t)))
+		}
+	}
+	if id := req.FormValue("id"); id != "" {
+		if err := filter.ContractID.LoadString(id); err != nil {
+			WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if host := req.FormValue("host"); host != "" {
+		if err := filter.HostPublicKey.LoadString(host); err != nil {
+			WriteError(w, Error{"unable to parse host: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if minHeight := req.FormValue("minheight"); minHeight != "" {
+		if _, err := fmt.Sscan(minHeight, &filter.MinHeight); err != nil {
+			WriteError(w, Error{"unable to parse minheight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if maxHeight := req.FormValue("maxheight"); maxHeight != "" {
+		if _, err := fmt.Sscan(maxHeight, &filter.MaxHeight); err != nil {
+			WriteError(w, Error{"unable to parse maxheight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if limit := req.FormValue("limit"); limit != "" {
+		if _, err := fmt.Sscan(limit, &filter.Limit); err != nil {
+			WriteError(w, Error{"unable to parse limit: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	events, err := api.renter.ContractEvents(filter)
+	if err != nil {
+		WriteError(w, Error{"unable to get contract events: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractEvents{Events: events})
+}
+
 // renterContractorChurnStatus handles the API call to request the churn status
 // from the renter's contractor.
 func (api *API) renterContractorChurnStatus(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
@@ -1371,7 +1529,7 @@ func (api *API) renterFuseMountHandlerPOST(w http.ResponseWriter, req *http.Requ
 			return
 		}
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -1453,12 +1611,12 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
-		newSiaPath, err = rebaseInputSiaPath(newSiaPath)
+		newSiaPath, err = rebaseInputSiaPath(req, newSiaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1489,7 +1647,7 @@ func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, p
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1506,7 +1664,7 @@ func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, p
 	// If the user requested the user siapath, trim the dir folder so that the
 	// output is all centered around the user's folder.
 	if !root {
-		files, err := trimSiaDirFolderOnFiles(file)
+		files, err := trimSiaDirFolderOnFiles(req, file)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1534,7 +1692,7 @@ func (api *API) renterFileHandlerPOST(w http.ResponseWriter, req *http.Request,
 		return
 	}
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1559,9 +1717,38 @@ func (api *API) renterFileHandlerPOST(w http.ResponseWriter, req *http.Request,
 			return
 		}
 	}
+	// Handle re-encoding the file.
+	ec, ct, reencode, err := parseReencodeParameters(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if reencode {
+		if err := api.renter.ReencodeFile(siaPath, ec, ct); err != nil {
+			WriteError(w, Error{"failed to re-encode file: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+	}
 	WriteSuccess(w)
 }
 
+// parseReencodeParameters parses the optional 'datapieces', 'paritypieces'
+// and 'ciphertype' parameters of a re-encoding request. The returned bool
+// indicates whether any of them were set.
+func parseReencodeParameters(req *http.Request) (modules.ErasureCoder, crypto.CipherType, bool, error) {
+	ec, err := parseErasureCodingParameters(req.FormValue("datapieces"), req.FormValue("paritypieces"))
+	if err != nil {
+		return nil, crypto.TypeInvalid, false, errors.AddContext(err, "unable to parse erasure code settings")
+	}
+	ct := crypto.TypeInvalid
+	if cts := req.FormValue("ciphertype"); cts != "" {
+		if err := ct.FromString(cts); err != nil {
+			return nil, crypto.TypeInvalid, false, errors.AddContext(err, "unable to parse 'ciphertype'")
+		}
+	}
+	return ec, ct, ec != nil || ct != crypto.TypeInvalid, nil
+}
+
 // renterFilesHandler handles the API call to list all of the files.
 func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var c bool
@@ -1575,7 +1762,7 @@ func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ h
 	}
 	var files []modules.FileInfo
 	var mu sync.Mutex
-	err = api.renter.FileList(modules.UserFolder, true, c, func(fi modules.FileInfo) {
+	err = api.renter.FileList(homeFolder(req), true, c, func(fi modules.FileInfo) {
 		mu.Lock()
 		files = append(files, fi)
 		mu.Unlock()
@@ -1588,7 +1775,7 @@ func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ h
 	sort.Slice(files, func(i, j int) bool {
 		return files[i].SiaPath.String() < files[j].SiaPath.String()
 	})
-	files, err = trimSiaDirFolderOnFiles(files...)
+	files, err = trimSiaDirFolderOnFiles(req, files...)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
 		return
@@ -1697,7 +1884,7 @@ func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1713,6 +1900,78 @@ func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// renterBulkHandlerPOST handles the API call to execute a list of operations
+// against the renter's filesystem.
+func (api *API) renterBulkHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params RenterBulkPOST
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if len(params.Operations) == 0 {
+		WriteError(w, Error{"no operations provided"}, http.StatusBadRequest)
+		return
+	}
+	if token, ok := tokenFromRequest(req); ok && bulkUsesLocalPath(params.Operations) && !token.HasScope(ScopeLocalFiles) {
+		WriteError(w, Error{fmt.Sprintf("API token not authorized: paths on the local filesystem require the %v scope", ScopeLocalFiles)}, http.StatusForbidden)
+		return
+	}
+
+	// Rebase the user's input to the user folder if the user is requesting
+	// user siapaths.
+	if !params.Root {
+		for i := range params.Operations {
+			op := &params.Operations[i]
+			op.SiaPath, err = rebaseInputSiaPath(req, op.SiaPath)
+			if err != nil {
+				WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+				return
+			}
+			if op.Type != modules.BulkOperationRename {
+				continue
+			}
+			op.NewSiaPath, err = rebaseInputSiaPath(req, op.NewSiaPath)
+			if err != nil {
+				WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	results, err := api.renter.BulkOperations(params.Operations)
+	if err != nil {
+		WriteError(w, Error{"failed to execute bulk operations: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Trim the user folder from the results and count the failures.
+	var rbr RenterBulkResults
+	for _, result := range results {
+		if !params.Root {
+			result.SiaPath, err = result.SiaPath.Rebase(homeFolder(req), modules.RootSiaPath())
+			if err != nil {
+				WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+				return
+			}
+			if !result.NewSiaPath.IsRoot() {
+				result.NewSiaPath, err = result.NewSiaPath.Rebase(homeFolder(req), modules.RootSiaPath())
+				if err != nil {
+					WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+					return
+				}
+			}
+		}
+		if result.Error == "" {
+			rbr.Succeeded++
+		} else {
+			rbr.Failed++
+		}
+		rbr.Results = append(rbr.Results, result)
+	}
+	WriteJSON(w, rbr)
+}
+
 // renterCancelDownloadHandler handles the API call to cancel a download.
 func (api *API) renterCancelDownloadHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	// Get the id.
@@ -1833,6 +2092,10 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 	if err != nil {
 		return modules.RenterDownloadParameters{}, errors.AddContext(err, "httpresp parameter could not be parsed")
 	}
+	// Tenants can't write to the local filesystem of the renter.
+	if _, ok := tenantFromRequest(req); ok && !httpresp {
+		return modules.RenterDownloadParameters{}, errors.New("tenants can only download with httpresp set to true")
+	}
 
 	// Parse the async parameter.
 	async, err := scanBool(asyncparam)
@@ -1853,7 +2116,7 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 
 	// If root is not set we need to rebase the siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			return modules.RenterDownloadParameters{}, err
 		}
@@ -1896,7 +2159,7 @@ func (api *API) renterStreamHandler(w http.ResponseWriter, req *http.Request, ps
 		return
 	}
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1956,7 +2219,7 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -2087,7 +2350,7 @@ func (api *API) renterUploadStreamHandler(w http.ResponseWriter, req *http.Reque
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -2101,6 +2364,24 @@ func (api *API) renterUploadStreamHandler(w http.ResponseWriter, req *http.Reque
 		// NOTE: can make this an optional param.
 		CipherType: crypto.TypeDefaultRenter,
 	}
+	// Check whether a resumable upload should be created instead.
+	resumable := false
+	if r := queryForm.Get("resumable"); r != "" {
+		resumable, err = strconv.ParseBool(r)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'resumable' parameter: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if resumable {
+		info, err := api.renter.CreateResumableUpload(up)
+		if err != nil {
+			WriteError(w, Error{"failed to create resumable upload: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteJSON(w, info)
+		return
+	}
 	err = api.renter.UploadStreamFromReader(up, req.Body)
 	if err != nil {
 		WriteError(w, Error{"upload failed: " + err.Error()}, http.StatusInternalServerError)
@@ -2120,6 +2401,88 @@ func (api *API) renterValidateSiaPathHandler(w http.ResponseWriter, _ *http.Requ
 	WriteSuccess(w)
 }
 
+// renterVerifyHandlerGET handles the API call to get the trust scores of the
+// hosts that were sampled by previous verifications.
+func (api *API) renterVerifyHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	hosts, err := api.renter.HostTrustScores()
+	if err != nil {
+		WriteError(w, Error{"unable to get trust scores: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RenterVerifyGET{Hosts: hosts})
+}
+
+// renterVerifyHandlerPOST handles the API call to verify the integrity of a
+// file or a directory.
+func (api *API) renterVerifyHandlerPOST(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Check whether the user is verifying a path relative to the root.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Parse the 'recursive' parameter.
+	recursive := false
+	if r := req.FormValue("recursive"); r != "" {
+		recursive, err = strconv.ParseBool(r)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'recursive' parameter: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Parse the 'repair' parameter.
+	repair := false
+	if r := req.FormValue("repair"); r != "" {
+		repair, err = strconv.ParseBool(r)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'repair' parameter: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Parse the 'samples' parameter.
+	samples := uint64(modules.DefaultVerificationSamples)
+	if s := req.FormValue("samples"); s != "" {
+		samples, err = strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'samples' parameter: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Parse the siapath. An empty siapath refers to the root directory.
+	var siaPath modules.SiaPath
+	str := ps.ByName("siapath")
+	if recursive && (str == "" || str == "/") {
+		siaPath = modules.RootSiaPath()
+	} else {
+		siaPath, err = modules.NewSiaPath(str)
+	}
+	if err != nil {
+		WriteError(w, Error{"unable to parse siapath: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if !root {
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Verify the file or directory.
+	var report modules.VerificationReport
+	if recursive {
+		report, err = api.renter.VerifyDir(siaPath, samples, repair)
+	} else {
+		report, err = api.renter.VerifyFile(siaPath, samples, repair)
+	}
+	if err != nil {
+		WriteError(w, Error{"failed to verify: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, report)
+}
+
 // renterDirHandlerGET handles the API call to query a directory
 func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	var siaPath modules.SiaPath
@@ -2144,7 +2507,7 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2158,7 +2521,7 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		directories, err = trimSiaDirFolder(directories...)
+		directories, err = trimSiaDirFolder(req, directories...)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2178,7 +2541,7 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		files, err = trimSiaDirFolderOnFiles(files...)
+		files, err = trimSiaDirFolderOnFiles(req, files...)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2225,7 +2588,7 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2257,7 +2620,7 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 			WriteError(w, Error{"failed to parse newsiapath: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
-		newSiaPath, err = rebaseInputSiaPath(newSiaPath)
+		newSiaPath, err = rebaseInputSiaPath(req, newSiaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2270,6 +2633,24 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 		WriteSuccess(w)
 		return
 	}
+	if action == "reencode" {
+		ec, ct, reencode, err := parseReencodeParameters(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if !reencode {
+			WriteError(w, Error{"must provide 'datapieces' and 'paritypieces' or 'ciphertype' to re-encode"}, http.StatusBadRequest)
+			return
+		}
+		err = api.renter.ReencodeDir(siaPath, ec, ct)
+		if err != nil {
+			WriteError(w, Error{"failed to re-encode directory: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
 
 	// Report that no calls were made
 	WriteError(w, Error{"no calls were made, please check your submission and try again"}, http.StatusInternalServerError)
@@ -2306,6 +2687,16 @@ func (api *API) renterWorkersHandler(w http.ResponseWriter, _ *http.Request, _ h
 	WriteJSON(w, workerPoolStatus)
 }
 
+// renterChunkCacheHandlerGET handles the API call to /renter/chunkcache.
+func (api *API) renterChunkCacheHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	status, err := api.renter.ChunkCacheStatus()
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, status)
+}
+
 func (api *API) renterFileHostsHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	// Determine the siapath that the user wants to get the file from.
 	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
@@ -2322,7 +2713,7 @@ func (api *API) renterFileHostsHandler(w http.ResponseWriter, req *http.Request,
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2337,3 +2728,183 @@ func (api *API) renterFileHostsHandler(w http.ResponseWriter, req *http.Request,
 
 	WriteJSON(w, hosts)
 }
+
+// renterUploadResumableHandlerGET handles the API call to fetch the state of a
+// resumable upload.
+func (api *API) renterUploadResumableHandlerGET(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	info, err := api.renter.ResumableUpload(modules.UploadID(ps.ByName("uploadid")))
+	if errors.Contains(err, renter.ErrResumableUploadNotFound) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"failed to fetch resumable upload: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, info)
+}
+
+// renterUploadResumableHandlerPUT handles the API call to append the request
+// body to a resumable upload.
+func (api *API) renterUploadResumableHandlerPUT(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Parse the offset from the query string since the body contains the
+	// data.
+	queryForm, err := url.ParseQuery(req.URL.RawQuery)
+	if err != nil {
+		WriteError(w, Error{"failed to parse query params"}, http.StatusBadRequest)
+		return
+	}
+	offset, err := strconv.ParseUint(queryForm.Get("offset"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{"unable to parse 'offset' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	info, err := api.renter.ResumableUploadWrite(modules.UploadID(ps.ByName("uploadid")), offset, req.Body)
+	if errors.Contains(err, renter.ErrResumableUploadNotFound) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if errors.Contains(err, renter.ErrResumableUploadOffsetMismatch) {
+		WriteError(w, Error{err.Error()}, http.StatusConflict)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"failed to write to resumable upload: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, info)
+}
+
+// renterUploadResumableHandlerPOST handles the API call to finalize or abort a
+// resumable upload.
+func (api *API) renterUploadResumableHandlerPOST(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	id := modules.UploadID(ps.ByName("uploadid"))
+	var err error
+	switch action := req.FormValue("action"); action {
+	case "finalize":
+		err = api.renter.FinalizeResumableUpload(id)
+	case "abort":
+		err = api.renter.AbortResumableUpload(id)
+	default:
+		WriteError(w, Error{fmt.Sprintf("unknown action '%v', must be either 'finalize' or 'abort'", action)}, http.StatusBadRequest)
+		return
+	}
+	if errors.Contains(err, renter.ErrResumableUploadNotFound) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// parseTenantQuota parses the quota parameters of the tenant API calls. Quotas
+// which are not specified keep the value of the provided quota.
+func parseTenantQuota(req *http.Request, quota modules.TenantQuota) (modules.TenantQuota, error) {
+	params := []struct {
+		name  string
+		field *uint64
+	}{
+		{"storagequota", &quota.Storage},
+		{"uploadquota", &quota.Upload},
+		{"downloadquota", &quota.Download},
+	}
+	for _, p := range params {
+		v := req.FormValue(p.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return modules.TenantQuota{}, errors.AddContext(err, "unable to parse "+p.name)
+		}
+		*p.field = n
+	}
+	return quota, nil
+}
+
+// renterTenantHandlerGET handles the API call to request information about the
+// tenant which authenticated the request.
+func (api *API) renterTenantHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	authenticated, ok := tenantFromRequest(req)
+	if !ok {
+		WriteError(w, Error{"request wasn't authenticated by a tenant"}, http.StatusBadRequest)
+		return
+	}
+	ti, err := api.renter.Tenant(authenticated.Name)
+	if err != nil {
+		WriteError(w, Error{"unable to get tenant: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, ti)
+}
+
+// renterTenantsHandlerGET handles the API call to list the renter's tenants.
+func (api *API) renterTenantsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	tenants, err := api.renter.Tenants()
+	if err != nil {
+		WriteError(w, Error{"unable to get tenants: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RenterTenants{Tenants: tenants})
+}
+
+// renterTenantsCreateHandlerPOST handles the API call to create a tenant.
+func (api *API) renterTenantsCreateHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	quota, err := parseTenantQuota(req, modules.TenantQuota{})
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	token, err := api.renter.CreateTenant(name, quota)
+	if err != nil {
+		WriteError(w, Error{"unable to create tenant: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	ti, err := api.renter.Tenant(name)
+	if err != nil {
+		WriteError(w, Error{"unable to get tenant: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RenterTenantsCreatePOST{
+		Name:  ti.Name,
+		Root:  ti.Root,
+		Token: token,
+	})
+}
+
+// renterTenantsDeleteHandlerPOST handles the API call to delete a tenant.
+func (api *API) renterTenantsDeleteHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.renter.DeleteTenant(req.FormValue("name"))
+	if errors.Contains(err, modules.ErrUnknownTenant) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"unable to delete tenant: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTenantsQuotaHandlerPOST handles the API call to update the quota of a
+// tenant.
+func (api *API) renterTenantsQuotaHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	ti, err := api.renter.Tenant(name)
+	if errors.Contains(err, modules.ErrUnknownTenant) {
+		WriteError(w, Error{err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"unable to get tenant: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	quota, err := parseTenantQuota(req, ti.Quota)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.SetTenantQuota(name, quota); err != nil {
+		WriteError(w, Error{"unable to set tenant quota: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
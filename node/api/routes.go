@@ -2,13 +2,17 @@ package api
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
 
 	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
 )
 
 var (
@@ -45,6 +49,12 @@ func (api *API) buildHTTPRoutes() {
 	router.GET("/daemon/update", api.daemonUpdateHandlerGET)
 	router.POST("/daemon/update", api.daemonUpdateHandlerPOST)
 	router.GET("/daemon/version", api.daemonVersionHandler)
+	router.GET("/daemon/tokens", RequirePassword(api.daemonTokensHandlerGET, requiredPassword))
+	router.POST("/daemon/tokens/create", RequirePassword(api.daemonTokensCreateHandlerPOST, requiredPassword))
+	router.POST("/daemon/tokens/revoke", RequirePassword(api.daemonTokensRevokeHandlerPOST, requiredPassword))
+
+	// Metrics API Calls
+	router.GET("/metrics", RequirePassword(api.metricsHandlerGET, requiredPassword))
 
 	// Consensus API Calls
 	if api.cs != nil {
@@ -82,18 +92,21 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/renter", RequirePassword(api.renterHandlerPOST, requiredPassword))
 		router.POST("/renter/allowance/cancel", RequirePassword(api.renterAllowanceCancelHandlerPOST, requiredPassword))
 		router.POST("/renter/bubble", api.renterBubbleHandlerPOST)
+		router.POST("/renter/bulk", RequirePassword(api.renterBulkHandlerPOST, requiredPassword))
 		router.GET("/renter/backups", RequirePassword(api.renterBackupsHandlerGET, requiredPassword))
 		router.POST("/renter/backups/create", RequirePassword(api.renterBackupsCreateHandlerPOST, requiredPassword))
 		router.POST("/renter/backups/restore", RequirePassword(api.renterBackupsRestoreHandlerGET, requiredPassword))
 		router.POST("/renter/clean", RequirePassword(api.renterCleanHandlerPOST, requiredPassword))
 		router.POST("/renter/contract/cancel", RequirePassword(api.renterContractCancelHandler, requiredPassword))
+		router.GET("/renter/chunkcache", api.renterChunkCacheHandlerGET)
 		router.GET("/renter/contracts", api.renterContractsHandler)
+		router.GET("/renter/contracts/events", api.renterContractEventsHandler)
 		router.GET("/renter/contractorchurnstatus", api.renterContractorChurnStatus)
 		router.GET("/renter/downloadinfo/*uid", api.renterDownloadByUIDHandlerGET)
 		router.GET("/renter/downloads", api.renterDownloadsHandler)
 		router.POST("/renter/downloads/clear", RequirePassword(api.renterClearDownloadsHandler, requiredPassword))
-		router.GET("/renter/files", api.renterFilesHandler)
-		router.GET("/renter/file/*siapath", api.renterFileHandlerGET)
+		router.GET("/renter/files", api.RequireTenantOrPassword(api.renterFilesHandler, "", tenantTrafficNone))
+		router.GET("/renter/file/*siapath", api.RequireTenantOrPassword(api.renterFileHandlerGET, "", tenantTrafficNone))
 		router.POST("/renter/file/*siapath", RequirePassword(api.renterFileHandlerPOST, requiredPassword))
 		router.GET("/renter/prices", api.renterPricesHandler)
 		router.POST("/renter/recoveryscan", RequirePassword(api.renterRecoveryScanHandlerPOST, requiredPassword))
@@ -102,24 +115,34 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/renter/fuse/mount", RequirePassword(api.renterFuseMountHandlerPOST, requiredPassword))
 		router.POST("/renter/fuse/unmount", RequirePassword(api.renterFuseUnmountHandlerPOST, requiredPassword))
 
-		router.POST("/renter/delete/*siapath", RequirePassword(api.renterDeleteHandler, requiredPassword))
-		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
+		router.POST("/renter/delete/*siapath", api.RequireTenantOrPassword(api.renterDeleteHandler, requiredPassword, tenantTrafficNone))
+		router.GET("/renter/download/*siapath", api.RequireTenantOrPassword(api.renterDownloadHandler, requiredPassword, tenantTrafficDownload))
 		router.POST("/renter/download/cancel", RequirePassword(api.renterCancelDownloadHandler, requiredPassword))
 		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
-		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
-		router.GET("/renter/stream/*siapath", api.renterStreamHandler)
+		router.POST("/renter/rename/*siapath", api.RequireTenantOrPassword(api.renterRenameHandler, requiredPassword, tenantTrafficNone))
+		router.GET("/renter/stream/*siapath", api.RequireTenantOrPassword(api.renterStreamHandler, "", tenantTrafficDownload))
 		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
 		router.GET("/renter/uploadready", api.renterUploadReadyHandler)
+		router.GET("/renter/tenant", api.RequireTenantOrPassword(api.renterTenantHandlerGET, requiredPassword, tenantTrafficNone))
+		router.GET("/renter/tenants", RequirePassword(api.renterTenantsHandlerGET, requiredPassword))
+		router.POST("/renter/tenants/create", RequirePassword(api.renterTenantsCreateHandlerPOST, requiredPassword))
+		router.POST("/renter/tenants/delete", RequirePassword(api.renterTenantsDeleteHandlerPOST, requiredPassword))
+		router.POST("/renter/tenants/quota", RequirePassword(api.renterTenantsQuotaHandlerPOST, requiredPassword))
 		router.POST("/renter/uploads/pause", RequirePassword(api.renterUploadsPauseHandler, requiredPassword))
 		router.POST("/renter/uploads/resume", RequirePassword(api.renterUploadsResumeHandler, requiredPassword))
-		router.POST("/renter/uploadstream/*siapath", RequirePassword(api.renterUploadStreamHandler, requiredPassword))
+		router.GET("/renter/uploads/resumable/:uploadid", RequirePassword(api.renterUploadResumableHandlerGET, requiredPassword))
+		router.PUT("/renter/uploads/resumable/:uploadid", RequirePassword(api.renterUploadResumableHandlerPUT, requiredPassword))
+		router.POST("/renter/uploads/resumable/:uploadid", RequirePassword(api.renterUploadResumableHandlerPOST, requiredPassword))
+		router.POST("/renter/uploadstream/*siapath", api.RequireTenantOrPassword(api.renterUploadStreamHandler, requiredPassword, tenantTrafficUpload))
 		router.POST("/renter/validatesiapath/*siapath", RequirePassword(api.renterValidateSiaPathHandler, requiredPassword))
+		router.GET("/renter/verify", api.renterVerifyHandlerGET)
+		router.POST("/renter/verify/*siapath", RequirePassword(api.renterVerifyHandlerPOST, requiredPassword))
 		router.GET("/renter/workers", api.renterWorkersHandler)
 		router.GET("/renter/hosts/*siapath", api.renterFileHostsHandler)
 
 		// Directory endpoints
-		router.POST("/renter/dir/*siapath", RequirePassword(api.renterDirHandlerPOST, requiredPassword))
-		router.GET("/renter/dir/*siapath", api.renterDirHandlerGET)
+		router.POST("/renter/dir/*siapath", api.RequireTenantOrPassword(api.renterDirHandlerPOST, requiredPassword, tenantTrafficNone))
+		router.GET("/renter/dir/*siapath", api.RequireTenantOrPassword(api.renterDirHandlerGET, "", tenantTrafficNone))
 
 		// HostDB endpoints.
 		router.GET("/hostdb", api.hostdbHandler)
@@ -128,6 +151,8 @@ func (api *API) buildHTTPRoutes() {
 		router.GET("/hostdb/hosts/:pubkey", api.hostdbHostsHandler)
 		router.GET("/hostdb/filtermode", api.hostdbFilterModeHandlerGET)
 		router.POST("/hostdb/filtermode", RequirePassword(api.hostdbFilterModeHandlerPOST, requiredPassword))
+		router.GET("/hostdb/policy", api.hostdbPolicyHandlerGET)
+		router.POST("/hostdb/policy", RequirePassword(api.hostdbPolicyHandlerPOST, requiredPassword))
 
 		// Renter watchdog endpoints.
 		router.GET("/renter/contractstatus", api.renterContractStatusHandler)
@@ -149,7 +174,7 @@ func (api *API) buildHTTPRoutes() {
 
 	// Apply UserAgent middleware and return the Router
 	api.routerMu.Lock()
-	api.router = timeoutHandler(RequireUserAgent(router, requiredUserAgent), httpServerTimeout)
+	api.router = api.staticRequestMetrics.instrument(timeoutHandler(RequireUserAgent(api.RequireTokenScope(router), requiredUserAgent), httpServerTimeout))
 	api.routerMu.Unlock()
 	return
 }
@@ -181,13 +206,24 @@ func RequireUserAgent(h http.Handler, ua string) http.Handler {
 
 // RequirePassword is middleware that requires a request to authenticate with a
 // password using HTTP basic auth. Usernames are ignored. Empty passwords
-// indicate no authentication is required.
+// indicate no authentication is required. Requests made with an API token are
+// accepted if the token has the scope required for password protected routes.
 func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 	// An empty password is equivalent to no password.
 	if password == "" {
 		return h
 	}
 	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		// RequireTokenScope already checked the scopes of requests made with
+		// an API token for unprotected routes.
+		if token, ok := tokenFromRequest(req); ok {
+			if scope := passwordScope(req.Method, req.URL.Path); !token.HasScope(scope) {
+				WriteError(w, Error{fmt.Sprintf("API token not authorized: route requires the %v scope", scope)}, http.StatusForbidden)
+				return
+			}
+			h(w, req, ps)
+			return
+		}
 		_, pass, ok := req.BasicAuth()
 		if !ok || pass != password {
 			w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
@@ -198,6 +234,164 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 	}
 }
 
+// tenantTraffic describes the traffic of a route which counts towards the
+// quota of a tenant.
+type tenantTraffic int
+
+const (
+	tenantTrafficNone tenantTraffic = iota
+	tenantTrafficUpload
+	tenantTrafficDownload
+)
+
+// tenantContextKey is the key of the tenant of a request within the request's
+// context.
+type tenantContextKey struct{}
+
+// tenantFromRequest returns the tenant which authenticated the request. The
+// bool is false if the request wasn't made by a tenant.
+func tenantFromRequest(req *http.Request) (modules.TenantInfo, bool) {
+	ti, ok := req.Context().Value(tenantContextKey{}).(modules.TenantInfo)
+	return ti, ok
+}
+
+// countingResponseWriter is a http.ResponseWriter which counts the number of
+// bytes written to the response body and fails once more bytes than the
+// remaining quota would be written.
+type countingResponseWriter struct {
+	http.ResponseWriter
+	remaining uint64
+	limited   bool
+	n         uint64
+}
+
+// Write implements io.Writer.
+func (w *countingResponseWriter) Write(b []byte) (int, error) {
+	if w.limited && w.n+uint64(len(b)) > w.remaining {
+		n, err := w.ResponseWriter.Write(b[:w.remaining-w.n])
+		w.n += uint64(n)
+		if err != nil {
+			return n, err
+		}
+		return n, modules.ErrTenantQuotaExceeded
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.n += uint64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *countingResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// quotaReader is a io.ReadCloser which counts the number of bytes read from
+// the underlying reader and fails once more bytes than the remaining quota
+// were read.
+type quotaReader struct {
+	io.ReadCloser
+	remaining uint64
+	limited   bool
+	n         uint64
+}
+
+// Read implements io.Reader.
+func (r *quotaReader) Read(b []byte) (int, error) {
+	n, err := r.ReadCloser.Read(b)
+	r.n += uint64(n)
+	if r.limited && r.n > r.remaining {
+		return n, modules.ErrTenantQuotaExceeded
+	}
+	return n, err
+}
+
+// RequireTenantOrPassword is middleware for routes which can be used by
+// tenants of the renter. Requests with the username and token of a tenant are
+// restricted to the tenant's root directory and count towards its quota. All
+// other requests require the password, if one is set.
+func (api *API) RequireTenantOrPassword(h httprouter.Handle, password string, traffic tenantTraffic) httprouter.Handle {
+	return func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		name, token, ok := req.BasicAuth()
+		if !ok || name == "" {
+			api.serveWithoutTenant(h, password, traffic)(w, req, ps)
+			return
+		}
+		ti, err := api.renter.AuthenticateTenant(name, token)
+		if errors.Contains(err, modules.ErrUnknownTenant) {
+			api.serveWithoutTenant(h, password, traffic)(w, req, ps)
+			return
+		} else if err != nil {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
+			WriteError(w, Error{"API authentication failed."}, http.StatusUnauthorized)
+			return
+		}
+
+		// Tenants can't access paths outside of their root. The body of
+		// uploads must not be parsed as a form.
+		rootParam := req.URL.Query().Get("root")
+		if traffic != tenantTrafficUpload {
+			rootParam = req.FormValue("root")
+		}
+		if root, err := scanBool(rootParam); err != nil || root {
+			WriteError(w, Error{"tenants can't use the root parameter"}, http.StatusForbidden)
+			return
+		}
+
+		// Check the quota of the tenant.
+		var body *quotaReader
+		counter := &countingResponseWriter{ResponseWriter: w}
+		switch traffic {
+		case tenantTrafficUpload:
+			remaining, limited := ti.RemainingUpload()
+			if limited && remaining == 0 {
+				WriteError(w, Error{modules.ErrTenantQuotaExceeded.Error()}, http.StatusForbidden)
+				return
+			}
+			body = &quotaReader{ReadCloser: req.Body, remaining: remaining, limited: limited}
+			req.Body = body
+		case tenantTrafficDownload:
+			remaining, limited := ti.RemainingDownload()
+			if limited && remaining == 0 {
+				WriteError(w, Error{modules.ErrTenantQuotaExceeded.Error()}, http.StatusForbidden)
+				return
+			}
+			counter.remaining, counter.limited = remaining, limited
+		}
+
+		req = req.WithContext(context.WithValue(req.Context(), tenantContextKey{}, ti))
+		h(counter, req, ps)
+
+		var uploaded, downloaded uint64
+		if body != nil {
+			uploaded = body.n
+		}
+		if traffic == tenantTrafficDownload {
+			downloaded = counter.n
+		}
+		if uploaded > 0 || downloaded > 0 {
+			api.renter.RecordTenantTraffic(ti.Name, uploaded, downloaded)
+		}
+	}
+}
+
+// serveWithoutTenant serves a request which wasn't made by a tenant. The
+// downloaded bytes are recorded to attribute the download spending of the
+// renter between the tenants and the other users.
+func (api *API) serveWithoutTenant(h httprouter.Handle, password string, traffic tenantTraffic) httprouter.Handle {
+	if traffic != tenantTrafficDownload {
+		return RequirePassword(h, password)
+	}
+	return RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		counter := &countingResponseWriter{ResponseWriter: w}
+		h(counter, req, ps)
+		if counter.n > 0 {
+			api.renter.RecordTenantTraffic("", 0, counter.n)
+		}
+	}, password)
+}
+
 // isUnrestricted checks if a request may bypass the useragent check.
 func isUnrestricted(req *http.Request) bool {
 	return strings.HasPrefix(req.URL.Path, "/renter/stream/")
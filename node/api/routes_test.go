@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// TestCountingResponseWriter tests that the countingResponseWriter caps the
+// response at the remaining quota and passes flushes on.
+func TestCountingResponseWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	w := &countingResponseWriter{ResponseWriter: rec, remaining: 10, limited: true}
+	if n, err := w.Write(make([]byte, 6)); err != nil || n != 6 {
+		t.Fatal("unexpected write", n, err)
+	}
+	n, err := w.Write(make([]byte, 6))
+	if !errors.Contains(err, modules.ErrTenantQuotaExceeded) {
+		t.Fatal("expected ErrTenantQuotaExceeded but got", err)
+	}
+	if n != 4 || w.n != 10 || rec.Body.Len() != 10 {
+		t.Fatal("response wasn't capped at the quota", n, w.n, rec.Body.Len())
+	}
+	if _, err := w.Write([]byte{0}); !errors.Contains(err, modules.ErrTenantQuotaExceeded) {
+		t.Fatal("expected ErrTenantQuotaExceeded but got", err)
+	}
+
+	// Unlimited writers only count.
+	w = &countingResponseWriter{ResponseWriter: rec}
+	if n, err := w.Write(make([]byte, 100)); err != nil || n != 100 || w.n != 100 {
+		t.Fatal("unexpected write", n, err, w.n)
+	}
+
+	// The writer is a http.Flusher.
+	var rw http.ResponseWriter = w
+	f, ok := rw.(http.Flusher)
+	if !ok {
+		t.Fatal("countingResponseWriter should implement http.Flusher")
+	}
+	f.Flush()
+	if !rec.Flushed {
+		t.Fatal("flush wasn't passed on")
+	}
+}
@@ -4,6 +4,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -154,19 +155,34 @@ func (srv *Server) Unlock(password string) error {
 // require authentication using HTTP basic auth if the supplied password is not
 // the empty string. Usernames are ignored for authentication. This type of
 // authentication sends passwords in plaintext and should therefore only be
-// used if the APIaddr is localhost.
-func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, <-chan error) {
+// used if the APIaddr is localhost or if tlsConfig is set, in which case the
+// API is served over TLS.
+func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string, tlsConfig *TLSConfig, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, <-chan error) {
 	c := make(chan error, 1)
 	defer close(c)
 
 	var errChan <-chan error
 	var n *node.Node
 	s, err := func() (*Server, error) {
+		// Create the TLS config before listening to fail early on invalid
+		// certificates.
+		var serverTLSConfig *tls.Config
+		if tlsConfig != nil {
+			var err error
+			serverTLSConfig, err = newTLSConfig(*tlsConfig)
+			if err != nil {
+				return nil, errors.AddContext(err, "failed to create API TLS config")
+			}
+		}
+
 		// Create the server listener.
 		listener, err := net.Listen("tcp", APIaddr)
 		if err != nil {
 			return nil, err
 		}
+		if serverTLSConfig != nil {
+			listener = tls.NewListener(listener, serverTLSConfig)
+		}
 
 		// Load the config file.
 		cfg, err := modules.NewConfig(filepath.Join(nodeParams.Dir, modules.ConfigName))
@@ -174,8 +190,14 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 			return nil, errors.AddContext(err, "failed to load siad config")
 		}
 
+		// Load the API tokens.
+		tokens, err := api.NewTokenStore(filepath.Join(nodeParams.Dir, api.TokensFile))
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to load API tokens")
+		}
+
 		// Create the api for the server.
-		api := api.New(cfg, requiredUserAgent, requiredPassword, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		api := api.New(cfg, tokens, requiredUserAgent, requiredPassword, nil, nil, nil, nil, nil, nil, nil, nil, nil)
 		srv := &Server{
 			api: api,
 			apiServer: &http.Server{
@@ -253,10 +275,11 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 // require authentication using HTTP basic auth if the supplied password is not
 // the empty string. Usernames are ignored for authentication. This type of
 // authentication sends passwords in plaintext and should therefore only be
-// used if the APIaddr is localhost.
-func New(APIaddr string, requiredUserAgent string, requiredPassword string, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, error) {
+// used if the APIaddr is localhost or if tlsConfig is set, in which case the
+// API is served over TLS.
+func New(APIaddr string, requiredUserAgent string, requiredPassword string, tlsConfig *TLSConfig, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, error) {
 	// Wait for the node to be done loading.
-	srv, errChan := NewAsync(APIaddr, requiredUserAgent, requiredPassword, nodeParams, loadStartTime)
+	srv, errChan := NewAsync(APIaddr, requiredUserAgent, requiredPassword, tlsConfig, nodeParams, loadStartTime)
 	if err := <-errChan; err != nil {
 		// Error occurred during async load. Close all modules.
 		if build.Release == "standard" || build.Release == "testnet" {
@@ -0,0 +1,157 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/build"
+)
+
+var (
+	// certReloadInterval is the minimum amount of time between two checks for
+	// whether the certificate files of the API listener changed.
+	certReloadInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: time.Minute,
+		Testnet:  time.Minute,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+)
+
+type (
+	// TLSConfig configures TLS for the API listener.
+	TLSConfig struct {
+		// CertFile and KeyFile are the paths of the PEM encoded certificate
+		// and key the API is served with. The files are reloaded when they
+		// change.
+		CertFile string
+		KeyFile  string
+
+		// ClientCAFile is the path of a PEM encoded CA certificate. If set,
+		// clients can authenticate with a certificate signed by the CA. The
+		// common name of the certificate is mapped to the API token with the
+		// same name.
+		ClientCAFile string
+
+		// RequireClientCert rejects connections without a valid client
+		// certificate.
+		RequireClientCert bool
+	}
+
+	// certReloader loads a certificate and key pair and reloads it when the
+	// files are modified.
+	certReloader struct {
+		cert      *tls.Certificate
+		certMod   time.Time
+		keyMod    time.Time
+		lastCheck time.Time
+
+		staticCertFile string
+		staticKeyFile  string
+		mu             sync.Mutex
+	}
+)
+
+// newCertReloader loads the certificate and key pair from the given files.
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	cr := &certReloader{
+		staticCertFile: certFile,
+		staticKeyFile:  keyFile,
+	}
+	if err := cr.reload(); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// modTimes returns the modification times of the certificate and key files.
+func (cr *certReloader) modTimes() (certMod, keyMod time.Time, err error) {
+	certStat, err := os.Stat(cr.staticCertFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	keyStat, err := os.Stat(cr.staticKeyFile)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return certStat.ModTime(), keyStat.ModTime(), nil
+}
+
+// reload loads the certificate and key pair from disk.
+func (cr *certReloader) reload() error {
+	certMod, keyMod, err := cr.modTimes()
+	if err != nil {
+		return errors.AddContext(err, "unable to stat certificate files")
+	}
+	cert, err := tls.LoadX509KeyPair(cr.staticCertFile, cr.staticKeyFile)
+	if err != nil {
+		return errors.AddContext(err, "unable to load certificate")
+	}
+	cr.cert = &cert
+	cr.certMod = certMod
+	cr.keyMod = keyMod
+	cr.lastCheck = time.Now()
+	return nil
+}
+
+// GetCertificate returns the current certificate. It implements the
+// GetCertificate callback of tls.Config. If the certificate files changed
+// they are reloaded. If reloading fails, the previous certificate keeps being
+// used.
+func (cr *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	cr.mu.Lock()
+	defer cr.mu.Unlock()
+	if time.Since(cr.lastCheck) < certReloadInterval {
+		return cr.cert, nil
+	}
+	cr.lastCheck = time.Now()
+	certMod, keyMod, err := cr.modTimes()
+	if err != nil || (certMod.Equal(cr.certMod) && keyMod.Equal(cr.keyMod)) {
+		return cr.cert, nil
+	}
+	// The files might be in the middle of being replaced, in which case the
+	// next check will pick up the new certificate.
+	_ = cr.reload()
+	return cr.cert, nil
+}
+
+// newTLSConfig creates the tls.Config of the API listener.
+func newTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, errors.New("both a certificate and a key file are required for TLS")
+	}
+	cr, err := newCertReloader(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		GetCertificate: cr.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}
+	if cfg.ClientCAFile == "" {
+		if cfg.RequireClientCert {
+			return nil, errors.New("a client CA file is required to require client certificates")
+		}
+		return tlsConfig, nil
+	}
+	caPEM, err := ioutil.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to read client CA file")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("client CA file doesn't contain any certificates")
+	}
+	tlsConfig.ClientCAs = pool
+	tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	if cfg.RequireClientCert {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/node/api"
+	"go.thebigfile.com/bigd/node/api/client"
+)
+
+// testCA is a certificate authority used to sign test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCA creates a new self-signed CA and writes its certificate to the
+// given file.
+func newTestCA(t *testing.T, certFile string) testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), fastrand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(fastrand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, certFile, "CERTIFICATE", der)
+	return testCA{cert: cert, key: key}
+}
+
+// issue creates a certificate with the given common name signed by the CA and
+// writes the certificate and key to the given files.
+func (ca testCA) issue(t *testing.T, commonName, certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), fastrand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(fastrand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+}
+
+// writePEM writes a PEM block to a file.
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := ioutil.WriteFile(path, data, modules.DefaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestCertReloader tests that the certReloader picks up changed certificates
+// and keeps the previous certificate if the new one is invalid.
+func TestCertReloader(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("server", t.Name())
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	ca := newTestCA(t, filepath.Join(dir, "ca.pem"))
+	ca.issue(t, "foo", certFile, keyFile)
+
+	cr, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commonName := func() string {
+		cert, err := cr.GetCertificate(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return leaf.Subject.CommonName
+	}
+	if cn := commonName(); cn != "foo" {
+		t.Fatal("unexpected common name", cn)
+	}
+
+	// Replace the certificate. Make sure the modification time changes.
+	ca.issue(t, "bar", certFile, keyFile)
+	modTime := time.Now().Add(time.Second)
+	if err := os.Chtimes(certFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(certReloadInterval)
+	if cn := commonName(); cn != "bar" {
+		t.Fatal("certificate wasn't reloaded", cn)
+	}
+
+	// An invalid certificate is ignored.
+	if err := ioutil.WriteFile(certFile, []byte("invalid"), modules.DefaultFilePerm); err != nil {
+		t.Fatal(err)
+	}
+	modTime = modTime.Add(time.Second)
+	if err := os.Chtimes(certFile, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(certReloadInterval)
+	if cn := commonName(); cn != "bar" {
+		t.Fatal("invalid certificate replaced the previous one", cn)
+	}
+}
+
+// TestTLSAPI tests serving the API over TLS and authenticating with client
+// certificates that are mapped to API tokens.
+func TestTLSAPI(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("server", t.Name())
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	file := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+	ca := newTestCA(t, file("ca.pem"))
+	ca.issue(t, "server", file("server.pem"), file("server-key.pem"))
+	ca.issue(t, "admin", file("admin.pem"), file("admin-key.pem"))
+	ca.issue(t, "reader", file("reader.pem"), file("reader-key.pem"))
+	ca.issue(t, "unknown", file("unknown.pem"), file("unknown-key.pem"))
+
+	// Invalid configs are rejected.
+	if _, err := newTLSConfig(TLSConfig{CertFile: file("server.pem")}); err == nil {
+		t.Fatal("expected error for missing key file")
+	}
+	if _, err := newTLSConfig(TLSConfig{CertFile: file("server.pem"), KeyFile: file("server-key.pem"), RequireClientCert: true}); err == nil {
+		t.Fatal("expected error for missing client CA")
+	}
+
+	// Create the API with a token for the admin and the reader.
+	cfg, err := modules.NewConfig(file(modules.ConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokens, err := api.NewTokenStore(file(api.TokensFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := tokens.Create("admin", []api.APIScope{api.ScopeDaemonAdmin}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := tokens.Create("reader", []api.APIScope{api.ScopeReadOnly}, nil); err != nil {
+		t.Fatal(err)
+	}
+	a := api.New(cfg, tokens, "", "password", nil, nil, nil, nil, nil, nil, nil, nil, nil)
+	a.Shutdown = func() error { return nil }
+
+	// Serve the API over TLS.
+	tlsConfig, err := newTLSConfig(TLSConfig{
+		CertFile:     file("server.pem"),
+		KeyFile:      file("server-key.pem"),
+		ClientCAFile: file("ca.pem"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: a}
+	go func() {
+		_ = srv.Serve(tls.NewListener(listener, tlsConfig))
+	}()
+	defer func() {
+		if err := srv.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	newClient := func(name, password string) *client.Client {
+		var certFile, keyFile string
+		if name != "" {
+			certFile, keyFile = file(name+".pem"), file(name+"-key.pem")
+		}
+		tlsConfig, err := client.NewTLSConfig(file("ca.pem"), certFile, keyFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return client.New(client.Options{
+			Address:   listener.Addr().String(),
+			Password:  password,
+			UserAgent: "Sia-Agent",
+			TLSConfig: tlsConfig,
+		})
+	}
+
+	// The password and the certificate of the admin grant access.
+	if err := newClient("", "password").DaemonStopGet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := newClient("admin", "").DaemonStopGet(); err != nil {
+		t.Fatal(err)
+	}
+	// The reader's token lacks the scope.
+	if err := newClient("reader", "").DaemonStopGet(); err == nil || !strings.Contains(err.Error(), "scope") {
+		t.Fatal("expected scope error but got", err)
+	}
+	// Certificates without a token and requests without credentials fail.
+	if err := newClient("unknown", "").DaemonStopGet(); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatal("expected authentication error but got", err)
+	}
+	if err := newClient("", "").DaemonStopGet(); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatal("expected authentication error but got", err)
+	}
+	// Plain HTTP requests fail.
+	c := newClient("admin", "password")
+	c.TLSConfig = nil
+	if err := c.DaemonStopGet(); err == nil {
+		t.Fatal("expected plain HTTP request to fail")
+	}
+}
@@ -129,7 +129,13 @@ func NewCustomServer(dir string, APIaddr string, requiredUserAgent string, requi
 		return nil, errors.AddContext(err, "failed to load siad config")
 	}
 
-	api := NewCustom(cfg, requiredUserAgent, requiredPassword, acc, cs, e, g, h, m, r, tp, w, apiDeps)
+	// Load the API tokens.
+	tokens, err := NewTokenStore(filepath.Join(dir, TokensFile))
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to load API tokens")
+	}
+
+	api := NewCustom(cfg, tokens, requiredUserAgent, requiredPassword, acc, cs, e, g, h, m, r, tp, w, apiDeps)
 	srv := &Server{
 		api: api,
 		apiServer: &http.Server{
@@ -0,0 +1,535 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/persist"
+)
+
+// API tokens are named credentials which can be used instead of the API
+// password. Every token has a set of scopes which limit the routes it can be
+// used for and renter tokens can additionally be limited to a set of siapath
+// prefixes. Only the hashes of the tokens are persisted, so a token can't be
+// retrieved after it was created.
+
+const (
+	// TokensFile is the name of the file the API tokens are persisted in.
+	TokensFile = "apitokens.json"
+
+	// apiTokenSize is the number of random bytes of an API token.
+	apiTokenSize = 32
+)
+
+// The scopes of API tokens.
+const (
+	// ScopeReadOnly allows requests which don't modify any state, don't
+	// require the API password and don't reveal file data. It is implied by
+	// all other scopes.
+	ScopeReadOnly = APIScope("read-only")
+
+	// ScopeRenterFiles allows uploading, downloading and managing the
+	// renter's files.
+	ScopeRenterFiles = APIScope("renter-files")
+
+	// ScopeLocalFiles allows renter requests which read from or write to the
+	// daemon's local filesystem, such as uploading from a source path or
+	// downloading to a destination path. These requests additionally require
+	// ScopeRenterFiles.
+	ScopeLocalFiles = APIScope("local-files")
+
+	// ScopeWalletSpend allows spending from and managing the wallet.
+	ScopeWalletSpend = APIScope("wallet-spend")
+
+	// ScopeHostAdmin allows changing the host's settings and storage.
+	ScopeHostAdmin = APIScope("host-admin")
+
+	// ScopeDaemonAdmin allows all other requests that modify state or require
+	// the API password, such as stopping the daemon or changing the renter's
+	// allowance.
+	ScopeDaemonAdmin = APIScope("daemon-admin")
+
+	// scopeNone is the scope of routes which can't be used with API tokens.
+	scopeNone = APIScope("")
+)
+
+var (
+	// ErrUnknownAPIToken is returned when an API token doesn't exist.
+	ErrUnknownAPIToken = errors.New("unknown API token")
+
+	// ErrAPITokenExists is returned when creating an API token with a name
+	// that is already used.
+	ErrAPITokenExists = errors.New("API token already exists")
+
+	// ErrInvalidAPITokenName is returned when the name of an API token is
+	// invalid.
+	ErrInvalidAPITokenName = errors.New("API token name must be between 1 and 64 characters and only contain letters, digits, '-' and '_'")
+
+	// ErrInvalidAPIScope is returned when an unknown scope is provided.
+	ErrInvalidAPIScope = errors.New("invalid API scope")
+
+	// ErrNoAPIScopes is returned when creating an API token without scopes.
+	ErrNoAPIScopes = errors.New("API token needs at least one scope")
+
+	// apiTokenNameRegex matches valid API token names.
+	apiTokenNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+	// tokensMetadata is the metadata of the persisted API tokens.
+	tokensMetadata = persist.Metadata{
+		Header:  "API Tokens",
+		Version: "1.0",
+	}
+
+	// renterFileRoutes are the renter routes which operate on files without
+	// taking a siapath. Tokens with path prefixes can't use them.
+	renterFileRoutes = []string{
+		"/renter/bubble",
+		"/renter/bulk",
+		"/renter/clean",
+		"/renter/download/cancel",
+		"/renter/downloadinfo",
+		"/renter/downloads",
+		"/renter/files",
+		"/renter/uploads",
+	}
+
+	// renterSiaPathRoutes are the prefixes of the renter routes which take a
+	// siapath as a path parameter.
+	renterSiaPathRoutes = []string{
+		"/renter/delete/",
+		"/renter/dir/",
+		"/renter/download/",
+		"/renter/downloadasync/",
+		"/renter/file/",
+		"/renter/hosts/",
+		"/renter/rename/",
+		"/renter/stream/",
+		"/renter/upload/",
+		"/renter/uploadstream/",
+		"/renter/validatesiapath/",
+		"/renter/verify/",
+	}
+)
+
+type (
+	// APIScope is a scope of an API token.
+	APIScope string
+
+	// APIToken contains information about an API token.
+	APIToken struct {
+		Name         string            `json:"name"`
+		Scopes       []APIScope        `json:"scopes"`
+		PathPrefixes []modules.SiaPath `json:"pathprefixes"`
+		CreationTime time.Time         `json:"creationtime"`
+	}
+
+	// apiTokenPersist is the persisted form of an API token.
+	apiTokenPersist struct {
+		APIToken
+		Hash crypto.Hash `json:"hash"`
+	}
+
+	// TokenStore manages the API tokens.
+	TokenStore struct {
+		tokens map[crypto.Hash]APIToken
+
+		staticPath string
+		mu         sync.Mutex
+	}
+
+	// apiTokenContextKey is the key of the API token of a request within the
+	// request's context.
+	apiTokenContextKey struct{}
+)
+
+// validAPIScopes are all the scopes an API token can have.
+var validAPIScopes = []APIScope{ScopeReadOnly, ScopeRenterFiles, ScopeLocalFiles, ScopeWalletSpend, ScopeHostAdmin, ScopeDaemonAdmin}
+
+// NewTokenStore loads the API tokens persisted at the given path.
+func NewTokenStore(path string) (*TokenStore, error) {
+	ts := &TokenStore{
+		tokens:     make(map[crypto.Hash]APIToken),
+		staticPath: path,
+	}
+	var data []apiTokenPersist
+	err := persist.LoadJSON(tokensMetadata, &data, path)
+	if os.IsNotExist(err) {
+		return ts, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load API tokens")
+	}
+	for _, t := range data {
+		ts.tokens[t.Hash] = t.APIToken
+	}
+	return ts, nil
+}
+
+// save persists the API tokens.
+func (ts *TokenStore) save() error {
+	data := make([]apiTokenPersist, 0, len(ts.tokens))
+	for h, t := range ts.tokens {
+		data = append(data, apiTokenPersist{APIToken: t, Hash: h})
+	}
+	sort.Slice(data, func(i, j int) bool {
+		return data[i].Name < data[j].Name
+	})
+	return persist.SaveJSON(tokensMetadata, data, ts.staticPath)
+}
+
+// Create creates a new API token and returns the secret the token
+// authenticates with.
+func (ts *TokenStore) Create(name string, scopes []APIScope, pathPrefixes []modules.SiaPath) (string, APIToken, error) {
+	if !apiTokenNameRegex.MatchString(name) {
+		return "", APIToken{}, ErrInvalidAPITokenName
+	}
+	if len(scopes) == 0 {
+		return "", APIToken{}, ErrNoAPIScopes
+	}
+	for _, s := range scopes {
+		if !s.valid() {
+			return "", APIToken{}, errors.AddContext(ErrInvalidAPIScope, string(s))
+		}
+	}
+	token := APIToken{
+		Name:         name,
+		Scopes:       scopes,
+		PathPrefixes: pathPrefixes,
+		CreationTime: time.Now(),
+	}
+	secret := hex.EncodeToString(fastrand.Bytes(apiTokenSize))
+	hash := crypto.HashBytes([]byte(secret))
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, t := range ts.tokens {
+		if t.Name == name {
+			return "", APIToken{}, ErrAPITokenExists
+		}
+	}
+	ts.tokens[hash] = token
+	if err := ts.save(); err != nil {
+		delete(ts.tokens, hash)
+		return "", APIToken{}, errors.AddContext(err, "unable to save API tokens")
+	}
+	return secret, token, nil
+}
+
+// Revoke removes the API token with the given name.
+func (ts *TokenStore) Revoke(name string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for h, t := range ts.tokens {
+		if t.Name != name {
+			continue
+		}
+		delete(ts.tokens, h)
+		if err := ts.save(); err != nil {
+			ts.tokens[h] = t
+			return errors.AddContext(err, "unable to save API tokens")
+		}
+		return nil
+	}
+	return ErrUnknownAPIToken
+}
+
+// Tokens returns all API tokens sorted by name.
+func (ts *TokenStore) Tokens() []APIToken {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	tokens := make([]APIToken, 0, len(ts.tokens))
+	for _, t := range ts.tokens {
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens
+}
+
+// authenticate returns the API token with the given secret.
+func (ts *TokenStore) authenticate(secret string) (APIToken, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	t, ok := ts.tokens[crypto.HashBytes([]byte(secret))]
+	return t, ok
+}
+
+// tokenByName returns the API token with the given name.
+func (ts *TokenStore) tokenByName(name string) (APIToken, bool) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, t := range ts.tokens {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return APIToken{}, false
+}
+
+// authenticateClientCert returns the API token with the same name as the
+// common name of the verified TLS client certificate of the request.
+func (ts *TokenStore) authenticateClientCert(req *http.Request) (APIToken, bool) {
+	if !hasVerifiedClientCert(req) {
+		return APIToken{}, false
+	}
+	cn := req.TLS.VerifiedChains[0][0].Subject.CommonName
+	if cn == "" {
+		return APIToken{}, false
+	}
+	return ts.tokenByName(cn)
+}
+
+// hasVerifiedClientCert returns whether the request was made with a verified
+// TLS client certificate.
+func hasVerifiedClientCert(req *http.Request) bool {
+	return req.TLS != nil && len(req.TLS.VerifiedChains) > 0 && len(req.TLS.VerifiedChains[0]) > 0
+}
+
+// valid returns whether the scope is a known scope.
+func (s APIScope) valid() bool {
+	for _, vs := range validAPIScopes {
+		if s == vs {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope returns whether the token has the given scope. Every scope implies
+// ScopeReadOnly.
+func (t APIToken) HasScope(scope APIScope) bool {
+	if scope == scopeNone {
+		return false
+	}
+	for _, s := range t.Scopes {
+		if s == scope || scope == ScopeReadOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsSiaPath returns whether the siapath is within one of the path prefixes
+// of the token.
+func (t APIToken) allowsSiaPath(siaPath modules.SiaPath) bool {
+	if len(t.PathPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range t.PathPrefixes {
+		if prefix.IsRoot() || siaPath.Equals(prefix) || strings.HasPrefix(siaPath.String(), prefix.String()+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// authorize checks whether the token may be used for the request.
+func (t APIToken) authorize(req *http.Request) error {
+	scope := requiredScope(req.Method, req.URL.Path)
+	if scope == scopeNone {
+		return errors.New("route can't be used with API tokens")
+	}
+	if !t.HasScope(scope) {
+		return fmt.Errorf("route requires the %v scope", scope)
+	}
+	if usesLocalPath(req) && !t.HasScope(ScopeLocalFiles) {
+		return fmt.Errorf("paths on the local filesystem require the %v scope", ScopeLocalFiles)
+	}
+	if len(t.PathPrefixes) == 0 {
+		return nil
+	}
+	return t.authorizeSiaPaths(req)
+}
+
+// authorizeSiaPaths checks whether the siapaths of a renter request are within
+// the path prefixes of the token.
+func (t APIToken) authorizeSiaPaths(req *http.Request) error {
+	path := req.URL.Path
+	for _, route := range renterFileRoutes {
+		if path == route || strings.HasPrefix(path, route+"/") {
+			return errors.New("route can't be used with API tokens restricted to path prefixes")
+		}
+	}
+	for _, route := range renterSiaPathRoutes {
+		if !strings.HasPrefix(path, route) {
+			continue
+		}
+		// The body of uploads must not be parsed as a form.
+		formValue := req.FormValue
+		if route == "/renter/uploadstream/" {
+			formValue = req.URL.Query().Get
+		}
+		if root, err := scanBool(formValue("root")); err != nil || root {
+			return errors.New("API tokens restricted to path prefixes can't use the root parameter")
+		}
+		siaPaths := []string{strings.TrimPrefix(path, route)}
+		if newSiaPath := formValue("newsiapath"); newSiaPath != "" {
+			siaPaths = append(siaPaths, newSiaPath)
+		}
+		for _, sp := range siaPaths {
+			siaPath, err := modules.NewSiaPath(sp)
+			if err != nil {
+				return errors.AddContext(err, "unable to parse siapath")
+			}
+			if !t.allowsSiaPath(siaPath) {
+				return fmt.Errorf("siapath %v is outside of the token's path prefixes", siaPath)
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// usesLocalPath returns whether a renter request reads from or writes to a
+// path on the daemon's local filesystem. The operations of bulk requests are
+// within the request's body and are checked by bulkUsesLocalPath once the body
+// was decoded.
+func usesLocalPath(req *http.Request) bool {
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/renter/upload/"):
+		return req.FormValue("source") != ""
+	case strings.HasPrefix(path, "/renter/download/"), strings.HasPrefix(path, "/renter/downloadasync/"):
+		// Downloads are written to the destination unless they are written
+		// to the response.
+		httpresp, err := scanBool(req.FormValue("httpresp"))
+		return err != nil || !httpresp
+	case strings.HasPrefix(path, "/renter/file/"):
+		return req.FormValue("trackingpath") != ""
+	}
+	return false
+}
+
+// bulkUsesLocalPath returns whether any of the bulk operations reads from or
+// writes to a path on the daemon's local filesystem.
+func bulkUsesLocalPath(ops []modules.BulkOperation) bool {
+	for _, op := range ops {
+		switch op.Type {
+		case modules.BulkOperationUpload, modules.BulkOperationSetLocalPath:
+			return true
+		}
+	}
+	return false
+}
+
+// requiredScope returns the scope an API token needs to be used for a route.
+func requiredScope(method, path string) APIScope {
+	// API tokens can't manage API tokens.
+	if path == "/daemon/tokens" || strings.HasPrefix(path, "/daemon/tokens/") {
+		return scopeNone
+	}
+	if method == http.MethodGet {
+		switch {
+		case path == "/wallet/address", path == "/wallet/backup", path == "/wallet/seeds", path == "/wallet/verifypassword":
+			return ScopeWalletSpend
+		case path == "/daemon/stop", path == "/miner/start", path == "/miner/stop":
+			return ScopeDaemonAdmin
+		case strings.HasPrefix(path, "/renter/download/"), strings.HasPrefix(path, "/renter/downloadasync/"), strings.HasPrefix(path, "/renter/stream/"):
+			return ScopeRenterFiles
+		}
+		return ScopeReadOnly
+	}
+	switch {
+	case path == "/wallet" || strings.HasPrefix(path, "/wallet/"):
+		return ScopeWalletSpend
+	case path == "/host" || strings.HasPrefix(path, "/host/"):
+		return ScopeHostAdmin
+	}
+	for _, route := range renterFileRoutes {
+		if path == route || strings.HasPrefix(path, route+"/") {
+			return ScopeRenterFiles
+		}
+	}
+	for _, route := range renterSiaPathRoutes {
+		if strings.HasPrefix(path, route) {
+			return ScopeRenterFiles
+		}
+	}
+	return ScopeDaemonAdmin
+}
+
+// passwordScope returns the scope an API token needs to be used for a route
+// which requires the API password. These routes reveal secrets even if they
+// don't modify any state, so ScopeReadOnly isn't enough for them. The metrics
+// are the exception so that monitoring tokens can scrape them.
+func passwordScope(method, path string) APIScope {
+	scope := requiredScope(method, path)
+	if scope != ScopeReadOnly {
+		return scope
+	}
+	switch {
+	case path == "/metrics":
+		return ScopeReadOnly
+	case path == "/wallet" || strings.HasPrefix(path, "/wallet/"):
+		return ScopeWalletSpend
+	case path == "/host" || strings.HasPrefix(path, "/host/"):
+		return ScopeHostAdmin
+	case strings.HasPrefix(path, "/renter/uploads/resumable/"):
+		return ScopeRenterFiles
+	}
+	for _, route := range renterSiaPathRoutes {
+		if strings.HasPrefix(path, route) {
+			return ScopeRenterFiles
+		}
+	}
+	return ScopeDaemonAdmin
+}
+
+// tokenFromRequest returns the API token which authenticated the request. The
+// bool is false if the request wasn't authenticated with an API token.
+func tokenFromRequest(req *http.Request) (APIToken, bool) {
+	t, ok := req.Context().Value(apiTokenContextKey{}).(APIToken)
+	return t, ok
+}
+
+// RequireTokenScope is middleware that authenticates requests made with an API
+// token and checks that the token may be used for the requested route. A
+// request that doesn't use the API password or an API token is authenticated
+// as the token named after the common name of its verified TLS client
+// certificate, if there is one. Requests with a verified client certificate
+// which doesn't belong to an API token are rejected, since any certificate
+// signed by the client CA would otherwise pass the API password checks of a
+// daemon without an API password. Other requests are passed on unchanged.
+func (api *API) RequireTokenScope(h http.Handler) http.Handler {
+	if api.staticTokens == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, pass, _ := req.BasicAuth()
+		if pass != "" && pass == api.requiredPassword {
+			h.ServeHTTP(w, req)
+			return
+		}
+		token, ok := api.staticTokens.authenticate(pass)
+		if !ok {
+			token, ok = api.staticTokens.authenticateClientCert(req)
+		}
+		if !ok && hasVerifiedClientCert(req) {
+			WriteError(w, Error{"API authentication failed: TLS client certificate doesn't belong to an API token"}, http.StatusForbidden)
+			return
+		}
+		if !ok {
+			h.ServeHTTP(w, req)
+			return
+		}
+		if err := token.authorize(req); err != nil {
+			WriteError(w, Error{"API token not authorized: " + err.Error()}, http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), apiTokenContextKey{}, token)))
+	})
+}
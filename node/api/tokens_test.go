@@ -0,0 +1,350 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+)
+
+// withToken returns the request as if it was authenticated with the API token.
+func withToken(req *http.Request, token APIToken) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), apiTokenContextKey{}, token))
+}
+
+// TestRequiredScope is a unit test for requiredScope.
+func TestRequiredScope(t *testing.T) {
+	tests := []struct {
+		method, path string
+		scope        APIScope
+	}{
+		{http.MethodGet, "/wallet", ScopeReadOnly},
+		{http.MethodGet, "/wallet/seeds", ScopeWalletSpend},
+		{http.MethodPost, "/wallet/siacoins", ScopeWalletSpend},
+		{http.MethodGet, "/host", ScopeReadOnly},
+		{http.MethodPost, "/host", ScopeHostAdmin},
+		{http.MethodPost, "/host/storage/folders/add", ScopeHostAdmin},
+		{http.MethodPost, "/hostdb/filtermode", ScopeDaemonAdmin},
+		{http.MethodGet, "/renter/files", ScopeReadOnly},
+		{http.MethodGet, "/renter/stream/foo", ScopeRenterFiles},
+		{http.MethodPost, "/renter/uploadstream/foo", ScopeRenterFiles},
+		{http.MethodPost, "/renter/download/cancel", ScopeRenterFiles},
+		{http.MethodPost, "/renter/uploads/pause", ScopeRenterFiles},
+		{http.MethodPost, "/renter", ScopeDaemonAdmin},
+		{http.MethodGet, "/daemon/stop", ScopeDaemonAdmin},
+		{http.MethodPost, "/daemon/settings", ScopeDaemonAdmin},
+		{http.MethodGet, "/metrics", ScopeReadOnly},
+		{http.MethodGet, "/daemon/tokens", scopeNone},
+		{http.MethodPost, "/daemon/tokens/create", scopeNone},
+	}
+	for _, test := range tests {
+		if scope := requiredScope(test.method, test.path); scope != test.scope {
+			t.Errorf("%v %v: expected scope %q but got %q", test.method, test.path, test.scope, scope)
+		}
+	}
+}
+
+// TestAPITokenAuthorize tests authorizing requests with the scopes and path
+// prefixes of API tokens.
+func TestAPITokenAuthorize(t *testing.T) {
+	prefix, err := modules.NewSiaPath("backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := APIToken{
+		Scopes:       []APIScope{ScopeRenterFiles},
+		PathPrefixes: []modules.SiaPath{prefix},
+	}
+	tests := []struct {
+		method, target string
+		allowed        bool
+	}{
+		{http.MethodGet, "/renter", true},
+		{http.MethodGet, "/wallet/seeds", false},
+		{http.MethodGet, "/renter/file/backups/foo", true},
+		{http.MethodGet, "/renter/file/backupsfoo", false},
+		{http.MethodGet, "/renter/file/backups/foo?root=true", false},
+		{http.MethodGet, "/renter/stream/foo", false},
+		{http.MethodPost, "/renter/uploadstream/backups/foo", true},
+		{http.MethodPost, "/renter/rename/backups/foo?newsiapath=backups/bar", true},
+		{http.MethodPost, "/renter/rename/backups/foo?newsiapath=bar", false},
+		{http.MethodGet, "/renter/files", false},
+		{http.MethodPost, "/renter/bulk", false},
+		{http.MethodPost, "/renter", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, nil)
+		if err := token.authorize(req); (err == nil) != test.allowed {
+			t.Errorf("%v %v: expected allowed to be %v but got %v", test.method, test.target, test.allowed, err)
+		}
+	}
+}
+
+// TestAPITokenAuthorizeLocalPaths tests that requests which use paths on the
+// daemon's local filesystem require the local-files scope.
+func TestAPITokenAuthorizeLocalPaths(t *testing.T) {
+	renterFiles := APIToken{Scopes: []APIScope{ScopeRenterFiles}}
+	localFiles := APIToken{Scopes: []APIScope{ScopeRenterFiles, ScopeLocalFiles}}
+	onlyLocalFiles := APIToken{Scopes: []APIScope{ScopeLocalFiles}}
+	tests := []struct {
+		method, target string
+		allowed        bool
+	}{
+		{http.MethodPost, "/renter/upload/foo?source=/etc/passwd", false},
+		{http.MethodGet, "/renter/download/foo?destination=/tmp/foo", false},
+		{http.MethodGet, "/renter/download/foo?destination=/tmp/foo&httpresp=false", false},
+		{http.MethodGet, "/renter/downloadasync/foo?destination=/tmp/foo", false},
+		{http.MethodGet, "/renter/download/foo?httpresp=invalid", false},
+		{http.MethodPost, "/renter/file/foo?trackingpath=/etc/passwd", false},
+		{http.MethodGet, "/renter/download/foo?httpresp=true", true},
+		{http.MethodGet, "/renter/download/foo?destination=/tmp/foo&httpresp=true", true},
+		{http.MethodPost, "/renter/uploadstream/foo", true},
+		{http.MethodPost, "/renter/file/foo?stuck=true", true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.target, nil)
+		if err := renterFiles.authorize(req); (err == nil) != test.allowed {
+			t.Errorf("%v %v: expected allowed to be %v but got %v", test.method, test.target, test.allowed, err)
+		}
+		// With the local-files scope all requests are allowed.
+		req = httptest.NewRequest(test.method, test.target, nil)
+		if err := localFiles.authorize(req); err != nil {
+			t.Errorf("%v %v: expected request to be allowed with the local-files scope but got %v", test.method, test.target, err)
+		}
+		// The local-files scope alone isn't enough.
+		req = httptest.NewRequest(test.method, test.target, nil)
+		if err := onlyLocalFiles.authorize(req); err == nil {
+			t.Errorf("%v %v: expected request to require the renter-files scope", test.method, test.target)
+		}
+	}
+}
+
+// TestAPITokenBulkLocalPaths tests that bulk operations which use paths on the
+// daemon's local filesystem require the local-files scope.
+func TestAPITokenBulkLocalPaths(t *testing.T) {
+	renterFiles := APIToken{Scopes: []APIScope{ScopeRenterFiles}}
+	tests := []struct {
+		op        modules.BulkOperationType
+		forbidden bool
+	}{
+		{modules.BulkOperationUpload, true},
+		{modules.BulkOperationSetLocalPath, true},
+		{modules.BulkOperationDelete, false},
+		{modules.BulkOperationSetStuck, false},
+	}
+	for _, test := range tests {
+		// The token passes the checks of the middleware.
+		req := httptest.NewRequest(http.MethodPost, "/renter/bulk", nil)
+		if err := renterFiles.authorize(req); err != nil {
+			t.Fatal(err)
+		}
+		if bulkUsesLocalPath([]modules.BulkOperation{{Type: test.op}}) != test.forbidden {
+			t.Errorf("%v: expected bulkUsesLocalPath to return %v", test.op, test.forbidden)
+		}
+		if !test.forbidden {
+			continue
+		}
+
+		// The handler rejects the operations before executing them.
+		body, err := json.Marshal(RenterBulkPOST{
+			Operations: []modules.BulkOperation{
+				{Type: modules.BulkOperationSetStuck},
+				{Type: test.op, LocalPath: "/etc"},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req = withToken(httptest.NewRequest(http.MethodPost, "/renter/bulk", bytes.NewReader(body)), renterFiles)
+		rec := httptest.NewRecorder()
+		(&API{}).renterBulkHandlerPOST(rec, req, nil)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%v: expected status %v but got %v", test.op, http.StatusForbidden, rec.Code)
+		}
+	}
+}
+
+// TestPasswordScope tests that API tokens need more than the read-only scope
+// for every password protected GET route except for the metrics.
+func TestPasswordScope(t *testing.T) {
+	tests := []struct {
+		path  string
+		scope APIScope
+	}{
+		{"/daemon/stop", ScopeDaemonAdmin},
+		{"/daemon/tokens", scopeNone},
+		{"/metrics", ScopeReadOnly},
+		{"/miner/header", ScopeDaemonAdmin},
+		{"/miner/start", ScopeDaemonAdmin},
+		{"/miner/stop", ScopeDaemonAdmin},
+		{"/renter/backups", ScopeDaemonAdmin},
+		{"/renter/download/foo", ScopeRenterFiles},
+		{"/renter/downloadasync/foo", ScopeRenterFiles},
+		{"/renter/tenant", ScopeDaemonAdmin},
+		{"/renter/tenants", ScopeDaemonAdmin},
+		{"/renter/uploads/resumable/foo", ScopeRenterFiles},
+		{"/wallet/address", ScopeWalletSpend},
+		{"/wallet/backup", ScopeWalletSpend},
+		{"/wallet/seeds", ScopeWalletSpend},
+		{"/wallet/unlockconditions/foo", ScopeWalletSpend},
+		{"/wallet/unspent", ScopeWalletSpend},
+		{"/wallet/verifypassword", ScopeWalletSpend},
+		{"/wallet/watch", ScopeWalletSpend},
+	}
+	handler := RequirePassword(func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		WriteSuccess(w)
+	}, "password")
+	readOnly := APIToken{Scopes: []APIScope{ScopeReadOnly}}
+	for _, test := range tests {
+		if scope := passwordScope(http.MethodGet, test.path); scope != test.scope {
+			t.Errorf("%v: expected scope %q but got %q", test.path, test.scope, scope)
+		}
+
+		// A read-only token is rejected unless the route only requires the
+		// read-only scope.
+		status := http.StatusForbidden
+		if test.scope == ScopeReadOnly {
+			status = http.StatusNoContent
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, withToken(httptest.NewRequest(http.MethodGet, test.path, nil), readOnly), nil)
+		if rec.Code != status {
+			t.Errorf("%v: expected status %v for a read-only token but got %v", test.path, status, rec.Code)
+		}
+		if test.scope == scopeNone {
+			continue
+		}
+
+		// A token with the required scope is accepted.
+		token := APIToken{Scopes: []APIScope{test.scope}}
+		rec = httptest.NewRecorder()
+		handler(rec, withToken(httptest.NewRequest(http.MethodGet, test.path, nil), token), nil)
+		if rec.Code != http.StatusNoContent {
+			t.Errorf("%v: expected status %v for a token with the %v scope but got %v", test.path, http.StatusNoContent, test.scope, rec.Code)
+		}
+	}
+}
+
+// TestTokenStore tests creating, persisting and revoking API tokens.
+func TestTokenStore(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	path := filepath.Join(build.TempDir("api", t.Name()), TokensFile)
+	if err := os.MkdirAll(filepath.Dir(path), modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := NewTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Check the validation.
+	if _, _, err := ts.Create("foo/bar", []APIScope{ScopeReadOnly}, nil); !errors.Contains(err, ErrInvalidAPITokenName) {
+		t.Fatal("expected ErrInvalidAPITokenName but got", err)
+	}
+	if _, _, err := ts.Create("foo", nil, nil); !errors.Contains(err, ErrNoAPIScopes) {
+		t.Fatal("expected ErrNoAPIScopes but got", err)
+	}
+	if _, _, err := ts.Create("foo", []APIScope{"admin"}, nil); !errors.Contains(err, ErrInvalidAPIScope) {
+		t.Fatal("expected ErrInvalidAPIScope but got", err)
+	}
+
+	secret, _, err := ts.Create("foo", []APIScope{ScopeHostAdmin}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ts.Create("foo", []APIScope{ScopeReadOnly}, nil); !errors.Contains(err, ErrAPITokenExists) {
+		t.Fatal("expected ErrAPITokenExists but got", err)
+	}
+
+	// The token is persisted.
+	ts, err = NewTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, ok := ts.authenticate(secret)
+	if !ok || token.Name != "foo" || !token.HasScope(ScopeHostAdmin) || !token.HasScope(ScopeReadOnly) || token.HasScope(ScopeWalletSpend) {
+		t.Fatal("unexpected token", token, ok)
+	}
+
+	// Revoke the token.
+	if err := ts.Revoke("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts.Revoke("foo"); !errors.Contains(err, ErrUnknownAPIToken) {
+		t.Fatal("expected ErrUnknownAPIToken but got", err)
+	}
+	if _, ok := ts.authenticate(secret); ok {
+		t.Fatal("revoked token shouldn't authenticate")
+	}
+}
+
+// TestRequireTokenScopeClientCert tests that requests with a verified TLS
+// client certificate are only accepted if the certificate belongs to an API
+// token, even if the daemon doesn't use an API password.
+func TestRequireTokenScopeClientCert(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	path := filepath.Join(build.TempDir("api", t.Name()), TokensFile)
+	if err := os.MkdirAll(filepath.Dir(path), modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	ts, err := NewTokenStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ts.Create("admin", []APIScope{ScopeDaemonAdmin}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Serve password protected routes without an API password, like a daemon
+	// which relies on client certificates.
+	router := httprouter.New()
+	handler := RequirePassword(func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		WriteSuccess(w)
+	}, "")
+	router.GET("/daemon/stop", handler)
+	router.POST("/wallet/siacoins", handler)
+	h := (&API{staticTokens: ts}).RequireTokenScope(router)
+
+	withCert := func(req *http.Request, cn string) *http.Request {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: cn}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		return req
+	}
+	tests := []struct {
+		method, path, cn string
+		status           int
+	}{
+		{http.MethodGet, "/daemon/stop", "unknown", http.StatusForbidden},
+		{http.MethodPost, "/wallet/siacoins", "unknown", http.StatusForbidden},
+		{http.MethodGet, "/daemon/stop", "", http.StatusForbidden},
+		{http.MethodPost, "/wallet/siacoins", "admin", http.StatusForbidden},
+		{http.MethodGet, "/daemon/stop", "admin", http.StatusNoContent},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, withCert(httptest.NewRequest(test.method, test.path, nil), test.cn))
+		if rec.Code != test.status {
+			t.Errorf("%v %v with certificate %q: expected status %v but got %v", test.method, test.path, test.cn, test.status, rec.Code)
+		}
+	}
+}
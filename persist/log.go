@@ -1,17 +1,133 @@
 package persist
 
 import (
+	"encoding/json"
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
+	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/log"
 	"go.thebigfile.com/bigd/build"
 )
 
-// Logger is a wrapper for log.Logger.
-type Logger struct {
-	*log.Logger
+// LogLevel is the minimum severity of the messages a logger writes.
+type LogLevel string
+
+// LogFormat is the format of the lines a logger writes.
+type LogFormat string
+
+const (
+	// LogLevelDebug writes all messages.
+	LogLevelDebug LogLevel = "debug"
+	// LogLevelInfo writes all messages except debug messages.
+	LogLevelInfo LogLevel = "info"
+	// LogLevelWarn only writes warnings and errors.
+	LogLevelWarn LogLevel = "warn"
+	// LogLevelError only writes errors, including severe and critical
+	// messages.
+	LogLevelError LogLevel = "error"
+)
+
+const (
+	// LogFormatText writes log lines as plain text followed by the level and
+	// the fields of the line as key=value pairs.
+	LogFormatText LogFormat = "text"
+	// LogFormatJSON writes every log line as a JSON object.
+	LogFormatJSON LogFormat = "json"
+)
+
+// DefaultLogModule is the module name under which the default log level of
+// all modules without a level of their own is set.
+const DefaultLogModule = "default"
+
+// Keys of the fields which are commonly attached to log lines.
+const (
+	LogKeyContractID = "contractid"
+	LogKeyError      = "error"
+	LogKeyHostKey    = "hostkey"
+	LogKeySiaPath    = "siapath"
+)
+
+var (
+	// ErrInvalidLogLevel is returned when an unknown log level is used.
+	ErrInvalidLogLevel = errors.New("invalid log level, valid levels are debug, info, warn and error")
+
+	// ErrInvalidLogFormat is returned when an unknown log format is used.
+	ErrInvalidLogFormat = errors.New("invalid log format, valid formats are text and json")
+)
+
+// logLevelRanks orders the log levels by severity.
+var logLevelRanks = map[LogLevel]int{
+	LogLevelDebug: 0,
+	LogLevelInfo:  1,
+	LogLevelWarn:  2,
+	LogLevelError: 3,
 }
 
+type (
+	// Logger is a wrapper for log.Logger which writes structured lines,
+	// filters them by the level of the logger's module and rotates its log
+	// file.
+	Logger struct {
+		*log.Logger
+
+		staticFields []LogField
+		staticModule string
+		staticSink   *logSink
+	}
+
+	// LogField is a key-value pair attached to a log line.
+	LogField struct {
+		Key   string
+		Value interface{}
+	}
+
+	// LogOptions are the options of all loggers created after the options
+	// are set.
+	LogOptions struct {
+		// Format is the format of the log lines.
+		Format LogFormat
+
+		// MaxSize is the size in bytes after which a log file is rotated.
+		// Zero disables rotation by size.
+		MaxSize uint64
+
+		// MaxAge is the duration after which a log file is rotated. Zero
+		// disables rotation by time.
+		MaxAge time.Duration
+	}
+
+	// logSink is the writer a Logger writes its lines to. If it writes to a
+	// file, it rotates the file once it exceeds the maximum size or age.
+	logSink struct {
+		closed bool
+		file   *os.File
+		opened time.Time
+		size   uint64
+		w      io.Writer
+
+		staticOptions LogOptions
+		staticPath    string
+		mu            sync.Mutex
+	}
+
+	// stdWriter is the writer of the wrapped log.Logger. It turns the lines
+	// logged through the methods of log.Logger which Logger doesn't override
+	// into structured lines.
+	stdWriter struct {
+		enabled bool
+		l       *Logger
+	}
+)
+
 var (
 	// options contains log options with Sia- and build-specific information.
 	options = log.Options{
@@ -21,37 +137,494 @@ var (
 		Release:      buildReleaseType(),
 		Version:      build.NodeVersion,
 	}
+
+	// logOptions are the options of new loggers.
+	logOptions   = LogOptions{Format: LogFormatText}
+	logOptionsMu sync.Mutex
+
+	// logLevels contains the levels of the modules. Modules without a level
+	// use the default level.
+	logLevels = struct {
+		defaultLevel LogLevel
+		modules      map[string]LogLevel
+		mu           sync.RWMutex
+	}{
+		defaultLevel: defaultLogLevel(),
+		modules:      make(map[string]LogLevel),
+	}
 )
 
-// printCommitHash logs build.GitRevision at startup.
-func printCommitHash(logger *log.Logger) {
+// defaultLogLevel returns the default log level for this build.
+func defaultLogLevel() LogLevel {
+	if build.DEBUG {
+		return LogLevelDebug
+	}
+	return LogLevelInfo
+}
+
+// Field creates a LogField.
+func Field(key string, value interface{}) LogField {
+	return LogField{Key: key, Value: value}
+}
+
+// Valid returns an error if the log level is unknown.
+func (level LogLevel) Valid() error {
+	if _, ok := logLevelRanks[level]; !ok {
+		return ErrInvalidLogLevel
+	}
+	return nil
+}
+
+// Valid returns an error if the log format is unknown.
+func (format LogFormat) Valid() error {
+	if format != LogFormatText && format != LogFormatJSON {
+		return ErrInvalidLogFormat
+	}
+	return nil
+}
+
+// SetLogOptions sets the options of loggers created afterwards.
+func SetLogOptions(opts LogOptions) error {
+	if err := opts.Format.Valid(); err != nil {
+		return err
+	}
+	logOptionsMu.Lock()
+	defer logOptionsMu.Unlock()
+	logOptions = opts
+	return nil
+}
+
+// currentLogOptions returns the options of new loggers.
+func currentLogOptions() LogOptions {
+	logOptionsMu.Lock()
+	defer logOptionsMu.Unlock()
+	return logOptions
+}
+
+// SetLogLevel sets the level of a module's loggers. DefaultLogModule sets the
+// default level of all modules without a level of their own.
+func SetLogLevel(module string, level LogLevel) error {
+	if err := level.Valid(); err != nil {
+		return err
+	}
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	if module == DefaultLogModule {
+		logLevels.defaultLevel = level
+	} else {
+		logLevels.modules[module] = level
+	}
+	return nil
+}
+
+// ClearLogLevel removes the level of a module, which then uses the default
+// level again.
+func ClearLogLevel(module string) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	delete(logLevels.modules, module)
+}
+
+// ResetLogLevels sets the default level back to the default of the build and
+// removes the levels of all modules.
+func ResetLogLevels() {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+	logLevels.defaultLevel = defaultLogLevel()
+	logLevels.modules = make(map[string]LogLevel)
+}
+
+// LogLevels returns the levels of all modules with a level of their own. The
+// default level is returned as the level of DefaultLogModule.
+func LogLevels() map[string]LogLevel {
+	logLevels.mu.RLock()
+	defer logLevels.mu.RUnlock()
+	levels := make(map[string]LogLevel, len(logLevels.modules)+1)
+	for module, level := range logLevels.modules {
+		levels[module] = level
+	}
+	levels[DefaultLogModule] = logLevels.defaultLevel
+	return levels
+}
+
+// logLevel returns the level of a module.
+func logLevel(module string) LogLevel {
+	logLevels.mu.RLock()
+	defer logLevels.mu.RUnlock()
+	if level, ok := logLevels.modules[module]; ok {
+		return level
+	}
+	return logLevels.defaultLevel
+}
+
+// newLogSink creates a sink writing to w. If path is not empty, w is the file
+// at path which is rotated according to the options.
+func newLogSink(w io.Writer, path string, opts LogOptions) *logSink {
+	ls := &logSink{
+		opened:        time.Now(),
+		w:             w,
+		staticOptions: opts,
+		staticPath:    path,
+	}
+	if f, ok := w.(*os.File); ok && path != "" {
+		ls.file = f
+		if fi, err := f.Stat(); err == nil {
+			ls.size = uint64(fi.Size())
+		}
+	}
+	return ls
+}
+
+// openLogFile opens a log file in append mode and creates it if it doesn't
+// exist.
+func openLogFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
+}
+
+// rotate renames the current log file and opens a new one.
+func (ls *logSink) rotate() error {
+	if err := ls.file.Sync(); err != nil {
+		return err
+	}
+	if err := ls.file.Close(); err != nil {
+		return err
+	}
+	rotated := ls.staticPath + "." + time.Now().UTC().Format("20060102T150405.000000000")
+	if err := os.Rename(ls.staticPath, rotated); err != nil {
+		return err
+	}
+	f, err := openLogFile(ls.staticPath)
+	if err != nil {
+		return err
+	}
+	ls.file = f
+	ls.w = f
+	ls.size = 0
+	ls.opened = time.Now()
+	return nil
+}
+
+// needsRotation returns whether the log file needs to be rotated before
+// writing n more bytes.
+func (ls *logSink) needsRotation(n int) bool {
+	if ls.file == nil || ls.size == 0 {
+		return false
+	}
+	maxSize, maxAge := ls.staticOptions.MaxSize, ls.staticOptions.MaxAge
+	return (maxSize > 0 && ls.size+uint64(n) > maxSize) || (maxAge > 0 && time.Since(ls.opened) >= maxAge)
+}
+
+// Write implements io.Writer.
+func (ls *logSink) Write(b []byte) (int, error) {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	// Sanity check - close should not have been called yet.
+	if ls.closed {
+		options.Critical("cannot write to the log after it has been closed")
+	}
+	if ls.needsRotation(len(b)) {
+		if err := ls.rotate(); err != nil {
+			return 0, errors.AddContext(err, "unable to rotate log file")
+		}
+	}
+	n, err := ls.w.Write(b)
+	ls.size += uint64(n)
+	return n, err
+}
+
+// Close closes the underlying writer if it is an io.Closer.
+func (ls *logSink) Close() error {
+	ls.mu.Lock()
+	defer ls.mu.Unlock()
+	// Sanity check - close should not have been called yet.
+	if ls.closed {
+		options.Critical("cannot close the log; already closed")
+	}
+	ls.closed = true
+	if ls.file != nil {
+		// Ensure that all data has actually hit the disk.
+		return errors.Compose(ls.file.Sync(), ls.file.Close())
+	}
+	if c, ok := ls.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Write implements io.Writer.
+func (sw *stdWriter) Write(b []byte) (int, error) {
+	if !sw.enabled {
+		return len(b), nil
+	}
+	msg := strings.TrimSuffix(string(b), "\n")
+	sw.l.output(messageLevel(msg), callerOutsideLog, msg)
+	return len(b), nil
+}
+
+// newLogger creates a Logger for the module which writes to w.
+func newLogger(w io.Writer, path, module string) (*Logger, error) {
+	sw := &stdWriter{}
+	logger, err := log.NewLogger(sw, options)
+	if err != nil {
+		return nil, err
+	}
+	logger.SetFlags(0)
+	l := &Logger{
+		Logger:       logger,
+		staticModule: module,
+		staticSink:   newLogSink(w, path, currentLogOptions()),
+	}
+	sw.l = l
+	sw.enabled = true
+	l.output(LogLevelInfo, 2, fmt.Sprintf("STARTUP: Logging has started. %v Version %v", options.BinaryName, options.Version))
 	if build.GitRevision != "" {
-		logger.Printf("STARTUP: Commit hash %v", build.GitRevision)
+		l.output(LogLevelInfo, 2, "STARTUP: Commit hash "+build.GitRevision)
 	} else {
-		logger.Println("STARTUP: Unknown commit hash")
+		l.output(LogLevelInfo, 2, "STARTUP: Unknown commit hash")
 	}
+	return l, nil
+}
+
+// moduleFromPath returns the name of the module a log file belongs to, which
+// is the name of the file without its extension.
+func moduleFromPath(path string) string {
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
 }
 
 // NewFileLogger returns a logger that logs to logFilename. The file is opened
-// in append mode, and created if it does not exist.
+// in append mode, and created if it does not exist. The module of the logger
+// is the name of the file without its extension.
 func NewFileLogger(logFilename string) (*Logger, error) {
-	logger, err := log.NewFileLogger(logFilename, options)
+	f, err := openLogFile(logFilename)
 	if err != nil {
 		return nil, err
 	}
-	printCommitHash(logger)
-	return &Logger{logger}, nil
+	l, err := newLogger(f, logFilename, moduleFromPath(logFilename))
+	if err != nil {
+		return nil, errors.Compose(err, f.Close())
+	}
+	return l, nil
 }
 
 // NewLogger returns a logger that can be closed. Calls should not be made to
-// the logger after 'Close' has been called.
+// the logger after 'Close' has been called. If w is a file, the module of the
+// logger is the name of the file without its extension.
 func NewLogger(w io.Writer) (*Logger, error) {
-	logger, err := log.NewLogger(w, options)
+	var module string
+	if f, ok := w.(*os.File); ok {
+		module = moduleFromPath(f.Name())
+	}
+	return newLogger(w, "", module)
+}
+
+// WithFields returns a logger which attaches the fields to every line it
+// writes in addition to the fields of l. The returned logger shares the log
+// file of l and must not be closed.
+func (l *Logger) WithFields(fields ...LogField) *Logger {
+	allFields := make([]LogField, 0, len(l.staticFields)+len(fields))
+	allFields = append(allFields, l.staticFields...)
+	allFields = append(allFields, fields...)
+	return &Logger{
+		Logger:       l.Logger,
+		staticFields: allFields,
+		staticModule: l.staticModule,
+		staticSink:   l.staticSink,
+	}
+}
+
+// Enabled returns whether lines with the given level are currently written.
+func (l *Logger) Enabled(level LogLevel) bool {
+	return logLevelRanks[level] >= logLevelRanks[logLevel(l.staticModule)]
+}
+
+// fieldValue converts the value of a field to a value that can be encoded.
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case error:
+		return v.Error()
+	case fmt.Stringer:
+		return v.String()
+	case string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// encodeText encodes a log line in the text format. The level is written as
+// the first field.
+func (l *Logger) encodeText(now time.Time, level LogLevel, caller, msg string) []byte {
+	var sb strings.Builder
+	sb.WriteString(now.Format("2006/01/02 15:04:05.000000 "))
+	sb.WriteString(caller + ": " + msg)
+	sb.WriteString(" level=" + string(level))
+	for _, f := range l.staticFields {
+		v := fmt.Sprint(fieldValue(f.Value))
+		if v == "" || strings.ContainsAny(v, " \t\n\"=") {
+			v = strconv.Quote(v)
+		}
+		sb.WriteString(" " + f.Key + "=" + v)
+	}
+	sb.WriteByte('\n')
+	return []byte(sb.String())
+}
+
+// encodeJSON encodes a log line in the JSON format.
+func (l *Logger) encodeJSON(now time.Time, level LogLevel, caller, msg string) []byte {
+	entry := make(map[string]interface{}, len(l.staticFields)+5)
+	for _, f := range l.staticFields {
+		entry[f.Key] = fieldValue(f.Value)
+	}
+	entry["time"] = now.Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["module"] = l.staticModule
+	entry["caller"] = caller
+	entry["msg"] = msg
+	b, err := json.Marshal(entry)
 	if err != nil {
-		return nil, err
+		b, _ = json.Marshal(map[string]interface{}{
+			"time":  entry["time"],
+			"level": level,
+			"msg":   msg,
+			"error": "unable to encode log fields: " + err.Error(),
+		})
+	}
+	return append(b, '\n')
+}
+
+// callerOutsideLog is the calldepth which makes output skip all stack frames
+// within the logging packages to find the caller.
+const callerOutsideLog = -1
+
+// caller returns the file and line of the caller.
+func caller(calldepth int) string {
+	if calldepth != callerOutsideLog {
+		if _, file, line, ok := runtime.Caller(calldepth + 1); ok {
+			return filepath.Base(file) + ":" + strconv.Itoa(line)
+		}
+		return "???:0"
+	}
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "log.") && !strings.Contains(frame.Function, "NebulousLabs/log.") && !strings.Contains(frame.Function, "bigd/persist.") {
+			return filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "???:0"
+		}
 	}
-	printCommitHash(logger)
-	return &Logger{logger}, nil
+}
+
+// output writes a line with the given level if the level is enabled.
+// calldepth is the number of stack frames to skip to find the caller.
+func (l *Logger) output(level LogLevel, calldepth int, msg string) {
+	if !l.Enabled(level) {
+		return
+	}
+	from := caller(calldepth)
+	now := time.Now().UTC()
+	var b []byte
+	if l.staticSink.staticOptions.Format == LogFormatJSON {
+		b = l.encodeJSON(now, level, from, msg)
+	} else {
+		b = l.encodeText(now, level, from, msg)
+	}
+	_, _ = l.staticSink.Write(b)
+}
+
+// messageLevel returns the level of a message logged with one of the Print
+// methods. Messages starting with an "ERROR:" or "WARN:" prefix, which the
+// modules use to mark errors and warnings, get the error or warn level, all
+// other messages the info level.
+func messageLevel(msg string) LogLevel {
+	i := strings.IndexByte(msg, ':')
+	if i < 0 {
+		return LogLevelInfo
+	}
+	switch strings.ToUpper(msg[:i]) {
+	case "ERROR", "CRITICAL", "SEVERE":
+		return LogLevelError
+	case "WARN", "WARNING":
+		return LogLevelWarn
+	}
+	return LogLevelInfo
+}
+
+// Print logs a message with the level of its prefix, see messageLevel.
+// Arguments are handled in the manner of fmt.Print.
+func (l *Logger) Print(v ...interface{}) {
+	msg := fmt.Sprint(v...)
+	l.output(messageLevel(msg), 2, msg)
+}
+
+// Printf logs a message with the level of its prefix, see messageLevel.
+// Arguments are handled in the manner of fmt.Printf.
+func (l *Logger) Printf(format string, v ...interface{}) {
+	msg := fmt.Sprintf(format, v...)
+	l.output(messageLevel(msg), 2, msg)
+}
+
+// Println logs a message with the level of its prefix, see messageLevel.
+// Arguments are handled in the manner of fmt.Println.
+func (l *Logger) Println(v ...interface{}) {
+	msg := strings.TrimSuffix(fmt.Sprintln(v...), "\n")
+	l.output(messageLevel(msg), 2, msg)
+}
+
+// Debug logs a message with the debug level. Arguments are handled in the
+// manner of fmt.Print.
+func (l *Logger) Debug(v ...interface{}) {
+	l.output(LogLevelDebug, 2, fmt.Sprint(v...))
+}
+
+// Debugf logs a message with the debug level. Arguments are handled in the
+// manner of fmt.Printf.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	l.output(LogLevelDebug, 2, fmt.Sprintf(format, v...))
+}
+
+// Debugln logs a message with the debug level. Arguments are handled in the
+// manner of fmt.Println.
+func (l *Logger) Debugln(v ...interface{}) {
+	l.output(LogLevelDebug, 2, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+// Critical logs a message with a CRITICAL prefix that guides the user to the
+// github tracker. If debug mode is enabled, it will also write the message to
+// os.Stderr and panic. Critical should only be called if there has been a
+// developer error, otherwise Severe should be called.
+func (l *Logger) Critical(v ...interface{}) {
+	l.output(LogLevelError, 2, "CRITICAL: "+strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+	options.Critical(v...)
+}
+
+// Severe logs a message with a SEVERE prefix. If debug mode is enabled, it
+// will also write the message to os.Stderr and panic. Severe should be called
+// if there is a severe problem with the user's machine or setup that should be
+// addressed ASAP but does not necessarily require that the machine crash or
+// exit.
+func (l *Logger) Severe(v ...interface{}) {
+	l.output(LogLevelError, 2, "SEVERE: "+strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+	s := fmt.Sprintf("Severe error: %v %v", options.BuildInfoString(), fmt.Sprintln(v...))
+	if options.Release != log.Testing {
+		debug.PrintStack()
+		_, _ = os.Stderr.WriteString(s)
+	}
+	if options.Debug {
+		panic(s)
+	}
+}
+
+// Close logs a shutdown message and closes the Logger's underlying writer, if
+// it is also an io.Closer.
+func (l *Logger) Close() error {
+	l.output(LogLevelInfo, 2, "SHUTDOWN: Logging has terminated.")
+	return l.staticSink.Close()
 }
 
 // buildReleaseType returns the release type for this build, defaulting to
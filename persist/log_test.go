@@ -0,0 +1,220 @@
+package persist
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.thebigfile.com/bigd/build"
+)
+
+// newTestLogger creates a file logger in a new test directory using the given
+// options. The options and levels are reset when the test finishes.
+func newTestLogger(t *testing.T, name string, opts LogOptions) (*Logger, string) {
+	dir := build.TempDir(persistDir, t.Name())
+	if err := os.MkdirAll(dir, defaultDirPermissions); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLogOptions(opts); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = SetLogOptions(LogOptions{Format: LogFormatText})
+		ResetLogLevels()
+	})
+	path := filepath.Join(dir, name)
+	l, err := NewFileLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l, path
+}
+
+// readLogLines reads the lines of a log file.
+func readLogLines(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+// TestLoggerJSON tests writing structured JSON lines with per-module levels.
+func TestLoggerJSON(t *testing.T) {
+	l, path := newTestLogger(t, "renter.log", LogOptions{Format: LogFormatJSON})
+
+	// Debug lines are only written if the module's level allows it.
+	if err := SetLogLevel(DefaultLogModule, LogLevelInfo); err != nil {
+		t.Fatal(err)
+	}
+	fl := l.WithFields(Field(LogKeySiaPath, "foo/bar"), Field(LogKeyError, errors.New("failed")))
+	fl.Debugln("hidden")
+	fl.Println("visible", 1)
+	if err := SetLogLevel("renter", LogLevelDebug); err != nil {
+		t.Fatal(err)
+	}
+	fl.Debugf("debug %v", 2)
+	if err := SetLogLevel("renter", "verbose"); err != ErrInvalidLogLevel {
+		t.Fatal("expected ErrInvalidLogLevel but got", err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLogLines(t, path)
+	if len(lines) != 5 {
+		t.Fatalf("expected 5 lines but got %v: %v", len(lines), lines)
+	}
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err, line)
+		}
+		if entry["module"] != "renter" {
+			t.Fatal("unexpected module", entry)
+		}
+		entries = append(entries, entry)
+	}
+	if msg := entries[0]["msg"].(string); !strings.HasPrefix(msg, "STARTUP: Logging has started") {
+		t.Fatal("unexpected first line", entries[0])
+	}
+	visible := entries[2]
+	if visible["msg"] != "visible 1" || visible["level"] != "info" || visible["siapath"] != "foo/bar" || visible["error"] != "failed" {
+		t.Fatal("unexpected line", visible)
+	}
+	if caller := visible["caller"].(string); !strings.HasPrefix(caller, "log_test.go:") {
+		t.Fatal("unexpected caller", caller)
+	}
+	if debug := entries[3]; debug["msg"] != "debug 2" || debug["level"] != "debug" {
+		t.Fatal("unexpected line", debug)
+	}
+	if shutdown := entries[4]; shutdown["msg"] != "SHUTDOWN: Logging has terminated." {
+		t.Fatal("unexpected last line", shutdown)
+	}
+}
+
+// TestLoggerText tests that fields are appended to lines in the text format.
+func TestLoggerText(t *testing.T) {
+	l, path := newTestLogger(t, "host.log", LogOptions{Format: LogFormatText})
+	l.WithFields(Field(LogKeyHostKey, "ed25519:abc"), Field(LogKeyError, errors.New("no space"))).Printf("failed to store %v", "sector")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := readLogLines(t, path)
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 lines but got %v: %v", len(lines), lines)
+	}
+	if !strings.Contains(lines[2], `log_test.go:`) || !strings.HasSuffix(lines[2], `: failed to store sector level=info hostkey=ed25519:abc error="no space"`) {
+		t.Fatal("unexpected line", lines[2])
+	}
+}
+
+// TestLoggerDebug tests that all debug methods write their lines the same way.
+func TestLoggerDebug(t *testing.T) {
+	l, path := newTestLogger(t, "renter.log", LogOptions{Format: LogFormatText})
+	if err := SetLogLevel(DefaultLogModule, LogLevelDebug); err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("debug ", 1)
+	l.Debugf("debug %v", 1)
+	l.Debugln("debug", 1)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	lines := readLogLines(t, path)
+	if len(lines) != 6 {
+		t.Fatalf("expected 6 lines but got %v: %v", len(lines), lines)
+	}
+	for _, line := range lines[2:5] {
+		if !strings.HasSuffix(line, ": debug 1 level=debug") {
+			t.Fatal("unexpected line", line)
+		}
+	}
+}
+
+// TestLoggerPrefixLevels tests that lines with an error or warning prefix are
+// logged with the error or warn level.
+func TestLoggerPrefixLevels(t *testing.T) {
+	l, path := newTestLogger(t, "host.log", LogOptions{Format: LogFormatText})
+	l.Println("ERROR: unable to store sector")
+	l.Printf("WARN: low on %v", "storage")
+	l.Print("WARNING: slow disk")
+	l.Println("Error: unable to sync")
+	l.Println("INFO: sector stored")
+	l.Println("no prefix: here")
+	if err := SetLogLevel("host", LogLevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	l.Println("hidden")
+	l.Println("WARN: visible")
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLogLines(t, path)
+	expected := []string{
+		"ERROR: unable to store sector level=error",
+		"WARN: low on storage level=warn",
+		"WARNING: slow disk level=warn",
+		"Error: unable to sync level=error",
+		"INFO: sector stored level=info",
+		"no prefix: here level=info",
+		"WARN: visible level=warn",
+	}
+	if len(lines) != len(expected)+2 {
+		t.Fatalf("expected %v lines but got %v: %v", len(expected)+2, len(lines), lines)
+	}
+	for i, suffix := range expected {
+		if !strings.HasSuffix(lines[i+2], ": "+suffix) {
+			t.Errorf("expected line to end with %q but got %q", suffix, lines[i+2])
+		}
+	}
+}
+
+// TestLogRotation tests rotating log files by size.
+func TestLogRotation(t *testing.T) {
+	l, path := newTestLogger(t, "wallet.log", LogOptions{Format: LogFormatText, MaxSize: 200})
+	for i := 0; i < 10; i++ {
+		l.Println(strings.Repeat("a", 50))
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fis, err := ioutil.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) < 3 {
+		t.Fatal("expected log to be rotated", len(fis))
+	}
+	var lines int
+	for _, fi := range fis {
+		if !strings.HasPrefix(fi.Name(), "wallet.log") {
+			t.Fatal("unexpected file", fi.Name())
+		}
+		if fi.Size() > 200 {
+			t.Fatal("log file exceeds max size", fi.Name(), fi.Size())
+		}
+		lines += len(readLogLines(t, filepath.Join(filepath.Dir(path), fi.Name())))
+	}
+	// The startup lines, 10 lines and the shutdown line.
+	if lines != 13 {
+		t.Fatal("expected 13 lines but got", lines)
+	}
+}
@@ -13,9 +13,12 @@ import (
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/node"
+	"go.thebigfile.com/bigd/node/api"
 	"go.thebigfile.com/bigd/node/api/client"
+	"go.thebigfile.com/bigd/persist"
 	"go.thebigfile.com/bigd/profile"
 	"go.thebigfile.com/bigd/siatest"
+	"go.thebigfile.com/bigd/types"
 )
 
 // TestDaemonAPIPassword makes sure that the daemon rejects requests with the
@@ -368,3 +371,205 @@ func TestDaemonProfile(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestDaemonAPITokens tests that API tokens are limited to the routes of their
+// scopes and that they are persisted.
+func TestDaemonAPITokens(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Wallet(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a read-only token for monitoring.
+	dtc, err := testNode.DaemonTokensCreatePost("monitoring", []api.APIScope{api.ScopeReadOnly}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitoring := testNode.Client
+	monitoring.Password = dtc.Token
+
+	// The token can read the wallet's balance but not spend from it, list its
+	// outputs or reveal its seeds.
+	if _, err := monitoring.WalletGet(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitoring.WalletUnspentGet(); err == nil || !strings.Contains(err.Error(), string(api.ScopeWalletSpend)) {
+		t.Fatal("read-only token shouldn't be able to list the unspent outputs", err)
+	}
+	if _, err := monitoring.WalletSeedsGet(); err == nil {
+		t.Fatal("read-only token shouldn't be able to get the seeds")
+	}
+	if _, err := monitoring.WalletSiacoinsPost(types.SiacoinPrecision, types.UnlockHash{}, false); err == nil || !strings.Contains(err.Error(), string(api.ScopeWalletSpend)) {
+		t.Fatal("read-only token shouldn't be able to spend", err)
+	}
+	if err := monitoring.DaemonStopGet(); err == nil {
+		t.Fatal("read-only token shouldn't be able to stop the daemon")
+	}
+
+	// Tokens can't manage tokens.
+	if _, err := monitoring.DaemonTokensGet(); err == nil {
+		t.Fatal("tokens shouldn't be able to list tokens")
+	}
+
+	// The tokens are persisted and revoking a token prevents its use.
+	if err := testNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	monitoring.Address = testNode.Address
+	if _, err := monitoring.WalletUnspentGet(); err == nil || !strings.Contains(err.Error(), string(api.ScopeWalletSpend)) {
+		t.Fatal("expected the token to be authenticated but lack the wallet-spend scope", err)
+	}
+	dtg, err := testNode.DaemonTokensGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dtg.Tokens) != 1 || dtg.Tokens[0].Name != "monitoring" {
+		t.Fatal("unexpected tokens", dtg.Tokens)
+	}
+	if err := testNode.DaemonTokensRevokePost("monitoring"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := monitoring.WalletUnspentGet(); err == nil || !strings.Contains(err.Error(), "API authentication failed") {
+		t.Fatal("revoked token shouldn't be able to authenticate", err)
+	}
+}
+
+// TestDaemonMetrics tests the /metrics endpoint.
+func TestDaemonMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server with all modules
+	testNode, err := siatest.NewCleanNode(node.AllModules(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	metrics, err := testNode.MetricsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, metric := range []string{
+		"# TYPE siad_api_request_duration_seconds histogram",
+		"siad_consensus_height ",
+		`siad_gateway_peers{direction="inbound"} `,
+		`siad_host_rpc_calls_total{rpc="settings"} `,
+		"siad_host_storage_capacity_bytes ",
+		"siad_renter_workers ",
+		`siad_renter_worker_job_seconds_bucket{job="read64k",le="+Inf"} `,
+		`siad_renter_memory_available_bytes{manager="system"} `,
+		"siad_tpool_transactions ",
+	} {
+		if !strings.Contains(metrics, metric) {
+			t.Errorf("metrics are missing %q", metric)
+		}
+	}
+
+	// The request to /metrics is counted by the next request.
+	metrics, err = testNode.MetricsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(metrics, `siad_api_requests_total{method="GET",code="200"} `) {
+		t.Error("request wasn't counted")
+	}
+
+	// The metrics require authentication.
+	c := testNode.Client
+	c.Password = ""
+	if _, err := c.MetricsGet(); err == nil {
+		t.Fatal("metrics shouldn't be available without the password")
+	}
+
+	// A read-only API token can scrape the metrics.
+	dtc, err := testNode.DaemonTokensCreatePost("monitoring", []api.APIScope{api.ScopeReadOnly}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Password = dtc.Token
+	if _, err := c.MetricsGet(); err != nil {
+		t.Fatal("read-only token should be able to scrape the metrics", err)
+	}
+}
+
+// TestDaemonLogLevels tests changing the log levels of the modules through
+// the /daemon/settings endpoint.
+func TestDaemonLogLevels(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// The log levels are global, reset them once the test is done.
+	defer persist.ResetLogLevels()
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Gateway(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Set the levels.
+	err = testNode.DaemonLogLevelsPost(map[string]persist.LogLevel{
+		persist.DefaultLogModule: persist.LogLevelError,
+		"gateway":                persist.LogLevelDebug,
+		"renter":                 persist.LogLevelInfo,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dsg, err := testNode.DaemonSettingsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsg.LogLevels[persist.DefaultLogModule] != persist.LogLevelError || dsg.LogLevels["gateway"] != persist.LogLevelDebug || dsg.LogLevels["renter"] != persist.LogLevelInfo {
+		t.Fatal("unexpected log levels", dsg.LogLevels)
+	}
+
+	// Invalid levels are rejected.
+	if err := testNode.DaemonLogLevelsPost(map[string]persist.LogLevel{"gateway": "verbose"}); err == nil {
+		t.Fatal("invalid log level was accepted")
+	}
+
+	// Reset the level of the renter and restart the node. The levels are
+	// persisted.
+	if err := testNode.DaemonLogLevelsPost(map[string]persist.LogLevel{"renter": ""}); err != nil {
+		t.Fatal(err)
+	}
+	persist.ResetLogLevels()
+	if err := testNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	dsg, err = testNode.DaemonSettingsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := dsg.LogLevels["renter"]; ok || dsg.LogLevels[persist.DefaultLogModule] != persist.LogLevelError || dsg.LogLevels["gateway"] != persist.LogLevelDebug {
+		t.Fatal("log levels weren't persisted", dsg.LogLevels)
+	}
+}
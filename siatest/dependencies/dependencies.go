@@ -308,6 +308,12 @@ func NewDependencyInterruptUploadAfterSendingRevision() *DependencyInterruptOnce
 	return newDependencyInterruptOnceOnKeyword("InterruptUploadAfterSendingRevision")
 }
 
+// NewDependencyInterruptReencodeSwap creates a new dependency that interrupts
+// the swap of a re-encoded file after the original was moved out of the way.
+func NewDependencyInterruptReencodeSwap() *DependencyInterruptOnceOnKeyword {
+	return newDependencyInterruptOnceOnKeyword("InterruptReencodeSwap")
+}
+
 // newDependencyInterruptOnceOnKeyword creates a new
 // DependencyInterruptOnceOnKeyword from a given disrupt key.
 func newDependencyInterruptOnceOnKeyword(str string) *DependencyInterruptOnceOnKeyword {
@@ -0,0 +1,119 @@
+package renter
+
+import (
+	"testing"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/siatest"
+)
+
+// TestRenterBulkOperations tests executing bulk operations against the
+// renter's filesystem.
+func TestRenterBulkOperations(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup.
+	gp := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Create a local directory with a subdirectory.
+	ld, err := r.FilesDir().CreateDir("bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub, err := ld.CreateDir("sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ld.NewFileWithName("a.txt", 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ld.NewFileWithName("b.dat", 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sub.NewFileWithName("c.txt", 100); err != nil {
+		t.Fatal(err)
+	}
+	bulkDir, err := modules.NewSiaPath("bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	movedDir, err := modules.NewSiaPath("moved")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// checkResults is a helper to check the number of succeeded and failed
+	// items of a bulk request.
+	checkResults := func(ops []modules.BulkOperation, succeeded, failed uint64) {
+		t.Helper()
+		rbr, err := r.RenterBulkPost(ops, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rbr.Succeeded != succeeded || rbr.Failed != failed {
+			t.Fatalf("expected %v succeeded and %v failed items but got %+v", succeeded, failed, rbr)
+		}
+	}
+
+	// Upload the local directory and mark all of its files as stuck within
+	// a single request.
+	checkResults([]modules.BulkOperation{
+		{Type: modules.BulkOperationUpload, SiaPath: bulkDir, LocalPath: ld.Path()},
+		{Type: modules.BulkOperationSetStuck, SiaPath: bulkDir, Stuck: true},
+	}, 6, 0)
+
+	// Move the .txt files of the top level into another folder.
+	checkResults([]modules.BulkOperation{
+		{Type: modules.BulkOperationRename, SiaPath: bulkDir, Glob: "*.txt", NewSiaPath: movedDir},
+	}, 1, 0)
+	movedFile, err := movedDir.Join("a.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RenterFileGet(movedFile); err != nil {
+		t.Fatal("moved file not found", err)
+	}
+
+	// Delete the remaining .txt files recursively as well as the moved
+	// folder. Deleting a file which doesn't exist should fail.
+	missing, err := modules.NewSiaPath("missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkResults([]modules.BulkOperation{
+		{Type: modules.BulkOperationDelete, SiaPath: bulkDir, Glob: "*.txt", Recursive: true},
+		{Type: modules.BulkOperationDelete, SiaPath: movedDir},
+		{Type: modules.BulkOperationDelete, SiaPath: missing},
+	}, 2, 1)
+
+	// Only b.dat should be left.
+	files, err := r.Files(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].SiaPath.Name() != "b.dat" {
+		t.Fatal("unexpected files", files)
+	}
+
+	// Invalid operations should be rejected.
+	if _, err := r.RenterBulkPost([]modules.BulkOperation{{Type: "foo"}}, false); err == nil {
+		t.Fatal("expected invalid operation to be rejected")
+	}
+}
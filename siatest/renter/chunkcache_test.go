@@ -0,0 +1,136 @@
+package renter
+
+import (
+	"testing"
+
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/siatest"
+)
+
+// TestRenterChunkCache tests that chunks which are downloaded in full are added
+// to the renter's chunk cache and that downloads and streams are served from
+// it.
+func TestRenterChunkCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup.
+	gp := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// The cache should be disabled by default.
+	ccs, err := r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.MaxSize != 0 {
+		t.Fatal("chunk cache should be disabled by default", ccs.MaxSize)
+	}
+
+	// Enable the cache.
+	cacheSize := uint64(1 << 30)
+	if err := r.RenterChunkCacheSizePost(cacheSize); err != nil {
+		t.Fatal(err)
+	}
+	rg, err := r.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.ChunkCacheSize != cacheSize {
+		t.Fatalf("expected cache size %v but got %v", cacheSize, rg.Settings.ChunkCacheSize)
+	}
+
+	// Upload a file with multiple chunks.
+	fileSize := int(2*modules.SectorSize) + siatest.Fuzz()
+	localFile, remoteFile, err := r.UploadNewFileBlocking(fileSize, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	numChunks := uint64(fileSize) / modules.SectorSize
+	if uint64(fileSize)%modules.SectorSize != 0 {
+		numChunks++
+	}
+
+	// Stream a small range of the file. Only a range of the first chunk is
+	// downloaded, so it shouldn't be added to the cache.
+	if _, err := r.StreamPartial(remoteFile, localFile, 1, 100); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err = r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.NumChunks != 0 || ccs.Misses != 1 || ccs.Hits != 0 || ccs.Size != 0 {
+		t.Fatal("unexpected cache status after partial stream", ccs)
+	}
+
+	// Download the whole file. All chunks should be added to the cache.
+	if _, _, err := r.DownloadByStream(remoteFile); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err = r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.NumChunks != numChunks || ccs.Hits != 0 {
+		t.Fatal("unexpected cache status after download", ccs)
+	}
+
+	// Take down the hosts. Downloading the file should still work since all
+	// chunks are cached.
+	for _, host := range tg.Hosts() {
+		if err := tg.RemoveNode(host); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err := r.DownloadByStream(remoteFile); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err = r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.Hits != numChunks {
+		t.Fatalf("expected %v hits but got %v", numChunks, ccs.Hits)
+	}
+
+	// Partial streams are served from the cache as well.
+	hits := ccs.Hits
+	if _, err := r.StreamPartial(remoteFile, localFile, 1, 100); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err = r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.Hits <= hits {
+		t.Fatal("partial stream wasn't served from the cache", ccs)
+	}
+
+	// Disabling the cache should remove all chunks.
+	if err := r.RenterChunkCacheSizePost(0); err != nil {
+		t.Fatal(err)
+	}
+	ccs, err = r.RenterChunkCacheGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ccs.NumChunks != 0 || ccs.Size != 0 {
+		t.Fatal("cache should be empty", ccs)
+	}
+}
@@ -71,6 +71,8 @@ func TestContractorOne(t *testing.T) {
 	subTests := []test{
 		{"TestContractFunding", testContractFunding},
 		{"TestContractorIncompleteMaintenanceAlert", testContractorIncompleteMaintenanceAlert},
+		{"TestContractEvents", testContractEvents},
+		{"TestSpendingCaps", testSpendingCaps},
 	}
 
 	// Run tests
@@ -128,6 +130,147 @@ func testContractFunding(t *testing.T, tg *siatest.TestGroup) {
 	}
 }
 
+// testContractEvents tests that contract formation and cancellation are
+// recorded in the contract event journal.
+func testContractEvents(t *testing.T, tg *siatest.TestGroup) {
+	r := tg.Renters()[0]
+	rc, err := r.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rc.ActiveContracts) == 0 {
+		t.Fatal("expected an active contract")
+	}
+	contract := rc.ActiveContracts[0]
+
+	// The formation of the contract should be recorded. The contract might
+	// have been renewed or refreshed already.
+	formed := modules.ContractEventFilter{
+		Types:      []modules.ContractEventType{modules.ContractEventFormed, modules.ContractEventRenewed, modules.ContractEventRefreshed},
+		ContractID: contract.ID,
+	}
+	rce, err := r.RenterContractEventsGet(formed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rce.Events) != 1 {
+		t.Fatalf("expected 1 formation event but got %v", len(rce.Events))
+	}
+	e := rce.Events[0]
+	if e.ContractID != contract.ID || !e.HostPublicKey.Equals(contract.HostPublicKey) || !e.Funds.Equals(contract.TotalCost) || e.BlockHeight == 0 {
+		t.Fatal("unexpected formation event", e)
+	}
+	rce, err = r.RenterContractEventsGet(modules.ContractEventFilter{HostPublicKey: contract.HostPublicKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rce.Events) == 0 || rce.Events[0].Type != modules.ContractEventFormed {
+		t.Fatal("expected the first event of the host to be a formation", rce.Events)
+	}
+
+	// Cancel the contract. The loss of utility should be recorded together
+	// with the reason.
+	if err := r.RenterContractCancelPost(contract.ID); err != nil {
+		t.Fatal(err)
+	}
+	rce, err = r.RenterContractEventsGet(modules.ContractEventFilter{
+		Types:      []modules.ContractEventType{modules.ContractEventNotGoodForUpload, modules.ContractEventNotGoodForRenew},
+		ContractID: contract.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rce.Events) != 2 {
+		t.Fatalf("expected 2 utility events but got %v", len(rce.Events))
+	}
+	for _, e := range rce.Events {
+		if e.Reason != "contract was canceled" {
+			t.Fatal("unexpected reason", e.Reason)
+		}
+	}
+
+	// Unknown event types are rejected.
+	_, err = r.RenterContractEventsGet(modules.ContractEventFilter{Types: []modules.ContractEventType{"unknown"}})
+	if err == nil {
+		t.Fatal("expected unknown event type to be rejected")
+	}
+}
+
+// testSpendingCaps tests that setting the allowance's spending caps registers
+// an alert once a cap is reached and unregisters it once the cap is removed.
+func testSpendingCaps(t *testing.T, tg *siatest.TestGroup) {
+	r := tg.Renters()[0]
+
+	// Set a fee cap which has already been reached by forming the contract.
+	err := r.RenterPostPartialAllowance().WithMaxFeeSpending(types.NewCurrency64(1)).WithSpendingAlertThreshold(0.5).Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rg, err := r.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := rg.Settings.Allowance
+	if !a.MaxFeeSpending.Equals64(1) || a.SpendingAlertThreshold != 0.5 || !a.MaxUploadSpending.IsZero() {
+		t.Fatal("spending caps weren't set", a.MaxFeeSpending, a.SpendingAlertThreshold, a.MaxUploadSpending)
+	}
+
+	// Check for the alert.
+	spendingCapAlert := func() (modules.Alert, bool, error) {
+		dag, err := r.DaemonAlertsGet()
+		if err != nil {
+			return modules.Alert{}, false, err
+		}
+		for _, alert := range dag.Alerts {
+			if alert.Module == "contractor" && alert.Msg == contractor.AlertMSGSpendingCapReached {
+				return alert, true, nil
+			}
+		}
+		return modules.Alert{}, false, nil
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		alert, ok, err := spendingCapAlert()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("spending cap alert isn't registered")
+		}
+		if alert.Severity != modules.SeverityError {
+			return fmt.Errorf("unexpected severity %v", alert.Severity)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An invalid threshold is rejected.
+	err = r.RenterPostPartialAllowance().WithSpendingAlertThreshold(2).Send()
+	if err == nil {
+		t.Fatal("expected invalid threshold to be rejected")
+	}
+
+	// Remove the cap again.
+	err = r.RenterPostPartialAllowance().WithMaxFeeSpending(types.ZeroCurrency).WithSpendingAlertThreshold(0).Send()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		_, ok, err := spendingCapAlert()
+		if err != nil {
+			return err
+		}
+		if ok {
+			return errors.New("spending cap alert is still registered")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 // testContractorIncompleteMaintenanceAlert tests that having the wallet locked
 // during maintenance results in an alert.
 func testContractorIncompleteMaintenanceAlert(t *testing.T, tg *siatest.TestGroup) {
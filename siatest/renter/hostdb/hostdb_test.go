@@ -2,8 +2,10 @@ package hostdb
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"testing"
 	"time"
@@ -1246,3 +1248,161 @@ func TestNetAddressFilterContracts(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestHostSelectionPolicy tests setting the host selection policy through the
+// API and that it is applied to the host scores and persisted.
+func TestHostSelectionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for testing
+	groupParams := siatest.GroupParams{
+		Hosts:   1,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostdbTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal(errors.AddContext(err, "failed to create group"))
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	renter := tg.Renters()[0]
+	pk, err := tg.Hosts()[0].HostPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The default policy shouldn't affect the score.
+	hdpg, err := renter.HostDbPolicyGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hdpg.Policy, modules.DefaultHostSelectionPolicy()) {
+		t.Fatal("expected default policy", hdpg.Policy)
+	}
+	hhg, err := renter.HostDbHostsGet(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hhg.ScoreBreakdown.PolicyAdjustment != 1 {
+		t.Fatal("expected policy adjustment of 1 but got", hhg.ScoreBreakdown.PolicyAdjustment)
+	}
+
+	// Set a storage price cap below the host's price.
+	policy := hdpg.Policy
+	policy.MaxStoragePrice = hhg.Entry.StoragePrice.Sub64(1)
+	policy.Weights.Price = 2
+	if err := renter.HostDbPolicyPost(policy); err != nil {
+		t.Fatal(err)
+	}
+	hhg, err = renter.HostDbHostsGet(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hhg.ScoreBreakdown.PolicyAdjustment != math.SmallestNonzeroFloat64 {
+		t.Fatal("expected minimal policy adjustment but got", hhg.ScoreBreakdown.PolicyAdjustment)
+	}
+
+	// Invalid policies should be rejected.
+	invalid := policy
+	invalid.MinUptime = 2
+	if err := renter.HostDbPolicyPost(invalid); err == nil {
+		t.Fatal("expected invalid policy to be rejected")
+	}
+	invalid = policy
+	invalid.PreferredRegions = []string{"DE"}
+	if err := renter.HostDbPolicyPost(invalid); err == nil {
+		t.Fatal("expected policy without location database to be rejected")
+	}
+
+	// The policy should be persisted.
+	if err := renter.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	hdpg, err = renter.HostDbPolicyGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(hdpg.Policy, policy) {
+		t.Fatalf("policy wasn't persisted: expected %+v but got %+v", policy, hdpg.Policy)
+	}
+	hhg, err = renter.HostDbHostsGet(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hhg.ScoreBreakdown.PolicyAdjustment != math.SmallestNonzeroFloat64 {
+		t.Fatal("expected minimal policy adjustment after restart but got", hhg.ScoreBreakdown.PolicyAdjustment)
+	}
+}
+
+// TestHostPerformanceMetrics checks that the hostdb measures the latencies of
+// hosts during scans and their throughputs during uploads and downloads.
+func TestHostPerformanceMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for testing
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := hostdbTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal(errors.AddContext(err, "failed to create group"))
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	renter := tg.Renters()[0]
+	pk, err := tg.Hosts()[0].HostPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host was scanned, so the latencies should be known.
+	hhg, err := renter.HostDbHostsGet(pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hhg.Entry.RTT <= 0 || hhg.Entry.PriceTableLatency <= 0 {
+		t.Fatal("expected latencies to be measured", hhg.Entry.RTT, hhg.Entry.PriceTableLatency)
+	}
+
+	// Upload and download a file to measure the throughputs.
+	_, rf, err := renter.UploadNewFileBlocking(int(modules.SectorSize), 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := renter.DownloadByStream(rf); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		hhg, err = renter.HostDbHostsGet(pk)
+		if err != nil {
+			return err
+		}
+		if hhg.Entry.UploadThroughput <= 0 || hhg.Entry.DownloadThroughput <= 0 {
+			return fmt.Errorf("throughputs weren't measured: %v %v", hhg.Entry.UploadThroughput, hhg.Entry.DownloadThroughput)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hhg.ScoreBreakdown.PerformanceAdjustment <= 0 || hhg.ScoreBreakdown.PerformanceAdjustment > 1 {
+		t.Fatal("invalid performance adjustment", hhg.ScoreBreakdown.PerformanceAdjustment)
+	}
+}
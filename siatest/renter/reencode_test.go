@@ -0,0 +1,140 @@
+package renter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/crypto"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/siatest"
+)
+
+// TestRenterReencode tests migrating files to new erasure code settings and
+// cipher types.
+func TestRenterReencode(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup.
+	gp := siatest.GroupParams{
+		Hosts:   4,
+		Renters: 1,
+		Miners:  1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Upload a file with 1-of-3 redundancy.
+	fileSize := int(2*modules.SectorSize) + siatest.Fuzz()
+	localFile, remoteFile, err := r.UploadNewFileBlocking(fileSize, 1, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Move the local file to force the data to be fetched from the hosts.
+	if err := localFile.Move(); err != nil {
+		t.Fatal(err)
+	}
+	// Move the remote file into a folder.
+	dir := modules.RandomSiaPath()
+	if err := r.RenterDirCreatePost(dir); err != nil {
+		t.Fatal(err)
+	}
+	newSiaPath, err := dir.Join(remoteFile.SiaPath().Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteFile, err = r.Rename(remoteFile, newSiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-encoding a folder without any settings should fail.
+	err = r.RenterDirReencodePost(dir, 0, 0, crypto.TypeInvalid)
+	if err == nil {
+		t.Fatal("expected re-encoding without settings to fail")
+	}
+
+	// Re-encode the file to 2-of-4.
+	err = r.RenterFileReencodePost(remoteFile.SiaPath(), 2, 2, crypto.TypeInvalid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := r.File(remoteFile)
+		if err != nil {
+			return err
+		}
+		if fi.Redundancy != 2 {
+			return fmt.Errorf("expected redundancy 2 but got %v", fi.Redundancy)
+		}
+		if fi.CipherType != crypto.TypeDefaultRenter.String() {
+			return fmt.Errorf("cipher type shouldn't have changed but got %v", fi.CipherType)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := r.DownloadByStream(remoteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := localFile.Equal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// Re-encode the file's folder to a new cipher type.
+	err = r.RenterDirReencodePost(dir, 0, 0, crypto.TypePlain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := r.File(remoteFile)
+		if err != nil {
+			return err
+		}
+		if fi.CipherType != crypto.TypePlain.String() {
+			return fmt.Errorf("expected cipher type %v but got %v", crypto.TypePlain, fi.CipherType)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, data, err = r.DownloadByStream(remoteFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := localFile.Equal(data); err != nil {
+		t.Fatal(err)
+	}
+
+	// The folder should only contain the re-encoded file.
+	rd, err := r.RenterDirGet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) != 1 {
+		t.Fatalf("expected 1 file in the folder but got %v", len(rd.Files))
+	}
+	// The re-encoding folder should be empty.
+	rd, err = r.RenterDirRootGet(modules.ReencodeFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) != 0 {
+		t.Fatalf("expected no files in the re-encoding folder but got %v", len(rd.Files))
+	}
+}
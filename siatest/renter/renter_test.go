@@ -278,6 +278,7 @@ func TestRenterFour(t *testing.T) {
 		{Name: "TestPauseAndResumeRepairAndUploads", Test: testPauseAndResumeRepairAndUploads},
 		{Name: "TestDownloadServedFromDisk", Test: testDownloadServedFromDisk},
 		{Name: "TestDirMode", Test: testDirMode},
+		{Name: "TestTenants", Test: testTenants},
 		{Name: "TestEscapeSiaPath", Test: testEscapeSiaPath}, // Runs last because it uploads many files
 	}
 
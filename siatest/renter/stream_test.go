@@ -3,16 +3,20 @@ package renter
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
 
 	"go.thebigfile.com/bigd/build"
 	"go.thebigfile.com/bigd/crypto"
 	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/modules/renter"
 	"go.thebigfile.com/bigd/node"
 	"go.thebigfile.com/bigd/siatest"
 	"go.thebigfile.com/bigd/siatest/dependencies"
@@ -182,6 +186,7 @@ func TestRenterStream(t *testing.T) {
 		{Name: "TestStreamRepair", Test: testStreamRepair},
 		{Name: "TestUploadStreaming", Test: testUploadStreaming},
 		{Name: "TestUploadStreamingWithBadDeps", Test: testUploadStreamingWithBadDeps},
+		{Name: "TestUploadStreamingResumable", Test: testUploadStreamingResumable},
 	}
 
 	// Run tests
@@ -378,3 +383,121 @@ func testUploadStreamingWithBadDeps(t *testing.T, tg *siatest.TestGroup) {
 		t.Fatal("dependency injection should have caused the upload to fail")
 	}
 }
+
+// interruptedReader is a reader which returns an error after reading n bytes
+// from the underlying reader to simulate a dropped connection.
+type interruptedReader struct {
+	r io.Reader
+	n int
+}
+
+// Read implements the io.Reader interface.
+func (ir *interruptedReader) Read(b []byte) (int, error) {
+	if ir.n <= 0 {
+		return 0, errors.New("connection dropped")
+	}
+	if len(b) > ir.n {
+		b = b[:ir.n]
+	}
+	n, err := ir.r.Read(b)
+	ir.n -= n
+	return n, err
+}
+
+// testUploadStreamingResumable uploads random data using a resumable upload
+// which is interrupted midway.
+func testUploadStreamingResumable(t *testing.T, tg *siatest.TestGroup) {
+	r := tg.Renters()[0]
+
+	// Create some random data to write.
+	fileSize := 2*int(modules.SectorSize) + siatest.Fuzz() + 2
+	data := fastrand.Bytes(fileSize)
+
+	// Create the upload.
+	siaPath, err := modules.NewSiaPath("resumable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := r.RenterUploadStreamResumablePost(siaPath, 1, uint64(len(tg.Hosts())-1), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Offset != 0 {
+		t.Fatal("expected offset 0 but got", info.Offset)
+	}
+
+	// Write the first half of a chunk and the first half of the data.
+	half := int(info.ChunkSize) / 2
+	info, err = r.RenterUploadResumablePut(info.ID, 0, bytes.NewReader(data[:half]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Offset != uint64(half) {
+		t.Fatalf("expected offset %v but got %v", half, info.Offset)
+	}
+
+	// Writing beyond the committed offset should fail.
+	_, err = r.RenterUploadResumablePut(info.ID, info.Offset+1, bytes.NewReader(data))
+	if err == nil || !strings.Contains(err.Error(), renter.ErrResumableUploadOffsetMismatch.Error()) {
+		t.Fatal("expected offset mismatch but got", err)
+	}
+
+	// Resending committed data is a no-op.
+	info, err = r.RenterUploadResumablePut(info.ID, 0, bytes.NewReader(data[:half/2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Offset != uint64(half) {
+		t.Fatalf("expected offset %v but got %v", half, info.Offset)
+	}
+
+	// Interrupt the next write after a chunk and a bit.
+	ir := &interruptedReader{r: bytes.NewReader(data[half:]), n: int(info.ChunkSize) + half/2}
+	_, err = r.RenterUploadResumablePut(info.ID, info.Offset, ir)
+	if err == nil {
+		t.Fatal("interrupted write should fail")
+	}
+
+	// Query the committed offset and resume from there.
+	info, err = r.RenterUploadResumableGet(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Offset < uint64(half) || info.Offset > uint64(len(data)) {
+		t.Fatal("unexpected offset after interruption", info.Offset)
+	}
+	// Resume from slightly before the committed offset, the committed bytes
+	// are skipped.
+	resumeOffset := info.Offset - uint64(half/2)
+	info, err = r.RenterUploadResumablePut(info.ID, resumeOffset, bytes.NewReader(data[resumeOffset:]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Offset != uint64(len(data)) {
+		t.Fatalf("expected offset %v but got %v", len(data), info.Offset)
+	}
+
+	// Finalize the upload. Afterwards the upload should be gone.
+	if err := r.RenterUploadResumableFinalizePost(info.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RenterUploadResumableGet(info.ID); err == nil {
+		t.Fatal("upload should be gone after finalizing it")
+	}
+
+	// Download the file and compare it to the original data.
+	rfg, err := r.RenterFileGet(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rfg.File.Filesize != uint64(len(data)) {
+		t.Fatalf("expected file size %v but got %v", len(data), rfg.File.Filesize)
+	}
+	_, downloadedData, err := r.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(data)), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, downloadedData) {
+		t.Fatal("Downloaded data doesn't match uploaded data")
+	}
+}
@@ -0,0 +1,122 @@
+package renter
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/siatest"
+)
+
+// testTenants tests that tenants are restricted to their root directory and
+// that their traffic counts towards their quota.
+func testTenants(t *testing.T, tg *siatest.TestGroup) {
+	r := tg.Renters()[0]
+
+	// Create a tenant.
+	rtc, err := r.RenterTenantsCreatePost("tenant", modules.TenantQuota{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := r.Client
+	tc.Username = rtc.Name
+	tc.Password = rtc.Token
+
+	// Upload a file as the tenant.
+	siaPath := modules.RandomSiaPath()
+	data := fastrand.Bytes(int(modules.SectorSize))
+	err = tc.RenterUploadStreamPost(bytes.NewReader(data), siaPath, 1, uint64(len(tg.Hosts())-1), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file is within the tenant's root.
+	if _, err := tc.RenterFileGet(siaPath); err != nil {
+		t.Fatal(err)
+	}
+	tenantPath, err := rtc.Root.Join(siaPath.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RenterFileRootGet(tenantPath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.RenterFileGet(siaPath); err == nil {
+		t.Fatal("tenant file shouldn't be in the user folder")
+	}
+
+	// The tenant can't access the root.
+	if _, err := tc.RenterFileRootGet(tenantPath); err == nil {
+		t.Fatal("tenant shouldn't be able to use the root parameter")
+	}
+
+	// Download the file.
+	_, downloaded, err := tc.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(data)), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded, data) {
+		t.Fatal("downloaded data doesn't match")
+	}
+	ti, err := tc.RenterTenantGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Usage.Uploaded != uint64(len(data)) || ti.Usage.Downloaded != uint64(len(data)) {
+		t.Fatal("unexpected usage", ti.Usage)
+	}
+
+	// A download can't exceed the remaining download quota.
+	quota := modules.TenantQuota{Download: uint64(len(data)) * 3 / 2}
+	if err := r.RenterTenantsQuotaPost(rtc.Name, quota); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := tc.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(data)), true, false); err == nil {
+		t.Fatal("expected download to exceed the quota")
+	}
+	ti, err = tc.RenterTenantGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Usage.Downloaded != quota.Download {
+		t.Fatalf("expected %v downloaded bytes but got %v", quota.Download, ti.Usage.Downloaded)
+	}
+
+	// Once the quota is used up, uploads and downloads fail.
+	quota = modules.TenantQuota{Upload: uint64(len(data)), Download: uint64(len(data))}
+	if err := r.RenterTenantsQuotaPost(rtc.Name, quota); err != nil {
+		t.Fatal(err)
+	}
+	err = tc.RenterUploadStreamPost(bytes.NewReader(data), modules.RandomSiaPath(), 1, uint64(len(tg.Hosts())-1), false)
+	if err == nil || !strings.Contains(err.Error(), modules.ErrTenantQuotaExceeded.Error()) {
+		t.Fatal("expected upload to exceed the quota but got", err)
+	}
+	if _, _, err := tc.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(data)), true, false); err == nil {
+		t.Fatal("expected download to exceed the quota")
+	}
+
+	// The tenant can't use admin endpoints or authenticate with a wrong
+	// token.
+	if _, err := tc.RenterTenantsGet(); err == nil {
+		t.Fatal("tenant shouldn't be able to list tenants")
+	}
+	wrong := tc
+	wrong.Password = rtc.Token + "0"
+	if _, err := wrong.RenterDirGet(modules.RootSiaPath()); err == nil {
+		t.Fatal("expected authentication to fail")
+	}
+
+	// Delete the tenant.
+	if err := r.RenterTenantsDeletePost(rtc.Name); err != nil {
+		t.Fatal(err)
+	}
+	rt, err := r.RenterTenantsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rt.Tenants) != 0 {
+		t.Fatal("expected no tenants", rt.Tenants)
+	}
+}
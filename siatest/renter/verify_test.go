@@ -0,0 +1,187 @@
+package renter
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.thebigfile.com/bigd/build"
+	"go.thebigfile.com/bigd/modules"
+	"go.thebigfile.com/bigd/node"
+	"go.thebigfile.com/bigd/siatest"
+	"go.thebigfile.com/bigd/siatest/dependencies"
+)
+
+// TestRenterVerify tests verifying the integrity of files by sampling their
+// data from the hosts.
+func TestRenterVerify(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a testgroup without a renter.
+	gp := siatest.GroupParams{
+		Hosts:  3,
+		Miners: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(renterTestDir(t.Name()), gp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add a host which corrupts the data it returns for reads and a renter.
+	hostParams := node.HostTemplate
+	hostParams.HostDeps = dependencies.NewDependencyCorruptReadSector()
+	nodes, err := tg.AddNodes(hostParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	corruptHostPK, err := nodes[0].HostPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes, err = tg.AddNodes(node.RenterTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := nodes[0]
+
+	// Upload a file with a piece on every host.
+	fileSize := int(modules.SectorSize) + siatest.Fuzz()
+	_, remoteFile, err := r.UploadNewFileBlocking(fileSize, 1, 3, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verifying with zero samples should fail.
+	_, err = r.RenterVerifyPost(remoteFile.SiaPath(), 0, false, false)
+	if err == nil {
+		t.Fatal("expected verification without samples to fail")
+	}
+
+	// Verify the file. Only the samples of the corrupt host should fail. A
+	// failed read puts the worker on a cooldown, so subsequent samples of the
+	// corrupt host might be unverified.
+	report, err := r.RenterVerifyPost(remoteFile.SiaPath(), modules.DefaultVerificationSamples, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FilesVerified != 1 {
+		t.Fatalf("expected 1 verified file but got %v", report.FilesVerified)
+	}
+	if len(report.Hosts) != len(tg.Hosts()) {
+		t.Fatalf("expected %v hosts but got %v", len(tg.Hosts()), len(report.Hosts))
+	}
+	for _, h := range report.Hosts {
+		if h.HostPublicKey.Equals(corruptHostPK) {
+			if h.Failures == 0 || h.Failures+h.Unverified != h.Samples || h.TrustScore >= 1 {
+				t.Fatalf("unexpected result of corrupt host %+v", h)
+			}
+			continue
+		}
+		if h.Samples == 0 || h.Failures != 0 || h.Unverified != 0 || h.TrustScore != 1 {
+			t.Fatalf("unexpected host result %+v", h)
+		}
+	}
+	if len(report.CorruptPieces) == 0 || report.PiecesRemoved != 0 {
+		t.Fatalf("unexpected pieces %+v", report)
+	}
+	for _, p := range append(report.CorruptPieces, report.UnverifiedPieces...) {
+		if !p.HostPublicKey.Equals(corruptHostPK) {
+			t.Fatalf("unexpected piece %+v", p)
+		}
+	}
+
+	// Take down a good host and verify the file's folder with repair enabled.
+	// The pieces of the corrupt host should be removed from the file while
+	// the pieces of the offline host are unverified and kept.
+	var offlineHost *siatest.TestNode
+	for _, h := range tg.Hosts() {
+		pk, err := h.HostPublicKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pk.Equals(corruptHostPK) {
+			offlineHost = h
+			break
+		}
+	}
+	offlineHostPK, err := offlineHost.HostPublicKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tg.RemoveNode(offlineHost); err != nil {
+		t.Fatal(err)
+	}
+	// Retry until the corrupt host's worker is off its cooldown.
+	err = build.Retry(60, time.Second, func() error {
+		report, err = r.RenterVerifyPost(modules.RootSiaPath(), modules.DefaultVerificationSamples, true, true)
+		if err != nil {
+			return err
+		}
+		if len(report.CorruptPieces) == 0 {
+			return errors.New("no corrupt pieces found")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.FilesVerified != 1 {
+		t.Fatalf("expected 1 verified file but got %v", report.FilesVerified)
+	}
+	if report.PiecesRemoved != uint64(len(report.CorruptPieces)) {
+		t.Fatalf("expected %v removed pieces but got %v", len(report.CorruptPieces), report.PiecesRemoved)
+	}
+	var offlinePieces int
+	for _, p := range report.UnverifiedPieces {
+		if p.HostPublicKey.Equals(offlineHostPK) {
+			offlinePieces++
+		} else if !p.HostPublicKey.Equals(corruptHostPK) {
+			t.Fatalf("unexpected unverified piece %+v", p)
+		}
+	}
+	if offlinePieces == 0 {
+		t.Fatal("expected unverified pieces of the offline host")
+	}
+	for _, h := range report.Hosts {
+		if h.HostPublicKey.Equals(offlineHostPK) && (h.Failures != 0 || h.TrustScore != 1) {
+			t.Fatalf("offline host shouldn't be penalized %+v", h)
+		}
+	}
+
+	// Verifying again should still report the pieces of the offline host since
+	// they weren't removed.
+	report, err = r.RenterVerifyPost(remoteFile.SiaPath(), modules.DefaultVerificationSamples, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.UnverifiedPieces) == 0 {
+		t.Fatal("expected the pieces of the offline host to remain")
+	}
+
+	// The trust scores of the hosts are persisted across verifications.
+	rvg, err := r.RenterVerifyGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rvg.Hosts) != len(tg.Hosts())+1 {
+		t.Fatalf("expected %v hosts but got %v", len(tg.Hosts())+1, len(rvg.Hosts))
+	}
+	for _, h := range rvg.Hosts {
+		if h.HostPublicKey.Equals(corruptHostPK) {
+			if h.Failures == 0 || h.TrustScore >= 1 {
+				t.Fatalf("unexpected trust score of corrupt host %+v", h)
+			}
+		} else if h.Failures != 0 || h.TrustScore != 1 {
+			t.Fatalf("unexpected trust score %+v", h)
+		}
+	}
+}
@@ -144,10 +144,10 @@ func newCleanNode(nodeParams node.NodeParams, asyncSync bool) (*TestNode, error)
 	var err error
 	if asyncSync {
 		var errChan <-chan error
-		s, errChan = server.NewAsync(":0", userAgent, password, nodeParams, time.Now())
+		s, errChan = server.NewAsync(":0", userAgent, password, nil, nodeParams, time.Now())
 		err = modules.PeekErr(errChan)
 	} else {
-		s, err = server.New(":0", userAgent, password, nodeParams, time.Now())
+		s, err = server.New(":0", userAgent, password, nil, nodeParams, time.Now())
 	}
 	if err != nil {
 		return nil, err
@@ -377,7 +377,7 @@ func (tn *TestNode) SiaPath(path string) modules.SiaPath {
 // StartNode starts a TestNode from an active group
 func (tn *TestNode) StartNode() error {
 	// Create server
-	s, err := server.New(":0", tn.UserAgent, tn.Password, tn.params, time.Now())
+	s, err := server.New(":0", tn.UserAgent, tn.Password, nil, tn.params, time.Now())
 	if err != nil {
 		return err
 	}
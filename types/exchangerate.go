@@ -6,6 +6,7 @@ package types
 import (
 	"errors"
 	"fmt"
+	"math"
 	"math/big"
 	"regexp"
 )
@@ -92,3 +93,25 @@ func (r *ExchangeRate) ApplyAndFormat(c Currency) string {
 	result = fmt.Sprintf("~ %s %s", result, r.staticSymbol)
 	return result
 }
+
+// FromFiat converts an amount of fiat in the currency of the exchange rate to
+// hastings. Amounts that are not positive numbers are converted to zero.
+func (r *ExchangeRate) FromFiat(amount float64) Currency {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount <= 0 {
+		return ZeroCurrency
+	}
+	asRatio, _ := r.staticValue.Rat(nil)
+	amountRat := new(big.Rat).SetFloat64(amount)
+
+	// calculate (amountRat / asRatio) * SiacoinPrecision
+	return SiacoinPrecision.MulRat(new(big.Rat).Quo(amountRat, asRatio))
+}
+
+// Apply applies the exchange rate to a currency amount and returns the result
+// with four decimal places and without the symbol, e.g. for exports.
+func (r *ExchangeRate) Apply(c Currency) string {
+	asRatio, _ := r.staticValue.Rat(nil)
+	cRat := new(big.Rat).SetInt(c.Big())
+	precisionRat := new(big.Rat).SetInt(SiacoinPrecision.Big())
+	return new(big.Rat).Quo(new(big.Rat).Mul(cRat, asRatio), precisionRat).FloatString(4)
+}
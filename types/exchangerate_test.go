@@ -1,6 +1,7 @@
 package types
 
 import (
+	"math"
 	"testing"
 )
 
@@ -122,3 +123,59 @@ func TestApplyAndFormat(t *testing.T) {
 		}
 	}
 }
+
+// TestExchangeRateFromFiat checks that fiat amounts are converted to hastings
+// correctly.
+func TestExchangeRateFromFiat(t *testing.T) {
+	mustParse := func(s string) *ExchangeRate {
+		rate, err := ParseExchangeRate(s)
+		if err != nil {
+			t.Fatalf("test case uses invalid exchange rate: %v", err)
+		}
+		return rate
+	}
+	tests := []struct {
+		rate   *ExchangeRate
+		amount float64
+		result Currency
+	}{
+		{mustParse("1 USD"), 1, SiacoinPrecision},
+		{mustParse("1 USD"), 0.5, SiacoinPrecision.Div64(2)},
+		{mustParse("0.125 USD"), 1, SiacoinPrecision.Mul64(8)},
+		{mustParse("0.25 USD"), 2.5, SiacoinPrecision.Mul64(10)},
+		{mustParse("2 EUR"), 0, ZeroCurrency},
+		{mustParse("2 EUR"), -1, ZeroCurrency},
+		{mustParse("2 EUR"), math.NaN(), ZeroCurrency},
+		{mustParse("2 EUR"), math.Inf(1), ZeroCurrency},
+	}
+	for _, test := range tests {
+		result := test.rate.FromFiat(test.amount)
+		if !result.Equals(test.result) {
+			t.Errorf("FromFiat(%v) with %v %v: expected %v, got %v",
+				test.amount, test.rate.staticValue, test.rate.staticSymbol, test.result, result)
+		}
+	}
+}
+
+// TestExchangeRateApply checks that currency amounts are converted to fiat
+// amounts with four decimal places.
+func TestExchangeRateApply(t *testing.T) {
+	rate, err := ParseExchangeRate("0.005 usd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		amount Currency
+		result string
+	}{
+		{ZeroCurrency, "0.0000"},
+		{SiacoinPrecision, "0.0050"},
+		{SiacoinPrecision.Mul64(1234), "6.1700"},
+		{SiacoinPrecision.Div64(10), "0.0005"},
+	}
+	for _, test := range tests {
+		if result := rate.Apply(test.amount); result != test.result {
+			t.Errorf("Apply(%v): expected %v, got %v", test.amount, test.result, result)
+		}
+	}
+}